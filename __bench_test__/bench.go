@@ -0,0 +1,228 @@
+// Package __bench_test__ benchmarks the stages of a GraphQL request, the lexing, the parsing, the validation and
+// the execution, with the workloads of the reference implementation. The benchmarks of this package measure this
+// library alone:
+//
+//	go test -run - -bench . -count 10 ./__bench_test__ | tee new.txt
+//	benchstat old.txt new.txt
+//
+// The compare module, in its own directory so that the other libraries are not dependencies of this one, runs the
+// same benchmarks with the engines of graphql-go/graphql and of gqlgen, see its package documentation for what is
+// compared.
+//
+// The sub-benchmarks are named after the workload and the engine, eg. BenchmarkExecute/star-wars/shyptr, so that
+// benchstat compares the runs of two commits engine by engine.
+package __bench_test__
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/validation"
+)
+
+// Workload is a query run against its schema.
+type Workload struct {
+	Name          string
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	// Schema is the schema of the workload built by this library, which the other engines build from its SDL
+	Schema *internal.Schema
+}
+
+// Workloads are the workloads of the benchmarks.
+var Workloads = func() []*Workload {
+	return []*Workload{
+		{
+			Name:          "kitchen-sink",
+			Query:         kitchenSinkQuery,
+			OperationName: "queryName",
+			Variables:     map[string]interface{}{"withStories": true, "foo": map[string]interface{}{"tags": []interface{}{"news"}, "minLikes": 1}},
+			Schema:        kitchenSinkSchema(),
+		},
+		{
+			Name:      "star-wars",
+			Query:     starWarsQuery,
+			Variables: map[string]interface{}{"withFriends": true},
+			Schema:    StarWars,
+		},
+		{
+			Name:          "introspection",
+			Query:         introspection.IntrospectionQuery,
+			OperationName: "IntrospectionQuery",
+			Schema:        StarWars,
+		},
+	}
+}()
+
+// Engine is a GraphQL library under benchmark. A stage it does not support is nil.
+type Engine struct {
+	Name string
+	// Lex scans all the tokens of query
+	Lex func(query string) error
+	// Parse parses query into its document
+	Parse func(query string) error
+	// Prepare returns the stages of the engine running against the schema of w, nil when it can not build it
+	Prepare func(w *Workload) *PreparedEngine
+}
+
+// PreparedEngine are the stages of an engine running a workload.
+type PreparedEngine struct {
+	// Validate parses and validates the query of the workload
+	Validate func() error
+	// Execute parses, validates and executes the query of the workload, as a request does
+	Execute func() error
+}
+
+// Shyptr is the engine of this library.
+var Shyptr = &Engine{
+	Name: "shyptr",
+	Lex: func(query string) error {
+		if _, err := internal.Lex(query); err != nil {
+			return err
+		}
+		return nil
+	},
+	Parse: func(query string) error {
+		if _, err := internal.Parse(query); err != nil {
+			return err
+		}
+		return nil
+	},
+	Prepare: func(w *Workload) *PreparedEngine {
+		// the caches of the executor would measure the lookups of the caches only
+		executor := &execution.Executor{
+			ValidationCache: validation.NewCache(0),
+			DocumentCache:   execution.NewDocumentCache(0),
+			PlanCache:       execution.NewPlanCache(0),
+		}
+		return &PreparedEngine{
+			Validate: func() error {
+				doc, err := internal.Parse(w.Query)
+				if err != nil {
+					return err
+				}
+				if err := validation.Validate(w.Schema, doc); err != nil {
+					return err
+				}
+				return nil
+			},
+			Execute: func() error {
+				_, err := executor.Do(w.Schema, execution.Params{
+					Query:         w.Query,
+					OperationName: w.OperationName,
+					Variables:     w.Variables,
+					Context:       context.Background(),
+				})
+				if err != nil {
+					return err
+				}
+				return nil
+			},
+		}
+	},
+}
+
+// run runs the sub-benchmarks of the stage selected by stage, for every workload and engine supporting it.
+func run(b *testing.B, engines []*Engine, stage func(e *Engine, w *Workload) func() error) {
+	for _, w := range Workloads {
+		for _, e := range engines {
+			fn := stage(e, w)
+			if fn == nil {
+				continue
+			}
+			b.Run(w.Name+"/"+e.Name, func(b *testing.B) {
+				if err := fn(); err != nil {
+					b.Fatalf("%s fails on %s: %v", e.Name, w.Name, err)
+				}
+				b.ReportAllocs()
+				b.SetBytes(int64(len(w.Query)))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					fn()
+				}
+			})
+		}
+	}
+}
+
+// Lex benchmarks the lexing of the workloads by engines.
+func Lex(b *testing.B, engines ...*Engine) {
+	run(b, engines, func(e *Engine, w *Workload) func() error {
+		if e.Lex == nil {
+			return nil
+		}
+		return func() error { return e.Lex(w.Query) }
+	})
+}
+
+// Parse benchmarks the parsing of the workloads by engines.
+func Parse(b *testing.B, engines ...*Engine) {
+	run(b, engines, func(e *Engine, w *Workload) func() error {
+		if e.Parse == nil {
+			return nil
+		}
+		return func() error { return e.Parse(w.Query) }
+	})
+}
+
+// Validate benchmarks the parsing and the validation of the workloads by engines.
+func Validate(b *testing.B, engines ...*Engine) {
+	run(b, engines, func(e *Engine, w *Workload) func() error {
+		if prepared := prepare(e, w); prepared != nil {
+			return prepared.Validate
+		}
+		return nil
+	})
+}
+
+// Execute benchmarks the requests of the workloads run by engines.
+func Execute(b *testing.B, engines ...*Engine) {
+	run(b, engines, func(e *Engine, w *Workload) func() error {
+		if prepared := prepare(e, w); prepared != nil {
+			return prepared.Execute
+		}
+		return nil
+	})
+}
+
+func prepare(e *Engine, w *Workload) *PreparedEngine {
+	if e.Prepare == nil {
+		return nil
+	}
+	return e.Prepare(w)
+}
+
+// Check checks that the workloads run without errors on engines, so that the benchmarks do not measure the
+// failures.
+func Check(t *testing.T, engines ...*Engine) {
+	for _, w := range Workloads {
+		for _, e := range engines {
+			for stage, fn := range map[string]func(string) error{"lex": e.Lex, "parse": e.Parse} {
+				if fn == nil {
+					continue
+				}
+				if err := fn(w.Query); err != nil {
+					t.Errorf("%s fails to %s %s: %v", e.Name, stage, w.Name, err)
+				}
+			}
+			prepared := prepare(e, w)
+			if prepared == nil {
+				continue
+			}
+			if prepared.Validate != nil {
+				if err := prepared.Validate(); err != nil {
+					t.Errorf("%s fails to validate %s: %v", e.Name, w.Name, err)
+				}
+			}
+			if prepared.Execute != nil {
+				if err := prepared.Execute(); err != nil {
+					t.Errorf("%s fails to execute %s: %v", e.Name, w.Name, err)
+				}
+			}
+		}
+	}
+}
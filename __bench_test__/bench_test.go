@@ -0,0 +1,12 @@
+package __bench_test__
+
+import "testing"
+
+func BenchmarkLex(b *testing.B)      { Lex(b, Shyptr) }
+func BenchmarkParse(b *testing.B)    { Parse(b, Shyptr) }
+func BenchmarkValidate(b *testing.B) { Validate(b, Shyptr) }
+func BenchmarkExecute(b *testing.B)  { Execute(b, Shyptr) }
+
+func TestWorkloads(t *testing.T) {
+	Check(t, Shyptr)
+}
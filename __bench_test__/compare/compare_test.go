@@ -0,0 +1,19 @@
+package compare
+
+import (
+	"testing"
+
+	bench "github.com/shyptr/graphql/__bench_test__"
+)
+
+// engines are the engines under benchmark, this library first.
+var engines = []*bench.Engine{bench.Shyptr, graphqlGo, gqlgen}
+
+func BenchmarkLex(b *testing.B)      { bench.Lex(b, engines...) }
+func BenchmarkParse(b *testing.B)    { bench.Parse(b, engines...) }
+func BenchmarkValidate(b *testing.B) { bench.Validate(b, engines...) }
+func BenchmarkExecute(b *testing.B)  { bench.Execute(b, engines...) }
+
+func TestWorkloads(t *testing.T) {
+	bench.Check(t, engines...)
+}
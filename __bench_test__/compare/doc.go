@@ -0,0 +1,21 @@
+// Package compare runs the benchmarks of the __bench_test__ package with the engines of graphql-go/graphql and of
+// gqlgen along with the one of this library. It is a module of its own so that the other libraries are not
+// dependencies of this one:
+//
+//	cd __bench_test__/compare
+//	go mod tidy
+//	go test -run - -bench . -count 10 | tee new.txt
+//	benchstat old.txt new.txt
+//
+// What is compared, per stage:
+//
+//   - lex and parse: the three engines, on every workload.
+//   - validate: this library and gqlgen on every workload, graphql-go on the star-wars and introspection
+//     workloads only, since it builds its schemas in Go and ships the star-wars one alone.
+//   - execute: this library and graphql-go on the star-wars and introspection workloads, this library alone on
+//     the kitchen-sink one. gqlgen is not measured: it lexes, parses and validates the requests with gqlparser,
+//     which is what is measured of it, and executes them with the code it generates for a schema.
+//
+// The validate and execute stages of graphql-go run against its own star-wars schema, which is the one of the
+// reference implementation like the star-wars schema of the workloads.
+package compare
@@ -0,0 +1,11 @@
+module github.com/shyptr/graphql/__bench_test__/compare
+
+go 1.23
+
+require (
+	github.com/graphql-go/graphql v0.8.1
+	github.com/shyptr/graphql v0.0.0
+	github.com/vektah/gqlparser/v2 v2.5.16
+)
+
+replace github.com/shyptr/graphql => ../..
@@ -0,0 +1,55 @@
+package compare
+
+import (
+	"fmt"
+
+	bench "github.com/shyptr/graphql/__bench_test__"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// gqlgen lexes, parses and validates the requests with gqlparser, which loads the schemas of the workloads from
+// their SDL. Its execution runs the code it generates for a schema, which is not measured here.
+var gqlgen = &bench.Engine{
+	Name: "gqlgen",
+	Lex: func(query string) error {
+		l := lexer.New(&ast.Source{Input: query})
+		for {
+			token, err := l.ReadToken()
+			if err != nil {
+				return err
+			}
+			if token.Kind == lexer.EOF {
+				return nil
+			}
+		}
+	},
+	Parse: func(query string) error {
+		if _, err := parser.ParseQuery(&ast.Source{Input: query}); err != nil {
+			return err
+		}
+		return nil
+	},
+	Prepare: func(w *bench.Workload) *bench.PreparedEngine {
+		schema, err := gqlparser.LoadSchema(&ast.Source{Name: w.Name, Input: introspection.PrintSchema(w.Schema)})
+		if err != nil {
+			panic(fmt.Sprintf("gqlparser fails to load the schema of %s: %v", w.Name, err))
+		}
+		return &bench.PreparedEngine{
+			Validate: func() error {
+				doc, err := parser.ParseQuery(&ast.Source{Input: w.Query})
+				if err != nil {
+					return err
+				}
+				if errs := validator.Validate(schema, doc); len(errs) > 0 {
+					return errs
+				}
+				return nil
+			},
+		}
+	},
+}
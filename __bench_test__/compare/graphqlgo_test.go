@@ -0,0 +1,64 @@
+package compare
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/lexer"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/graphql-go/graphql/testutil"
+	bench "github.com/shyptr/graphql/__bench_test__"
+)
+
+// graphql-go builds its schemas in Go only, it runs the workloads of the star-wars schema, which it ships.
+var graphqlGo = &bench.Engine{
+	Name: "graphql-go",
+	Lex: func(query string) error {
+		lex := lexer.Lex(source.NewSource(&source.Source{Body: []byte(query)}))
+		for position := 0; ; {
+			token, err := lex(position)
+			if err != nil {
+				return err
+			}
+			if token.Kind == lexer.EOF {
+				return nil
+			}
+			position = token.End
+		}
+	},
+	Parse: func(query string) error {
+		_, err := parser.Parse(parser.ParseParams{Source: query})
+		return err
+	},
+	Prepare: func(w *bench.Workload) *bench.PreparedEngine {
+		if w.Schema != bench.StarWars {
+			return nil
+		}
+		schema := testutil.StarWarsSchema
+		return &bench.PreparedEngine{
+			Validate: func() error {
+				doc, err := parser.Parse(parser.ParseParams{Source: w.Query})
+				if err != nil {
+					return err
+				}
+				if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+					return fmt.Errorf("%v", result.Errors)
+				}
+				return nil
+			},
+			Execute: func() error {
+				result := graphql.Do(graphql.Params{
+					Schema:         schema,
+					RequestString:  w.Query,
+					OperationName:  w.OperationName,
+					VariableValues: w.Variables,
+				})
+				if result.HasErrors() {
+					return fmt.Errorf("%v", result.Errors)
+				}
+				return nil
+			},
+		}
+	},
+}
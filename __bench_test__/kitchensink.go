@@ -0,0 +1,226 @@
+package __bench_test__
+
+import (
+	"fmt"
+
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// The kitchen-sink workload is an executable version of the kitchen sink of the reference implementation: it
+// exercises most of the syntax of the documents, several operations, the fragments, the directives, the variables
+// and every kind of values, along with an interface, a union, an enum, input objects and lists.
+
+type Site int
+
+const (
+	Desktop Site = iota
+	Mobile
+)
+
+type Node interface {
+	GetID() string
+}
+
+type User struct {
+	ID       string   `graphql:"id"`
+	Name     string   `graphql:"name"`
+	Stories  []*Story `graphql:"-"`
+	Followed []string `graphql:"-"`
+}
+
+func (u *User) GetID() string { return u.ID }
+
+type Story struct {
+	ID     string   `graphql:"id"`
+	Text   string   `graphql:"text"`
+	Likers []string `graphql:"-"`
+	Tags   []string `graphql:"tags"`
+}
+
+func (s *Story) GetID() string { return s.ID }
+
+type Media struct {
+	*Story
+	*User
+}
+
+type StoryFilter struct {
+	Tags     []string `graphql:"tags"`
+	MinLikes int      `graphql:"minLikes"`
+	Author   *string  `graphql:"author"`
+}
+
+var users = func() map[string]*User {
+	users := make(map[string]*User)
+	for i := 0; i < 20; i++ {
+		user := &User{ID: fmt.Sprint(i), Name: fmt.Sprint("user ", i)}
+		for j := 0; j < 5; j++ {
+			user.Stories = append(user.Stories, &Story{
+				ID:     fmt.Sprint(i, "-", j),
+				Text:   fmt.Sprintf("story %d of user %d", j, i),
+				Likers: []string{fmt.Sprint((i + 1) % 20), fmt.Sprint((i + 2) % 20)},
+				Tags:   []string{"news", "sports"},
+			})
+			user.Followed = append(user.Followed, fmt.Sprint((i+j+1)%20))
+		}
+		users[user.ID] = user
+	}
+	return users
+}()
+
+func kitchenSinkSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Enum("Site", Site(0), map[string]interface{}{"DESKTOP": Desktop, "MOBILE": Mobile})
+	build.InputObject("StoryFilter", StoryFilter{})
+	nodeType := build.Interface("Node", new(Node), func(n Node) Node { return n })
+	nodeType.FieldFunc("id", "GetID")
+	build.Union("Media", Media{}, "")
+
+	userType := build.Object("User", User{})
+	userType.InterfaceList(nodeType)
+	userType.FieldFunc("profilePicture", func(u *User, args struct {
+		Size int   `graphql:"size"`
+		Site *Site `graphql:"site"`
+	}) string {
+		site := Desktop
+		if args.Site != nil {
+			site = *args.Site
+		}
+		return fmt.Sprintf("https://example.com/%s/%d/%d.png", u.ID, site, args.Size)
+	})
+	userType.FieldFunc("stories", func(u *User, args struct {
+		First  int          `graphql:"first"`
+		After  *string      `graphql:"after"`
+		Filter *StoryFilter `graphql:"filter"`
+	}) []*Story {
+		if args.First < len(u.Stories) {
+			return u.Stories[:args.First]
+		}
+		return u.Stories
+	})
+	userType.FieldFunc("followed", func(u *User) []*User {
+		followed := make([]*User, len(u.Followed))
+		for i, id := range u.Followed {
+			followed[i] = users[id]
+		}
+		return followed
+	})
+
+	storyType := build.Object("Story", Story{})
+	storyType.InterfaceList(nodeType)
+	storyType.FieldFunc("likers", func(s *Story, args struct {
+		First int `graphql:"first"`
+	}) []*User {
+		likers := make([]*User, 0, len(s.Likers))
+		for _, id := range s.Likers {
+			likers = append(likers, users[id])
+		}
+		return likers
+	})
+	storyType.FieldFunc("likeCount", func(s *Story) int { return len(s.Likers) })
+
+	query := build.Query()
+	query.FieldFunc("node", func(args struct {
+		ID string `graphql:"id"`
+	}) Node {
+		return users[args.ID]
+	})
+	query.FieldFunc("nodes", func(args struct {
+		IDs []string `graphql:"ids;;nonnull"`
+	}) []Node {
+		nodes := make([]Node, len(args.IDs))
+		for i, id := range args.IDs {
+			nodes[i] = users[id]
+		}
+		return nodes
+	})
+	query.FieldFunc("feed", func(args struct {
+		Filter *StoryFilter `graphql:"filter"`
+		Limit  int          `graphql:"limit"`
+	}) []Media {
+		var feed []Media
+		for i := 0; i < 20 && len(feed) < args.Limit; i++ {
+			user := users[fmt.Sprint(i)]
+			feed = append(feed, Media{User: user}, Media{Story: user.Stories[0]})
+		}
+		return feed
+	})
+	query.FieldFunc("echo", func(args struct {
+		Text    string  `graphql:"text"`
+		Truthy  bool    `graphql:"truthy"`
+		Falsey  bool    `graphql:"falsey"`
+		Nullish *string `graphql:"nullish"`
+		Ratio   float64 `graphql:"ratio"`
+		Numbers []int   `graphql:"numbers"`
+	}) string {
+		return fmt.Sprint(args.Text, args.Truthy, args.Falsey, args.Nullish, args.Ratio, args.Numbers)
+	})
+	build.Mutation().FieldFunc("like", func(args struct {
+		Story string `graphql:"story"`
+	}) *Story {
+		return users["0"].Stories[0]
+	})
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	return schema
+}
+
+const kitchenSinkQuery = `# the kitchen sink, executable
+query queryName($foo: StoryFilter, $site: Site = MOBILE, $ids: [String!]! = ["1", "2"], $withStories: Boolean!) {
+  whoever123is: node(id: "3") {
+    id ,
+    ... on User @include(if: $withStories) {
+      name
+      profilePicture(size: 64, site: $site)
+      alias: stories(first: 3, after: null, filter: $foo,) @include(if: $withStories) {
+        id,
+        ...storyFields
+      }
+      followed {
+        ...userFields
+        followed { ...userFields }
+      }
+    }
+    ... @skip(if: $withStories) {
+      id
+    }
+    ... {
+      __typename
+    }
+  }
+  nodes(ids: $ids) {
+    ... on User { ...userFields }
+  }
+  feed(limit: 10, filter: {tags: ["news", "sports"], minLikes: 2, author: null}) {
+    __typename
+    ... on User { name }
+    ... on Story { ...storyFields }
+  }
+  echo(text: """
+      block string uses \"""
+  """, truthy: true, falsey: false, nullish: null, ratio: -1.5e3, numbers: [1, 2, 3])
+}
+
+mutation likeStory {
+  like(story: "0-0") {
+    ...storyFields
+  }
+}
+
+fragment storyFields on Story {
+  text
+  tags
+  likeCount
+  likers(first: 10) {
+    ...userFields
+  }
+}
+
+fragment userFields on User {
+  id
+  name
+  profilePicture(size: 32)
+}
+`
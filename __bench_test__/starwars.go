@@ -0,0 +1,158 @@
+package __bench_test__
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// The star-wars schema is the one of the reference implementation, which the other libraries ship too.
+
+type Episode int
+
+const (
+	NewHope Episode = iota + 4
+	Empire
+	Jedi
+)
+
+type Character interface {
+	GetID() string
+	GetName() string
+	GetFriends() []Character
+	GetAppearsIn() []Episode
+}
+
+type Human struct {
+	ID         string    `graphql:"id"`
+	Name       string    `graphql:"name"`
+	Friends    []string  `graphql:"-"`
+	AppearsIn  []Episode `graphql:"appearsIn"`
+	HomePlanet *string   `graphql:"homePlanet"`
+}
+
+func (h *Human) GetID() string           { return h.ID }
+func (h *Human) GetName() string         { return h.Name }
+func (h *Human) GetFriends() []Character { return friends(h.Friends) }
+func (h *Human) GetAppearsIn() []Episode { return h.AppearsIn }
+
+type Droid struct {
+	ID              string    `graphql:"id"`
+	Name            string    `graphql:"name"`
+	Friends         []string  `graphql:"-"`
+	AppearsIn       []Episode `graphql:"appearsIn"`
+	PrimaryFunction string    `graphql:"primaryFunction"`
+}
+
+func (d *Droid) GetID() string           { return d.ID }
+func (d *Droid) GetName() string         { return d.Name }
+func (d *Droid) GetFriends() []Character { return friends(d.Friends) }
+func (d *Droid) GetAppearsIn() []Episode { return d.AppearsIn }
+
+var (
+	tatooine  = "Tatooine"
+	alderaan  = "Alderaan"
+	episodes  = []Episode{NewHope, Empire, Jedi}
+	humanData = map[string]*Human{
+		"1000": {ID: "1000", Name: "Luke Skywalker", Friends: []string{"1002", "1003", "2000", "2001"}, AppearsIn: episodes, HomePlanet: &tatooine},
+		"1001": {ID: "1001", Name: "Darth Vader", Friends: []string{"1004"}, AppearsIn: episodes, HomePlanet: &tatooine},
+		"1002": {ID: "1002", Name: "Han Solo", Friends: []string{"1000", "1003", "2001"}, AppearsIn: episodes},
+		"1003": {ID: "1003", Name: "Leia Organa", Friends: []string{"1000", "1002", "2000", "2001"}, AppearsIn: episodes, HomePlanet: &alderaan},
+		"1004": {ID: "1004", Name: "Wilhuff Tarkin", Friends: []string{"1001"}, AppearsIn: []Episode{NewHope}},
+	}
+	droidData = map[string]*Droid{
+		"2000": {ID: "2000", Name: "C-3PO", Friends: []string{"1000", "1002", "1003", "2001"}, AppearsIn: episodes, PrimaryFunction: "Protocol"},
+		"2001": {ID: "2001", Name: "R2-D2", Friends: []string{"1000", "1002", "1003"}, AppearsIn: episodes, PrimaryFunction: "Astromech"},
+	}
+)
+
+func character(id string) Character {
+	if human, ok := humanData[id]; ok {
+		return human
+	}
+	if droid, ok := droidData[id]; ok {
+		return droid
+	}
+	return nil
+}
+
+func friends(ids []string) []Character {
+	characters := make([]Character, len(ids))
+	for i, id := range ids {
+		characters[i] = character(id)
+	}
+	return characters
+}
+
+// StarWars is the star-wars schema, the one of the star-wars and introspection workloads.
+var StarWars = starWarsSchema()
+
+func starWarsSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Enum("Episode", Episode(0), map[string]interface{}{
+		"NEWHOPE": schemabuilder.DescField{Field: NewHope, Desc: "Released in 1977."},
+		"EMPIRE":  schemabuilder.DescField{Field: Empire, Desc: "Released in 1980."},
+		"JEDI":    schemabuilder.DescField{Field: Jedi, Desc: "Released in 1983."},
+	}, "One of the films in the Star Wars Trilogy")
+
+	characterType := build.Interface("Character", new(Character), func(c Character) Character { return c },
+		"A character in the Star Wars Trilogy")
+	characterType.FieldFunc("id", "GetID", "The id of the character.")
+	characterType.FieldFunc("name", "GetName", "The name of the character.")
+	characterType.FieldFunc("friends", "GetFriends", "The friends of the character, or an empty list if they have none.")
+	characterType.FieldFunc("appearsIn", "GetAppearsIn", "Which movies they appear in.")
+
+	humanType := build.Object("Human", Human{}, "A humanoid creature in the Star Wars universe.")
+	humanType.FieldFunc("friends", func(h *Human) []Character { return h.GetFriends() })
+	humanType.InterfaceList(characterType)
+	droidType := build.Object("Droid", Droid{}, "A mechanical creature in the Star Wars universe.")
+	droidType.FieldFunc("friends", func(d *Droid) []Character { return d.GetFriends() })
+	droidType.InterfaceList(characterType)
+
+	query := build.Query()
+	query.FieldFunc("hero", func(args struct {
+		Episode *Episode `graphql:"episode"`
+	}) Character {
+		if args.Episode != nil && *args.Episode == Empire {
+			return humanData["1000"]
+		}
+		return droidData["2001"]
+	})
+	query.FieldFunc("human", func(args struct {
+		ID string `graphql:"id"`
+	}) *Human {
+		return humanData[args.ID]
+	})
+	query.FieldFunc("droid", func(args struct {
+		ID string `graphql:"id"`
+	}) *Droid {
+		return droidData[args.ID]
+	})
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	return schema
+}
+
+const starWarsQuery = `query HeroNameAndFriends($episode: Episode = EMPIRE, $withFriends: Boolean!) {
+  hero(episode: $episode) {
+    __typename
+    ...CharacterFields
+    friends @include(if: $withFriends) {
+      ...CharacterFields
+      friends {
+        name
+        ... on Human { homePlanet }
+        ... on Droid { primaryFunction }
+      }
+    }
+  }
+  leia: human(id: "1003") { ...CharacterFields homePlanet }
+  r2d2: droid(id: "2001") { ...CharacterFields primaryFunction }
+}
+
+fragment CharacterFields on Character {
+  id
+  name
+  appearsIn
+}
+`
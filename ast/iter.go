@@ -0,0 +1,49 @@
+package ast
+
+import "iter"
+
+// AllFields yields every field of the executable definitions of the document, depth first and in source order,
+// including the fields of fragment definitions and inline fragments. Fragment spreads are not followed,
+// so each field of the document is yielded once.
+func (d *Document) AllFields() iter.Seq[*Field] {
+	return func(yield func(*Field) bool) {
+		for _, definition := range d.Definition {
+			var selectionSet *SelectionSet
+			switch definition := definition.(type) {
+			case *OperationDefinition:
+				selectionSet = definition.SelectionSet
+			case *FragmentDefinition:
+				selectionSet = definition.SelectionSet
+			}
+			if !selectionSet.allFields(yield) {
+				return
+			}
+		}
+	}
+}
+
+// AllFields is like Document.AllFields for the selection set.
+func (s *SelectionSet) AllFields() iter.Seq[*Field] {
+	return func(yield func(*Field) bool) {
+		s.allFields(yield)
+	}
+}
+
+func (s *SelectionSet) allFields(yield func(*Field) bool) bool {
+	if s == nil {
+		return true
+	}
+	for _, selection := range s.Selections {
+		switch selection := selection.(type) {
+		case *Field:
+			if !yield(selection) || !selection.SelectionSet.allFields(yield) {
+				return false
+			}
+		case *InlineFragment:
+			if !selection.SelectionSet.allFields(yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
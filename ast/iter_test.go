@@ -0,0 +1,30 @@
+package ast_test
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDocument_AllFields(t *testing.T) {
+	doc, err := internal.ParseDocument(`
+		query { hero { name ...on Droid { primaryFunction } ...friends } }
+		fragment friends on Character { friends { name } }
+	`)
+	assert.Nil(t, err)
+
+	var names []string
+	for field := range doc.AllFields() {
+		names = append(names, field.Name.Name)
+	}
+	assert.Equal(t, []string{"hero", "name", "primaryFunction", "friends", "name"}, names)
+
+	names = nil
+	for field := range doc.AllFields() {
+		if field.Name.Name == "primaryFunction" {
+			break
+		}
+		names = append(names, field.Name.Name)
+	}
+	assert.Equal(t, []string{"hero", "name"}, names)
+}
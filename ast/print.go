@@ -0,0 +1,337 @@
+package ast
+
+import "strings"
+
+// Print returns the source of node in the canonical layout of the GraphQL documents, like print of graphql-js:
+// the selection sets and the fields are indented by two spaces, the definitions are separated by a blank line and
+// the descriptions are block strings on the lines before what they describe. The comments are not part of the AST,
+// so they are not printed.
+func Print(node Node) string {
+	switch node := node.(type) {
+	case *Document:
+		definitions := make([]string, len(node.Definition))
+		for i, definition := range node.Definition {
+			definitions[i] = Print(definition)
+		}
+		return strings.Join(definitions, "\n\n") + "\n"
+	case *OperationDefinition:
+		if node.Operation == Query && node.Name == nil && len(node.Vars) == 0 && len(node.Directives) == 0 {
+			return Print(node.SelectionSet)
+		}
+		s := strings.ToLower(string(node.Operation))
+		if node.Name != nil || len(node.Vars) != 0 {
+			s += " " + printName(node.Name) + printVariableDefinitions(node.Vars)
+		}
+		return s + printDirectives(node.Directives) + " " + Print(node.SelectionSet)
+	case *FragmentDefinition:
+		return "fragment " + printName(node.Name) + printVariableDefinitions(node.VariableDefinitions) + " on " +
+			Print(node.TypeCondition) + printDirectives(node.Directives) + " " + Print(node.SelectionSet)
+	case *VariableDefinition:
+		return Print(node.Var) + ": " + Print(node.Type) + printDefault(node.DefaultValue) +
+			printDirectives(node.Directives)
+	case *SelectionSet:
+		selections := make([]string, len(node.Selections))
+		for i, selection := range node.Selections {
+			selections[i] = Print(selection)
+		}
+		return printBlock(selections, "\n")
+	case *Field:
+		s := printName(node.Name) + printArguments(node.Arguments) + printDirectives(node.Directives)
+		if node.Alias != nil && node.Alias.Name != node.Name.Name {
+			s = node.Alias.Name + ": " + s
+		}
+		if node.SelectionSet != nil {
+			s += " " + Print(node.SelectionSet)
+		}
+		return s
+	case *FragmentSpread:
+		return "..." + printName(node.Name) + printDirectives(node.Directives)
+	case *InlineFragment:
+		s := "..."
+		if node.TypeCondition != nil {
+			s += " on " + Print(node.TypeCondition)
+		}
+		return s + printDirectives(node.Directives) + " " + Print(node.SelectionSet)
+	case *Argument:
+		return printName(node.Name) + ": " + Print(node.Value)
+	case *Directive:
+		return "@" + printName(node.Name) + printArguments(node.Args)
+
+	case *Variable:
+		return "$" + printName(node.Name)
+	case *IntValue:
+		return node.Value
+	case *FloatValue:
+		return node.Value
+	case *StringValue:
+		if node.Block {
+			return printBlockString(node.Value)
+		}
+		return `"` + node.Value + `"`
+	case *BooleanValue:
+		if node.Value {
+			return "true"
+		}
+		return "false"
+	case *NullValue:
+		return "null"
+	case *EnumValue:
+		return node.Value
+	case *ListValue:
+		values := make([]string, len(node.Values))
+		for i, value := range node.Values {
+			values[i] = Print(value)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case *ObjectValue:
+		fields := make([]string, len(node.Fields))
+		for i, field := range node.Fields {
+			fields[i] = Print(field)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case *ObjectField:
+		return Print(node.Name) + ": " + Print(node.Value)
+
+	case *Named:
+		return printName(node.Name)
+	case *List:
+		return "[" + Print(node.Type) + "]"
+	case *NonNull:
+		return Print(node.Type) + "!"
+	case *Name:
+		return printName(node)
+
+	case *SchemaDefinition:
+		return printDescription(node.Desc) + "schema" + printDirectives(node.Directives) +
+			printOperationTypes(node.OperationTypes)
+	case *SchemaExtension:
+		return "extend schema" + printDirectives(node.Directives) + printOperationTypes(node.RootOperation)
+	case *OperationTypeDefinition:
+		return strings.ToLower(string(node.Operation)) + ": " + Print(node.Type)
+	case *ScalarDefinition:
+		return printDescription(node.Desc) + "scalar " + printName(node.Name) + printDirectives(node.Directives)
+	case *ScalarExtension:
+		return "extend scalar " + printName(node.Name) + printDirectives(node.Directives)
+	case *ObjectDefinition:
+		return printDescription(node.Desc) + "type " + printName(node.Name) + printImplements(node.Interfaces) +
+			printDirectives(node.Directives) + printFieldDefinitions(node.Fields)
+	case *ObjectExtension:
+		return "extend type " + printName(node.Name) + printImplements(node.Interfaces) +
+			printDirectives(node.Directives) + printFieldDefinitions(node.Fields)
+	case *InterfaceDefinition:
+		return printDescription(node.Desc) + "interface " + printName(node.Name) + printImplements(node.Interfaces) +
+			printDirectives(node.Directives) + printFieldDefinitions(node.Fields)
+	case *InterfaceExtension:
+		return "extend interface " + printName(node.Name) + printImplements(node.Interfaces) +
+			printDirectives(node.Directives) + printFieldDefinitions(node.Fields)
+	case *FieldDefinition:
+		return printDescription(node.Desc) + printName(node.Name) + printArgumentDefinitions(node.Argument) + ": " +
+			Print(node.Type) + printDirectives(node.Directives)
+	case *InputValueDefinition:
+		return printDescription(node.Desc) + printName(node.Name) + ": " + Print(node.Type) +
+			printDefault(node.DefaultValue) + printDirectives(node.Directives)
+	case *UnionDefinition:
+		return printDescription(node.Desc) + "union " + printName(node.Name) + printDirectives(node.Directives) +
+			printMembers(node.Members)
+	case *UnionExtension:
+		return "extend union " + printName(node.Name) + printDirectives(node.Directives) + printMembers(node.Members)
+	case *EnumDefinition:
+		return printDescription(node.Desc) + "enum " + printName(node.Name) + printDirectives(node.Directives) +
+			printEnumValues(node.Values)
+	case *EnumExtension:
+		return "extend enum " + printName(node.Name) + printDirectives(node.Directives) + printEnumValues(node.Values)
+	case *EnumValueDefinition:
+		return printDescription(node.Desc) + Print(node.Value) + printDirectives(node.Directives)
+	case *InputObjectDefinition:
+		return printDescription(node.Desc) + "input " + printName(node.Name) + printDirectives(node.Directives) +
+			printInputValueDefinitions(node.InputFields)
+	case *InputObjectExtension:
+		return "extend input " + printName(node.Name) + printDirectives(node.Directives) +
+			printInputValueDefinitions(node.InputFields)
+	case *DirectiveDefinition:
+		s := printDescription(node.Desc) + "directive @" + printName(node.Name) +
+			printArgumentDefinitions(node.Arguments)
+		if node.Repeatable {
+			s += " repeatable"
+		}
+		return s + " on " + strings.Join(node.Locations, " | ")
+	}
+	return ""
+}
+
+func printName(name *Name) string {
+	if name == nil {
+		return ""
+	}
+	return name.Name
+}
+
+// printBlock prints lines between braces, indented by two spaces and separated by sep.
+func printBlock(lines []string, sep string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return "{\n" + indent(strings.Join(lines, sep)) + "\n}"
+}
+
+// indent indents the lines of s which are not empty by two spaces.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func printVariableDefinitions(definitions []*VariableDefinition) string {
+	if len(definitions) == 0 {
+		return ""
+	}
+	printed := make([]string, len(definitions))
+	for i, definition := range definitions {
+		printed[i] = Print(definition)
+	}
+	return "(" + strings.Join(printed, ", ") + ")"
+}
+
+func printArguments(args []*Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	printed := make([]string, len(args))
+	for i, arg := range args {
+		printed[i] = Print(arg)
+	}
+	return "(" + strings.Join(printed, ", ") + ")"
+}
+
+func printDirectives(directives []*Directive) string {
+	var s strings.Builder
+	for _, directive := range directives {
+		s.WriteString(" " + Print(directive))
+	}
+	return s.String()
+}
+
+func printDefault(value Value) string {
+	if value == nil {
+		return ""
+	}
+	return " = " + Print(value)
+}
+
+func printOperationTypes(operationTypes []*OperationTypeDefinition) string {
+	if len(operationTypes) == 0 {
+		return ""
+	}
+	printed := make([]string, len(operationTypes))
+	for i, operationType := range operationTypes {
+		printed[i] = Print(operationType)
+	}
+	return " " + printBlock(printed, "\n")
+}
+
+func printImplements(interfaces []*Named) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	names := make([]string, len(interfaces))
+	for i, named := range interfaces {
+		names[i] = Print(named)
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+func printMembers(members []*Named) string {
+	if len(members) == 0 {
+		return ""
+	}
+	names := make([]string, len(members))
+	for i, named := range members {
+		names[i] = Print(named)
+	}
+	return " = " + strings.Join(names, " | ")
+}
+
+// printFieldDefinitions prints the fields, separated by a blank line when one of them is described.
+func printFieldDefinitions(fields []*FieldDefinition) string {
+	printed := make([]string, len(fields))
+	described := false
+	for i, field := range fields {
+		printed[i] = Print(field)
+		described = described || field.Desc != nil
+	}
+	return printDefinitionBlock(printed, described)
+}
+
+func printInputValueDefinitions(values []*InputValueDefinition) string {
+	printed := make([]string, len(values))
+	described := false
+	for i, value := range values {
+		printed[i] = Print(value)
+		described = described || value.Desc != nil
+	}
+	return printDefinitionBlock(printed, described)
+}
+
+func printEnumValues(values []*EnumValueDefinition) string {
+	printed := make([]string, len(values))
+	described := false
+	for i, value := range values {
+		printed[i] = Print(value)
+		described = described || value.Desc != nil
+	}
+	return printDefinitionBlock(printed, described)
+}
+
+func printDefinitionBlock(lines []string, described bool) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	if described {
+		return " " + printBlock(lines, "\n\n")
+	}
+	return " " + printBlock(lines, "\n")
+}
+
+// printArgumentDefinitions prints the arguments on a line, or on a line each when one of them is described.
+func printArgumentDefinitions(args []*InputValueDefinition) string {
+	if len(args) == 0 {
+		return ""
+	}
+	printed := make([]string, len(args))
+	described := false
+	for i, arg := range args {
+		printed[i] = Print(arg)
+		described = described || arg.Desc != nil
+	}
+	if !described {
+		return "(" + strings.Join(printed, ", ") + ")"
+	}
+	return "(\n" + indent(strings.Join(printed, "\n")) + "\n)"
+}
+
+// printDescription prints desc as a block string on the lines before a definition. The descriptions which are
+// strings with invalid escapes are printed as they are.
+func printDescription(desc *StringValue) string {
+	if desc == nil {
+		return ""
+	}
+	text := desc.Text()
+	// the escapes of a string are unescaped by Text, unless they are invalid
+	if !desc.Block && text == desc.Value && strings.Contains(desc.Value, `\`) {
+		return `"` + desc.Value + `"` + "\n"
+	}
+	return printBlockString(text) + "\n"
+}
+
+// printBlockString prints value as a block string, on lines of its own when it has several lines.
+func printBlockString(value string) string {
+	value = strings.ReplaceAll(value, `"""`, `\"""`)
+	if !strings.Contains(value, "\n") && !strings.HasSuffix(value, `"`) && !strings.HasSuffix(value, `\`) {
+		return `"""` + value + `"""`
+	}
+	return `"""` + "\n" + value + "\n" + `"""`
+}
@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"encoding/json"
+
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/kinds"
 )
@@ -120,8 +122,11 @@ func (b *BooleanValue) GetValue() interface{} { return b.Value }
 // The empty string "" must not be followed by another " otherwise it would be interpreted as the beginning of a block string.
 // As an example, the source """""" can only be interpreted as a single empty block string and not three empty strings.
 type StringValue struct {
-	Kind  string          `json:"kind"`
-	Value string          `json:"value"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	// Block tells that Value is the string itself, of a block string or of a raw string, rather than the escaped
+	// characters between the quotes of a string.
+	Block bool            `json:"block"`
 	Loc   errors.Location `json:"loc"`
 }
 
@@ -135,6 +140,19 @@ func (s *StringValue) Location() errors.Location {
 
 func (s *StringValue) GetValue() interface{} { return s.Value }
 
+// Text returns the string of the value, the escaped characters of a string being unescaped. The strings with
+// invalid escapes are returned as they are.
+func (s *StringValue) Text() string {
+	if s.Block {
+		return s.Value
+	}
+	var text string
+	if err := json.Unmarshal([]byte(`"`+s.Value+`"`), &text); err != nil {
+		return s.Value
+	}
+	return text
+}
+
 //// Null values are represented as the keyword null.
 ////
 //// GraphQL has two semantically different ways to represent the lack of a value:
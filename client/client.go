@@ -0,0 +1,162 @@
+// Package client sends GraphQL operations to a server over HTTP, eg. to call another graph from a resolver:
+//
+//	c := client.New("https://api.example.com/graphql", client.Header("Authorization", "Bearer "+token))
+//	var out struct {
+//		User struct {
+//			Name string `json:"name"`
+//		} `json:"user"`
+//	}
+//	err := c.Do(ctx, `query($id: ID!) { user(id: $id) { name } }`, map[string]interface{}{"id": id}, &out)
+//
+// The errors of the responses are returned as an errors.MultiError, the categories of which errors.As tells apart
+// as the ones of the local operations, see errors.SyntaxError.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/shyptr/graphql/errors"
+)
+
+// Client sends the operations to the endpoint of a server. It is safe for concurrent use.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// Option configures the client returned by New.
+type Option func(*Client)
+
+// HTTPClient sends the requests with httpClient, eg. to set its timeout or its transport, instead of
+// http.DefaultClient.
+func HTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Header adds the header name to the requests, eg. their Authorization.
+func Header(name, value string) Option {
+	return func(c *Client) {
+		c.header.Add(name, value)
+	}
+}
+
+// New returns the client of the server whose GraphQL endpoint is url.
+func New(url string, options ...Option) *Client {
+	c := &Client{url: url, httpClient: http.DefaultClient, header: make(http.Header)}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Request is an operation sent to a server.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Response is the response of a server to an operation.
+type Response struct {
+	Errors     errors.MultiError      `json:"errors"`
+	Data       json.RawMessage        `json:"data"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// HTTPError is the error of the requests rejected with a status other than 2xx and without the errors of
+// a GraphQL response, eg. by a proxy.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Body is the beginning of the body of the response.
+	Body string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("graphql request rejected with %s: %s", e.Status, e.Body)
+}
+
+// Do sends the operation query with variables and decodes its data into out, eg. a pointer to a struct, see
+// json.Unmarshal. The data is decoded, eg. the partial data of an operation some fields of which failed, even when
+// the response has errors, which are returned as an errors.MultiError. out may be nil when the data is not used.
+func (c *Client) Do(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	resp, err := c.Send(ctx, &Request{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("graphql response data: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+// Send sends req and returns the response of the server, its errors included. The error is about the request only,
+// eg. an *HTTPError, a GraphQL response rejected with a status other than 2xx being returned as a response.
+func (c *Client) Send(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/graphql-response+json, application/json")
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{}
+	mediaType, _, _ := mime.ParseMediaType(httpResp.Header.Get("Content-Type"))
+	isJSON := mediaType == "application/json" || mediaType == "application/graphql-response+json"
+	if !isJSON || json.Unmarshal(respBody, resp) != nil ||
+		httpResp.StatusCode/100 != 2 && len(resp.Errors) == 0 {
+		if httpResp.StatusCode/100 == 2 {
+			return nil, fmt.Errorf("invalid graphql response with %s: %.1024s", httpResp.Status, respBody)
+		}
+		return nil, &HTTPError{StatusCode: httpResp.StatusCode, Status: httpResp.Status,
+			Body: fmt.Sprintf("%.1024s", respBody)}
+	}
+	for _, err := range resp.Errors {
+		shape(err)
+	}
+	return resp, nil
+}
+
+// shape sets the kind of err, an error of a response, from its code, so that errors.As tells the syntax and
+// validation errors apart, the execution errors being the ones having a path.
+func shape(err *errors.GraphQLError) {
+	switch errors.Code(err) {
+	case errors.CodeParseFailed:
+		err.Kind = errors.KindSyntax
+	case errors.CodeValidationFailed, errors.CodeBadUserInput:
+		if err.Path == nil {
+			err.Kind = errors.KindValidation
+		}
+	}
+}
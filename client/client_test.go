@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/client"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/handler"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	build := schemabuilder.NewSchema()
+	build.Object("User", user{}).FieldFunc("name", func(u user) string { return u.Name })
+	build.Query().FieldFunc("user", func(args struct {
+		Name string `graphql:"name"`
+	}) *user {
+		return &user{Name: args.Name}
+	})
+	build.Query().FieldFunc("fail", func() (*string, error) {
+		return nil, errors.New("not signed in").WithCode(errors.CodeUnauthenticated)
+	})
+	h := handler.New(build.MustBuild())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := newServer(t)
+	c := client.New(server.URL, client.Header("Authorization", "Bearer token"),
+		client.HTTPClient(&http.Client{Timeout: time.Minute}))
+	ctx := context.Background()
+
+	var out struct {
+		User user `json:"user"`
+	}
+	err := c.Do(ctx, `query($name: String!) { user(name: $name) { name } }`, map[string]interface{}{"name": "Ada"},
+		&out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ada", out.User.Name)
+
+	// the partial data is decoded along with the errors
+	var partial struct {
+		User *user   `json:"user"`
+		Fail *string `json:"fail"`
+	}
+	err = c.Do(ctx, `{ user(name: "Bob") { name } fail }`, nil, &partial)
+	var errs errors.MultiError
+	if assert.True(t, stderrors.As(err, &errs)) && assert.Len(t, errs, 1) {
+		assert.Equal(t, "not signed in", errs[0].Message)
+		assert.Equal(t, []interface{}{"fail"}, errs[0].Path)
+		assert.Equal(t, errors.CodeUnauthenticated, errors.Code(errs[0]))
+	}
+	var executionErr *errors.ExecutionError
+	assert.True(t, stderrors.As(err, &executionErr))
+	assert.Equal(t, "Bob", partial.User.Name)
+	assert.Nil(t, partial.Fail)
+
+	err = c.Do(ctx, `{ unknown }`, nil, nil)
+	var validationErr *errors.ValidationError
+	assert.True(t, stderrors.As(err, &validationErr))
+	err = c.Do(ctx, `{ user(`, nil, nil)
+	var syntaxErr *errors.SyntaxError
+	assert.True(t, stderrors.As(err, &syntaxErr))
+
+	resp, err := c.Send(ctx, &client.Request{Query: `query A { a: user(name: "A") { name } } query B { fail }`,
+		OperationName: "A"})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"a": {"name": "A"}}`, string(resp.Data))
+}
+
+func TestClient_HTTPError(t *testing.T) {
+	server := newServer(t)
+	err := client.New(server.URL).Do(context.Background(), `{ fail }`, nil, nil)
+	var httpErr *client.HTTPError
+	if assert.True(t, stderrors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+		assert.EqualError(t, err, "graphql request rejected with 401 Unauthorized: unauthorized\n")
+	}
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	defer ok.Close()
+	err = client.New(ok.URL).Do(context.Background(), `{ fail }`, nil, nil)
+	assert.EqualError(t, err, "invalid graphql response with 200 OK: <html>")
+}
@@ -0,0 +1,179 @@
+// Package clock provides the time and randomness sources of the time dependent parts of graphql,
+// such as latency logging, tracing and cache expiry. Tests replace them with a Mock and a seeded Rand
+// to run deterministically, without sleeping.
+package clock
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the Clock of the time package.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithTimeout is context.WithTimeout with the timeout measured by c, the context returned being done once
+// c.After(timeout) fires, eg. once a Mock is moved forward by timeout. A nil c is Real.
+func WithTimeout(parent context.Context, c Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if c == nil || c == Real {
+		return context.WithTimeout(parent, timeout)
+	}
+	ctx := &timeoutCtx{Context: parent, deadline: c.Now().Add(timeout), done: make(chan struct{})}
+	after, stop := c.After(timeout), make(chan struct{})
+	go func() {
+		select {
+		case <-after:
+			ctx.finish(context.DeadlineExceeded)
+		case <-parent.Done():
+			ctx.finish(parent.Err())
+		case <-stop:
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(stop) })
+		ctx.finish(context.Canceled)
+	}
+}
+
+type timeoutCtx struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (t *timeoutCtx) Deadline() (time.Time, bool) { return t.deadline, true }
+func (t *timeoutCtx) Done() <-chan struct{}       { return t.done }
+
+func (t *timeoutCtx) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *timeoutCtx) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = err
+		close(t.done)
+	}
+}
+
+// Mock is a Clock whose time only moves when it is told to.
+type Mock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	until time.Time
+	c     chan time.Time
+}
+
+// NewMock returns a Mock set to now.
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+func (m *Mock) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- m.now
+		return c
+	}
+	m.waiters = append(m.waiters, waiter{until: m.now.Add(d), c: c})
+	return c
+}
+
+// Add moves the time forward by d, and fires the After channels which are due, earliest first.
+func (m *Mock) Add(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set moves the time to now, and fires the After channels which are due, earliest first.
+func (m *Mock) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+	sort.SliceStable(m.waiters, func(i, j int) bool { return m.waiters[i].until.Before(m.waiters[j].until) })
+	var pending []waiter
+	for _, w := range m.waiters {
+		if w.until.After(now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- now
+	}
+	m.waiters = pending
+}
+
+// Rand is a source of randomness, *math/rand.Rand implements it.
+type Rand interface {
+	Int63() int64
+	Intn(n int) int
+	Float64() float64
+}
+
+// NewRand returns a Rand seeded with seed which is safe for concurrent use.
+func NewRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// DefaultRand is a Rand seeded from the current time.
+var DefaultRand = NewRand(time.Now().UnixNano())
+
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Int63() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63()
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
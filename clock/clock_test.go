@@ -0,0 +1,53 @@
+package clock_test
+
+import (
+	"context"
+	"github.com/shyptr/graphql/clock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := clock.NewMock(start)
+	assert.Equal(t, start, m.Now())
+
+	late, early := m.After(2*time.Second), m.After(time.Second)
+	m.Add(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-early)
+	select {
+	case <-late:
+		t.Fatal("fired too early")
+	default:
+	}
+	m.Add(time.Minute)
+	assert.Equal(t, start.Add(time.Minute+time.Second), <-late)
+	assert.Equal(t, time.Minute+time.Second, m.Since(start))
+}
+
+func TestNewRand(t *testing.T) {
+	a, b := clock.NewRand(42), clock.NewRand(42)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.Int63(), b.Int63())
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	m := clock.NewMock(time.Unix(0, 0))
+	ctx, cancel := clock.WithTimeout(context.Background(), m, time.Second)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1, 0), deadline)
+	assert.NoError(t, ctx.Err())
+
+	m.Add(time.Second)
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	ctx, cancel = clock.WithTimeout(context.Background(), m, time.Second)
+	cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/loader"
+	"github.com/shyptr/graphql/schemadoc"
+)
+
+// docs prints the reference documentation of the schema split in the files of args, see schemadoc, as Markdown or
+// with -html as an HTML page, to stdout or with -o to a file.
+func docs(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	html := flags.Bool("html", false, "")
+	var opts schemadoc.Options
+	flags.StringVar(&opts.Title, "title", "", "")
+	output := flags.String("o", "", "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+	files, err := loader.Read(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	doc, err := files.Schema()
+	if err != nil {
+		return printErrors(stdout, err)
+	}
+	schema, err := introspection.BuildASTSchema(doc)
+	if errs, ok := err.(errors.MultiError); ok {
+		return printErrors(stdout, files.Errors(errs))
+	}
+	if err != nil {
+		return err
+	}
+
+	page := schemadoc.Markdown(schema, opts)
+	if *html {
+		page = schemadoc.HTML(schema, opts)
+	}
+	if *output == "" {
+		_, err := fmt.Fprint(stdout, page)
+		return err
+	}
+	return os.WriteFile(*output, []byte(page), 0644)
+}
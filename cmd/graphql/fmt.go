@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/shyptr/graphql/format"
+)
+
+// formatFiles formats the files of args with format.Format, printing them to stdout, or with -w writing them back
+// in place. With -l it lists the files which are not formatted and fails when there are some, eg. in a CI.
+func formatFiles(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	write := flags.Bool("w", false, "")
+	list := flags.Bool("l", false, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+
+	failed := false
+	for _, pattern := range flags.Args() {
+		names, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no file matches %s", pattern)
+		}
+		for _, name := range names {
+			src, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			formatted, err := format.Format(string(src))
+			if err != nil {
+				if err := printErrors(stdout, fileErrors(name, err)); err != errFailed {
+					return err
+				}
+				failed = true
+				continue
+			}
+			switch {
+			case *list:
+				if formatted != string(src) {
+					failed = true
+					fmt.Fprintln(stdout, name)
+				}
+			case *write:
+				if formatted != string(src) {
+					if err := os.WriteFile(name, []byte(formatted), 0644); err != nil {
+						return err
+					}
+				}
+			default:
+				io.WriteString(stdout, formatted)
+			}
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
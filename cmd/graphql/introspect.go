@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shyptr/graphql/introspection"
+)
+
+// headers are the values of the -header flags, eg. "Authorization: Bearer token".
+type headers http.Header
+
+func (h headers) String() string {
+	return ""
+}
+
+func (h headers) Set(value string) error {
+	name, v, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, expected name: value", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
+
+// introspect runs introspection.IntrospectionQuery against the endpoint of args and prints the schema of its result
+// in the schema definition language, to stdout or with -o to a file. The flags may follow the endpoint.
+func introspect(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("introspect", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	header := make(headers)
+	flags.Var(header, "header", "")
+	output := flags.String("o", "", "")
+	timeout := flags.Duration("timeout", 30*time.Second, "")
+	endpoints, err := parseInterspersed(flags, args)
+	if err != nil || len(endpoints) != 1 {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	result, err := postIntrospectionQuery(ctx, endpoints[0], http.Header(header))
+	if err != nil {
+		return err
+	}
+	sdl, err := introspection.PrintIntrospectionSchema(result)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := io.WriteString(stdout, sdl)
+		return err
+	}
+	return os.WriteFile(*output, []byte(sdl), 0644)
+}
+
+// parseInterspersed parses the flags of args, which may follow the other arguments, and returns the other arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// postIntrospectionQuery posts introspection.IntrospectionQuery to endpoint and returns the JSON of the response,
+// an error when the response has errors, eg. when the introspection is disabled.
+func postIntrospectionQuery(ctx context.Context, endpoint string, header http.Header) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"query":         introspection.IntrospectionQuery,
+		"operationName": "IntrospectionQuery",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return nil, fmt.Errorf("%s: invalid response with %s: %.1024s", endpoint, resp.Status, result)
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, err := range response.Errors {
+			messages[i] = err.Message
+		}
+		return nil, fmt.Errorf("%s: %s", endpoint, strings.Join(messages, " "))
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s: introspection rejected with %s", endpoint, resp.Status)
+	}
+	return result, nil
+}
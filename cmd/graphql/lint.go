@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/loader"
+	"github.com/shyptr/graphql/validation"
+)
+
+// lint validates the operations of the files of args, eg. the directories of a frontend, against the schema of the
+// -schema files. The fragments may be defined in any file, every operation is validated with the fragments of all
+// of them. The uses of deprecated fields, arguments and enum values are printed as warnings, as errors with
+// -deprecated, and the operations may be limited by -max-depth and -max-complexity, see validation.MaxComplexity.
+func lint(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	schemaPatterns := flags.String("schema", "", "")
+	deprecated := flags.Bool("deprecated", false, "")
+	maxDepth := flags.Int("max-depth", 0, "")
+	maxComplexity := flags.Int("max-complexity", 0, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || *schemaPatterns == "" {
+		return errUsage
+	}
+
+	schemaFiles, err := loader.Read(strings.Fields(*schemaPatterns)...)
+	if err != nil {
+		return err
+	}
+	sdl, err := schemaFiles.Schema()
+	if err != nil {
+		return printErrors(stdout, err)
+	}
+	schema, err := introspection.BuildASTSchema(sdl)
+	if errs, ok := err.(errors.MultiError); ok {
+		return printErrors(stdout, schemaFiles.Errors(errs))
+	}
+	if err != nil {
+		return err
+	}
+
+	files, err := loader.Read(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	doc, err := files.Parse()
+	if err != nil {
+		return printErrors(stdout, err)
+	}
+
+	all := &internal.Document{}
+	// operations are the operations of every file, by its name
+	operations := make(map[string][]*ast.OperationDefinition)
+	for _, definition := range doc.Definition {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			all.Operations = append(all.Operations, definition)
+			file := files.Position(definition.Loc).File
+			operations[file] = append(operations[file], definition)
+		case *ast.FragmentDefinition:
+			all.Fragments = append(all.Fragments, definition)
+		default:
+			return fmt.Errorf("%s: %s is not an executable definition", files.Position(definition.Location()),
+				definition.GetKind())
+		}
+	}
+
+	rules := append([]validation.Rule{validation.MaxDepth(*maxDepth), validation.MaxComplexity(*maxComplexity)},
+		validation.SpecifiedRules...)
+	var errs, warnings errors.MultiError
+	seen := make(map[string]bool)
+	report := func(reported errors.MultiError, to *errors.MultiError) {
+		for _, err := range reported {
+			if key := fmt.Sprint(err.Message, err.Locations); !seen[key] {
+				seen[key] = true
+				*to = append(*to, err)
+			}
+		}
+	}
+	// the documents of the files share all the fragments, whether their own operations use them is checked with
+	// all the operations
+	for _, name := range files.Names() {
+		fileDoc := &internal.Document{Operations: operations[name], Fragments: all.Fragments}
+		var fileErrs errors.MultiError
+		for _, err := range validation.ValidateWithRules(schema, fileDoc, rules) {
+			if err.Rule != "NoUnusedFragments" {
+				fileErrs = append(fileErrs, err)
+			}
+		}
+		report(fileErrs, &errs)
+	}
+	report(validation.ValidateWithRules(schema, all, []validation.Rule{validation.UniqueOperationNames,
+		validation.NoUnusedFragments}), &errs)
+	if *deprecated {
+		report(validation.ValidateWithRules(schema, all, []validation.Rule{validation.NoDeprecated}), &errs)
+	} else {
+		report(validation.ValidateWithRules(schema, all, []validation.Rule{validation.NoDeprecated}), &warnings)
+		for _, warning := range warnings {
+			warning.Message = "warning: " + warning.Message
+		}
+	}
+
+	printErrors(stdout, files.Errors(append(errs, warnings...)))
+	if len(errs) > 0 {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Command graphql checks the GraphQL schemas of a repository, eg. in its pre-commit hooks:
+//
+//	graphql schema validate ./schema/*.graphql
+//	graphql schema lint -config lint.json ./schema/*.graphql
+//	graphql schema check -registry hive ./schema/*.graphql
+//	graphql fmt -l ./schema/*.graphql ./queries/*.graphql
+//	graphql lint -schema './schema/*.graphql' -max-depth 10 ./src
+//	graphql docs -html -o docs/index.html ./schema/*.graphql
+//	graphql introspect https://api.example.com/graphql -header 'Authorization: Bearer token' -o schema.graphql
+//
+// The errors are printed as file:line:column: message and the command exits with 1 when there are some.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `usage: graphql <command> [arguments]
+
+commands:
+	schema validate schema.graphql...	check the SDL of the schema split in the files
+	schema lint [-config lint.json] [-json] schema.graphql...
+						check the style of the schema, eg. its descriptions and its names
+	schema check [-registry hive|apollo] [-endpoint url] [-graph ref] schema.graphql...
+						print the changes of the schema from the version of the registry
+	schema publish [registry flags] [-author name] [-commit hash] [-force] schema.graphql...
+						publish the schema to the registry, unless it has breaking changes
+	fmt [-w] [-l] file.graphql...		format the documents, -w writes them back, -l lists the unformatted ones
+	lint -schema 'schema.graphql...' [-deprecated] [-max-depth n] [-max-complexity n] dir...
+						validate the operations of the .graphql and .gql files against the schema
+	docs [-html] [-title title] [-o file] schema.graphql...
+						print the reference documentation of the schema, as Markdown or HTML
+	introspect url [-header 'name: value']... [-timeout 30s] [-o schema.graphql]
+						print the schema of the endpoint, queried by introspection
+
+The registries are authenticated by the HIVE_TOKEN or the APOLLO_KEY environment variable.
+`
+
+// errUsage is returned for the unknown commands and the missing arguments.
+var errUsage = errors.New("usage")
+
+// errFailed is returned by the commands which printed their failures, eg. the errors of a schema.
+var errFailed = errors.New("failed")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	switch {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	case errors.Is(err, errFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, "graphql:", err)
+		os.Exit(1)
+	}
+}
+
+// commands are the commands by their name, of one or two words.
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"schema validate": schemaValidate,
+	"schema lint":     schemaLint,
+	"schema check":    schemaCheck,
+	"schema publish":  schemaPublish,
+	"fmt":             formatFiles,
+	"lint":            lint,
+	"introspect":      introspect,
+	"docs":            docs,
+}
+
+// run runs the command of args, printing its output to stdout.
+func run(args []string, stdout io.Writer) error {
+	for words := min(2, len(args)); words > 0; words-- {
+		if command, ok := commands[strings.Join(args[:words], " ")]; ok {
+			return command(args[words:], stdout)
+		}
+	}
+	return errUsage
+}
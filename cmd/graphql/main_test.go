@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shyptr/graphql/handler"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0644))
+	}
+	return dir
+}
+
+func TestSchemaValidate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  user: User\n}\n",
+		"b.graphql": "type User {\n  id: ID!\n}\n\ntype User {\n  name: Strin\n}\n",
+	})
+	var out strings.Builder
+	err := run([]string{"schema", "validate", filepath.Join(dir, "*.graphql")}, &out)
+	assert.Equal(t, errFailed, err)
+	b := filepath.Join(dir, "b.graphql")
+	assert.Equal(t, b+`:1:6: There can be only one type named "User".`+"\n\t"+b+":5:6\n"+
+		b+`:6:9: Unknown type "Strin". Did you mean "String"?`+"\n", out.String())
+
+	out.Reset()
+	err = run([]string{"schema", "validate", filepath.Join(dir, "a.graphql"), filepath.Join(dir, "b.graphql")}, &out)
+	assert.Equal(t, errFailed, err)
+
+	dir = writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  user: User\n}\n",
+		"b.graphql": "type User {\n  id: ID!\n",
+		"c.graphql": "type {",
+	})
+	out.Reset()
+	err = run([]string{"schema", "validate", filepath.Join(dir, "*.graphql")}, &out)
+	assert.Equal(t, errFailed, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], filepath.Join(dir, "b.graphql")+":3:1: "), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], filepath.Join(dir, "c.graphql")+":1:6: "), lines[1])
+	}
+
+	dir = writeFiles(t, map[string]string{"a.graphql": "type Query {\n  user: User\n}\n", "b.graphql": "type User {\n  id: ID!\n}"})
+	out.Reset()
+	assert.NoError(t, run([]string{"schema", "validate", filepath.Join(dir, "*.graphql")}, &out))
+	assert.Empty(t, out.String())
+
+	assert.Equal(t, errUsage, run([]string{"schema", "validate"}, &out))
+	assert.Equal(t, errUsage, run([]string{"schema"}, &out))
+}
+
+func TestSchemaLint(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "\"The root.\"\ntype Query {\n  \"The user.\"\n  user: User\n}\n",
+		"b.graphql": "type User {\n  id: ID!\n}\n\n\"A role.\"\nenum Role { admin }\n",
+		"lint.json": `{"rules": {"FieldDescriptions": "off"}}`,
+	})
+	b := filepath.Join(dir, "b.graphql")
+	var out strings.Builder
+	err := run([]string{"schema", "lint", "-config", filepath.Join(dir, "lint.json"), filepath.Join(dir, "*.graphql")},
+		&out)
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, b+":1:6: warning: The type User has no description. (TypeDescriptions)\n"+
+		b+":6:13: error: The enum value Role.admin is not SCREAMING_SNAKE_CASE. (EnumValuesScreamingSnakeCase)\n",
+		out.String())
+
+	out.Reset()
+	assert.NoError(t, run([]string{"schema", "lint", "-json", filepath.Join(dir, "a.graphql")}, &out))
+	assert.JSONEq(t, "[]", out.String())
+
+	out.Reset()
+	err = run([]string{"schema", "lint", "-json", b}, &out)
+	assert.Equal(t, errFailed, err)
+	assert.JSONEq(t, `[
+		{"file": "`+b+`", "line": 1, "column": 6, "severity": "warning", "rule": "TypeDescriptions",
+			"message": "The type User has no description."},
+		{"file": "`+b+`", "line": 2, "column": 3, "severity": "warning", "rule": "FieldDescriptions",
+			"message": "The field User.id has no description."},
+		{"file": "`+b+`", "line": 6, "column": 13, "severity": "error", "rule": "EnumValuesScreamingSnakeCase",
+			"message": "The enum value Role.admin is not SCREAMING_SNAKE_CASE."}
+	]`, out.String())
+
+	assert.Equal(t, errUsage, run([]string{"schema", "lint", "-config"}, &out))
+}
+
+func TestSchemaPublish(t *testing.T) {
+	published := "type Query {\n  user: User\n}\n\ntype User {\n  id: ID!\n  name: String\n}\n"
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var req struct {
+			Query     string
+			Variables map[string]map[string]string
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req.Query)
+		if strings.HasPrefix(req.Query, "mutation") {
+			published = req.Variables["input"]["sdl"]
+			w.Write([]byte(`{"data": {"schemaPublish": {"__typename": "SchemaPublishSuccess"}}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"latestValidVersion": map[string]string{"sdl": published},
+		}})
+	}))
+	defer server.Close()
+	t.Setenv("HIVE_TOKEN", "token")
+
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  user: User\n}\n",
+		"b.graphql": "type User {\n  id: ID!\n  email: String\n}\n",
+	})
+	schema := filepath.Join(dir, "*.graphql")
+	var out strings.Builder
+	assert.Equal(t, errFailed, run([]string{"schema", "check", "-endpoint", server.URL, schema}, &out))
+	assert.Equal(t, "breaking: Field User.name was removed.\nsafe: Field User.email was added.\n", out.String())
+
+	out.Reset()
+	assert.Equal(t, errFailed, run([]string{"schema", "publish", "-endpoint", server.URL, schema}, &out))
+	assert.Equal(t, "breaking: Field User.name was removed.\nsafe: Field User.email was added.\n"+
+		"the schema was not published, -force publishes it anyway\n", out.String())
+	assert.Len(t, requests, 2)
+
+	out.Reset()
+	assert.NoError(t, run([]string{"schema", "publish", "-endpoint", server.URL, "-force", schema}, &out))
+	assert.Len(t, requests, 4)
+	out.Reset()
+	assert.NoError(t, run([]string{"schema", "check", "-endpoint", server.URL, schema}, &out))
+	assert.Empty(t, out.String())
+
+	// the errors of the schema are told in its files
+	dir = writeFiles(t, map[string]string{"a.graphql": "type Query {\n  user: User\n}\n"})
+	out.Reset()
+	assert.Equal(t, errFailed, run([]string{"schema", "check", "-endpoint", server.URL, dir}, &out))
+	assert.Equal(t, filepath.Join(dir, "a.graphql")+`:2:9: Unknown type "User".`+"\n", out.String())
+
+	assert.Equal(t, errUsage, run([]string{"schema", "check", "-registry", "apollo", schema}, &out))
+}
+
+func TestFormat(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "type Query { a: Int }",
+		"b.graphql": "type Query {\n  a: Int\n}\n",
+		"c.graphql": "type Query {\n  a: Int # the a\n}\n",
+	})
+	var out strings.Builder
+	err := run([]string{"fmt", "-l", filepath.Join(dir, "*.graphql")}, &out)
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, filepath.Join(dir, "a.graphql")+"\n"+filepath.Join(dir, "c.graphql")+
+		":2:10: Cannot format the comments, which are not kept by the formatter.\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, run([]string{"fmt", "-w", filepath.Join(dir, "a.graphql")}, &out))
+	src, err := os.ReadFile(filepath.Join(dir, "a.graphql"))
+	assert.NoError(t, err)
+	assert.Equal(t, "type Query {\n  a: Int\n}\n", string(src))
+
+	assert.NoError(t, run([]string{"fmt", filepath.Join(dir, "a.graphql"), filepath.Join(dir, "b.graphql")}, &out))
+	assert.Equal(t, "type Query {\n  a: Int\n}\ntype Query {\n  a: Int\n}\n", out.String())
+}
+
+func TestLint(t *testing.T) {
+	schemaDir := writeFiles(t, map[string]string{
+		"schema.graphql": `type Query { hero: Hero search(text: String!, first: Int): [Hero!]! }
+type Hero { name: String! nickname: String @deprecated(reason: "Use name.") friends: [Hero!]! }`,
+	})
+	dir := writeFiles(t, map[string]string{
+		"fragments.graphql": "fragment names on Hero {\n  name\n  nickname\n}\n\nfragment unused on Hero {\n  name\n}\n",
+		"hero.graphql":      "query Hero {\n  hero {\n    ...names\n    friends { friends { name } }\n  }\n}\n",
+		"search.gql":        "query Search {\n  search(text: \"luke\", first: 50) { ...names villain }\n}\n",
+		"README.md":         "not a document",
+	})
+	schema := filepath.Join(schemaDir, "schema.graphql")
+	var out strings.Builder
+	err := run([]string{"lint", "-schema", schema, "-max-depth", "3", dir}, &out)
+	assert.Equal(t, errFailed, err)
+	fragments, hero, search := filepath.Join(dir, "fragments.graphql"), filepath.Join(dir, "hero.graphql"),
+		filepath.Join(dir, "search.gql")
+	assert.Equal(t, fragments+":3:3: warning: The field Hero.nickname is deprecated. Use name.\n"+
+		fragments+`:6:1: Fragment "unused" is never used.`+"\n"+
+		hero+`:1:1: Depth limit of 3 exceeded by operation "Hero", found 4.`+"\n"+
+		search+`:2:46: Cannot query field "villain" on type "Hero".`+"\n", out.String())
+
+	out.Reset()
+	err = run([]string{"lint", "-schema", schema, "-deprecated", "-max-complexity", "100", dir}, &out)
+	assert.Equal(t, errFailed, err)
+	assert.Contains(t, out.String(), fragments+":3:3: The field Hero.nickname is deprecated. Use name.\n")
+	assert.Contains(t, out.String(), search+`:1:1: Complexity limit of 100 exceeded by operation "Search", found 200.`)
+
+	assert.Equal(t, errUsage, run([]string{"lint", dir}, &out))
+}
+
+func TestIntrospect(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func(args struct {
+		Name *string `graphql:"name"`
+	}) string {
+		return "hello"
+	})
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	h := handler.New(schema)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Tenant") != "a: b" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors": [{"message": "Unauthorized"}]}`))
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	assert.NoError(t, run([]string{"introspect", server.URL, "-header", "Authorization: Bearer token",
+		"--header", "X-Tenant: a: b"}, &out))
+	assert.Equal(t, "type Query {\n  hello(name: String): String!\n}\n", out.String())
+
+	output := filepath.Join(t.TempDir(), "schema.graphql")
+	out.Reset()
+	assert.NoError(t, run([]string{"introspect", "-o", output, "-header", "Authorization: Bearer token",
+		"-header", "X-Tenant: a: b", server.URL}, &out))
+	assert.Empty(t, out.String())
+	b, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "type Query {\n  hello(name: String): String!\n}\n", string(b))
+
+	assert.EqualError(t, run([]string{"introspect", server.URL}, &out), server.URL+": Unauthorized")
+	assert.Equal(t, errUsage, run([]string{"introspect", server.URL, "-header", "Authorization"}, &out))
+	assert.Equal(t, errUsage, run([]string{"introspect"}, &out))
+}
+
+func TestDocs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  \"The user.\"\n  user: User\n}\n",
+		"b.graphql": "type User {\n  id: ID!\n}\n",
+	})
+	var out strings.Builder
+	assert.NoError(t, run([]string{"docs", "-title", "Users", filepath.Join(dir, "*.graphql")}, &out))
+	assert.True(t, strings.HasPrefix(out.String(), "# Users\n\n## Queries\n\n### Query.user\n\nThe user.\n"),
+		out.String())
+
+	output := filepath.Join(dir, "index.html")
+	out.Reset()
+	assert.NoError(t, run([]string{"docs", "-html", "-o", output, filepath.Join(dir, "*.graphql")}, &out))
+	assert.Empty(t, out.String())
+	b, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `<section id="user">`)
+
+	dir = writeFiles(t, map[string]string{"a.graphql": "type Query {\n  user: User\n}\n"})
+	out.Reset()
+	assert.Equal(t, errFailed, run([]string{"docs", dir}, &out))
+	assert.Equal(t, filepath.Join(dir, "a.graphql")+`:2:9: Unknown type "User".`+"\n", out.String())
+}
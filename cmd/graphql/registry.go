@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/loader"
+	"github.com/shyptr/graphql/registry"
+	"github.com/shyptr/graphql/schemadiff"
+)
+
+// registryFlags are the flags of the commands of a registry, which is authenticated by the HIVE_TOKEN or the
+// APOLLO_KEY environment variable.
+type registryFlags struct {
+	*flag.FlagSet
+	registry string
+	endpoint string
+	graphRef string
+}
+
+func newRegistryFlags(name string) *registryFlags {
+	f := &registryFlags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.SetOutput(io.Discard)
+	f.StringVar(&f.registry, "registry", "hive", "")
+	f.StringVar(&f.endpoint, "endpoint", "", "")
+	f.StringVar(&f.graphRef, "graph", os.Getenv("APOLLO_GRAPH_REF"), "")
+	return f
+}
+
+// open returns the registry of the flags and the SDL of the schema split in the files of the arguments.
+func (f *registryFlags) open(args []string) (registry.Registry, *loader.Files, string, error) {
+	if err := f.Parse(args); err != nil || f.NArg() == 0 {
+		return nil, nil, "", errUsage
+	}
+	var r registry.Registry
+	switch f.registry {
+	case "hive":
+		hive := registry.NewHive(os.Getenv("HIVE_TOKEN"))
+		if f.endpoint != "" {
+			hive.Endpoint = f.endpoint
+		}
+		r = hive
+	case "apollo":
+		if f.graphRef == "" {
+			return nil, nil, "", errUsage
+		}
+		apollo := registry.NewApollo(os.Getenv("APOLLO_KEY"), f.graphRef)
+		if f.endpoint != "" {
+			apollo.Endpoint = f.endpoint
+		}
+		r = apollo
+	default:
+		return nil, nil, "", errUsage
+	}
+	files, err := loader.Read(f.Args()...)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return r, files, files.Source(), nil
+}
+
+// schemaCheck prints the changes of the schema split in the files of args from the version published to the
+// registry, and fails when some of them are breaking.
+func schemaCheck(args []string, stdout io.Writer) error {
+	r, files, sdl, err := newRegistryFlags("schema check").open(args)
+	if err != nil {
+		return err
+	}
+	changes, err := registry.Check(context.Background(), r, sdl)
+	if err != nil {
+		return printRegistryError(stdout, files, err)
+	}
+	printChanges(stdout, changes)
+	if schemadiff.BreakingChanges(changes) != nil {
+		return errFailed
+	}
+	return nil
+}
+
+// schemaPublish publishes the schema split in the files of args to the registry, unless it has breaking changes
+// and -force is not set. The changes are printed.
+func schemaPublish(args []string, stdout io.Writer) error {
+	flags := newRegistryFlags("schema publish")
+	var metadata registry.Metadata
+	flags.StringVar(&metadata.Author, "author", "", "")
+	flags.StringVar(&metadata.Commit, "commit", "", "")
+	force := flags.Bool("force", false, "")
+	r, files, sdl, err := flags.open(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var changes []*schemadiff.Change
+	if *force {
+		if changes, err = registry.Check(ctx, r, sdl); err == nil {
+			err = r.Publish(ctx, sdl, metadata)
+		}
+	} else {
+		changes, err = registry.Publish(ctx, r, sdl, metadata)
+	}
+	printChanges(stdout, changes)
+	var breaking *registry.BreakingChangesError
+	if stderrors.As(err, &breaking) {
+		fmt.Fprintln(stdout, "the schema was not published, -force publishes it anyway")
+		return errFailed
+	}
+	if err != nil {
+		return printRegistryError(stdout, files, err)
+	}
+	return nil
+}
+
+func printChanges(w io.Writer, changes []*schemadiff.Change) {
+	for _, change := range changes {
+		fmt.Fprintln(w, change)
+	}
+}
+
+// printRegistryError prints the errors of the schema, at their positions in the files.
+func printRegistryError(w io.Writer, files *loader.Files, err error) error {
+	var errs errors.MultiError
+	if stderrors.As(err, &errs) {
+		return printErrors(w, files.Errors(errs))
+	}
+	return err
+}
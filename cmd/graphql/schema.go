@@ -0,0 +1,66 @@
+package main
+
+import (
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/loader"
+	"github.com/shyptr/graphql/validation"
+)
+
+// schemaValidate checks the SDL of the schema split in the files of args, see loader.Read, with
+// validation.ValidateSDL.
+func schemaValidate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("schema validate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+	files, err := loader.Read(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	doc, err := files.Parse()
+	if err != nil {
+		return printErrors(stdout, err)
+	}
+	if errs := validation.ValidateSDL(doc); errs != nil {
+		return printErrors(stdout, files.Errors(errs))
+	}
+	return nil
+}
+
+// printErrors prints the errors of err, a loader.ErrorList, as position: message followed by the other positions
+// of every error on lines of their own, and returns errFailed. The other errors are returned as they are.
+func printErrors(w io.Writer, err error) error {
+	var errs loader.ErrorList
+	if !stderrors.As(err, &errs) {
+		return err
+	}
+	for _, err := range errs {
+		fmt.Fprintln(w, err.Error())
+		for i := 1; i < len(err.Positions); i++ {
+			fmt.Fprintf(w, "\t%s\n", err.Positions[i])
+		}
+	}
+	return errFailed
+}
+
+// fileErrors returns the errors of err, a GraphQLError or a MultiError, at their locations in the file name.
+func fileErrors(name string, err error) error {
+	var errs errors.MultiError
+	var graphqlErr *errors.GraphQLError
+	switch {
+	case stderrors.As(err, &errs):
+	case stderrors.As(err, &graphqlErr):
+		errs = errors.MultiError{graphqlErr}
+	default:
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	files := &loader.Files{}
+	files.Add(name, "")
+	return files.Errors(errs)
+}
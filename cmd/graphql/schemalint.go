@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shyptr/graphql/loader"
+	"github.com/shyptr/graphql/schemalint"
+)
+
+// lintProblem is a problem of schemaLint in the JSON output, at its position in the files.
+type lintProblem struct {
+	loader.Position
+	Severity schemalint.Severity `json:"severity"`
+	Rule     string              `json:"rule"`
+	Message  string              `json:"message"`
+}
+
+// schemaLint checks the style of the schema split in the files of args with the schemalint.DefaultRules, the
+// severities of which are configured by the -config JSON file, eg. {"rules": {"TypeDescriptions": "off"}}. The
+// problems are printed as file:line:column: severity: message (rule), or as a JSON array with -json. The command
+// fails when there are errors, not when there are warnings only.
+func schemaLint(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("schema lint", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", "", "")
+	jsonOutput := flags.Bool("json", false, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+	var config schemalint.Config
+	if *configFile != "" {
+		b, err := os.ReadFile(*configFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &config); err != nil {
+			return fmt.Errorf("%s: %w", *configFile, err)
+		}
+	}
+
+	files, err := loader.Read(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	doc, err := files.Parse()
+	if err != nil {
+		return printErrors(stdout, err)
+	}
+	problems, err := schemalint.Lint(doc, config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configFile, err)
+	}
+
+	output := make([]lintProblem, len(problems))
+	failed := false
+	for i, problem := range problems {
+		output[i] = lintProblem{Severity: problem.Severity, Rule: problem.Rule, Message: problem.Message}
+		if len(problem.Locations) != 0 {
+			output[i].Position = files.Position(problem.Locations[0])
+		}
+		failed = failed || problem.Severity == schemalint.Error
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range output {
+			fmt.Fprintf(stdout, "%s: %s: %s (%s)\n", problem.Position, problem.Severity, problem.Message,
+				problem.Rule)
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Command graphqlgen generates the Go types and the resolver interfaces of a GraphQL schema, bound to a
+// schemabuilder.Schema by the generated Register function, see codegen.Generate:
+//
+//	graphqlgen -pkg starwars -out generated.go -scalar JSON=encoding/json.RawMessage ./schema/*.graphql
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shyptr/graphql/codegen"
+)
+
+// scalars are the Go types of the custom scalars, set by the repeated -scalar flag.
+type scalars map[string]string
+
+func (s scalars) String() string {
+	return fmt.Sprint(map[string]string(s))
+}
+
+func (s scalars) Set(value string) error {
+	name, goType, ok := strings.Cut(value, "=")
+	if !ok || name == "" || goType == "" {
+		return fmt.Errorf("%q is not Name=import/path.Type", value)
+	}
+	s[name] = goType
+	return nil
+}
+
+func main() {
+	config := codegen.Config{Scalars: scalars{}}
+	flag.StringVar(&config.Package, "pkg", "generated", "the package of the generated file")
+	out := flag.String("out", "", "the generated file, the standard output when empty")
+	flag.Var(scalars(config.Scalars), "scalar", "the Go type of a custom scalar, as Name=import/path.Type, repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: graphqlgen [flags] schema.graphql...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(config, *out, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "graphqlgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(config codegen.Config, out string, patterns []string) error {
+	var sdl strings.Builder
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no file matches %s", pattern)
+		}
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			sdl.Write(src)
+			sdl.WriteString("\n")
+		}
+	}
+	src, err := codegen.Generate(config, sdl.String())
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,276 @@
+// Package codegen generates Go source from a GraphQL schema definition (SDL).
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// GenerateEnums generates a Go type for every enum defined in sdl.
+//
+// Every generated type comes with one constant per enum value, String, IsValid,
+// MarshalGQL and UnmarshalGQL methods, and a Register function which registers
+// the enum on a schemabuilder.Schema. The Enum's Map and ReverseMap are built from
+// the generated constants, so the Go side can not drift from the SDL. The values
+// marked @deprecated are deprecated both in the Go source and in the registered enum.
+// The SDL whose names generate the same Go identifier, eg. FOO_BAR and FooBar, is rejected.
+//
+// For example:
+//
+//	enum Episode { NEW_HOPE EMPIRE }
+//
+// generates:
+//
+//	type Episode int
+//	const (
+//	  EpisodeNewHope Episode = iota
+//	  EpisodeEmpire
+//	)
+//	func RegisterEpisode(s *schemabuilder.Schema)
+func GenerateEnums(pkg string, sdl string) ([]byte, error) {
+	doc, err := internal.ParseDocument(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	enums := enumsOf(doc)
+	if len(enums) == 0 {
+		return nil, fmt.Errorf("no enum defined in schema")
+	}
+	if err := checkIdentifiers(enums); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := enumTemplate.Execute(&buf, map[string]interface{}{"Package": pkg, "Enums": enums}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// enumsOf returns the enums defined in doc.
+func enumsOf(doc *ast.Document) []*enumData {
+	var enums []*enumData
+	for _, definition := range doc.Definition {
+		definition, ok := definition.(*ast.EnumDefinition)
+		if !ok {
+			continue
+		}
+		enum := &enumData{
+			Name:   definition.Name.Name,
+			GoName: goName(definition.Name.Name),
+			Desc:   description(definition.Desc),
+		}
+		for _, value := range definition.Values {
+			data := &enumValueData{
+				Name:   value.Value.Value,
+				GoName: enum.GoName + goName(value.Value.Value),
+				Desc:   description(value.Desc),
+			}
+			data.Deprecated, data.DeprecationReason = deprecation(value.Directives)
+			enum.Annotated = enum.Annotated || data.Desc != "" || data.Deprecated
+			enum.Values = append(enum.Values, data)
+		}
+		enums = append(enums, enum)
+	}
+	return enums
+}
+
+type enumData struct {
+	Name   string
+	GoName string
+	Desc   string
+	Values []*enumValueData
+	// Annotated tells whether some values have a description or are deprecated
+	Annotated bool
+}
+
+type enumValueData struct {
+	Name              string
+	GoName            string
+	Desc              string
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// checkIdentifiers reports the go identifiers generated twice, eg. by the enum values FOO_BAR and FooBar.
+func checkIdentifiers(enums []*enumData) error {
+	generated := map[string]string{"RegisterEnums": "the function RegisterEnums"}
+	declare := func(identifier, origin string) error {
+		if previous, ok := generated[identifier]; ok {
+			return fmt.Errorf("%s and %s both generate the go identifier %s", previous, origin, identifier)
+		}
+		generated[identifier] = origin
+		return nil
+	}
+	for _, enum := range enums {
+		origin := "the enum " + enum.Name
+		for _, identifier := range []string{enum.GoName, unexported(enum.GoName) + "Names", "Register" + enum.GoName} {
+			if err := declare(identifier, origin); err != nil {
+				return err
+			}
+		}
+		for _, value := range enum.Values {
+			if err := declare(value.GoName, "the enum value "+enum.Name+"."+value.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deprecation returns whether directives deprecate an enum value, along with the reason given by @deprecated if any.
+func deprecation(directives []*ast.Directive) (bool, string) {
+	for _, directive := range directives {
+		if directive.Name.Name != "deprecated" {
+			continue
+		}
+		for _, arg := range directive.Args {
+			if reason, ok := arg.Value.(*ast.StringValue); ok && arg.Name.Name == "reason" {
+				return true, reason.Value
+			}
+		}
+		return true, ""
+	}
+	return false, ""
+}
+
+func description(desc *ast.StringValue) string {
+	if desc == nil {
+		return ""
+	}
+	return desc.Value
+}
+
+// goName turns a graphql name into an exported go identifier, eg. NEW_HOPE -> NewHope.
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		if strings.ToUpper(part) == part {
+			runes = []rune(strings.ToLower(part))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+func comment(s string) string {
+	return "// " + strings.Join(strings.Split(s, "\n"), "\n// ")
+}
+
+func unexported(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+var enumTemplate = template.Must(template.New("enum").Funcs(template.FuncMap{
+	"comment":    comment,
+	"unexported": unexported,
+	"deprecationReason": func(reason string) string {
+		if reason == "" {
+			return internal.DefaultDeprecationReason
+		}
+		return reason
+	},
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}).Parse(`{{define "enums"}}{{range .}}{{$enum := .}}
+{{if .Desc}}{{comment .Desc}}{{else}}// {{.GoName}} is the graphql enum {{.Name}}.{{end}}
+type {{.GoName}} int
+
+const (
+{{- range $i, $v := .Values}}
+	{{if $v.Desc}}{{comment $v.Desc}}
+	{{end}}{{if $v.Deprecated}}{{if $v.Desc}}//
+	{{end}}{{comment (print "Deprecated: " (deprecationReason $v.DeprecationReason))}}
+	{{end}}{{$v.GoName}}{{if eq $i 0}} {{$enum.GoName}} = iota{{end}}
+{{- end}}
+)
+
+var {{unexported .GoName}}Names = [...]string{
+{{- range .Values}}
+	{{.GoName}}: {{quote .Name}},
+{{- end}}
+}
+
+// String returns the graphql name of the enum value.
+func (e {{.GoName}}) String() string {
+	if !e.IsValid() {
+		return "{{.GoName}}(" + strconv.Itoa(int(e)) + ")"
+	}
+	return {{unexported .GoName}}Names[e]
+}
+
+// IsValid reports whether e is one of the values declared in the schema.
+func (e {{.GoName}}) IsValid() bool {
+	return e >= 0 && int(e) < len({{unexported .GoName}}Names)
+}
+
+// MarshalGQL writes the enum value as a quoted graphql enum name.
+func (e {{.GoName}}) MarshalGQL(w io.Writer) {
+	io.WriteString(w, strconv.Quote(e.String()))
+}
+
+// UnmarshalGQL reads the enum value from its graphql name.
+func (e *{{.GoName}}) UnmarshalGQL(v interface{}) error {
+	name, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enum {{.Name}} must be a string, got %T", v)
+	}
+	for i, n := range {{unexported .GoName}}Names {
+		if n == name {
+			*e = {{.GoName}}(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a valid {{.Name}}", name)
+}
+
+// Register{{.GoName}} registers {{.GoName}} as the graphql enum {{.Name}}.
+func Register{{.GoName}}(s *schemabuilder.Schema) {
+	{{if .Annotated}}enum := {{end}}schemabuilder.BindEnum(s, {{quote .Name}}, map[string]{{.GoName}}{
+	{{- range .Values}}
+		{{quote .Name}}: {{.GoName}},
+	{{- end}}
+	}, {{quote .Desc}})
+	{{- range .Values}}
+	{{- if .Desc}}
+	enum.Describe({{.GoName}}, {{quote .Desc}})
+	{{- end}}
+	{{- if .Deprecated}}
+	enum.Deprecate({{.GoName}}, {{quote .DeprecationReason}})
+	{{- end}}
+	{{- end}}
+}
+{{end}}
+// RegisterEnums registers every generated enum on the schema.
+func RegisterEnums(s *schemabuilder.Schema) {
+{{- range .}}
+	Register{{.GoName}}(s)
+{{- end}}
+}
+{{end}}// Code generated by github.com/shyptr/graphql/codegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/shyptr/graphql/schemabuilder"
+)
+{{template "enums" .Enums}}`))
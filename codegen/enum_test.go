@@ -0,0 +1,72 @@
+package codegen_test
+
+import (
+	"testing"
+
+	"github.com/shyptr/graphql/codegen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateEnums(t *testing.T) {
+	t.Run("generates constants and methods", func(t *testing.T) {
+		src, err := codegen.GenerateEnums("starwars", `
+			"""
+			One of the films in the Star Wars Trilogy
+			"""
+			enum Episode {
+				"Released in 1977."
+				NEW_HOPE
+				EMPIRE
+				JEDI @deprecated
+				"Released in 2015."
+				AWAKENS @deprecated(reason: "Not canon.")
+			}
+			type Query { hero(episode: Episode): String }
+		`)
+		assert.NoError(t, err)
+		code := string(src)
+		assert.Contains(t, code, "package starwars")
+		assert.Contains(t, code, "// One of the films in the Star Wars Trilogy\ntype Episode int")
+		assert.Contains(t, code, "// Released in 1977.\n\tEpisodeNewHope Episode = iota")
+		assert.Contains(t, code, "EpisodeEmpire\n")
+		assert.Contains(t, code, "// Deprecated: No longer supported\n\tEpisodeJedi\n")
+		assert.Contains(t, code, "// Released in 2015.\n\t//\n\t// Deprecated: Not canon.\n\tEpisodeAwakens\n")
+		assert.Contains(t, code, `EpisodeJedi:    "JEDI",`)
+		assert.Contains(t, code, "func (e Episode) String() string")
+		assert.Contains(t, code, "func (e Episode) IsValid() bool")
+		assert.Contains(t, code, "func (e Episode) MarshalGQL(w io.Writer)")
+		assert.Contains(t, code, "func (e *Episode) UnmarshalGQL(v interface{}) error")
+		assert.Contains(t, code, `enum := schemabuilder.BindEnum(s, "Episode", map[string]Episode{`)
+		assert.Contains(t, code, `"NEW_HOPE": EpisodeNewHope,`)
+		assert.Contains(t, code, `enum.Describe(EpisodeNewHope, "Released in 1977.")`)
+		assert.Contains(t, code, `enum.Deprecate(EpisodeJedi, "")`)
+		assert.Contains(t, code, `enum.Deprecate(EpisodeAwakens, "Not canon.")`)
+		assert.Contains(t, code, "\tRegisterEpisode(s)\n")
+	})
+
+	t.Run("registers the enums without annotations", func(t *testing.T) {
+		src, err := codegen.GenerateEnums("starwars", `enum Episode { NEW_HOPE EMPIRE }`)
+		assert.NoError(t, err)
+		assert.Contains(t, string(src), "\tschemabuilder.BindEnum(s, \"Episode\", map[string]Episode{")
+		assert.NotContains(t, string(src), "enum :=")
+	})
+
+	t.Run("reports the go identifiers generated twice", func(t *testing.T) {
+		_, err := codegen.GenerateEnums("starwars", `enum Episode { FOO_BAR FooBar }`)
+		assert.EqualError(t, err, "the enum value Episode.FOO_BAR and the enum value Episode.FooBar both generate the go identifier EpisodeFooBar")
+		_, err = codegen.GenerateEnums("starwars", `enum Episode { NEW_HOPE } enum EpisodeNew { HOPE }`)
+		assert.EqualError(t, err, "the enum value Episode.NEW_HOPE and the enum value EpisodeNew.HOPE both generate the go identifier EpisodeNewHope")
+		_, err = codegen.GenerateEnums("starwars", `enum Enums { A }`)
+		assert.EqualError(t, err, "the function RegisterEnums and the enum Enums both generate the go identifier RegisterEnums")
+	})
+
+	t.Run("requires an enum", func(t *testing.T) {
+		_, err := codegen.GenerateEnums("starwars", `type Query { name: String }`)
+		assert.EqualError(t, err, "no enum defined in schema")
+	})
+
+	t.Run("reports syntax errors", func(t *testing.T) {
+		_, err := codegen.GenerateEnums("starwars", `enum Episode { true }`)
+		assert.EqualError(t, err, `[graphql: Syntax Error: Name "true" is reserved and cannot be used for an enum value. (1:21)]`)
+	})
+}
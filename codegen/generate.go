@@ -0,0 +1,613 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Config configures Generate.
+type Config struct {
+	// Package is the name of the package of the generated file.
+	Package string
+	// Scalars are the Go types of the custom scalars of the schema by scalar name, as an import path and a type
+	// name, eg. "encoding/json.RawMessage" or "github.com/google/uuid.UUID". They are registered on the schema
+	// with Schema.Scalar before Register is called.
+	Scalars map[string]string
+}
+
+// Generate generates the Go types of the schema defined in sdl, and the Register function binding them to a
+// schemabuilder.Schema with the resolvers of a ResolverRoot.
+//
+// Objects, input objects and unions are structs, interfaces and enums are the Go interfaces and constants of the
+// same name. The fields of the objects without arguments are struct fields, the fields with arguments and the
+// fields of the root types are resolved by the methods of a resolver interface, their arguments decoded into a
+// struct. The Go interfaces are nullable for the schemabuilder: the items of the lists of a non-null interface,
+// eg. [Character!], are nullable in the built schema. For example:
+//
+//	type Query { hero(episode: Episode): Character }
+//
+// generates:
+//
+//	type QueryHeroArgs struct {
+//		Episode *Episode `graphql:"episode"`
+//	}
+//
+//	type QueryResolver interface {
+//		Hero(ctx context.Context, args QueryHeroArgs) (Character, error)
+//	}
+//
+//	type ResolverRoot interface {
+//		Query() QueryResolver
+//	}
+//
+//	func Register(s *schemabuilder.Schema, r ResolverRoot)
+func Generate(config Config, sdl string) ([]byte, error) {
+	doc, errs := internal.ParseDocument(sdl)
+	if errs != nil {
+		return nil, errs
+	}
+	g := &generator{
+		config:  config,
+		kinds:   map[string]string{},
+		imports: map[string]bool{"context": true, "github.com/shyptr/graphql/schemabuilder": true},
+		roots: map[string]ast.OperationType{
+			"Query":        ast.Query,
+			"Mutation":     ast.Mutation,
+			"Subscription": ast.Subscription,
+		},
+	}
+	if err := g.collect(doc); err != nil {
+		return nil, err
+	}
+	data, err := g.generate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := generateTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type generator struct {
+	config Config
+	// kinds are the kinds of the types of the schema by name
+	kinds   map[string]string
+	imports map[string]bool
+	// roots are the names of the root types
+	roots     map[string]ast.OperationType
+	useBigInt bool
+}
+
+type generateData struct {
+	Package string
+	// StdImports are the imports of the standard library, Imports the other ones
+	StdImports []string
+	Imports    []string
+	Enums      []*enumData
+	Interfaces []*objectData
+	Objects    []*objectData
+	Inputs     []*objectData
+	Unions     []*unionData
+	// Resolvers are the objects having fields resolved by a resolver
+	Resolvers []*objectData
+	UseBigInt bool
+}
+
+type objectData struct {
+	Name   string
+	GoName string
+	Desc   string
+	// Root is the operation of a root type
+	Root ast.OperationType
+	// Fields are the struct fields, or the methods of an interface
+	Fields []*fieldData
+	// Resolved are the fields resolved by the resolver of the object
+	Resolved   []*fieldData
+	Interfaces []*objectData
+	OneOf      bool
+}
+
+type fieldData struct {
+	Name   string
+	GoName string
+	Desc   string
+	Type   string
+	// NonNull marks a non-null field whose Go type is nullable for the schemabuilder, eg. a slice
+	NonNull     bool
+	Deprecation *string
+	// Default is the Go expression of the default value of an input field
+	Default string
+	// ArgsType is the struct of the arguments of a resolved field
+	ArgsType string
+	Args     []*fieldData
+}
+
+type unionData struct {
+	Name    string
+	GoName  string
+	Desc    string
+	Members []*objectData
+}
+
+// builtinScalars are the Go types of the scalars the schemabuilder defines.
+var builtinScalars = map[string]string{
+	"Int":     "int",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "schemabuilder.Id",
+	"Int8":    "int8",
+	"Int16":   "int16",
+	"Int32":   "int32",
+	"Int64":   "int64",
+	"Uint":    "uint",
+	"Uint8":   "uint8",
+	"Uint16":  "uint16",
+	"Uint32":  "uint32",
+	"Uint64":  "uint64",
+	"Time":    "time.Time",
+	"BigInt":  "big.Int",
+}
+
+func (g *generator) collect(doc *ast.Document) error {
+	for name := range builtinScalars {
+		g.kinds[name] = "scalar"
+	}
+	for _, definition := range doc.Definition {
+		switch definition := definition.(type) {
+		case *ast.SchemaDefinition:
+			g.roots = map[string]ast.OperationType{}
+			for _, operation := range definition.OperationTypes {
+				g.roots[operation.Type.Name.Name] = operation.Operation
+			}
+		case *ast.ScalarDefinition:
+			g.kinds[definition.Name.Name] = "scalar"
+		case *ast.EnumDefinition:
+			g.kinds[definition.Name.Name] = "enum"
+		case *ast.ObjectDefinition:
+			g.kinds[definition.Name.Name] = "object"
+		case *ast.InterfaceDefinition:
+			g.kinds[definition.Name.Name] = "interface"
+		case *ast.UnionDefinition:
+			g.kinds[definition.Name.Name] = "union"
+		case *ast.InputObjectDefinition:
+			g.kinds[definition.Name.Name] = "input"
+		}
+	}
+	return nil
+}
+
+func (g *generator) generate(doc *ast.Document) (*generateData, error) {
+	data := &generateData{Package: g.config.Package, Enums: enumsOf(doc)}
+	if len(data.Enums) > 0 {
+		g.imports["fmt"], g.imports["io"], g.imports["strconv"] = true, true, true
+	}
+	interfaces := map[string]*objectData{}
+	for _, definition := range doc.Definition {
+		if definition, ok := definition.(*ast.InterfaceDefinition); ok {
+			iface := &objectData{Name: definition.Name.Name, GoName: goName(definition.Name.Name), Desc: description(definition.Desc)}
+			for _, field := range definition.Fields {
+				if len(field.Argument) > 0 {
+					return nil, fmt.Errorf("interface %s: field %s: the fields of the interfaces cannot have arguments", iface.Name, field.Name.Name)
+				}
+				f, err := g.field(field.Name.Name, field.Desc, field.Type, field.Directives)
+				if err != nil {
+					return nil, fmt.Errorf("interface %s: %w", iface.Name, err)
+				}
+				iface.Fields = append(iface.Fields, f)
+			}
+			interfaces[iface.Name] = iface
+			data.Interfaces = append(data.Interfaces, iface)
+		}
+	}
+	for _, definition := range doc.Definition {
+		switch definition := definition.(type) {
+		case *ast.ObjectDefinition:
+			object, err := g.object(definition, interfaces)
+			if err != nil {
+				return nil, err
+			}
+			if object.Root == "" {
+				data.Objects = append(data.Objects, object)
+			}
+			if len(object.Resolved) > 0 {
+				data.Resolvers = append(data.Resolvers, object)
+			}
+		case *ast.InputObjectDefinition:
+			input := &objectData{Name: definition.Name.Name, GoName: goName(definition.Name.Name), Desc: description(definition.Desc)}
+			for _, directive := range definition.Directives {
+				input.OneOf = input.OneOf || directive.Name.Name == "oneOf"
+			}
+			fields, err := g.inputFields(definition.InputFields)
+			if err != nil {
+				return nil, fmt.Errorf("input %s: %w", input.Name, err)
+			}
+			input.Fields = fields
+			data.Inputs = append(data.Inputs, input)
+		case *ast.UnionDefinition:
+			union := &unionData{Name: definition.Name.Name, GoName: goName(definition.Name.Name), Desc: description(definition.Desc)}
+			for _, member := range definition.Members {
+				if g.kinds[member.Name.Name] != "object" {
+					return nil, fmt.Errorf("union %s: member %s is not an object", union.Name, member.Name.Name)
+				}
+				union.Members = append(union.Members, &objectData{Name: member.Name.Name, GoName: goName(member.Name.Name)})
+			}
+			data.Unions = append(data.Unions, union)
+		}
+	}
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			data.Imports = append(data.Imports, path)
+		} else {
+			data.StdImports = append(data.StdImports, path)
+		}
+	}
+	sort.Strings(data.StdImports)
+	sort.Strings(data.Imports)
+	data.UseBigInt = g.useBigInt
+	return data, nil
+}
+
+func (g *generator) object(definition *ast.ObjectDefinition, interfaces map[string]*objectData) (*objectData, error) {
+	object := &objectData{
+		Name:   definition.Name.Name,
+		GoName: goName(definition.Name.Name),
+		Desc:   description(definition.Desc),
+		Root:   g.roots[definition.Name.Name],
+	}
+	for _, named := range definition.Interfaces {
+		iface, ok := interfaces[named.Name.Name]
+		if !ok {
+			return nil, fmt.Errorf("object %s: %s is not an interface", object.Name, named.Name.Name)
+		}
+		object.Interfaces = append(object.Interfaces, iface)
+	}
+	for _, field := range definition.Fields {
+		f, err := g.field(field.Name.Name, field.Desc, field.Type, field.Directives)
+		if err != nil {
+			return nil, fmt.Errorf("object %s: %w", object.Name, err)
+		}
+		if object.Root == "" && len(field.Argument) == 0 {
+			object.Fields = append(object.Fields, f)
+			continue
+		}
+		if len(field.Argument) > 0 {
+			f.ArgsType = object.GoName + f.GoName + "Args"
+			if f.Args, err = g.inputFields(field.Argument); err != nil {
+				return nil, fmt.Errorf("object %s: field %s: %w", object.Name, f.Name, err)
+			}
+		}
+		object.Resolved = append(object.Resolved, f)
+	}
+	for _, iface := range object.Interfaces {
+		for _, f := range iface.Fields {
+			if !hasField(object.Fields, f.Name) {
+				return nil, fmt.Errorf("object %s: field %s of interface %s must be a field without arguments", object.Name, f.Name, iface.Name)
+			}
+		}
+	}
+	return object, nil
+}
+
+func hasField(fields []*fieldData, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) inputFields(definitions []*ast.InputValueDefinition) ([]*fieldData, error) {
+	var fields []*fieldData
+	for _, definition := range definitions {
+		f, err := g.field(definition.Name.Name, definition.Desc, definition.Type, definition.Directives)
+		if err != nil {
+			return nil, err
+		}
+		if definition.DefaultValue != nil {
+			value, err := internal.ValueToJson(definition.DefaultValue, nil)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			f.Default = fmt.Sprintf("%#v", value)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (g *generator) field(name string, desc *ast.StringValue, typ ast.Type, directives []*ast.Directive) (*fieldData, error) {
+	goType, nonNull, err := g.goType(typ)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", name, err)
+	}
+	f := &fieldData{Name: name, GoName: goName(name), Desc: description(desc), Type: goType, NonNull: nonNull}
+	for _, directive := range directives {
+		if directive.Name.Name != "deprecated" {
+			continue
+		}
+		reason := internal.DefaultDeprecationReason
+		for _, arg := range directive.Args {
+			if value, ok := arg.Value.(*ast.StringValue); ok && arg.Name.Name == "reason" {
+				reason = value.Value
+			}
+		}
+		f.Deprecation = &reason
+	}
+	return f, nil
+}
+
+// goType returns the Go type of typ, and whether typ is non-null while its Go type is nullable for the
+// schemabuilder: the slices are nullable lists and the Go interfaces nullable interfaces.
+func (g *generator) goType(typ ast.Type) (string, bool, error) {
+	switch typ := typ.(type) {
+	case *ast.NonNull:
+		if list, ok := typ.Type.(*ast.List); ok {
+			elem, _, err := g.goType(list.Type)
+			return "[]" + elem, true, err
+		}
+		named, err := g.named(typ.Type.(*ast.Named))
+		return named, g.kinds[typ.Type.(*ast.Named).Name.Name] == "interface", err
+	case *ast.List:
+		elem, _, err := g.goType(typ.Type)
+		return "[]" + elem, false, err
+	case *ast.Named:
+		named, err := g.named(typ)
+		if err != nil || g.kinds[typ.Name.Name] == "interface" {
+			return named, false, err
+		}
+		return "*" + named, false, nil
+	}
+	return "", false, fmt.Errorf("unknown type %v", typ)
+}
+
+func (g *generator) named(typ *ast.Named) (string, error) {
+	name := typ.Name.Name
+	if goType, ok := builtinScalars[name]; ok {
+		switch name {
+		case "Time":
+			g.imports["time"] = true
+		case "BigInt":
+			g.imports["math/big"] = true
+			g.useBigInt = true
+		}
+		return goType, nil
+	}
+	if scalar, ok := g.config.Scalars[name]; ok {
+		dot := strings.LastIndex(scalar, ".")
+		if dot < 0 {
+			return scalar, nil
+		}
+		path := scalar[:dot]
+		g.imports[path] = true
+		return path[strings.LastIndex(path, "/")+1:] + scalar[dot:], nil
+	}
+	kind, ok := g.kinds[name]
+	if !ok {
+		return "", fmt.Errorf("unknown type %s", name)
+	}
+	if kind == "scalar" {
+		return "", fmt.Errorf("scalar %s has no Go type, see Config.Scalars", name)
+	}
+	if _, ok := g.roots[name]; ok {
+		return "", fmt.Errorf("root type %s cannot be the type of a field", name)
+	}
+	return goName(name), nil
+}
+
+// tag returns the graphql tag of a field, its description cannot hold the separators of the tag.
+func tag(f *fieldData) string {
+	desc := strings.NewReplacer(";", ",", "\n", " ", "`", "'", `"`, "'").Replace(f.Desc)
+	tag := f.Name
+	if desc != "" || f.NonNull {
+		tag += ";" + desc
+	}
+	if f.NonNull {
+		tag += ";nonnull"
+	}
+	value := fmt.Sprintf("graphql:%q", tag)
+	if f.Deprecation != nil {
+		value += fmt.Sprintf(" deprecated:%q", *f.Deprecation)
+	}
+	return "`" + value + "`"
+}
+
+// options returns the options of the FieldFunc of a resolved field.
+func options(f *fieldData) string {
+	options := fmt.Sprintf("%q", f.Desc)
+	if f.NonNull {
+		options += ", schemabuilder.NonNullField"
+	}
+	if f.Deprecation != nil {
+		options += fmt.Sprintf(", schemabuilder.Deprecated(%q)", *f.Deprecation)
+	}
+	return options
+}
+
+var generateTemplate = template.Must(template.Must(enumTemplate.Clone()).New("generate").Funcs(template.FuncMap{
+	"tag":     tag,
+	"options": options,
+	"root": func(operation ast.OperationType) string {
+		return goName(strings.ToLower(string(operation)))
+	},
+	"defaults": func(fields []*fieldData) bool {
+		for _, f := range fields {
+			if f.Default != "" {
+				return true
+			}
+		}
+		return false
+	},
+	"deprecated": func(fields []*fieldData) bool {
+		for _, f := range fields {
+			if f.Deprecation != nil {
+				return true
+			}
+		}
+		return false
+	},
+}).Parse(`// Code generated by github.com/shyptr/graphql/codegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .StdImports}}
+	{{quote .}}
+{{- end}}
+{{range .Imports}}
+	{{quote .}}
+{{- end}}
+)
+{{template "enums" .Enums}}
+{{- range .Interfaces}}
+{{if .Desc}}{{comment .Desc}}{{else}}// {{.GoName}} is the graphql interface {{.Name}}.{{end}}
+type {{.GoName}} interface {
+	Is{{.GoName}}()
+{{- range .Fields}}
+	Get{{.GoName}}() {{.Type}}
+{{- end}}
+}
+{{end}}
+{{- range .Objects}}{{$object := .}}
+{{if .Desc}}{{comment .Desc}}{{else}}// {{.GoName}} is the graphql object {{.Name}}.{{end}}
+type {{.GoName}} struct {
+{{- range .Fields}}
+	{{if .Desc}}{{comment .Desc}}
+	{{end}}{{.GoName}} {{.Type}} {{tag .}}
+{{- end}}
+}
+{{range .Interfaces}}
+func (*{{$object.GoName}}) Is{{.GoName}}() {}
+{{range .Fields}}
+func (o *{{$object.GoName}}) Get{{.GoName}}() {{.Type}} {
+	return o.{{.GoName}}
+}
+{{end}}{{end}}{{end}}
+{{- range .Inputs}}
+{{if .Desc}}{{comment .Desc}}{{else}}// {{.GoName}} is the graphql input {{.Name}}.{{end}}
+type {{.GoName}} struct {
+{{- range .Fields}}
+	{{if .Desc}}{{comment .Desc}}
+	{{end}}{{.GoName}} {{.Type}} {{tag .}}
+{{- end}}
+}
+{{end}}
+{{- range .Unions}}
+{{if .Desc}}{{comment .Desc}}{{else}}// {{.GoName}} is the graphql union {{.Name}}, one of its members is set.{{end}}
+type {{.GoName}} struct {
+{{- range .Members}}
+	{{.GoName}} *{{.GoName}} ` + "`" + `graphql:{{quote .Name}}` + "`" + `
+{{- end}}
+}
+{{end}}
+{{- range .Resolvers}}{{$object := .}}
+{{- range .Resolved}}{{if .Args}}
+// {{.ArgsType}} are the arguments of {{$object.Name}}.{{.Name}}.
+type {{.ArgsType}} struct {
+{{- range .Args}}
+	{{if .Desc}}{{comment .Desc}}
+	{{end}}{{.GoName}} {{.Type}} {{tag .}}
+{{- end}}
+}
+{{end}}{{end}}
+// {{.GoName}}Resolver resolves the fields of {{.Name}}.
+type {{.GoName}}Resolver interface {
+{{- range .Resolved}}
+	{{if .Desc}}{{comment .Desc}}
+	{{end}}{{.GoName}}(ctx context.Context{{if not $object.Root}}, obj *{{$object.GoName}}{{end}}{{if .Args}}, args {{.ArgsType}}{{end}}) ({{if eq $object.Root "SUBSCRIPTION"}}<-chan {{end}}{{.Type}}, error)
+{{- end}}
+}
+{{end}}
+// ResolverRoot returns the resolvers of the objects.
+type ResolverRoot interface {
+{{- range .Resolvers}}
+	{{.GoName}}() {{.GoName}}Resolver
+{{- end}}
+}
+
+// Register registers the types of the schema on s, their fields resolved by r. The custom scalars are registered
+// before.
+func Register(s *schemabuilder.Schema, r ResolverRoot) {
+{{- if .UseBigInt}}
+	s.UseScalar(schemabuilder.BigInt)
+{{- end}}
+{{- if .Enums}}
+	RegisterEnums(s)
+{{- end}}
+{{- range .Interfaces}}
+	{
+		iface := s.Interface({{quote .Name}}, new({{.GoName}}), func(v {{.GoName}}) {{.GoName}} { return v }, {{quote .Desc}})
+{{- range .Fields}}
+		iface.FieldFunc({{quote .Name}}, "Get{{.GoName}}", {{quote .Desc}})
+{{- end}}
+	}
+{{- end}}
+{{- range .Inputs}}
+{{- if or .OneOf (defaults .Fields)}}
+	{
+		input := s.InputObject({{quote .Name}}, {{.GoName}}{}, {{quote .Desc}})
+{{- if .OneOf}}
+		input.OneOf = true
+{{- end}}
+{{- range .Fields}}{{if .Default}}
+		input.FieldDefault({{quote .Name}}, {{.Default}})
+{{- end}}{{end}}
+	}
+{{- else}}
+	s.InputObject({{quote .Name}}, {{.GoName}}{}, {{quote .Desc}})
+{{- end}}
+{{- end}}
+{{- range .Resolvers}}{{$object := .}}{{range .Resolved}}{{if defaults .Args}}
+	{
+		args := s.InputObject({{quote .ArgsType}}, {{.ArgsType}}{})
+{{- range .Args}}{{if .Default}}
+		args.FieldDefault({{quote .Name}}, {{.Default}})
+{{- end}}{{end}}
+	}
+{{- end}}{{end}}{{end}}
+{{- range .Objects}}{{$object := .}}
+{{- if or .Interfaces .Resolved (deprecated .Fields)}}
+	{
+		object := s.Object({{quote .Name}}, {{.GoName}}{}, {{quote .Desc}})
+{{- range .Interfaces}}
+		object.InterfaceList(s.GetInterface({{quote .Name}}))
+{{- end}}
+{{- range .Fields}}{{if .Deprecation}}
+		object.FieldFunc({{quote .Name}}, func(obj *{{$object.GoName}}) {{.Type}} { return obj.{{.GoName}} }, {{options .}})
+{{- end}}{{end}}
+{{- range .Resolved}}
+		object.FieldFunc({{quote .Name}}, func(ctx context.Context, obj *{{$object.GoName}}{{if .Args}}, args {{.ArgsType}}{{end}}) ({{.Type}}, error) {
+			return r.{{$object.GoName}}().{{.GoName}}(ctx, obj{{if .Args}}, args{{end}})
+		}, {{options .}})
+{{- end}}
+	}
+{{- else}}
+	s.Object({{quote .Name}}, {{.GoName}}{}, {{quote .Desc}})
+{{- end}}
+{{- end}}
+{{- range .Unions}}
+	s.Union({{quote .Name}}, {{.GoName}}{}, {{quote .Desc}})
+{{- end}}
+{{- range .Resolvers}}{{$object := .}}{{if .Root}}
+	{
+		object := s.{{root .Root}}()
+{{- range .Resolved}}
+		object.FieldFunc({{quote .Name}}, func(ctx context.Context{{if .Args}}, args {{.ArgsType}}{{end}}) ({{if eq $object.Root "SUBSCRIPTION"}}<-chan {{end}}{{.Type}}, error) {
+			return r.{{$object.GoName}}().{{.GoName}}(ctx{{if .Args}}, args{{end}})
+		}, {{options .}})
+{{- end}}
+	}
+{{- end}}{{end}}
+}
+`))
@@ -0,0 +1,92 @@
+package codegen_test
+
+import (
+	"testing"
+
+	"github.com/shyptr/graphql/codegen"
+	"github.com/stretchr/testify/assert"
+)
+
+const starwarsSDL = `
+enum Episode { NEW_HOPE EMPIRE }
+
+scalar JSON
+
+"A character"
+interface Character {
+	id: ID!
+	name: String
+}
+
+type Human implements Character {
+	id: ID!
+	name: String
+	"The friends"
+	friends(first: Int = 10): [Character!]!
+	appearsIn: [Episode!]!
+	height: Float @deprecated(reason: "Use size.")
+	extra: JSON
+}
+
+type Droid implements Character {
+	id: ID!
+	name: String
+}
+
+union SearchResult = Human | Droid
+
+input CharacterBy @oneOf {
+	id: ID
+	name: String
+}
+
+type Query {
+	hero(episode: Episode): Character
+	search(text: String!): [SearchResult!]!
+	character(by: CharacterBy!): Character
+}
+
+type Subscription {
+	reviews: Int!
+}
+`
+
+func TestGenerate(t *testing.T) {
+	t.Run("generates the types and the resolvers", func(t *testing.T) {
+		src, err := codegen.Generate(codegen.Config{
+			Package: "starwars",
+			Scalars: map[string]string{"JSON": "encoding/json.RawMessage"},
+		}, starwarsSDL)
+		assert.NoError(t, err)
+		code := string(src)
+		assert.Contains(t, code, "package starwars")
+		assert.Contains(t, code, "\t\"encoding/json\"\n")
+		assert.Contains(t, code, "type Episode int")
+		assert.Contains(t, code, "// A character\ntype Character interface {\n\tIsCharacter()\n\tGetId() schemabuilder.Id\n\tGetName() *string\n}")
+		assert.Contains(t, code, "\tAppearsIn []Episode        `graphql:\"appearsIn;;nonnull\"`")
+		assert.Contains(t, code, "\tHeight    *float64         `graphql:\"height\" deprecated:\"Use size.\"`")
+		assert.Contains(t, code, "\tExtra     *json.RawMessage `graphql:\"extra\"`")
+		assert.Contains(t, code, "func (o *Human) GetName() *string {\n\treturn o.Name\n}")
+		assert.Contains(t, code, "type SearchResult struct {\n\tHuman *Human `graphql:\"Human\"`\n\tDroid *Droid `graphql:\"Droid\"`\n}")
+		assert.Contains(t, code, "type HumanFriendsArgs struct {\n\tFirst *int `graphql:\"first\"`\n}")
+		assert.Contains(t, code, "\tFriends(ctx context.Context, obj *Human, args HumanFriendsArgs) ([]Character, error)")
+		assert.Contains(t, code, "\tHero(ctx context.Context, args QueryHeroArgs) (Character, error)")
+		assert.Contains(t, code, "\tReviews(ctx context.Context) (<-chan int, error)")
+		assert.Contains(t, code, "type ResolverRoot interface {\n\tHuman() HumanResolver\n\tQuery() QueryResolver\n\tSubscription() SubscriptionResolver\n}")
+		assert.Contains(t, code, "\t\targs.FieldDefault(\"first\", 10)\n")
+		assert.Contains(t, code, "\t\tinput.OneOf = true\n")
+		assert.Contains(t, code, "\t\tobject.InterfaceList(s.GetInterface(\"Character\"))\n")
+		assert.Contains(t, code, "}, \"The friends\", schemabuilder.NonNullField)")
+		assert.Contains(t, code, "\ts.Union(\"SearchResult\", SearchResult{}, \"\")\n")
+	})
+
+	t.Run("requires the Go types of the custom scalars", func(t *testing.T) {
+		_, err := codegen.Generate(codegen.Config{Package: "starwars"}, starwarsSDL)
+		assert.EqualError(t, err, "object Human: field extra: scalar JSON has no Go type, see Config.Scalars")
+	})
+
+	t.Run("reports the unknown types", func(t *testing.T) {
+		_, err := codegen.Generate(codegen.Config{Package: "starwars"}, `type Query { hero: Hero }`)
+		assert.EqualError(t, err, "object Query: field hero: unknown type Hero")
+	})
+}
@@ -3,7 +3,9 @@ package graphql
 import (
 	"context"
 	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/clock"
 	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
 	"log"
 	"net"
 	"net/http"
@@ -22,6 +24,7 @@ type Context struct {
 	// keys is a key/value pair exclusively for the Context of each request.
 	keys                  map[interface{}]interface{}
 	MaxDepth              int
+	Introspection         execution.IntrospectionPolicy
 	Logger                *log.Logger
 	useStringDescriptions bool
 	HandlersChain         []HandlerFunc
@@ -29,6 +32,7 @@ type Context struct {
 	index                 int8
 	OperationName         string
 	Method                ast.OperationType
+	Clock                 clock.Clock
 }
 
 var Ctx = &Context{
@@ -41,29 +45,56 @@ var Ctx = &Context{
 	HandlersChain:         []HandlerFunc{},
 	Error:                 nil,
 	index:                 -1,
+	Clock:                 clock.Real,
 }
 
+type contextKey struct{}
+
+// GetContext returns the Context of the request, ctx may be a context derived from it.
 func GetContext(ctx context.Context) *Context {
-	return ctx.(*Context)
+	if c, ok := ctx.(*Context); ok {
+		return c
+	}
+	c, _ := ctx.Value(contextKey{}).(*Context)
+	return c
 }
 
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.Request != nil {
+		return c.Request.Context().Deadline()
+	}
 	return
 }
 
+// Done is closed when the request is cancelled, eg. the client went away.
 func (c *Context) Done() <-chan struct{} {
+	if c.Request != nil {
+		return c.Request.Context().Done()
+	}
 	return nil
 }
 
 func (c *Context) Err() error {
 	if len(c.Error) == 0 {
+		if c.Request != nil {
+			return c.Request.Context().Err()
+		}
 		return nil
 	}
 	return c.Error
 }
 
 func (c *Context) Value(key interface{}) interface{} {
-	return c.keys[key]
+	if key == (contextKey{}) {
+		return c
+	}
+	if value, ok := c.keys[key]; ok {
+		return value
+	}
+	if c.Request != nil {
+		return c.Request.Context().Value(key)
+	}
+	return nil
 }
 
 func (c *Context) Set(key, value interface{}) {
@@ -78,16 +109,28 @@ func UseStringDescriptions() {
 	Ctx.useStringDescriptions = true
 }
 
-// MaxDepth specifies the maximum field nesting depth in a query. The default is 0 which disables max depth checking.
+// MaxDepth specifies the maximum field nesting depth in a query, fragments included. 0 disables max depth checking.
 func MaxDepth(n int) {
 	Ctx.MaxDepth = n
 }
 
+// Introspection restricts the introspection of the schema to the requests allowed by policy, eg. the requests
+// of authenticated users, execution.DisableIntrospection disables it.
+func Introspection(policy execution.IntrospectionPolicy) {
+	Ctx.Introspection = policy
+}
+
 // Logger is used to log panics during query execution. It defaults to exec.DefaultLogger.
 func SetLogger(logger *log.Logger) {
 	Ctx.Logger = logger
 }
 
+// SetClock replaces the clock used to measure and timestamp requests, eg. with a clock.Mock in tests. It also
+// measures the timeouts of the operations of the handlers whose executor has no clock.
+func SetClock(c clock.Clock) {
+	Ctx.Clock = c
+}
+
 func (ctx *Context) Next() {
 	ctx.index++
 	if ctx.index < int8(len(ctx.HandlersChain)) {
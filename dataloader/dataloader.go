@@ -0,0 +1,190 @@
+// Package dataloader batches and caches keyed lookups of resolvers, to avoid N+1 queries.
+//
+// Load does not fetch anything, it queues the key and returns a Thunk. A schemabuilder resolver
+// returning the thunk (or any func() (T, error)) defers the field: the executor keeps resolving
+// the rest of the level, then calls the thunks, and the first call fetches all the queued keys
+// with a single call of the BatchFunc.
+//
+// A Loader caches what it has loaded, so it should live as long as one request. Create it
+// lazily with execution.Memo to share it between the resolvers of an operation:
+//
+//	type userLoaderKey struct{}
+//
+//	func userLoader(ctx context.Context) *dataloader.Loader[int64, *User] {
+//		loader, _ := execution.Memo(ctx, userLoaderKey{}, func() (*dataloader.Loader[int64, *User], error) {
+//			return dataloader.New(fetchUsers), nil
+//		})
+//		return loader
+//	}
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchFunc fetches the values of keys. The keys missing from the returned map get the zero value of V,
+// an error fails every key of the batch.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Thunk returns the loaded value, fetching the pending batch on the first call.
+type Thunk[V any] func() (V, error)
+
+type Option func(*options)
+
+type options struct {
+	maxBatch int
+}
+
+// MaxBatch limits the number of keys passed to one call of the BatchFunc, zero means no limit.
+func MaxBatch(n int) Option {
+	return func(o *options) {
+		o.maxBatch = n
+	}
+}
+
+// Loader loads values of type V by keys of type K, it is safe for concurrent use.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+	options
+
+	mu      sync.Mutex
+	cache   map[K]*result[K, V]
+	pending *batch[K, V]
+}
+
+type result[K comparable, V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// batch is the batch fetching the value, nil for primed values
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	results []*result[K, V]
+	once    sync.Once
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	l := &Loader[K, V]{fetch: fetch, cache: make(map[K]*result[K, V])}
+	for _, opt := range opts {
+		opt(&l.options)
+	}
+	return l
+}
+
+// Load queues key into the pending batch, unless it has been loaded or queued already.
+// The batch is fetched with the context of its first key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk[V] {
+	l.mu.Lock()
+	if r, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return l.thunk(r)
+	}
+	if l.pending == nil {
+		l.pending = &batch[K, V]{ctx: ctx}
+	}
+	b := l.pending
+	r := &result[K, V]{done: make(chan struct{}), batch: b}
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	l.cache[key] = r
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+	return l.thunk(r)
+}
+
+// LoadMany is like Load for several keys, the values are returned in the order of keys.
+// The first error fails the whole thunk.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) Thunk[[]V] {
+	thunks := make([]Thunk[V], len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(ctx, key)
+	}
+	return func() ([]V, error) {
+		values := make([]V, len(thunks))
+		for i, thunk := range thunks {
+			value, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+}
+
+// Prime adds the value of key to the cache, it does nothing when the key has been loaded or queued already.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	r := &result[K, V]{done: make(chan struct{}), value: value}
+	close(r.done)
+	l.cache[key] = r
+}
+
+// Clear removes key from the cache, the next Load of it fetches it again.
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+// ClearAll empties the cache.
+func (l *Loader[K, V]) ClearAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cache = make(map[K]*result[K, V])
+}
+
+func (l *Loader[K, V]) thunk(r *result[K, V]) Thunk[V] {
+	return func() (V, error) {
+		select {
+		case <-r.done:
+		default:
+			l.dispatch(r.batch)
+			<-r.done
+		}
+		return r.value, r.err
+	}
+}
+
+// dispatch fetches the batch b, once.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		l.mu.Unlock()
+
+		values, err := l.call(b)
+		for i, key := range b.keys {
+			r := b.results[i]
+			if err != nil {
+				r.err = err
+			} else {
+				r.value = values[key]
+			}
+			close(r.done)
+		}
+	})
+}
+
+func (l *Loader[K, V]) call(b *batch[K, V]) (values map[K]V, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			values, err = nil, fmt.Errorf("dataloader: panic: %v", panicErr)
+		}
+	}()
+	return l.fetch(b.ctx, b.keys)
+}
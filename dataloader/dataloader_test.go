@@ -0,0 +1,121 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shyptr/graphql/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	var batches [][]int
+	loader := dataloader.New(func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int]string, len(keys))
+		for _, key := range keys {
+			if key > 0 {
+				values[key] = string(rune('a' + key - 1))
+			}
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+
+	a := loader.Load(ctx, 1)
+	b := loader.Load(ctx, 2)
+	again := loader.Load(ctx, 1)
+	missing := loader.Load(ctx, -1)
+	assert.Empty(t, batches, "nothing is loaded before a thunk is called")
+
+	value, err := b()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+	value, _ = a()
+	assert.Equal(t, "a", value)
+	value, _ = again()
+	assert.Equal(t, "a", value)
+	value, err = missing()
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+	assert.Equal(t, [][]int{{1, 2, -1}}, batches)
+
+	values, err := loader.LoadMany(ctx, []int{2, 3})()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, values)
+	assert.Equal(t, [][]int{{1, 2, -1}, {3}}, batches)
+
+	loader.Prime(4, "primed")
+	loader.Clear(1)
+	value, _ = loader.Load(ctx, 4)()
+	assert.Equal(t, "primed", value)
+	value, _ = loader.Load(ctx, 1)()
+	assert.Equal(t, "a", value)
+	assert.Equal(t, [][]int{{1, 2, -1}, {3}, {1}}, batches)
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	var batches [][]int
+	loader := dataloader.New(func(ctx context.Context, keys []int) (map[int]int, error) {
+		batches = append(batches, keys)
+		return nil, nil
+	}, dataloader.MaxBatch(2))
+
+	values, err := loader.LoadMany(context.Background(), []int{1, 2, 3, 4, 5})()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0, 0, 0}, values)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+}
+
+func TestLoader_Error(t *testing.T) {
+	loader := dataloader.New(func(ctx context.Context, keys []int) (map[int]int, error) {
+		if len(keys) > 1 {
+			return nil, errors.New("boom")
+		}
+		panic("panicked")
+	})
+	ctx := context.Background()
+
+	a, b := loader.Load(ctx, 1), loader.Load(ctx, 2)
+	_, err := a()
+	assert.EqualError(t, err, "boom")
+	_, err = b()
+	assert.EqualError(t, err, "boom")
+
+	_, err = loader.Load(ctx, 3)()
+	assert.EqualError(t, err, "dataloader: panic: panicked")
+}
+
+func TestLoader_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	loader := dataloader.New(func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			values[key] = key * 10
+		}
+		return values, nil
+	})
+	thunks := make([]dataloader.Thunk[int], 10)
+	for i := range thunks {
+		thunks[i] = loader.Load(context.Background(), i)
+	}
+
+	var wg sync.WaitGroup
+	for i, thunk := range thunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := thunk()
+			assert.NoError(t, err)
+			assert.Equal(t, i*10, value)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+}
@@ -1,6 +1,24 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// The codes of the errors commonly told apart by the clients, set in the code extension, see WithCode. They are
+// the ones of Apollo Server: the library sets CodeParseFailed on the syntax errors, CodeValidationFailed on the
+// validation errors, CodeBadUserInput on the variables which can not be coerced and CodeInternalServerError on the
+// masked errors, the persisted query codes on the automatic persisted queries. The others are for the resolvers.
+const (
+	CodeParseFailed                = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed           = "GRAPHQL_VALIDATION_FAILED"
+	CodeBadUserInput               = "BAD_USER_INPUT"
+	CodeUnauthenticated            = "UNAUTHENTICATED"
+	CodeForbidden                  = "FORBIDDEN"
+	CodePersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	CodePersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+	CodeInternalServerError        = "INTERNAL_SERVER_ERROR"
+)
 
 type GraphQLError struct {
 	Message       string                 `json:"message"`
@@ -9,6 +27,67 @@ type GraphQLError struct {
 	Rule          string                 `json:"-"`
 	ResolverError error                  `json:"-"`
 	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+	// Kind is the category of the error, when it is not told by its Rule or its Path.
+	Kind Kind `json:"-"`
+}
+
+// Kind is the category of a GraphQLError, which errors.As tells apart with SyntaxError, ValidationError and
+// ExecutionError.
+type Kind int
+
+const (
+	// KindUnknown is the kind of the errors of no category, eg. the ones of a transport.
+	KindUnknown Kind = iota
+	// KindSyntax is the kind of the errors of the parser.
+	KindSyntax
+	// KindValidation is the kind of the errors having a Rule.
+	KindValidation
+	// KindExecution is the kind of the errors having a Path.
+	KindExecution
+)
+
+func (err *GraphQLError) kind() Kind {
+	switch {
+	case err.Kind != KindUnknown:
+		return err.Kind
+	case err.Rule != "":
+		return KindValidation
+	case err.Path != nil:
+		return KindExecution
+	}
+	return KindUnknown
+}
+
+// SyntaxError is the category of the errors of the documents which can not be parsed:
+//
+//	var syntaxErr *errors.SyntaxError
+//	if errors.As(err, &syntaxErr) { ... }
+type SyntaxError struct {
+	Err *GraphQLError
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+// ValidationError is the category of the errors of the operations which are rejected before being executed, eg.
+// the ones which are invalid for the schema or whose variables can not be coerced.
+type ValidationError struct {
+	Err *GraphQLError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// ExecutionError is the category of the errors of the fields, eg. the ones returned by the resolvers, which
+// errors.As finds too.
+type ExecutionError struct {
+	Err *GraphQLError
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
 }
 
 func (err *GraphQLError) Error() string {
@@ -41,8 +120,76 @@ func (m MultiError) Error() string {
 	return res
 }
 
+// Unwrap returns the category of err and the error returned by the resolver, if any.
+func (err *GraphQLError) Unwrap() []error {
+	var errs []error
+	switch err.kind() {
+	case KindSyntax:
+		errs = append(errs, &SyntaxError{Err: err})
+	case KindValidation:
+		errs = append(errs, &ValidationError{Err: err})
+	case KindExecution:
+		errs = append(errs, &ExecutionError{Err: err})
+	}
+	if err.ResolverError != nil {
+		errs = append(errs, err.ResolverError)
+	}
+	return errs
+}
+
+// WithExtension sets the extension key of err to value, and returns err.
+func (err *GraphQLError) WithExtension(key string, value interface{}) *GraphQLError {
+	if err.Extensions == nil {
+		err.Extensions = make(map[string]interface{})
+	}
+	err.Extensions[key] = value
+	return err
+}
+
+// WithCode sets the code extension of err, and returns err:
+//
+//	return nil, errors.New("not signed in").WithCode(errors.CodeUnauthenticated)
+func (err *GraphQLError) WithCode(code string) *GraphQLError {
+	return err.WithExtension("code", code)
+}
+
+// Code returns the code extension of err or of the GraphQLError which it wraps, "" when it has none.
+func Code(err error) string {
+	code, _ := FromError(err).Extensions["code"].(string)
+	return code
+}
+
+// FromError returns the GraphQLError reporting err, eg. an error returned by a resolver. The message and the
+// extensions are the ones of err when it is a GraphQLError, the extensions are kept when it wraps one.
+// The GraphQLError is a copy, which the caller may set the locations and the path of.
+func FromError(err error) *GraphQLError {
+	graphqlErr := &GraphQLError{Message: err.Error(), ResolverError: err}
+	var wrapped *GraphQLError
+	if stderrors.As(err, &wrapped) {
+		if wrapped == err {
+			graphqlErr.Message = wrapped.Message
+		}
+		if len(wrapped.Extensions) > 0 {
+			graphqlErr.Extensions = make(map[string]interface{}, len(wrapped.Extensions))
+			for key, value := range wrapped.Extensions {
+				graphqlErr.Extensions[key] = value
+			}
+		}
+	}
+	return graphqlErr
+}
+
 var _ error = (*GraphQLError)(nil)
 
+// Unwrap returns the errors of m, so that errors.As finds the first of them of a category.
+func (m MultiError) Unwrap() []error {
+	errs := make([]error, len(m))
+	for i, err := range m {
+		errs[i] = err
+	}
+	return errs
+}
+
 type Location struct {
 	Line   int `json:"line"`
 	Column int `json:"column"`
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+)
+
+// Print returns the message of err followed, for every location of err, by the lines of source around the location
+// with a caret under its column, like printError of graphql-js:
+//
+//	Cannot query field "nam" on type "Hero".
+//
+//	GraphQL request:2:9
+//	1 | {
+//	2 |   hero { nam }
+//	  |          ^
+//	3 | }
+//
+// source is the document which err reports an error of, err is a GraphQLError or a MultiError, the errors of which
+// are separated by a blank line. The other errors are printed as is.
+func Print(err error, source string) string {
+	var multi MultiError
+	if stderrors.As(err, &multi) {
+		printed := make([]string, len(multi))
+		for i, err := range multi {
+			printed[i] = Print(err, source)
+		}
+		return strings.Join(printed, "\n\n")
+	}
+	var graphqlErr *GraphQLError
+	if !stderrors.As(err, &graphqlErr) {
+		return err.Error()
+	}
+	var b strings.Builder
+	b.WriteString(graphqlErr.Message)
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(source), "\n")
+	for _, loc := range graphqlErr.Locations {
+		if loc.Line < 1 || loc.Line > len(lines) {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\nGraphQL request:%d:%d", loc.Line, loc.Column)
+		printLocation(&b, lines, loc)
+	}
+	return b.String()
+}
+
+// printLocation prints the line of loc between the lines around it, and the caret under the column of loc.
+func printLocation(b *strings.Builder, lines []string, loc Location) {
+	first, last := loc.Line-1, loc.Line+1
+	if first < 1 || strings.TrimSpace(lines[first-1]) == "" {
+		first = loc.Line
+	}
+	if last > len(lines) || strings.TrimSpace(lines[last-1]) == "" {
+		last = loc.Line
+	}
+	width := len(fmt.Sprint(last))
+	for n := first; n <= last; n++ {
+		fmt.Fprintf(b, "\n%*d | %s", width, n, lines[n-1])
+		if n != loc.Line {
+			continue
+		}
+		// the tabs of the line are kept so that the caret is under the column whatever their width
+		var padding strings.Builder
+		runes := []rune(lines[n-1])
+		for i := 0; i < loc.Column-1; i++ {
+			if i < len(runes) && runes[i] == '\t' {
+				padding.WriteRune('\t')
+			} else {
+				padding.WriteRune(' ')
+			}
+		}
+		fmt.Fprintf(b, "\n%*s | %s^", width, "", padding.String())
+	}
+}
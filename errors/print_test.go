@@ -0,0 +1,40 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrint(t *testing.T) {
+	source := "{\n  hero { nam }\n\n\tdroid(id: 1) { nam }\n}"
+	err := errors.MultiError{
+		{Message: `Cannot query field "nam" on type "Hero".`, Locations: []errors.Location{{Line: 2, Column: 10}}},
+		{Message: `Cannot query field "nam" on type "Droid".`, Locations: []errors.Location{{Line: 4, Column: 17}}},
+	}
+	assert.Equal(t, `Cannot query field "nam" on type "Hero".
+
+GraphQL request:2:10
+1 | {
+2 |   hero { nam }
+  |          ^
+
+Cannot query field "nam" on type "Droid".
+
+GraphQL request:4:17
+4 | `+"\t"+`droid(id: 1) { nam }
+  | `+"\t"+`               ^
+5 | }`, errors.Print(err, source))
+
+	syntaxErr := errors.New("Syntax Error: Expected Name, found <EOF>.")
+	syntaxErr.Locations = []errors.Location{{Line: 1, Column: 8}}
+	assert.Equal(t, `Syntax Error: Expected Name, found <EOF>.
+
+GraphQL request:1:8
+1 | { hero
+  |        ^`, errors.Print(fmt.Errorf("parse: %w", syntaxErr), "{ hero"))
+
+	assert.Equal(t, "missing query", errors.Print(fmt.Errorf("missing query"), ""))
+}
@@ -1,8 +1,11 @@
 package __bench_test__
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/schemabuilder"
+	"io"
 	"testing"
 )
 
@@ -66,3 +69,146 @@ func BenchmarkExecutor_Execute(b *testing.B) {
 		execution.Do(schema, execution.Params{Query: source})
 	}
 }
+
+type Starship struct {
+	ID               string  `graphql:"id"`
+	Name             string  `graphql:"name"`
+	Model            string  `graphql:"model"`
+	Manufacturer     string  `graphql:"manufacturer"`
+	Class            string  `graphql:"starshipClass"`
+	Length           float64 `graphql:"length"`
+	Crew             int     `graphql:"crew"`
+	Passengers       int     `graphql:"passengers"`
+	CargoCapacity    float64 `graphql:"cargoCapacity"`
+	HyperdriveRating float64 `graphql:"hyperdriveRating"`
+}
+
+// BenchmarkExecutor_ExecuteFields measures the resolution loop of the objects of a long list, selecting all their
+// fields.
+func BenchmarkExecutor_ExecuteFields(b *testing.B) {
+	b.ReportAllocs()
+	build := schemabuilder.NewSchema()
+	build.Object("Starship", Starship{}, "")
+	starships := make([]Starship, 1000)
+	for i := range starships {
+		starships[i] = Starship{ID: "1", Name: "Millennium Falcon", Model: "YT-1300", Manufacturer: "Corellian",
+			Class: "Light freighter", Length: 34.37, Crew: 4, Passengers: 6, CargoCapacity: 100000, HyperdriveRating: 0.5}
+	}
+	build.Query().FieldFunc("starships", func() []Starship { return starships }, "")
+	schema := build.MustBuild()
+
+	const source = `{
+  starships {
+    id name model manufacturer starshipClass length crew passengers cargoCapacity hyperdriveRating __typename
+  }
+}`
+	executor := &execution.Executor{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executor.Do(schema, execution.Params{Query: source})
+	}
+}
+
+// starshipsData is the data of a list of n objects, as built by the executor.
+func starshipsData(n int) interface{} {
+	starships := make([]interface{}, n)
+	for i := range starships {
+		starships[i] = map[string]interface{}{
+			"id": fmt.Sprint(i), "name": "Millennium Falcon", "model": "YT-1300 light freighter",
+			"length": 34.37, "crew": 4, "hyperdriveRating": 0.5, "pilots": []interface{}{"Han Solo", "Chewbacca"},
+		}
+	}
+	return map[string]interface{}{"starships": starships}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	b.ReportAllocs()
+	data := starshipsData(10000)
+	for i := 0; i < b.N; i++ {
+		execution.WriteJSON(io.Discard, data)
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	b.ReportAllocs()
+	data := starshipsData(10000)
+	for i := 0; i < b.N; i++ {
+		encoded, _ := json.Marshal(data)
+		io.Discard.Write(encoded)
+	}
+}
+
+// BenchmarkExecutor_ExecuteVariables measures the executions of an operation whose arguments are bound to its
+// variables.
+func BenchmarkExecutor_ExecuteVariables(b *testing.B) {
+	b.ReportAllocs()
+	build := schemabuilder.NewSchema()
+	build.Object("Starship", Starship{}, "")
+	build.Query().FieldFunc("starship", func(args struct {
+		ID string `graphql:"id"`
+	}) Starship {
+		return Starship{ID: args.ID, Name: "Millennium Falcon", Model: "YT-1300"}
+	}, "")
+	schema := build.MustBuild()
+
+	const source = `query Starship($id: String!) {
+  starship(id: $id) { id name model }
+  other: starship(id: "1") { id name model }
+}`
+	executor := &execution.Executor{}
+	variables := map[string]interface{}{"id": "2"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executor.Do(schema, execution.Params{Query: source, Variables: variables})
+	}
+}
+
+type StarshipFilter struct {
+	Manufacturer string   `graphql:"manufacturer"`
+	MinCrew      int      `graphql:"minCrew"`
+	Classes      []string `graphql:"classes"`
+}
+
+// BenchmarkExecutor_ExecuteArguments measures the decoding of the arguments of the objects of a list, each field
+// taking several of them.
+func BenchmarkExecutor_ExecuteArguments(b *testing.B) {
+	b.ReportAllocs()
+	build := schemabuilder.NewSchema()
+	build.InputObject("StarshipFilter", StarshipFilter{})
+	starship := build.Object("Starship", Starship{}, "")
+	starship.FieldFunc("length", func(source Starship, args struct {
+		Unit      string  `graphql:"unit"`
+		Precision int     `graphql:"precision"`
+		Scale     float64 `graphql:"scale"`
+		Round     bool    `graphql:"round"`
+	}) float64 {
+		return source.Length * args.Scale
+	}, "")
+	starship.FieldFunc("similar", func(source Starship, args struct {
+		First  int             `graphql:"first"`
+		After  string          `graphql:"after"`
+		Filter *StarshipFilter `graphql:"filter"`
+		IDs    []string        `graphql:"ids"`
+	}) []Starship {
+		return nil
+	}, "")
+	starships := make([]Starship, 100)
+	for i := range starships {
+		starships[i] = Starship{ID: fmt.Sprint(i), Name: "Millennium Falcon", Length: 34.37}
+	}
+	build.Query().FieldFunc("starships", func() []Starship { return starships }, "")
+	schema := build.MustBuild()
+
+	const source = `{
+  starships {
+    id
+    length(unit: "METER", precision: 2, scale: 1.5, round: true)
+    similar(first: 10, after: "abc", filter: {manufacturer: "Corellian", minCrew: 2, classes: ["freighter", "corvette"]}, ids: ["1", "2", "3"]) { id }
+  }
+}`
+	executor := &execution.Executor{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executor.Do(schema, execution.Params{Query: source})
+	}
+}
@@ -64,11 +64,11 @@ func Init3() {
 		dest.SetString("DeserializedValue")
 		return nil
 	})
-	TestComplexScalar.LiteralFunc(func(value ast.Value) error {
+	TestComplexScalar.LiteralFunc(func(value ast.Value) (interface{}, error) {
 		if value.GetValue() != "SerializedValue" {
-			return fmt.Errorf("unexpected invariant triggered")
+			return nil, fmt.Errorf("unexpected invariant triggered")
 		}
-		return nil
+		return ComplexScalar("DeserializedValue"), nil
 	})
 
 	build.InputObject("TestInputObject", TestInputObject{}, "")
@@ -322,7 +322,7 @@ func Test_Handler_Input(t *testing.T) {
 			t.Run("errors on null for nested non-null", func(t *testing.T) {
 				_, err := execution.Do(schema, execution.Params{Query: doc, Variables: map[string]interface{}{
 					"input": map[string]interface{}{"a": "foo", "b": "bar", "c": nil}}})
-				assert.EqualError(t, err, "[graphql: Variable \"c\" has invalid value null.\nExpected type \"String!\", found null. (2:16)]")
+				assert.EqualError(t, err, "[graphql: Variable \"input.c\" has invalid value null.\nExpected type \"String!\", found null. (2:16)]")
 			})
 
 			t.Run("errors on incorrect type", func(t *testing.T) {
@@ -334,7 +334,7 @@ func Test_Handler_Input(t *testing.T) {
 			t.Run("errors on omission of nested non-null", func(t *testing.T) {
 				_, err := execution.Do(schema, execution.Params{Query: doc, Variables: map[string]interface{}{
 					"input": map[string]interface{}{"a": "foo", "b": "bar"}}})
-				assert.EqualError(t, err, "[graphql: Variable \"c\" has invalid value null.\nExpected type \"String!\", found null. (2:16)]")
+				assert.EqualError(t, err, "[graphql: Variable \"input.c\" has invalid value null.\nExpected type \"String!\", found null. (2:16)]")
 			})
 
 			t.Run("errors on deep nested errors and with many errors", func(t *testing.T) {
@@ -343,7 +343,7 @@ func Test_Handler_Input(t *testing.T) {
             fieldWithNestedInputObject(input: $input)
           }
         `, Variables: map[string]interface{}{"input": map[string]interface{}{"na": map[string]interface{}{"a": "foo"}}}})
-				assert.EqualError(t, err, "[graphql: Variable \"c\" has invalid value null.\nExpected type \"String!\", found null. (2:18)\ngraphql: Variable \"nb\" has invalid value null.\nExpected type \"String!\", found null. (2:18)]")
+				assert.EqualError(t, err, "[graphql: Variable \"input.na.c\" has invalid value null.\nExpected type \"String!\", found null. (2:18)\ngraphql: Variable \"input.nb\" has invalid value null.\nExpected type \"String!\", found null. (2:18)]")
 			})
 
 			t.Run("errors on addition of unknown input field", func(t *testing.T) {
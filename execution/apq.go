@@ -0,0 +1,138 @@
+package execution
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// PersistedQueryCache stores the query texts of the automatic persisted queries by their sha256 hash.
+// Implementations must be safe for concurrent use, eg. backed by redis or memcached.
+type PersistedQueryCache interface {
+	Get(ctx context.Context, hash string) (string, bool)
+	Add(ctx context.Context, hash string, query string)
+}
+
+// MemoryQueryCache is a PersistedQueryCache in memory of Size queries at most, whose entries expire TTL after
+// their last use. The least recently used query is evicted to add one to a full cache. Its zero value is usable.
+type MemoryQueryCache struct {
+	// TTL is how long the queries are kept after their last use, zero keeps them until they are evicted.
+	TTL time.Duration
+	// Size is the number of queries kept, DefaultMemoryQueryCacheSize when zero.
+	Size int
+	// Clock tells the time of the uses of the queries, clock.Real when nil.
+	Clock clock.Clock
+
+	mu  sync.Mutex
+	lru internal.LRU[string, *queryEntry]
+}
+
+type queryEntry struct {
+	query    string
+	lastUsed time.Time
+}
+
+// DefaultMemoryQueryCacheSize is the number of queries of a MemoryQueryCache whose Size is zero.
+const DefaultMemoryQueryCacheSize = 1024
+
+// NewMemoryQueryCache returns a MemoryQueryCache of DefaultMemoryQueryCacheSize queries, a ttl of zero keeps the
+// queries until they are evicted.
+func NewMemoryQueryCache(ttl time.Duration) *MemoryQueryCache {
+	return &MemoryQueryCache{TTL: ttl, Size: DefaultMemoryQueryCacheSize, Clock: clock.Real}
+}
+
+func (c *MemoryQueryCache) Get(ctx context.Context, hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.lru.Get(hash)
+	if !ok {
+		return "", false
+	}
+	now := c.clock().Now()
+	if c.expired(entry, now) {
+		c.lru.Remove(hash)
+		return "", false
+	}
+	entry.lastUsed = now
+	return entry.query, true
+}
+
+func (c *MemoryQueryCache) Add(ctx context.Context, hash string, query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock().Now()
+	if entry, ok := c.lru.Get(hash); ok {
+		entry.query, entry.lastUsed = query, now
+		return
+	}
+	// the entries expire in the order of their last use, only the expired ones are visited
+	for oldest, entry, ok := c.lru.Oldest(); ok && c.expired(entry, now); oldest, entry, ok = c.lru.Oldest() {
+		c.lru.Remove(oldest)
+	}
+	c.lru.Size = c.size()
+	c.lru.Add(hash, &queryEntry{query: query, lastUsed: now})
+}
+
+func (c *MemoryQueryCache) clock() clock.Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return clock.Real
+}
+
+func (c *MemoryQueryCache) size() int {
+	if c.Size > 0 {
+		return c.Size
+	}
+	return DefaultMemoryQueryCacheSize
+}
+
+func (c *MemoryQueryCache) expired(entry *queryEntry, now time.Time) bool {
+	return c.TTL > 0 && now.Sub(entry.lastUsed) >= c.TTL
+}
+
+func persistedQueryError(message, code string) *errors.GraphQLError {
+	return &errors.GraphQLError{Message: message, Extensions: map[string]interface{}{"code": code}}
+}
+
+// resolvePersistedQuery implements the automatic persisted queries of Apollo, see
+// https://github.com/apollographql/apollo-link-persisted-queries#protocol.
+// A request with a persistedQuery extension and no query is looked up by its hash;
+// clients send the query again along with its hash when it is not found, and it is then stored.
+func (e *Executor) resolvePersistedQuery(ctx context.Context, param *Params) *errors.GraphQLError {
+	ext, ok := param.Extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if e.PersistedQueries == nil {
+		return persistedQueryError("PersistedQueryNotSupported", errors.CodePersistedQueryNotSupported)
+	}
+	if fmt.Sprint(ext["version"]) != "1" {
+		return persistedQueryError("unsupported persisted query version", "PERSISTED_QUERY_VERSION_NOT_SUPPORTED")
+	}
+	hash, _ := ext["sha256Hash"].(string)
+	if hash == "" {
+		return persistedQueryError("persisted query without sha256Hash", errors.CodeBadUserInput)
+	}
+	if param.Query == "" {
+		query, ok := e.PersistedQueries.Get(ctx, hash)
+		if !ok {
+			return persistedQueryError("PersistedQueryNotFound", errors.CodePersistedQueryNotFound)
+		}
+		param.Query = query
+		return nil
+	}
+	sum := sha256.Sum256([]byte(param.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return persistedQueryError("provided sha does not match query", errors.CodeBadUserInput)
+	}
+	e.PersistedQueries.Add(ctx, hash, param.Query)
+	return nil
+}
@@ -0,0 +1,167 @@
+package execution
+
+import (
+	"sync"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// fieldPlan caches the fields collected for the selection sets of an operation, so the objects of a list
+// and the executions of a PreparedQuery collect them once. It is safe for concurrent use.
+type fieldPlan struct {
+	mu     sync.RWMutex
+	fields map[planKey]plannedFields
+	// shared is the plan of the static selection sets of a cached operation plan, shared by its executions
+	shared *fieldPlan
+	static map[*internal.SelectionSet]bool
+}
+
+// plannedFields are the fields collected for an object along with their definitions, looked up once by the plan
+// rather than for every object of a list. The definition of __typename and of unknown fields is nil.
+type plannedFields struct {
+	selections  []*internal.Selection
+	definitions []*internal.Field
+}
+
+type planKey struct {
+	selectionSet *internal.SelectionSet
+	object       *internal.Object
+	union        *internal.Union
+}
+
+func newFieldPlan() *fieldPlan {
+	return &fieldPlan{fields: make(map[planKey]plannedFields)}
+}
+
+func (p *fieldPlan) collect(object *internal.Object, union *internal.Union, selectionSet *internal.SelectionSet) (plannedFields, error) {
+	if p.shared != nil && p.static[selectionSet] {
+		return p.shared.collect(object, union, selectionSet)
+	}
+	key := planKey{selectionSet: selectionSet, object: object, union: union}
+	p.mu.RLock()
+	planned, ok := p.fields[key]
+	p.mu.RUnlock()
+	if ok {
+		return planned, nil
+	}
+	selections, err := collectFields(object, union, selectionSet)
+	if err != nil {
+		return plannedFields{}, err
+	}
+	planned = plannedFields{selections: selections, definitions: make([]*internal.Field, len(selections))}
+	for i, selection := range selections {
+		if selection.Name != "__typename" {
+			planned.definitions[i] = object.Fields[selection.Name]
+		}
+	}
+	p.mu.Lock()
+	p.fields[key] = planned
+	p.mu.Unlock()
+	return planned, nil
+}
+
+// collectFields implements CollectFields of the specification: the fields of selectionSet and of the
+// fragments applying to object are grouped by response key, skipping those excluded by @skip and @include.
+// The fields of a group are merged into one selection whose selection set holds all of their subselections.
+// The groups are in the order of their first field, the fields coming before the fragments of a selection set.
+//
+// union is the union whose value object is, a fragment on it applies too. A nil object collects every fragment.
+func collectFields(object *internal.Object, union *internal.Union, selectionSet *internal.SelectionSet) ([]*internal.Selection, error) {
+	var keys []string
+	grouped := make(map[string][]*internal.Selection)
+	visitedFragments := make(map[*internal.FragmentDefinition]bool)
+
+	var visit func(*internal.SelectionSet) error
+	visit = func(selectionSet *internal.SelectionSet) error {
+		if selectionSet == nil {
+			return nil
+		}
+		for _, selection := range selectionSet.Selections {
+			if ok, err := shouldIncludeNode(selection.Directives); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if _, ok := grouped[selection.Alias]; !ok {
+				keys = append(keys, selection.Alias)
+			}
+			grouped[selection.Alias] = append(grouped[selection.Alias], selection)
+		}
+		for _, fragment := range selectionSet.Fragments {
+			if ok, err := shouldIncludeNode(fragment.Directives); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			// named fragments are spread once, inline fragments have no name
+			if fragment.Fragment.Name != "" {
+				if visitedFragments[fragment.Fragment] {
+					continue
+				}
+				visitedFragments[fragment.Fragment] = true
+			}
+			if !fragmentApplies(object, union, fragment.Fragment) {
+				continue
+			}
+			if err := visit(fragment.Fragment.SelectionSet); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(selectionSet); err != nil {
+		return nil, err
+	}
+
+	collected := make([]*internal.Selection, 0, len(keys))
+	for _, key := range keys {
+		selections := grouped[key]
+		if len(selections) == 1 || selections[0].SelectionSet == nil {
+			collected = append(collected, selections[0])
+			continue
+		}
+
+		merged := &internal.SelectionSet{Loc: selections[0].SelectionSet.Loc}
+		for _, selection := range selections {
+			if selection.SelectionSet == nil {
+				continue
+			}
+			merged.Selections = append(merged.Selections, selection.SelectionSet.Selections...)
+			merged.Fragments = append(merged.Fragments, selection.SelectionSet.Fragments...)
+		}
+		collected = append(collected, &internal.Selection{
+			Name:         selections[0].Name,
+			Alias:        selections[0].Alias,
+			Args:         selections[0].Args,
+			SelectionSet: merged,
+			Directives:   selections[0].Directives,
+			Loc:          selections[0].Loc,
+			Arguments:    selections[0].Arguments,
+		})
+	}
+	return collected, nil
+}
+
+// fragmentApplies reports whether fragment applies to object, which is the case when it has no type condition, or
+// when its type condition names object, an interface implemented by object or a union object is a member of. The
+// fragments whose union is not resolved, eg. built by hand, apply to the members of union, the enclosing union.
+func fragmentApplies(object *internal.Object, union *internal.Union, fragment *internal.FragmentDefinition) bool {
+	on := fragment.On
+	if object == nil || on == "" || on == object.Name {
+		return true
+	}
+	if _, ok := object.Interfaces[on]; ok {
+		return true
+	}
+	if fragment.Union != nil {
+		union = fragment.Union
+	}
+	if union != nil && union.Name == on {
+		for _, member := range union.Types {
+			if member == object {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"github.com/shyptr/graphql/internal"
+)
+
+// deferred stands in the response for the value of a field whose resolver returned an internal.Thunk,
+// it is replaced by the value once the thunk has been called.
+type deferred struct {
+	value interface{}
+}
+
+type deferredField struct {
+	marker *deferred
+	path   []interface{}
+	// slots are the places of the field and of its ancestors in the response, see propagate
+	slots     []slot
+	field     *internal.Field
+	selection *internal.Selection
+	thunk     internal.Thunk
+}
+
+// runDeferred calls the thunks of the deferred fields and completes their values, until none is left.
+// The thunks of one round are all returned before the first of them is called, which lets a dataloader
+// batch the keys they load. The thunks of a round are called concurrently when the executor has a ThunkScheduler or
+// a MaxConcurrentThunks, within the global limit of the scheduler. The resolvers are not: the fields are still
+// resolved and completed one after the other.
+func (e *Executor) runDeferred(ctx *exeContext) {
+	for len(ctx.deferred) > 0 {
+		fields := ctx.deferred
+		ctx.deferred = nil
+		var results []*resolved
+		if e.concurrent() && len(fields) > 1 {
+			results = e.callThunks(ctx, fields)
+		}
+		for i, d := range fields {
+			ctx.path = append(ctx.path[:0], d.path...)
+			if ctx.cancelled(d.selection.Loc) {
+				continue
+			}
+			// the fields deferred while completing d are under its slot, the slots of d are not overwritten by them
+			ctx.field, ctx.location, ctx.slots = d.field, d.selection.Loc, d.slots[:len(d.slots):len(d.slots)]
+			var called *resolved
+			if results != nil {
+				called = results[i]
+			}
+			value, err := e.completeDeferred(ctx, d, called)
+			if err != nil {
+				if err != errNullPropagated {
+					ctx.addFieldErr(d.selection.Loc, d.field, err)
+				}
+				// the ancestors of the field are complete already, the null is set in their place
+				ctx.propagate(d.slots)
+				continue
+			}
+			d.marker.value = value
+		}
+	}
+	ctx.path, ctx.slots = nil, nil
+}
+
+// slot is the place of a value in the response, the key of an object or the index of a list.
+type slot struct {
+	object map[string]interface{}
+	key    string
+	items  []interface{}
+	index  int
+	// propagates tells that a null value makes the value of the previous slot null, see PropagateError
+	propagates bool
+	// omit tells that a null item is left out of its list, see OmitOnError
+	omit bool
+}
+
+// omitted stands in a list for an item which failed once the list was complete, it is left out by undefer.
+var omitted = &deferred{}
+
+// propagate sets the null value of a failed field in the last of slots, or in the nearest of its ancestors whose
+// value is nullable. The data of the response is null when none is.
+func (e *exeContext) propagate(slots []slot) {
+	for i := len(slots) - 1; i >= 0; i-- {
+		s := slots[i]
+		if s.propagates {
+			continue
+		}
+		switch {
+		case s.object != nil:
+			s.object[s.key] = nil
+		case s.omit:
+			s.items[s.index] = omitted
+		default:
+			s.items[s.index] = nil
+		}
+		return
+	}
+	e.nullData = true
+}
+
+// completeDeferred completes the value of d, called is the result of its thunk when it has been called already.
+func (e *Executor) completeDeferred(ctx *exeContext, d *deferredField, called *resolved) (result interface{}, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			ctx.path = append(ctx.path[:0], d.path...)
+			result, err = nil, e.recoverPanic(ctx.Context, panicErr, stack())
+		}
+	}()
+	if called == nil {
+		value, err := d.thunk()
+		called = &resolved{value: value, err: err}
+	}
+	if called.err != nil {
+		return nil, called.err
+	}
+	return e.execute(ctx, d.field.Type, called.value, d.selection.SelectionSet)
+}
+
+// undefer replaces the deferred values of a response by their value.
+func undefer(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *deferred:
+		return undefer(value.value)
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = undefer(v)
+		}
+	case []interface{}:
+		items := value[:0]
+		for _, v := range value {
+			if v != omitted {
+				items = append(items, undefer(v))
+			}
+		}
+		return items
+	}
+	return value
+}
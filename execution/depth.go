@@ -0,0 +1,53 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// CheckDepth returns an error when a field of selectionSet is nested deeper than max,
+// the fields of the root selection set being at depth 1. Fragments do not add a level,
+// their fields count at the depth where they are spread. A max of zero disables the check.
+func CheckDepth(selectionSet *internal.SelectionSet, max int) error {
+	if max <= 0 || selectionSet == nil {
+		return nil
+	}
+	return checkDepth(selectionSet, max, nil)
+}
+
+func checkDepth(selectionSet *internal.SelectionSet, max int, path []interface{}) error {
+	for _, selection := range selectionSet.Selections {
+		key := selection.Alias
+		if key == "" {
+			key = selection.Name
+		}
+		path := append(path[:len(path):len(path)], key)
+		if len(path) > max {
+			keys := make([]string, len(path))
+			for i, p := range path {
+				keys[i] = fmt.Sprint(p)
+			}
+			return &errors.GraphQLError{
+				Message:    fmt.Sprintf("query depth exceeds the maximum of %d at %s", max, strings.Join(keys, ".")),
+				Locations:  []errors.Location{selection.Loc},
+				Path:       path,
+				Kind:       errors.KindValidation,
+				Extensions: map[string]interface{}{"code": errors.CodeValidationFailed},
+			}
+		}
+		if selection.SelectionSet != nil {
+			if err := checkDepth(selection.SelectionSet, max, path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, fragment := range selectionSet.Fragments {
+		if err := checkDepth(fragment.Fragment.SelectionSet, max, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
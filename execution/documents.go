@@ -0,0 +1,79 @@
+package execution
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// DocumentCache keeps the documents parsed from the most recently used queries, so the queries sent again and
+// again are parsed once. The documents are keyed by the sha256 hash of the query text, the queries which can not
+// be parsed are not cached. The cached documents are shared by the operations, they must not be modified.
+// It is safe for concurrent use.
+type DocumentCache struct {
+	mu    sync.Mutex
+	lru   *internal.LRU[[sha256.Size]byte, *internal.Document]
+	stats DocumentCacheStats
+}
+
+// DocumentCacheStats are the counters of a DocumentCache, eg. to export as metrics.
+type DocumentCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Len is the number of cached documents.
+	Len int
+}
+
+// NewDocumentCache returns a DocumentCache of size documents at most.
+func NewDocumentCache(size int) *DocumentCache {
+	return &DocumentCache{lru: internal.NewLRU[[sha256.Size]byte, *internal.Document](size)}
+}
+
+// DefaultDocumentCacheSize is the number of documents of the document cache shared by the executors.
+const DefaultDocumentCacheSize = 1024
+
+var defaultDocumentCache = NewDocumentCache(DefaultDocumentCacheSize)
+
+func (e *Executor) documentCache() *DocumentCache {
+	if e.DocumentCache != nil {
+		return e.DocumentCache
+	}
+	return defaultDocumentCache
+}
+
+// Get returns the document of query, parsed unless it is cached.
+func (c *DocumentCache) Get(query string) (*internal.Document, errors.MultiError) {
+	hash := sha256.Sum256([]byte(query))
+	c.mu.Lock()
+	if doc, ok := c.lru.Get(hash); ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return doc, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// concurrent misses of a query parse it each, the documents are the same
+	doc, errs := internal.Parse(query)
+	if errs != nil {
+		return nil, errs
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lru.Contains(hash) {
+		c.stats.Evictions += uint64(c.lru.Add(hash, doc))
+	}
+	return doc, nil
+}
+
+// Stats returns the counters of the cache.
+func (c *DocumentCache) Stats() DocumentCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Len = c.lru.Len()
+	return stats
+}
@@ -0,0 +1,206 @@
+package execution
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// WriteJSON writes value, the data of a Response, to w as JSON as it is encoded, rather than marshaling the whole
+// of it first, which saves the memory and the copies of the large responses. The objects and the lists built by
+// the executor and their scalars are encoded directly, the other values with json.Marshal, the output being the
+// one of json.Marshal. The data written before an error is left as is.
+func WriteJSON(w io.Writer, value interface{}) error {
+	buffered, ok := w.(*bufio.Writer)
+	if !ok {
+		buffered = bufio.NewWriterSize(w, 32<<10)
+	}
+	e := &encoder{w: buffered}
+	if err := e.encode(value); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+type encoder struct {
+	w *bufio.Writer
+	// scratch is the buffer of the scalars being encoded
+	scratch []byte
+	// keys is the buffer of the sorted keys of the objects, reused once an object is written
+	keys []string
+}
+
+func (e *encoder) encode(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		_, err := e.w.WriteString("null")
+		return err
+	case map[string]interface{}:
+		return e.encodeObject(value)
+	case []interface{}:
+		if err := e.w.WriteByte('['); err != nil {
+			return err
+		}
+		for i, item := range value {
+			if i > 0 {
+				if err := e.w.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+		return e.w.WriteByte(']')
+	case string:
+		return e.write(appendString(e.scratch[:0], value))
+	case bool:
+		return e.write(strconv.AppendBool(e.scratch[:0], value))
+	case int:
+		return e.write(strconv.AppendInt(e.scratch[:0], int64(value), 10))
+	case int32:
+		return e.write(strconv.AppendInt(e.scratch[:0], int64(value), 10))
+	case int64:
+		return e.write(strconv.AppendInt(e.scratch[:0], value, 10))
+	case uint:
+		return e.write(strconv.AppendUint(e.scratch[:0], uint64(value), 10))
+	case uint32:
+		return e.write(strconv.AppendUint(e.scratch[:0], uint64(value), 10))
+	case uint64:
+		return e.write(strconv.AppendUint(e.scratch[:0], value, 10))
+	case float64:
+		return e.encodeFloat(value, 64)
+	case float32:
+		return e.encodeFloat(float64(value), 32)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(encoded)
+		return err
+	}
+}
+
+// encodeObject writes object with its keys sorted, as json.Marshal does.
+func (e *encoder) encodeObject(object map[string]interface{}) error {
+	start := len(e.keys)
+	for key := range object {
+		e.keys = append(e.keys, key)
+	}
+	keys := e.keys[start:]
+	sort.Strings(keys)
+	defer func() {
+		clear(keys)
+		e.keys = e.keys[:start]
+	}()
+
+	if err := e.w.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if i > 0 {
+			e.scratch = append(e.scratch[:0], ',')
+		} else {
+			e.scratch = e.scratch[:0]
+		}
+		e.scratch = append(appendString(e.scratch, key), ':')
+		if err := e.write(e.scratch); err != nil {
+			return err
+		}
+		if err := e.encode(object[key]); err != nil {
+			return err
+		}
+	}
+	return e.w.WriteByte('}')
+}
+
+// encodeFloat writes f of bits bits in the format of json.Marshal, which fails on the NaN and the infinities.
+func (e *encoder) encodeFloat(f float64, bits int) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return &json.UnsupportedValueError{Value: reflect.ValueOf(f), Str: strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b := strconv.AppendFloat(e.scratch[:0], f, format, -1, bits)
+	if format == 'e' {
+		// e-09 is written e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return e.write(b)
+}
+
+func (e *encoder) write(b []byte) error {
+	e.scratch = b
+	_, err := e.w.Write(b)
+	return err
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s quoted to b, escaping the HTML characters and replacing the invalid UTF-8 as
+// json.Marshal does.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// the line and paragraph separators are not valid in JavaScript strings
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
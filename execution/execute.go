@@ -2,33 +2,307 @@ package execution
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
-	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/clock"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/internal"
 	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	stdlog "log"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 )
 
+// RecoverFunc turns a panic which happened while resolving a field into the error of the field.
+// stack is the stack trace of the panicking goroutine.
+type RecoverFunc func(ctx context.Context, panicValue interface{}, stack []byte) error
+
+// ErrorPresenter turns an error of an operation into the one sent to the client, eg. to give a code to the errors
+// of the domain, to localize the messages or to hide the internal details. err is the *errors.GraphQLError, which
+// wraps the error returned by the resolver if any, see errors.As. A nil result keeps err as is.
+type ErrorPresenter func(ctx context.Context, err error) *errors.GraphQLError
+
 type Executor struct {
 	iterate bool
+	// RecoverFunc is called when a resolver panics, the siblings of the field are still executed.
+	// By default the error reports the panic value, its stack trace is logged when the error is masked.
+	RecoverFunc RecoverFunc
+	// ErrorPresenter, when set, presents every error of the responses, after the AfterExecution interceptors.
+	// The unexpected errors it keeps are then masked, see MaskInternalErrors.
+	ErrorPresenter ErrorPresenter
+	// UnmaskedErrors sends the unexpected errors of the resolvers as they are, eg. in development, instead of
+	// masking them.
+	UnmaskedErrors bool
+	// FieldTimeout bounds every resolver call which has no timeout of its own, zero means no bound.
+	// A resolver which does not return in time is abandoned and its field becomes an error.
+	FieldTimeout time.Duration
+	// Timeout is the deadline of the whole operation, zero means no deadline. The context of the resolvers is
+	// done at the deadline, the resolvers are expected to return then, only the ones with a timeout are abandoned.
+	// The fields which are not resolved yet are left null.
+	Timeout time.Duration
+	// ErrorPolicy tells what becomes of the fields which fail and have no policy of their own, see
+	// schemabuilder.OnError. By default the errors propagate, see schemabuilder.PropagateError.
+	ErrorPolicy schemabuilder.ErrorPolicy
+	// Interceptors hook into the lifecycle of the operations run by the executor.
+	Interceptors []*Interceptor
+	// Stages replace the default stages of the operations run by the executor.
+	Stages Stages
+	// SlowQueryThreshold is the duration of the operations beyond which they are reported to SlowQueryLog, with the
+	// time spent in each of their stages. Zero reports none.
+	SlowQueryThreshold time.Duration
+	SlowQueryLog       func(ctx context.Context, query *SlowQuery)
+	// SlowQueryRedact returns the value reported for the variable name of a slow query. By default the values of
+	// all the variables are Redacted.
+	SlowQueryRedact func(name string, value interface{}) interface{}
+	// Clock measures the durations and the timeouts of the operations, it is clock.Real by default.
+	Clock clock.Clock
+	// MaxDepth rejects the operations selecting fields nested deeper than it, see CheckDepth.
+	// Zero means no limit.
+	MaxDepth int
+	// Introspection, when set, decides whether an operation may introspect the schema, see CheckIntrospection.
+	// By default the schema is introspectable.
+	Introspection IntrospectionPolicy
+	// PersistedQueries enables the automatic persisted queries when set.
+	PersistedQueries PersistedQueryCache
+	// SubscriptionBuffer is the number of responses of a subscription waiting for its subscriber.
+	SubscriptionBuffer int
+	// SubscriptionOverflow is the policy applied when a subscriber is too slow to receive its responses,
+	// by default the events are executed no faster than the subscriber receives them.
+	SubscriptionOverflow OverflowPolicy
+	// ValidationCache caches the validation results of the queries run by the executor, by default a cache of
+	// DefaultValidationCacheSize documents shared by the executors is used. The cache holds the validation rules,
+	// a cache built with validation.NewCacheWithRules runs others, eg. the limits of aliases.
+	ValidationCache *validation.Cache
+	// DocumentCache caches the documents parsed from the queries run by the executor, by default a cache of
+	// DefaultDocumentCacheSize documents shared by the executors is used.
+	DocumentCache *DocumentCache
+	// PlanCache caches the plans of the operations run by the executor, by default a cache of DefaultPlanCacheSize
+	// plans shared by the executors is used.
+	PlanCache *PlanCache
+	// ThunkScheduler, when set, calls the thunks of the deferred fields of a round concurrently, eg. a WorkerPool
+	// shared by the executors bounds the goroutines of all their operations. By default the thunks are called one
+	// after the other, unless MaxConcurrentThunks is more than one: they are then called by a pool of
+	// DefaultThunkWorkers goroutines shared by the executors. Only the thunks are scheduled, the resolvers of the
+	// fields are called one after the other, see ThunkScheduler.
+	ThunkScheduler ThunkScheduler
+	// MaxConcurrentThunks is the number of thunks an operation calls at once at most, whatever its ThunkScheduler,
+	// so that a wide operation does not starve the others. Zero means no limit of its own, one calls the thunks
+	// one after the other.
+	MaxConcurrentThunks int
+}
+
+// DefaultValidationCacheSize is the number of documents of the validation cache shared by the executors.
+const DefaultValidationCacheSize = 1024
+
+var defaultValidationCache = validation.NewCache(DefaultValidationCacheSize)
+
+func (e *Executor) validationCache() *validation.Cache {
+	if e.ValidationCache != nil {
+		return e.ValidationCache
+	}
+	return defaultValidationCache
+}
+
+var maskInternalErrors = MaskInternalErrors(nil)
+
+// presentErrors applies the ErrorPresenter of the executor to errs, then masks the unexpected errors unless
+// UnmaskedErrors.
+func (e *Executor) presentErrors(ctx context.Context, errs errors.MultiError) errors.MultiError {
+	if e.ErrorPresenter == nil && e.UnmaskedErrors || len(errs) == 0 {
+		return errs
+	}
+	presented := make(errors.MultiError, len(errs))
+	for i, err := range errs {
+		presented[i] = err
+		if e.ErrorPresenter != nil {
+			if p := e.ErrorPresenter(ctx, err); p != nil {
+				presented[i] = p
+			}
+		}
+		if !e.UnmaskedErrors {
+			if masked := maskInternalErrors(ctx, presented[i]); masked != nil {
+				presented[i] = masked
+			}
+		}
+	}
+	return presented
+}
+
+// InternalErrorMessage is the message of the errors masked by MaskInternalErrors.
+const InternalErrorMessage = "internal system error"
+
+// MaskInternalErrors returns the ErrorPresenter replacing the unexpected errors of the resolvers with an internal
+// system error, so that eg. the SQL queries or the panics do not leak to the clients. An error is expected when it
+// is or wraps an *errors.GraphQLError, when it has a code, when it comes from the context of the operation or when it
+// is not the error of a field, eg. the one of an interceptor rejecting the operation.
+// log receives the original errors along with their path, they are written to the standard logger when it is nil,
+// along with the id of the request if any, see RequestID, and the stack trace of the panics.
+// The executors mask the internal errors by default, see Executor.UnmaskedErrors.
+func MaskInternalErrors(log func(ctx context.Context, err *errors.GraphQLError)) ErrorPresenter {
+	if log == nil {
+		log = func(ctx context.Context, err *errors.GraphQLError) {
+			at := fmt.Sprint(err.Path)
+			if id := RequestID(ctx); id != "" {
+				at += " of request " + id
+			}
+			var panicErr *panicError
+			if stderrors.As(err.ResolverError, &panicErr) {
+				stdlog.Printf("graphql: internal error at %s: %v\n%s", at, err.ResolverError, panicErr.stack)
+				return
+			}
+			stdlog.Printf("graphql: internal error at %s: %v", at, err.ResolverError)
+		}
+	}
+	return func(ctx context.Context, err error) *errors.GraphQLError {
+		graphqlErr, ok := err.(*errors.GraphQLError)
+		if !ok || graphqlErr.ResolverError == nil || graphqlErr.Path == nil || errors.Code(graphqlErr) != "" {
+			return nil
+		}
+		var wrapped *errors.GraphQLError
+		if stderrors.As(graphqlErr.ResolverError, &wrapped) ||
+			stderrors.Is(graphqlErr.ResolverError, context.Canceled) ||
+			stderrors.Is(graphqlErr.ResolverError, context.DeadlineExceeded) {
+			return nil
+		}
+		log(ctx, graphqlErr)
+		masked := &errors.GraphQLError{Message: InternalErrorMessage, Locations: graphqlErr.Locations, Path: graphqlErr.Path}
+		return masked.WithCode(errors.CodeInternalServerError)
+	}
+}
+
+// panicError is the error of a panicking resolver, its stack trace is left out of its message.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("graphql: panic: %v", e.value)
+}
+
+func defaultRecover(ctx context.Context, panicValue interface{}, stack []byte) error {
+	return &panicError{value: panicValue, stack: stack}
+}
+
+func (e *Executor) recoverPanic(ctx context.Context, panicValue interface{}, stack []byte) error {
+	if e.RecoverFunc != nil {
+		return e.RecoverFunc(ctx, panicValue, stack)
+	}
+	return defaultRecover(ctx, panicValue, stack)
+}
+
+func stack() []byte {
+	const size = 64 << 10
+	buf := make([]byte, size)
+	return buf[:runtime.Stack(buf, false)]
 }
 
 type exeContext struct {
 	context.Context
 	errs errors.MultiError
 	path []interface{}
+	// aborted is the error of the context once it is done
+	aborted error
+	// deferred are the fields waiting for their thunk to be called
+	deferred []*deferredField
+	// hasDeferred tells whether the response contains deferred values
+	hasDeferred bool
+	// slots are the places in the response of the values being completed, from the root fields to the current one
+	slots []slot
+	// nullData tells that the error of a deferred field propagated up to the data of the response
+	nullData bool
+	// plan holds the fields collected so far
+	plan *fieldPlan
+	// field and location are the ones of the field being completed, the errors of its items are reported at them
+	field    *internal.Field
+	location errors.Location
 }
 
-func (e *exeContext) addErr(location errors.Location, err error) {
+// cancelled reports whether the context is done, eg. the client went away or the deadline passed.
+// The error of the context is added once, the fields which are not resolved yet are left null.
+func (e *exeContext) cancelled(location errors.Location) bool {
+	if e.aborted != nil {
+		return true
+	}
+	if e.aborted = e.Err(); e.aborted == nil {
+		return false
+	}
+	message := e.aborted.Error()
+	if e.aborted == context.DeadlineExceeded {
+		message = "operation timed out: " + message
+	}
 	e.errs = append(e.errs, &errors.GraphQLError{
-		Message:       err.Error(),
-		ResolverError: err,
+		Message:       message,
+		ResolverError: e.aborted,
 		Locations:     []errors.Location{location},
-		Path:          e.path,
+		Path:          append([]interface{}(nil), e.path...),
 	})
+	return true
+}
+
+// addErr adds err, raised by the field at location. The error is reported at the current path, or at the path of
+// the item which raised it.
+func (e *exeContext) addErr(location errors.Location, err error) {
+	path := e.path
+	if itemErr, ok := err.(*itemError); ok {
+		err, path = itemErr.error, itemErr.path
+	}
+	var extensions map[string]interface{}
+	if inputErr, ok := err.(*inputError); ok {
+		err, location, extensions = inputErr.error, inputErr.location, inputErr.extensions
+	}
+	graphqlErr := errors.FromError(err)
+	graphqlErr.Locations = []errors.Location{location}
+	graphqlErr.Path = append([]interface{}(nil), path...)
+	for key, value := range extensions {
+		graphqlErr.WithExtension(key, value)
+	}
+	e.errs = append(e.errs, graphqlErr)
+}
+
+// errNullPropagated is returned in place of the error of a field which has been reported already and makes the
+// parent object null, see PropagateError.
+var errNullPropagated = stderrors.New("null propagated")
+
+// errorPolicy returns the error policy of field.
+func (e *Executor) errorPolicy(field *internal.Field) schemabuilder.ErrorPolicy {
+	switch {
+	case field != nil && field.ErrorPolicy != 0:
+		return field.ErrorPolicy
+	case e.ErrorPolicy != 0:
+		return e.ErrorPolicy
+	}
+	return schemabuilder.PropagateError
+}
+
+func (e *Executor) clock() clock.Clock {
+	if e.Clock != nil {
+		return e.Clock
+	}
+	return clock.Real
+}
+
+// itemError is the error of a non-null item of a list, which fails the field of the list, along with the path of
+// the item.
+type itemError struct {
+	error
+	path []interface{}
+}
+
+func (e *itemError) Unwrap() error {
+	return e.error
+}
+
+// addFieldErr adds the error of a field, tagged with the owner of the field.
+func (e *exeContext) addFieldErr(location errors.Location, field *internal.Field, err error) {
+	e.addErr(location, err)
+	if owner := field.Owner(); owner != "" {
+		e.errs[len(e.errs)-1].WithExtension(internal.OwnerExtension, owner)
+	}
 }
 
 func (e *exeContext) updatePath(add bool, path ...interface{}) {
@@ -44,53 +318,50 @@ type Params struct {
 	Query         string                 `json:"query"`
 	OperationName string                 `json:"operationName"`
 	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
 	Context       context.Context        `json:"context"`
 }
 
 func Do(schema *internal.Schema, param Params) (interface{}, errors.MultiError) {
+	return (&Executor{}).Do(schema, param)
+}
 
-	doc, err := internal.Parse(param.Query)
-	if err != nil {
-		return nil, []*errors.GraphQLError{err.(*errors.GraphQLError)}
-	}
-
-	operationType, selectionSet, err := ApplySelectionSet(schema, doc, param.OperationName, param.Variables)
-	if err != nil {
-		return nil, []*errors.GraphQLError{err.(*errors.GraphQLError)}
-	}
-	root := schema.Query
-	if operationType == ast.Mutation {
-		root = schema.Mutation
-	}
-	executor := &Executor{}
-	ctx := param.Context
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	return executor.Execute(ctx, root, nil, selectionSet)
+// Do parses, validates and executes a request with the executor.
+func (e *Executor) Do(schema *internal.Schema, param Params) (interface{}, errors.MultiError) {
+	_, response := e.Run(schema, param)
+	return response.Data, response.Errors
 }
 
 func (e *Executor) Execute(ctx context.Context, typ internal.Type, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, errors.MultiError) {
-	exeCtx := &exeContext{Context: ctx}
+	return e.executePlan(ctx, typ, source, selectionSet, newFieldPlan())
+}
+
+func (e *Executor) executePlan(ctx context.Context, typ internal.Type, source interface{},
+	selectionSet *internal.SelectionSet, plan *fieldPlan) (interface{}, errors.MultiError) {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = clock.WithTimeout(ctx, e.clock(), e.Timeout)
+		defer cancel()
+	}
+	ctx, release := withMemo(ctx)
+	defer release()
+	exeCtx := &exeContext{Context: ctx, plan: plan}
 	response, err := e.execute(exeCtx, typ, source, selectionSet)
-	if err != nil {
+	if err != nil && err != errNullPropagated {
 		exeCtx.addErr(selectionSet.Loc, err)
 	}
+	e.runDeferred(exeCtx)
+	if exeCtx.nullData {
+		response = nil
+	} else if exeCtx.hasDeferred {
+		response = undefer(response)
+	}
 	return response, exeCtx.errs
 }
 
 func (e *Executor) execute(ctx *exeContext, typ internal.Type, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, error) {
-	if err := ctx.Err(); err != nil {
-		if err, ok := err.(errors.MultiError); ok {
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
-	}
 	switch typ := typ.(type) {
 	case *internal.Scalar:
 		if typ.Serialize != nil {
@@ -143,73 +414,34 @@ func (e *Executor) executeUnion(ctx *exeContext, typ *internal.Union, source int
 	if value.Kind() == reflect.Ptr && value.IsNil() {
 		return nil, nil
 	}
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Struct {
+		value = value.Elem()
+	}
 
-	fields := make(map[string]interface{})
-
+	var object *internal.Object
+	var inner reflect.Value
 	var possibleTypes []string
-	for typString, object := range typ.Types {
-		inner := value
-		if inner.Kind() == reflect.Ptr && inner.Elem().Kind() == reflect.Struct {
-			inner = inner.Elem()
-		}
-		inner = *schemabuilder.GetField(inner, typString)
-		if inner.IsNil() {
+	for typString, member := range typ.Types {
+		field := *schemabuilder.GetField(value, typString)
+		if field.IsNil() {
 			continue
 		}
-		possibleTypes = append(possibleTypes, object.String())
-		for _, selection := range selectionSet.Selections {
-			func() {
-				ctx.updatePath(true, selection.Name)
-				defer func() {
-					ctx.updatePath(false)
-				}()
-				if selection.Name == "__typename" {
-					fields[selection.Alias] = object.Name
-					return
-				}
-				field := object.Fields[selection.Name]
-				if field != nil {
-					resolved, err := e.resolveAndExecute(ctx, field, inner.Interface(), selection)
-					if err != nil {
-						ctx.addErr(selection.Loc, err)
-						fields[selection.Alias] = nil
-						return
-					}
-					fields[selection.Alias] = resolved
-				}
-			}()
-		}
-
-		for _, fragment := range selectionSet.Fragments {
-			func() {
-				ctx.updatePath(true, fragment.Fragment.Name)
-				defer func() {
-					ctx.updatePath(false)
-				}()
-				if fragment.Fragment.On != typString && fragment.Fragment.On != typ.Name {
-					if _, ok := object.Interfaces[fragment.Fragment.On]; !ok {
-						return
-					}
-				}
-				resolved, err := e.executeObject(ctx, object, inner.Interface(), fragment.Fragment.SelectionSet)
-				if err != nil {
-					ctx.addErr(fragment.Loc, err)
-					return
-				}
-
-				for k, v := range resolved.(map[string]interface{}) {
-					fields[k] = v
-				}
-				return
-			}()
-
-		}
+		possibleTypes = append(possibleTypes, member.String())
+		object, inner = member, field
 	}
-
 	if len(possibleTypes) > 1 {
+		sort.Strings(possibleTypes)
 		return nil, fmt.Errorf("union type field should only return one value, but received: %s", strings.Join(possibleTypes, " "))
 	}
-	return fields, nil
+	if object == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	planned, err := ctx.plan.collect(object, typ, selectionSet)
+	if err != nil {
+		return nil, err
+	}
+	return e.executeFields(ctx, object, inner.Interface(), planned)
 }
 
 func (e *Executor) executeObject(ctx *exeContext, typ *internal.Object, source interface{},
@@ -219,45 +451,52 @@ func (e *Executor) executeObject(ctx *exeContext, typ *internal.Object, source i
 		return nil, nil
 	}
 
-	selections, err := Flatten(selectionSet)
+	planned, err := ctx.plan.collect(typ, nil, selectionSet)
 	if err != nil {
 		return nil, err
 	}
+	return e.executeFields(ctx, typ, source, planned)
+}
 
-	fields := make(map[string]interface{})
+// executeFields resolves the collected fields of an object. A field which fails is null, the object is null
+// instead when the field is non-null and propagates its errors.
+func (e *Executor) executeFields(ctx *exeContext, typ *internal.Object, source interface{},
+	planned plannedFields) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(planned.selections))
+	propagated := false
 
 	// for every selection, resolve the value and store it in the output object
-	for _, selection := range selections {
+	for i, selection := range planned.selections {
+		if ctx.cancelled(selection.Loc) {
+			fields[selection.Alias] = nil
+			continue
+		}
 		func() {
 			ctx.updatePath(true, selection.Alias)
 			defer func() {
 				ctx.updatePath(false)
 			}()
-			field := typ.Fields[selection.Name]
-			if len(selection.Directives) > 0 {
-				for _, directive := range selection.Directives {
-					next, result, err := directive.FnResolve(ctx, directive.ArgVals, field.Resolve, source, selection.Args)
-					if err != nil {
-						ctx.addErr(directive.Loc, err)
-						return
-					}
-					fields[selection.Alias] = result
-					if !next {
-						break
-					}
-				}
-				return
-			}
-
+			field := planned.definitions[i]
 			if selection.Name == "__typename" {
 				fields[selection.Alias] = typ.Name
 				return
 			}
 
 			if field != nil {
-				resolved, err := e.resolveAndExecute(ctx, field, source, selection)
+				parent, location, slots := ctx.field, ctx.location, len(ctx.slots)
+				ctx.field, ctx.location = field, selection.Loc
+				_, nonNull := field.Type.(*internal.NonNull)
+				propagates := nonNull && e.errorPolicy(field) == schemabuilder.PropagateError
+				ctx.slots = append(ctx.slots, slot{object: fields, key: selection.Alias, propagates: propagates})
+				resolved, err := e.resolveAndExecute(ctx, typ, field, source, selection)
+				ctx.field, ctx.location, ctx.slots = parent, location, ctx.slots[:slots]
 				if err != nil {
-					ctx.addErr(selection.Loc, err)
+					if err != errNullPropagated {
+						ctx.addFieldErr(selection.Loc, field, err)
+					}
+					if propagates {
+						propagated = true
+					}
 					fields[selection.Alias] = nil
 					return
 				}
@@ -266,31 +505,82 @@ func (e *Executor) executeObject(ctx *exeContext, typ *internal.Object, source i
 			return
 		}()
 	}
-	return fields, nil
-}
-
-func (e *Executor) resolveAndExecute(ctx *exeContext, field *internal.Field, source interface{},
-	selection *internal.Selection) (interface{}, error) {
-	value, err := safeExecuteResolver(ctx.Context, field, source, selection.Args)
-	if err != nil {
-		return nil, err
+	if propagated {
+		return nil, errNullPropagated
 	}
-	return e.execute(ctx, field.Type, value, selection.SelectionSet)
+	return fields, nil
 }
 
-func safeExecuteResolver(ctx context.Context, field *internal.Field, source, args interface{}) (result interface{}, err error) {
+func (e *Executor) resolveAndExecute(ctx *exeContext, typ *internal.Object, field *internal.Field, source interface{},
+	selection *internal.Selection) (result interface{}, err error) {
+	// a panic while resolving or completing the value only fails this field
+	depth := len(ctx.path)
 	defer func() {
 		if panicErr := recover(); panicErr != nil {
-			const size = 64 << 10
-			buf := make([]byte, size)
-			buf = buf[:runtime.Stack(buf, false)]
-			result, err = nil, fmt.Errorf("graphql: panic: %v\n%s", panicErr, buf)
+			ctx.path = ctx.path[:depth]
+			result, err = nil, e.recoverPanic(ctx.Context, panicErr, stack())
 		}
 	}()
-	return field.Resolve(ctx, source, args)
+	resolve := e.fieldResolve(ctx, field)
+	// the directives of the field wrap its resolver, the first directive is the outermost,
+	// @skip and @include have been applied while collecting the fields
+	for i := len(selection.Directives) - 1; i >= 0; i-- {
+		directive := selection.Directives[i]
+		if directive.Name == "skip" || directive.Name == "include" {
+			continue
+		}
+		next := resolve
+		resolve = func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			// the wrapped resolver is called once, be it by the directive or after it
+			var called bool
+			var value interface{}
+			var err error
+			once := func(ctx context.Context, source, args interface{}) (interface{}, error) {
+				if !called {
+					called = true
+					value, err = next(ctx, source, args)
+				}
+				return value, err
+			}
+			proceed, result, dirErr := directive.FnResolve(ctx, directive.ArgVals, once, source, args)
+			if dirErr != nil || !proceed {
+				return result, dirErr
+			}
+			return once(ctx, source, args)
+		}
+	}
+	resolve = traceResolve(ctx, typ, field, resolve)
+	timeout := field.Timeout
+	if timeout == 0 {
+		timeout = e.FieldTimeout
+	}
+	var value interface{}
+	if timeout > 0 {
+		value, err = e.resolveWithTimeout(ctx.Context, timeout, resolve, source, selection.Args)
+	} else {
+		value, err = resolve(ctx.Context, source, selection.Args)
+	}
+	if err != nil {
+		return nil, locateInputError(selection, err)
+	}
+	if thunk, ok := value.(internal.Thunk); ok {
+		marker := &deferred{}
+		ctx.deferred = append(ctx.deferred, &deferredField{
+			marker:    marker,
+			path:      append([]interface{}(nil), ctx.path...),
+			slots:     append([]slot(nil), ctx.slots...),
+			field:     field,
+			selection: selection,
+			thunk:     thunk,
+		})
+		ctx.hasDeferred = true
+		return marker, nil
+	}
+	return e.execute(ctx, field.Type, value, selection.SelectionSet)
 }
 
-// executeList executes a set query
+// executeList executes a set query. An item which fails is null and its error is reported, the whole list fails
+// when the items are non-null. The failed items are left out with OmitOnError.
 func (e *Executor) executeList(ctx *exeContext, typ *internal.List, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, error) {
 	if reflect.ValueOf(source).IsNil() {
@@ -299,16 +589,37 @@ func (e *Executor) executeList(ctx *exeContext, typ *internal.List, source inter
 
 	// iterate over arbitrary slice types using reflect
 	slice := reflect.ValueOf(source)
-	items := make([]interface{}, slice.Len())
+	items := make([]interface{}, 0, slice.Len())
 
 	// resolve every element in the slice
+	_, nonNull := typ.Type.(*internal.NonNull)
+	omit := e.errorPolicy(ctx.field) == schemabuilder.OmitOnError
 	for i := 0; i < slice.Len(); i++ {
 		value := slice.Index(i)
+		ctx.updatePath(true, i)
+		// items has the capacity of all the items, the slot of the item is not moved by the next ones
+		ctx.slots = append(ctx.slots, slot{items: items[:slice.Len()], index: len(items), propagates: nonNull && !omit,
+			omit: omit})
 		resolved, err := e.execute(ctx, typ.Type, value.Interface(), selectionSet)
+		ctx.slots = ctx.slots[:len(ctx.slots)-1]
+		if err != nil && (!nonNull || omit) {
+			if err != errNullPropagated {
+				ctx.addFieldErr(ctx.location, ctx.field, err)
+			}
+			if omit {
+				ctx.updatePath(false)
+				continue
+			}
+			resolved, err = nil, nil
+		}
+		if _, ok := err.(*itemError); err != nil && err != errNullPropagated && !ok {
+			err = &itemError{error: err, path: append([]interface{}(nil), ctx.path...)}
+		}
+		ctx.updatePath(false)
 		if err != nil {
 			return nil, err
 		}
-		items[i] = resolved
+		items = append(items, resolved)
 	}
 
 	return items, nil
@@ -345,22 +656,8 @@ func (e *Executor) executeInterface(ctx *exeContext, typ *internal.Interface, so
 		return nil, fmt.Errorf("can not find the type for interface %s", typ.Name)
 	}
 
-	typString, graphqlTyp := object.Name, object
-
-	// modifiedSelectionSet selection set contains fragments on typString
-	modifiedSelectionSet := &internal.SelectionSet{
-		Selections: selectionSet.Selections,
-		Fragments:  []*internal.FragmentSpread{},
-	}
-	for _, f := range selectionSet.Fragments {
-		if f.Fragment.On == typString {
-			modifiedSelectionSet.Fragments = append(modifiedSelectionSet.Fragments, f)
-		} else if _, ok := graphqlTyp.Interfaces[f.Fragment.On]; ok {
-			modifiedSelectionSet.Fragments = append(modifiedSelectionSet.Fragments, f)
-		}
-	}
-
-	return e.executeObject(ctx, object, source, modifiedSelectionSet)
+	// the fragments not applying to object are left out while collecting its fields
+	return e.executeObject(ctx, object, source, selectionSet)
 }
 
 func findDirectiveWithName(directives []*internal.Directive, name string) *internal.Directive {
@@ -1,14 +1,33 @@
 package execution_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/dataloader"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
 	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type Pet interface {
@@ -24,6 +43,11 @@ func (d Dog) GetName() string {
 	return d.Name
 }
 
+type Animal struct {
+	Dog *Dog
+	Cat *Cat
+}
+
 type Cat struct {
 	Name  string `graphql:"name"`
 	Meows bool   `graphql:"meows"`
@@ -393,6 +417,22 @@ func TestExecutor_Execute(t *testing.T) {
 				assert.JSONEq(t, `{"a":"a"}`, string(marshal))
 			})
 		})
+
+		t.Run("works with variables", func(t *testing.T) {
+			query := `
+        query ($skip: Boolean!, $include: Boolean = true) {
+          a @include(if: $include)
+          b @skip(if: $skip)
+        }
+      `
+			result, err := execution.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"skip": true}})
+			assert.Equal(t, errors.MultiError(nil), err)
+			assert.Equal(t, map[string]interface{}{"a": "a"}, result)
+
+			result, err = execution.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"skip": false, "include": false}})
+			assert.Equal(t, errors.MultiError(nil), err)
+			assert.Equal(t, map[string]interface{}{"b": "b"}, result)
+		})
 	})
 
 	t.Run("Execute: Handles basic execution tasks", func(t *testing.T) {
@@ -623,17 +663,2445 @@ func TestExecutor_Execute(t *testing.T) {
 	})
 }
 
-func check(t *testing.T, testType interface{}, testData interface{}, expected interface{}) {
+func TestExecutor_DecodeArguments(t *testing.T) {
+	type Filter struct {
+		Name  string   `graphql:"name"`
+		Limit int      `graphql:"limit"`
+		Tags  []string `graphql:"tags"`
+	}
 	build := schemabuilder.NewSchema()
-	build.Query().FieldFunc("test", testType, "")
-	build.Query().FieldFunc("nest", func() schemabuilder.Query { return schemabuilder.Query{} }, "")
+	build.InputObject("Filter", Filter{}).FieldDefault("limit", 10.0)
+	build.Query().FieldFunc("search", func(args struct {
+		Filter  *Filter  `graphql:"filter"`
+		Filters []Filter `graphql:"filters"`
+		Page    *int     `graphql:"page"`
+	}) string {
+		result := fmt.Sprint(args.Filter, args.Filters)
+		if args.Page != nil {
+			result += fmt.Sprint(" page ", *args.Page)
+		}
+		return result
+	})
+	schema := build.MustBuild()
+
+	const query = `{
+  a: search(filter: {name: "luke", tags: ["jedi"]}, page: 2)
+  b: search(filters: [{name: "leia", limit: 1}, {name: "han"}])
+  c: search
+}`
+	expected := map[string]interface{}{
+		"a": "&{luke 10 [jedi]} [] page 2",
+		"b": "<nil> [{leia 1 []} {han 10 []}]",
+		"c": "<nil> []",
+	}
+	// the arguments of the operation are shared by its executions, they are not changed by the defaults
+	for i := 0; i < 2; i++ {
+		result, err := execution.Do(schema, execution.Params{Query: query})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, expected, result)
+	}
+}
+
+type JSON map[string]interface{}
+
+func TestScalar_ParseLiteral(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Scalar("JSON", JSON{}, func(value interface{}, dest reflect.Value) error {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("not an object")
+		}
+		dest.Set(reflect.ValueOf(JSON(object)))
+		return nil
+	}).LiteralFunc(func(value ast.Value) (interface{}, error) {
+		object, ok := value.(*ast.ObjectValue)
+		if !ok {
+			return nil, fmt.Errorf("not an object")
+		}
+		// the literal keeps the kinds of its values, which a variable does not tell
+		parsed := JSON{}
+		for _, field := range object.Fields {
+			parsed[field.Name.Name.Name] = fmt.Sprintf("%s:%v", field.Value.GetKind(), field.Value.GetValue())
+		}
+		return parsed, nil
+	})
+	build.Query().FieldFunc("echo", func(args struct {
+		Value JSON   `graphql:"value"`
+		List  []JSON `graphql:"list"`
+	}) string {
+		return fmt.Sprint(args.Value, args.List)
+	})
+	schema := build.MustBuild()
+
+	t.Run("parses the literals with ParseLiteral", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ echo(value: {a: 1, b: "x"}, list: [{c: true}]) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"echo": "map[a:IntValue:1 b:StringValue:x] [map[c:BooleanValue:true]]"}, result)
+	})
+
+	t.Run("parses the variables with ParseValue", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($v: JSON!) { echo(value: $v) }`,
+			Variables: map[string]interface{}{"v": map[string]interface{}{"a": 1.0}},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"echo": "map[a:1] []"}, result)
+	})
+
+	t.Run("rejects the literals ParseLiteral fails on", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ echo(value: "x") }`})
+		assert.EqualError(t, err, "[graphql: Expected value of type \"JSON!\", found \"x\". (1:15)]")
+	})
+}
+
+func TestScalar_Integers(t *testing.T) {
+	build := func(configure func(*schemabuilder.Schema)) *internal.Schema {
+		build := schemabuilder.NewSchema()
+		configure(build)
+		build.Query().FieldFunc("count", func(args struct {
+			N int `graphql:"n"`
+		}) int {
+			return args.N * 1000
+		})
+		build.Query().FieldFunc("id", func(args struct {
+			ID int64 `graphql:"id"`
+		}) int64 {
+			return args.ID + 1
+		})
+		build.Query().FieldFunc("big", func(args struct {
+			N *big.Int `graphql:"n"`
+		}) *big.Int {
+			return new(big.Int).Mul(args.N, big.NewInt(2))
+		})
+		return build.MustBuild()
+	}
+	schema := build(func(s *schemabuilder.Schema) {
+		s.UseScalar(schemabuilder.Int64String)
+		s.UseScalar(schemabuilder.BigInt)
+	})
+
+	t.Run("fails the Int fields out of range", func(t *testing.T) {
+		result, err := unmasked.Do(schema, execution.Params{Query: `{ count(n: 3000000) }`})
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "Int cannot represent non 32-bit signed integer value: 3000000000")
+
+		allowing := build(func(s *schemabuilder.Schema) { s.SetIntOverflow(schemabuilder.IntOverflowAllow) })
+		result, err = execution.Do(allowing, execution.Params{Query: `{ count(n: 3000000) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"count": 3000000000}, result)
+	})
+
+	t.Run("rejects the Int arguments out of range", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{
+			Query:     `query ($n: Int!) { count(n: $n) }`,
+			Variables: map[string]interface{}{"n": 3000000000.0},
+		})
+		assert.Error(t, err)
+		_, err = execution.Do(schema, execution.Params{
+			Query:     `query ($n: Int!) { count(n: $n) }`,
+			Variables: map[string]interface{}{"n": 1.5},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("serializes Int64String and BigInt as strings", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($id: Int64!) { a: id(id: 9007199254740993) b: id(id: "41") c: id(id: $id) big(n: 123456789012345678901234567890) }`,
+			Variables: map[string]interface{}{"id": "9223372036854775806"},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{
+			"a":   "9007199254740994",
+			"b":   "42",
+			"c":   "9223372036854775807",
+			"big": "246913578024691357802469135780",
+		}, result)
+	})
+
+	t.Run("keeps the integers of the variables exact", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query: `query ($a: Int64!, $b: Int64!, $big: BigInt!) { a: id(id: $a) b: id(id: $b) big(n: $big) }`,
+			Variables: map[string]interface{}{
+				"a":   json.Number("9007199254740993"),
+				"b":   int64(9007199254740995),
+				"big": json.Number("123456789012345678901234567890"),
+			},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{
+			"a":   "9007199254740994",
+			"b":   "9007199254740996",
+			"big": "246913578024691357802469135780",
+		}, result)
+	})
+
+	t.Run("rejects the Int64String arguments out of range", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ id(id: "9223372036854775808") }`})
+		assert.Error(t, err)
+	})
+}
+
+func TestScalar_ID(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("node", func(args struct {
+		ID schemabuilder.Id `graphql:"id"`
+	}) schemabuilder.Id {
+		return args.ID
+	})
+	build.Query().FieldFunc("stored", func() []schemabuilder.Id {
+		return []schemabuilder.Id{{Value: 42}, {Value: int64(9007199254740993)}, {Value: "a1"}}
+	})
+	schema := build.MustBuild()
+
+	t.Run("accepts strings and integers", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($a: ID!, $b: ID!) { s: node(id: "x1") i: node(id: 12345678901234567890) a: node(id: $a) b: node(id: $b) }`,
+			Variables: map[string]interface{}{"a": 7.0, "b": "y2"},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"s": "x1", "i": "12345678901234567890", "a": "7", "b": "y2"}, result)
+	})
+
+	t.Run("serializes as strings", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ stored }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"stored": []interface{}{"42", "9007199254740993", "a1"}}, result)
+	})
+
+	t.Run("rejects the other values", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ node(id: 1.5) }`})
+		assert.Error(t, err)
+		_, err = execution.Do(schema, execution.Params{
+			Query:     `query ($a: ID!) { node(id: $a) }`,
+			Variables: map[string]interface{}{"a": true},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestInputObject_OneOf(t *testing.T) {
+	type UserBy struct {
+		ID    *int64  `graphql:"id"`
+		Email *string `graphql:"email"`
+	}
+
+	build := schemabuilder.NewSchema()
+	build.InputObject("UserBy", UserBy{}).OneOf = true
+	build.Query().FieldFunc("user", func(args struct {
+		By UserBy `graphql:"by"`
+	}) string {
+		if args.By.ID != nil {
+			return fmt.Sprint("id:", *args.By.ID)
+		}
+		return "email:" + *args.By.Email
+	})
+	schema := build.MustBuild()
+
+	t.Run("accepts exactly one field", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ user(by: {email: "a@b.c"}) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"user": "email:a@b.c"}, result)
+
+		result, err = execution.Do(schema, execution.Params{
+			Query:     `query ($by: UserBy!) { user(by: $by) }`,
+			Variables: map[string]interface{}{"by": map[string]interface{}{"id": 1.0}},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"user": "id:1"}, result)
+	})
+
+	t.Run("rejects several fields", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ user(by: {id: 1, email: "a@b.c"}) }`})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `OneOf Input Object "UserBy" must specify exactly one key.`)
+	})
+
+	t.Run("rejects a null field", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ user(by: {id: null}) }`})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `Field "UserBy.id" must be non-null.`)
+	})
+
+	t.Run("rejects several fields in variables", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{
+			Query:     `query ($by: UserBy!) { user(by: $by) }`,
+			Variables: map[string]interface{}{"by": map[string]interface{}{"id": 1.0, "email": "a@b.c"}},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must specify exactly one key")
+	})
+
+	t.Run("rejects nullable variables in the fields", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{
+			Query:     `query ($id: Int64) { user(by: {id: $id}) }`,
+			Variables: map[string]interface{}{"id": 1.0},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestOmittable(t *testing.T) {
+	type UpdateUser struct {
+		Name     schemabuilder.Omittable[string]  `graphql:"name"`
+		Nickname schemabuilder.Omittable[*string] `graphql:"nickname"`
+	}
+
+	build := schemabuilder.NewSchema()
+	build.InputObject("UpdateUser", UpdateUser{})
+	build.Query().FieldFunc("update", func(args struct {
+		Input UpdateUser                    `graphql:"input"`
+		Age   schemabuilder.Omittable[*int] `graphql:"age"`
+	}) string {
+		describe := func(set bool, value interface{}) string {
+			if !set {
+				return "omitted"
+			}
+			return fmt.Sprint(value)
+		}
+		nickname := interface{}(nil)
+		if v := args.Input.Nickname.Value(); v != nil {
+			nickname = *v
+		}
+		age := interface{}(nil)
+		if v := args.Age.Value(); v != nil {
+			age = *v
+		}
+		return strings.Join([]string{
+			describe(args.Input.Name.IsSet(), args.Input.Name.Value()),
+			describe(args.Input.Nickname.IsSet(), nickname),
+			describe(args.Age.IsSet(), age),
+		}, ",")
+	})
+	schema := build.MustBuild()
+
+	t.Run("tells omitted fields from null fields", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ update(input: {name: "Luke", nickname: null}) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"update": "Luke,<nil>,omitted"}, result)
+
+		result, err = execution.Do(schema, execution.Params{Query: `{ update(input: {nickname: "Skywalker"}, age: null) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"update": "omitted,Skywalker,<nil>"}, result)
+	})
+
+	t.Run("tells omitted fields from null fields in variables", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($input: UpdateUser!, $age: Int) { update(input: $input, age: $age) }`,
+			Variables: map[string]interface{}{"input": map[string]interface{}{"name": nil}, "age": 3.0},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"update": ",omitted,3"}, result)
+	})
+
+	t.Run("has the nullable type of its value", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ update(input: {name: 1}) }`})
+		assert.Error(t, err)
+	})
+}
+
+func TestInputObject_Tags(t *testing.T) {
+	type Window struct {
+		From  time.Time  `graphql:"from"`
+		Until *time.Time `graphql:"until"`
+	}
+	type Filter struct {
+		Windows []*Window `graphql:"windows"`
+		Main    *Window   `graphql:"main"`
+		Limit   *int      `graphql:"limit"`
+		Owner   string
+	}
+
+	build := schemabuilder.NewSchema()
+	// Window is mapped from its tags without being registered
+	build.InputObject("Filter", Filter{})
+	build.Query().FieldFunc("search", func(args struct {
+		Filter Filter `graphql:"filter"`
+	}) string {
+		var windows []string
+		for _, w := range append(args.Filter.Windows, args.Filter.Main) {
+			if w == nil {
+				windows = append(windows, "<nil>")
+			} else if w.Until == nil {
+				windows = append(windows, w.From.Format("2006-01-02")+"..")
+			} else {
+				windows = append(windows, w.From.Format("2006-01-02")+".."+w.Until.Format("2006-01-02"))
+			}
+		}
+		return fmt.Sprintf("%v %v %s", windows, args.Filter.Limit != nil, args.Filter.Owner)
+	})
+	schema := build.MustBuild()
+
+	t.Run("decodes the nested inputs", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ search(filter: {
+			windows: [{from: "2024-01-02T00:00:00Z"}],
+			main: {from: "2024-01-02T00:00:00Z", until: "2024-02-03T00:00:00Z"},
+			Owner: "luke"
+		}) }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"search": "[2024-01-02.. 2024-01-02..2024-02-03] false luke"}, result)
+	})
+
+	t.Run("decodes the nested inputs of variables", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query: `query ($filter: Filter!) { search(filter: $filter) }`,
+			Variables: map[string]interface{}{"filter": map[string]interface{}{
+				"windows": []interface{}{map[string]interface{}{"from": "2024-01-02T00:00:00Z", "until": nil}},
+				"limit":   2.0,
+				"Owner":   "han",
+			}},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"search": "[2024-01-02.. <nil>] true han"}, result)
+	})
+
+	t.Run("rejects the objects as inputs", func(t *testing.T) {
+		build := schemabuilder.NewSchema()
+		build.Object("Window", Window{})
+		build.Query().FieldFunc("search", func(args struct {
+			Window Window `graphql:"window"`
+		}) string {
+			return ""
+		})
+		_, err := build.Build()
+		assert.Error(t, err)
+	})
+}
+
+func TestInputObject_Validate(t *testing.T) {
+	type DateRange struct {
+		Start int `graphql:"start"`
+		End   int `graphql:"end"`
+	}
+	type Filter struct {
+		Range *DateRange `graphql:"range"`
+	}
+
+	build := schemabuilder.NewSchema()
+	build.InputObject("DateRange", DateRange{}).Validate(func(ctx context.Context, value interface{}) error {
+		if r := value.(DateRange); r.Start > r.End {
+			return fmt.Errorf("start must be before end")
+		}
+		return nil
+	})
+	build.InputObject("Filter", Filter{})
+	build.Query().FieldFunc("count", func(args struct {
+		Filter Filter `graphql:"filter"`
+	}) int {
+		return args.Filter.Range.End - args.Filter.Range.Start
+	})
+	schema := build.MustBuild()
+
+	t.Run("passes valid input", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query: `{ count(filter: {range: {start: 1, end: 3}}) }`,
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"count": 2}, result)
+	})
+
+	t.Run("attributes errors to the input object path", func(t *testing.T) {
+		result, err := unmasked.Do(schema, execution.Params{
+			Query: `query ($f: Filter!) { count(filter: $f) }`,
+			Variables: map[string]interface{}{"f": map[string]interface{}{
+				"range": map[string]interface{}{"start": 3.0, "end": 2.0},
+			}},
+		})
+		assert.EqualError(t, err, `[graphql: invalid value for "filter.range": start must be before end (1:37) path: [count]]`)
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, map[string]interface{}{"code": errors.CodeBadUserInput, "inputPath": "variables.f.range"}, err[0].Extensions)
+		}
+		assert.Nil(t, result)
+	})
+
+	t.Run("locates the errors of literals at the input object", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query: `{ count(filter: {range: {start: 3, end: 2}}) }`,
+		})
+		assert.EqualError(t, err, `[graphql: invalid value for "filter.range": start must be before end (1:25) path: [count]]`)
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, map[string]interface{}{"code": errors.CodeBadUserInput}, err[0].Extensions)
+		}
+		assert.Nil(t, result)
+	})
+
+	t.Run("locates the errors of nested variables", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{
+			Query:     `query ($r: DateRange) { count(filter: {range: $r}) }`,
+			Variables: map[string]interface{}{"r": map[string]interface{}{"start": 3, "end": 2}},
+		})
+		assert.EqualError(t, err, `[graphql: invalid value for "filter.range": start must be before end (1:47) path: [count]]`)
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, "variables.r", err[0].Extensions["inputPath"])
+		}
+	})
+}
+
+func TestExecutor_CoerceVariables(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("sum", func(args struct {
+		A int   `graphql:"a"`
+		B []int `graphql:"b"`
+	}) int {
+		for _, b := range args.B {
+			args.A += b
+		}
+		return args.A
+	})
+	schema := build.MustBuild()
+	query := `query ($a: Int! = 1, $b: [Int!]) { sum(a: $a, b: $b) }`
+
+	t.Run("applies default values", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: query})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"sum": 1}, result)
+	})
+
+	t.Run("coerces go values and single values to lists", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"a": 2, "b": int64(3)}})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"sum": 5}, result)
+	})
+
+	t.Run("rejects missing non-null variables", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `query ($a: Int!) { sum(a: $a) }`})
+		assert.EqualError(t, err, "[graphql: Variable \"a\" has invalid value null.\nExpected type \"Int!\", found null. (1:8)]")
+	})
+
+	t.Run("rejects values which can not be coerced", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"a": 1.5, "b": []interface{}{1, "2", nil}}})
+		assert.EqualError(t, err, "[graphql: Variable \"a\" has invalid value 1.5.\nExpected type \"Int\", found 1.5. (1:8)\n"+
+			"graphql: Variable \"b[1]\" has invalid value 2.\nExpected type \"Int\", found 2. (1:22)\n"+
+			"graphql: Variable \"b[2]\" has invalid value null.\nExpected type \"Int!\", found null. (1:22)]")
+	})
+
+	t.Run("rejects variables of unknown types", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `query ($a: Number) { sum(a: $a) }`})
+		assert.EqualError(t, err, "[graphql: Unknown type \"Number\". (1:8)]")
+	})
+
+	t.Run("rejects variables not defined by the operation", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"c": 1, "a": 1}})
+		assert.EqualError(t, err, "[graphql: Variable \"$c\" is not defined by the operation.]")
+	})
+
+	t.Run("reports the input path and expected type of invalid values", func(t *testing.T) {
+		type Address struct {
+			Zip string `graphql:"zip"`
+		}
+		type Person struct {
+			Addresses []Address `graphql:"addresses"`
+		}
+		build := schemabuilder.NewSchema()
+		build.InputObject("Address", Address{})
+		build.InputObject("Person", Person{})
+		build.Query().FieldFunc("count", func(args struct {
+			Input Person `graphql:"input"`
+		}) int {
+			return len(args.Input.Addresses)
+		})
+		addresses := []interface{}{
+			map[string]interface{}{"zip": "75001"},
+			map[string]interface{}{"zip": "10115"},
+			map[string]interface{}{"zip": 94103, "street": "Main"},
+		}
+		_, err := execution.Do(build.MustBuild(), execution.Params{
+			Query:     `query ($input: Person!) { count(input: $input) }`,
+			Variables: map[string]interface{}{"input": map[string]interface{}{"addresses": addresses}},
+		})
+		if !assert.Len(t, err, 2) {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{
+			"code":      errors.CodeBadUserInput,
+			"inputPath": "variables.input.addresses[2].street",
+		}, err[0].Extensions)
+		assert.EqualError(t, err[1], "graphql: Variable \"input.addresses[2].zip\" has invalid value 94103.\nExpected type \"String\", found 94103. (1:8)")
+		assert.Equal(t, map[string]interface{}{
+			"code":         errors.CodeBadUserInput,
+			"inputPath":    "variables.input.addresses[2].zip",
+			"expectedType": "String",
+		}, err[1].Extensions)
+	})
+}
+
+type Hero struct {
+	Name string `graphql:"name"`
+}
+
+// unmasked runs the operations of the tests checking the errors of the resolvers.
+var unmasked = &execution.Executor{UnmaskedErrors: true}
+
+func TestExecutor_Replay(t *testing.T) {
+	gob.Register([]*Hero{})
+	calls := 0
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("heroes", func(args struct {
+		Limit int `graphql:"limit"`
+	}) []*Hero {
+		calls++
+		return []*Hero{{Name: "R2-D2"}, {Name: "Luke"}}[:args.Limit]
+	})
+	build.Query().FieldFunc("villain", func() (*Hero, error) {
+		calls++
+		return nil, fmt.Errorf("no villain")
+	})
+	schema := build.MustBuild()
+
+	recorder := execution.NewRecorder()
+	expected, expectedErr := unmasked.Do(schema, execution.Params{
+		Query:     `query ($limit: Int!) { heroes(limit: $limit) { name } villain { name } }`,
+		Variables: map[string]interface{}{"limit": 2.0},
+		Context:   execution.WithRecorder(context.Background(), recorder),
+	})
+	assert.EqualError(t, expectedErr, "[graphql: no villain (1:63) path: [villain]]")
+	assert.Equal(t, 2, calls)
+
+	var buf bytes.Buffer
+	assert.NoError(t, recorder.Snapshot().Encode(&buf))
+	snapshot, err := execution.ReadSnapshot(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"limit": int64(2)}, snapshot.Resolves["heroes"].Args)
+
+	result, errs := execution.Replay(schema, snapshot)
+	assert.Equal(t, expected, result)
+	assert.Equal(t, expectedErr.Error(), errs.Error())
+	assert.Equal(t, 2, calls)
+}
+
+func TestExecutor_FieldDirectives(t *testing.T) {
+	calls := 0
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("secret", func() string {
+		calls++
+		return "open sesame"
+	})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Object("Hero", Hero{})
+	build.Directive("uppercase", []string{"FIELD"}, func(fn func() (interface{}, error)) (interface{}, error) {
+		result, err := fn()
+		if s, ok := result.(string); ok {
+			return strings.ToUpper(s), err
+		}
+		return result, err
+	})
+	build.Directive("mask", []string{"FIELD"}, func(args struct {
+		Keep int `graphql:"keep"`
+	}, fn func() (interface{}, error)) (interface{}, error) {
+		result, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		s := result.(string)
+		if len(s) <= args.Keep {
+			return s, nil
+		}
+		return s[:args.Keep] + strings.Repeat("*", len(s)-args.Keep), nil
+	}).FieldDefault("keep", 4.0)
+	build.Directive("cached", []string{"FIELD"}, func(args struct {
+		Hit bool `graphql:"hit"`
+	}, fn func() (interface{}, error)) (bool, interface{}, error) {
+		if args.Hit {
+			return false, "cached", nil
+		}
+		// looks at the value, the field still resolves
+		_, err := fn()
+		return true, nil, err
+	})
+	schema := build.MustBuild()
+
+	t.Run("wraps the resolver", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ secret @uppercase hero { name @uppercase } }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"secret": "OPEN SESAME", "hero": map[string]interface{}{"name": "LUKE"}}, result)
+	})
+
+	t.Run("receives coerced arguments", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($keep: Int) { a: secret @mask b: secret @mask(keep: $keep) }`,
+			Variables: map[string]interface{}{"keep": 2},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"a": "open*******", "b": "op*********"}, result)
+	})
+
+	t.Run("lets the field resolve or stops it", func(t *testing.T) {
+		calls = 0
+		result, err := execution.Do(schema, execution.Params{
+			Query: `{ a: secret @cached(hit: true) b: secret @cached(hit: false) c: secret @uppercase @cached(hit: false) }`,
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"a": "cached", "b": "open sesame", "c": "OPEN SESAME"}, result)
+		assert.Equal(t, 2, calls, "the resolver is called once by field")
+	})
+}
+
+func TestExecutor_Warnings(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("heroes", func(ctx context.Context) []string {
+		execution.AddWarning(ctx, &execution.Warning{Message: "heroes truncated to 1", Path: []interface{}{"heroes"}})
+		return []string{"Luke"}
+	})
+	build.Query().FieldFunc("villain", func(ctx context.Context) string {
+		execution.Warn(ctx, "villain is deprecated, use %s", "villains")
+		return "Vader"
+	})
 	schema := build.MustBuild()
+
+	warnings := &execution.Warnings{}
 	result, err := execution.Do(schema, execution.Params{
-		Query:         "{ nest { test } }",
-		OperationName: "",
-		Variables:     nil,
-		Context:       context.WithValue(context.Background(), "test", testData),
+		Query:   `{ heroes villain }`,
+		Context: execution.WithWarnings(context.Background(), warnings),
 	})
 	assert.Equal(t, errors.MultiError(nil), err)
-	assert.Equal(t, expected, result)
+	assert.Equal(t, map[string]interface{}{"heroes": []interface{}{"Luke"}, "villain": "Vader"}, result)
+	assert.ElementsMatch(t, []*execution.Warning{
+		{Message: "heroes truncated to 1", Path: []interface{}{"heroes"}},
+		{Message: "villain is deprecated, use villains"},
+	}, warnings.List())
+
+	t.Run("warnings are dropped when not collected", func(t *testing.T) {
+		_, err := execution.Do(schema, execution.Params{Query: `{ villain }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+	})
+}
+
+func TestExecutor_RecoverFunc(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("a", func() string { return "a" })
+	build.Query().FieldFunc("b", func() *string { panic("b is broken") })
+	build.Query().FieldFunc("c", func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}} })
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(h *Hero) string {
+		if h.Name == "Leia" {
+			panic("no friend")
+		}
+		return "Han"
+	})
+	schema := build.MustBuild()
+
+	t.Run("masks the panic by default", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `{ a b }`})
+		assert.Equal(t, map[string]interface{}{"a": "a", "b": nil}, result)
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, execution.InternalErrorMessage, err[0].Message)
+		}
+
+		// the stack trace is left out of the message
+		_, err = unmasked.Do(schema, execution.Params{Query: `{ a b }`})
+		assert.EqualError(t, err, "[graphql: graphql: panic: b is broken (1:5) path: [b]]")
+	})
+
+	t.Run("uses the RecoverFunc", func(t *testing.T) {
+		var stack []byte
+		executor := &execution.Executor{UnmaskedErrors: true, RecoverFunc: func(ctx context.Context, panicValue interface{},
+			s []byte) error {
+			stack = s
+			return fmt.Errorf("internal error: %v", panicValue)
+		}}
+		result, err := executor.Do(schema, execution.Params{Query: `{ b a c { friend } }`})
+		assert.Equal(t, map[string]interface{}{"a": "a", "b": nil, "c": []interface{}{
+			map[string]interface{}{"friend": "Han"},
+			nil,
+		}}, result)
+		var messages []string
+		for _, err := range err {
+			messages = append(messages, err.Error())
+		}
+		assert.ElementsMatch(t, []string{
+			"graphql: internal error: b is broken (1:3) path: [b]",
+			"graphql: internal error: no friend (1:11) path: [c 1 friend]",
+		}, messages)
+		assert.True(t, strings.HasPrefix(string(stack), "goroutine"))
+	})
+}
+
+func TestExecutor_Owner(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("invoice", func() (*Hero, error) { return nil, fmt.Errorf("billing is down") }, schemabuilder.Owner("billing"))
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{} })
+	hero := build.Object("Hero", Hero{})
+	hero.Extensions = map[string]interface{}{internal.OwnerExtension: "heroes"}
+	hero.FieldFunc("friend", func() (string, error) { return "", fmt.Errorf("no friend") })
+	hero.FieldFunc("ship", func() (string, error) { return "", fmt.Errorf("no ship") }, schemabuilder.Owner("fleet"))
+	starship := build.DynamicObject("Starship")
+	starship.Extensions = map[string]interface{}{internal.OwnerExtension: "fleet"}
+	starship.AddField(schemabuilder.DynamicField{
+		Name: "name",
+		Type: "String",
+		Resolve: func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("no starship name")
+		},
+	})
+	build.DynamicObject("Query").AddField(schemabuilder.DynamicField{
+		Name: "starship",
+		Type: "Starship",
+		Resolve: func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			return map[string]interface{}{}, nil
+		},
+	})
+	schema := build.MustBuild()
+
+	_, err := unmasked.Do(schema, execution.Params{Query: `{ invoice { name } hero { friend ship } starship { name } }`})
+	assert.Len(t, err, 4)
+	owners := make(map[string]interface{})
+	for _, err := range err {
+		owners[err.Message] = err.Extensions["owner"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"billing is down": "billing", "no friend": "heroes", "no ship": "fleet", "no starship name": "fleet",
+	}, owners)
+	assert.Equal(t, "heroes", schema.TypeMap["Hero"].(*internal.Object).Fields["friend"].Owner())
+}
+
+func TestExecutor_ErrorExtensions(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("me", func() (string, error) {
+		return "", errors.New("not signed in").WithCode(errors.CodeUnauthenticated)
+	}, schemabuilder.Owner("accounts"))
+	build.Query().FieldFunc("invoice", func() (string, error) {
+		return "", fmt.Errorf("billing: %w", errors.New("card declined").WithExtension("retryable", true))
+	})
+	schema := build.MustBuild()
+
+	_, errs := execution.Do(schema, execution.Params{Query: `{ me invoice }`})
+	assert.Len(t, errs, 2)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Message < errs[j].Message })
+	assert.Equal(t, "billing: graphql: card declined", errs[0].Message)
+	assert.Equal(t, map[string]interface{}{"retryable": true}, errs[0].Extensions)
+	assert.Equal(t, "not signed in", errs[1].Message)
+	assert.Equal(t, map[string]interface{}{"code": "UNAUTHENTICATED", "owner": "accounts"}, errs[1].Extensions)
+	assert.Equal(t, errors.CodeUnauthenticated, errors.Code(errs[1].ResolverError))
+}
+
+func TestExecutor_ErrorCodes(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func(args struct {
+		ID int `graphql:"id"`
+	}) string {
+		return "Luke"
+	})
+	schema := build.MustBuild()
+
+	for query, code := range map[string]string{
+		`{ hero(id: 1) `:                              errors.CodeParseFailed,
+		`{ hero(id: 1) { name } }`:                    errors.CodeValidationFailed,
+		`query($id: Int!) { hero(id: $id) }`:          errors.CodeBadUserInput,
+		`{ hero(id: 1) }`:                             "",
+		`query { hero(id: 1) } query { hero(id: 2) }`: errors.CodeValidationFailed,
+	} {
+		_, errs := execution.Do(schema, execution.Params{Query: query})
+		if code == "" {
+			assert.Empty(t, errs, query)
+			continue
+		}
+		if assert.NotEmpty(t, errs, query) {
+			assert.Equal(t, code, errors.Code(errs[0]), query)
+		}
+	}
+}
+
+type notFoundError struct{ id int }
+
+func (e notFoundError) Error() string { return fmt.Sprintf("no hero %d", e.id) }
+
+type Episode int
+
+func TestExecutor_ErrorPositions(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Enum("Episode", Episode(0), map[string]interface{}{"NEWHOPE": Episode(4), "EMPIRE": Episode(5)})
+	build.Query().FieldFunc("episodes", func() [][]Episode { return [][]Episode{{4}, {5, 7}} })
+	build.Query().FieldFunc("heroes", func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}} })
+	hero := build.Object("Hero", Hero{})
+	hero.FieldFunc("ship", func(h *Hero) (string, error) {
+		if h.Name == "Leia" {
+			return "", fmt.Errorf("no ship")
+		}
+		return "X-wing", nil
+	})
+	schema := build.MustBuild()
+
+	_, errs := unmasked.Do(schema, execution.Params{Query: `{
+		episodes
+		heroes { ship }
+	}`})
+	assert.Len(t, errs, 2)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Message < errs[j].Message })
+	assert.Equal(t, "enum is not valid", errs[0].Message)
+	assert.Equal(t, []errors.Location{{Line: 2, Column: 3}}, errs[0].Locations)
+	// the error of an item is reported at the item, though it fails the whole list
+	assert.Equal(t, []interface{}{"episodes", 1, 1}, errs[0].Path)
+	assert.Equal(t, "no ship", errs[1].Message)
+	assert.Equal(t, []errors.Location{{Line: 3, Column: 12}}, errs[1].Locations)
+	assert.Equal(t, []interface{}{"heroes", 1, "ship"}, errs[1].Path)
+}
+
+func TestExecutor_PartialResponses(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Enum("Episode", Episode(0), map[string]interface{}{"NEWHOPE": Episode(4), "EMPIRE": Episode(5)})
+	newHope, unknown := Episode(4), Episode(7)
+	build.Query().FieldFunc("episodes", func() []*Episode { return []*Episode{&newHope, &unknown} })
+	build.Query().FieldFunc("strict", func() []Episode { return []Episode{4, 7} })
+	build.Query().FieldFunc("heroes", func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}} })
+	hero := build.Object("Hero", Hero{})
+	hero.FieldFunc("ship", func(h *Hero) (string, error) {
+		if h.Name == "Leia" {
+			return "", fmt.Errorf("no ship")
+		}
+		return "X-wing", nil
+	})
+	schema := build.MustBuild()
+
+	data, errs := unmasked.Do(schema, execution.Params{Query: `{
+		episodes
+		strict
+		heroes { name ship }
+	}`})
+	// the failed items and fields are null, up to their nearest nullable ancestor, the rest of the tree is returned
+	// along with the errors
+	assert.Equal(t, map[string]interface{}{
+		"episodes": []interface{}{"NEWHOPE", nil},
+		"strict":   nil,
+		"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "ship": "X-wing"},
+			nil,
+		},
+	}, data)
+	if !assert.Len(t, errs, 3) {
+		return
+	}
+	sort.Slice(errs, func(i, j int) bool { return fmt.Sprint(errs[i].Path) < fmt.Sprint(errs[j].Path) })
+	assert.Equal(t, "enum is not valid", errs[0].Message)
+	assert.Equal(t, []interface{}{"episodes", 1}, errs[0].Path)
+	assert.Equal(t, []errors.Location{{Line: 2, Column: 3}}, errs[0].Locations)
+	assert.Equal(t, "no ship", errs[1].Message)
+	assert.Equal(t, []interface{}{"heroes", 1, "ship"}, errs[1].Path)
+	// the items of strict are non-null, the item fails the whole list
+	assert.Equal(t, "enum is not valid", errs[2].Message)
+	assert.Equal(t, []interface{}{"strict", 1}, errs[2].Path)
+}
+
+func TestExecutor_ErrorPolicy(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	heroes := func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}, {Name: "Han"}} }
+	build.Query().FieldFunc("heroes", heroes)
+	build.Query().FieldFunc("omitted", heroes, schemabuilder.OnError(schemabuilder.OmitOnError))
+	build.Query().FieldFunc("strictHeroes", func() []Hero { return []Hero{{Name: "Luke"}, {Name: "Leia"}} },
+		schemabuilder.NonNullField)
+	hero := build.Object("Hero", Hero{})
+	hero.FieldFunc("ship", func(h *Hero) (string, error) {
+		if h.Name == "Leia" {
+			return "", fmt.Errorf("no ship")
+		}
+		return "X-wing", nil
+	})
+	hero.FieldFunc("strictShip", func(h *Hero) (string, error) {
+		if h.Name == "Leia" {
+			return "", fmt.Errorf("no ship")
+		}
+		return "X-wing", nil
+	}, schemabuilder.OnError(schemabuilder.PropagateError))
+	schema := build.MustBuild()
+
+	t.Run("nulls the field with NullOnError", func(t *testing.T) {
+		executor := &execution.Executor{ErrorPolicy: schemabuilder.NullOnError}
+		data, errs := executor.Do(schema, execution.Params{Query: `{ heroes { name ship } }`})
+		assert.Len(t, errs, 1)
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "ship": "X-wing"},
+			map[string]interface{}{"name": "Leia", "ship": nil},
+			map[string]interface{}{"name": "Han", "ship": "X-wing"},
+		}}, data)
+	})
+
+	t.Run("propagates the error to the parent", func(t *testing.T) {
+		// the items of heroes are nullable, the hero is null
+		data, errs := execution.Do(schema, execution.Params{Query: `{ heroes { name strictShip } }`})
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, []interface{}{"heroes", 1, "strictShip"}, errs[0].Path)
+		}
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "strictShip": "X-wing"},
+			nil,
+			map[string]interface{}{"name": "Han", "strictShip": "X-wing"},
+		}}, data)
+	})
+
+	t.Run("omits the failed items", func(t *testing.T) {
+		data, errs := execution.Do(schema, execution.Params{Query: `{ omitted { name strictShip } }`})
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, []interface{}{"omitted", 1, "strictShip"}, errs[0].Path)
+		}
+		assert.Equal(t, map[string]interface{}{"omitted": []interface{}{
+			map[string]interface{}{"name": "Luke", "strictShip": "X-wing"},
+			map[string]interface{}{"name": "Han", "strictShip": "X-wing"},
+		}}, data)
+	})
+
+	t.Run("propagates up to the data by default", func(t *testing.T) {
+		// the heroes and their list are non-null, up to the query
+		data, errs := execution.Do(schema, execution.Params{Query: `{ strictHeroes { name ship } }`})
+		assert.Len(t, errs, 1)
+		assert.Nil(t, data)
+
+		data, errs = execution.Do(schema, execution.Params{Query: `{ heroes { name ship } }`})
+		assert.Len(t, errs, 1)
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "ship": "X-wing"},
+			nil,
+			map[string]interface{}{"name": "Han", "ship": "X-wing"},
+		}}, data)
+	})
+}
+
+func TestExecutor_DeferredErrorPolicy(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	heroes := func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}, {Name: "Han"}} }
+	build.Query().FieldFunc("heroes", heroes)
+	build.Query().FieldFunc("omitted", heroes, schemabuilder.OnError(schemabuilder.OmitOnError))
+	build.Query().FieldFunc("strictHeroes", func() []Hero { return []Hero{{Name: "Luke"}, {Name: "Leia"}} },
+		schemabuilder.NonNullField)
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(h Hero) func() (*Hero, error) {
+		return func() (*Hero, error) {
+			if h.Name == "Leia" {
+				return nil, fmt.Errorf("no friend")
+			}
+			return &Hero{Name: "Chewie"}, nil
+		}
+	}, schemabuilder.NonNullField, schemabuilder.OnError(schemabuilder.PropagateError))
+	schema := build.MustBuild()
+
+	t.Run("propagates the error of a deferred field to its parent", func(t *testing.T) {
+		data, errs := execution.Do(schema, execution.Params{Query: `{ heroes { name friend { name } } }`})
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, []interface{}{"heroes", 1, "friend"}, errs[0].Path)
+		}
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "friend": map[string]interface{}{"name": "Chewie"}},
+			nil,
+			map[string]interface{}{"name": "Han", "friend": map[string]interface{}{"name": "Chewie"}},
+		}}, data)
+	})
+
+	t.Run("omits the item of a deferred field", func(t *testing.T) {
+		data, errs := execution.Do(schema, execution.Params{Query: `{ omitted { name friend { name } } }`})
+		assert.Len(t, errs, 1)
+		assert.Equal(t, map[string]interface{}{"omitted": []interface{}{
+			map[string]interface{}{"name": "Luke", "friend": map[string]interface{}{"name": "Chewie"}},
+			map[string]interface{}{"name": "Han", "friend": map[string]interface{}{"name": "Chewie"}},
+		}}, data)
+	})
+
+	t.Run("propagates the error of a deferred field up to the data", func(t *testing.T) {
+		data, errs := execution.Do(schema, execution.Params{Query: `{ strictHeroes { name friend { name } } }`})
+		assert.Len(t, errs, 1)
+		assert.Nil(t, data)
+	})
+}
+
+func TestExecutor_ErrorKinds(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() (string, error) { return "", notFoundError{id: 1} })
+	schema := build.MustBuild()
+	executor := &execution.Executor{UnmaskedErrors: true}
+
+	var syntaxErr *errors.SyntaxError
+	_, errs := executor.Do(schema, execution.Params{Query: `{ hero `})
+	assert.True(t, stderrors.As(errs, &syntaxErr))
+	assert.Equal(t, errs[0], syntaxErr.Err)
+
+	for _, query := range []string{`{ unknown }`, `{ hero { name } }`, `query($id: Int!) { hero }`} {
+		var validationErr *errors.ValidationError
+		_, errs = executor.Do(schema, execution.Params{Query: query})
+		assert.True(t, stderrors.As(errs, &validationErr), query)
+		assert.False(t, stderrors.As(errs, &syntaxErr), query)
+	}
+
+	var executionErr *errors.ExecutionError
+	var notFound notFoundError
+	_, errs = executor.Do(schema, execution.Params{Query: `{ hero }`})
+	assert.True(t, stderrors.As(errs, &executionErr))
+	assert.Equal(t, []interface{}{"hero"}, executionErr.Err.Path)
+	assert.True(t, stderrors.As(errs, &notFound))
+	assert.Equal(t, 1, notFound.id)
+}
+
+func TestExecutor_ErrorPresenter(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() (*Hero, error) { return nil, notFoundError{id: 2} })
+	build.Query().FieldFunc("secret", func() (string, error) { return "", fmt.Errorf("db password is hunter2") })
+	schema := build.MustBuild()
+	executor := &execution.Executor{ErrorPresenter: func(ctx context.Context, err error) *errors.GraphQLError {
+		graphqlErr := err.(*errors.GraphQLError)
+		var notFound notFoundError
+		switch {
+		case stderrors.As(err, &notFound):
+			return graphqlErr.WithCode("NOT_FOUND")
+		case graphqlErr.ResolverError != nil:
+			return &errors.GraphQLError{Message: "internal error", Locations: graphqlErr.Locations, Path: graphqlErr.Path}
+		}
+		return nil
+	}}
+
+	_, errs := executor.Do(schema, execution.Params{Query: `{ hero { name } secret }`})
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Message < errs[j].Message })
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "internal error", errs[0].Message)
+	assert.Equal(t, []interface{}{"secret"}, errs[0].Path)
+	assert.Equal(t, "no hero 2", errs[1].Message)
+	assert.Equal(t, "NOT_FOUND", errors.Code(errs[1]))
+
+	// the errors of the request are presented too, and kept when the presenter returns nil
+	_, errs = executor.Do(schema, execution.Params{Query: `{ unknown }`})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, `Cannot query field "unknown" on type "Query".`, errs[0].Message)
+
+	p, err := executor.Compile(schema, `{ secret }`)
+	assert.NoError(t, err)
+	_, errs = p.Execute(context.Background(), nil)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "internal error", errs[0].Message)
+}
+
+func TestMaskInternalErrors(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("internal", func() (string, error) { return "", fmt.Errorf("pq: relation \"users\" does not exist") })
+	build.Query().FieldFunc("public", func() (string, error) { return "", errors.New("not signed in") })
+	build.Query().FieldFunc("coded", func() (string, error) {
+		return "", fmt.Errorf("lookup: %w", (&errors.GraphQLError{Message: "gone"}).WithCode("NOT_FOUND"))
+	})
+	build.Query().FieldFunc("slow", func(ctx context.Context) (string, error) { return "", context.DeadlineExceeded })
+	schema := build.MustBuild()
+	var logged []string
+	executor := &execution.Executor{ErrorPresenter: execution.MaskInternalErrors(func(ctx context.Context, err *errors.GraphQLError) {
+		logged = append(logged, fmt.Sprint(err.Path, " ", err.ResolverError))
+	})}
+
+	_, errs := executor.Do(schema, execution.Params{Query: `{ internal public coded slow }`})
+	messages := make(map[string]string)
+	for _, err := range errs {
+		messages[fmt.Sprint(err.Path...)] = err.Message
+	}
+	assert.Equal(t, map[string]string{
+		"internal": execution.InternalErrorMessage,
+		"public":   "not signed in",
+		"coded":    "lookup: graphql: gone",
+		"slow":     "context deadline exceeded",
+	}, messages)
+	assert.Equal(t, []string{`[internal] pq: relation "users" does not exist`}, logged)
+}
+
+func TestExecutor_Cancellation(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Query().FieldFunc("heroes", func() []*Hero { return []*Hero{{Name: "Luke"}, {Name: "Leia"}} })
+	build.Object("Hero", Hero{}).FieldFunc("leave", func(ctx context.Context) string {
+		ctx.Value("cancel").(context.CancelFunc)()
+		return "bye"
+	})
+	schema := build.MustBuild()
+
+	t.Run("stops resolving when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		result, err := execution.Do(schema, execution.Params{
+			Query:   `{ hero { leave } }`,
+			Context: context.WithValue(ctx, "cancel", cancel),
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"hero": map[string]interface{}{"leave": "bye"}}, result)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		result, err = execution.Do(schema, execution.Params{
+			Query:   `{ heroes { leave } }`,
+			Context: context.WithValue(ctx, "cancel", cancel),
+		})
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"leave": "bye"},
+			map[string]interface{}{"leave": nil},
+		}}, result)
+		assert.Len(t, err, 1)
+		assert.Equal(t, "context canceled", err[0].Message)
+		assert.Equal(t, context.Canceled, err[0].ResolverError)
+	})
+
+	t.Run("returns a partial response with a timeout error", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		result, err := execution.Do(schema, execution.Params{Query: `{ hero { name } }`, Context: ctx})
+		assert.Len(t, err, 1)
+		assert.Equal(t, "operation timed out: context deadline exceeded", err[0].Message)
+		assert.Equal(t, map[string]interface{}{"hero": nil}, result)
+	})
+}
+
+func TestSelectionSet_Fields(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{} })
+	build.Object("Hero", Hero{}).FieldFunc("friends", func() []*Hero { return nil })
+	schema := build.MustBuild()
+
+	var types []string
+	for typ := range schema.Types() {
+		types = append(types, typ.String())
+	}
+	assert.Contains(t, types, "Hero")
+	assert.True(t, sort.StringsAreSorted(types))
+
+	doc, parseErrs := internal.Parse(`{ hero { name ...on Hero { friends { name } } __typename } }`)
+	assert.Nil(t, parseErrs)
+	_, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", nil)
+	assert.NoError(t, err)
+	hero := selectionSet.Selections[0].SelectionSet
+
+	var fields []string
+	for selection, field := range hero.Fields(schema, "Hero") {
+		fields = append(fields, fmt.Sprintf("%s:%v", selection.Name, field != nil))
+	}
+	assert.Equal(t, []string{"name:true", "__typename:false", "friends:true"}, fields)
+
+	fields = nil
+	for selection := range hero.Fields(schema, "Query") {
+		fields = append(fields, selection.Name)
+	}
+	assert.Equal(t, []string{"name", "__typename"}, fields)
+}
+
+func TestExecutor_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("fast", func() string { return "fast" })
+	build.Query().FieldFunc("slow", func(ctx context.Context) (string, error) {
+		select {
+		case <-release:
+			return "slow", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+	build.Query().FieldFunc("stuck", func() string {
+		<-release
+		return "stuck"
+	}, schemabuilder.Timeout(time.Millisecond))
+	schema := build.MustBuild()
+
+	t.Run("timed out fields become field errors", func(t *testing.T) {
+		executor := &execution.Executor{FieldTimeout: 10 * time.Millisecond}
+		result, err := executor.Do(schema, execution.Params{Query: `{ fast slow stuck }`})
+		// slow and stuck are non-null, their errors propagate to the data
+		assert.Nil(t, result)
+		var messages []string
+		for _, err := range err {
+			messages = append(messages, err.Message)
+		}
+		assert.ElementsMatch(t, []string{
+			"resolver timed out after 10ms: context deadline exceeded",
+			"resolver timed out after 1ms: context deadline exceeded",
+		}, messages)
+	})
+
+	t.Run("the operation has a deadline", func(t *testing.T) {
+		executor := &execution.Executor{Timeout: 10 * time.Millisecond}
+		result, err := executor.Do(schema, execution.Params{Query: `{ slow }`})
+		assert.Nil(t, result)
+		// the context of slow is done at the deadline
+		assert.EqualError(t, err, "[graphql: context deadline exceeded (1:3) path: [slow]]")
+	})
+
+	t.Run("the timeouts are measured by the clock", func(t *testing.T) {
+		mock := clock.NewMock(time.Unix(0, 0))
+		started := make(chan struct{})
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("slow", func(ctx context.Context) (*string, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, schemabuilder.Timeout(time.Hour))
+		executor := &execution.Executor{Clock: mock}
+		go func() {
+			<-started
+			mock.Add(time.Hour)
+		}()
+		result, err := executor.Do(build.MustBuild(), execution.Params{Query: `{ slow }`})
+		assert.Equal(t, map[string]interface{}{"slow": nil}, result)
+		assert.EqualError(t, err, "[graphql: resolver timed out after 1h0m0s: context deadline exceeded (1:3) path: [slow]]")
+	})
+}
+
+func TestExecutor_Interceptors(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("me", func(ctx context.Context) string { return ctx.Value("user").(string) })
+	build.Mutation().FieldFunc("drop", func() bool { return true })
+	schema := build.MustBuild()
+
+	var audit []string
+	executor := &execution.Executor{Interceptors: []*execution.Interceptor{{
+		AfterParse: func(ctx context.Context, op *execution.Operation) error {
+			audit = append(audit, fmt.Sprintf("parsed %d operation", len(op.Document.Operations)))
+			return nil
+		},
+		AfterValidation: func(ctx context.Context, op *execution.Operation) error {
+			if op.Type == ast.Mutation {
+				return fmt.Errorf("mutations are not allowed")
+			}
+			return nil
+		},
+		BeforeExecution: func(ctx context.Context, op *execution.Operation) (context.Context, error) {
+			return context.WithValue(ctx, "user", "luke"), nil
+		},
+		AfterExecution: func(ctx context.Context, op *execution.Operation, response *execution.Response) {
+			audit = append(audit, fmt.Sprintf("%q: %d errors", op.Query, len(response.Errors)))
+		},
+	}}}
+
+	result, err := executor.Do(schema, execution.Params{Query: `{ me }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"me": "luke"}, result)
+
+	_, err = executor.Do(schema, execution.Params{Query: `mutation { drop }`})
+	assert.EqualError(t, err, "[graphql: mutations are not allowed]")
+
+	_, response := executor.Run(schema, execution.Params{Query: `{ me }`}, &execution.Interceptor{
+		AfterExecution: func(ctx context.Context, op *execution.Operation, response *execution.Response) {
+			response.Data = nil
+		},
+	})
+	assert.Nil(t, response.Data)
+	assert.Equal(t, []string{
+		"parsed 1 operation", `"{ me }": 0 errors`,
+		"parsed 1 operation", `"mutation { drop }": 1 errors`,
+		"parsed 1 operation", `"{ me }": 0 errors`,
+	}, audit)
+}
+
+type permissionsKey struct{}
+
+func TestMemo(t *testing.T) {
+	computed := 0
+	permissions := func(ctx context.Context) (map[string]bool, error) {
+		return execution.Memo(ctx, permissionsKey{}, func() (map[string]bool, error) {
+			computed++
+			return map[string]bool{"read": true}, nil
+		})
+	}
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("heroes", func(ctx context.Context) ([]*Hero, error) {
+		if _, err := permissions(ctx); err != nil {
+			return nil, err
+		}
+		return []*Hero{{Name: "Luke"}, {Name: "Leia"}}, nil
+	})
+	build.Object("Hero", Hero{}).FieldFunc("readable", func(ctx context.Context) (bool, error) {
+		p, err := permissions(ctx)
+		return p["read"], err
+	})
+	schema := build.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ heroes { readable } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+		map[string]interface{}{"readable": true},
+		map[string]interface{}{"readable": true},
+	}}, result)
+	assert.Equal(t, 1, computed)
+
+	_, err = execution.Do(schema, execution.Params{Query: `{ heroes { readable } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, 2, computed, "values do not outlive the operation")
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("count", func(ctx context.Context) int {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					permissions(ctx)
+				}()
+			}
+			wg.Wait()
+			return computed
+		})
+		result, err := execution.Do(build.MustBuild(), execution.Params{Query: `{ count }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"count": 3}, result)
+	})
+}
+
+func TestExecutor_MaxDepth(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(source Hero) *Hero { return &source })
+	schema := build.MustBuild()
+	executor := &execution.Executor{MaxDepth: 3}
+
+	_, response := executor.Run(schema, execution.Params{Query: `{ hero { friend { name } } }`})
+	assert.Equal(t, errors.MultiError(nil), response.Errors)
+
+	_, response = executor.Run(schema, execution.Params{Query: `{ hero { friend { friend { name } } } }`})
+	assert.EqualError(t, response.Errors, "[graphql: query depth exceeds the maximum of 3 at hero.friend.friend.name (1:28) path: [hero friend friend name]]")
+	assert.Nil(t, response.Data)
+
+	_, response = executor.Run(schema, execution.Params{Query: `
+query { hero { ...friends } }
+fragment friends on Hero { friend { me: friend { name } } }`})
+	assert.EqualError(t, response.Errors, "[graphql: query depth exceeds the maximum of 3 at hero.friend.me.name (3:50) path: [hero friend me name]]")
+}
+
+func TestExecutor_Introspection(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Object("Hero", Hero{})
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	type adminKey struct{}
+	executor := &execution.Executor{Introspection: func(ctx context.Context) bool {
+		return ctx.Value(adminKey{}) != nil
+	}}
+
+	_, response := executor.Run(schema, execution.Params{Query: `{ __typename hero { __typename name } }`})
+	assert.Equal(t, errors.MultiError(nil), response.Errors)
+
+	_, response = executor.Run(schema, execution.Params{Query: `
+query { hero { name } ...schema }
+fragment schema on Query { __schema { queryType { name } } }`})
+	assert.EqualError(t, response.Errors, `[graphql: GraphQL introspection has been disabled, but the requested query contained the field "__schema". (3:37)]`)
+	assert.Nil(t, response.Data)
+
+	admin := context.WithValue(context.Background(), adminKey{}, true)
+	_, response = executor.Run(schema, execution.Params{Context: admin, Query: `{ __type(name: "Hero") { name } }`})
+	assert.Equal(t, errors.MultiError(nil), response.Errors)
+	data, _ := json.Marshal(response.Data)
+	assert.JSONEq(t, `{"__type": {"name": "Hero"}}`, string(data))
+
+	executor.Introspection = execution.DisableIntrospection
+	query, err := executor.Compile(schema, `{ __type(name: "Hero") { name } }`)
+	assert.NoError(t, err)
+	_, errs := query.Execute(admin, nil)
+	assert.EqualError(t, errs, `[graphql: GraphQL introspection has been disabled, but the requested query contained the field "__type". (1:24)]`)
+}
+
+func TestExecutor_PersistedQueries(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Object("Hero", Hero{})
+	schema := build.MustBuild()
+	cache := execution.NewMemoryQueryCache(time.Hour)
+	mock := clock.NewMock(time.Unix(0, 0))
+	cache.Clock = mock
+	executor := &execution.Executor{PersistedQueries: cache}
+
+	query := `{ hero { name } }`
+	sum := sha256.Sum256([]byte(query))
+	extensions := map[string]interface{}{"persistedQuery": map[string]interface{}{
+		"version":    1.0,
+		"sha256Hash": hex.EncodeToString(sum[:]),
+	}}
+	expected := map[string]interface{}{"hero": map[string]interface{}{"name": "Luke"}}
+
+	_, response := executor.Run(schema, execution.Params{Extensions: extensions})
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "PersistedQueryNotFound", response.Errors[0].Message)
+		assert.Equal(t, "PERSISTED_QUERY_NOT_FOUND", response.Errors[0].Extensions["code"])
+	}
+
+	_, response = executor.Run(schema, execution.Params{Query: query, Extensions: extensions})
+	assert.Equal(t, errors.MultiError(nil), response.Errors)
+	assert.Equal(t, expected, response.Data)
+
+	op, response := executor.Run(schema, execution.Params{Extensions: extensions})
+	assert.Equal(t, errors.MultiError(nil), response.Errors)
+	assert.Equal(t, expected, response.Data)
+	assert.Equal(t, query, op.Query)
+
+	mock.Add(time.Hour)
+	_, response = executor.Run(schema, execution.Params{Extensions: extensions})
+	assert.EqualError(t, response.Errors, "[graphql: PersistedQueryNotFound]", "the query has expired")
+
+	_, response = executor.Run(schema, execution.Params{Query: `{ hero { __typename } }`, Extensions: extensions})
+	assert.EqualError(t, response.Errors, "[graphql: provided sha does not match query]")
+
+	_, response = (&execution.Executor{}).Run(schema, execution.Params{Extensions: extensions})
+	assert.EqualError(t, response.Errors, "[graphql: PersistedQueryNotSupported]")
+}
+
+func TestMemoryQueryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := execution.NewMemoryQueryCache(time.Hour)
+	mock := clock.NewMock(time.Unix(0, 0))
+	cache.Clock = mock
+	cache.Size = 2
+
+	cache.Add(ctx, "a", "{ a }")
+	cache.Add(ctx, "b", "{ b }")
+	_, ok := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	cache.Add(ctx, "c", "{ c }")
+	_, ok = cache.Get(ctx, "b")
+	assert.False(t, ok, "the least recently used query is evicted")
+	query, ok := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "{ a }", query)
+
+	mock.Add(time.Hour)
+	_, ok = cache.Get(ctx, "a")
+	assert.False(t, ok, "the query has expired")
+	cache.Add(ctx, "d", "{ d }")
+	_, ok = cache.Get(ctx, "c")
+	assert.False(t, ok, "the expired queries are dropped when adding one")
+	_, ok = cache.Get(ctx, "d")
+	assert.True(t, ok)
+}
+
+func TestMemoryQueryCache_Zero(t *testing.T) {
+	ctx := context.Background()
+	cache := &execution.MemoryQueryCache{}
+
+	_, ok := cache.Get(ctx, "a")
+	assert.False(t, ok)
+	for i := 0; i <= execution.DefaultMemoryQueryCacheSize; i++ {
+		cache.Add(ctx, fmt.Sprint(i), fmt.Sprintf("{ a%d }", i))
+	}
+	_, ok = cache.Get(ctx, "0")
+	assert.False(t, ok, "the cache keeps DefaultMemoryQueryCacheSize queries")
+	query, ok := cache.Get(ctx, "1")
+	assert.True(t, ok)
+	assert.Equal(t, "{ a1 }", query)
+}
+
+func TestPreparedQuery(t *testing.T) {
+	calls := 0
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("heroes", func(args struct {
+		Prefix *string `graphql:"prefix"`
+	}) []*Hero {
+		calls++
+		heroes := []*Hero{{Name: "Luke"}, {Name: "Leia"}}
+		if args.Prefix != nil {
+			for _, hero := range heroes {
+				hero.Name = *args.Prefix + hero.Name
+			}
+		}
+		return heroes
+	})
+	build.Object("Hero", Hero{})
+	schema := build.MustBuild()
+
+	prepared, err := execution.Compile(schema, `{ heroes { name ... on Hero { alias: name } } }`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		result, errs := prepared.Execute(context.Background(), nil)
+		assert.Equal(t, errors.MultiError(nil), errs)
+		assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+			map[string]interface{}{"name": "Luke", "alias": "Luke"},
+			map[string]interface{}{"name": "Leia", "alias": "Leia"},
+		}}, result)
+	}
+	assert.Equal(t, 2, calls)
+
+	prepared, err = execution.Compile(schema, `query Heroes($prefix: String) { heroes(prefix: $prefix) { name } }`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, errs := prepared.Execute(context.Background(), map[string]interface{}{"prefix": "Sir "})
+	assert.Equal(t, errors.MultiError(nil), errs)
+	assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+		map[string]interface{}{"name": "Sir Luke"},
+		map[string]interface{}{"name": "Sir Leia"},
+	}}, result)
+	_, errs = prepared.Execute(context.Background(), map[string]interface{}{"prefix": 1.0})
+	assert.NotEmpty(t, errs)
+
+	_, err = execution.Compile(schema, `{ villains { name } }`)
+	assert.EqualError(t, err, `graphql: Cannot query field "villains" on type "Query". (1:3)`)
+	// the operations declaring variables are validated once compiled too
+	_, err = execution.Compile(schema, `query Villains($prefix: String) { villains(prefix: $prefix) { name } }`)
+	assert.EqualError(t, err, `graphql: Cannot query field "villains" on type "Query". (1:35)`)
+	_, err = execution.Compile(schema, `{ heroes { `)
+	assert.Error(t, err)
+}
+
+func TestExecutor_CollectFields(t *testing.T) {
+	var calls []string
+	build := schemabuilder.NewSchema()
+	pet := build.Interface("Pet", new(Pet), nil, "")
+	pet.FieldFunc("name", "GetName", "")
+	build.Object("Dog", Dog{}, "").InterfaceList(pet)
+	build.Object("Cat", Cat{}, "").InterfaceList(pet)
+	build.Object("Hero", Hero{}).FieldFunc("pet", func(source Hero) Pet {
+		calls = append(calls, source.Name+".pet")
+		return Dog{Name: "Odie", Woofs: true}
+	})
+	build.Query().FieldFunc("pets", func() []Pet {
+		return []Pet{Dog{"Odie", true}, Cat{"Garfield", false}}
+	})
+	build.Union("Animal", Animal{}, "")
+	build.Query().FieldFunc("animal", func() Animal { return Animal{Cat: &Cat{Name: "Garfield"}} })
+	build.Query().FieldFunc("a", func() *Hero {
+		calls = append(calls, "a")
+		return &Hero{Name: "a"}
+	})
+	build.Query().FieldFunc("b", func() *Hero {
+		calls = append(calls, "b")
+		return &Hero{Name: "b"}
+	})
+	schema := build.MustBuild()
+
+	t.Run("type conditions narrow abstract types", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `
+{
+  pets {
+    __typename
+    ...PetName
+    ... on Dog { woofs }
+    ... on Cat { meows ...PetName }
+    ... on Pet { ... on Cat { kind: __typename } }
+  }
+}
+fragment PetName on Pet { name }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"pets": []interface{}{
+			map[string]interface{}{"__typename": "Dog", "name": "Odie", "woofs": true},
+			map[string]interface{}{"__typename": "Cat", "name": "Garfield", "meows": false, "kind": "Cat"},
+		}}, result)
+	})
+
+	t.Run("fragments on a union apply to its members under an interface", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{Query: `
+{
+  pets {
+    ... on Animal { ... on Dog { woofs } }
+    ...AnimalType
+  }
+}
+fragment AnimalType on Animal { __typename }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"pets": []interface{}{
+			map[string]interface{}{"__typename": "Dog", "woofs": true},
+			map[string]interface{}{"__typename": "Cat"},
+		}}, result)
+	})
+
+	t.Run("identical fields are merged and resolved once, in order", func(t *testing.T) {
+		calls = nil
+		result, err := execution.Do(schema, execution.Params{Query: `
+{
+  b { name }
+  a { pet { name } }
+  ... on Query { b { pet { name } } a { pet { ... on Dog { woofs } } } }
+}`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{
+			"a": map[string]interface{}{"pet": map[string]interface{}{"name": "Odie", "woofs": true}},
+			"b": map[string]interface{}{"name": "b", "pet": map[string]interface{}{"name": "Odie"}},
+		}, result)
+		assert.Equal(t, []string{"b", "b.pet", "a", "a.pet"}, calls)
+	})
+
+	t.Run("fragments are skipped by directives", func(t *testing.T) {
+		result, err := execution.Do(schema, execution.Params{
+			Query:     `query ($dog: Boolean!) { pets { ... on Dog @include(if: $dog) { woofs } ... on Cat @skip(if: true) { meows } } }`,
+			Variables: map[string]interface{}{"dog": false},
+		})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"pets": []interface{}{
+			map[string]interface{}{},
+			map[string]interface{}{},
+		}}, result)
+	})
+}
+
+func TestExecutor_Subscribe(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func(ctx context.Context, args struct {
+		Names []string `graphql:"names"`
+	}) <-chan *Hero {
+		heroes := make(chan *Hero)
+		go func() {
+			defer close(heroes)
+			for _, name := range args.Names {
+				select {
+				case heroes <- &Hero{Name: name}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return heroes
+	})
+	schema := build.MustBuild()
+
+	doc, parseErrs := internal.Parse(`subscription ($names: [String!]!) { heroes(names: $names) { name upper: name @uppercase } }`)
+	if !assert.Nil(t, parseErrs) {
+		return
+	}
+	_, err := execution.Subscribe(context.Background(), schema, doc, map[string]interface{}{"names": []interface{}{"Luke"}})
+	assert.EqualError(t, err, `graphql: Unknown directive "uppercase". (1:78)`)
+
+	doc, _ = internal.Parse(`subscription ($names: [String!]!) { heroes(names: $names) { name } }`)
+	responses, err := execution.Subscribe(context.Background(), schema, doc, map[string]interface{}{"names": []interface{}{"Luke", "Leia"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var received []interface{}
+	for response := range responses {
+		assert.Equal(t, errors.MultiError(nil), response.Errors)
+		received = append(received, response.Data)
+	}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"heroes": map[string]interface{}{"name": "Luke"}},
+		map[string]interface{}{"heroes": map[string]interface{}{"name": "Leia"}},
+	}, received)
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		responses, err := execution.Subscribe(ctx, schema, doc, map[string]interface{}{"names": []interface{}{"Luke", "Leia", "Han"}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		<-responses
+		cancel()
+		for range responses {
+		}
+	})
+
+	t.Run("rejects other operations", func(t *testing.T) {
+		doc, _ := internal.Parse(`{ hero { name } }`)
+		_, err := execution.Subscribe(context.Background(), schema, doc, nil)
+		assert.EqualError(t, err, "graphql: operation is not a subscription")
+
+		_, err = execution.Do(schema, execution.Params{Query: `subscription { heroes(names: []) { name } }`})
+		assert.EqualError(t, err, "[graphql: subscriptions must be executed with Subscribe]")
+	})
+}
+
+// failingStream sends its heroes and then fails.
+type failingStream struct {
+	heroes []*Hero
+	closed *bool
+}
+
+func (s *failingStream) Next(ctx context.Context) (*Hero, error) {
+	if len(s.heroes) == 0 {
+		return nil, fmt.Errorf("connection to the broker lost")
+	}
+	hero := s.heroes[0]
+	s.heroes = s.heroes[1:]
+	return hero, nil
+}
+
+func (s *failingStream) Close() { *s.closed = true }
+
+func TestExecutor_SourceStream(t *testing.T) {
+	var cleanups int
+	var failingClosed bool
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func() *schemabuilder.Stream[*Hero] {
+		heroes := make(chan *Hero, 2)
+		heroes <- &Hero{Name: "Luke"}
+		heroes <- &Hero{Name: "Leia"}
+		close(heroes)
+		return schemabuilder.NewStream(heroes, func() { cleanups++ })
+	})
+	build.Subscription().FieldFunc("failing", func() schemabuilder.SourceStream[*Hero] {
+		return &failingStream{heroes: []*Hero{{Name: "Han"}}, closed: &failingClosed}
+	})
+	build.Subscription().FieldFunc("missing", func() *schemabuilder.Stream[*Hero] { return nil })
+	schema := build.MustBuild()
+
+	receive := func(query string) ([]*execution.Response, error) {
+		doc, parseErrs := internal.Parse(query)
+		if parseErrs != nil {
+			return nil, parseErrs
+		}
+		responses, err := unmasked.Subscribe(context.Background(), schema, doc, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		var received []*execution.Response
+		for response := range responses {
+			received = append(received, response)
+		}
+		return received, nil
+	}
+
+	responses, err := receive(`subscription { heroes { name } }`)
+	assert.NoError(t, err)
+	assert.Equal(t, []*execution.Response{
+		{Data: map[string]interface{}{"heroes": map[string]interface{}{"name": "Luke"}}},
+		{Data: map[string]interface{}{"heroes": map[string]interface{}{"name": "Leia"}}},
+	}, responses)
+	assert.Equal(t, 1, cleanups)
+
+	responses, err = receive(`subscription { failing { name } }`)
+	assert.NoError(t, err)
+	if assert.Len(t, responses, 2) {
+		assert.Equal(t, map[string]interface{}{"failing": map[string]interface{}{"name": "Han"}}, responses[0].Data)
+		assert.Nil(t, responses[1].Data)
+		assert.EqualError(t, responses[1].Errors, "[graphql: connection to the broker lost (1:24) path: [failing]]")
+		assert.Equal(t, []interface{}{"failing"}, responses[1].Errors[0].Path)
+	}
+	assert.True(t, failingClosed)
+
+	_, err = receive(`subscription { missing { name } }`)
+	assert.EqualError(t, err, "subscription field missing resolved to a nil stream")
+	// the rules of the validation cache of the executor apply to the subscriptions
+	executor := &execution.Executor{ValidationCache: validation.NewCacheWithRules(1,
+		append([]validation.Rule{validation.MaxAliases(1)}, validation.SpecifiedRules...))}
+	doc, _ := internal.Parse(`subscription { luke: heroes { name: name alias: name } }`)
+	_, err = executor.Subscribe(context.Background(), schema, doc, "", nil)
+	assert.EqualError(t, err, "graphql: Aliases limit of 1 exceeded, found 2. (1:42)")
+}
+
+type heroesArgs struct {
+	Prefix string `graphql:"prefix"`
+}
+
+func TestExecutor_SubscriptionFilter(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func(args heroesArgs) <-chan *Hero {
+		heroes := make(chan *Hero, 3)
+		for _, name := range []string{"Luke", "Leia", "Han"} {
+			heroes <- &Hero{Name: name}
+		}
+		close(heroes)
+		return heroes
+	}, schemabuilder.Filter(func(ctx context.Context, event, args interface{}) bool {
+		return strings.HasPrefix(event.(*Hero).Name, args.(heroesArgs).Prefix)
+	}))
+	schema := build.MustBuild()
+
+	doc, _ := internal.Parse(`subscription { heroes(prefix: "L") { name } }`)
+	responses, err := execution.Subscribe(context.Background(), schema, doc, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var names []interface{}
+	for response := range responses {
+		names = append(names, response.Data.(map[string]interface{})["heroes"].(map[string]interface{})["name"])
+	}
+	assert.Equal(t, []interface{}{"Luke", "Leia"}, names)
+}
+
+func TestExecutor_SubscriptionOverflow(t *testing.T) {
+	var finished chan struct{}
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func() *schemabuilder.Stream[*Hero] {
+		heroes := make(chan *Hero, 5)
+		for _, name := range []string{"1", "2", "3", "4", "5"} {
+			heroes <- &Hero{Name: name}
+		}
+		close(heroes)
+		return schemabuilder.NewStream[*Hero](heroes, func() { close(finished) })
+	})
+	schema := build.MustBuild()
+	doc, _ := internal.Parse(`subscription { heroes { name } }`)
+
+	for _, test := range []struct {
+		policy   execution.OverflowPolicy
+		received []interface{}
+	}{
+		{policy: execution.DropOldest, received: []interface{}{"4", "5"}},
+		{policy: execution.DropNewest, received: []interface{}{"1", "2"}},
+		{policy: execution.CloseWithError, received: []interface{}{"2", execution.ErrSubscriptionOverflow.Message}},
+	} {
+		finished = make(chan struct{})
+		executor := &execution.Executor{SubscriptionBuffer: 2, SubscriptionOverflow: test.policy}
+		responses, err := executor.Subscribe(context.Background(), schema, doc, "", nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		// the subscriber is slow: it receives once the stream is over
+		<-finished
+		var received []interface{}
+		for response := range responses {
+			if response.Errors != nil {
+				received = append(received, response.Errors[0].Message)
+				continue
+			}
+			received = append(received, response.Data.(map[string]interface{})["heroes"].(map[string]interface{})["name"])
+		}
+		assert.Equal(t, test.received, received, "policy %d", test.policy)
+	}
+}
+
+func TestSubscriptionMultiplexer(t *testing.T) {
+	var starts, stops atomic.Int32
+	feed := make(chan *Hero)
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func() *schemabuilder.Stream[*Hero] {
+		starts.Add(1)
+		return schemabuilder.NewStream[*Hero](feed, func() { stops.Add(1) })
+	})
+	schema := build.MustBuild()
+
+	query := `subscription { heroes { name } }`
+	doc, _ := internal.Parse(query)
+	key := execution.MultiplexKey(query, "", nil)
+	assert.Equal(t, key, execution.MultiplexKey(query, "", map[string]interface{}{}))
+	assert.NotEqual(t, key, execution.MultiplexKey(query, "", map[string]interface{}{"a": 1}))
+	subscribe := func(ctx context.Context) (<-chan *execution.Response, error) {
+		return execution.Subscribe(ctx, schema, doc, nil)
+	}
+	mux := execution.NewSubscriptionMultiplexer()
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	a, err := mux.Subscribe(ctxA, key, subscribe)
+	assert.NoError(t, err)
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	b, err := mux.Subscribe(ctxB, key, subscribe)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), starts.Load())
+
+	feed <- &Hero{Name: "Luke"}
+	fromA := make(chan *execution.Response, 1)
+	go func() { fromA <- <-a }()
+	luke := map[string]interface{}{"heroes": map[string]interface{}{"name": "Luke"}}
+	assert.Equal(t, luke, (<-b).Data)
+	assert.Equal(t, luke, (<-fromA).Data)
+
+	// the subscription goes on for the remaining subscribers
+	cancelA()
+	for range a {
+	}
+	feed <- &Hero{Name: "Leia"}
+	assert.Equal(t, map[string]interface{}{"heroes": map[string]interface{}{"name": "Leia"}}, (<-b).Data)
+
+	cancelB()
+	for range b {
+	}
+	ctxC, cancelC := context.WithCancel(context.Background())
+	defer cancelC()
+	c, err := mux.Subscribe(ctxC, key, subscribe)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), starts.Load())
+	close(feed)
+	for range c {
+	}
+	// the first subscription may still be stopping
+	for i := 0; stops.Load() < 2 && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, int32(2), stops.Load())
+
+	_, err = mux.Subscribe(context.Background(), "invalid", func(ctx context.Context) (<-chan *execution.Response, error) {
+		return nil, fmt.Errorf("invalid subscription")
+	})
+	assert.EqualError(t, err, "invalid subscription")
+}
+
+func TestSubscriptionMultiplexer_Subscribers(t *testing.T) {
+	feed := make(chan *Hero)
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Query().FieldFunc("hero", func() *Hero { return &Hero{Name: "Luke"} })
+	build.Subscription().FieldFunc("heroes", func() <-chan *Hero { return feed })
+	schema := build.MustBuild()
+	doc, _ := internal.Parse(`subscription { heroes { name } }`)
+	subscribe := func(ctx context.Context) (<-chan *execution.Response, error) {
+		return execution.Subscribe(ctx, schema, doc, nil)
+	}
+
+	t.Run("a slow subscriber overflows alone", func(t *testing.T) {
+		mux := execution.NewSubscriptionMultiplexer()
+		mux.Buffer = 1
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		slow, err := mux.Subscribe(ctx, "heroes", subscribe)
+		assert.NoError(t, err)
+		fast, err := mux.Subscribe(ctx, "heroes", subscribe)
+		assert.NoError(t, err)
+
+		for _, name := range []string{"Luke", "Leia", "Han"} {
+			feed <- &Hero{Name: name}
+			assert.Equal(t, map[string]interface{}{"heroes": map[string]interface{}{"name": name}}, (<-fast).Data)
+		}
+		var responses []*execution.Response
+		for response := range slow {
+			responses = append(responses, response)
+		}
+		if assert.Len(t, responses, 1) {
+			assert.Equal(t, errors.MultiError{execution.ErrSubscriptionOverflow}, responses[0].Errors)
+		}
+	})
+
+	t.Run("a subscription starting does not block the other keys", func(t *testing.T) {
+		mux := execution.NewSubscriptionMultiplexer()
+		started, release := make(chan struct{}), make(chan struct{})
+		var starts atomic.Int32
+		slowSubscribe := func(ctx context.Context) (<-chan *execution.Response, error) {
+			starts.Add(1)
+			close(started)
+			<-release
+			return subscribe(ctx)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		subscribed := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := mux.Subscribe(ctx, "slow", slowSubscribe)
+				subscribed <- err
+			}()
+		}
+		<-started
+
+		_, err := mux.Subscribe(ctx, "other", subscribe)
+		assert.NoError(t, err)
+		close(release)
+		assert.NoError(t, <-subscribed)
+		assert.NoError(t, <-subscribed)
+		assert.Equal(t, int32(1), starts.Load())
+	})
+}
+
+type heroLoaderKey struct{}
+
+func TestExecutor_Dataloader(t *testing.T) {
+	var batches [][]string
+	friends := map[string]string{"Luke": "Leia", "Leia": "Han", "Han": "Luke"}
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("heroes", func() []*Hero {
+		return []*Hero{{Name: "Luke"}, {Name: "Leia"}, {Name: "Han"}}
+	})
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(ctx context.Context, source Hero) dataloader.Thunk[*Hero] {
+		loader, _ := execution.Memo(ctx, heroLoaderKey{}, func() (*dataloader.Loader[string, *Hero], error) {
+			return dataloader.New(func(ctx context.Context, names []string) (map[string]*Hero, error) {
+				batches = append(batches, names)
+				heroes := make(map[string]*Hero, len(names))
+				for _, name := range names {
+					if name != "Han" {
+						heroes[name] = &Hero{Name: friends[name]}
+					}
+				}
+				return heroes, nil
+			}), nil
+		})
+		return loader.Load(ctx, source.Name)
+	})
+	schema := build.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ heroes { name friend { name friend { name } } } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"heroes": []interface{}{
+		map[string]interface{}{"name": "Luke", "friend": map[string]interface{}{"name": "Leia", "friend": map[string]interface{}{"name": "Han"}}},
+		map[string]interface{}{"name": "Leia", "friend": map[string]interface{}{"name": "Han", "friend": nil}},
+		map[string]interface{}{"name": "Han", "friend": nil},
+	}}, result)
+	if assert.Len(t, batches, 1, "nested friends are cached") {
+		assert.ElementsMatch(t, []string{"Luke", "Leia", "Han"}, batches[0])
+	}
+}
+
+func TestExecutor_ThunkScheduler(t *testing.T) {
+	var running, peak atomic.Int32
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("heroes", func() []*Hero {
+		heroes := make([]*Hero, 8)
+		for i := range heroes {
+			heroes[i] = &Hero{Name: fmt.Sprint("hero", i)}
+		}
+		return heroes
+	})
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(source Hero) func() (*Hero, error) {
+		return func() (*Hero, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if source.Name == "hero3" {
+				panic("no friend")
+			}
+			return &Hero{Name: source.Name + "'s friend"}, nil
+		}
+	})
+	schema := build.MustBuild()
+	expected := make([]interface{}, 8)
+	for i := range expected {
+		name := fmt.Sprint("hero", i)
+		expected[i] = map[string]interface{}{"friend": map[string]interface{}{"name": name + "'s friend"}}
+	}
+	expected[3] = map[string]interface{}{"friend": nil}
+
+	pool := execution.NewWorkerPool(3)
+	for _, test := range []struct {
+		name       string
+		executor   *execution.Executor
+		operations int
+		peak       int32
+	}{
+		{"sequential", &execution.Executor{}, 1, 1},
+		{"default pool", &execution.Executor{MaxConcurrentThunks: 8}, 1, 8},
+		{"operation budget", &execution.Executor{ThunkScheduler: pool, MaxConcurrentThunks: 2}, 1, 2},
+		// the pool is shared by the operations
+		{"pool", &execution.Executor{ThunkScheduler: pool}, 2, 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			peak.Store(0)
+			var wg sync.WaitGroup
+			for i := 0; i < test.operations; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					result, err := test.executor.Do(schema, execution.Params{Query: `{ heroes { friend { name } } }`})
+					if assert.Len(t, err, 1) {
+						assert.Equal(t, []interface{}{"heroes", 3, "friend"}, err[0].Path)
+					}
+					assert.Equal(t, map[string]interface{}{"heroes": expected}, result)
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, test.peak, peak.Load())
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, pool.Go(ctx, func() { t.Error("the task of a done context is run") }))
+}
+
+func check(t *testing.T, testType interface{}, testData interface{}, expected interface{}) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("test", testType, "")
+	build.Query().FieldFunc("nest", func() schemabuilder.Query { return schemabuilder.Query{} }, "")
+	schema := build.MustBuild()
+	result, err := execution.Do(schema, execution.Params{
+		Query:         "{ nest { test } }",
+		OperationName: "",
+		Variables:     nil,
+		Context:       context.WithValue(context.Background(), "test", testData),
+	})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, expected, result)
+}
+
+func TestExecutor_ValidationCache(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func() string { return "world" })
+	schema := build.MustBuild()
+
+	cache := validation.NewCache(8)
+	executor := &execution.Executor{ValidationCache: cache}
+	for i := 0; i < 2; i++ {
+		result, err := executor.Do(schema, execution.Params{Query: `{ hello }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"hello": "world"}, result)
+		_, err = executor.Do(schema, execution.Params{Query: `{ helo }`})
+		assert.EqualError(t, err, `[graphql: Cannot query field "helo" on type "Query". Did you mean "hello"? (1:3)]`)
+	}
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestExecutor_Stages(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func() string { return "world" })
+	schema := build.MustBuild()
+
+	executor := &execution.Executor{}
+	documents := make(map[string]*internal.Document)
+	var parsed, validated int
+	executor.Stages.Parse = func(ctx context.Context, op *execution.Operation) errors.MultiError {
+		if doc, ok := documents[op.Query]; ok {
+			op.Document = doc
+			return nil
+		}
+		parsed++
+		if errs := executor.Parse(ctx, op); errs != nil {
+			return errs
+		}
+		documents[op.Query] = op.Document
+		return nil
+	}
+	executor.Stages.Validate = func(ctx context.Context, schema *internal.Schema, op *execution.Operation) errors.MultiError {
+		if op.Query == `{ trusted: hello }` {
+			return nil
+		}
+		validated++
+		return executor.Validate(ctx, schema, op)
+	}
+	executor.Stages.Execute = func(ctx context.Context, schema *internal.Schema, op *execution.Operation) (interface{}, errors.MultiError) {
+		data, errs := executor.ExecuteOperation(ctx, schema, op)
+		return map[string]interface{}{"wrapped": data}, errs
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := executor.Do(schema, execution.Params{Query: `{ hello }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"wrapped": map[string]interface{}{"hello": "world"}}, result)
+		result, err = executor.Do(schema, execution.Params{Query: `{ trusted: hello }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"wrapped": map[string]interface{}{"trusted": "world"}}, result)
+	}
+	assert.Equal(t, 2, parsed)
+	assert.Equal(t, 2, validated)
+
+	_, err := executor.Do(schema, execution.Params{Query: `{ helo }`})
+	assert.EqualError(t, err, `[graphql: Cannot query field "helo" on type "Query". Did you mean "hello"? (1:3)]`)
+}
+
+func TestExecutor_Trace(t *testing.T) {
+	mock := clock.NewMock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func(args struct {
+		Name string `graphql:"name"`
+	}) *Hero {
+		mock.Add(10 * time.Millisecond)
+		return &Hero{Name: args.Name}
+	})
+	build.Object("Hero", Hero{}).FieldFunc("friend", func(source Hero) dataloader.Thunk[*Hero] {
+		mock.Add(time.Millisecond)
+		return func() (*Hero, error) {
+			mock.Add(5 * time.Millisecond)
+			return nil, stderrors.New("no friend")
+		}
+	})
+	schema := build.MustBuild()
+
+	var traces []*execution.Trace
+	executor := &execution.Executor{UnmaskedErrors: true, Interceptors: []*execution.Interceptor{
+		execution.Tracing(func(ctx context.Context, op *execution.Operation, trace *execution.Trace) {
+			traces = append(traces, trace)
+		}),
+	}}
+	trace := execution.NewTrace(mock)
+	_, err := executor.Do(schema, execution.Params{
+		Query:   `{ hero(name: "Luke") { name friend { name } } }`,
+		Context: execution.WithTrace(context.Background(), trace),
+	})
+	assert.EqualError(t, err, "[graphql: no friend (1:36) path: [hero friend]]")
+	assert.Equal(t, []*execution.Trace{trace}, traces, "the trace of the context is kept")
+
+	resolvers := trace.Resolvers()
+	if assert.Len(t, resolvers, 4) {
+		for _, r := range resolvers {
+			r.Args = nil
+		}
+		assert.Equal(t, []*execution.ResolverTrace{
+			{Path: []interface{}{"hero"}, ParentType: "Query", FieldName: "hero", ReturnType: "Hero", Duration: 10 * time.Millisecond},
+			{Path: []interface{}{"hero", "name"}, ParentType: "Hero", FieldName: "name", ReturnType: "String!", StartOffset: 10 * time.Millisecond},
+			{Path: []interface{}{"hero", "friend"}, ParentType: "Hero", FieldName: "friend", ReturnType: "Hero",
+				StartOffset: 10 * time.Millisecond, Duration: time.Millisecond},
+			{Path: []interface{}{"hero", "friend"}, ParentType: "Hero", FieldName: "friend", ReturnType: "Hero",
+				StartOffset: 11 * time.Millisecond, Duration: 5 * time.Millisecond, Deferred: true, Error: "no friend"},
+		}, resolvers)
+	}
+	assert.Equal(t, 16*time.Millisecond, trace.Duration())
+	extension := trace.Extension()
+	assert.Equal(t, int64(16*time.Millisecond), extension["duration"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", extension["startTime"])
+	assert.Equal(t, "2024-01-01T00:00:00.016Z", extension["endTime"])
+}
+
+func TestExecutor_SlowQueryLog(t *testing.T) {
+	mock := clock.NewMock(time.Now())
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func(args struct {
+		Name     string `graphql:"name"`
+		Password string `graphql:"password"`
+	}) string {
+		if args.Name == "slow" {
+			mock.Add(50 * time.Millisecond)
+		}
+		return args.Name
+	})
+	schema := build.MustBuild()
+
+	var queries []*execution.SlowQuery
+	executor := &execution.Executor{
+		SlowQueryThreshold: 20 * time.Millisecond,
+		SlowQueryLog: func(ctx context.Context, query *execution.SlowQuery) {
+			queries = append(queries, query)
+		},
+		Clock: mock,
+	}
+	executor.Stages.Validate = func(ctx context.Context, schema *internal.Schema, op *execution.Operation) errors.MultiError {
+		mock.Add(2 * time.Millisecond)
+		return executor.Validate(ctx, schema, op)
+	}
+	query := `
+		# the hero
+		query Hero($name: String!, $password: String!) {
+			hero(name: $name, password: $password)
+			other: hero(name: "Luke", password: "secret")
+		}`
+	_, err := executor.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"name": "fast", "password": "x"}})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Empty(t, queries)
+
+	_, err = executor.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"name": "slow", "password": "x"}})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, []*execution.SlowQuery{{
+		Query:     `query Hero($name:String!$password:String!){hero(name:$name password:$password)other:hero(name:"" password:"")}`,
+		Variables: map[string]interface{}{"name": execution.Redacted, "password": execution.Redacted},
+		Duration:  52 * time.Millisecond,
+		Validate:  2 * time.Millisecond,
+		Execute:   50 * time.Millisecond,
+	}}, queries)
+
+	queries = nil
+	executor.SlowQueryRedact = func(name string, value interface{}) interface{} {
+		if name == "password" {
+			return execution.Redacted
+		}
+		return value
+	}
+	_, err = executor.Do(schema, execution.Params{Query: query, OperationName: "Hero",
+		Variables: map[string]interface{}{"name": "slow", "password": "x"}})
+	assert.Equal(t, errors.MultiError(nil), err)
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, "Hero", queries[0].OperationName)
+		assert.Equal(t, map[string]interface{}{"name": "slow", "password": execution.Redacted}, queries[0].Variables)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("request", func(ctx context.Context) string {
+		return fmt.Sprintf("%s %v", execution.RequestID(ctx), execution.MetadataValue(ctx, "tenant"))
+	})
+	build.Query().FieldFunc("fail", func() (*string, error) { return nil, stderrors.New("failed") })
+	schema := build.MustBuild()
+
+	ctx := execution.WithRequestID(context.Background(), "42")
+	parent := execution.WithMetadata(ctx, "tenant", "acme")
+	ctx = execution.WithMetadata(parent, "client", "ios")
+	assert.Equal(t, map[string]interface{}{"tenant": "acme"}, execution.Metadata(parent))
+	assert.Equal(t, map[string]interface{}{"tenant": "acme", "client": "ios"}, execution.Metadata(ctx))
+	assert.Nil(t, execution.Metadata(context.Background()))
+
+	var presented []string
+	executor := &execution.Executor{ErrorPresenter: func(ctx context.Context, err error) *errors.GraphQLError {
+		presented = append(presented, execution.RequestID(ctx))
+		return nil
+	}}
+	result, _ := executor.Do(schema, execution.Params{Query: `{ request fail }`, Context: ctx})
+	assert.Equal(t, map[string]interface{}{"request": "42 acme", "fail": nil}, result)
+	assert.Equal(t, []string{"42"}, presented)
+
+	assert.Len(t, execution.NewRequestID(nil), 32)
+	assert.NotEqual(t, execution.NewRequestID(nil), execution.NewRequestID(nil))
+	assert.Equal(t, execution.NewRequestID(clock.NewRand(42)), execution.NewRequestID(clock.NewRand(42)))
+}
+
+func TestDocumentCache(t *testing.T) {
+	cache := execution.NewDocumentCache(2)
+	first, errs := cache.Get(`{ a }`)
+	assert.Nil(t, errs)
+	cached, _ := cache.Get(`{ a }`)
+	assert.Same(t, first, cached)
+
+	cache.Get(`{ b }`)
+	cache.Get(`{ a }`)
+	cache.Get(`{ c }`)
+	_, errs = cache.Get(`{ d `)
+	assert.EqualError(t, errs, `[graphql: Syntax Error: Expected Ident, found "". (1:5)]`)
+	assert.Equal(t, execution.DocumentCacheStats{Hits: 2, Misses: 4, Evictions: 1, Len: 2}, cache.Stats())
+
+	// b is the least recently used document, it has been evicted
+	cached, _ = cache.Get(`{ a }`)
+	assert.Same(t, first, cached)
+	cache.Get(`{ b }`)
+	assert.Equal(t, execution.DocumentCacheStats{Hits: 3, Misses: 5, Evictions: 2, Len: 2}, cache.Stats())
+}
+
+func TestPlanCache(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("echo", func(args struct {
+		Value string `graphql:"value"`
+	}) string {
+		return args.Value
+	})
+	build.Query().FieldFunc("items", func(args struct {
+		Tags []string `graphql:"tags"`
+	}) []string {
+		return args.Tags
+	})
+	schema := build.MustBuild()
+	cache := execution.NewPlanCache(2)
+	executor := &execution.Executor{PlanCache: cache}
+
+	const query = `query Echo($value: String!, $skip: Boolean!) {
+	static: echo(value: "static")
+	bound: echo(value: $value)
+	skipped: echo(value: "skipped") @skip(if: $skip)
+	...Items
+}
+fragment Items on Query { items(tags: ["a", $value]) }`
+	for _, value := range []string{"first", "second"} {
+		result, errs := executor.Do(schema, execution.Params{Query: query,
+			Variables: map[string]interface{}{"value": value, "skip": value == "second"}})
+		assert.Nil(t, errs)
+		expected := map[string]interface{}{"static": "static", "bound": value, "items": []interface{}{"a", value}}
+		if value == "first" {
+			expected["skipped"] = "skipped"
+		}
+		assert.Equal(t, expected, result)
+	}
+	assert.Equal(t, execution.PlanCacheStats{Hits: 1, Misses: 1, Len: 1}, cache.Stats())
+
+	// the variables are coerced for every execution
+	_, errs := executor.Do(schema, execution.Params{Query: query, Variables: map[string]interface{}{"skip": true}})
+	assert.EqualError(t, errs, "[graphql: Variable \"value\" has invalid value null.\nExpected type \"String!\", found null. (1:12)]")
+	assert.Equal(t, execution.PlanCacheStats{Hits: 2, Misses: 1, Len: 1}, cache.Stats())
+
+	// the executions bind the variables concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value := fmt.Sprint(i)
+			result, errs := executor.Do(schema, execution.Params{Query: query,
+				Variables: map[string]interface{}{"value": value, "skip": true}})
+			assert.Nil(t, errs)
+			assert.Equal(t, map[string]interface{}{"static": "static", "bound": value, "items": []interface{}{"a", value}},
+				result)
+		}()
+	}
+	wg.Wait()
+
+	executor.Do(schema, execution.Params{Query: `{ a: echo(value: "a") }`})
+	executor.Do(schema, execution.Params{Query: `{ b: echo(value: "b") }`})
+	assert.Equal(t, execution.PlanCacheStats{Hits: 22, Misses: 3, Evictions: 1, Len: 2}, cache.Stats())
+}
+
+func TestWriteJSON(t *testing.T) {
+	values := []interface{}{
+		nil, true, 0, -42, int32(7), int64(1) << 60, uint(3), uint64(1) << 63,
+		0.0, -0.5, 3.14, 1e21, 1e-7, 123456789.0, float32(0.1), float32(1e-7),
+		"", "plain", "quote\" backslash\\ \n\r\t\b\f\x00\x1f", "<a href=\"x\">&</a>", "é ✓ 𝄞", "  ", "\xff\xfe invalid",
+		[]interface{}{}, []interface{}{1, "two", nil, []interface{}{3.5}},
+		map[string]interface{}{}, map[string]interface{}{"b": 1, "a": map[string]interface{}{"<k>": []interface{}{true}}},
+		time.Unix(0, 0).UTC(), json.Number("12"), []string{"typed"}, map[string]int{"typed": 1}, int8(-8),
+	}
+	for _, value := range values {
+		var buf bytes.Buffer
+		assert.NoError(t, execution.WriteJSON(&buf, value), "%#v", value)
+		expected, _ := json.Marshal(value)
+		assert.Equal(t, string(expected), buf.String(), "%#v", value)
+	}
+
+	var buf bytes.Buffer
+	assert.EqualError(t, execution.WriteJSON(&buf, map[string]interface{}{"nan": math.NaN()}),
+		"json: unsupported value: NaN")
 }
@@ -0,0 +1,53 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// IntrospectionPolicy decides whether the operation run with ctx may introspect the schema with the __schema and
+// __type fields, eg. to hide the schema from the anonymous users. __typename is always allowed.
+type IntrospectionPolicy func(ctx context.Context) bool
+
+// DisableIntrospection is the IntrospectionPolicy of the schemas which are not introspectable at all.
+func DisableIntrospection(context.Context) bool {
+	return false
+}
+
+// CheckIntrospection returns an error when the root selectionSet of an operation introspects the schema while
+// allow denies it for ctx. A nil policy allows the introspection.
+func CheckIntrospection(ctx context.Context, selectionSet *internal.SelectionSet, allow IntrospectionPolicy) error {
+	if allow == nil || selectionSet == nil {
+		return nil
+	}
+	selection := findIntrospection(selectionSet)
+	if selection == nil || allow(ctx) {
+		return nil
+	}
+	return &errors.GraphQLError{
+		Message: fmt.Sprintf("GraphQL introspection has been disabled, but the requested query contained the field %q.",
+			selection.Name),
+		Locations:  []errors.Location{selection.Loc},
+		Kind:       errors.KindValidation,
+		Extensions: map[string]interface{}{"code": errors.CodeValidationFailed},
+	}
+}
+
+// findIntrospection returns the first __schema or __type field of selectionSet, the meta-fields being selected
+// on the query type only.
+func findIntrospection(selectionSet *internal.SelectionSet) *internal.Selection {
+	for _, selection := range selectionSet.Selections {
+		if selection.Name == "__schema" || selection.Name == "__type" {
+			return selection
+		}
+	}
+	for _, fragment := range selectionSet.Fragments {
+		if selection := findIntrospection(fragment.Fragment.SelectionSet); selection != nil {
+			return selection
+		}
+	}
+	return nil
+}
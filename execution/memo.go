@@ -0,0 +1,75 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type memoKey struct{}
+
+// memoStore holds the memoized values of one operation.
+type memoStore struct {
+	mu      sync.Mutex
+	entries map[interface{}]*memoEntry
+}
+
+type memoEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func withMemo(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(memoKey{}).(*memoStore); ok {
+		return ctx, func() {}
+	}
+	store := &memoStore{entries: make(map[interface{}]*memoEntry)}
+	return context.WithValue(ctx, memoKey{}, store), func() {
+		store.mu.Lock()
+		store.entries = nil
+		store.mu.Unlock()
+	}
+}
+
+// Memo returns the result of fn for key, computed once per operation and shared by all of its resolvers,
+// eg. the permissions of the current user. Concurrent callers of the same key wait for the first one,
+// errors are memoized as well. The values are released when the operation ends.
+// Keys are compared like map keys, use an unexported type to avoid collisions.
+// Outside of an operation fn is called every time.
+func Memo[T any](ctx context.Context, key interface{}, fn func() (T, error)) (T, error) {
+	store, ok := ctx.Value(memoKey{}).(*memoStore)
+	if !ok {
+		return fn()
+	}
+	store.mu.Lock()
+	if store.entries == nil {
+		store.mu.Unlock()
+		return fn()
+	}
+	entry, ok := store.entries[key]
+	if !ok {
+		entry = &memoEntry{done: make(chan struct{})}
+		store.entries[key] = entry
+	}
+	store.mu.Unlock()
+
+	if ok {
+		<-entry.done
+	} else {
+		func() {
+			completed := false
+			defer func() {
+				// the callers waiting for a panicking fn get an error, the panic goes on in this one
+				if !completed {
+					entry.err = fmt.Errorf("memo %v: computation panicked", key)
+				}
+				close(entry.done)
+			}()
+			entry.value, entry.err = fn()
+			completed = true
+		}()
+	}
+	value, _ := entry.value.(T)
+	return value, entry.err
+}
@@ -0,0 +1,62 @@
+package execution
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/shyptr/graphql/clock"
+)
+
+type requestIDKey struct{}
+
+type metadataKey struct{}
+
+// WithRequestID returns a context carrying the id of the request, eg. attached by the transport from a header, and
+// retrieved with RequestID by the resolvers, the error presenters and the interceptors.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the id of the request of ctx, empty when it has none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request id of 16 bytes in hexadecimal, read from r, eg. a seeded clock.NewRand in
+// tests, or from crypto/rand when r is nil.
+func NewRequestID(r clock.Rand) string {
+	var id [16]byte
+	if r == nil {
+		rand.Read(id[:])
+	} else {
+		for i := range id {
+			id[i] = byte(r.Intn(256))
+		}
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// WithMetadata returns a context carrying the metadata of ctx along with value under key, eg. the tenant or the
+// client of the request, retrieved with Metadata. The metadata of ctx is left as is.
+func WithMetadata(ctx context.Context, key string, value interface{}) context.Context {
+	parent, _ := ctx.Value(metadataKey{}).(map[string]interface{})
+	metadata := make(map[string]interface{}, len(parent)+1)
+	for k, v := range parent {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// Metadata returns the metadata of the request of ctx, nil when it has none. It must not be modified.
+func Metadata(ctx context.Context) map[string]interface{} {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]interface{})
+	return metadata
+}
+
+// MetadataValue returns the metadata of the request of ctx under key, nil when it has none.
+func MetadataValue(ctx context.Context, key string) interface{} {
+	return Metadata(ctx)[key]
+}
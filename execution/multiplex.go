@@ -0,0 +1,220 @@
+package execution
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/shyptr/graphql/errors"
+)
+
+// SubscriptionMultiplexer shares one execution between the identical subscriptions of many clients, eg. a
+// popular live query: the first subscriber of a key starts the subscription, the next ones receive its responses
+// from then on, and the subscription is stopped when the last subscriber leaves.
+//
+// The shared subscription runs with the values of the context of its first subscriber, so only the subscriptions
+// whose results do not depend on the subscriber, eg. on the user, should be multiplexed. Every subscriber has a
+// buffer of its own, a subscriber which does not receive its responses is handled with the Overflow policy so
+// that it does not delay the others.
+type SubscriptionMultiplexer struct {
+	// Buffer is the number of responses waiting for each subscriber, DefaultMultiplexerBuffer by default.
+	Buffer int
+	// Overflow is the policy applied to a subscriber whose buffer is full, CloseWithError by default: its
+	// subscription ends while the others go on. Block makes the slow subscribers delay the others.
+	Overflow OverflowPolicy
+
+	mu      sync.Mutex
+	sources map[string]*multiplexedSource
+}
+
+// DefaultMultiplexerBuffer is the default buffer of the subscribers of a SubscriptionMultiplexer.
+const DefaultMultiplexerBuffer = 16
+
+type multiplexedSource struct {
+	// ready is closed once the subscription is started, err being the error starting it
+	ready  chan struct{}
+	err    error
+	cancel context.CancelFunc
+	// stopped tells that the subscription completed or that it has no subscriber left
+	stopped     bool
+	subscribers map[*multiplexedSubscriber]struct{}
+}
+
+type multiplexedSubscriber struct {
+	ctx       context.Context
+	mu        sync.Mutex
+	closed    bool
+	done      chan struct{}
+	responses chan *Response
+}
+
+func NewSubscriptionMultiplexer() *SubscriptionMultiplexer {
+	return &SubscriptionMultiplexer{
+		Buffer:   DefaultMultiplexerBuffer,
+		Overflow: CloseWithError,
+		sources:  make(map[string]*multiplexedSource),
+	}
+}
+
+// MultiplexKey returns the key identifying a subscription request, see SubscriptionMultiplexer.Subscribe.
+func MultiplexKey(query, operationName string, variables map[string]interface{}) string {
+	var vars []byte
+	if len(variables) > 0 {
+		// the keys of the maps are sorted by encoding/json
+		vars, _ = json.Marshal(variables)
+	}
+	h := sha256.New()
+	h.Write([]byte(query))
+	h.Write([]byte{0})
+	h.Write([]byte(operationName))
+	h.Write([]byte{0})
+	h.Write(vars)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Subscribe subscribes to the responses of the subscription identified by key, calling subscribe to start it
+// when it is not running yet, eg. with a closure over Executor.Subscribe. The returned channel is closed once the
+// subscription completes, ctx is done or the subscriber overflows. The responses are shared between the
+// subscribers and must not be modified.
+//
+// The subscription is started without holding the lock of the multiplexer, the subscribers of the same key
+// waiting for it meanwhile.
+func (m *SubscriptionMultiplexer) Subscribe(ctx context.Context, key string,
+	subscribe func(ctx context.Context) (<-chan *Response, error)) (<-chan *Response, error) {
+	for {
+		m.mu.Lock()
+		source, ok := m.sources[key]
+		if !ok {
+			source = &multiplexedSource{ready: make(chan struct{}), subscribers: make(map[*multiplexedSubscriber]struct{})}
+			m.sources[key] = source
+		}
+		m.mu.Unlock()
+		if !ok {
+			return m.start(ctx, key, source, subscribe)
+		}
+
+		select {
+		case <-source.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if source.err != nil {
+			return nil, source.err
+		}
+		m.mu.Lock()
+		if source.stopped {
+			// the subscription ended meanwhile, a new one is started
+			m.mu.Unlock()
+			continue
+		}
+		subscriber := m.join(ctx, key, source)
+		m.mu.Unlock()
+		return subscriber.responses, nil
+	}
+}
+
+// start starts the subscription of source for its first subscriber.
+func (m *SubscriptionMultiplexer) start(ctx context.Context, key string, source *multiplexedSource,
+	subscribe func(ctx context.Context) (<-chan *Response, error)) (<-chan *Response, error) {
+	defer close(source.ready)
+	// the subscription outlives its first subscriber
+	sourceCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	responses, err := subscribe(sourceCtx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		cancel()
+		source.err, source.stopped = err, true
+		if m.sources[key] == source {
+			delete(m.sources, key)
+		}
+		return nil, err
+	}
+	source.cancel = cancel
+	subscriber := m.join(ctx, key, source)
+	go m.fanOut(key, source, responses)
+	return subscriber.responses, nil
+}
+
+// join adds a subscriber to source, m.mu being held.
+func (m *SubscriptionMultiplexer) join(ctx context.Context, key string, source *multiplexedSource) *multiplexedSubscriber {
+	subscriber := &multiplexedSubscriber{ctx: ctx, done: make(chan struct{}), responses: make(chan *Response, m.Buffer)}
+	source.subscribers[subscriber] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.leave(key, source, subscriber)
+		case <-subscriber.done:
+		}
+	}()
+	return subscriber
+}
+
+func (m *SubscriptionMultiplexer) fanOut(key string, source *multiplexedSource, responses <-chan *Response) {
+	for response := range responses {
+		for _, subscriber := range m.subscribers(source) {
+			if !subscriber.send(m.Overflow, response) {
+				m.leave(key, source, subscriber)
+			}
+		}
+	}
+	m.mu.Lock()
+	source.stopped = true
+	if m.sources[key] == source {
+		delete(m.sources, key)
+	}
+	m.mu.Unlock()
+	for _, subscriber := range m.subscribers(source) {
+		subscriber.close()
+	}
+}
+
+func (m *SubscriptionMultiplexer) subscribers(source *multiplexedSource) []*multiplexedSubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscribers := make([]*multiplexedSubscriber, 0, len(source.subscribers))
+	for subscriber := range source.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	return subscribers
+}
+
+// leave removes a subscriber whose context is done or which overflowed, and stops the subscription once it has
+// none.
+func (m *SubscriptionMultiplexer) leave(key string, source *multiplexedSource, subscriber *multiplexedSubscriber) {
+	m.mu.Lock()
+	delete(source.subscribers, subscriber)
+	if len(source.subscribers) == 0 {
+		source.stopped = true
+		if m.sources[key] == source {
+			delete(m.sources, key)
+		}
+		source.cancel()
+	}
+	m.mu.Unlock()
+	subscriber.close()
+}
+
+// send sends response to the subscriber according to policy, it returns false when the subscriber must leave.
+func (s *multiplexedSubscriber) send(policy OverflowPolicy, response *Response) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return true
+	}
+	return sendWithOverflow(s.ctx, policy, s.responses, response, func() *Response {
+		return &Response{Errors: errors.MultiError{ErrSubscriptionOverflow}}
+	})
+}
+
+func (s *multiplexedSubscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+		close(s.responses)
+	}
+}
@@ -0,0 +1,126 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Operation is a request going through Executor.Run, it is filled in as the request moves on:
+// Document is set once the query is parsed, Type and SelectionSet once it is validated. The document and the
+// selection set are shared with the other operations of the same query through the caches of the executor, they
+// must not be modified.
+type Operation struct {
+	Params
+	Document     *internal.Document
+	Type         ast.OperationType
+	SelectionSet *internal.SelectionSet
+
+	// fields are the fields collected for the selection set, shared with the other executions of its plan
+	fields *fieldPlan
+}
+
+// Response is the outcome of an operation. Data is nil when the operation is rejected before being executed,
+// otherwise it is returned along with the errors of the fields and items which failed, which are null.
+type Response struct {
+	Data   interface{}
+	Errors errors.MultiError
+}
+
+// Interceptor hooks into the lifecycle of the operations run by an executor, for auth gates, audit trails and the like.
+// Every hook is optional, a hook returning an error rejects the operation with it.
+type Interceptor struct {
+	AfterParse      func(ctx context.Context, op *Operation) error
+	AfterValidation func(ctx context.Context, op *Operation) error
+	// BeforeExecution may return the context to execute the operation with, eg. carrying the authenticated user.
+	BeforeExecution func(ctx context.Context, op *Operation) (context.Context, error)
+	// AfterExecution sees the final response, it is called for rejected operations too and may modify the response.
+	AfterExecution func(ctx context.Context, op *Operation, response *Response)
+}
+
+// Run parses, validates and executes a request through the stages of the executor. The interceptors are run after
+// those of the executor.
+func (e *Executor) Run(schema *internal.Schema, param Params, interceptors ...*Interceptor) (*Operation, *Response) {
+	interceptors = append(e.Interceptors[:len(e.Interceptors):len(e.Interceptors)], interceptors...)
+	op := &Operation{Params: param}
+	ctx := param.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	response := &Response{}
+	stages, logSlowQuery := e.slowQueryLog(e.stages(), op)
+	defer func() {
+		for _, interceptor := range interceptors {
+			if interceptor.AfterExecution != nil {
+				interceptor.AfterExecution(ctx, op, response)
+			}
+		}
+		logSlowQuery(ctx, response)
+		response.Errors = e.presentErrors(ctx, response.Errors)
+	}()
+
+	if err := e.resolvePersistedQuery(ctx, &op.Params); err != nil {
+		response.Errors = errors.MultiError{err}
+		return op, response
+	}
+	if errs := stages.Parse(ctx, op); errs != nil {
+		response.Errors = errs
+		return op, response
+	}
+	for _, interceptor := range interceptors {
+		if interceptor.AfterParse == nil {
+			continue
+		}
+		if err := interceptor.AfterParse(ctx, op); err != nil {
+			response.Errors = toMultiError(err)
+			return op, response
+		}
+	}
+
+	if errs := stages.Validate(ctx, schema, op); len(errs) > 0 {
+		response.Errors = errs
+		return op, response
+	}
+	if err := stages.Plan(ctx, schema, op); err != nil {
+		response.Errors = toMultiError(err)
+		return op, response
+	}
+	for _, interceptor := range interceptors {
+		if interceptor.AfterValidation == nil {
+			continue
+		}
+		if err := interceptor.AfterValidation(ctx, op); err != nil {
+			response.Errors = toMultiError(err)
+			return op, response
+		}
+	}
+
+	for _, interceptor := range interceptors {
+		if interceptor.BeforeExecution == nil {
+			continue
+		}
+		var err error
+		if ctx, err = interceptor.BeforeExecution(ctx, op); err != nil {
+			response.Errors = toMultiError(err)
+			return op, response
+		}
+	}
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		r.setRequest(op.Params)
+	}
+	response.Data, response.Errors = stages.Execute(ctx, schema, op)
+	return op, response
+}
+
+func toMultiError(err error) errors.MultiError {
+	switch err := err.(type) {
+	case errors.MultiError:
+		return err
+	case *errors.GraphQLError:
+		return errors.MultiError{err}
+	default:
+		return errors.MultiError{errors.FromError(err)}
+	}
+}
@@ -0,0 +1,101 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Stages are the stages of the operations run by Executor.Run, parse → validate → plan → execute, each of which
+// may be replaced, eg. by a parser caching the documents or by a validation skipping the trusted documents. A nil
+// stage is the default one, the method of the executor of the same name, which the replacement may call:
+//
+//	executor.Stages.Validate = func(ctx context.Context, schema *internal.Schema, op *execution.Operation) errors.MultiError {
+//		if trusted[op.Query] {
+//			return nil
+//		}
+//		return executor.Validate(ctx, schema, op)
+//	}
+type Stages struct {
+	// Parse sets the document of the operation.
+	Parse func(ctx context.Context, op *Operation) errors.MultiError
+	// Validate validates the document of the operation against the schema.
+	Validate func(ctx context.Context, schema *internal.Schema, op *Operation) errors.MultiError
+	// Plan sets the type and the selection set of the operation, coercing its variables.
+	Plan func(ctx context.Context, schema *internal.Schema, op *Operation) error
+	// Execute executes the selection set of the operation and returns its data.
+	Execute func(ctx context.Context, schema *internal.Schema, op *Operation) (interface{}, errors.MultiError)
+}
+
+// Parse is the default parse stage, it parses the query of op through the document cache of the executor.
+func (e *Executor) Parse(ctx context.Context, op *Operation) errors.MultiError {
+	doc, errs := e.documentCache().Get(op.Query)
+	if errs != nil {
+		return errs
+	}
+	op.Document = doc
+	return nil
+}
+
+// Validate is the default validate stage, it validates the document of op through the validation cache of the
+// executor.
+func (e *Executor) Validate(ctx context.Context, schema *internal.Schema, op *Operation) errors.MultiError {
+	if len(op.Document.Operations) == 0 {
+		return errors.News("no operations in query document")
+	}
+	return e.validationCache().Validate(schema, op.Query, op.Document)
+}
+
+// Plan is the default plan stage, it selects the operation of the document through the plan cache of the
+// executor, coerces its variables and checks it against the introspection policy and the maximum depth of the
+// executor.
+func (e *Executor) Plan(ctx context.Context, schema *internal.Schema, op *Operation) error {
+	if err := e.planOperation(schema, op); err != nil {
+		return err
+	}
+	if op.Type == ast.Subscription {
+		return errors.New("subscriptions must be executed with Subscribe")
+	}
+	if err := CheckIntrospection(ctx, op.SelectionSet, e.Introspection); err != nil {
+		return err
+	}
+	return CheckDepth(op.SelectionSet, e.MaxDepth)
+}
+
+// ExecuteOperation is the default execute stage, it executes the selection set of op on the root type of its
+// operation type.
+func (e *Executor) ExecuteOperation(ctx context.Context, schema *internal.Schema, op *Operation) (interface{}, errors.MultiError) {
+	root := schema.Query
+	if op.Type == ast.Mutation {
+		root = schema.Mutation
+	}
+	fields := op.fields
+	if fields == nil {
+		fields = newFieldPlan()
+	}
+	return e.executePlan(ctx, root, nil, op.SelectionSet, fields)
+}
+
+// validated skips the validation of the documents validated by the validate stage.
+func validated(*internal.Schema, *internal.Document) errors.MultiError {
+	return nil
+}
+
+func (e *Executor) stages() Stages {
+	stages := e.Stages
+	if stages.Parse == nil {
+		stages.Parse = e.Parse
+	}
+	if stages.Validate == nil {
+		stages.Validate = e.Validate
+	}
+	if stages.Plan == nil {
+		stages.Plan = e.Plan
+	}
+	if stages.Execute == nil {
+		stages.Execute = e.ExecuteOperation
+	}
+	return stages
+}
@@ -0,0 +1,324 @@
+package execution
+
+import (
+	"sync"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// PlanCache keeps the execution plans of the most recently executed operations, so the operations executed again
+// and again are planned once: their selection set, the fields collected for each of its selection sets and
+// object types and the definitions of these fields are reused, only the variables are coerced and bound to the
+// arguments referencing them for every execution. The plans are keyed by the schema, the parsed document and the
+// operation name, the documents being shared through the DocumentCache. It is safe for concurrent use.
+type PlanCache struct {
+	mu    sync.Mutex
+	lru   *internal.LRU[planCacheKey, *operationPlan]
+	stats PlanCacheStats
+}
+
+// PlanCacheStats are the counters of a PlanCache, eg. to export as metrics.
+type PlanCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Len is the number of cached plans.
+	Len int
+}
+
+type planCacheKey struct {
+	schema        *internal.Schema
+	document      *internal.Document
+	operationName string
+}
+
+// NewPlanCache returns a PlanCache of size plans at most.
+func NewPlanCache(size int) *PlanCache {
+	return &PlanCache{lru: internal.NewLRU[planCacheKey, *operationPlan](size)}
+}
+
+// DefaultPlanCacheSize is the number of plans of the plan cache shared by the executors.
+const DefaultPlanCacheSize = 1024
+
+var defaultPlanCache = NewPlanCache(DefaultPlanCacheSize)
+
+func (e *Executor) planCache() *PlanCache {
+	if e.PlanCache != nil {
+		return e.PlanCache
+	}
+	return defaultPlanCache
+}
+
+// get returns the plan of the operation named operationName of document, planned unless it is cached. The
+// document must be validated.
+func (c *PlanCache) get(schema *internal.Schema, document *internal.Document, operationName string) (*operationPlan, error) {
+	key := planCacheKey{schema: schema, document: document, operationName: operationName}
+	c.mu.Lock()
+	if plan, ok := c.lru.Get(key); ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return plan, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// concurrent misses of an operation plan it each, the plans are the same
+	plan, err := newOperationPlan(schema, document, operationName)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lru.Contains(key) {
+		c.stats.Evictions += uint64(c.lru.Add(key, plan))
+	}
+	return plan, nil
+}
+
+// Stats returns the counters of the cache.
+func (c *PlanCache) Stats() PlanCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Len = c.lru.Len()
+	return stats
+}
+
+// planBindings are the arguments of the fields and of the directives of a planned selection set which reference
+// variables, along with the definitions of the variables.
+type planBindings struct {
+	definitions []*ast.VariableDefinition
+	selections  map[*internal.Selection]boundArguments
+	directives  map[*internal.Directive]boundArguments
+}
+
+// boundArguments are the arguments of a field or a directive which reference variables, along with their
+// definitions.
+type boundArguments struct {
+	arguments   []*ast.Argument
+	definitions map[string]*internal.InputField
+}
+
+// operationPlan is the plan of an operation, shared by its executions.
+type operationPlan struct {
+	typ          ast.OperationType
+	selectionSet *internal.SelectionSet
+	bindings     planBindings
+	// dirty are the selection sets holding arguments bound to the variables, themselves or their subselections,
+	// they are copied by every execution. The other selection sets are static.
+	dirty  map[*internal.SelectionSet]bool
+	static map[*internal.SelectionSet]bool
+	// fields are the fields collected for the static selection sets
+	fields *fieldPlan
+}
+
+func newOperationPlan(schema *internal.Schema, document *internal.Document, operationName string) (*operationPlan, error) {
+	plan := &operationPlan{
+		bindings: planBindings{
+			selections: make(map[*internal.Selection]boundArguments),
+			directives: make(map[*internal.Directive]boundArguments),
+		},
+		dirty:  make(map[*internal.SelectionSet]bool),
+		static: make(map[*internal.SelectionSet]bool),
+		fields: newFieldPlan(),
+	}
+	var err error
+	plan.typ, plan.selectionSet, err = buildSelectionSet(schema, document, operationName, nil, validated, &plan.bindings)
+	if err != nil {
+		return nil, err
+	}
+	plan.markDirty(plan.selectionSet)
+	return plan, nil
+}
+
+// markDirty sorts out the dirty and the static selection sets of selectionSet, and reports whether it is dirty.
+func (p *operationPlan) markDirty(selectionSet *internal.SelectionSet) bool {
+	if selectionSet == nil {
+		return false
+	}
+	if dirty, ok := p.dirty[selectionSet]; ok {
+		return dirty
+	}
+	dirty := false
+	for _, selection := range selectionSet.Selections {
+		_, bound := p.bindings.selections[selection]
+		// every selection set is visited
+		dirty = p.markDirty(selection.SelectionSet) || bound || p.boundDirectives(selection.Directives) || dirty
+	}
+	for _, fragment := range selectionSet.Fragments {
+		dirty = p.markDirty(fragment.Fragment.SelectionSet) || p.boundDirectives(fragment.Directives) || dirty
+	}
+	p.dirty[selectionSet] = dirty
+	if !dirty {
+		p.static[selectionSet] = true
+	}
+	return dirty
+}
+
+func (p *operationPlan) boundDirectives(directives []*internal.Directive) bool {
+	for _, directive := range directives {
+		if _, ok := p.bindings.directives[directive]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bind returns the selection set of the plan with its arguments bound to vars, the coerced variables, along with
+// the field plan of the execution.
+func (p *operationPlan) bind(vars map[string]interface{}) (*internal.SelectionSet, *fieldPlan, error) {
+	if !p.dirty[p.selectionSet] {
+		return p.selectionSet, p.fields, nil
+	}
+	b := &binder{plan: p, vars: vars, fragments: make(map[*internal.FragmentDefinition]*internal.FragmentDefinition)}
+	selectionSet, err := b.bindSelectionSet(p.selectionSet)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the arguments are compared once bound
+	if err := detectConflicts(selectionSet); err != nil {
+		return nil, nil, err
+	}
+	fields := newFieldPlan()
+	fields.shared, fields.static = p.fields, p.static
+	return selectionSet, fields, nil
+}
+
+// binder copies the dirty selection sets of a plan for an execution.
+type binder struct {
+	plan *operationPlan
+	vars map[string]interface{}
+	// fragments are the copies of the fragments spread by the dirty selection sets
+	fragments map[*internal.FragmentDefinition]*internal.FragmentDefinition
+}
+
+func (b *binder) bindSelectionSet(selectionSet *internal.SelectionSet) (*internal.SelectionSet, error) {
+	if selectionSet == nil || !b.plan.dirty[selectionSet] {
+		return selectionSet, nil
+	}
+	bound := &internal.SelectionSet{
+		Loc:        selectionSet.Loc,
+		Selections: make([]*internal.Selection, len(selectionSet.Selections)),
+		Fragments:  make([]*internal.FragmentSpread, len(selectionSet.Fragments)),
+	}
+	for i, selection := range selectionSet.Selections {
+		copied := *selection
+		if arguments, ok := b.plan.bindings.selections[selection]; ok {
+			args, err := argsToJson(arguments.arguments, arguments.definitions, b.vars)
+			if err != nil {
+				return nil, err
+			}
+			copied.Args = args
+		}
+		var err error
+		if copied.Directives, err = b.bindDirectives(selection.Directives); err != nil {
+			return nil, err
+		}
+		if copied.SelectionSet, err = b.bindSelectionSet(selection.SelectionSet); err != nil {
+			return nil, err
+		}
+		bound.Selections[i] = &copied
+	}
+	for i, fragment := range selectionSet.Fragments {
+		copied := *fragment
+		var err error
+		if copied.Directives, err = b.bindDirectives(fragment.Directives); err != nil {
+			return nil, err
+		}
+		if copied.Fragment, err = b.bindFragment(fragment.Fragment); err != nil {
+			return nil, err
+		}
+		bound.Fragments[i] = &copied
+	}
+	return bound, nil
+}
+
+// bindFragment copies the fragment definition once for all of its spreads, as its named fragments are visited once
+// when the fields are collected.
+func (b *binder) bindFragment(fragment *internal.FragmentDefinition) (*internal.FragmentDefinition, error) {
+	if !b.plan.dirty[fragment.SelectionSet] {
+		return fragment, nil
+	}
+	if copied, ok := b.fragments[fragment]; ok {
+		return copied, nil
+	}
+	copied := *fragment
+	var err error
+	if copied.SelectionSet, err = b.bindSelectionSet(fragment.SelectionSet); err != nil {
+		return nil, err
+	}
+	b.fragments[fragment] = &copied
+	return &copied, nil
+}
+
+func (b *binder) bindDirectives(directives []*internal.Directive) ([]*internal.Directive, error) {
+	if !b.plan.boundDirectives(directives) {
+		return directives, nil
+	}
+	bound := make([]*internal.Directive, len(directives))
+	for i, directive := range directives {
+		bound[i] = directive
+		if arguments, ok := b.plan.bindings.directives[directive]; ok {
+			args, err := argsToJson(arguments.arguments, arguments.definitions, b.vars)
+			if err != nil {
+				return nil, err
+			}
+			copied := *directive
+			copied.ArgVals = args
+			bound[i] = &copied
+		}
+	}
+	return bound, nil
+}
+
+// hasVariables reports whether the values of arguments reference variables.
+func hasVariables(arguments []*ast.Argument) bool {
+	for _, argument := range arguments {
+		if valueHasVariables(argument.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueHasVariables(value ast.Value) bool {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return true
+	case *ast.ListValue:
+		for _, item := range value.Values {
+			if valueHasVariables(item) {
+				return true
+			}
+		}
+	case *ast.ObjectValue:
+		for _, field := range value.Fields {
+			if valueHasVariables(field.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planOperation sets the type and the selection set of op from the plan of its operation, binding its variables.
+func (e *Executor) planOperation(schema *internal.Schema, op *Operation) error {
+	plan, err := e.planCache().get(schema, op.Document, op.OperationName)
+	if err != nil {
+		return err
+	}
+	vars := op.Variables
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+	vars, errs := coerceVariableValues(schema, plan.bindings.definitions, vars)
+	if len(errs) == 1 {
+		return errs[0]
+	} else if len(errs) > 1 {
+		return errs
+	}
+	op.Type = plan.typ
+	op.SelectionSet, op.fields, err = plan.bind(vars)
+	return err
+}
@@ -0,0 +1,92 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// PreparedQuery is a query parsed, validated and planned once and executed many times, eg. the hot queries of a
+// server. The variables are coerced and bound to the arguments referencing them for every execution, the selection
+// sets without such arguments and the fields collected for them are shared by the executions.
+type PreparedQuery struct {
+	executor      *Executor
+	schema        *internal.Schema
+	query         string
+	operationName string
+	plan          *operationPlan
+}
+
+// Compile prepares query with a zero Executor, see Executor.Compile.
+func Compile(schema *internal.Schema, query string, operationName ...string) (*PreparedQuery, error) {
+	return (&Executor{}).Compile(schema, query, operationName...)
+}
+
+// Compile parses query, validates it through the validation cache of the executor and plans its execution.
+// operationName selects the operation of documents having several of them.
+func (e *Executor) Compile(schema *internal.Schema, query string, operationName ...string) (*PreparedQuery, error) {
+	doc, parseErrs := internal.Parse(query)
+	if parseErrs != nil {
+		return nil, parseErrs
+	}
+	p := &PreparedQuery{executor: e, schema: schema, query: query}
+	if len(operationName) > 0 {
+		p.operationName = operationName[0]
+	}
+	if errs := e.validationCache().Validate(schema, query, doc); len(errs) == 1 {
+		return nil, errs[0]
+	} else if len(errs) > 1 {
+		return nil, errs
+	}
+	plan, err := newOperationPlan(schema, doc, p.operationName)
+	if err != nil {
+		return nil, err
+	}
+	if plan.typ == ast.Subscription {
+		return nil, errors.New("subscriptions must be executed with Subscribe")
+	}
+	if err := CheckDepth(plan.selectionSet, e.MaxDepth); err != nil {
+		return nil, err
+	}
+	p.plan = plan
+	return p, nil
+}
+
+// Execute executes the prepared query with variables, it is safe for concurrent use.
+// The interceptors of the executor are not run, its ErrorPresenter is.
+func (p *PreparedQuery) Execute(ctx context.Context, variables map[string]interface{}) (interface{}, errors.MultiError) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	data, errs := p.execute(ctx, variables)
+	return data, p.executor.presentErrors(ctx, errs)
+}
+
+func (p *PreparedQuery) execute(ctx context.Context, variables map[string]interface{}) (interface{}, errors.MultiError) {
+	vars := variables
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+	vars, errs := coerceVariableValues(p.schema, p.plan.bindings.definitions, vars)
+	if errs != nil {
+		return nil, errs
+	}
+	selectionSet, fields, err := p.plan.bind(vars)
+	if err != nil {
+		return nil, toMultiError(err)
+	}
+	// the policy depends on the context of every execution
+	if err := CheckIntrospection(ctx, selectionSet, p.executor.Introspection); err != nil {
+		return nil, toMultiError(err)
+	}
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		r.setRequest(Params{Query: p.query, OperationName: p.operationName, Variables: variables})
+	}
+	root := p.schema.Query
+	if p.plan.typ == ast.Mutation {
+		root = p.schema.Mutation
+	}
+	return p.executor.executePlan(ctx, root, nil, selectionSet, fields)
+}
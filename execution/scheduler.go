@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"context"
+	"sync"
+)
+
+// ThunkScheduler runs the calls of the thunks of the deferred fields of a round, the only tasks the executor runs
+// concurrently, see Executor.ThunkScheduler. It does not schedule the resolvers: the fields are resolved and
+// completed one after the other by the goroutine of the operation, a resolver resolves concurrently by returning a
+// thunk, eg. the one of a dataloader. It is shared by the operations of the executor, so it bounds the goroutines
+// of all of them.
+type ThunkScheduler interface {
+	// Go runs task in a goroutine of its own, it blocks until the task may start. It returns an error without
+	// running task when task is not to be run, eg. the error of ctx once it is done, the executor then calls the
+	// thunk itself.
+	Go(ctx context.Context, task func()) error
+}
+
+// WorkerPool is the ThunkScheduler running size tasks at most at once, the others wait for a task to be done.
+// It is safe for concurrent use.
+type WorkerPool struct {
+	slots chan struct{}
+}
+
+// NewWorkerPool returns a WorkerPool of size concurrent tasks, size must be positive.
+func NewWorkerPool(size int) *WorkerPool {
+	return &WorkerPool{slots: make(chan struct{}, size)}
+}
+
+func (p *WorkerPool) Go(ctx context.Context, task func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	go func() {
+		defer func() { <-p.slots }()
+		task()
+	}()
+	return nil
+}
+
+// DefaultThunkWorkers is the number of tasks of the WorkerPool shared by the executors without a ThunkScheduler.
+const DefaultThunkWorkers = 256
+
+var defaultThunkScheduler = NewWorkerPool(DefaultThunkWorkers)
+
+func (e *Executor) thunkScheduler() ThunkScheduler {
+	if e.ThunkScheduler != nil {
+		return e.ThunkScheduler
+	}
+	return defaultThunkScheduler
+}
+
+// concurrent reports whether the thunks of the rounds are called concurrently.
+func (e *Executor) concurrent() bool {
+	return e.ThunkScheduler != nil || e.MaxConcurrentThunks > 1
+}
+
+// callThunks calls the thunks of fields concurrently, through the ThunkScheduler of the executor or the default one
+// and MaxConcurrentThunks at most at once, and waits for them. The result of a thunk which is not called, eg. as ctx is
+// done, is nil, its field calls it when completed.
+func (e *Executor) callThunks(ctx *exeContext, fields []*deferredField) []*resolved {
+	results := make([]*resolved, len(fields))
+	scheduler := e.thunkScheduler()
+	// limit is the budget of the operation, on top of the one of the scheduler
+	var limit chan struct{}
+	if e.MaxConcurrentThunks > 0 {
+		limit = make(chan struct{}, e.MaxConcurrentThunks)
+	}
+	var wg sync.WaitGroup
+	for i, d := range fields {
+		if ctx.Err() != nil {
+			break
+		}
+		if limit != nil {
+			select {
+			case limit <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		wg.Add(1)
+		err := scheduler.Go(ctx, func() {
+			defer wg.Done()
+			if limit != nil {
+				defer func() { <-limit }()
+			}
+			results[i] = e.callThunk(ctx.Context, d.thunk)
+		})
+		if err != nil {
+			wg.Done()
+			if limit != nil {
+				<-limit
+			}
+		}
+	}
+	wg.Wait()
+	return results
+}
+
+// callThunk calls thunk, turning its panic into its error.
+func (e *Executor) callThunk(ctx context.Context, thunk func() (interface{}, error)) (r *resolved) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			r = &resolved{err: e.recoverPanic(ctx, panicErr, stack())}
+		}
+	}()
+	value, err := thunk()
+	return &resolved{value: value, err: err}
+}
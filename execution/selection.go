@@ -8,18 +8,33 @@ import (
 	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/utils"
+	"github.com/shyptr/graphql/validation"
 )
 
 func printErr(loc errors.Location, rule string, format string, a ...interface{}) error {
 	return &errors.GraphQLError{
-		Message:   fmt.Sprintf(format, a...),
-		Locations: []errors.Location{loc},
-		Rule:      rule,
+		Message:    fmt.Sprintf(format, a...),
+		Locations:  []errors.Location{loc},
+		Rule:       rule,
+		Extensions: map[string]interface{}{"code": errors.CodeValidationFailed},
 	}
 }
 
 func ApplySelectionSet(schema *internal.Schema, document *internal.Document, operationName string, vars map[string]interface{}) (
 	ast.OperationType, *internal.SelectionSet, error) {
+	return applySelectionSet(schema, document, operationName, vars, validation.Validate)
+}
+
+// applySelectionSet is ApplySelectionSet validating the document with validate, eg. through a validation cache.
+func applySelectionSet(schema *internal.Schema, document *internal.Document, operationName string, vars map[string]interface{},
+	validate func(*internal.Schema, *internal.Document) errors.MultiError) (ast.OperationType, *internal.SelectionSet, error) {
+	return buildSelectionSet(schema, document, operationName, vars, validate, nil)
+}
+
+// buildSelectionSet is applySelectionSet recording the arguments referencing variables into bindings when they are
+// not nil, the variables being coerced and bound for every execution of the plan instead.
+func buildSelectionSet(schema *internal.Schema, document *internal.Document, operationName string, vars map[string]interface{},
+	validate func(*internal.Schema, *internal.Document) errors.MultiError, bindings *planBindings) (ast.OperationType, *internal.SelectionSet, error) {
 
 	if document == nil {
 		return "", nil, errors.New("must provide document")
@@ -27,6 +42,11 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 	if len(document.Operations) == 0 {
 		return "", nil, errors.New("no operations in query document")
 	}
+	if errs := validate(schema, document); len(errs) == 1 {
+		return "", nil, errs[0]
+	} else if len(errs) > 1 {
+		return "", nil, errs
+	}
 	if vars == nil {
 		vars = make(map[string]interface{})
 	}
@@ -51,9 +71,9 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 	}
 	var opName string
 	if op.Name != nil {
-		opName = opName
+		opName = op.Name.Name
 	}
-	if op.Operation == "subscription" && len(op.SelectionSet.Selections) != 1 {
+	if op.Operation == ast.Subscription && len(op.SelectionSet.Selections) != 1 {
 		if opName != "" {
 			return "", nil, printErr(op.Loc, "Single root field", `Subscription "%s" must select only one top level field.`, opName)
 		} else {
@@ -86,68 +106,23 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 		}
 	}
 
-	// set default value
-	varset := make(map[string]struct{})
-	for _, v := range op.Vars {
-		variableName := v.Var.Name.Name
-		if _, ok := varset[variableName]; ok {
-			return "", nil, printErr(v.Loc, "Variable Uniqueness", "duplicate variable name %s", variableName)
-		}
-		varset[variableName] = struct{}{}
-		vTyp, err := utils.TypeFromAst(schema, v.Type)
-		if err != nil {
-			return "", nil, printErr(v.Loc, "ValuesOfCorrectType", err.Error())
-		}
-		if vTyp != nil && !internal.IsInputType(vTyp) {
-			return "", nil, printErr(v.Loc, "Variables Are Input Types", `Variable "$%s" cannot be non-input type "%s".`, variableName, v.Type.String())
-		}
-		if value, ok := vars[variableName]; !ok {
-			return "", nil, printErr(v.Loc, "NoUndefinedVariables", "Variable %q is not defined%s.", variableName, opName)
-		} else if ok && value == nil {
-			if v.DefaultValue != nil {
-				value, err := internal.ValueToJson(v.DefaultValue, nil)
-				if err != nil {
-					return "", nil, printErr(v.Loc, "DefaultValuesOfCorrectType", err.Error())
-				} else {
-					vars[variableName] = value
-				}
-			}
-		}
-		if err := validateValue(v, vars[variableName], vTyp); err != nil {
-			return "", nil, err
+	definitions := op.Vars
+	for _, fragment := range document.Fragments {
+		definitions = append(definitions[:len(definitions):len(definitions)], fragment.VariableDefinitions...)
+	}
+	if bindings != nil {
+		bindings.definitions = definitions
+	} else {
+		var errs errors.MultiError
+		vars, errs = coerceVariableValues(schema, definitions, vars)
+		if len(errs) == 1 {
+			return "", nil, errs[0]
+		} else if len(errs) > 1 {
+			return "", nil, errs
 		}
 	}
 
 	for _, fragment := range document.Fragments {
-		// set default value
-		for _, v := range fragment.VariableDefinitions {
-			variableName := v.Var.Name.Name
-			if _, ok := varset[variableName]; ok {
-				return "", nil, printErr(v.Loc, "Variable Uniqueness", "duplicate variable name %s", variableName)
-			}
-			varset[variableName] = struct{}{}
-			vTyp, err := utils.TypeFromAst(schema, v.Type)
-			if err != nil {
-				return "", nil, printErr(v.Loc, "ValuesOfCorrectType", err.Error())
-			}
-			if vTyp != nil && !internal.IsInputType(vTyp) {
-				return "", nil, printErr(v.Loc, "Variables Are Input Types", `Variable "$%s" cannot be non-input type "%s".`, variableName, v.Type.String())
-			}
-			if value, ok := vars[variableName]; !ok {
-				return "", nil, printErr(v.Loc, "NoUndefinedVariables", "Variable %q is not defined%s.", variableName, opName)
-			} else if ok && value == nil && v.DefaultValue != nil {
-				value, err := internal.ValueToJson(v.DefaultValue, nil)
-				if err != nil {
-					return "", nil, printErr(v.Loc, "DefaultValuesOfCorrectType", err.Error())
-				} else {
-					vars[variableName] = value
-				}
-			}
-			if err := validateValue(v, vars[variableName], vTyp); err != nil {
-				return "", nil, err
-			}
-		}
-
 		vtyp, err := utils.TypeFromAst(schema, fragment.TypeCondition)
 		if err != nil {
 			return "", nil, printErr(fragment.Loc, "FragmentsOnCompositeTypes", err.Error())
@@ -161,18 +136,19 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 			return "", nil, printErr(fragment.TypeCondition.Loc, "FragmentsOnCompositeTypes", "Fragment %q cannot condition on non composite type %q.", fragment.Name.Name, t)
 		}
 
-		selectionSet, err := parseSelectionSet(schema, t, fragment.SelectionSet, globalFragments, vars)
+		selectionSet, err := parseSelectionSet(schema, t, fragment.SelectionSet, globalFragments, vars, bindings)
 		if err != nil {
 			return "", rv, err
 		}
 		globalFragments[fragment.Name.Name].SelectionSet = selectionSet
+		globalFragments[fragment.Name.Name].Union, _ = t.(*internal.Union)
 	}
 
 	if err := validateDirectives(schema, string(op.Operation), op.Directives); err != nil {
 		return "", nil, err
 	}
 
-	selectionSet, err := parseSelectionSet(schema, obj, op.SelectionSet, globalFragments, vars)
+	selectionSet, err := parseSelectionSet(schema, obj, op.SelectionSet, globalFragments, vars, bindings)
 	if err != nil {
 		return "", rv, err
 	}
@@ -192,7 +168,7 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 
 // parseSelectionSet takes a grapqhl-go selection set and converts it to a simplified *SelectionSet, bindings vars
 func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast.SelectionSet, globalFragments map[string]*internal.FragmentDefinition,
-	vars map[string]interface{}) (*internal.SelectionSet, error) {
+	vars map[string]interface{}, bindings *planBindings) (*internal.SelectionSet, error) {
 	if input == nil {
 		return nil, nil
 	}
@@ -207,9 +183,9 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				alias = selection.Alias.Name
 			}
 
-			if alias == "__typename" {
+			if selection.Name.Name == "__typename" {
 				selections = append(selections, &internal.Selection{
-					Name:  alias,
+					Name:  selection.Name.Name,
 					Alias: alias,
 					Loc:   selection.Loc,
 				})
@@ -218,20 +194,15 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 
 			f := fields(t)[selection.Name.Name]
 			if f == nil {
-				var names []string
-				for name := range fields(t) {
-					names = append(names, name)
-				}
-				suggestion := makeSuggestion("Did you mean", names, selection.Name.Name)
-				return nil, printErr(selection.Alias.Loc, "FieldsOnCorrectType", "Cannot query field %q on type %q.%s", selection.Name.Name, t, suggestion)
+				return nil, printErr(selection.Alias.Loc, "FieldsOnCorrectType", "Cannot query field %q on type %q.", selection.Name.Name, t)
 			}
 
-			args, err := argsToJson(selection.Arguments, vars)
+			args, err := argsToJson(selection.Arguments, f.Args, vars)
 			if err != nil {
 				return nil, err
 			}
 
-			directives, err := parseDirectives(schema, "FIELD", selection.Directives, vars)
+			directives, err := parseDirectives(schema, "FIELD", selection.Directives, vars, bindings)
 			if err != nil {
 				return nil, err
 			}
@@ -250,20 +221,25 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 			}
 			var selectionSet *internal.SelectionSet
 			if namedType != nil && selection.SelectionSet != nil {
-				selectionSet, err = parseSelectionSet(schema, namedType, selection.SelectionSet, globalFragments, vars)
+				selectionSet, err = parseSelectionSet(schema, namedType, selection.SelectionSet, globalFragments, vars, bindings)
 				if err != nil {
 					return nil, err
 				}
 			}
 
-			selections = append(selections, &internal.Selection{
+			parsed := &internal.Selection{
 				Alias:        alias,
 				Name:         selection.Name.Name,
 				Args:         args,
 				SelectionSet: selectionSet,
 				Directives:   directives,
 				Loc:          selection.Loc,
-			})
+				Arguments:    selection.Arguments,
+			}
+			if bindings != nil && hasVariables(selection.Arguments) {
+				bindings.selections[parsed] = boundArguments{arguments: selection.Arguments, definitions: f.Args}
+			}
+			selections = append(selections, parsed)
 
 		case *ast.FragmentSpread:
 			name := selection.Name.Name
@@ -273,7 +249,7 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				return nil, errors.New("unknown fragment")
 			}
 
-			directives, err := parseDirectives(schema, "FRAGMENT_SPREAD", selection.Directives, vars)
+			directives, err := parseDirectives(schema, "FRAGMENT_SPREAD", selection.Directives, vars, bindings)
 			if err != nil {
 				return nil, err
 			}
@@ -292,21 +268,37 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				on = selection.TypeCondition.Name.Name
 			}
 
-			directives, err := parseDirectives(schema, "INLINE_FRAGMENT", selection.Directives, vars)
+			directives, err := parseDirectives(schema, "INLINE_FRAGMENT", selection.Directives, vars, bindings)
 			if err != nil {
 				return nil, err
 			}
 
-			selectionSet, err := parseSelectionSet(schema, t, selection.SelectionSet, globalFragments, vars)
+			condition := t
+			if on != "" {
+				typ, ok := schema.TypeMap[on]
+				if !ok {
+					return nil, printErr(selection.TypeCondition.Loc, "KnownTypeNames", "Unknown type %q.", on)
+				}
+				if !canBeFragment(typ) {
+					return nil, printErr(selection.TypeCondition.Loc, "FragmentsOnCompositeTypes", "Fragment cannot condition on non composite type %q.", on)
+				}
+				condition = typ
+			}
+			selectionSet, err := parseSelectionSet(schema, condition, selection.SelectionSet, globalFragments, vars, bindings)
 			if err != nil {
 				return nil, err
 			}
+			var union *internal.Union
+			if on != "" {
+				union, _ = condition.(*internal.Union)
+			}
 
 			fragments = append(fragments, &internal.FragmentSpread{
 				Fragment: &internal.FragmentDefinition{
 					On:           on,
 					SelectionSet: selectionSet,
 					Loc:          selection.Loc,
+					Union:        union,
 				},
 				Directives: directives,
 				Loc:        selection.Loc,
@@ -321,15 +313,20 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 	return selectionSet, nil
 }
 
-// argsToJson converts a graphql-go ast argument list to a json.Marshal-style map[string]interface{}
-func argsToJson(input []*ast.Argument, vars map[string]interface{}) (map[string]interface{}, error) {
+// argsToJson converts a graphql-go ast argument list to a json.Marshal-style map[string]interface{}, the literals
+// of the scalars having a ParseLiteral are parsed with it, see literalToJson.
+func argsToJson(input []*ast.Argument, definitions map[string]*internal.InputField, vars map[string]interface{}) (map[string]interface{}, error) {
 	args := make(map[string]interface{})
 	for _, arg := range input {
 		name := arg.Name.Name
 		if _, found := args[name]; found {
 			return nil, errors.New("duplicate arg")
 		}
-		value, err := internal.ValueToJson(arg.Value, vars)
+		var typ internal.Type
+		if definition := definitions[name]; definition != nil {
+			typ = definition.Type
+		}
+		value, err := literalToJson(arg.Value, typ, vars)
 		if err != nil {
 			return nil, err
 		}
@@ -338,6 +335,66 @@ func argsToJson(input []*ast.Argument, vars map[string]interface{}) (map[string]
 	return args, nil
 }
 
+// literalToJson converts value, a literal of the input type typ, like internal.ValueToJson, except for the
+// literals of the scalars having a ParseLiteral which are replaced with what it returns. The literals referencing
+// variables are left to ParseValue, as the values of the variables are.
+func literalToJson(value ast.Value, typ internal.Type, vars map[string]interface{}) (interface{}, error) {
+	switch typ := typ.(type) {
+	case *internal.NonNull:
+		return literalToJson(value, typ.Type, vars)
+	case *internal.Scalar:
+		if _, null := value.(*ast.NullValue); typ.ParseLiteral != nil && !null && !valueHasVariables(value) {
+			parsed, err := typ.ParseLiteral(value)
+			if err != nil {
+				return nil, printErr(value.Location(), "ValuesOfCorrectType", "Expected value of type %q: %s.", typ.Name, err)
+			}
+			return parsed, nil
+		}
+	case *internal.List:
+		list, ok := value.(*ast.ListValue)
+		if !ok {
+			// a single value is coerced to a list of one item by the resolvers
+			return literalToJson(value, typ.Type, vars)
+		}
+		values := make([]interface{}, 0, len(list.Values))
+		for _, item := range list.Values {
+			v, err := literalToJson(item, typ.Type, vars)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case *internal.InputObject:
+		object, ok := value.(*ast.ObjectValue)
+		if !ok {
+			break
+		}
+		fields := make(map[string]interface{}, len(object.Fields))
+		for _, field := range object.Fields {
+			name := field.Name.Name.Name
+			if _, found := fields[name]; found {
+				return nil, errors.New("duplicate field")
+			}
+			var fieldTyp internal.Type
+			if definition := typ.Fields[name]; definition != nil {
+				fieldTyp = definition.Type
+			}
+			v, err := literalToJson(field.Value, fieldTyp, vars)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = v
+		}
+		return fields, nil
+	}
+	v, err := internal.ValueToJson(value, vars)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 type visitState int
 
 const (
@@ -346,19 +403,25 @@ const (
 	visited
 )
 
-func parseDirectives(schema *internal.Schema, loc string, directives []*ast.Directive, vars map[string]interface{}) ([]*internal.Directive, error) {
+func parseDirectives(schema *internal.Schema, loc string, directives []*ast.Directive, vars map[string]interface{},
+	bindings *planBindings) ([]*internal.Directive, error) {
 	if err := validateDirectives(schema, loc, directives); err != nil {
 		return nil, err
 	}
 	d := make([]*internal.Directive, 0, len(directives))
 	for _, directive := range directives {
-		args, err := argsToJson(directive.Args, vars)
+		// copy the definition, the same directive can be used with different arguments in a document
+		dir := *schema.Directives[directive.Name.Name]
+		args, err := argsToJson(directive.Args, dir.Args, vars)
 		if err != nil {
 			return nil, err
 		}
-		dir := schema.Directives[directive.Name.Name]
 		dir.ArgVals = args
-		d = append(d, dir)
+		dir.Loc = directive.Loc
+		if bindings != nil && hasVariables(directive.Args) {
+			bindings.directives[&dir] = boundArguments{arguments: directive.Args, definitions: dir.Args}
+		}
+		d = append(d, &dir)
 	}
 	return d, nil
 }
@@ -492,133 +555,10 @@ func detectConflicts(selectionSet *internal.SelectionSet) error {
 //     groups: { name name id { widgets { name } } }
 //
 // Flatten does _not_ flatten out the inner queries, so the name above does not
-// get flattened out yet.
+// get flattened out yet. The type conditions of the fragments are ignored, the
+// executor collects the fields of each object with only the fragments applying to it.
 func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error) {
-	grouped := make(map[string][]*internal.Selection)
-
-	state := make(map[*internal.SelectionSet]visitState)
-	var visit func(*internal.SelectionSet) error
-	visit = func(selectionSet *internal.SelectionSet) error {
-		if state[selectionSet] == visited {
-			return nil
-		}
-
-		for _, selection := range selectionSet.Selections {
-			grouped[selection.Alias] = append(grouped[selection.Alias], selection)
-		}
-		for _, fragment := range selectionSet.Fragments {
-			if ok, err := shouldIncludeNode(fragment.Directives); err != nil {
-				return err
-
-			} else if !ok {
-				continue
-
-			}
-			if err := visit(fragment.Fragment.SelectionSet); err != nil {
-				return err
-			}
-		}
-
-		state[selectionSet] = visited
-		return nil
-	}
-
-	if err := visit(selectionSet); err != nil {
-		return nil, err
-	}
-
-	var flattened []*internal.Selection
-	for _, selections := range grouped {
-		if len(selections) == 1 || selections[0].SelectionSet == nil {
-			flattened = append(flattened, selections[0])
-			continue
-		}
-
-		merged := &internal.SelectionSet{}
-		for _, selection := range selections {
-			merged.Selections = append(merged.Selections, selection.SelectionSet.Selections...)
-			merged.Fragments = append(merged.Fragments, selection.SelectionSet.Fragments...)
-		}
-
-		flattened = append(flattened, &internal.Selection{
-			Name:         selections[0].Name,
-			Alias:        selections[0].Alias,
-			Args:         selections[0].Args,
-			SelectionSet: merged,
-			Loc:          selections[0].Loc,
-		})
-	}
-
-	return flattened, nil
-}
-
-func validateValue(v *ast.VariableDefinition, val interface{}, vtyp internal.Type, names ...string) error {
-	name := v.Var.Name.Name
-	if len(names) > 0 {
-		name = names[0]
-	}
-	switch vtyp := vtyp.(type) {
-	case *internal.NonNull:
-		if val == nil {
-			return printErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value null.\nExpected type \"%s\", found null.", name, vtyp.String())
-		}
-		return validateValue(v, val, vtyp.Type)
-	case *internal.List:
-		if val == nil {
-			return nil
-		}
-		vv, ok := val.([]interface{})
-		if !ok {
-			return validateValue(v, val, vtyp.Type)
-		}
-		for index, vi := range vv {
-			if err := validateValue(v, vi, vtyp.Type, fmt.Sprintf("%s[%d]", v.Var.Name.Name, index)); err != nil {
-				return err
-			}
-		}
-	case *internal.Enum:
-		if val == nil {
-			return nil
-		}
-		e, ok := val.(string)
-		if !ok {
-			return printErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %v.", name, val, vtyp, val)
-		}
-		for _, option := range vtyp.Values {
-			if option == e {
-				return nil
-			}
-		}
-		return printErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %s.\nExpected type \"%s\", found %s.", name, e, vtyp.String(), e)
-	case *internal.Scalar:
-
-	case *internal.InputObject:
-		if val == nil {
-			return nil
-		}
-		in, ok := val.(map[string]interface{})
-		if !ok {
-			return printErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %s.", name, val, vtyp, val)
-		}
-		for argName, arg := range in {
-			if f, ok := vtyp.Fields[argName]; !ok {
-				return printErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" got invalid value %v; Field %q is not defined by type %q", name, val, argName, vtyp.Name)
-			} else {
-				if err := validateValue(v, arg, f.Type, f.Name); err != nil {
-					return err
-				}
-			}
-
-		}
-		for fname, f := range vtyp.Fields {
-			if _, ok := in[fname]; !ok {
-				if err := validateValue(v, nil, f.Type, fname); err != nil {
-					return err
-				}
-			}
-		}
-	}
-	return nil
+	return collectFields(nil, nil, selectionSet)
 }
 
 func unwrapType(t internal.Type) (internal.NamedType, error) {
@@ -0,0 +1,88 @@
+package execution
+
+import (
+	"context"
+	"time"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Redacted is the value of the redacted variables of the slow queries.
+const Redacted = "[REDACTED]"
+
+// SlowQuery is an operation which took longer than the SlowQueryThreshold of its executor, along with the time
+// spent in each of its stages, see Stages. The time spent outside of the stages, eg. by the interceptors, is part
+// of Duration only.
+type SlowQuery struct {
+	// Query is the normalized query of the operation, see internal.NormalizeQuery.
+	Query         string
+	OperationName string
+	// Variables are the variables of the operation, redacted by the SlowQueryRedact of the executor.
+	Variables map[string]interface{}
+	Errors    errors.MultiError
+	Duration  time.Duration
+	Parse     time.Duration
+	Validate  time.Duration
+	Plan      time.Duration
+	Execute   time.Duration
+}
+
+// slowQueryLog returns the stages measuring their duration into query, and the function reporting query once the
+// operation is done, when the slow queries are logged.
+func (e *Executor) slowQueryLog(stages Stages, op *Operation) (Stages, func(ctx context.Context, response *Response)) {
+	if e.SlowQueryThreshold <= 0 || e.SlowQueryLog == nil {
+		return stages, func(context.Context, *Response) {}
+	}
+	c := e.clock()
+	start := c.Now()
+	query := &SlowQuery{}
+	measure := func(d *time.Duration) func() {
+		start := c.Now()
+		return func() {
+			*d = c.Since(start)
+		}
+	}
+	timed := stages
+	timed.Parse = func(ctx context.Context, op *Operation) errors.MultiError {
+		defer measure(&query.Parse)()
+		return stages.Parse(ctx, op)
+	}
+	timed.Validate = func(ctx context.Context, schema *internal.Schema, op *Operation) errors.MultiError {
+		defer measure(&query.Validate)()
+		return stages.Validate(ctx, schema, op)
+	}
+	timed.Plan = func(ctx context.Context, schema *internal.Schema, op *Operation) error {
+		defer measure(&query.Plan)()
+		return stages.Plan(ctx, schema, op)
+	}
+	timed.Execute = func(ctx context.Context, schema *internal.Schema, op *Operation) (interface{}, errors.MultiError) {
+		defer measure(&query.Execute)()
+		return stages.Execute(ctx, schema, op)
+	}
+	return timed, func(ctx context.Context, response *Response) {
+		if query.Duration = c.Since(start); query.Duration < e.SlowQueryThreshold {
+			return
+		}
+		query.Query = internal.NormalizeQuery(op.Query)
+		query.OperationName = op.OperationName
+		query.Variables = e.redactVariables(op.Variables)
+		query.Errors = response.Errors
+		e.SlowQueryLog(ctx, query)
+	}
+}
+
+func (e *Executor) redactVariables(variables map[string]interface{}) map[string]interface{} {
+	if variables == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		if e.SlowQueryRedact != nil {
+			redacted[name] = e.SlowQueryRedact(name, value)
+		} else {
+			redacted[name] = Redacted
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,182 @@
+package execution
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+func init() {
+	// arguments and JSON like resolver results are made of these
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Snapshot is the record of one request: the operation and everything its resolvers received and returned.
+// Snapshots are encoded with encoding/gob, so the concrete types returned by resolvers must be registered
+// with gob.Register before a snapshot is written or read.
+type Snapshot struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	// Resolves are keyed by the response path of the field, eg. "hero.friends.0.name".
+	Resolves map[string]*ResolveRecord
+}
+
+// ResolveRecord is one resolver call.
+type ResolveRecord struct {
+	Args   interface{}
+	Result interface{}
+	Err    string
+}
+
+// Recorder captures the resolver calls of the executions running with a context returned by WithRecorder.
+type Recorder struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{snapshot: Snapshot{Resolves: make(map[string]*ResolveRecord)}}
+}
+
+type recorderKey struct{}
+
+type replayerKey struct{}
+
+// WithRecorder returns a context which makes Do and Executor.Execute record every resolver call into r.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+func (r *Recorder) setRequest(param Params) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snapshot.Query = param.Query
+	r.snapshot.OperationName = param.OperationName
+	r.snapshot.Variables = param.Variables
+}
+
+func (r *Recorder) record(path string, args, result interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record := &ResolveRecord{Args: args, Result: result}
+	if err != nil {
+		record.Err = err.Error()
+	}
+	r.snapshot.Resolves[path] = record
+}
+
+// Snapshot returns what has been recorded so far.
+func (r *Recorder) Snapshot() *Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := r.snapshot
+	snapshot.Resolves = make(map[string]*ResolveRecord, len(r.snapshot.Resolves))
+	for path, record := range r.snapshot.Resolves {
+		snapshot.Resolves[path] = record
+	}
+	return &snapshot
+}
+
+// WriteFile writes the recorded snapshot into the named file.
+func (r *Recorder) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := r.Snapshot().Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *Snapshot) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s)
+}
+
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func ReadSnapshotFile(name string) (*Snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
+
+// Replay executes the recorded operation again, but the resolvers of the schema are not called:
+// every field gets the result which was recorded for its path.
+// Result completion (serializing scalars and enums, type resolution, non-null checks) runs as usual.
+// The errors are not masked, see Executor.UnmaskedErrors.
+func Replay(schema *internal.Schema, s *Snapshot) (interface{}, errors.MultiError) {
+	return (&Executor{UnmaskedErrors: true}).Do(schema, Params{
+		Query:         s.Query,
+		OperationName: s.OperationName,
+		Variables:     s.Variables,
+		Context:       context.WithValue(context.Background(), replayerKey{}, s),
+	})
+}
+
+type replayError string
+
+func (e replayError) Error() string {
+	return string(e)
+}
+
+func pathKey(path []interface{}) string {
+	keys := make([]string, len(path))
+	for i, p := range path {
+		keys[i] = fmt.Sprint(p)
+	}
+	return strings.Join(keys, ".")
+}
+
+// fieldResolve returns the resolver of field to be called at the current path,
+// which records or replays the calls according to the context.
+func (e *Executor) fieldResolve(ctx *exeContext, field *internal.Field) internal.FieldResolve {
+	if s, ok := ctx.Value(replayerKey{}).(*Snapshot); ok {
+		path := pathKey(ctx.path)
+		return func(context.Context, interface{}, interface{}) (interface{}, error) {
+			record, ok := s.Resolves[path]
+			if !ok {
+				return nil, fmt.Errorf("no result recorded for %s", path)
+			}
+			if record.Err != "" {
+				return record.Result, replayError(record.Err)
+			}
+			return record.Result, nil
+		}
+	}
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		path := pathKey(ctx.path)
+		return func(c context.Context, source, args interface{}) (interface{}, error) {
+			result, err := field.Resolve(c, source, args)
+			if thunk, ok := result.(internal.Thunk); ok && err == nil {
+				return internal.Thunk(func() (interface{}, error) {
+					result, err := thunk()
+					r.record(path, args, result, err)
+					return result, err
+				}), nil
+			}
+			r.record(path, args, result, err)
+			return result, err
+		}
+	}
+	return field.Resolve
+}
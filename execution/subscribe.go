@@ -0,0 +1,244 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Subscribe runs the subscription of doc with a zero Executor, see Executor.Subscribe.
+func Subscribe(ctx context.Context, schema *internal.Schema, doc *internal.Document, vars map[string]interface{}) (<-chan *Response, error) {
+	return (&Executor{}).Subscribe(ctx, schema, doc, "", vars)
+}
+
+// Subscribe validates the subscription operation of doc with the rules of the ValidationCache and resolves its root field to the stream of
+// its events: the resolver of a subscription field returns a schemabuilder.SourceStream or a receive channel, eg.
+//
+//	s.Subscription().FieldFunc("messages", func(ctx context.Context, args struct{ Room string }) <-chan *Message {...})
+//
+// Every event of the stream is executed against the selection set of the subscription, with the event
+// as the value of the root field, and sent as a response on the returned channel; the events dropped by the
+// Filter of the field are skipped. The channel is closed once the stream completes or ctx is done; a stream
+// ending with an error sends a last response with that error first. The stream is closed when the subscription ends.
+func (e *Executor) Subscribe(ctx context.Context, schema *internal.Schema, doc *internal.Document, operationName string,
+	vars map[string]interface{}) (<-chan *Response, error) {
+	// the query of doc is not known, its validation is not cached
+	typ, selectionSet, err := applySelectionSet(schema, doc, operationName, vars, e.validationCache().ValidateDocument)
+	if err != nil {
+		return nil, err
+	}
+	if typ != ast.Subscription {
+		return nil, errors.New("operation is not a subscription")
+	}
+	if err := CheckDepth(selectionSet, e.MaxDepth); err != nil {
+		return nil, err
+	}
+	root := schema.Subscription.(*internal.Object)
+	plan := newFieldPlan()
+	planned, err := plan.collect(root, nil, selectionSet)
+	if err != nil {
+		return nil, err
+	}
+	if len(planned.selections) != 1 {
+		return nil, errors.New("subscription must select only one top level field")
+	}
+	selection, field := planned.selections[0], planned.definitions[0]
+	if field == nil {
+		return nil, errors.New("subscription field %s does not exist", selection.Name)
+	}
+
+	stream, err := e.createSourceEventStream(ctx, field, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	// the events are the values of the root field, which resolves to its source
+	eventField := *field
+	eventField.Resolve = func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		return source, nil
+	}
+	eventRoot := *root
+	eventRoot.Fields = map[string]*internal.Field{selection.Name: &eventField}
+
+	responses := make(chan *Response, e.SubscriptionBuffer)
+	go func() {
+		defer close(responses)
+		defer stream.Close()
+		for {
+			event, err := e.nextEvent(ctx, stream)
+			if err == io.EOF || ctx.Err() != nil {
+				return
+			}
+			if err == nil && field.Filter != nil {
+				var keep bool
+				if keep, err = e.filterEvent(ctx, field, event, selection.Args); err == nil && !keep {
+					continue
+				}
+			}
+			response := &Response{}
+			if err != nil {
+				graphqlErr := errors.FromError(err)
+				graphqlErr.Locations = []errors.Location{selection.Loc}
+				graphqlErr.Path = []interface{}{selection.Alias}
+				response.Errors = errors.MultiError{graphqlErr}
+			} else {
+				response.Data, response.Errors = e.executePlan(ctx, &eventRoot, event, selectionSet, plan)
+			}
+			if !e.sendResponse(ctx, responses, response) || err != nil {
+				return
+			}
+		}
+	}()
+	return responses, nil
+}
+
+// OverflowPolicy tells what to do with the response of a subscription whose buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block waits for the subscriber to receive a response before taking the next event.
+	Block OverflowPolicy = iota
+	// DropOldest drops the oldest buffered response to make room for the new one.
+	DropOldest
+	// DropNewest drops the new response.
+	DropNewest
+	// CloseWithError ends the subscription with a last response reporting ErrSubscriptionOverflow, which
+	// takes the place of the oldest buffered response.
+	CloseWithError
+)
+
+// ErrSubscriptionOverflow ends the subscriptions which overflow with the CloseWithError policy.
+var ErrSubscriptionOverflow = errors.New("subscription buffer overflow: the subscriber is too slow")
+
+// sendResponse sends response to the subscriber according to the overflow policy of the executor,
+// it returns false when the subscription must end.
+func (e *Executor) sendResponse(ctx context.Context, responses chan *Response, response *Response) bool {
+	response.Errors = e.presentErrors(ctx, response.Errors)
+	return sendWithOverflow(ctx, e.SubscriptionOverflow, responses, response, func() *Response {
+		return &Response{Errors: e.presentErrors(ctx, errors.MultiError{ErrSubscriptionOverflow})}
+	})
+}
+
+// sendWithOverflow sends response on responses according to policy, overflow returning the last response of the
+// subscriptions closed with CloseWithError. It returns false when the subscription must end.
+func sendWithOverflow(ctx context.Context, policy OverflowPolicy, responses chan *Response, response *Response,
+	overflow func() *Response) bool {
+	switch policy {
+	case DropOldest:
+		sendDroppingOldest(responses, response)
+		return true
+	case DropNewest:
+		select {
+		case responses <- response:
+		default:
+		}
+		return true
+	case CloseWithError:
+		select {
+		case responses <- response:
+			return true
+		default:
+			sendDroppingOldest(responses, overflow())
+			return false
+		}
+	default:
+		select {
+		case responses <- response:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// sendDroppingOldest sends response, dropping the oldest buffered responses until there is room for it.
+func sendDroppingOldest(responses chan *Response, response *Response) {
+	for {
+		select {
+		case responses <- response:
+			return
+		default:
+		}
+		if cap(responses) == 0 {
+			// nothing to drop, the subscriber is not receiving
+			return
+		}
+		// the subscriber may have received the oldest response meanwhile
+		select {
+		case <-responses:
+		default:
+		}
+	}
+}
+
+// createSourceEventStream calls the resolver of the subscription field, which returns the stream.
+func (e *Executor) createSourceEventStream(ctx context.Context, field *internal.Field, selection *internal.Selection) (stream internal.SourceStream, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = e.recoverPanic(ctx, panicErr, stack())
+		}
+	}()
+	result, err := field.Resolve(ctx, nil, selection.Args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("subscription field %s resolved to a nil stream", field.Name)
+	}
+	if stream, ok := result.(internal.SourceStream); ok {
+		return stream, nil
+	}
+	events := reflect.ValueOf(result)
+	if events.Kind() != reflect.Chan || events.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, fmt.Errorf("subscription field %s should resolve to a source stream or a receivable channel, not %T", field.Name, result)
+	}
+	if events.IsNil() {
+		return nil, fmt.Errorf("subscription field %s resolved to a nil stream", field.Name)
+	}
+	return chanStream{events: events}, nil
+}
+
+func (e *Executor) nextEvent(ctx context.Context, stream internal.SourceStream) (event interface{}, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = e.recoverPanic(ctx, panicErr, stack())
+		}
+	}()
+	return stream.Next(ctx)
+}
+
+// filterEvent tells whether the subscriber wants event, according to the filter of the subscription field.
+func (e *Executor) filterEvent(ctx context.Context, field *internal.Field, event, args interface{}) (keep bool, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = e.recoverPanic(ctx, panicErr, stack())
+		}
+	}()
+	return field.Filter(ctx, event, args), nil
+}
+
+// chanStream is the stream of a receive channel, of any element type.
+type chanStream struct {
+	events reflect.Value
+}
+
+func (s chanStream) Next(ctx context.Context) (interface{}, error) {
+	chosen, event, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: s.events},
+	})
+	if chosen == 0 {
+		return nil, ctx.Err()
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+	return event.Interface(), nil
+}
+
+func (s chanStream) Close() {}
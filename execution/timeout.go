@@ -0,0 +1,45 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/internal"
+)
+
+type resolved struct {
+	value interface{}
+	err   error
+}
+
+// resolveWithTimeout calls resolve in its own goroutine and waits for it until timeout elapses
+// or ctx is done, whichever comes first. Only the fields with a timeout are resolved so. A resolver which is given up on keeps running
+// with a cancelled context, its result is dropped.
+func (e *Executor) resolveWithTimeout(ctx context.Context, timeout time.Duration, resolve internal.FieldResolve,
+	source, args interface{}) (interface{}, error) {
+	resolveCtx, cancel := clock.WithTimeout(ctx, e.clock(), timeout)
+	defer cancel()
+
+	done := make(chan resolved, 1)
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				done <- resolved{err: e.recoverPanic(resolveCtx, panicErr, stack())}
+			}
+		}()
+		value, err := resolve(resolveCtx, source, args)
+		done <- resolved{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-resolveCtx.Done():
+		if ctx.Err() == nil {
+			return nil, fmt.Errorf("resolver timed out after %s: %w", timeout, resolveCtx.Err())
+		}
+		return nil, fmt.Errorf("resolver aborted: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,188 @@
+package execution
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Trace records the resolution tree of the operations executed with a context returned by WithTrace: which
+// fields were resolved, in which order, with which arguments and for how long. It is safe for concurrent use.
+type Trace struct {
+	clock clock.Clock
+	start time.Time
+
+	mu        sync.Mutex
+	end       time.Time
+	resolvers []*ResolverTrace
+}
+
+// ResolverTrace is one resolver call of a trace.
+type ResolverTrace struct {
+	Path       []interface{} `json:"path"`
+	ParentType string        `json:"parentType"`
+	FieldName  string        `json:"fieldName"`
+	ReturnType string        `json:"returnType"`
+	Args       interface{}   `json:"args,omitempty"`
+	// StartOffset is the start of the call since the start of the trace.
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+	// Deferred tells that the call is the one of the thunk returned by the resolver, which is called along with the
+	// other thunks of its round once the fields of the round have been resolved, eg. to batch the loads.
+	Deferred bool   `json:"deferred,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NewTrace returns a trace starting now, measured with c, clock.Real when nil.
+func NewTrace(c clock.Clock) *Trace {
+	if c == nil {
+		c = clock.Real
+	}
+	return &Trace{clock: c, start: c.Now()}
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context which makes the operations executed with it record their resolvers into t.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFrom returns the trace of ctx, nil when the resolvers are not traced.
+func TraceFrom(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// Tracing returns the interceptor tracing the operations run by an executor, report receives their trace once
+// they have been executed:
+//
+//	executor.Interceptors = append(executor.Interceptors, execution.Tracing(func(ctx context.Context, op *execution.Operation, trace *execution.Trace) {
+//		if trace.Duration() > time.Second {
+//			log.Printf("slow operation %s: %+v", op.OperationName, trace.Resolvers())
+//		}
+//	}))
+//
+// The operations executed with a context traced already keep their trace.
+func Tracing(report func(ctx context.Context, op *Operation, trace *Trace)) *Interceptor {
+	return &Interceptor{
+		BeforeExecution: func(ctx context.Context, op *Operation) (context.Context, error) {
+			if TraceFrom(ctx) != nil {
+				return ctx, nil
+			}
+			return WithTrace(ctx, NewTrace(nil)), nil
+		},
+		// the context is the one of BeforeExecution once the operation is executed
+		AfterExecution: func(ctx context.Context, op *Operation, response *Response) {
+			if t := TraceFrom(ctx); t != nil {
+				t.finish()
+				report(ctx, op, t)
+			}
+		},
+	}
+}
+
+func (t *Trace) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.end = t.clock.Now()
+}
+
+// Duration returns the duration of the trace, until the operation has been executed or until now.
+func (t *Trace) Duration() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.end.IsZero() {
+		return t.clock.Since(t.start)
+	}
+	return t.end.Sub(t.start)
+}
+
+// Resolvers returns the resolver calls recorded so far, in the order they started.
+func (t *Trace) Resolvers() []*ResolverTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*ResolverTrace(nil), t.resolvers...)
+}
+
+// Extension returns the trace as the "tracing" extension of the responses, in the format of Apollo Tracing with
+// the arguments of the resolvers and their errors. The durations are in nanoseconds.
+func (t *Trace) Extension() map[string]interface{} {
+	resolvers := t.Resolvers()
+	encoded := make([]map[string]interface{}, len(resolvers))
+	for i, r := range resolvers {
+		encoded[i] = map[string]interface{}{
+			"path":        r.Path,
+			"parentType":  r.ParentType,
+			"fieldName":   r.FieldName,
+			"returnType":  r.ReturnType,
+			"startOffset": r.StartOffset.Nanoseconds(),
+			"duration":    r.Duration.Nanoseconds(),
+		}
+		if r.Args != nil {
+			encoded[i]["args"] = r.Args
+		}
+		if r.Deferred {
+			encoded[i]["deferred"] = true
+		}
+		if r.Error != "" {
+			encoded[i]["error"] = r.Error
+		}
+	}
+	duration := t.Duration()
+	return map[string]interface{}{
+		"version":   1,
+		"startTime": t.start.UTC().Format(time.RFC3339Nano),
+		"endTime":   t.start.Add(duration).UTC().Format(time.RFC3339Nano),
+		"duration":  duration.Nanoseconds(),
+		"execution": map[string]interface{}{"resolvers": encoded},
+	}
+}
+
+func (t *Trace) record(r *ResolverTrace, start time.Time, err error) {
+	r.StartOffset = start.Sub(t.start)
+	r.Duration = t.clock.Since(start)
+	if err != nil {
+		r.Error = err.Error()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resolvers = append(t.resolvers, r)
+}
+
+// traceResolve returns resolve recording its calls at the current path into the trace of ctx, along with the
+// calls of the thunks it returns.
+func traceResolve(ctx *exeContext, typ *internal.Object, field *internal.Field, resolve internal.FieldResolve) internal.FieldResolve {
+	t := TraceFrom(ctx)
+	if t == nil {
+		return resolve
+	}
+	path := append([]interface{}(nil), ctx.path...)
+	return func(c context.Context, source, args interface{}) (interface{}, error) {
+		newTrace := func(deferred bool) *ResolverTrace {
+			return &ResolverTrace{
+				Path:       path,
+				ParentType: typ.Name,
+				FieldName:  field.Name,
+				ReturnType: field.Type.String(),
+				Args:       args,
+				Deferred:   deferred,
+			}
+		}
+		start := t.clock.Now()
+		result, err := resolve(c, source, args)
+		t.record(newTrace(false), start, err)
+		if thunk, ok := result.(internal.Thunk); ok && err == nil {
+			return internal.Thunk(func() (interface{}, error) {
+				start := t.clock.Now()
+				result, err := thunk()
+				t.record(newTrace(true), start, err)
+				return result, err
+			}), nil
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,405 @@
+package execution
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/utils"
+)
+
+// coerceVariableValues checks the provided variables against the variable definitions of an operation.
+// Default values are applied to the variables which are not provided, variables of unknown types and
+// missing non-null variables and provided values which are not defined by the operation are rejected, and every
+// provided value is coerced according to its input type.
+//
+// Coerced values keep the shape of argument literals (input objects are maps), so the arguments resolvers of the
+// schema can decode variables and literals the same way. Integers are kept exact as int64 rather than float64, the
+// values of the custom scalars are the ones provided.
+func coerceVariableValues(schema *internal.Schema, definitions []*ast.VariableDefinition, inputs map[string]interface{}) (
+	map[string]interface{}, errors.MultiError) {
+	var errs errors.MultiError
+	coerced := make(map[string]interface{}, len(definitions))
+	defined := make(map[string]struct{}, len(definitions))
+	for _, v := range definitions {
+		variableName := v.Var.Name.Name
+		if _, ok := defined[variableName]; ok {
+			errs = append(errs, printErr(v.Loc, "Variable Uniqueness", "duplicate variable name %s", variableName).(*errors.GraphQLError))
+			continue
+		}
+		defined[variableName] = struct{}{}
+
+		vTyp, err := utils.TypeFromAst(schema, v.Type)
+		if err != nil {
+			errs = append(errs, printErr(v.Loc, "ValuesOfCorrectType", err.Error()).(*errors.GraphQLError))
+			continue
+		}
+		if vTyp == nil {
+			errs = append(errs, printErr(v.Loc, "KnownTypeNames", "Unknown type %q.", v.Type.String()).(*errors.GraphQLError))
+			continue
+		}
+		if !internal.IsInputType(vTyp) {
+			errs = append(errs, printErr(v.Loc, "Variables Are Input Types", `Variable "$%s" cannot be non-input type "%s".`, variableName, v.Type.String()).(*errors.GraphQLError))
+			continue
+		}
+
+		value, provided := inputs[variableName]
+		if !provided && v.DefaultValue != nil {
+			value, err := internal.ValueToJson(v.DefaultValue, nil)
+			if err != nil {
+				errs = append(errs, printErr(v.Loc, "DefaultValuesOfCorrectType", err.Error()).(*errors.GraphQLError))
+				continue
+			}
+			value, coerceErrs := coerceValue(v, value, vTyp, []interface{}{variableName})
+			if len(coerceErrs) > 0 {
+				for _, err := range coerceErrs {
+					err.Rule = "DefaultValuesOfCorrectType"
+				}
+				errs = append(errs, coerceErrs...)
+				continue
+			}
+			coerced[variableName] = value
+			continue
+		}
+		if !provided {
+			if nonNull, ok := vTyp.(*internal.NonNull); ok {
+				errs = append(errs, invalidNull(v, []interface{}{variableName}, nonNull))
+			}
+			continue
+		}
+		value, coerceErrs := coerceValue(v, value, vTyp, []interface{}{variableName})
+		if len(coerceErrs) > 0 {
+			errs = append(errs, coerceErrs...)
+			continue
+		}
+		coerced[variableName] = value
+	}
+
+	var unknown []string
+	for variableName := range inputs {
+		if _, ok := defined[variableName]; !ok {
+			unknown = append(unknown, variableName)
+		}
+	}
+	sort.Strings(unknown)
+	for _, variableName := range unknown {
+		errs = append(errs, &errors.GraphQLError{
+			Message:    fmt.Sprintf("Variable \"$%s\" is not defined by the operation.", variableName),
+			Rule:       "VariablesOfCorrectType",
+			Extensions: map[string]interface{}{"code": errors.CodeBadUserInput, "inputPath": "variables." + variableName},
+		})
+	}
+
+	return coerced, errs
+}
+
+func invalidNull(v *ast.VariableDefinition, path []interface{}, typ internal.Type) *errors.GraphQLError {
+	return invalidVariable(v, path, typ, "Variable \"%s\" has invalid value null.\nExpected type \"%s\", found null.", inputPath(path), typ.String())
+}
+
+// invalidVariable reports the part of a variable value at path which can not be coerced to typ. The path and
+// the expected type are added to the extensions, so the clients can tie the error to the input which caused it.
+func invalidVariable(v *ast.VariableDefinition, path []interface{}, typ internal.Type, format string, a ...interface{}) *errors.GraphQLError {
+	err := printErr(v.Loc, "VariablesOfCorrectType", format, a...).(*errors.GraphQLError)
+	err.Extensions = map[string]interface{}{"code": errors.CodeBadUserInput, "inputPath": "variables." + inputPath(path)}
+	if typ != nil {
+		err.Extensions["expectedType"] = typ.String()
+	}
+	return err
+}
+
+// inputPath prints the path of a part of a variable value, eg. input.addresses[2].zip.
+func inputPath(path []interface{}) string {
+	var b strings.Builder
+	for i, key := range path {
+		switch key := key.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", key)
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, key)
+		}
+	}
+	return b.String()
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	return append(path[:len(path):len(path)], key)
+}
+
+// coerceValue coerces a variable value, or the part of it at path, according to typ.
+func coerceValue(v *ast.VariableDefinition, value interface{}, typ internal.Type, path []interface{}) (interface{}, errors.MultiError) {
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		if value == nil {
+			return nil, errors.MultiError{invalidNull(v, path, nonNull)}
+		}
+		return coerceValue(v, value, nonNull.Type, path)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	name := inputPath(path)
+	invalid := func() errors.MultiError {
+		return errors.MultiError{invalidVariable(v, path, typ, "Variable \"%s\" has invalid value %v.\nExpected type \"%s\", found %v.", name, value, typ, value)}
+	}
+
+	switch typ := typ.(type) {
+	case *internal.List:
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			// a single value is accepted as a list of one item
+			item, errs := coerceValue(v, value, typ.Type, path)
+			if len(errs) > 0 {
+				return nil, errs
+			}
+			return []interface{}{item}, nil
+		}
+		var errs errors.MultiError
+		items := make([]interface{}, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			item, itemErrs := coerceValue(v, list.Index(i).Interface(), typ.Type, appendPath(path, i))
+			errs = append(errs, itemErrs...)
+			items[i] = item
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		return items, nil
+	case *internal.Enum:
+		e, ok := value.(string)
+		if !ok {
+			return nil, errors.MultiError{invalidVariable(v, path, typ, "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %v.", name, value, typ, value)}
+		}
+		for _, option := range typ.Values {
+			if option == e {
+				return e, nil
+			}
+		}
+		return nil, invalid()
+	case *internal.Scalar:
+		value, ok := coerceScalar(typ, value)
+		if !ok {
+			return nil, invalid()
+		}
+		return value, nil
+	case *internal.InputObject:
+		in, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.MultiError{invalidVariable(v, path, typ, "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %s.", name, value, typ, value)}
+		}
+		fieldNames := make([]string, 0, len(typ.Fields))
+		for fieldName := range typ.Fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+
+		var errs errors.MultiError
+		var unknown []string
+		for fieldName := range in {
+			if _, ok := typ.Fields[fieldName]; !ok {
+				unknown = append(unknown, fieldName)
+			}
+		}
+		sort.Strings(unknown)
+		for _, fieldName := range unknown {
+			errs = append(errs, invalidVariable(v, appendPath(path, fieldName), nil, "Variable \"%s\" got invalid value %v; Field %q is not defined by type %q", name, value, fieldName, typ.Name))
+		}
+
+		if typ.OneOf {
+			if len(in) != 1 {
+				errs = append(errs, invalidVariable(v, path, typ, "Variable \"%s\" got invalid value %v; OneOf Input Object %q must specify exactly one key.", name, value, typ.Name))
+			}
+			for fieldName, fieldValue := range in {
+				if _, ok := typ.Fields[fieldName]; ok && fieldValue == nil && len(in) == 1 {
+					errs = append(errs, invalidVariable(v, appendPath(path, fieldName), typ.Fields[fieldName].Type, "Variable \"%s\" got invalid value %v; Field \"%s.%s\" must be non-null.", name, value, typ.Name, fieldName))
+				}
+			}
+		}
+
+		object := make(map[string]interface{}, len(in))
+		for _, fieldName := range fieldNames {
+			field := typ.Fields[fieldName]
+			fieldValue, ok := in[fieldName]
+			if !ok {
+				// the default value of the field is applied when the arguments are decoded
+				if _, nonNull := field.Type.(*internal.NonNull); nonNull && field.DefaultValue == nil {
+					errs = append(errs, invalidNull(v, appendPath(path, fieldName), field.Type))
+				}
+				continue
+			}
+			fieldValue, fieldErrs := coerceValue(v, fieldValue, field.Type, appendPath(path, fieldName))
+			errs = append(errs, fieldErrs...)
+			object[fieldName] = fieldValue
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		return object, nil
+	}
+	return value, nil
+}
+
+// coerceScalar checks value against the built-in scalars, other scalars are checked with their ParseValue and
+// keep the value provided. The numbers of the built-in scalars are turned into int64 when they are integers and into
+// float64 otherwise, so the integers decoded from JSON with UseNumber, or provided as Go integers, stay exact.
+func coerceScalar(typ *internal.Scalar, value interface{}) (interface{}, bool) {
+	switch typ.Name {
+	case "Int":
+		if number, ok := toInt64(value); ok && number <= math.MaxInt32 && number >= math.MinInt32 {
+			return number, true
+		}
+	case "Float":
+		if number, ok := toFloat64(value); ok {
+			return number, true
+		}
+	case "String":
+		if _, ok := value.(string); ok {
+			return value, true
+		}
+	case "Boolean":
+		if _, ok := value.(bool); ok {
+			return value, true
+		}
+	case "ID":
+		if _, ok := value.(string); ok {
+			return value, true
+		}
+		if number, ok := toInt64(value); ok {
+			return number, true
+		}
+		// the integers beyond int64 are kept as they are
+		if number, ok := value.(json.Number); ok && isInteger(string(number)) {
+			return value, true
+		}
+	default:
+		if typ.ParseValue != nil {
+			if _, err := typ.ParseValue(value); err != nil {
+				return nil, false
+			}
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+// toInt64 returns the integer of value, a number with no fractional part which fits in an int64.
+func toInt64(value interface{}) (int64, bool) {
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return i, true
+		}
+		f, err := number.Float64()
+		if err != nil {
+			return 0, false
+		}
+		value = f
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// isInteger reports whether number, a JSON number, has no fraction nor exponent.
+func isInteger(number string) bool {
+	return !strings.ContainsAny(number, ".eE")
+}
+
+// inputError is the error of an input object rejected by its validator, reported at the value of the input object
+// in the document, see locateInputError.
+type inputError struct {
+	error
+	location   errors.Location
+	extensions map[string]interface{}
+}
+
+func (e *inputError) Unwrap() error {
+	return e.error
+}
+
+// locateInputError locates err when it is the one of an input object rejected by its validator, whose path is
+// relative to the arguments of selection: at the variable holding the input object, along with its path in the
+// variables, or at its literal in the document. The other errors and the input objects of default values are left
+// at the field.
+func locateInputError(selection *internal.Selection, err error) error {
+	var validationErr *schemabuilder.InputValidationError
+	if !stderrors.As(err, &validationErr) || len(validationErr.Path) == 0 {
+		return err
+	}
+	var value ast.Value
+	for _, argument := range selection.Arguments {
+		if argument.Name.Name == validationErr.Path[0] {
+			value = argument.Value
+		}
+	}
+	located := &inputError{error: err, extensions: map[string]interface{}{"code": errors.CodeBadUserInput}}
+	for path := validationErr.Path[1:]; value != nil; path = path[1:] {
+		if variable, ok := value.(*ast.Variable); ok {
+			located.location = variable.Loc
+			located.extensions["inputPath"] = "variables." + inputPath(append([]interface{}{variable.Name.Name}, path...))
+			return located
+		}
+		if len(path) == 0 {
+			located.location = value.Location()
+			return located
+		}
+		next := value
+		value = nil
+		if i, ok := path[0].(int); ok {
+			if list, ok := next.(*ast.ListValue); ok && i < len(list.Values) {
+				value = list.Values[i]
+			} else if !ok && i == 0 {
+				// a single value is coerced to a list of it
+				value = next
+			}
+		} else if object, ok := next.(*ast.ObjectValue); ok {
+			for _, field := range object.Fields {
+				if field.Name.Name.Name == path[0] {
+					value = field.Value
+				}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Warning is a non-fatal problem of an operation, such as a deprecation notice or a truncated result.
+// Unlike errors, warnings do not affect data and are sent under extensions.warnings of the response.
+type Warning struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Warnings collects the warnings of an operation, it is safe for concurrent use.
+type Warnings struct {
+	mu   sync.Mutex
+	list []*Warning
+}
+
+func (w *Warnings) Add(warning *Warning) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, warning)
+}
+
+// List returns the collected warnings in the order they have been added.
+func (w *Warnings) List() []*Warning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]*Warning(nil), w.list...)
+}
+
+type warningsKey struct{}
+
+// WithWarnings returns a context collecting the warnings of the operation executed with it into w.
+func WithWarnings(ctx context.Context, w *Warnings) context.Context {
+	return context.WithValue(ctx, warningsKey{}, w)
+}
+
+// Warn adds a warning to the operation of ctx, it does nothing when the warnings are not collected.
+func Warn(ctx context.Context, format string, a ...interface{}) {
+	AddWarning(ctx, &Warning{Message: fmt.Sprintf(format, a...)})
+}
+
+// AddWarning is like Warn, for warnings with a path or extensions.
+func AddWarning(ctx context.Context, warning *Warning) {
+	if w, ok := ctx.Value(warningsKey{}).(*Warnings); ok {
+		w.Add(warning)
+	}
+}
@@ -454,9 +454,9 @@ func NewPlaner(schema *SchemaWithFederationInfo) (*Planner, error) {
 }
 
 func MustPlan(planner *Planner, param execution.Params) (*Plan, error) {
-	doc, err := internal.Parse(param.Query)
-	if err != nil {
-		return nil, err
+	doc, parseErrs := internal.Parse(param.Query)
+	if parseErrs != nil {
+		return nil, parseErrs
 	}
 	//errs := validation.Validate(planner.schema.Schema, doc, param.Variables, 50)
 	//if len(errs) > 0 {
@@ -0,0 +1,349 @@
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	"sort"
+	"strings"
+)
+
+// LinkURL is the federation 2 specification which the printed subgraph schemas link with @link.
+const LinkURL = "https://specs.apollo.dev/federation/v2.3"
+
+const (
+	linkSpec       = "https://specs.apollo.dev/federation/"
+	linkNamespace  = "federation"
+	extensionKey   = "federation"
+	fieldSetScalar = "FieldSet"
+)
+
+// Directive is a federation directive applied to an object or to a field of a subgraph schema.
+type Directive struct {
+	// Name is the name of the directive in the federation specification, without @
+	Name string
+	// Args are the arguments of the directive by name
+	Args map[string]interface{}
+}
+
+var (
+	// Shareable marks an object or a field which several subgraphs resolve.
+	Shareable = Directive{Name: "shareable"}
+	// Inaccessible hides an object or a field from the API of the supergraph, the subgraphs still using it.
+	Inaccessible = Directive{Name: "inaccessible"}
+	// InterfaceObject marks an object standing for an interface of the supergraph which the subgraph does not
+	// know, to add fields to all its implementations.
+	InterfaceObject = Directive{Name: "interfaceObject"}
+	// External marks a field which the subgraph does not resolve but needs for @key, @requires or @provides.
+	External = Directive{Name: "external"}
+)
+
+// Key makes an object an entity identified by fields, which is a selection set as "id" or "id organization { id }".
+func Key(fields string) Directive {
+	return Directive{Name: "key", Args: map[string]interface{}{"fields": fields}}
+}
+
+// Override moves the resolution of a field from the subgraph from to the subgraph declaring it.
+func Override(from string) Directive {
+	return Directive{Name: "override", Args: map[string]interface{}{"from": from}}
+}
+
+// ObjectDirectives applies directives to object, they are printed by PrintSubgraphSchema.
+func ObjectDirectives(object *schemabuilder.Object, directives ...Directive) {
+	if object.Extensions == nil {
+		object.Extensions = make(map[string]interface{})
+	}
+	applied, _ := object.Extensions[extensionKey].([]Directive)
+	object.Extensions[extensionKey] = append(applied, directives...)
+}
+
+// FieldDirectives is the field option applying directives to a field:
+//
+//	user.FieldFunc("email", fn, federation.FieldDirectives(federation.Shareable))
+func FieldDirectives(directives ...Directive) interface{} {
+	return schemabuilder.Extension(extensionKey, directives)
+}
+
+// specifiedDirectives are the definitions of the directives of the federation 2 specification, the names of
+// the directives and of the FieldSet scalar being formatted with the names they have in the schema.
+var specifiedDirectives = map[string]string{
+	"key":             `directive @%[1]s(fields: %[2]s!, resolvable: Boolean = true) repeatable on OBJECT | INTERFACE`,
+	"requires":        `directive @%[1]s(fields: %[2]s!) on FIELD_DEFINITION`,
+	"provides":        `directive @%[1]s(fields: %[2]s!) on FIELD_DEFINITION`,
+	"external":        `directive @%[1]s on OBJECT | FIELD_DEFINITION`,
+	"extends":         `directive @%[1]s on OBJECT | INTERFACE`,
+	"shareable":       `directive @%[1]s repeatable on OBJECT | FIELD_DEFINITION`,
+	"override":        `directive @%[1]s(from: String!) on FIELD_DEFINITION`,
+	"interfaceObject": `directive @%[1]s on OBJECT`,
+	"inaccessible": `directive @%[1]s on FIELD_DEFINITION | OBJECT | INTERFACE | UNION | ARGUMENT_DEFINITION | SCALAR | ` +
+		`ENUM | ENUM_VALUE | INPUT_OBJECT | INPUT_FIELD_DEFINITION`,
+}
+
+// federation1Directives are the directives of the subgraphs which do not link the federation 2 specification.
+var federation1Directives = []string{"key", "requires", "provides", "external", "extends"}
+
+const linkDefinitions = `
+directive @link(url: String!, as: String, import: [link__Import], for: link__Purpose) repeatable on SCHEMA
+scalar link__Import
+enum link__Purpose { SECURITY EXECUTION }`
+
+// PrintSubgraphSchema prints schema in the schema definition language along with the federation directives
+// applied with ObjectDirectives and FieldDirectives, the schema being extended with the @link to LinkURL
+// importing them.
+func PrintSubgraphSchema(schema *internal.Schema) string {
+	used := make(map[string]bool)
+	collect := func(extensions map[string]interface{}) {
+		directives, _ := extensions[extensionKey].([]Directive)
+		for _, d := range directives {
+			used["@"+d.Name] = true
+		}
+	}
+	for _, typ := range schema.TypeMap {
+		if object, ok := typ.(*internal.Object); ok {
+			collect(object.Extensions)
+			for _, field := range object.Fields {
+				collect(field.Extensions)
+			}
+		}
+	}
+	imports := make([]string, 0, len(used))
+	for name := range used {
+		imports = append(imports, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(imports)
+
+	link := fmt.Sprintf("extend schema @link(url: %q, import: [%s])\n\n", LinkURL, strings.Join(imports, ", "))
+	return link + introspection.PrintSchema(schema, introspection.PrintDirectives(printDirectives))
+}
+
+func printDirectives(extensions map[string]interface{}) string {
+	directives, _ := extensions[extensionKey].([]Directive)
+	var s strings.Builder
+	for _, d := range directives {
+		s.WriteString(" @" + d.Name)
+		if len(d.Args) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(d.Args))
+		for name := range d.Args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		args := make([]string, len(names))
+		for i, name := range names {
+			value, _ := json.Marshal(d.Args[name])
+			args[i] = name + ": " + string(value)
+		}
+		s.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	return s.String()
+}
+
+// Subgraph is the federation metadata of a subgraph schema.
+type Subgraph struct {
+	// Types are the directives applied to the objects and to the interfaces, by type name
+	Types map[string][]Directive
+	// Fields are the directives applied to the fields, by type name and field name
+	Fields map[string]map[string][]Directive
+}
+
+// ParseSubgraphSchema parses and validates the schema of a subgraph written in the schema definition language,
+// and returns the federation directives applied to its objects, interfaces and fields.
+//
+// The subgraphs of federation 2 link the specification with @link, the directives which are not imported
+// being prefixed by the namespace of the link, eg. @federation__shareable. The others know the directives of
+// federation 1 only, without prefix. The definitions of the directives may be omitted.
+func ParseSubgraphSchema(sdl string) (*Subgraph, error) {
+	doc, err := internal.ParseDocument(sdl)
+	if err != nil {
+		return nil, err
+	}
+	names, fieldSet, linkErr := linkedNames(doc)
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	defined := make(map[string]bool)
+	for _, definition := range doc.Definition {
+		switch d := definition.(type) {
+		case *ast.DirectiveDefinition:
+			defined["@"+d.Name.Name] = true
+		case *ast.ScalarDefinition:
+			defined[d.Name.Name] = true
+		case *ast.EnumDefinition:
+			defined[d.Name.Name] = true
+		}
+	}
+	var definitions []string
+	for name, spec := range names {
+		if !defined["@"+name] {
+			definitions = append(definitions, fmt.Sprintf(specifiedDirectives[spec], name, fieldSet))
+		}
+	}
+	if !defined[fieldSet] {
+		definitions = append(definitions, "scalar "+fieldSet)
+	}
+	if !defined["@link"] && !defined["link__Import"] && !defined["link__Purpose"] {
+		definitions = append(definitions, linkDefinitions)
+	}
+	specified, err := internal.ParseDocument(strings.Join(definitions, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	full := &ast.Document{Definition: append(append([]ast.Definition(nil), doc.Definition...), specified.Definition...)}
+	if errs := validation.ValidateSDL(full); len(errs) > 0 {
+		return nil, errs
+	}
+
+	subgraph := &Subgraph{Types: make(map[string][]Directive), Fields: make(map[string]map[string][]Directive)}
+	add := func(typ *ast.Name, directives []*ast.Directive, fields []*ast.FieldDefinition) {
+		if applied := appliedDirectives(directives, names); len(applied) > 0 {
+			subgraph.Types[typ.Name] = append(subgraph.Types[typ.Name], applied...)
+		}
+		for _, field := range fields {
+			applied := appliedDirectives(field.Directives, names)
+			if len(applied) == 0 {
+				continue
+			}
+			if subgraph.Fields[typ.Name] == nil {
+				subgraph.Fields[typ.Name] = make(map[string][]Directive)
+			}
+			subgraph.Fields[typ.Name][field.Name.Name] = append(subgraph.Fields[typ.Name][field.Name.Name], applied...)
+		}
+	}
+	for _, definition := range doc.Definition {
+		switch d := definition.(type) {
+		case *ast.ObjectDefinition:
+			add(d.Name, d.Directives, d.Fields)
+		case *ast.ObjectExtension:
+			add(d.Name, d.Directives, d.Fields)
+		case *ast.InterfaceDefinition:
+			add(d.Name, d.Directives, d.Fields)
+		case *ast.InterfaceExtension:
+			add(d.Name, d.Directives, d.Fields)
+		}
+	}
+	return subgraph, nil
+}
+
+// linkedNames returns the specified directives by their name in the schema of doc, and the name of the FieldSet
+// scalar, from the @link of the federation specification.
+func linkedNames(doc *ast.Document) (map[string]string, string, error) {
+	var link *ast.Directive
+	for _, definition := range doc.Definition {
+		var directives []*ast.Directive
+		switch d := definition.(type) {
+		case *ast.SchemaDefinition:
+			directives = d.Directives
+		case *ast.SchemaExtension:
+			directives = d.Directives
+		}
+		for _, d := range directives {
+			if url, ok := argument(d, "url").(string); d.Name.Name == "link" && ok && strings.HasPrefix(url, linkSpec) {
+				link = d
+			}
+		}
+	}
+	names := make(map[string]string)
+	if link == nil {
+		for _, name := range federation1Directives {
+			names[name] = name
+		}
+		return names, "_" + fieldSetScalar, nil
+	}
+	url := argument(link, "url").(string)
+	if !strings.HasPrefix(url, linkSpec+"v2.") {
+		return nil, "", fmt.Errorf("unsupported federation specification %s", url)
+	}
+
+	namespace := linkNamespace
+	if as, ok := argument(link, "as").(string); ok {
+		namespace = as
+	}
+	// the elements which are not imported are prefixed by the namespace
+	for spec := range specifiedDirectives {
+		names[namespace+"__"+spec] = spec
+	}
+	fieldSet := namespace + "__" + fieldSetScalar
+
+	imports, _ := argument(link, "import").([]interface{})
+	for _, i := range imports {
+		var name, as string
+		switch i := i.(type) {
+		case string:
+			name, as = i, i
+		case map[string]interface{}:
+			name, _ = i["name"].(string)
+			as, _ = i["as"].(string)
+			if as == "" {
+				as = name
+			}
+		}
+		if name == fieldSetScalar {
+			fieldSet = as
+			continue
+		}
+		spec := strings.TrimPrefix(name, "@")
+		if _, ok := specifiedDirectives[spec]; !ok || spec == name || !strings.HasPrefix(as, "@") {
+			return nil, "", fmt.Errorf("unknown import %s of %s", name, url)
+		}
+		delete(names, namespace+"__"+spec)
+		names[strings.TrimPrefix(as, "@")] = spec
+	}
+	return names, fieldSet, nil
+}
+
+// appliedDirectives returns the federation directives among directives, by their name in the specification.
+func appliedDirectives(directives []*ast.Directive, names map[string]string) []Directive {
+	var applied []Directive
+	for _, d := range directives {
+		spec, ok := names[d.Name.Name]
+		if !ok {
+			continue
+		}
+		directive := Directive{Name: spec}
+		for _, arg := range d.Args {
+			if directive.Args == nil {
+				directive.Args = make(map[string]interface{})
+			}
+			directive.Args[arg.Name.Name] = valueOf(arg.Value)
+		}
+		applied = append(applied, directive)
+	}
+	return applied
+}
+
+func argument(d *ast.Directive, name string) interface{} {
+	for _, arg := range d.Args {
+		if arg.Name.Name == name {
+			return valueOf(arg.Value)
+		}
+	}
+	return nil
+}
+
+// valueOf returns the Go value of a literal: lists are []interface{}, objects map[string]interface{}, enums
+// their name and the numbers their text.
+func valueOf(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.ListValue:
+		values := make([]interface{}, len(value.Values))
+		for i, v := range value.Values {
+			values[i] = valueOf(v)
+		}
+		return values
+	case *ast.ObjectValue:
+		fields := make(map[string]interface{}, len(value.Fields))
+		for _, field := range value.Fields {
+			fields[field.Name.Name.Name] = valueOf(field.Value)
+		}
+		return fields
+	case *ast.NullValue:
+		return nil
+	}
+	return value.GetValue()
+}
@@ -0,0 +1,92 @@
+package federation_test
+
+import (
+	"github.com/shyptr/graphql/federation"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Account struct {
+	ID    int    `graphql:"id"`
+	Email string `graphql:"email"`
+}
+
+func TestPrintSubgraphSchema(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	account := build.Object("Account", Account{})
+	federation.ObjectDirectives(account, federation.Key("id"), federation.Shareable)
+	account.FieldFunc("plan", func(a Account) string { return "free" },
+		federation.FieldDirectives(federation.Override("billing"), federation.Inaccessible))
+	build.Query().FieldFunc("me", func() *Account { return nil })
+	sdl := federation.PrintSubgraphSchema(build.MustBuild())
+
+	assert.Equal(t, `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@inaccessible", "@key", "@override", "@shareable"])
+
+type Account @key(fields: "id") @shareable {
+  email: String!
+  id: Int!
+  plan: String! @override(from: "billing") @inaccessible
+}
+
+type Query {
+  me: Account
+}
+`, sdl)
+
+	subgraph, err := federation.ParseSubgraphSchema(sdl)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]federation.Directive{
+		"Account": {federation.Key("id"), federation.Shareable},
+	}, subgraph.Types)
+	assert.Equal(t, map[string]map[string][]federation.Directive{
+		"Account": {"plan": {federation.Override("billing"), federation.Inaccessible}},
+	}, subgraph.Fields)
+}
+
+func TestParseSubgraphSchema(t *testing.T) {
+	t.Run("resolves the namespaced and renamed imports", func(t *testing.T) {
+		subgraph, err := federation.ParseSubgraphSchema(`
+			extend schema @link(url: "https://specs.apollo.dev/federation/v2.0", as: "fed",
+				import: [{name: "@key", as: "@id"}, "@interfaceObject"])
+
+			type Media @id(fields: "id") @interfaceObject {
+				id: ID!
+				title: String @fed__shareable
+			}
+
+			type Query {
+				media: [Media]
+			}
+		`)
+		assert.NoError(t, err)
+		assert.Equal(t, []federation.Directive{federation.Key("id"), federation.InterfaceObject}, subgraph.Types["Media"])
+		assert.Equal(t, []federation.Directive{federation.Shareable}, subgraph.Fields["Media"]["title"])
+	})
+
+	t.Run("knows the federation 1 directives without link", func(t *testing.T) {
+		subgraph, err := federation.ParseSubgraphSchema(`
+			type User @key(fields: "id") {
+				id: ID! @external
+			}
+		`)
+		assert.NoError(t, err)
+		assert.Equal(t, []federation.Directive{federation.External}, subgraph.Fields["User"]["id"])
+
+		_, err = federation.ParseSubgraphSchema(`type User @shareable { id: ID! }`)
+		assert.EqualError(t, err, `[graphql: Unknown directive "shareable". (1:11)]`)
+	})
+
+	t.Run("rejects the directives which are not imported", func(t *testing.T) {
+		_, err := federation.ParseSubgraphSchema(`
+			extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key"])
+			type User @key(fields: "id") @shareable { id: ID! }
+		`)
+		assert.Error(t, err)
+		_, err = federation.ParseSubgraphSchema(`
+			extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@provide"])
+			type User { id: ID! }
+		`)
+		assert.EqualError(t, err, "unknown import @provide of https://specs.apollo.dev/federation/v2.3")
+	})
+}
@@ -0,0 +1,32 @@
+// Package format prints the GraphQL documents, the executable ones and the SDL, in their canonical layout.
+package format
+
+import (
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Format returns src, a document, printed by ast.Print: indented by two spaces, the definitions separated by a blank
+// line and the descriptions as block strings on the lines before what they describe. Formatting a formatted
+// document returns it as is.
+//
+// The comments are not part of the AST, so the documents with comments are not formatted rather than losing them:
+// the error reports the location of the first one, like the syntax errors. A blank src is formatted as empty.
+func Format(src string) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	doc, errs := internal.ParseDocument(src)
+	if errs != nil {
+		return "", errs
+	}
+	if loc, ok := internal.FindComment(src); ok {
+		err := errors.New("Cannot format the comments, which are not kept by the formatter.")
+		err.Locations = []errors.Location{loc}
+		return "", err
+	}
+	return ast.Print(doc), nil
+}
@@ -0,0 +1,137 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name, src, formatted string
+	}{
+		{
+			name: "query",
+			src: `query Hero($episode: Episode = JEDI, $withFriends: Boolean!) @live { hero(episode: $episode) {
+name, ... on Droid { primaryFunction } friends @include(if: $withFriends) { n: name ...Names } } }
+fragment Names on Character { name list(of: [1, 2.5, "a\"b", {x: null, y: true}]) }
+{ a }`,
+			formatted: `query Hero($episode: Episode = JEDI, $withFriends: Boolean!) @live {
+  hero(episode: $episode) {
+    name
+    ... on Droid {
+      primaryFunction
+    }
+    friends @include(if: $withFriends) {
+      n: name
+      ...Names
+    }
+  }
+}
+
+fragment Names on Character {
+  name
+  list(of: [1, 2.5, "a\"b", {x: null, y: true}])
+}
+
+{
+  a
+}
+`,
+		},
+		{
+			name: "schema",
+			src: `schema @link { query: Query mutation: Mutation }
+"the root"
+type Query implements Node & Entity @key(fields: "id") { id: ID!
+"""
+the users
+  of the page
+"""
+users(
+"how many" first: Int = 10, after: String): [User!]! @deprecated(reason: "use search") }
+directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+enum Status { ACTIVE """can not sign in""" BANNED @deprecated }
+input Filter @oneOf { status: Status name: String }
+union Result = User | Page
+scalar Time @specifiedBy(url: "https://example.com")
+extend type User @shareable { email: String }`,
+			formatted: `schema @link {
+  query: Query
+  mutation: Mutation
+}
+
+"""the root"""
+type Query implements Node & Entity @key(fields: "id") {
+  id: ID!
+
+  """
+  the users
+    of the page
+  """
+  users(
+    """how many"""
+    first: Int = 10
+    after: String
+  ): [User!]! @deprecated(reason: "use search")
+}
+
+directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+
+enum Status {
+  ACTIVE
+
+  """can not sign in"""
+  BANNED @deprecated
+}
+
+input Filter @oneOf {
+  status: Status
+  name: String
+}
+
+union Result = User | Page
+
+scalar Time @specifiedBy(url: "https://example.com")
+
+extend type User @shareable {
+  email: String
+}
+`,
+		},
+		{
+			name:      "escaped description",
+			src:       `"a \"quoted\"\nline" scalar Time`,
+			formatted: "\"\"\"\na \"quoted\"\nline\n\"\"\"\nscalar Time\n",
+		},
+		{
+			name:      "blank",
+			src:       " \n",
+			formatted: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formatted, err := format.Format(test.src)
+			assert.NoError(t, err)
+			assert.Equal(t, test.formatted, formatted)
+
+			again, err := format.Format(formatted)
+			assert.NoError(t, err)
+			assert.Equal(t, formatted, again)
+		})
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	_, err := format.Format("type Query {\n  a: Int # the a\n}")
+	assert.Equal(t, &errors.GraphQLError{
+		Message:   "Cannot format the comments, which are not kept by the formatter.",
+		Locations: []errors.Location{{Line: 2, Column: 10}},
+	}, err)
+
+	_, err = format.Format("type Query {")
+	assert.Error(t, err)
+}
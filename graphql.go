@@ -1,11 +1,12 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
 	"net/http"
 )
 
@@ -14,9 +15,18 @@ func Use(mm ...HandlerFunc) {
 }
 
 type Handler struct {
-	Schema   *internal.Schema
-	Executor *execution.Executor
-	ctx      *Context
+	Schema *internal.Schema
+	// SchemaHolder, when set, provides the schema of each request instead of Schema
+	SchemaHolder *schemabuilder.SchemaHolder
+	Executor     *execution.Executor
+	ctx          *Context
+}
+
+func (h *Handler) schema() *internal.Schema {
+	if h.SchemaHolder != nil {
+		return h.SchemaHolder.Schema()
+	}
+	return h.Schema
 }
 
 // Resp represents a typical response of a GraphQL server. It may be encoded to JSON directly or
@@ -38,6 +48,15 @@ func HTTPHandler(schema *internal.Schema) http.Handler {
 	return h
 }
 
+// HolderHandler is like HTTPHandler for a schema which is rebuilt at runtime, every request
+// is executed with the schema which is current when it arrives.
+func HolderHandler(holder *schemabuilder.SchemaHolder) http.Handler {
+	return &Handler{
+		SchemaHolder: holder,
+		Executor:     &execution.Executor{},
+	}
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := *Ctx
 	ctx.Writer, ctx.Request = &Resp{ResponseWriter: w}, r
@@ -62,6 +81,8 @@ func execute(handler *Handler) HandlerFunc {
 			return
 		}
 		ctx.OperationName = param.OperationName
+		warnings := &execution.Warnings{}
+		ctx.Request = ctx.Request.WithContext(execution.WithWarnings(ctx.Request.Context(), warnings))
 		var execute interface{}
 		var exeErr errors.MultiError
 		defer func() {
@@ -69,6 +90,9 @@ func execute(handler *Handler) HandlerFunc {
 				Data:   execute,
 				Errors: exeErr,
 			}
+			if list := warnings.List(); len(list) > 0 {
+				res.Extensions = map[string]interface{}{"warnings": list}
+			}
 			if len(exeErr) > 0 {
 				ctx.Error = append(ctx.Error, exeErr...)
 			}
@@ -81,26 +105,29 @@ func execute(handler *Handler) HandlerFunc {
 			ctx.Writer.Header().Set("Content-Type", "application/json")
 			ctx.Writer.Write(responseJSON)
 		}()
-		doc, parseErr := internal.Parse(param.Query)
-		if parseErr != nil {
-			exeErr = []*errors.GraphQLError{parseErr.(*errors.GraphQLError)}
-			return
-		}
 		//exeErr = validation.Validate(handler.Schema, doc, param.Variables, ctx.MaxDepth)
 		//if len(exeErr) > 0 {
 		//	return
 		//}
 
-		operationType, selectionSet, applyErr := execution.ApplySelectionSet(handler.Schema, doc, param.OperationName, param.Variables)
-		if applyErr != nil {
-			exeErr = []*errors.GraphQLError{applyErr.(*errors.GraphQLError)}
-			return
-		}
-		ctx.Method = operationType
-		root := handler.Schema.Query
-		if operationType == ast.Mutation {
-			root = handler.Schema.Mutation
+		executor := handler.Executor
+		if executor.Clock == nil {
+			copied := *executor
+			copied.Clock = ctx.Clock
+			executor = &copied
 		}
-		execute, exeErr = handler.Executor.Execute(ctx, root, nil, selectionSet)
+		_, response := executor.Run(handler.schema(), param, &execution.Interceptor{
+			AfterValidation: func(c context.Context, op *execution.Operation) error {
+				if err := execution.CheckIntrospection(c, op.SelectionSet, ctx.Introspection); err != nil {
+					return err
+				}
+				return execution.CheckDepth(op.SelectionSet, ctx.MaxDepth)
+			},
+			BeforeExecution: func(c context.Context, op *execution.Operation) (context.Context, error) {
+				ctx.Method = op.Type
+				return c, nil
+			},
+		})
+		execute, exeErr = response.Data, response.Errors
 	}
 }
@@ -0,0 +1,586 @@
+// Package handler serves a schema over HTTP, following the GraphQL over HTTP specification,
+// https://graphql.github.io/graphql-over-http/draft/:
+//
+//	http.Handle("/graphql", handler.New(schema))
+//
+// The operations are POSTed as JSON, or sent with GET in the query string as
+// /graphql?query=...&variables=...&operationName=..., the variables and the extensions being encoded in JSON.
+// GET runs the queries only, so that they may be cached. A batch of operations may be POSTed at once as a JSON
+// array, which gets the array of their responses, see MaxBatchSize.
+//
+// The responses are written as application/json with the status 200 once the request is well-formed, the errors of
+// the operation being in the response. The clients accepting application/graphql-response+json get it instead,
+// with the status 400 when the operation is not executed, eg. when it is invalid. The responses are compressed
+// with gzip or deflate when the client accepts it. The unexpected errors of the resolvers are masked, see
+// ErrorLogger.
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Request is the body of a request, an operation along with its variables. The operation is sent as a query
+// text, or as the id of a trusted document, see TrustedDocuments.
+type Request struct {
+	Query         string                 `json:"query"`
+	DocumentID    string                 `json:"documentId"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+// Response is the body of a response. Data is absent when the operation is not executed, eg. when it is invalid,
+// and null when its execution fails.
+type Response struct {
+	Errors     errors.MultiError      `json:"errors,omitempty"`
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// executed is whether the operation is executed, data being its data when it is streamed rather than marshaled
+	executed bool
+	data     interface{}
+}
+
+// Option configures the handler returned by New.
+type Option func(*handler)
+
+// Executor runs the operations with executor, eg. to set its interceptors or its limits, instead of a zero
+// execution.Executor. The internal errors are masked by the handler whatever the ErrorPresenter and the
+// UnmaskedErrors of executor, the handler runs a copy of executor unmasking them, see ErrorLogger.
+func Executor(executor *execution.Executor) Option {
+	return func(h *handler) {
+		h.executor = executor
+	}
+}
+
+// BatchConcurrency runs up to n operations of a batch at once, the operations of a batch being run one after the
+// other by default.
+func BatchConcurrency(n int) Option {
+	return func(h *handler) {
+		h.batchConcurrency = n
+	}
+}
+
+// DefaultMaxBatchSize is the number of operations of a batch at most, unless set with MaxBatchSize.
+const DefaultMaxBatchSize = 10
+
+// MaxBatchSize rejects the batches of more than n operations with the status 400, before running any of them. Zero
+// accepts the batches of any size.
+func MaxBatchSize(n int) Option {
+	return func(h *handler) {
+		h.maxBatchSize = n
+	}
+}
+
+// MaxBodyBytes rejects the requests whose body is larger than n bytes with the status 413, before parsing them.
+func MaxBodyBytes(n int64) Option {
+	return func(h *handler) {
+		h.maxBodyBytes = n
+	}
+}
+
+// ReadTimeout rejects the requests whose body is not read within timeout with the status 408, eg. the ones sent
+// slowly to hold the connections. Unlike the ReadTimeout of http.Server, it applies to the GraphQL endpoint only.
+func ReadTimeout(timeout time.Duration) Option {
+	return func(h *handler) {
+		h.readTimeout = timeout
+	}
+}
+
+// TrustedDocuments only runs the trusted documents of manifest, the query texts by their document id, rejecting
+// the requests sent with a query text. This prevents the clients of a public API from running arbitrary
+// operations. The id of a document is sent as documentId, or as the sha256Hash of the persistedQuery extension of
+// Apollo clients.
+func TrustedDocuments(manifest map[string]string) Option {
+	return func(h *handler) {
+		h.trustedDocuments = manifest
+	}
+}
+
+// ErrorLogger receives the internal errors masked in the responses, instead of the standard logger, see
+// execution.MaskInternalErrors.
+func ErrorLogger(log func(ctx context.Context, err *errors.GraphQLError)) Option {
+	return func(h *handler) {
+		h.errorPresenter = execution.MaskInternalErrors(log)
+	}
+}
+
+// Unmarshal replaces the stage reading the requests, json.Unmarshal with UseNumber by default, which decodes the
+// JSON requests and their variables and extensions, the integers of the variables staying exact. The parse,
+// validate, plan and execute stages are the ones of the executor, see execution.Stages.
+func Unmarshal(unmarshal func(data []byte, v interface{}) error) Option {
+	return func(h *handler) {
+		h.unmarshal = unmarshal
+	}
+}
+
+// unmarshalJSON is json.Unmarshal decoding the numbers as json.Number.
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return stderrors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// Marshal replaces the stage serializing the responses, which by default streams their data to the client as it
+// is encoded, see execution.WriteJSON, the output being the one of json.Marshal.
+func Marshal(marshal func(v interface{}) ([]byte, error)) Option {
+	return func(h *handler) {
+		h.marshal = marshal
+	}
+}
+
+// Tracing sends the trace of the resolvers of the operations under the "tracing" extension of their response, see
+// execution.Trace, for the requests which enabled accepts, eg. the ones of the developers sending a debug header.
+// The operations of every request are traced when enabled is nil.
+func Tracing(enabled func(r *http.Request) bool) Option {
+	return func(h *handler) {
+		h.tracing = enabled
+		if h.tracing == nil {
+			h.tracing = func(*http.Request) bool { return true }
+		}
+	}
+}
+
+// RequestID tells the id of the requests to the operations, see execution.RequestID. The id is the one of the
+// header if the request has it, a new one otherwise, and it is sent back in the header of the response.
+func RequestID(header string) Option {
+	return func(h *handler) {
+		h.requestIDHeader = header
+	}
+}
+
+// Rand generates the ids of the requests without one, see RequestID, instead of crypto/rand, eg. a seeded
+// clock.NewRand in tests.
+func Rand(r clock.Rand) Option {
+	return func(h *handler) {
+		h.rand = r
+	}
+}
+
+// UnmaskedErrors sends the internal errors as they are, eg. in development.
+func UnmaskedErrors() Option {
+	return func(h *handler) {
+		h.errorPresenter = nil
+	}
+}
+
+type handler struct {
+	schema           *internal.Schema
+	executor         *execution.Executor
+	batchConcurrency int
+	maxBatchSize     int
+	maxBodyBytes     int64
+	readTimeout      time.Duration
+	trustedDocuments map[string]string
+	errorPresenter   execution.ErrorPresenter
+	unmarshal        func(data []byte, v interface{}) error
+	marshal          func(v interface{}) ([]byte, error)
+	tracing          func(r *http.Request) bool
+	requestIDHeader  string
+	rand             clock.Rand
+}
+
+// New returns the handler executing the operations sent to it on schema.
+func New(schema *internal.Schema, options ...Option) http.Handler {
+	h := &handler{
+		schema:         schema,
+		executor:       &execution.Executor{},
+		errorPresenter: execution.MaskInternalErrors(nil),
+		unmarshal:      unmarshalJSON,
+		maxBatchSize:   DefaultMaxBatchSize,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	// the handler masks the internal errors itself, with its ErrorLogger
+	executor := *h.executor
+	executor.UnmaskedErrors = true
+	h.executor = &executor
+	return h
+}
+
+// the media types of the responses
+const (
+	applicationJSON            = "application/json"
+	applicationGraphQLResponse = "application/graphql-response+json"
+)
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mediaType := negotiate(r.Header.Get("Accept"), map[string]string{
+		applicationGraphQLResponse: applicationGraphQLResponse,
+		applicationJSON:            applicationJSON,
+		"application/*":            applicationJSON,
+		"*/*":                      applicationJSON,
+	}, applicationJSON)
+	if mediaType == "" {
+		h.writeError(w, r, http.StatusNotAcceptable, "unsupported accepted media types %q, expected %s or %s",
+			r.Header.Get("Accept"), applicationGraphQLResponse, applicationJSON)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	traced := h.tracing != nil && h.tracing(r)
+	if h.requestIDHeader != "" {
+		id := r.Header.Get(h.requestIDHeader)
+		if id == "" {
+			id = execution.NewRequestID(h.rand)
+		}
+		w.Header().Set(h.requestIDHeader, id)
+		r = r.WithContext(execution.WithRequestID(r.Context(), id))
+	}
+
+	var request Request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query, request.DocumentID = query.Get("query"), query.Get("documentId")
+		request.OperationName = query.Get("operationName")
+		for _, param := range []struct {
+			name  string
+			value *map[string]interface{}
+		}{{"variables", &request.Variables}, {"extensions", &request.Extensions}} {
+			if encoded := query.Get(param.name); encoded != "" {
+				if err := h.unmarshal([]byte(encoded), param.value); err != nil {
+					h.writeError(w, r, http.StatusBadRequest, "invalid %s: %v", param.name, err)
+					return
+				}
+			}
+		}
+	case http.MethodPost:
+		if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType != applicationJSON {
+			h.writeError(w, r, http.StatusUnsupportedMediaType, "unsupported content type %q, expected application/json",
+				r.Header.Get("Content-Type"))
+			return
+		}
+		body, ok := h.readBody(w, r)
+		if !ok {
+			return
+		}
+		if len(body) > 0 && body[0] == '[' {
+			var batch []Request
+			if err := h.unmarshal(body, &batch); err != nil {
+				h.writeError(w, r, http.StatusBadRequest, "invalid request body: %v", err)
+				return
+			}
+			if len(batch) == 0 {
+				h.writeError(w, r, http.StatusBadRequest, "empty batch")
+				return
+			}
+			if h.maxBatchSize > 0 && len(batch) > h.maxBatchSize {
+				h.writeError(w, r, http.StatusBadRequest, "batch of %d operations, the limit is %d", len(batch), h.maxBatchSize)
+				return
+			}
+			h.write(w, r, http.StatusOK, h.executeBatch(r.Context(), batch, traced))
+			return
+		}
+		if err := h.unmarshal(body, &request); err != nil {
+			h.writeError(w, r, http.StatusBadRequest, "invalid request body: %v", err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "GraphQL requests must be GET or POST")
+		return
+	}
+	status, response := h.execute(r.Context(), request, r.Method, traced)
+	if status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", http.MethodPost)
+	}
+	// application/graphql-response+json tells the request errors apart from the field errors by the status
+	if mediaType == applicationGraphQLResponse && status == http.StatusOK && !response.executed {
+		status = http.StatusBadRequest
+	}
+	h.write(w, r, status, response)
+}
+
+// readBody reads the JSON body of r within the limits of h, and writes the error response when it fails.
+func (h *handler) readBody(w http.ResponseWriter, r *http.Request) (json.RawMessage, bool) {
+	if h.maxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+	}
+	controller := http.NewResponseController(w)
+	if h.readTimeout > 0 {
+		// the deadline is not supported by every ResponseWriter, eg. by httptest.ResponseRecorder
+		controller.SetReadDeadline(time.Now().Add(h.readTimeout))
+	}
+	var body json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		// the deadline would cancel the context of the request while the operation runs, the connection being
+		// watched in the background once the body is read. It is kept on failure, the server discarding the rest
+		// of the body.
+		if h.readTimeout > 0 {
+			controller.SetReadDeadline(time.Time{})
+		}
+		return body, true
+	case stderrors.As(err, &tooLarge):
+		h.writeError(w, r, http.StatusRequestEntityTooLarge, "request body larger than %d bytes", tooLarge.Limit)
+	case stderrors.Is(err, os.ErrDeadlineExceeded):
+		h.writeError(w, r, http.StatusRequestTimeout, "request body not read within %v", h.readTimeout)
+	default:
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil, false
+}
+
+// execute runs request sent with method, and returns the status of its response. The response has the trace of
+// the operation when it is traced.
+func (h *handler) execute(ctx context.Context, request Request, method string, traced bool) (int, *Response) {
+	if h.trustedDocuments != nil {
+		if err := h.resolveDocument(&request); err != nil {
+			return http.StatusBadRequest, &Response{Errors: errors.MultiError{err}}
+		}
+	}
+	// the query of a persisted query may be known by its hash only
+	if request.Query == "" && request.Extensions["persistedQuery"] == nil {
+		return http.StatusBadRequest, &Response{Errors: errors.News("missing query")}
+	}
+	status := http.StatusOK
+	executed := false
+	var trace *execution.Trace
+	interceptors := []*execution.Interceptor{{
+		AfterValidation: func(ctx context.Context, op *execution.Operation) error {
+			if method == http.MethodGet && op.Type != ast.Query {
+				status = http.StatusMethodNotAllowed
+				return errors.New("Can only perform a %s operation from a POST request.", strings.ToLower(string(op.Type)))
+			}
+			return nil
+		},
+		// the interceptors of the executor have accepted the operation when this one runs
+		BeforeExecution: func(ctx context.Context, op *execution.Operation) (context.Context, error) {
+			executed = true
+			return ctx, nil
+		},
+	}}
+	if traced {
+		interceptors = append(interceptors, execution.Tracing(func(ctx context.Context, op *execution.Operation, t *execution.Trace) {
+			trace = t
+		}))
+	}
+	_, result := h.executor.Run(h.schema, execution.Params{
+		Query:         request.Query,
+		OperationName: request.OperationName,
+		Variables:     request.Variables,
+		Extensions:    request.Extensions,
+		Context:       ctx,
+	}, interceptors...)
+	response := &Response{Errors: result.Errors}
+	if h.errorPresenter != nil && len(result.Errors) > 0 {
+		response.Errors = make(errors.MultiError, len(result.Errors))
+		for i, err := range result.Errors {
+			if response.Errors[i] = h.errorPresenter(ctx, err); response.Errors[i] == nil {
+				response.Errors[i] = err
+			}
+		}
+	}
+	if executed && h.marshal != nil {
+		data, err := h.marshal(result.Data)
+		if err != nil {
+			return http.StatusInternalServerError, &Response{Errors: errors.News("%v", err)}
+		}
+		response.Data = data
+	}
+	response.executed, response.data = executed, result.Data
+	if trace != nil {
+		response.Extensions = map[string]interface{}{"tracing": trace.Extension()}
+	}
+	return status, response
+}
+
+// resolveDocument sets the query of request to the text of its trusted document.
+func (h *handler) resolveDocument(request *Request) *errors.GraphQLError {
+	if request.Query != "" {
+		return errors.New("only trusted documents are accepted, send the documentId of the operation")
+	}
+	id := request.DocumentID
+	if persisted, ok := request.Extensions["persistedQuery"].(map[string]interface{}); ok {
+		if hash, _ := persisted["sha256Hash"].(string); id == "" {
+			id = hash
+		}
+		// the document is resolved here, not by the persisted queries of the executor
+		extensions := make(map[string]interface{}, len(request.Extensions))
+		for key, value := range request.Extensions {
+			if key != "persistedQuery" {
+				extensions[key] = value
+			}
+		}
+		request.Extensions = extensions
+	}
+	if id == "" {
+		return errors.New("missing documentId")
+	}
+	query, ok := h.trustedDocuments[id]
+	if !ok {
+		return errors.New("unknown document %q", id)
+	}
+	request.Query = query
+	return nil
+}
+
+// executeBatch runs the operations of a batch, h.batchConcurrency at a time, and returns their responses in order.
+func (h *handler) executeBatch(ctx context.Context, batch []Request, traced bool) []*Response {
+	responses := make([]*Response, len(batch))
+	semaphore := make(chan struct{}, max(h.batchConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, request := range batch {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			_, responses[i] = h.execute(ctx, request, http.MethodPost, traced)
+		}()
+	}
+	wg.Wait()
+	return responses
+}
+
+func (h *handler) writeError(w http.ResponseWriter, r *http.Request, status int, format string, args ...interface{}) {
+	h.write(w, r, status, &Response{Errors: errors.News(format, args...)})
+}
+
+// write writes body, a Response or the responses of a batch, compressed with the encoding accepted by r if any.
+// The Content-Type is application/json unless it is already set.
+func (h *handler) write(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	var encoded []byte
+	if h.marshal != nil {
+		var err error
+		if encoded, err = h.marshal(body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", applicationJSON)
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	var compressed io.WriteCloser
+	encoding := negotiate(r.Header.Get("Accept-Encoding"), map[string]string{
+		"gzip": "gzip", "deflate": "deflate", "*": "gzip",
+	}, "")
+	switch encoding {
+	case "gzip":
+		compressed = gzip.NewWriter(w)
+	case "deflate":
+		// the deflate content coding is the zlib format
+		compressed = zlib.NewWriter(w)
+	}
+	var out io.Writer = w
+	if compressed != nil {
+		w.Header().Set("Content-Encoding", encoding)
+		out = compressed
+	}
+	w.WriteHeader(status)
+	if encoded != nil {
+		out.Write(encoded)
+	} else if err := writeBody(out, body); err != nil {
+		// the status is sent already, the response is aborted for the client not to take it for a complete one
+		panic(http.ErrAbortHandler)
+	}
+	if compressed != nil {
+		compressed.Close()
+	}
+}
+
+// writeBody writes body, a Response or the responses of a batch, as json.Marshal does.
+func writeBody(w io.Writer, body interface{}) error {
+	buffered := bufio.NewWriterSize(w, 32<<10)
+	switch body := body.(type) {
+	case *Response:
+		if err := body.writeJSON(buffered); err != nil {
+			return err
+		}
+	case []*Response:
+		buffered.WriteByte('[')
+		for i, response := range body {
+			if i > 0 {
+				buffered.WriteByte(',')
+			}
+			if err := response.writeJSON(buffered); err != nil {
+				return err
+			}
+		}
+		buffered.WriteByte(']')
+	}
+	return buffered.Flush()
+}
+
+// writeJSON writes the response, its data being streamed by execution.WriteJSON.
+func (response *Response) writeJSON(w *bufio.Writer) error {
+	w.WriteByte('{')
+	separator := ""
+	for _, field := range []struct {
+		name    string
+		present bool
+		value   interface{}
+	}{
+		{"errors", len(response.Errors) > 0, response.Errors},
+		{"data", response.executed, response.data},
+		{"extensions", len(response.Extensions) > 0, response.Extensions},
+	} {
+		if !field.present {
+			continue
+		}
+		w.WriteString(separator + `"` + field.name + `":`)
+		separator = ","
+		if err := execution.WriteJSON(w, field.value); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// negotiate returns the value of the preferred one of the accepted keys listed in header, an Accept or an
+// Accept-Encoding header, or def when header is empty. It returns "" when no key is accepted.
+func negotiate(header string, values map[string]string, def string) string {
+	if strings.TrimSpace(header) == "" {
+		return def
+	}
+	value, quality := "", 0.0
+	for _, accepted := range strings.Split(header, ",") {
+		key, params, err := mime.ParseMediaType(accepted)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if params["q"] != "" {
+			if q, err = strconv.ParseFloat(params["q"], 64); err != nil {
+				continue
+			}
+		}
+		if v, ok := values[key]; ok && q > quality {
+			value, quality = v, q
+		}
+	}
+	return value
+}
@@ -0,0 +1,480 @@
+package handler_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	graphqlerrors "github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/handler"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func(args struct {
+		Name *string `graphql:"name"`
+	}) string {
+		if args.Name == nil {
+			return "hello world"
+		}
+		return "hello " + *args.Name
+	})
+	build.Query().FieldFunc("fail", func() (*string, error) {
+		return nil, errors.New("failed")
+	})
+	build.Mutation().FieldFunc("reset", func() bool { return true })
+	return build.MustBuild()
+}
+
+func serve(h http.Handler, method, contentType, body string, query ...url.Values) *httptest.ResponseRecorder {
+	target := "/graphql"
+	if len(query) > 0 {
+		target += "?" + query[0].Encode()
+	}
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := handler.New(buildSchema())
+
+	t.Run("executes an operation", func(t *testing.T) {
+		w := serve(h, http.MethodPost, "application/json; charset=utf-8",
+			`{"query": "query Hello($name: String) { hello(name: $name) }", "operationName": "Hello", "variables": {"name": "Ada"}}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"data": {"hello": "hello Ada"}}`, w.Body.String())
+	})
+
+	t.Run("keeps the integers of the variables exact", func(t *testing.T) {
+		build := schemabuilder.NewSchema()
+		build.UseScalar(schemabuilder.Int64String)
+		build.Query().FieldFunc("next", func(args struct {
+			ID int64 `graphql:"id"`
+		}) int64 {
+			return args.ID + 1
+		})
+		w := serve(handler.New(build.MustBuild()), http.MethodPost, "application/json",
+			`{"query": "query ($id: Int64!) { next(id: $id) }", "variables": {"id": 9007199254740993}}`)
+		assert.JSONEq(t, `{"data": {"next": "9007199254740994"}}`, w.Body.String())
+	})
+
+	t.Run("returns the field errors along with the data", func(t *testing.T) {
+		var logged []string
+		h := handler.New(buildSchema(), handler.ErrorLogger(func(ctx context.Context, err *graphqlerrors.GraphQLError) {
+			logged = append(logged, fmt.Sprint(err.Path, err.ResolverError))
+		}))
+		w := serve(h, http.MethodPost, "application/json", `{"query": "{ hello fail }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"errors": [{
+				"message": "internal system error",
+				"locations": [{"line": 1, "column": 9}],
+				"path": ["fail"],
+				"extensions": {"code": "INTERNAL_SERVER_ERROR"}
+			}],
+			"data": {"fail": null, "hello": "hello world"}
+		}`, w.Body.String())
+		assert.Equal(t, []string{"[fail] failed"}, logged)
+
+		w = serve(handler.New(buildSchema(), handler.UnmaskedErrors()), http.MethodPost, "application/json",
+			`{"query": "{ fail }"}`)
+		assert.JSONEq(t, `{
+			"errors": [{"message": "failed", "locations": [{"line": 1, "column": 3}], "path": ["fail"]}],
+			"data": {"fail": null}
+		}`, w.Body.String())
+	})
+
+	t.Run("returns no data for an invalid operation", func(t *testing.T) {
+		w := serve(h, http.MethodPost, "application/json", `{"query": "{ unknown }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"errors": [{
+			"message": "Cannot query field \"unknown\" on type \"Query\".",
+			"locations": [{"line": 1, "column": 3}],
+			"extensions": {"code": "GRAPHQL_VALIDATION_FAILED"}
+		}]}`, w.Body.String())
+	})
+
+	t.Run("rejects the malformed requests", func(t *testing.T) {
+		w := serve(h, http.MethodPut, "application/json", `{"query": "{ hello }"}`)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+
+		w = serve(h, http.MethodPost, "text/plain", `{ hello }`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "unsupported content type \"text/plain\", expected application/json"}]}`,
+			w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{"query": "{ hello }", "variables": []}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid request body")
+
+		w = serve(h, http.MethodPost, "application/json", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "missing query"}]}`, w.Body.String())
+	})
+
+	t.Run("executes a query sent with GET", func(t *testing.T) {
+		query := url.Values{
+			"query":         {"query Hello($name: String) { hello(name: $name) }"},
+			"operationName": {"Hello"},
+			"variables":     {`{"name": "Ada"}`},
+		}
+		w := serve(h, http.MethodGet, "", "", query)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data": {"hello": "hello Ada"}}`, w.Body.String())
+
+		w = serve(h, http.MethodGet, "", "", url.Values{"query": {"{ hello }"}, "variables": {"{"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid variables")
+	})
+
+	t.Run("rejects the mutations sent with GET", func(t *testing.T) {
+		w := serve(h, http.MethodGet, "", "", url.Values{"query": {"mutation { reset }"}})
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+		assert.JSONEq(t, `{"errors": [{"message": "Can only perform a mutation operation from a POST request."}]}`,
+			w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{"query": "mutation { reset }"}`)
+		assert.JSONEq(t, `{"data": {"reset": true}}`, w.Body.String())
+	})
+
+	t.Run("runs the operations with the executor", func(t *testing.T) {
+		h := handler.New(buildSchema(), handler.Executor(&execution.Executor{
+			Introspection: execution.DisableIntrospection,
+		}))
+		w := serve(h, http.MethodPost, "application/json", `{"query": "{ __schema { queryType { name } } }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"data"`)
+	})
+
+	t.Run("executes with the context of the request", func(t *testing.T) {
+		type key struct{}
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("value", func(ctx context.Context) string { return ctx.Value(key{}).(string) })
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ value }"}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.New(build.MustBuild()).ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key{}, "from request")))
+		assert.JSONEq(t, `{"data": {"value": "from request"}}`, w.Body.String())
+	})
+}
+
+func TestHandler_Batch(t *testing.T) {
+	t.Run("returns the responses of a batch in order", func(t *testing.T) {
+		w := serve(handler.New(buildSchema()), http.MethodPost, "application/json", ` [
+			{"query": "{ hello }"},
+			{"query": "query Hello($name: String) { hello(name: $name) }", "variables": {"name": "Ada"}},
+			{"query": "{ unknown }"},
+			{}
+		]`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[
+			{"data": {"hello": "hello world"}},
+			{"data": {"hello": "hello Ada"}},
+			{"errors": [{"message": "Cannot query field \"unknown\" on type \"Query\".", "locations": [{"line": 1, "column": 3}],
+				"extensions": {"code": "GRAPHQL_VALIDATION_FAILED"}}]},
+			{"errors": [{"message": "missing query"}]}
+		]`, w.Body.String())
+	})
+
+	t.Run("runs the operations of a batch concurrently", func(t *testing.T) {
+		var arrived sync.WaitGroup
+		arrived.Add(2)
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("meet", func() (bool, error) {
+			arrived.Done()
+			done := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return true, nil
+			case <-time.After(time.Second):
+				return false, errors.New("the other operation has not run")
+			}
+		})
+		w := serve(handler.New(build.MustBuild(), handler.BatchConcurrency(2)), http.MethodPost, "application/json",
+			`[{"query": "{ meet }"}, {"query": "{ meet }"}]`)
+		assert.JSONEq(t, `[{"data": {"meet": true}}, {"data": {"meet": true}}]`, w.Body.String())
+	})
+
+	t.Run("rejects an empty batch", func(t *testing.T) {
+		w := serve(handler.New(buildSchema()), http.MethodPost, "application/json", `[]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "empty batch"}]}`, w.Body.String())
+	})
+
+	t.Run("rejects the batches beyond the limit", func(t *testing.T) {
+		batch := "[" + strings.Repeat(`{"query": "{ hello }"},`, handler.DefaultMaxBatchSize) + `{"query": "{ hello }"}]`
+		w := serve(handler.New(buildSchema()), http.MethodPost, "application/json", batch)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "batch of 11 operations, the limit is 10"}]}`, w.Body.String())
+
+		w = serve(handler.New(buildSchema(), handler.MaxBatchSize(0)), http.MethodPost, "application/json", batch)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve(handler.New(buildSchema(), handler.MaxBatchSize(1)), http.MethodPost, "application/json",
+			`[{"query": "{ hello }"}, {"query": "{ hello }"}]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_Negotiation(t *testing.T) {
+	h := handler.New(buildSchema())
+	serveAccepting := func(accept, acceptEncoding, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", accept)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("writes application/graphql-response+json when accepted", func(t *testing.T) {
+		accept := "application/graphql-response+json, application/json;q=0.9"
+		w := serveAccepting(accept, "", `{"query": "{ hello fail }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/graphql-response+json", w.Header().Get("Content-Type"))
+
+		w = serveAccepting(accept, "", `{"query": "{ unknown }"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Cannot query field")
+
+		w = serveAccepting("application/graphql-response+json;q=0.5, application/json", "", `{"query": "{ unknown }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("rejects the requests accepting no JSON", func(t *testing.T) {
+		w := serveAccepting("text/html", "", `{"query": "{ hello }"}`)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("compresses the responses", func(t *testing.T) {
+		for encoding, reader := range map[string]func(io.Reader) (io.Reader, error){
+			"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+			"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		} {
+			w := serveAccepting("", "br;q=1, "+encoding+";q=0.8, identity;q=0.1", `{"query": "{ hello }"}`)
+			assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			r, err := reader(w.Body)
+			assert.NoError(t, err)
+			body, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, string(body))
+		}
+
+		w := serveAccepting("", "gzip;q=0", `{"query": "{ hello }"}`)
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+	})
+}
+
+func TestHandler_Limits(t *testing.T) {
+	t.Run("rejects the bodies larger than the limit", func(t *testing.T) {
+		h := handler.New(buildSchema(), handler.MaxBodyBytes(32))
+		w := serve(h, http.MethodPost, "application/json", `{"query": "{ hello }"}`)
+		assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{"query": "{ hello hello hello hello }"}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "request body larger than 32 bytes"}]}`, w.Body.String())
+	})
+
+	t.Run("rejects the bodies not read in time", func(t *testing.T) {
+		server := httptest.NewServer(handler.New(buildSchema(), handler.ReadTimeout(50*time.Millisecond)))
+		defer server.Close()
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		// the body is sent partly and never completed
+		_, err = io.WriteString(conn, "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n"+
+			"Content-Length: 100\r\n\r\n{\"query\":")
+		assert.NoError(t, err)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusRequestTimeout, response.StatusCode)
+		body, _ := io.ReadAll(response.Body)
+		assert.JSONEq(t, `{"errors": [{"message": "request body not read within 50ms"}]}`, string(body))
+	})
+}
+
+func TestHandler_Codec(t *testing.T) {
+	var unmarshaled, marshaled int
+	h := handler.New(buildSchema(),
+		handler.Unmarshal(func(data []byte, v interface{}) error {
+			unmarshaled++
+			return json.Unmarshal(data, v)
+		}),
+		handler.Marshal(func(v interface{}) ([]byte, error) {
+			marshaled++
+			return json.MarshalIndent(v, "", "  ")
+		}))
+
+	w := serve(h, http.MethodPost, "application/json", `{"query": "query($name: String) { hello(name: $name) }", "variables": {"name": "Ada"}}`)
+	assert.Equal(t, "{\n  \"data\": {\n    \"hello\": \"hello Ada\"\n  }\n}", w.Body.String())
+	assert.Equal(t, 1, unmarshaled)
+	assert.Equal(t, 2, marshaled)
+
+	w = serve(h, http.MethodGet, "", "", url.Values{"query": {"query($name: String) { hello(name: $name) }"},
+		"variables": {`{"name": "Grace"}`}})
+	assert.JSONEq(t, `{"data": {"hello": "hello Grace"}}`, w.Body.String())
+	assert.Equal(t, 2, unmarshaled)
+}
+
+func TestHandler_Streaming(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("items", func() []string {
+		items := make([]string, 1000)
+		for i := range items {
+			items[i] = fmt.Sprintf("<item %d> & \"é\"", i)
+		}
+		return items
+	})
+	build.Query().FieldFunc("fail", func() (*string, error) { return nil, errors.New("failed") })
+	schema := build.MustBuild()
+	streamed, marshaled := handler.New(schema), handler.New(schema, handler.Marshal(json.Marshal))
+
+	for _, body := range []string{
+		`{"query": "{ items fail }"}`,
+		`{"query": "{ unknown }"}`,
+		`[{"query": "{ items }"}, {"query": "{ fail }"}, {}]`,
+	} {
+		expected := serve(marshaled, http.MethodPost, "application/json", body)
+		w := serve(streamed, http.MethodPost, "application/json", body)
+		assert.Equal(t, expected.Code, w.Code, body)
+		assert.Equal(t, expected.Body.String(), w.Body.String(), body)
+	}
+}
+
+func TestHandler_Tracing(t *testing.T) {
+	h := handler.New(buildSchema(), handler.Tracing(func(r *http.Request) bool {
+		return r.Header.Get("X-Debug") != ""
+	}))
+
+	w := serve(h, http.MethodPost, "application/json", `{"query": "{ hello }"}`)
+	assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ hello(name: \"Ada\") }"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Debug", "1")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	var response struct {
+		Data       map[string]interface{}
+		Extensions struct {
+			Tracing struct {
+				Version   int
+				Execution struct {
+					Resolvers []struct {
+						Path       []interface{}
+						ParentType string
+						FieldName  string
+						ReturnType string
+						Args       map[string]interface{}
+					}
+				}
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]interface{}{"hello": "hello Ada"}, response.Data)
+	assert.Equal(t, 1, response.Extensions.Tracing.Version)
+	if resolvers := response.Extensions.Tracing.Execution.Resolvers; assert.Len(t, resolvers, 1) {
+		assert.Equal(t, []interface{}{"hello"}, resolvers[0].Path)
+		assert.Equal(t, "Query", resolvers[0].ParentType)
+		assert.Equal(t, "hello", resolvers[0].FieldName)
+		assert.Equal(t, "String!", resolvers[0].ReturnType)
+		assert.Equal(t, map[string]interface{}{"name": "Ada"}, resolvers[0].Args)
+	}
+}
+
+func TestHandler_RequestID(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("requestId", func(ctx context.Context) string { return execution.RequestID(ctx) })
+	h := handler.New(build.MustBuild(), handler.RequestID("X-Request-Id"))
+
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ requestId }"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-Id", "42")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.JSONEq(t, `{"data": {"requestId": "42"}}`, w.Body.String())
+	assert.Equal(t, "42", w.Header().Get("X-Request-Id"))
+
+	w = serve(h, http.MethodPost, "application/json", `{"query": "{ requestId }"}`)
+	id := w.Header().Get("X-Request-Id")
+	assert.Len(t, id, 32)
+	assert.JSONEq(t, `{"data": {"requestId": "`+id+`"}}`, w.Body.String())
+
+	seeded := handler.New(build.MustBuild(), handler.RequestID("X-Request-Id"), handler.Rand(clock.NewRand(42)))
+	w = serve(seeded, http.MethodPost, "application/json", `{"query": "{ requestId }"}`)
+	assert.Equal(t, execution.NewRequestID(clock.NewRand(42)), w.Header().Get("X-Request-Id"))
+}
+
+func TestHandler_TrustedDocuments(t *testing.T) {
+	h := handler.New(buildSchema(), handler.TrustedDocuments(map[string]string{
+		"hello": "query Hello($name: String) { hello(name: $name) }",
+		"ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38": "{ hello }",
+	}))
+
+	t.Run("runs the documents by their id", func(t *testing.T) {
+		w := serve(h, http.MethodPost, "application/json", `{"documentId": "hello", "variables": {"name": "Ada"}}`)
+		assert.JSONEq(t, `{"data": {"hello": "hello Ada"}}`, w.Body.String())
+
+		w = serve(h, http.MethodGet, "", "", url.Values{"documentId": {"hello"}})
+		assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{"extensions": {"persistedQuery": {
+			"version": 1,
+			"sha256Hash": "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"
+		}}}`)
+		assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+	})
+
+	t.Run("rejects the other operations", func(t *testing.T) {
+		w := serve(h, http.MethodPost, "application/json", `{"query": "{ hello }"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "only trusted documents are accepted, send the documentId of the operation"}]}`,
+			w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{"documentId": "unknown"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": [{"message": "unknown document \"unknown\""}]}`, w.Body.String())
+
+		w = serve(h, http.MethodPost, "application/json", `{}`)
+		assert.JSONEq(t, `{"errors": [{"message": "missing documentId"}]}`, w.Body.String())
+	})
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// PageOption configures the page served by IDE or GraphiQL.
+type PageOption func(*page)
+
+// SubscriptionEndpoint sends the subscriptions to endpoint with the graphql-transport-ws protocol, eg. "/subscriptions"
+// or "wss://example.com/subscriptions", a path being resolved against the location of the page.
+func SubscriptionEndpoint(endpoint string) PageOption {
+	return func(p *page) {
+		p.SubscriptionEndpoint = endpoint
+	}
+}
+
+// Headers fills the header editor with headers, which are sent along with the operations, eg. an authorization
+// header for an internal tool.
+func Headers(headers map[string]string) PageOption {
+	return func(p *page) {
+		p.Headers = headers
+	}
+}
+
+// GraphiQLAssets loads the files of GraphiQL from base, eg. "/graphiql/" served by an http.FileServer of copies
+// embedded in the binary, instead of from unpkg. The files are graphiql.min.css and graphiql.min.js of GraphiQL
+// GraphiQLVersion, and react.production.min.js and react-dom.production.min.js of React ReactVersion.
+func GraphiQLAssets(base string) PageOption {
+	return func(p *page) {
+		p.assets = base
+	}
+}
+
+// The versions of GraphiQL and React loaded by the page of GraphiQL, the files of the CDN are pinned to them so
+// that the page does not change when new versions are published.
+const (
+	GraphiQLVersion = "3.1.0"
+	ReactVersion    = "18.3.1"
+)
+
+var graphiqlAssets = map[string]string{
+	"graphiql.min.css":            "https://unpkg.com/graphiql@" + GraphiQLVersion + "/graphiql.min.css",
+	"graphiql.min.js":             "https://unpkg.com/graphiql@" + GraphiQLVersion + "/graphiql.min.js",
+	"react.production.min.js":     "https://unpkg.com/react@" + ReactVersion + "/umd/react.production.min.js",
+	"react-dom.production.min.js": "https://unpkg.com/react-dom@" + ReactVersion + "/umd/react-dom.production.min.js",
+}
+
+// Playground serves GraphQL Playground, https://github.com/graphql/graphql-playground, instead of GraphiQL.
+func Playground() PageOption {
+	return func(p *page) {
+		p.ui = playgroundPage
+	}
+}
+
+// Altair serves Altair, https://altairgraphql.dev, instead of GraphiQL.
+func Altair() PageOption {
+	return func(p *page) {
+		p.ui = altairPage
+	}
+}
+
+// ApolloSandbox redirects to Apollo Sandbox, https://studio.apollographql.com/sandbox, instead of serving
+// GraphiQL. The sandbox is hosted by Apollo and ignores the headers, it finds the subscriptions by itself.
+func ApolloSandbox() PageOption {
+	return func(p *page) {
+		p.ui = apolloSandboxPage
+	}
+}
+
+type page struct {
+	ui                   *template.Template
+	assets               string
+	Endpoint             string
+	SubscriptionEndpoint string
+	Headers              map[string]string
+}
+
+// Asset returns the URL of the file name of GraphiQL.
+func (p *page) Asset(name string) string {
+	if p.assets != "" {
+		return strings.TrimSuffix(p.assets, "/") + "/" + name
+	}
+	return graphiqlAssets[name]
+}
+
+// HeadersText returns the headers as the JSON text of the header editor of GraphiQL.
+func (p *page) HeadersText() (string, error) {
+	if len(p.Headers) == 0 {
+		return "", nil
+	}
+	encoded, err := json.MarshalIndent(p.Headers, "", "  ")
+	return string(encoded), err
+}
+
+// IDE returns the handler serving an in-browser IDE exploring the schema served at endpoint, eg. "/graphql".
+// The IDE is GraphiQL unless an option selects another one, and is loaded from a CDN, see GraphiQLAssets to host
+// the files of GraphiQL.
+//
+//	http.Handle("/", handler.IDE("/graphql", handler.Altair()))
+func IDE(endpoint string, options ...PageOption) http.Handler {
+	p := &page{ui: graphiqlPage, Endpoint: endpoint, Headers: map[string]string{}}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// GraphiQL returns the handler serving GraphiQL, https://github.com/graphql/graphiql, an in-browser IDE exploring
+// the schema served at endpoint, whatever the options.
+func GraphiQL(endpoint string, options ...PageOption) http.Handler {
+	p := IDE(endpoint, options...).(*page)
+	p.ui = graphiqlPage
+	return p
+}
+
+func (p *page) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := p.ui.Execute(&buf, p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// endpoints resolves the endpoints against the location of the page, as url and subscriptionUrl.
+var endpoints = template.Must(template.New("endpoints").Parse(`
+    const url = new URL({{.Endpoint}}, location.href).toString();
+    let subscriptionUrl;
+    {{- if .SubscriptionEndpoint}}
+    const ws = new URL({{.SubscriptionEndpoint}}, location.href);
+    ws.protocol = ws.protocol === "https:" ? "wss:" : ws.protocol === "http:" ? "ws:" : ws.protocol;
+    subscriptionUrl = ws.toString();
+    {{- end}}`))
+
+func uiPage(name, text string) *template.Template {
+	return template.Must(template.Must(endpoints.Clone()).New(name).Parse(text))
+}
+
+var graphiqlPage = uiPage("GraphiQL", `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8"/>
+    <meta name="robots" content="noindex"/>
+    <meta name="referrer" content="origin"/>
+    <title>GraphiQL</title>
+    <link rel="stylesheet" href="{{.Asset "graphiql.min.css"}}"/>
+    <script crossorigin src="{{.Asset "react.production.min.js"}}"></script>
+    <script crossorigin src="{{.Asset "react-dom.production.min.js"}}"></script>
+    <script crossorigin src="{{.Asset "graphiql.min.js"}}"></script>
+</head>
+<body style="margin: 0;">
+<div id="graphiql" style="height: 100vh;">Loading...</div>
+<script>
+    {{- template "endpoints" .}}
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(
+        React.createElement(GraphiQL, {
+            fetcher: GraphiQL.createFetcher({url, subscriptionUrl}),
+            defaultHeaders: {{.HeadersText}},
+            isHeadersEditorEnabled: true,
+        }),
+    );
+</script>
+</body>
+</html>
+`)
+
+var playgroundPage = uiPage("Playground", `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8"/>
+    <meta name="robots" content="noindex"/>
+    <meta name="viewport" content="user-scalable=no, initial-scale=1.0, minimum-scale=1.0, maximum-scale=1.0, minimal-ui"/>
+    <title>GraphQL Playground</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/graphql-playground-react@1/build/static/css/index.css"/>
+    <link rel="shortcut icon" href="https://cdn.jsdelivr.net/npm/graphql-playground-react@1/build/favicon.png"/>
+    <script src="https://cdn.jsdelivr.net/npm/graphql-playground-react@1/build/static/js/middleware.js"></script>
+</head>
+<body>
+<div id="root"></div>
+<script>
+    window.addEventListener("load", function () {
+        {{- template "endpoints" .}}
+        GraphQLPlayground.init(document.getElementById("root"), {
+            endpoint: url,
+            subscriptionEndpoint: subscriptionUrl,
+            headers: {{.Headers}},
+        });
+    });
+</script>
+</body>
+</html>
+`)
+
+var altairPage = uiPage("Altair", `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8"/>
+    <meta name="robots" content="noindex"/>
+    <title>Altair</title>
+    <base href="https://cdn.jsdelivr.net/npm/altair-static@5/build/dist/"/>
+    <link rel="stylesheet" href="styles.css"/>
+    <script src="runtime.js"></script>
+    <script src="polyfills.js"></script>
+    <script src="main.js"></script>
+</head>
+<body>
+<app-root></app-root>
+<script>
+    window.addEventListener("load", function () {
+        {{- template "endpoints" .}}
+        AltairGraphQL.init({
+            endpointURL: url,
+            subscriptionsEndpoint: subscriptionUrl,
+            initialSubscriptionsProvider: "graphql-ws",
+            initialHeaders: {{.Headers}},
+        });
+    });
+</script>
+</body>
+</html>
+`)
+
+var apolloSandboxPage = uiPage("ApolloSandbox", `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8"/>
+    <meta name="robots" content="noindex"/>
+    <title>Apollo Sandbox</title>
+</head>
+<body>
+<script>
+    {{- template "endpoints" .}}
+    location.replace("https://studio.apollographql.com/sandbox/explorer?endpoint=" + encodeURIComponent(url));
+</script>
+</body>
+</html>
+`)
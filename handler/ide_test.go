@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shyptr/graphql/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(h http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestGraphiQL(t *testing.T) {
+	w := get(handler.GraphiQL("/graphql",
+		handler.SubscriptionEndpoint("/subscriptions"),
+		handler.Headers(map[string]string{"Authorization": "Bearer </script>"}),
+		handler.Altair(),
+	))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "graphiql.min.js")
+	assert.Contains(t, body, `new URL("/graphql", location.href)`)
+	assert.Contains(t, body, `new URL("/subscriptions", location.href)`)
+	// the headers are escaped in the script
+	assert.Contains(t, body, `\"Authorization\": \"Bearer \\u003c/script\\u003e\"`)
+	assert.NotContains(t, body, "Bearer </script>")
+}
+
+func TestIDE(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		option   handler.PageOption
+		contains []string
+	}{
+		{"Playground", handler.Playground(), []string{"GraphQLPlayground.init", `headers: {"Authorization":"Bearer token"}`}},
+		{"Altair", handler.Altair(), []string{"AltairGraphQL.init", `initialHeaders: {"Authorization":"Bearer token"}`}},
+		{"ApolloSandbox", handler.ApolloSandbox(), []string{"https://studio.apollographql.com/sandbox/explorer?endpoint="}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			body := get(handler.IDE("/graphql", test.option,
+				handler.Headers(map[string]string{"Authorization": "Bearer token"}),
+			)).Body.String()
+			assert.Contains(t, body, `new URL("/graphql", location.href)`)
+			for _, s := range test.contains {
+				assert.Contains(t, body, s)
+			}
+		})
+	}
+
+	t.Run("serves GraphiQL by default", func(t *testing.T) {
+		body := get(handler.IDE("/graphql")).Body.String()
+		assert.Contains(t, body, "graphiql.min.js")
+		assert.Contains(t, body, `defaultHeaders: ""`)
+	})
+
+	t.Run("pins the versions of the assets of GraphiQL", func(t *testing.T) {
+		body := get(handler.GraphiQL("/graphql")).Body.String()
+		assert.Contains(t, body, `src="https://unpkg.com/graphiql@`+handler.GraphiQLVersion+`/graphiql.min.js"`)
+		assert.Contains(t, body, `src="https://unpkg.com/react@`+handler.ReactVersion+`/umd/react.production.min.js"`)
+
+		body = get(handler.GraphiQL("/graphql", handler.GraphiQLAssets("/assets/"))).Body.String()
+		assert.Contains(t, body, `href="/assets/graphiql.min.css"`)
+		assert.Contains(t, body, `src="/assets/react-dom.production.min.js"`)
+		assert.NotContains(t, body, "unpkg.com")
+	})
+}
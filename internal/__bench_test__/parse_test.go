@@ -0,0 +1,110 @@
+package __bench_test__
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+const kitchenSink = `
+query queryName($foo: ComplexType, $site: Site = MOBILE) {
+  whoever123is: node(id: [123, 456]) {
+    id ,
+    ... on User @defer {
+      field2 {
+        id ,
+        alias: field1(first:10, after:$foo,) @include(if: $foo) {
+          id,
+          ...frag
+        }
+      }
+    }
+    ... @skip(unless: $foo) {
+      id
+    }
+    ... {
+      id
+    }
+  }
+}
+
+mutation likeStory {
+  like(story: 123) @defer {
+    story {
+      id
+    }
+  }
+}
+
+subscription StoryLikeSubscription($input: StoryLikeSubscribeInput) {
+  storyLikeSubscribe(input: $input) {
+    story {
+      likers {
+        count
+      }
+      likeSentence {
+        text
+      }
+    }
+  }
+}
+
+fragment frag on Friend {
+  foo(size: $size, bar: $b, obj: {key: "value", block: """
+      block string uses \"""
+  """})
+}
+
+{
+  unnamed(truthy: true, falsey: false, nullish: null),
+  query
+}
+`
+
+func BenchmarkParseDocument(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(kitchenSink)))
+	for i := 0; i < b.N; i++ {
+		if _, err := internal.ParseDocument(kitchenSink); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(kitchenSink)))
+	for i := 0; i < b.N; i++ {
+		internal.NormalizeQuery(kitchenSink)
+	}
+}
+
+// BenchmarkParseDocumentGC measures the GC pressure of a server parsing distinct queries, the documents being kept
+// by a cache of the last 1024 ones as the document cache of the executors does. It reports the GC cycles and their
+// pause time per parse along with the allocations.
+func BenchmarkParseDocumentGC(b *testing.B) {
+	queries := make([]string, 4096)
+	for i := range queries {
+		queries[i] = strings.Replace(kitchenSink, "whoever123is", "whoever"+strconv.Itoa(i), 1)
+	}
+	cache := make([]interface{}, 1024)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc, err := internal.ParseDocument(queries[i%len(queries)])
+		if err != nil {
+			b.Fatal(err)
+		}
+		cache[i%len(cache)] = doc
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N)*1000, "gc/kop")
+	b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "pause-ns/op")
+}
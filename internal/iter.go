@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"iter"
+	"sort"
+)
+
+// Types yields the named types of the schema ordered by name.
+func (s *Schema) Types() iter.Seq[NamedType] {
+	return func(yield func(NamedType) bool) {
+		names := make([]string, 0, len(s.TypeMap))
+		for name := range s.TypeMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !yield(s.TypeMap[name]) {
+				return
+			}
+		}
+	}
+}
+
+// Fields yields the selections of the selection set which apply to the object or interface named parent,
+// with their definition in the schema: the selections of the set first, then those of its fragments.
+// Fragments apply when their type condition is parent, an interface implemented by parent or a union containing it.
+// The definition of __typename and of unknown fields is nil.
+func (s *SelectionSet) Fields(schema *Schema, parent string) iter.Seq2[*Selection, *Field] {
+	var fields map[string]*Field
+	var interfaces map[string]*Interface
+	switch typ := schema.TypeMap[parent].(type) {
+	case *Object:
+		fields, interfaces = typ.Fields, typ.Interfaces
+	case *Interface:
+		fields, interfaces = typ.Fields, typ.Interfaces
+	}
+	applies := func(on string) bool {
+		if on == "" || on == parent {
+			return true
+		}
+		if _, ok := interfaces[on]; ok {
+			return true
+		}
+		if union, ok := schema.TypeMap[on].(*Union); ok {
+			for _, object := range union.Types {
+				if object.Name == parent {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return func(yield func(*Selection, *Field) bool) {
+		var walk func(*SelectionSet) bool
+		walk = func(selectionSet *SelectionSet) bool {
+			if selectionSet == nil {
+				return true
+			}
+			for _, selection := range selectionSet.Selections {
+				if !yield(selection, fields[selection.Name]) {
+					return false
+				}
+			}
+			for _, fragment := range selectionSet.Fragments {
+				if applies(fragment.Fragment.On) && !walk(fragment.Fragment.SelectionSet) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(s)
+	}
+}
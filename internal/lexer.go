@@ -1,33 +1,57 @@
 package internal
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/token"
 	"strings"
 	"text/scanner"
+	"unicode"
+	"unicode/utf8"
 )
 
 type syntaxError string
 
+// lexer scans the tokens of a source byte by byte. The text of a token is a substring of the source, so that
+// scanning allocates nothing but the values of the block strings.
 type lexer struct {
-	scan                  *scanner.Scanner
+	source string
+	// pos is the offset of the next byte to scan, line and lineStart are the line of pos and the offset of its
+	// start
+	pos       int
+	line      int
+	lineStart int
+	// next is the kind of the current token, which spans source[start:end] at tokLine and tokCol
 	next                  rune
-	comment               bytes.Buffer
+	start, end            int
+	tokLine, tokCol       int
 	useStringDescriptions bool
+	blockString           string
+	// nodes allocates the nodes of the document
+	nodes nodes
 }
 
 func NewLexer(source string, useStringDescriptions ...bool) *lexer {
-	scan := &scanner.Scanner{
-		Mode: scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings,
+	l := &lexer{source: source, line: 1}
+	// a leading byte order mark is not part of the document
+	if strings.HasPrefix(source, "\uFEFF") {
+		l.pos, l.lineStart = len("\uFEFF"), len("\uFEFF")
 	}
-	scan.Init(strings.NewReader(source))
-
 	if len(useStringDescriptions) > 0 {
-		return &lexer{scan: scan, useStringDescriptions: useStringDescriptions[0]}
+		l.useStringDescriptions = useStringDescriptions[0]
 	}
-	return &lexer{scan: scan}
+	return l
+}
+
+// Lex scans all the tokens of source and returns their number, eg. to measure the lexer apart from the parser.
+func Lex(source string) (tokens int, err *errors.GraphQLError) {
+	l := NewLexer(source)
+	err = l.catchSyntaxError(func() {
+		for l.SkipWhitespace(); l.next != token.EOF; l.SkipWhitespace() {
+			tokens++
+		}
+	})
+	return tokens, err
 }
 
 func (l *lexer) catchSyntaxError(fn func()) (graphQLError *errors.GraphQLError) {
@@ -36,6 +60,8 @@ func (l *lexer) catchSyntaxError(fn func()) (graphQLError *errors.GraphQLError)
 			if err, ok := err.(syntaxError); ok {
 				graphQLError = errors.New("Syntax Error: %s", err)
 				graphQLError.Locations = []errors.Location{l.location()}
+				graphQLError.Kind = errors.KindSyntax
+				graphQLError.WithCode(errors.CodeParseFailed)
 				return
 			}
 			panic(err)
@@ -49,59 +75,282 @@ func (l *lexer) peek() rune {
 	return l.next
 }
 
+// text returns the text of the current token, empty at the end of the source.
+func (l *lexer) text() string {
+	return l.source[l.start:l.end]
+}
+
 func (l *lexer) location() errors.Location {
 	return errors.Location{
-		Line:   l.scan.Line,
-		Column: l.scan.Column,
+		Line:   l.tokLine,
+		Column: l.tokCol,
 	}
 }
 
 // skip whitespace, also tab, commas, BOM and comments
 func (l *lexer) SkipWhitespace() {
-	l.comment.Reset()
-	for {
-		l.next = l.scan.Scan()
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n', '\r':
+			// \r\n is one line break, as \n and a lone \r are
+			if c == '\r' && strings.HasPrefix(l.source[l.pos:], "\r\n") {
+				l.pos++
+			}
+			l.pos++
+			l.line, l.lineStart = l.line+1, l.pos
+		case '#':
+			l.skipComment()
+		case '/':
+			// the comments of Go have always been skipped too
+			if !l.skipGoComment() {
+				l.scan()
+				return
+			}
+		default:
+			if c == 0xEF && strings.HasPrefix(l.source[l.pos:], "\uFEFF") {
+				l.pos += len("\uFEFF")
+				continue
+			}
+			l.scan()
+			return
+		}
+	}
+	l.next, l.start, l.end = token.EOF, l.pos, l.pos
+	l.setTokenPosition()
+}
 
-		if l.next == ',' {
-			continue
+func (l *lexer) setTokenPosition() {
+	l.tokLine = l.line
+	l.tokCol = 1 + l.start - l.lineStart
+	// the columns count characters, the bytes of the multi-byte ones are counted once
+	for i := l.lineStart; i < l.start; i++ {
+		if c := l.source[i]; c >= utf8.RuneSelf && utf8.RuneStart(c) {
+			l.tokCol = 1 + utf8.RuneCountInString(l.source[l.lineStart:l.start])
+			break
 		}
+	}
+}
 
-		if l.next == '#' {
-			l.skipComment()
-			continue
+// scan scans the token starting at pos.
+func (l *lexer) scan() {
+	l.start = l.pos
+	l.setTokenPosition()
+	c := l.source[l.pos]
+	switch {
+	case isNameStart(c):
+		l.pos++
+		for l.pos < len(l.source) && isNameContinue(l.source[l.pos]) {
+			l.pos++
+		}
+		l.next = token.NAME
+	case c == '-' || isDigit(c):
+		l.scanNumber()
+	case c == '"':
+		if strings.HasPrefix(l.source[l.pos:], `"""`) {
+			l.scanBlockString()
+		} else {
+			l.scanString()
+		}
+	case c == '`':
+		end := strings.IndexByte(l.source[l.pos+1:], '`')
+		if end < 0 {
+			l.SyntaxError("Unterminated string.")
+		}
+		l.advanceLines(l.pos + 1 + end + 1)
+		l.next = token.RAWSTRING
+	case c < utf8.RuneSelf:
+		l.pos++
+		l.next = rune(c)
+	default:
+		r, size := utf8.DecodeRuneInString(l.source[l.pos:])
+		if unicode.IsLetter(r) {
+			// the names may have letters of any script
+			l.pos += size
+			for l.pos < len(l.source) {
+				r, size := utf8.DecodeRuneInString(l.source[l.pos:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				l.pos += size
+			}
+			l.next = token.NAME
+		} else {
+			l.pos += size
+			l.next = r
 		}
-		break
 	}
+	l.end = l.pos
 }
 
-func (l *lexer) skipComment() {
-	if l.next != '#' {
-		panic("consumeComment used in wrong context")
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scanNumber scans an IntValue or a FloatValue, a minus sign followed by no digit is a token of its own.
+func (l *lexer) scanNumber() {
+	l.next = token.INT
+	if l.source[l.pos] == '-' {
+		if l.pos+1 == len(l.source) || !isDigit(l.source[l.pos+1]) {
+			l.pos++
+			l.next = '-'
+			return
+		}
+		l.pos++
+	}
+	l.skipDigits()
+	if l.pos+1 < len(l.source) && l.source[l.pos] == '.' && isDigit(l.source[l.pos+1]) {
+		l.pos++
+		l.skipDigits()
+		l.next = token.FLOAT
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		exponent := l.pos + 1
+		if exponent < len(l.source) && (l.source[exponent] == '+' || l.source[exponent] == '-') {
+			exponent++
+		}
+		if exponent < len(l.source) && isDigit(l.source[exponent]) {
+			l.pos = exponent
+			l.skipDigits()
+			l.next = token.FLOAT
+		}
 	}
+}
 
-	// TODO: count and trim whitespace so we can dedent any following lines.
-	if l.scan.Peek() == ' ' {
-		l.scan.Next()
+func (l *lexer) skipDigits() {
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
 	}
+}
 
-	if l.comment.Len() > 0 {
-		l.comment.WriteRune('\n')
+// scanString scans a string on a single line, its text keeps the quotes and the escape sequences.
+func (l *lexer) scanString() {
+	for i := l.pos + 1; i < len(l.source); i++ {
+		switch l.source[i] {
+		case '\\':
+			i++
+		case '"':
+			l.pos = i + 1
+			l.next = token.STRING
+			return
+		case '\n', '\r':
+			l.pos = i
+			l.SyntaxError("Unterminated string.")
+		}
 	}
+	l.pos = len(l.source)
+	l.SyntaxError("Unterminated string.")
+}
 
-	for {
-		next := l.scan.Next()
-		if next == '\r' || next == '\n' || next == scanner.EOF {
-			break
+// scanBlockString scans a block string, whose value is the one of blockStringValue.
+func (l *lexer) scanBlockString() {
+	body := l.pos + len(`"""`)
+	escaped := false
+	for i := body; i < len(l.source); i++ {
+		switch {
+		case l.source[i] == '\\' && strings.HasPrefix(l.source[i+1:], `"""`):
+			// \""" is the only escape sequence a block string knows about
+			escaped = true
+			i += len(`"""`)
+		case l.source[i] == '"' && strings.HasPrefix(l.source[i:], `"""`):
+			raw := l.source[body:i]
+			if escaped {
+				raw = strings.ReplaceAll(raw, `\"""`, `"""`)
+			}
+			l.blockString = blockStringValue(raw)
+			l.advanceLines(i + len(`"""`))
+			l.next = token.BLOCK_STRING
+			return
+		}
+	}
+	l.SyntaxError("Unterminated string.")
+}
+
+// advanceLines moves pos to end, counting the lines in between.
+func (l *lexer) advanceLines(end int) {
+	for i := l.pos; i < end; i++ {
+		if c := l.source[i]; c == '\n' || c == '\r' && !strings.HasPrefix(l.source[i:], "\r\n") {
+			l.line, l.lineStart = l.line+1, i+1
+		}
+	}
+	l.pos = end
+}
+
+// blockStringValue implements the BlockStringValue algorithm of the spec:
+// it removes the common indentation and the leading and trailing blank lines.
+func blockStringValue(raw string) string {
+	if strings.IndexByte(raw, '\r') >= 0 {
+		raw = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(raw)
+	}
+	lines := strings.Split(raw, "\n")
+
+	commonIndent := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (commonIndent < 0 || indent < commonIndent) {
+			commonIndent = indent
+		}
+	}
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= commonIndent {
+				lines[i] = lines[i][commonIndent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l *lexer) skipComment() {
+	for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+		l.pos++
+	}
+}
+
+// skipGoComment skips the // or /* */ comment at pos, it reports false when there is none.
+func (l *lexer) skipGoComment() bool {
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "//"):
+		l.skipComment()
+	case strings.HasPrefix(l.source[l.pos:], "/*"):
+		end := strings.Index(l.source[l.pos+2:], "*/")
+		if end < 0 {
+			l.advanceLines(len(l.source))
+		} else {
+			l.advanceLines(l.pos + 2 + end + 2)
 		}
-		l.comment.WriteRune(next)
+	default:
+		return false
 	}
+	return true
 }
 
 // If the next token is of the given kind, advance and skip whitespace.
 // Otherwise, do not change the parser state and return error.
 func (l *lexer) advance(expected rune) {
 	if l.next != expected {
-		found := strings.TrimPrefix(l.scan.TokenText(), `"`)
+		found := strings.TrimPrefix(l.text(), `"`)
 		found = strings.TrimSuffix(found, `"`)
 		l.SyntaxError(fmt.Sprintf(`Expected %s, found %q.`, scanner.TokenString(expected), found))
 	}
@@ -111,8 +360,8 @@ func (l *lexer) advance(expected rune) {
 // If the next token is of the given kind, advance and skip whitespace.
 // Otherwise, do not change the parser state and return error.
 func (l *lexer) advanceKeyWord(keyword string) {
-	if l.next != token.NAME || l.scan.TokenText() != keyword {
-		found := strings.TrimPrefix(l.scan.TokenText(), `"`)
+	if l.next != token.NAME || l.text() != keyword {
+		found := strings.TrimPrefix(l.text(), `"`)
 		found = strings.TrimSuffix(found, `"`)
 		l.SyntaxError(fmt.Sprintf(`Expected "%s", found %q.`, keyword, found))
 	}
@@ -0,0 +1,88 @@
+package internal
+
+import "container/list"
+
+// LRU keeps the values of Size keys at most, evicting the least recently used key to add one to a full cache. A Size
+// of zero keeps no value. Its zero value is usable, it is not safe for concurrent use: the caches wrapping it hold
+// their lock around it.
+type LRU[K comparable, V any] struct {
+	Size int
+
+	entries map[K]*list.Element
+	// order lists the entries from the most to the least recently used
+	order *list.List
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewLRU returns an LRU of size keys at most.
+func NewLRU[K comparable, V any](size int) *LRU[K, V] {
+	return &LRU[K, V]{Size: size}
+}
+
+// Get returns the value of key, marking it as the most recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+// Contains reports whether key has a value, without marking it as used.
+func (c *LRU[K, V]) Contains(key K) bool {
+	_, ok := c.entries[key]
+	return ok
+}
+
+// Add sets the value of key, marking it as the most recently used, and returns the number of keys evicted to keep
+// Size keys at most.
+func (c *LRU[K, V]) Add(key K, value V) (evicted int) {
+	if c.Size <= 0 {
+		return 0
+	}
+	if c.entries == nil {
+		c.entries = make(map[K]*list.Element)
+		c.order = list.New()
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	}
+	for c.order.Len() > c.Size {
+		c.Remove(c.order.Back().Value.(*lruEntry[K, V]).key)
+		evicted++
+	}
+	return evicted
+}
+
+// Oldest returns the least recently used key and its value.
+func (c *LRU[K, V]) Oldest() (K, V, bool) {
+	if c.order == nil || c.order.Len() == 0 {
+		var key K
+		var value V
+		return key, value, false
+	}
+	entry := c.order.Back().Value.(*lruEntry[K, V])
+	return entry.key, entry.value, true
+}
+
+// Remove drops the value of key.
+func (c *LRU[K, V]) Remove(key K) {
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of keys with a value.
+func (c *LRU[K, V]) Len() int {
+	return len(c.entries)
+}
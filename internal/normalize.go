@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/token"
+)
+
+// NormalizeQuery returns source without its comments and its insignificant whitespace, its string literals being
+// replaced by "" and its numbers by 0, so that the queries differing by their layout or their inline values are
+// the same and do not leak the values. A query which can not be lexed has its whitespace collapsed only.
+func NormalizeQuery(source string) string {
+	var b strings.Builder
+	l := NewLexer(source)
+	// the names and values following one another are separated by a space
+	word := false
+	err := l.catchSyntaxError(func() {
+		l.SkipWhitespace()
+		for l.next != token.EOF {
+			text := l.text()
+			isWord := false
+			switch l.next {
+			case token.STRING, token.BLOCK_STRING:
+				text, isWord = `""`, true
+			case token.INT, token.FLOAT:
+				text, isWord = "0", true
+			case token.NAME:
+				isWord = true
+			}
+			if word && isWord {
+				b.WriteByte(' ')
+			}
+			b.WriteString(text)
+			word = isWord
+			l.SkipWhitespace()
+		}
+	})
+	if err != nil {
+		return strings.Join(strings.Fields(source), " ")
+	}
+	return b.String()
+}
+
+// FindComment returns the location of the first comment of source, false when it has none or can not be lexed.
+// The comments are what the lexer skips between the tokens besides the whitespace.
+func FindComment(source string) (errors.Location, bool) {
+	l := NewLexer(source)
+	var loc errors.Location
+	found := false
+	l.catchSyntaxError(func() {
+		end := l.pos
+		for {
+			l.SkipWhitespace()
+			if i := strings.IndexAny(source[end:l.start], "#/"); i >= 0 {
+				offset := end + i
+				lineStart := strings.LastIndexByte(source[:offset], '\n') + 1
+				loc = errors.Location{
+					Line:   strings.Count(source[:offset], "\n") + 1,
+					Column: utf8.RuneCountInString(source[lineStart:offset]) + 1,
+				}
+				found = true
+				return
+			}
+			if l.next == token.EOF {
+				return
+			}
+			end = l.end
+		}
+	})
+	return loc, found
+}
@@ -11,7 +11,10 @@ import (
 	"text/scanner"
 )
 
-func Parse(source string) (*Document, error) {
+// Parse parses the executable document of source. The errors are nil when it succeeds, like the ones of the
+// validation and of the execution, and hold a single error otherwise: the parser stops at the first syntax error,
+// see ParseDocument.
+func Parse(source string) (*Document, errors.MultiError) {
 	doc, err := ParseDocument(source)
 	if err != nil {
 		return nil, err
@@ -32,9 +35,13 @@ func Parse(source string) (*Document, error) {
 	}, nil
 }
 
-func ParseDocument(source string) (*ast.Document, *errors.GraphQLError) {
+// ParseDocument parses the document of source, executable or not. The parser stops at the first syntax error.
+func ParseDocument(source string) (*ast.Document, errors.MultiError) {
 	if source == "" {
-		return nil, errors.New("Must provide source. Received: undefined.")
+		err := errors.New("Must provide source. Received: undefined.")
+		err.Kind = errors.KindSyntax
+		err.WithCode(errors.CodeParseFailed)
+		return nil, errors.MultiError{err}
 	}
 	l := NewLexer(source, false)
 
@@ -43,11 +50,27 @@ func ParseDocument(source string) (*ast.Document, *errors.GraphQLError) {
 		doc = parseDocument(l)
 	})
 	if err != nil {
-		return nil, err
+		return nil, errors.MultiError{err}
 	}
 	return doc, nil
 }
 
+// ParseValue parses the source of a constant value, eg. the default value of an argument printed by the
+// introspection.
+func ParseValue(source string) (ast.Value, *errors.GraphQLError) {
+	l := NewLexer(source, false)
+	var value ast.Value
+	err := l.catchSyntaxError(func() {
+		l.SkipWhitespace()
+		value = ParseValueLiteral(l, true)
+		l.advance(token.EOF)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
 func parseDocument(l *lexer) *ast.Document {
 	doc := &ast.Document{Kind: kinds.Document, Loc: l.location()}
 	l.SkipWhitespace()
@@ -59,22 +82,45 @@ func parseDocument(l *lexer) *ast.Document {
 			continue
 		}
 
+		var desc *ast.StringValue
+		if l.peek() == token.STRING || l.peek() == token.BLOCK_STRING {
+			desc = parseDescription(l)
+		}
+
 		loc := l.location()
-		switch name := parseName(l); name.Name {
+		name := parseName(l)
+		if desc != nil {
+			if definition := parseTypeSystemDefinition(l, name, desc); definition != nil {
+				doc.Definition = append(doc.Definition, definition)
+				continue
+			}
+			l.SyntaxError(fmt.Sprintf(`Unexpected description before %q.`, name.Name))
+		}
+		switch name.Name {
 		case "query":
 			definition := parseOperationDefinition(l, ast.Query)
 			definition.Loc = loc
 			doc.Definition = append(doc.Definition, definition)
 		case "mutation":
-			doc.Definition = append(doc.Definition, parseOperationDefinition(l, ast.Mutation))
+			definition := parseOperationDefinition(l, ast.Mutation)
+			definition.Loc = loc
+			doc.Definition = append(doc.Definition, definition)
 		case "subscription":
-			doc.Definition = append(doc.Definition, parseOperationDefinition(l, ast.Mutation))
+			definition := parseOperationDefinition(l, ast.Subscription)
+			definition.Loc = loc
+			doc.Definition = append(doc.Definition, definition)
 		case "fragment":
 			fragment := parseFragmentDefinition(l)
 			fragment.Loc = loc
 			doc.Definition = append(doc.Definition, fragment)
+		case "extend":
+			doc.Definition = append(doc.Definition, parseTypeSystemExtension(l, loc))
 		default:
-			l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, name.Name))
+			definition := parseTypeSystemDefinition(l, name, nil)
+			if definition == nil {
+				l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, name.Name))
+			}
+			doc.Definition = append(doc.Definition, definition)
 		}
 	}
 	return doc
@@ -104,12 +150,12 @@ func parseFragmentDefinition(l *lexer) *ast.FragmentDefinition {
 // Name : but not `on`
 func parseFragmentName(l *lexer) *ast.Name {
 	loc := l.location()
-	name := l.scan.TokenText()
+	name := l.text()
 	if name == "on" {
 		panic(syntaxError(`Unexpected Name "on".`))
 	}
 	l.advance(token.NAME)
-	return &ast.Name{Kind: kinds.Name, Name: name, Loc: loc}
+	return l.nodes.names.new(ast.Name{Kind: kinds.Name, Name: name, Loc: loc})
 }
 
 func parseOperationDefinition(l *lexer, opType ast.OperationType) *ast.OperationDefinition {
@@ -199,9 +245,9 @@ func ParseType(l *lexer) ast.Type {
 // Converts a name lex token into a name parse node.
 func parseName(l *lexer) *ast.Name {
 	loc := l.location()
-	name := l.scan.TokenText()
+	name := l.text()
 	l.advance(token.NAME)
-	return &ast.Name{Kind: kinds.Name, Name: name, Loc: loc}
+	return l.nodes.names.new(ast.Name{Kind: kinds.Name, Name: name, Loc: loc})
 }
 
 /**
@@ -223,11 +269,11 @@ func parseSelectionSet(l *lexer) *ast.SelectionSet {
 		selections = append(selections, parseSelection(l))
 	}
 	l.advance(token.BRACE_R)
-	return &ast.SelectionSet{
+	return l.nodes.selectionSets.new(ast.SelectionSet{
 		Kind:       kinds.SelectionSet,
 		Selections: selections,
 		Loc:        loc,
-	}
+	})
 }
 
 /**
@@ -287,27 +333,31 @@ func ParseValueLiteral(l *lexer, constOnly bool) ast.Value {
 			return parseVariable(l)
 		}
 	case token.INT:
-		value := l.scan.TokenText()
+		value := l.text()
 		l.advance(token.INT)
 		return &ast.IntValue{Kind: kinds.IntValue, Value: value, Loc: loc}
 	case token.FLOAT:
-		value := l.scan.TokenText()
+		value := l.text()
 		l.advance(token.FLOAT)
 		return &ast.FloatValue{Kind: kinds.FloatValue, Value: value, Loc: loc}
 	case token.STRING:
-		value := l.scan.TokenText()
+		value := l.text()
 		value = strings.TrimPrefix(value, `"`)
 		value = strings.TrimSuffix(value, `"`)
 		l.advance(token.STRING)
 		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Loc: loc}
+	case token.BLOCK_STRING:
+		value := l.blockString
+		l.advance(token.BLOCK_STRING)
+		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Block: true, Loc: loc}
 	case token.RAWSTRING:
-		value := l.scan.TokenText()
+		value := l.text()
 		value = strings.TrimPrefix(value, "`")
 		value = strings.TrimSuffix(value, "`")
 		l.advance(token.RAWSTRING)
-		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Loc: loc}
+		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Block: true, Loc: loc}
 	case token.NAME:
-		tokenText := l.scan.TokenText()
+		tokenText := l.text()
 		l.advance(token.NAME)
 		if tokenText == "true" || tokenText == "false" {
 			value := false
@@ -382,7 +432,7 @@ func parseVariable(l *lexer) *ast.Variable {
  * Alias : Name :
  */
 func parseField(l *lexer) *ast.Field {
-	field := &ast.Field{Kind: kinds.Field, Loc: l.location()}
+	field := l.nodes.fields.new(ast.Field{Kind: kinds.Field, Loc: l.location()})
 	field.Alias = parseName(l)
 	field.Name = field.Alias
 	if l.peek() == token.COLON {
@@ -459,6 +509,357 @@ func parseDirective(l *lexer) *ast.Directive {
 	return directive
 }
 
+/**
+ * Description : StringValue
+ */
+func parseDescription(l *lexer) *ast.StringValue {
+	return ParseValueLiteral(l, true).(*ast.StringValue)
+}
+
+func parseOptionalDescription(l *lexer) *ast.StringValue {
+	if l.peek() == token.STRING || l.peek() == token.BLOCK_STRING {
+		return parseDescription(l)
+	}
+	return nil
+}
+
+/**
+ * TypeSystemDefinition :
+ *   - SchemaDefinition
+ *   - TypeDefinition
+ *   - DirectiveDefinition
+ *
+ * The keyword has already been consumed, nil is returned if it does not start a type system definition.
+ */
+func parseTypeSystemDefinition(l *lexer, keyword *ast.Name, desc *ast.StringValue) ast.Definition {
+	loc := keyword.Loc
+	switch keyword.Name {
+	case "schema":
+		return &ast.SchemaDefinition{
+			Kind:           kinds.SchemaDefinition,
+			Desc:           desc,
+			Directives:     parseDirectives(l),
+			OperationTypes: parseOperationTypeDefinitions(l),
+			Loc:            loc,
+		}
+	case "scalar":
+		return &ast.ScalarDefinition{
+			Kind:       kinds.ScalarDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseDirectives(l),
+			Loc:        loc,
+		}
+	case "type":
+		return &ast.ObjectDefinition{
+			Kind:       kinds.ObjectDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case "interface":
+		return &ast.InterfaceDefinition{
+			Kind:       kinds.InterfaceDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case "union":
+		return &ast.UnionDefinition{
+			Kind:       kinds.UnionDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseDirectives(l),
+			Members:    parseUnionMemberTypes(l),
+			Loc:        loc,
+		}
+	case "enum":
+		return &ast.EnumDefinition{
+			Kind:       kinds.EnumDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseDirectives(l),
+			Values:     parseEnumValuesDefinition(l),
+			Loc:        loc,
+		}
+	case "input":
+		return &ast.InputObjectDefinition{
+			Kind:        kinds.InputObjectDefinition,
+			Desc:        desc,
+			Name:        parseName(l),
+			Directives:  parseDirectives(l),
+			InputFields: parseInputFieldsDefinition(l),
+			Loc:         loc,
+		}
+	case "directive":
+		definition := parseDirectiveDefinition(l)
+		definition.Desc = desc
+		definition.Loc = loc
+		return definition
+	}
+	return nil
+}
+
+/**
+ * TypeSystemExtension :
+ *   - SchemaExtension
+ *   - TypeExtension
+ */
+func parseTypeSystemExtension(l *lexer, loc errors.Location) ast.Definition {
+	switch keyword := parseName(l); keyword.Name {
+	case "schema":
+		extension := &ast.SchemaExtension{Directives: parseDirectives(l), Loc: loc}
+		if l.peek() == token.BRACE_L {
+			extension.RootOperation = parseOperationTypeDefinitions(l)
+		}
+		return extension
+	case "scalar":
+		return &ast.ScalarExtension{Name: parseName(l), Directives: parseDirectives(l), Loc: loc}
+	case "type":
+		return &ast.ObjectExtension{
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case "interface":
+		return &ast.InterfaceExtension{
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case "union":
+		return &ast.UnionExtension{
+			Name:       parseName(l),
+			Directives: parseDirectives(l),
+			Members:    parseUnionMemberTypes(l),
+			Loc:        loc,
+		}
+	case "enum":
+		return &ast.EnumExtension{
+			Name:       parseName(l),
+			Directives: parseDirectives(l),
+			Values:     parseEnumValuesDefinition(l),
+			Loc:        loc,
+		}
+	case "input":
+		return &ast.InputObjectExtension{
+			Name:        parseName(l),
+			Directives:  parseDirectives(l),
+			InputFields: parseInputFieldsDefinition(l),
+			Loc:         loc,
+		}
+	default:
+		l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, keyword.Name))
+	}
+	return nil
+}
+
+/**
+ * OperationTypeDefinitions : { OperationTypeDefinition+ }
+ *
+ * OperationTypeDefinition : OperationType : NamedType
+ */
+func parseOperationTypeDefinitions(l *lexer) []*ast.OperationTypeDefinition {
+	var operationTypes []*ast.OperationTypeDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		loc := l.location()
+		operation := parseName(l)
+		var opType ast.OperationType
+		switch operation.Name {
+		case "query":
+			opType = ast.Query
+		case "mutation":
+			opType = ast.Mutation
+		case "subscription":
+			opType = ast.Subscription
+		default:
+			l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, operation.Name))
+		}
+		l.advance(token.COLON)
+		operationTypes = append(operationTypes, &ast.OperationTypeDefinition{
+			Kind:      kinds.OperationTypeDefinition,
+			Operation: opType,
+			Type:      parseNamed(l),
+			Loc:       loc,
+		})
+	}
+	l.advance(token.BRACE_R)
+	return operationTypes
+}
+
+/**
+ * ImplementsInterfaces : implements &? NamedType (& NamedType)*
+ */
+func parseImplementsInterfaces(l *lexer) []*ast.Named {
+	if l.peek() != token.NAME || l.text() != "implements" {
+		return nil
+	}
+	l.advanceKeyWord("implements")
+	if l.peek() == token.AMP {
+		l.advance(token.AMP)
+	}
+	interfaces := []*ast.Named{parseNamed(l)}
+	for l.peek() == token.AMP {
+		l.advance(token.AMP)
+		interfaces = append(interfaces, parseNamed(l))
+	}
+	return interfaces
+}
+
+/**
+ * FieldsDefinition : { FieldDefinition+ }
+ *
+ * FieldDefinition : Description? Name ArgumentsDefinition? : Type Directives?
+ */
+func parseFieldsDefinition(l *lexer) []*ast.FieldDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var fields []*ast.FieldDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		field := &ast.FieldDefinition{Kind: kinds.FieldDefinition, Desc: parseOptionalDescription(l), Loc: l.location()}
+		field.Name = parseName(l)
+		field.Argument = parseArgumentsDefinition(l)
+		l.advance(token.COLON)
+		field.Type = ParseType(l)
+		field.Directives = parseDirectives(l)
+		fields = append(fields, field)
+	}
+	l.advance(token.BRACE_R)
+	return fields
+}
+
+/**
+ * ArgumentsDefinition : ( InputValueDefinition+ )
+ */
+func parseArgumentsDefinition(l *lexer) []*ast.InputValueDefinition {
+	if l.peek() != token.PAREN_L {
+		return nil
+	}
+	var args []*ast.InputValueDefinition
+	l.advance(token.PAREN_L)
+	for l.peek() != token.PAREN_R {
+		args = append(args, parseInputValueDefinition(l))
+	}
+	l.advance(token.PAREN_R)
+	return args
+}
+
+/**
+ * InputValueDefinition : Description? Name : Type DefaultValue? Directives?
+ */
+func parseInputValueDefinition(l *lexer) *ast.InputValueDefinition {
+	value := &ast.InputValueDefinition{Kind: kinds.InputValueDefinition, Desc: parseOptionalDescription(l), Loc: l.location()}
+	value.Name = parseName(l)
+	l.advance(token.COLON)
+	value.Type = ParseType(l)
+	if l.peek() == token.EQUALS {
+		l.advance(token.EQUALS)
+		value.DefaultValue = ParseValueLiteral(l, true)
+	}
+	value.Directives = parseDirectives(l)
+	return value
+}
+
+/**
+ * UnionMemberTypes : = |? NamedType (| NamedType)*
+ */
+func parseUnionMemberTypes(l *lexer) []*ast.Named {
+	if l.peek() != token.EQUALS {
+		return nil
+	}
+	l.advance(token.EQUALS)
+	if l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+	}
+	members := []*ast.Named{parseNamed(l)}
+	for l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+		members = append(members, parseNamed(l))
+	}
+	return members
+}
+
+/**
+ * EnumValuesDefinition : { EnumValueDefinition+ }
+ *
+ * EnumValueDefinition : Description? EnumValue Directives?
+ */
+func parseEnumValuesDefinition(l *lexer) []*ast.EnumValueDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var values []*ast.EnumValueDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		value := &ast.EnumValueDefinition{Kind: kinds.EnumValueDefinition, Desc: parseOptionalDescription(l), Loc: l.location()}
+		name := parseName(l)
+		switch name.Name {
+		case "true", "false", "null":
+			l.SyntaxError(fmt.Sprintf(`Name %q is reserved and cannot be used for an enum value.`, name.Name))
+		}
+		value.Value = &ast.EnumValue{Kind: kinds.EnumValue, Value: name.Name, Loc: name.Loc}
+		value.Directives = parseDirectives(l)
+		values = append(values, value)
+	}
+	l.advance(token.BRACE_R)
+	return values
+}
+
+/**
+ * InputFieldsDefinition : { InputValueDefinition+ }
+ */
+func parseInputFieldsDefinition(l *lexer) []*ast.InputValueDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var fields []*ast.InputValueDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		fields = append(fields, parseInputValueDefinition(l))
+	}
+	l.advance(token.BRACE_R)
+	return fields
+}
+
+/**
+ * DirectiveDefinition : Description? directive @ Name ArgumentsDefinition? repeatable? on DirectiveLocations
+ *
+ * DirectiveLocations : |? Name (| Name)*
+ */
+func parseDirectiveDefinition(l *lexer) *ast.DirectiveDefinition {
+	l.advance(token.AT)
+	definition := &ast.DirectiveDefinition{Kind: kinds.DirectiveDefinition}
+	definition.Name = parseName(l)
+	definition.Arguments = parseArgumentsDefinition(l)
+	if l.peek() == token.NAME && l.text() == "repeatable" {
+		l.advanceKeyWord("repeatable")
+		definition.Repeatable = true
+	}
+	l.advanceKeyWord("on")
+	if l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+	}
+	definition.Locations = append(definition.Locations, parseName(l).Name)
+	for l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+		definition.Locations = append(definition.Locations, parseName(l).Name)
+	}
+	return definition
+}
+
 func ValueToJson(value ast.Value, vars map[string]interface{}) (interface{}, *errors.GraphQLError) {
 	switch value := value.(type) {
 	case *ast.IntValue:
@@ -11,93 +11,113 @@ import (
 	"testing"
 )
 
-var NilGraphQLError *errors.GraphQLError
-
 func TestParser(t *testing.T) {
 	t.Run("asserts that a source to parse was provided", func(t *testing.T) {
 		_, err := internal.ParseDocument("")
-		assert.EqualError(t, err, "graphql: Must provide source. Received: undefined.")
+		assert.EqualError(t, err, "[graphql: Must provide source. Received: undefined.]")
 	})
 
 	t.Run("parse provides useful errors", func(t *testing.T) {
 		_, err := internal.ParseDocument("{")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Expected Ident, found "".`,
 			Locations: []errors.Location{{1, 2}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument(`
       { ...MissingOn }
       fragment MissingOn Operation
     `)
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Expected "on", found "Operation".`,
 			Locations: []errors.Location{{3, 26}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument("{ field: {} }")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Expected Ident, found "{".`,
 			Locations: []errors.Location{{1, 10}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument("notAnOperation Foo { field }")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Unexpected "notAnOperation".`,
 			Locations: []errors.Location{{1, 16}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument("...")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Expected Ident, found ".".`,
 			Locations: []errors.Location{{1, 1}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument(`{ ""`)
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   fmt.Sprintf(`Syntax Error: Expected Ident, found "".`),
 			Locations: []errors.Location{{1, 3}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 
 		_, err = internal.ParseDocument("query")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   `Syntax Error: Expected "{", found "".`,
 			Locations: []errors.Location{{1, 6}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
+	})
+
+	t.Run("counts the lines broken by \\r, \\n and \\r\\n", func(t *testing.T) {
+		_, err := internal.ParseDocument("{\r  a\r\n  b\n  ...\r}")
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, []errors.Location{{5, 1}}, err[0].Locations)
+		}
+
+		_, err = internal.ParseDocument("{ a(b: \"\"\"\r\r\n\"\"\") ...\r}")
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, []errors.Location{{4, 1}}, err[0].Locations)
+		}
 	})
 
 	t.Run("parses variable inline values", func(t *testing.T) {
 		_, err := internal.ParseDocument("{ field(complex: { a: { b: [ $var ] } }) }")
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("parses constant default values", func(t *testing.T) {
 		_, err := internal.ParseDocument("query Foo($x: Complex = { a: { b: [ $var ] } }) { field }")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   fmt.Sprintf(`Syntax Error: Unexpected %q.`, `"$"`),
 			Locations: []errors.Location{{1, 37}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 	})
 
 	t.Run("parses variable definition directives", func(t *testing.T) {
 		_, err := internal.ParseDocument("query Foo($x: Boolean = false @bar) { field }")
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run(`does not accept fragments named "on"`, func(t *testing.T) {
 		_, err := internal.ParseDocument("fragment on on on { on }")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   fmt.Sprintf(`Syntax Error: Unexpected Name "on".`),
 			Locations: []errors.Location{{1, 10}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 	})
 
 	t.Run(`oes not accept fragments spread of "on"`, func(t *testing.T) {
 		_, err := internal.ParseDocument("{ ...on }")
-		assert.Equal(t, &errors.GraphQLError{
+		assert.Equal(t, errors.MultiError{{
 			Message:   fmt.Sprintf(`Syntax Error: Expected Ident, found "}".`),
 			Locations: []errors.Location{{1, 9}},
-		}, err)
+			Kind:      errors.KindSyntax,
+		}}, err)
 	})
 
 	t.Run(`parses multi-byte characters`, func(t *testing.T) {
@@ -105,14 +125,14 @@ func TestParser(t *testing.T) {
       # This comment has a \u0A0A multi-byte character.
       { field(arg: "Has a \u0A0A multi-byte character.") }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 		assert.Equal(t, `Has a \u0A0A multi-byte character.`, doc.Definition[0].(*ast.OperationDefinition).SelectionSet.
 			Selections[0].(*ast.Field).Arguments[0].Value.GetValue())
 	})
 
 	t.Run("parses kitchen sink", func(t *testing.T) {
 		_, err := internal.ParseDocument(string(__test__.KitchenSinkQuery))
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("allows non-keywords anywhere a Name is allowed", func(t *testing.T) {
@@ -133,7 +153,7 @@ func TestParser(t *testing.T) {
         }
       `, keyword, fragmentName, keyword, fragmentName, keyword, keyword, keyword, keyword, keyword, keyword)
 			_, err := internal.ParseDocument(document)
-			assert.Equal(t, NilGraphQLError, err)
+			assert.Nil(t, err)
 		}
 	})
 
@@ -143,7 +163,7 @@ func TestParser(t *testing.T) {
         mutationField
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("parses anonymous subscription operations", func(t *testing.T) {
@@ -152,7 +172,7 @@ func TestParser(t *testing.T) {
         subscriptionField
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("parses named mutation operations", func(t *testing.T) {
@@ -161,7 +181,7 @@ func TestParser(t *testing.T) {
         mutationField
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("parses named subscription operations", func(t *testing.T) {
@@ -170,7 +190,7 @@ func TestParser(t *testing.T) {
         subscriptionField
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 	})
 
 	t.Run("creates ast", func(t *testing.T) {
@@ -182,7 +202,7 @@ func TestParser(t *testing.T) {
         }
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 		assert.Equal(t, &ast.Document{
 			Kind: kinds.Document,
 			Definition: []ast.Definition{
@@ -274,7 +294,7 @@ func TestParser(t *testing.T) {
         }
       }
     `)
-		assert.Equal(t, NilGraphQLError, err)
+		assert.Nil(t, err)
 		assert.Equal(t, &ast.Document{
 			Kind: kinds.Document,
 			Loc:  errors.Location{0, 0},
@@ -358,6 +378,31 @@ func TestParseValueLiteral(t *testing.T) {
 			},
 		}, literal)
 	})
+
+	t.Run("parses numbers and block strings", func(t *testing.T) {
+		lexer := internal.NewLexer("[-12, 1.5e-3 \"\"\"\n  a \\\"\"\"\n\"\"\"]")
+		lexer.SkipWhitespace()
+		literal := internal.ParseValueLiteral(lexer, false)
+		assert.Equal(t, &ast.ListValue{
+			Kind: kinds.ListValue,
+			Loc:  errors.Location{1, 1},
+			Values: []ast.Value{
+				&ast.IntValue{Kind: kinds.IntValue, Loc: errors.Location{1, 2}, Value: "-12"},
+				&ast.FloatValue{Kind: kinds.FloatValue, Loc: errors.Location{1, 7}, Value: "1.5e-3"},
+				&ast.StringValue{Kind: kinds.StringValue, Loc: errors.Location{1, 14}, Value: `a """`, Block: true},
+			},
+		}, literal)
+	})
+
+	t.Run("rejects unterminated strings", func(t *testing.T) {
+		_, err := internal.ParseValue(`"abc`)
+		assert.Equal(t, &errors.GraphQLError{
+			Message:    "Syntax Error: Unterminated string.",
+			Locations:  []errors.Location{{1, 1}},
+			Kind:       errors.KindSyntax,
+			Extensions: map[string]interface{}{"code": errors.CodeParseFailed},
+		}, err)
+	})
 }
 
 func TestParseType(t *testing.T) {
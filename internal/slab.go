@@ -0,0 +1,32 @@
+package internal
+
+import "github.com/shyptr/graphql/ast"
+
+// slab allocates the nodes of a type by chunks, so that parsing a document allocates a few chunks instead of every
+// node. The nodes of a chunk are freed together, once none of them is referenced, ie. once the request which parsed
+// the document completes unless the document is cached.
+type slab[T any] []T
+
+// the chunks grow by half with the documents, from minChunk to maxChunk nodes
+const (
+	minChunk = 8
+	maxChunk = 512
+)
+
+// new returns a node of the slab set to node.
+func (s *slab[T]) new(node T) *T {
+	if len(*s) == cap(*s) {
+		*s = make([]T, 0, min(max(cap(*s)+cap(*s)/2, minChunk), maxChunk))
+	}
+	*s = append(*s, node)
+	return &(*s)[len(*s)-1]
+}
+
+// nodes are the slabs of the most numerous nodes of the executable documents, the names, the fields and the
+// selection sets. The other nodes are allocated one by one: their chunks would be mostly unused, and the unused
+// nodes of a chunk are garbage collected along with the used ones, see BenchmarkParseDocumentGC.
+type nodes struct {
+	names         slab[ast.Name]
+	fields        slab[ast.Field]
+	selectionSets slab[ast.SelectionSet]
+}
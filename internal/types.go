@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/errors"
+	"time"
 )
 
 // Operation corresponds to GraphQLType
@@ -40,11 +41,14 @@ var _ NamedType = (*Union)(nil)
 // The leaf values of any request and input values to arguments are Scalars (or Enums)
 // and are defined with a name and a series of serialization functions used to ensure validity.
 type Scalar struct {
-	Name         string                                 `json:"name"`
-	Desc         string                                 `json:"description"`
-	Serialize    func(interface{}) (interface{}, error) `json:"-"`
-	ParseValue   func(interface{}) (interface{}, error) `json:"-"`
-	ParseLiteral func(value ast.Value) error            `json:"-"`
+	Name       string                                 `json:"name"`
+	Desc       string                                 `json:"description"`
+	Serialize  func(interface{}) (interface{}, error) `json:"-"`
+	ParseValue func(interface{}) (interface{}, error) `json:"-"`
+	// ParseLiteral parses the inline literals of the scalar, eg. the object literals of a JSON scalar, into their
+	// Go values. The literals referencing variables, and all the literals when it is nil, are converted to JSON
+	// and parsed with ParseValue, as the values of the variables are.
+	ParseLiteral func(value ast.Value) (interface{}, error) `json:"-"`
 }
 
 // Almost all of the GraphQL types you define will be object types.
@@ -55,6 +59,8 @@ type Object struct {
 	Interfaces map[string]*Interface `json:"interfaces"`
 	Fields     map[string]*Field     `json:"fields"`
 	IsTypeOf   interface{}           `json:"-"`
+	// Extensions are the metadata of the object for the tooling, eg. its tags or its owner, see OwnerExtension
+	Extensions map[string]interface{} `json:"-"`
 }
 
 // When a field can return one of a heterogeneous set of types,
@@ -91,6 +97,8 @@ type Enum struct {
 	ReverseMap map[string]interface{} `json:"-"`
 	Map        map[interface{}]string `json:"-"`
 	Desc       string                 `json:"description"`
+	// ValuesDeprecation are the deprecation reasons of the deprecated values
+	ValuesDeprecation map[string]string `json:"-"`
 }
 
 // An input object defines a structured collection of fields which may be supplied to a field argument.
@@ -100,6 +108,8 @@ type InputObject struct {
 	Name   string                 `json:"name"`
 	Fields map[string]*InputField `json:"fields"`
 	Desc   string                 `json:"description"`
+	// OneOf tells that exactly one of the fields, which are nullable, is provided and non-null, as @oneOf does
+	OneOf bool `json:"-"`
 }
 
 // A list is a kind of type marker, a wrapping type which points to another type.
@@ -153,6 +163,16 @@ type TypeResolve func(ctx context.Context, value interface{}) *Object
 
 type FieldResolve func(ctx context.Context, source, args interface{}) (interface{}, error)
 
+// Thunk is the value of a field which is computed later, the executor calls it after the fields it has
+// already started, so a resolver returning a thunk can have its loads batched with those of its siblings.
+type Thunk func() (interface{}, error)
+
+// SourceStream is the stream of events a subscription field resolves to, see schemabuilder.SourceStream.
+type SourceStream interface {
+	Next(ctx context.Context) (interface{}, error)
+	Close()
+}
+
 //type HandlerFunc func(ctx context.Context) error
 
 type Field struct {
@@ -161,15 +181,53 @@ type Field struct {
 	Args    map[string]*InputField `json:"arguments"`
 	Resolve FieldResolve           `json:"-"`
 	Desc    string                 `json:"desc"`
+	// Timeout bounds the resolver calls of the field, zero means the timeout of the executor is used
+	Timeout time.Duration `json:"-"`
+	// ErrorPolicy tells what becomes of the field when it fails, zero means the policy of the executor is used
+	ErrorPolicy ErrorPolicy `json:"-"`
+	// Filter, on a subscription field, selects the events executed for a subscriber with the arguments args
+	Filter func(ctx context.Context, event, args interface{}) bool `json:"-"`
+	// DeprecationReason is not empty when the field is deprecated
+	DeprecationReason string `json:"-"`
+	// Extensions are the metadata of the field for the tooling, eg. its owner, see OwnerExtension
+	Extensions map[string]interface{} `json:"-"`
 }
 
+// OwnerExtension is the extension of the objects and of the fields naming the team owning them, the failures of
+// the fields are routed to their owner.
+const OwnerExtension = "owner"
+
+// Owner returns the team owning the field, see OwnerExtension.
+func (f *Field) Owner() string {
+	owner, _ := f.Extensions[OwnerExtension].(string)
+	return owner
+}
+
+// ErrorPolicy tells what becomes of a field which fails, eg. whose resolver returns an error.
+type ErrorPolicy int
+
+const (
+	// NullOnError makes the field null, or its failed items when it is a list of nullable items.
+	NullOnError ErrorPolicy = iota + 1
+	// PropagateError makes the field null when it is nullable, else its parent object, as the specification tells.
+	// The null parent is in turn handled with the policy of its own field.
+	PropagateError
+	// OmitOnError leaves the failed items out of the list of the field, the field is null when it fails as a whole.
+	OmitOnError
+)
+
 type InputField struct {
 	Name         string      `json:"name"`
 	Type         Type        `json:"type"`
 	Desc         string      `json:"description"`
 	DefaultValue interface{} `json:"defaultValue"`
+	// DeprecationReason is not empty when the argument is deprecated
+	DeprecationReason string `json:"-"`
 }
 
+// DefaultDeprecationReason is the reason of the elements deprecated without one, as @deprecated defaults it.
+const DefaultDeprecationReason = "No longer supported"
+
 //Schema used to validate and resolve the queries
 type Schema struct {
 	TypeMap      map[string]NamedType  `json:"-"`
@@ -238,6 +296,8 @@ type Selection struct {
 	SelectionSet *SelectionSet
 	Directives   []*Directive
 	Loc          errors.Location
+	// Arguments are the arguments as written in the document, the errors of their values are located with them
+	Arguments []*ast.Argument
 }
 
 // A FragmentDefinition represents a reusable part of a GraphQL query
@@ -250,6 +310,8 @@ type FragmentDefinition struct {
 	On           string
 	SelectionSet *SelectionSet
 	Loc          errors.Location
+	// Union is the type named by On when it is a union, the fragment applies to its members
+	Union *Union
 }
 
 // FragmentSpread represents a usage of a FragmentDefinition. Alongside the information
@@ -0,0 +1,362 @@
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// clientSchema is the result of the __schema field of an introspection query.
+type clientSchema struct {
+	QueryType        *clientTypeRef    `json:"queryType"`
+	MutationType     *clientTypeRef    `json:"mutationType"`
+	SubscriptionType *clientTypeRef    `json:"subscriptionType"`
+	Types            []clientType      `json:"types"`
+	Directives       []clientDirective `json:"directives"`
+}
+
+type clientTypeRef struct {
+	Kind   TypeKind       `json:"kind"`
+	Name   string         `json:"name"`
+	OfType *clientTypeRef `json:"ofType"`
+}
+
+type clientType struct {
+	Kind          TypeKind           `json:"kind"`
+	Name          string             `json:"name"`
+	Desc          *string            `json:"description"`
+	Fields        []clientField      `json:"fields"`
+	InputFields   []clientInputValue `json:"inputFields"`
+	Interfaces    []clientTypeRef    `json:"interfaces"`
+	PossibleTypes []clientTypeRef    `json:"possibleTypes"`
+	EnumValues    []clientEnumValue  `json:"enumValues"`
+	// IsOneOf is only returned by the servers supporting @oneOf, when it is queried
+	IsOneOf bool `json:"isOneOf"`
+}
+
+type clientField struct {
+	Name              string             `json:"name"`
+	Desc              *string            `json:"description"`
+	Args              []clientInputValue `json:"args"`
+	Type              *clientTypeRef     `json:"type"`
+	IsDeprecated      bool               `json:"isDeprecated"`
+	DeprecationReason *string            `json:"deprecationReason"`
+}
+
+type clientInputValue struct {
+	Name              string         `json:"name"`
+	Desc              *string        `json:"description"`
+	Type              *clientTypeRef `json:"type"`
+	DefaultValue      *string        `json:"defaultValue"`
+	IsDeprecated      bool           `json:"isDeprecated"`
+	DeprecationReason *string        `json:"deprecationReason"`
+}
+
+type clientEnumValue struct {
+	Name              string  `json:"name"`
+	Desc              *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type clientDirective struct {
+	Name      string              `json:"name"`
+	Desc      *string             `json:"description"`
+	Locations []DirectiveLocation `json:"locations"`
+	Args      []clientInputValue  `json:"args"`
+}
+
+// BuildClientSchema builds the schema described by the result of an introspection query, eg. IntrospectionQuery
+// run against a remote server, so that the queries sent to the server can be validated or planned offline.
+// result is the JSON of a response, the "data" member of the response being optional.
+//
+// The schema has no resolvers, it can not be executed. Its scalars other than the built-in ones accept any value.
+//
+//	schema, err := introspection.BuildClientSchema(response)
+//	errs := validation.Validate(schema, doc)
+func BuildClientSchema(result []byte) (*internal.Schema, error) {
+	var response struct {
+		Data *struct {
+			Schema *clientSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *clientSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %v", err)
+	}
+	schema := response.Schema
+	if response.Data != nil {
+		schema = response.Data.Schema
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("invalid introspection result: missing __schema")
+	}
+	b := &clientBuilder{types: make(map[string]internal.NamedType, len(schema.Types))}
+	return b.build(schema)
+}
+
+type clientBuilder struct {
+	types map[string]internal.NamedType
+}
+
+func (b *clientBuilder) build(schema *clientSchema) (*internal.Schema, error) {
+	// the types are declared first, as their fields refer to each other
+	for _, typ := range schema.Types {
+		if _, ok := b.types[typ.Name]; ok {
+			return nil, fmt.Errorf("duplicate type %s", typ.Name)
+		}
+		desc := stringValue(typ.Desc)
+		switch typ.Kind {
+		case SCALAR:
+			b.types[typ.Name] = &internal.Scalar{Name: typ.Name, Desc: desc}
+		case OBJECT:
+			b.types[typ.Name] = &internal.Object{Name: typ.Name, Desc: desc}
+		case INTERFACE:
+			b.types[typ.Name] = &internal.Interface{Name: typ.Name, Desc: desc}
+		case UNION:
+			b.types[typ.Name] = &internal.Union{Name: typ.Name, Desc: desc}
+		case ENUM:
+			b.types[typ.Name] = &internal.Enum{Name: typ.Name, Desc: desc}
+		case INPUT_OBJECT:
+			b.types[typ.Name] = &internal.InputObject{Name: typ.Name, Desc: desc}
+		default:
+			return nil, fmt.Errorf("type %s has unknown kind %s", typ.Name, typ.Kind)
+		}
+	}
+	for _, typ := range schema.Types {
+		if err := b.define(typ); err != nil {
+			return nil, fmt.Errorf("type %s: %v", typ.Name, err)
+		}
+	}
+
+	directives := make(map[string]*internal.Directive, len(schema.Directives))
+	for _, d := range schema.Directives {
+		args, err := b.inputFields(d.Args)
+		if err != nil {
+			return nil, fmt.Errorf("directive @%s: %v", d.Name, err)
+		}
+		locs := make([]string, len(d.Locations))
+		for i, loc := range d.Locations {
+			locs[i] = string(loc)
+		}
+		directives[d.Name] = &internal.Directive{Name: d.Name, Desc: stringValue(d.Desc), Args: args, Locs: locs}
+	}
+
+	if schema.QueryType == nil {
+		return nil, fmt.Errorf("invalid introspection result: missing query type")
+	}
+	query, err := b.rootType(schema.QueryType)
+	if err != nil {
+		return nil, err
+	}
+	b.addMetaFields(query)
+	result := &internal.Schema{TypeMap: b.types, Directives: directives, Query: query}
+	if schema.MutationType != nil {
+		if result.Mutation, err = b.rootType(schema.MutationType); err != nil {
+			return nil, err
+		}
+	}
+	if schema.SubscriptionType != nil {
+		if result.Subscription, err = b.rootType(schema.SubscriptionType); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// define sets the fields, values and members of typ, which is declared.
+func (b *clientBuilder) define(typ clientType) error {
+	var err error
+	switch named := b.types[typ.Name].(type) {
+	case *internal.Object:
+		if named.Fields, err = b.fields(typ.Fields); err != nil {
+			return err
+		}
+		named.Interfaces, err = b.interfaces(typ.Interfaces)
+	case *internal.Interface:
+		if named.Fields, err = b.fields(typ.Fields); err != nil {
+			return err
+		}
+		if named.Interfaces, err = b.interfaces(typ.Interfaces); err != nil {
+			return err
+		}
+		named.PossibleTypes, err = b.objects(typ.PossibleTypes)
+	case *internal.Union:
+		named.Types, err = b.objects(typ.PossibleTypes)
+	case *internal.Enum:
+		named.Values = make([]string, 0, len(typ.EnumValues))
+		named.ValuesDesc = make(map[string]string, len(typ.EnumValues))
+		named.ValuesDeprecation = make(map[string]string)
+		named.Map = make(map[interface{}]string, len(typ.EnumValues))
+		named.ReverseMap = make(map[string]interface{}, len(typ.EnumValues))
+		// without resolvers, the values of an enum are their names
+		for _, value := range typ.EnumValues {
+			named.Values = append(named.Values, value.Name)
+			named.ValuesDesc[value.Name] = stringValue(value.Desc)
+			if value.IsDeprecated {
+				named.ValuesDeprecation[value.Name] = deprecationReason(value.DeprecationReason)
+			}
+			named.Map[value.Name] = value.Name
+			named.ReverseMap[value.Name] = value.Name
+		}
+	case *internal.InputObject:
+		named.OneOf = typ.IsOneOf
+		named.Fields, err = b.inputFields(typ.InputFields)
+	}
+	return err
+}
+
+func (b *clientBuilder) fields(fields []clientField) (map[string]*internal.Field, error) {
+	result := make(map[string]*internal.Field, len(fields))
+	for _, field := range fields {
+		typ, err := b.typeRef(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		args, err := b.inputFields(field.Args)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		result[field.Name] = &internal.Field{
+			Name: field.Name,
+			Desc: stringValue(field.Desc),
+			Type: typ,
+			Args: args,
+		}
+		if field.IsDeprecated {
+			result[field.Name].DeprecationReason = deprecationReason(field.DeprecationReason)
+		}
+	}
+	return result, nil
+}
+
+func (b *clientBuilder) inputFields(values []clientInputValue) (map[string]*internal.InputField, error) {
+	result := make(map[string]*internal.InputField, len(values))
+	for _, value := range values {
+		typ, err := b.typeRef(value.Type)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", value.Name, err)
+		}
+		if !internal.IsInputType(typ) {
+			return nil, fmt.Errorf("argument %s: type %s is not an input type", value.Name, typ)
+		}
+		field := &internal.InputField{Name: value.Name, Desc: stringValue(value.Desc), Type: typ}
+		if value.DefaultValue != nil {
+			literal, err := internal.ParseValue(*value.DefaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: invalid default value %s: %v", value.Name, *value.DefaultValue,
+					err.Message)
+			}
+			if field.DefaultValue, err = internal.ValueToJson(literal, nil); err != nil {
+				return nil, fmt.Errorf("argument %s: invalid default value %s: %v", value.Name, *value.DefaultValue,
+					err.Message)
+			}
+		}
+		if value.IsDeprecated {
+			field.DeprecationReason = deprecationReason(value.DeprecationReason)
+		}
+		result[value.Name] = field
+	}
+	return result, nil
+}
+
+func (b *clientBuilder) interfaces(refs []clientTypeRef) (map[string]*internal.Interface, error) {
+	result := make(map[string]*internal.Interface, len(refs))
+	for _, ref := range refs {
+		iface, ok := b.types[ref.Name].(*internal.Interface)
+		if !ok {
+			return nil, fmt.Errorf("interface %s is not an interface type", ref.Name)
+		}
+		result[ref.Name] = iface
+	}
+	return result, nil
+}
+
+func (b *clientBuilder) objects(refs []clientTypeRef) (map[string]*internal.Object, error) {
+	result := make(map[string]*internal.Object, len(refs))
+	for _, ref := range refs {
+		object, ok := b.types[ref.Name].(*internal.Object)
+		if !ok {
+			return nil, fmt.Errorf("possible type %s is not an object type", ref.Name)
+		}
+		result[ref.Name] = object
+	}
+	return result, nil
+}
+
+// typeRef returns the type referred to by ref, its wrapping types included.
+func (b *clientBuilder) typeRef(ref *clientTypeRef) (internal.Type, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("missing type")
+	}
+	switch ref.Kind {
+	case LIST, NON_NULL:
+		typ, err := b.typeRef(ref.OfType)
+		if err != nil {
+			return nil, err
+		}
+		if ref.Kind == LIST {
+			return &internal.List{Type: typ}, nil
+		}
+		if _, ok := typ.(*internal.NonNull); ok {
+			return nil, fmt.Errorf("non-null type %s can not be wrapped in a non-null type", typ)
+		}
+		return &internal.NonNull{Type: typ}, nil
+	}
+	typ, ok := b.types[ref.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", ref.Name)
+	}
+	return typ, nil
+}
+
+func (b *clientBuilder) rootType(ref *clientTypeRef) (*internal.Object, error) {
+	object, ok := b.types[ref.Name].(*internal.Object)
+	if !ok {
+		return nil, fmt.Errorf("root type %s is not an object type", ref.Name)
+	}
+	return object, nil
+}
+
+// addMetaFields adds the __schema and __type fields to the query type, so the introspection queries are valid
+// against the schema, when the introspection types are part of the result.
+func (b *clientBuilder) addMetaFields(query *internal.Object) {
+	schema, ok := b.types["__Schema"].(*internal.Object)
+	if !ok {
+		return
+	}
+	typ, ok := b.types["__Type"].(*internal.Object)
+	if !ok {
+		return
+	}
+	var name internal.Type = &internal.Scalar{Name: "String"}
+	if str, ok := b.types["String"]; ok {
+		name = str
+	}
+	if query.Fields == nil {
+		query.Fields = make(map[string]*internal.Field)
+	}
+	query.Fields["__schema"] = &internal.Field{Name: "__schema", Type: &internal.NonNull{Type: schema}}
+	query.Fields["__type"] = &internal.Field{
+		Name: "__type",
+		Type: typ,
+		Args: map[string]*internal.InputField{
+			"name": {Name: "name", Type: &internal.NonNull{Type: name}},
+		},
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func deprecationReason(reason *string) string {
+	if reason == nil || *reason == "" {
+		return internal.DefaultDeprecationReason
+	}
+	return *reason
+}
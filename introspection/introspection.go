@@ -7,7 +7,9 @@ import (
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/internal"
 	"github.com/shyptr/graphql/schemabuilder"
+	"reflect"
 	"sort"
+	"strings"
 )
 
 // A GraphQL server supports introspection over its schema.
@@ -62,6 +64,22 @@ type introspection struct {
 	query        internal.Type
 	mutation     internal.Type
 	subscription internal.Type
+	// extensionsField is the name of the meta-field exposing the extensions, see ExtensionsField
+	extensionsField string
+}
+
+// Option configures the introspection added by AddIntrospectionToSchema.
+type Option func(*introspection)
+
+// ExtensionsField exposes the extensions of the objects and of the fields, the metadata attached with
+// schemabuilder.Extension for instance, through a field name of __Type and __Field. This field is not part of the
+// specification, it returns a list of __Extension with the key and the JSON encoded value of each extension:
+//
+//	{ __type(name: "Invoice") { _extensions { key value } fields { name _extensions { key value } } } }
+func ExtensionsField(name string) Option {
+	return func(s *introspection) {
+		s.extensionsField = name
+	}
 }
 
 type DirectiveLocation string
@@ -105,12 +123,12 @@ const (
 // The schema introspection system is accessible from the meta‐fields __schema and __type which are accessible
 // from the type of the root of a query operation.
 type __Schema struct {
-	Desc             string        `graphql:"description"`
-	Types            []__Type      `graphql:"types"`
-	QueryType        *__Type       `graphql:"queryType"`
+	Desc             *string       `graphql:"description"`
+	Types            []__Type      `graphql:"types;;nonnull"`
+	QueryType        *__Type       `graphql:"queryType;;nonnull"`
 	MutationType     *__Type       `graphql:"mutationType"`
 	SubscriptionType *__Type       `graphql:"subscriptionType"`
-	Directives       []__Directive `graphql:"directives"`
+	Directives       []__Directive `graphql:"directives;;nonnull"`
 }
 
 func (s *introspection) registerSchema(schema *schemabuilder.Schema) {
@@ -126,44 +144,8 @@ type __Type struct {
 	OfType internal.Type `graphql:"-" json:"-"`
 }
 
-//var includeDirective = __Directive{
-//	Name: "include",
-//	Desc: "Directs the executor to include this field or fragment only when the `if` argument is true.",
-//	Locations: []DirectiveLocation{
-//		Field,
-//		FragmentSpread,
-//		InlineFragment,
-//	},
-//	Args: []__InputValue{
-//		{
-//			Name: "if",
-//			Fn: __Type{OfType: &system.Scalar{Name: "Boolean"}},
-//			Desc: "Included when true.",
-//		},
-//	},
-//	IsDeprecated: false,
-//}
-//
-//var skipDirective = __Directive{
-//	Name: "skip",
-//	Desc: "Directs the executor to skip this field or fragment only when the `if` argument is true.",
-//	Locations: []DirectiveLocation{
-//		Field,
-//		FragmentSpread,
-//		InlineFragment,
-//	},
-//	Args: []__InputValue{
-//		{
-//			Name: "if",
-//			Fn: __Type{OfType: &system.Scalar{Name: "Boolean"}},
-//			Desc: "Skipped when true.",
-//		},
-//	},
-//	IsDeprecated: false,
-//}
-
 func (s *introspection) registerType(schema *schemabuilder.Schema) {
-	schema.Enum("__TypeKind", TypeKind(0), map[string]interface{}{
+	schema.Enum("__TypeKind", TypeKind(""), map[string]interface{}{
 		string(OBJECT):       OBJECT,
 		string(UNION):        UNION,
 		string(SCALAR):       SCALAR,
@@ -196,111 +178,68 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 		return ""
 	}, "")
 
-	object.FieldFunc("name", func(t __Type) string {
+	object.FieldFunc("name", func(t __Type) *string {
 		switch t := t.OfType.(type) {
 		case internal.NamedType:
-			return t.TypeName()
+			name := t.TypeName()
+			return &name
 		default:
-			return ""
+			return nil
 		}
 	}, "")
 
-	object.FieldFunc("description", func(t __Type) string {
+	object.FieldFunc("description", func(t __Type) *string {
 		switch t := t.OfType.(type) {
 		case internal.NamedType:
-			return t.Description()
+			return description(t.Description())
 		default:
-			return ""
+			return nil
 		}
 	}, "")
 
 	object.FieldFunc("fields", func(t __Type, args struct {
 		IncludeDeprecated *bool `graphql:"includeDeprecated"`
 	}) []__Field {
-		fields := make([]__Field, 0)
-
 		switch t := t.OfType.(type) {
 		case *internal.Object:
-			for name, field := range t.Fields {
-				args := make([]__InputValue, 0)
-				for name, arg := range field.Args {
-					var defaultValue string
-					if arg.DefaultValue != nil {
-						defaultValue = fmt.Sprintf("%v", arg.DefaultValue)
-					}
-					args = append(args, __InputValue{
-						Name:         name,
-						Desc:         arg.Desc,
-						DefaultValue: &defaultValue,
-						Type:         __Type{OfType: arg.Type},
-					})
-				}
-				sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
-				fields = append(fields, __Field{
-					Name:              name,
-					Desc:              &field.Desc,
-					Args:              args,
-					Type:              __Type{OfType: field.Type},
-					IsDeprecated:      false,
-					DeprecationReason: "",
-				})
-			}
+			return fields(t.Fields, includeDeprecated(args.IncludeDeprecated))
 		case *internal.Interface:
-			for name, field := range t.Fields {
-				args := make([]__InputValue, 0)
-				for name, arg := range field.Args {
-					args = append(args, __InputValue{
-						Name: name,
-						Desc: arg.Desc,
-						Type: __Type{OfType: arg.Type},
-					})
-				}
-				sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
-				fields = append(fields, __Field{
-					Name:              name,
-					Desc:              &field.Desc,
-					Args:              args,
-					Type:              __Type{OfType: field.Type},
-					IsDeprecated:      false,
-					DeprecationReason: "",
-				})
-			}
+			return fields(t.Fields, includeDeprecated(args.IncludeDeprecated))
 		}
-		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
-
-		return fields
+		return nil
 	}, "should be non-null for OBJECT and INTERFACE only, must be null for the others")
 
 	object.FieldFunc("interfaces", func(t __Type) []__Type {
-		interfaces := make([]__Type, 0)
-
+		var interfaces map[string]*internal.Interface
 		switch t := t.OfType.(type) {
 		case *internal.Object:
-			for _, i := range t.Interfaces {
-				interfaces = append(interfaces, __Type{OfType: i})
-			}
+			interfaces = t.Interfaces
 		case *internal.Interface:
-			for _, i := range t.Interfaces {
-				interfaces = append(interfaces, __Type{OfType: i})
-			}
+			interfaces = t.Interfaces
+		default:
+			return nil
 		}
-		sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].OfType.String() < interfaces[j].OfType.String() })
-
-		return interfaces
+		types := make([]__Type, 0, len(interfaces))
+		for _, i := range interfaces {
+			types = append(types, __Type{OfType: i})
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i].OfType.String() < types[j].OfType.String() })
+		return types
 	}, "should be non-null for OBJECT and INTERFACE only, must be null for the others")
 
 	object.FieldFunc("possibleTypes", func(t __Type) []__Type {
-		types := make([]__Type, 0)
-
+		var possibleTypes map[string]*internal.Object
 		switch t := t.OfType.(type) {
 		case *internal.Union:
-			for _, typ := range t.Types {
-				types = append(types, __Type{OfType: typ})
-			}
+			possibleTypes = t.Types
 		case *internal.Interface:
-			for _, typ := range t.PossibleTypes {
-				types = append(types, __Type{OfType: typ})
-			}
+			possibleTypes = t.PossibleTypes
+		default:
+			return nil
+		}
+		types := make([]__Type, 0, len(possibleTypes))
+		for _, typ := range possibleTypes {
+			types = append(types, __Type{OfType: typ})
 		}
 		sort.Slice(types, func(i, j int) bool { return types[i].OfType.String() < types[j].OfType.String() })
 		return types
@@ -309,42 +248,41 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 	object.FieldFunc("enumValues", func(t __Type, args struct {
 		IncludeDeprecated *bool `graphql:"includeDeprecated"`
 	}) []__EnumValue {
-
-		switch t := t.OfType.(type) {
-		case *internal.Enum:
-			enumValues := make([]__EnumValue, 0)
-			for _, v := range t.Map {
-				desc := t.ValuesDesc[v]
-				enumValues = append(enumValues,
-					__EnumValue{Name: v, Desc: &desc, IsDeprecated: false, DeprecationReason: ""})
+		enum, ok := t.OfType.(*internal.Enum)
+		if !ok {
+			return nil
+		}
+		enumValues := make([]__EnumValue, 0, len(enum.Map))
+		for _, v := range enum.Map {
+			reason := enum.ValuesDeprecation[v]
+			if reason != "" && !includeDeprecated(args.IncludeDeprecated) {
+				continue
 			}
-			sort.Slice(enumValues, func(i, j int) bool { return enumValues[i].Name < enumValues[j].Name })
-			return enumValues
+			enumValues = append(enumValues, __EnumValue{
+				Name:              v,
+				Desc:              description(enum.ValuesDesc[v]),
+				IsDeprecated:      reason != "",
+				DeprecationReason: description(reason),
+			})
 		}
-		return []__EnumValue{}
+		sort.Slice(enumValues, func(i, j int) bool { return enumValues[i].Name < enumValues[j].Name })
+		return enumValues
 	}, "should be non-null for ENUM only, must be null for the others")
 
-	object.FieldFunc("inputFields", func(t __Type) []__InputValue {
-		fields := make([]__InputValue, 0)
-
-		switch t := t.OfType.(type) {
-		case *internal.InputObject:
-			for name, f := range t.Fields {
-				var defaultValue string
-				if f.DefaultValue != nil {
-					defaultValue = fmt.Sprintf("%v", f.DefaultValue)
-				}
-				fields = append(fields, __InputValue{
-					Name:         name,
-					Type:         __Type{OfType: f.Type},
-					DefaultValue: &defaultValue,
-					Desc:         f.Desc,
-				})
-			}
+	object.FieldFunc("inputFields", func(t __Type, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []__InputValue {
+		if input, ok := t.OfType.(*internal.InputObject); ok {
+			return inputValues(input.Fields, includeDeprecated(args.IncludeDeprecated))
 		}
+		return nil
+	}, "should be non-null for INPUT_OBJECT only, must be null for the others")
 
-		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
-		return fields
+	object.FieldFunc("isOneOf", func(t __Type) *bool {
+		if input, ok := t.OfType.(*internal.InputObject); ok {
+			return &input.OneOf
+		}
+		return nil
 	}, "should be non-null for INPUT_OBJECT only, must be null for the others")
 
 	object.FieldFunc("ofType", func(t __Type) *__Type {
@@ -359,26 +297,152 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 	}, "should be non-null for NON_NULL and LIST only, must be null for the others")
 }
 
+// description returns desc, or nil when it is empty as the descriptions are optional.
+func description(desc string) *string {
+	if desc == "" {
+		return nil
+	}
+	return &desc
+}
+
+// includeDeprecated returns the value of an includeDeprecated argument, which defaults to false.
+func includeDeprecated(include *bool) bool {
+	return include != nil && *include
+}
+
+// fields returns the introspection of the fields of an object or an interface. The meta-fields, as __schema
+// and __type of the query type, are implicit and are not returned.
+func fields(definitions map[string]*internal.Field, includeDeprecated bool) []__Field {
+	fields := make([]__Field, 0, len(definitions))
+	for name, field := range definitions {
+		if strings.HasPrefix(name, "__") || field.DeprecationReason != "" && !includeDeprecated {
+			continue
+		}
+		fields = append(fields, __Field{
+			Name:              name,
+			Desc:              description(field.Desc),
+			Type:              __Type{OfType: field.Type},
+			IsDeprecated:      field.DeprecationReason != "",
+			DeprecationReason: description(field.DeprecationReason),
+			Arguments:         field.Args,
+			Extensions:        field.Extensions,
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// inputValues returns the introspection of the arguments of a field or a directive, or of the fields of an
+// input object.
+func inputValues(definitions map[string]*internal.InputField, includeDeprecated bool) []__InputValue {
+	values := make([]__InputValue, 0, len(definitions))
+	for name, value := range definitions {
+		if value.DeprecationReason != "" && !includeDeprecated {
+			continue
+		}
+		values = append(values, __InputValue{
+			Name:              name,
+			Desc:              description(value.Desc),
+			Type:              __Type{OfType: value.Type},
+			DefaultValue:      printDefaultValue(value.DefaultValue, value.Type),
+			IsDeprecated:      value.DeprecationReason != "",
+			DeprecationReason: description(value.DeprecationReason),
+		})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// printDefaultValue prints a default value of type typ as a GraphQL literal, a default value being given in the
+// form of the variables: names of enum values, and maps of input objects. It returns nil without default value.
+func printDefaultValue(value interface{}, typ internal.Type) *string {
+	if value == nil {
+		return nil
+	}
+	literal := printValue(value, typ)
+	return &literal
+}
+
+func printValue(value interface{}, typ internal.Type) string {
+	if value == nil {
+		return "null"
+	}
+	switch typ := typ.(type) {
+	case *internal.NonNull:
+		return printValue(value, typ.Type)
+	case *internal.List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			// a single value is coerced to a list of one item
+			return printValue(value, typ.Type)
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = printValue(v.Index(i).Interface(), typ.Type)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *internal.InputObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			if typ.Fields[name] != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		fields := make([]string, len(names))
+		for i, name := range names {
+			fields[i] = name + ": " + printValue(object[name], typ.Fields[name].Type)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case *internal.Enum:
+		if name, ok := value.(string); ok {
+			return name
+		}
+		if reflect.TypeOf(value).Comparable() {
+			if name, ok := typ.Map[value]; ok {
+				return name
+			}
+		}
+	}
+	// the literals of the scalars are written as in JSON
+	if literal, err := json.Marshal(value); err == nil {
+		return string(literal)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 // The __Field type represents each field in an Object or Interface type.
 type __Field struct {
-	Name              string         `graphql:"name"`
-	Desc              *string        `graphql:"description"`
-	Args              []__InputValue `graphql:"args"`
-	Type              __Type         `graphql:"type"`
-	IsDeprecated      bool           `graphql:"isDeprecated"`
-	DeprecationReason string         `graphql:"deprecationReason"`
+	Name              string                          `graphql:"name"`
+	Desc              *string                         `graphql:"description"`
+	Type              __Type                          `graphql:"type"`
+	IsDeprecated      bool                            `graphql:"isDeprecated"`
+	DeprecationReason *string                         `graphql:"deprecationReason"`
+	Arguments         map[string]*internal.InputField `graphql:"-"`
+	Extensions        map[string]interface{}          `graphql:"-"`
 }
 
 func (s *introspection) registerField(schema *schemabuilder.Schema) {
-	schema.Object("__Field", __Field{}, "")
+	object := schema.Object("__Field", __Field{}, "")
+	object.FieldFunc("args", func(f __Field, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []__InputValue {
+		return inputValues(f.Arguments, includeDeprecated(args.IncludeDeprecated))
+	}, "")
 }
 
 // The __InputValue type represents field and directive arguments as well as the inputFields of an input object.
 type __InputValue struct {
-	Name         string  `graphql:"name"`
-	Desc         string  `graphql:"description"`
-	Type         __Type  `graphql:"type"`
-	DefaultValue *string `graphql:"defaultValue"`
+	Name              string  `graphql:"name"`
+	Desc              *string `graphql:"description"`
+	Type              __Type  `graphql:"type"`
+	DefaultValue      *string `graphql:"defaultValue"`
+	IsDeprecated      bool    `graphql:"isDeprecated"`
+	DeprecationReason *string `graphql:"deprecationReason"`
 }
 
 func (s *introspection) registerInputValue(schema *schemabuilder.Schema) {
@@ -390,7 +454,7 @@ type __EnumValue struct {
 	Name              string  `graphql:"name"`
 	Desc              *string `graphql:"description"`
 	IsDeprecated      bool    `graphql:"isDeprecated"`
-	DeprecationReason string  `graphql:"deprecationReason"`
+	DeprecationReason *string `graphql:"deprecationReason"`
 }
 
 func (s *introspection) registerEnumValue(schema *schemabuilder.Schema) {
@@ -399,16 +463,22 @@ func (s *introspection) registerEnumValue(schema *schemabuilder.Schema) {
 
 // The __Directive type represents a Directive that a server supports.
 type __Directive struct {
-	Name         string              `graphql:"name"`
-	Desc         string              `graphql:"description"`
-	Locations    []DirectiveLocation `graphql:"locations"`
-	Args         []__InputValue      `graphql:"args"`
-	IsDeprecated bool                `graphql:"isDeprecated"`
+	Name         string                          `graphql:"name"`
+	Desc         *string                         `graphql:"description"`
+	Locations    []DirectiveLocation             `graphql:"locations;;nonnull"`
+	IsRepeatable bool                            `graphql:"isRepeatable"`
+	IsDeprecated bool                            `graphql:"isDeprecated"`
+	Arguments    map[string]*internal.InputField `graphql:"-"`
 }
 
 func (s *introspection) registerDirective(schema *schemabuilder.Schema) {
-	schema.Object("__Directive", __Directive{}, "")
-	schema.Enum("__DirectiveLocation ", DirectiveLocation("QUERY"), map[string]DirectiveLocation{
+	object := schema.Object("__Directive", __Directive{}, "")
+	object.FieldFunc("args", func(d __Directive, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []__InputValue {
+		return inputValues(d.Arguments, includeDeprecated(args.IncludeDeprecated))
+	}, "")
+	schema.Enum("__DirectiveLocation", DirectiveLocation("QUERY"), map[string]DirectiveLocation{
 		"QUERY":                  Query,
 		"MUTATION":               Mutation,
 		"FIELD":                  Field,
@@ -445,6 +515,9 @@ func collectTypes(typ internal.Type, types map[string]internal.Type) {
 				collectTypes(arg.Type, types)
 			}
 		}
+		for _, iface := range typ.Interfaces {
+			collectTypes(iface, types)
+		}
 
 	case *internal.Union:
 		if _, ok := types[typ.Name]; ok {
@@ -468,6 +541,9 @@ func collectTypes(typ internal.Type, types map[string]internal.Type) {
 				collectTypes(arg.Type, types)
 			}
 		}
+		for _, iface := range typ.Interfaces {
+			collectTypes(iface, types)
+		}
 		for _, object := range typ.PossibleTypes {
 			collectTypes(object, types)
 		}
@@ -502,6 +578,41 @@ func collectTypes(typ internal.Type, types map[string]internal.Type) {
 	}
 }
 
+// __Extension is an extension of an object or of a field, its value is encoded in JSON.
+type __Extension struct {
+	Key   string `graphql:"key"`
+	Value string `graphql:"value"`
+}
+
+func (s *introspection) registerExtension(schema *schemabuilder.Schema) {
+	if s.extensionsField == "" {
+		return
+	}
+	schema.Object("__Extension", __Extension{}, "")
+	schema.Object("__Type", __Type{}).FieldFunc(s.extensionsField, func(t __Type) ([]__Extension, error) {
+		if object, ok := t.OfType.(*internal.Object); ok {
+			return extensions(object.Extensions)
+		}
+		return nil, nil
+	}, "the extensions of an OBJECT, null for the others")
+	schema.Object("__Field", __Field{}).FieldFunc(s.extensionsField, func(f __Field) ([]__Extension, error) {
+		return extensions(f.Extensions)
+	}, "")
+}
+
+// extensions returns the introspection of the extensions m sorted by key.
+func extensions(m map[string]interface{}) ([]__Extension, error) {
+	extensions := make([]__Extension, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		value, err := json.Marshal(m[key])
+		if err != nil {
+			return nil, fmt.Errorf("extension %s: %v", key, err)
+		}
+		extensions = append(extensions, __Extension{Key: key, Value: string(value)})
+	}
+	return extensions, nil
+}
+
 func (s *introspection) registerQuery(schema *schemabuilder.Schema) {
 	object := schema.Query()
 
@@ -558,65 +669,60 @@ func (s *introspection) schema() *internal.Schema {
 	s.registerQuery(schema)
 	s.registerSchema(schema)
 	s.registerType(schema)
+	s.registerExtension(schema)
 
 	return schema.MustBuild()
 }
 
-// AddIntrospectionToSchema adds the introspection fields to existing schema
-func AddIntrospectionToSchema(schema *internal.Schema) {
-	types := make(map[string]internal.Type)
-	collectTypes(schema.Query, types)
-	collectTypes(schema.Mutation, types)
-	collectTypes(schema.Subscription, types)
-	is := &introspection{
-		types: types,
+// AddIntrospectionToSchema adds the introspection fields to existing schema: __schema and __type to its query
+// type, __typename being resolved on every object by the executor.
+func AddIntrospectionToSchema(schema *internal.Schema, options ...Option) {
+	is := &introspection{}
+	for _, option := range options {
+		option(is)
 	}
 	for _, d := range schema.Directives {
+		locs := make([]DirectiveLocation, len(d.Locs))
+		for index, loc := range d.Locs {
+			locs[index] = DirectiveLocation(loc)
+		}
 		is.directives = append(is.directives, __Directive{
-			Name: d.Name,
-			Desc: d.Desc,
-			Locations: func() []DirectiveLocation {
-				locs := make([]DirectiveLocation, len(d.Locs))
-				for index, loc := range d.Locs {
-					locs[index] = DirectiveLocation(loc)
-				}
-				return locs
-			}(),
-			Args: func() []__InputValue {
-				inputValues := make([]__InputValue, 0)
-				for _, arguemnt := range d.Args {
-					var defaultValue string
-					if arguemnt.DefaultValue != nil {
-						defaultValue = fmt.Sprintf("%s", defaultValue)
-					}
-					inputValues = append(inputValues, __InputValue{
-						Name:         arguemnt.Name,
-						Desc:         arguemnt.Desc,
-						Type:         __Type{OfType: arguemnt.Type},
-						DefaultValue: &defaultValue,
-					})
-				}
-				return inputValues
-			}(),
-			IsDeprecated: false,
+			Name:      d.Name,
+			Desc:      description(d.Desc),
+			Locations: locs,
+			Arguments: d.Args,
 		})
 	}
+	sort.Slice(is.directives, func(i, j int) bool { return is.directives[i].Name < is.directives[j].Name })
 	isSchema := is.schema()
 
 	copyObject(schema.Query, isSchema.Query)
 	schema.Query = isSchema.Query
-	//copyObject(schema.Mutation, isSchema.Mutation)
-	//schema.Mutation = isSchema.Mutation
-	//copyObject(schema.Subscription, isSchema.Subscription)
-	//schema.Subscription = isSchema.Subscription
-
+	// the introspection schema has root types of its own, only its query type and the introspection types
+	// are merged, the root types without fields are not exposed
 	for k, v := range isSchema.TypeMap {
-		schema.TypeMap[k] = v
+		if k == schema.Query.String() || strings.HasPrefix(k, "__") {
+			schema.TypeMap[k] = v
+		}
+	}
+	is.query, is.mutation, is.subscription = schema.Query, rootType(schema.Mutation), rootType(schema.Subscription)
+
+	is.types = make(map[string]internal.Type)
+	collectTypes(is.query, is.types)
+	collectTypes(is.mutation, is.types)
+	collectTypes(is.subscription, is.types)
+	for _, d := range schema.Directives {
+		for _, arg := range d.Args {
+			collectTypes(arg.Type, is.types)
+		}
 	}
+}
 
-	is.query, is.mutation, is.subscription = schema.Query, schema.Mutation, schema.Subscription
-	//is.types["Mutation"] = schema.Mutation
-	//is.types["Subscription"] = schema.Subscription
+func rootType(typ internal.Type) internal.Type {
+	if object, ok := typ.(*internal.Object); !ok || len(object.Fields) == 0 {
+		return nil
+	}
+	return typ
 }
 
 func copyObject(s internal.Type, d internal.Type) {
@@ -628,7 +734,7 @@ func copyObject(s internal.Type, d internal.Type) {
 	}
 	src := s.(*internal.Object)
 	dest := d.(*internal.Object)
-	dest.Name, dest.IsTypeOf, dest.Desc = src.Name, src.IsTypeOf, src.Desc
+	dest.Name, dest.IsTypeOf, dest.Desc, dest.Extensions = src.Name, src.IsTypeOf, src.Desc, src.Extensions
 	for k, v := range src.Fields {
 		dest.Fields[k] = v
 	}
@@ -641,9 +747,9 @@ func copyObject(s internal.Type, d internal.Type) {
 // query.
 func ComputeSchemaJSON(schema *internal.Schema) ([]byte, error) {
 
-	query, err := internal.Parse(IntrospectionQuery)
-	if err != nil {
-		return nil, err
+	query, parseErrs := internal.Parse(IntrospectionQuery)
+	if parseErrs != nil {
+		return nil, parseErrs
 	}
 
 	//if err := validation.Validate(schema, query, nil, 50); err != nil {
@@ -0,0 +1,374 @@
+package introspection_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+type Episode int
+
+type Hero struct {
+	Name string `graphql:"name;the name of the hero"`
+}
+
+type ReviewInput struct {
+	Stars   int      `graphql:"stars"`
+	Episode *Episode `graphql:"episode"`
+	Tags    []string `graphql:"tags"`
+}
+
+func buildSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Enum("Episode", Episode(0), map[string]interface{}{
+		"NEWHOPE": Episode(4),
+		"EMPIRE":  Episode(5),
+		"CLONES":  schemabuilder.DeprecatedField{Field: Episode(2), Reason: "Use NEWHOPE."},
+	})
+	hero := build.Object("Hero", Hero{}, "A hero of the saga.")
+	hero.FieldFunc("nickname", func(h Hero) string { return h.Name }, schemabuilder.Deprecated("Use name."))
+	review := build.InputObject("ReviewInput", ReviewInput{})
+	review.FieldDefault("stars", 5)
+	review.FieldDefault("episode", "EMPIRE")
+	review.FieldDefault("tags", []interface{}{"new"})
+	build.Query().FieldFunc("hero", func(args struct {
+		Episode *Episode `graphql:"episode"`
+	}) *Hero {
+		return &Hero{Name: "Luke"}
+	})
+	build.Query().FieldFunc("review", func(args struct {
+		Review ReviewInput `graphql:"review"`
+	}) bool {
+		return true
+	})
+	build.Directive("upper", []string{"FIELD", "FRAGMENT_SPREAD"}, func(args struct {
+		Locale *string `graphql:"locale;the locale of the upper casing"`
+	}, resolve func() (interface{}, error)) (interface{}, error) {
+		return resolve()
+	}, "Upper cases a string field.")
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	return schema
+}
+
+func do(t *testing.T, schema *internal.Schema, query string) string {
+	result, err := execution.Do(schema, execution.Params{Query: query})
+	assert.Equal(t, 0, len(err), "%v", err)
+	marshal, _ := json.Marshal(result)
+	return string(marshal)
+}
+
+func TestIntrospection(t *testing.T) {
+	schema := buildSchema()
+
+	t.Run("introspects the schema", func(t *testing.T) {
+		var result struct {
+			Schema struct {
+				QueryType        struct{ Name string } `json:"queryType"`
+				MutationType     interface{}           `json:"mutationType"`
+				SubscriptionType interface{}           `json:"subscriptionType"`
+				Types            []struct{ Name string }
+				Directives       []struct{ Name string }
+			} `json:"__schema"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(do(t, schema, `{
+			__schema {
+				queryType { name }
+				mutationType { name }
+				subscriptionType { name }
+				types { name }
+				directives { name }
+			}
+		}`)), &result))
+		assert.Equal(t, "Query", result.Schema.QueryType.Name)
+		// the schema has no mutation nor subscription fields
+		assert.Nil(t, result.Schema.MutationType)
+		assert.Nil(t, result.Schema.SubscriptionType)
+		var types []string
+		for _, typ := range result.Schema.Types {
+			types = append(types, typ.Name)
+		}
+		assert.Equal(t, []string{"Boolean", "Episode", "Hero", "Int", "Query", "ReviewInput", "String", "__Directive",
+			"__DirectiveLocation", "__EnumValue", "__Field", "__InputValue", "__Schema", "__Type", "__TypeKind"}, types)
+	})
+
+	t.Run("introspects an object", func(t *testing.T) {
+		assert.JSONEq(t, `{"__type": {
+			"kind": "OBJECT",
+			"name": "Hero",
+			"description": "A hero of the saga.",
+			"fields": [
+				{"name": "name", "description": "the name of the hero", "isDeprecated": false, "deprecationReason": null},
+				{"name": "nickname", "description": null, "isDeprecated": true, "deprecationReason": "Use name."}
+			],
+			"interfaces": [],
+			"possibleTypes": null,
+			"enumValues": null,
+			"inputFields": null
+		}}`, do(t, schema, `{
+			__type(name: "Hero") {
+				kind
+				name
+				description
+				fields(includeDeprecated: true) { name description isDeprecated deprecationReason }
+				interfaces { name }
+				possibleTypes { name }
+				enumValues { name }
+				inputFields { name }
+			}
+		}`))
+	})
+
+	t.Run("hides the deprecated elements and the meta-fields", func(t *testing.T) {
+		assert.JSONEq(t, `{
+			"hero": {"fields": [{"name": "name"}]},
+			"episode": {"enumValues": [{"name": "EMPIRE"}, {"name": "NEWHOPE"}]},
+			"query": {"fields": [{"name": "hero"}, {"name": "review"}]}
+		}`, do(t, schema, `{
+			hero: __type(name: "Hero") { fields { name } }
+			episode: __type(name: "Episode") { enumValues { name } }
+			query: __type(name: "Query") { fields { name } }
+		}`))
+	})
+
+	t.Run("prints the default values as literals", func(t *testing.T) {
+		assert.JSONEq(t, `{"__type": {"inputFields": [
+			{"name": "episode", "defaultValue": "EMPIRE", "type": {"kind": "ENUM", "name": "Episode", "ofType": null}},
+			{"name": "stars", "defaultValue": "5", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR"}}},
+			{"name": "tags", "defaultValue": "[\"new\"]", "type": {"kind": "LIST", "name": null, "ofType": {"kind": "NON_NULL"}}}
+		]}}`, do(t, schema, `{
+			__type(name: "ReviewInput") {
+				inputFields { name defaultValue type { kind name ofType { kind } } }
+			}
+		}`))
+	})
+
+	t.Run("resolves __typename", func(t *testing.T) {
+		assert.JSONEq(t, `{"__typename": "Query", "hero": {"__typename": "Hero", "name": "Luke"}}`,
+			do(t, schema, `{ __typename hero { __typename name } }`))
+	})
+
+	t.Run("returns null for an unknown type", func(t *testing.T) {
+		assert.JSONEq(t, `{"__type": null}`, do(t, schema, `{ __type(name: "Droid") { name } }`))
+	})
+
+	t.Run("computes the result of the introspection query", func(t *testing.T) {
+		schemaJSON, err := introspection.ComputeSchemaJSON(schema)
+		assert.NoError(t, err)
+		assert.Contains(t, string(schemaJSON), `"queryType":{"name":"Query"}`)
+	})
+}
+
+func TestBuildClientSchema(t *testing.T) {
+	schemaJSON, err := introspection.ComputeSchemaJSON(buildSchema())
+	assert.NoError(t, err)
+	schema, err := introspection.BuildClientSchema([]byte(`{"data": ` + string(schemaJSON) + `}`))
+	assert.NoError(t, err)
+
+	review := schema.TypeMap["ReviewInput"].(*internal.InputObject)
+	assert.EqualValues(t, 5, review.Fields["stars"].DefaultValue)
+	assert.Equal(t, "EMPIRE", review.Fields["episode"].DefaultValue)
+	assert.Equal(t, []interface{}{"new"}, review.Fields["tags"].DefaultValue)
+	assert.Equal(t, "Use NEWHOPE.", schema.TypeMap["Episode"].(*internal.Enum).ValuesDeprecation["CLONES"])
+
+	validate := func(query string, rules ...validation.Rule) []string {
+		doc, err := internal.Parse(query)
+		assert.Nil(t, err)
+		errs := validation.Validate(schema, doc)
+		if len(rules) > 0 {
+			errs = validation.ValidateWithRules(schema, doc, rules)
+		}
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+	assert.Nil(t, validate(`{ hero(episode: EMPIRE) { name } review(review: {stars: 4}) }`))
+	assert.Nil(t, validate(introspection.IntrospectionQuery))
+	assert.Equal(t, []string{`graphql: Cannot query field "villain" on type "Query". (1:3)`}, validate(`{ villain }`))
+	assert.Equal(t, []string{
+		`graphql: The enum value "Episode.CLONES" is deprecated. Use NEWHOPE. (1:17)`,
+		`graphql: The field Hero.nickname is deprecated. Use name. (1:27)`,
+	}, validate(`{ hero(episode: CLONES) { nickname } }`, validation.NoDeprecated))
+
+	_, err = introspection.BuildClientSchema([]byte(`{"__schema": {"queryType": {"name": "Query"}, "types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [{"name": "hero", "args": [], "type": {"kind": "OBJECT", "name": "Hero"}}]}
+	]}}`))
+	assert.EqualError(t, err, `type Query: field hero: unknown type "Hero"`)
+	_, err = introspection.BuildClientSchema([]byte(`{"data": null}`))
+	assert.EqualError(t, err, "invalid introspection result: missing __schema")
+}
+
+func TestBuildASTSchema(t *testing.T) {
+	doc, errs := internal.ParseDocument(`
+"A hero of the saga."
+type Hero implements Character { name: String! nickname: String @deprecated(reason: "Use name.") }
+interface Character { name: String! }
+enum Episode { NEWHOPE EMPIRE }
+extend enum Episode { CLONES @deprecated(reason: "Use \"NEWHOPE\".") }
+input ReviewInput @oneOf { stars: Int comment: String }
+type Query { hero(episode: Episode = EMPIRE): Character }
+extend type Query { search(text: String!): [Hero!]! }
+type Mutation { review(review: ReviewInput!): Boolean }
+`)
+	if !assert.Nil(t, errs) {
+		return
+	}
+	schema, err := introspection.BuildASTSchema(doc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "A hero of the saga.", schema.TypeMap["Hero"].(*internal.Object).Desc)
+	assert.Equal(t, `Use "NEWHOPE".`, schema.TypeMap["Episode"].(*internal.Enum).ValuesDeprecation["CLONES"])
+	assert.Contains(t, schema.TypeMap["Character"].(*internal.Interface).PossibleTypes, "Hero")
+	assert.True(t, schema.TypeMap["ReviewInput"].(*internal.InputObject).OneOf)
+	assert.Equal(t, "Mutation", schema.Mutation.String())
+
+	validate := func(query string, rules ...validation.Rule) []string {
+		doc, err := internal.Parse(query)
+		assert.Nil(t, err)
+		errs := validation.Validate(schema, doc)
+		if len(rules) > 0 {
+			errs = validation.ValidateWithRules(schema, doc, rules)
+		}
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+	assert.Nil(t, validate(`{ hero { name ... on Hero { nickname } } search(text: "luke") { name } }`))
+	assert.Nil(t, validate(`mutation { review(review: {stars: 4}) }`))
+	assert.Nil(t, validate(introspection.IntrospectionQuery))
+	assert.Equal(t, []string{`graphql: Cannot query field "villain" on type "Query". (1:3)`}, validate(`{ villain }`))
+	assert.Equal(t, []string{
+		`graphql: The enum value "Episode.CLONES" is deprecated. Use "NEWHOPE". (1:17)`,
+		`graphql: The field Hero.nickname is deprecated. Use name. (1:41)`,
+	}, validate(`{ hero(episode: CLONES) { ... on Hero { nickname } } }`, validation.NoDeprecated))
+
+	doc, _ = internal.ParseDocument(`type Query { hero: Hero }`)
+	_, err = introspection.BuildASTSchema(doc)
+	assert.EqualError(t, err, `[graphql: Unknown type "Hero". (1:20)]`)
+}
+
+func TestPrintSchema(t *testing.T) {
+	schema := buildSchema()
+	sdl := introspection.PrintSchema(schema)
+	assert.Equal(t, `"""Upper cases a string field."""
+directive @upper(
+  """the locale of the upper casing"""
+  locale: String
+) on FIELD | FRAGMENT_SPREAD
+
+enum Episode {
+  CLONES @deprecated(reason: "Use NEWHOPE.")
+  EMPIRE
+  NEWHOPE
+}
+
+"""A hero of the saga."""
+type Hero {
+  """the name of the hero"""
+  name: String!
+  nickname: String! @deprecated(reason: "Use name.")
+}
+
+type Query {
+  hero(episode: Episode): Hero
+  review(review: ReviewInput!): Boolean!
+}
+
+input ReviewInput {
+  episode: Episode = EMPIRE
+  stars: Int! = 5
+  tags: [String!] = ["new"]
+}
+`, sdl)
+
+	doc, err := internal.ParseDocument(sdl)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(validation.ValidateSDL(doc)))
+
+	schemaJSON, jsonErr := introspection.ComputeSchemaJSON(schema)
+	assert.NoError(t, jsonErr)
+	printed, printErr := introspection.PrintIntrospectionSchema(schemaJSON)
+	assert.NoError(t, printErr)
+	assert.Equal(t, sdl, printed)
+}
+
+func TestExtensions(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	invoice := build.Object("Invoice", Hero{})
+	invoice.Extensions = map[string]interface{}{"tags": []string{"billing"}}
+	invoice.FieldFunc("total", func(h Hero) int { return 0 },
+		schemabuilder.Extension("pii", false), schemabuilder.Extension("cost", map[string]int{"weight": 2}))
+	build.Query().FieldFunc("invoice", func() *Hero { return nil })
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema, introspection.ExtensionsField("_extensions"))
+
+	t.Run("exposes the extensions through the introspection", func(t *testing.T) {
+		assert.JSONEq(t, `{"__type": {
+			"_extensions": [{"key": "tags", "value": "[\"billing\"]"}],
+			"fields": [
+				{"name": "name", "_extensions": []},
+				{"name": "total", "_extensions": [
+					{"key": "cost", "value": "{\"weight\":2}"},
+					{"key": "pii", "value": "false"}
+				]}
+			]
+		}}`, do(t, schema, `{
+			__type(name: "Invoice") { _extensions { key value } fields { name _extensions { key value } } }
+		}`))
+	})
+
+	t.Run("prints the extensions as a directive", func(t *testing.T) {
+		assert.Equal(t, `type Invoice @meta(tags: ["billing"]) {
+  """the name of the hero"""
+  name: String!
+  total: Int! @meta(cost: {weight: 2}, pii: false)
+}
+
+type Query {
+  invoice: Invoice
+}
+`, introspection.PrintSchema(schema, introspection.PrintExtensions("meta")))
+		assert.NotContains(t, introspection.PrintSchema(schema), "@meta")
+	})
+
+	t.Run("does not expose the extensions by default", func(t *testing.T) {
+		_, errs := execution.Do(buildSchema(), execution.Params{Query: `{ __type(name: "Hero") { _extensions { key } } }`})
+		assert.Equal(t, 1, len(errs))
+	})
+}
+
+func TestOneOf(t *testing.T) {
+	type UserBy struct {
+		ID    *string `graphql:"id"`
+		Email *string `graphql:"email"`
+	}
+	build := schemabuilder.NewSchema()
+	build.InputObject("UserBy", UserBy{}).OneOf = true
+	build.Query().FieldFunc("user", func(args struct {
+		By UserBy `graphql:"by"`
+	}) bool {
+		return true
+	})
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+
+	assert.JSONEq(t, `{"userBy": {"isOneOf": true}, "query": {"isOneOf": null}}`,
+		do(t, schema, `{ userBy: __type(name: "UserBy") { isOneOf } query: __type(name: "Query") { isOneOf } }`))
+
+	sdl := introspection.PrintSchema(schema)
+	assert.Contains(t, sdl, "input UserBy @oneOf {")
+	doc, err := internal.ParseDocument(sdl)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(validation.ValidateSDL(doc)))
+}
@@ -0,0 +1,339 @@
+package introspection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// PrintIntrospectionSchema prints the schema described by the result of an introspection query in the schema
+// definition language, eg. to save the schema of a remote server. See BuildClientSchema for result.
+func PrintIntrospectionSchema(result []byte) (string, error) {
+	schema, err := BuildClientSchema(result)
+	if err != nil {
+		return "", err
+	}
+	return PrintSchema(schema), nil
+}
+
+// PrintOption configures PrintSchema.
+type PrintOption func(*printOptions)
+
+type printOptions struct {
+	directives func(extensions map[string]interface{}) string
+}
+
+// PrintExtensions prints the extensions of the objects and of the fields as a directive named directive, with an
+// argument per extension:
+//
+//	type Invoice @meta(tags: ["billing", "pii"]) {
+//
+// The directive is not defined in the printed schema, the tooling reading it is expected to know it.
+func PrintExtensions(directive string) PrintOption {
+	return PrintDirectives(func(extensions map[string]interface{}) string {
+		return " " + PrintExtensionsDirective(extensions, directive)
+	})
+}
+
+// PrintDirectives prints the directives applied to the objects and to the fields, returned by directives from
+// their extensions with a leading space, eg. ` @key(fields: "id")`.
+func PrintDirectives(directives func(extensions map[string]interface{}) string) PrintOption {
+	return func(options *printOptions) {
+		options.directives = directives
+	}
+}
+
+// PrintSchema prints schema in the schema definition language. The definitions are sorted by name, the built-in
+// scalars and directives and the introspection types are not printed.
+func PrintSchema(schema *internal.Schema, options ...PrintOption) string {
+	var opts printOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	var definitions []string
+	if definition := printSchemaDefinition(schema); definition != "" {
+		definitions = append(definitions, definition)
+	}
+
+	directives := make([]*internal.Directive, 0, len(schema.Directives))
+	for _, d := range schema.Directives {
+		if !specifiedDirectives[d.Name] {
+			directives = append(directives, d)
+		}
+	}
+	sort.Slice(directives, func(i, j int) bool { return directives[i].Name < directives[j].Name })
+	for _, d := range directives {
+		definitions = append(definitions, printDirectiveDefinition(d))
+	}
+
+	names := make([]string, 0, len(schema.TypeMap))
+	for name := range schema.TypeMap {
+		if !strings.HasPrefix(name, "__") && !specifiedScalars[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if definition := printTypeDefinition(schema.TypeMap[name], opts); definition != "" {
+			definitions = append(definitions, definition)
+		}
+	}
+	return strings.Join(definitions, "\n\n") + "\n"
+}
+
+var specifiedScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+var specifiedDirectives = map[string]bool{"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "oneOf": true}
+
+// printSchemaDefinition prints the schema definition, which is omitted when the root types have their
+// conventional names.
+func printSchemaDefinition(schema *internal.Schema) string {
+	roots := []struct {
+		operation, name string
+		typ             internal.Type
+	}{
+		{"query", "Query", schema.Query},
+		{"mutation", "Mutation", schema.Mutation},
+		{"subscription", "Subscription", schema.Subscription},
+	}
+	conventional := true
+	var fields []string
+	for _, root := range roots {
+		object, ok := rootType(root.typ).(*internal.Object)
+		if !ok {
+			continue
+		}
+		conventional = conventional && object.Name == root.name
+		fields = append(fields, "  "+root.operation+": "+object.Name)
+	}
+	if conventional {
+		return ""
+	}
+	return "schema {\n" + strings.Join(fields, "\n") + "\n}"
+}
+
+func printDirectiveDefinition(d *internal.Directive) string {
+	return printDescription(d.Desc, "") + "directive @" + d.Name + printArgs(d.Args, "") + " on " +
+		strings.Join(d.Locs, " | ")
+}
+
+func printTypeDefinition(typ internal.NamedType, opts printOptions) string {
+	desc := printDescription(typ.Description(), "")
+	switch typ := typ.(type) {
+	case *internal.Scalar:
+		return desc + "scalar " + typ.Name
+	case *internal.Object:
+		return desc + "type " + typ.Name + printImplements(typ.Interfaces) +
+			opts.printDirectives(typ.Extensions) + printFields(typ.Fields, opts)
+	case *internal.Interface:
+		return desc + "interface " + typ.Name + printImplements(typ.Interfaces) + printFields(typ.Fields, opts)
+	case *internal.Union:
+		members := make([]string, 0, len(typ.Types))
+		for name := range typ.Types {
+			members = append(members, name)
+		}
+		sort.Strings(members)
+		if len(members) == 0 {
+			return desc + "union " + typ.Name
+		}
+		return desc + "union " + typ.Name + " = " + strings.Join(members, " | ")
+	case *internal.Enum:
+		values := append([]string(nil), typ.Values...)
+		sort.Strings(values)
+		lines := make([]string, len(values))
+		for i, value := range values {
+			lines[i] = printDescription(typ.ValuesDesc[value], "  ") + "  " + value +
+				printDeprecated(typ.ValuesDeprecation[value])
+		}
+		return desc + "enum " + typ.Name + printBlock(lines)
+	case *internal.InputObject:
+		names := sortedKeys(typ.Fields)
+		lines := make([]string, len(names))
+		for i, name := range names {
+			lines[i] = printDescription(typ.Fields[name].Desc, "  ") + "  " + printInputValue(typ.Fields[name])
+		}
+		oneOf := ""
+		if typ.OneOf {
+			oneOf = " @oneOf"
+		}
+		return desc + "input " + typ.Name + oneOf + printBlock(lines)
+	}
+	return ""
+}
+
+func printImplements(interfaces map[string]*internal.Interface) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(interfaces))
+	for name := range interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return " implements " + strings.Join(names, " & ")
+}
+
+func printFields(fields map[string]*internal.Field, opts printOptions) string {
+	var lines []string
+	for _, name := range sortedKeys(fields) {
+		// the meta-fields of the query type are implicit
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		field := fields[name]
+		lines = append(lines, printDescription(field.Desc, "  ")+"  "+name+printArgs(field.Args, "  ")+": "+
+			field.Type.String()+printDeprecated(field.DeprecationReason)+
+			opts.printDirectives(field.Extensions))
+	}
+	return printBlock(lines)
+}
+
+// printArgs prints the arguments on a line, or on a line each when one of them is described.
+func printArgs(args map[string]*internal.InputField, indent string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	names := sortedKeys(args)
+	described := false
+	for _, name := range names {
+		described = described || args[name].Desc != ""
+	}
+	values := make([]string, len(names))
+	for i, name := range names {
+		if described {
+			values[i] = printDescription(args[name].Desc, indent+"  ") + indent + "  " + printInputValue(args[name])
+		} else {
+			values[i] = printInputValue(args[name])
+		}
+	}
+	if !described {
+		return "(" + strings.Join(values, ", ") + ")"
+	}
+	return "(\n" + strings.Join(values, "\n") + "\n" + indent + ")"
+}
+
+func printInputValue(value *internal.InputField) string {
+	s := value.Name + ": " + value.Type.String()
+	if defaultValue := printDefaultValue(value.DefaultValue, value.Type); defaultValue != nil {
+		s += " = " + *defaultValue
+	}
+	return s + printDeprecated(value.DeprecationReason)
+}
+
+func printDeprecated(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case internal.DefaultDeprecationReason:
+		return " @deprecated"
+	}
+	return fmt.Sprintf(" @deprecated(reason: %s)", printString(reason))
+}
+
+func (opts printOptions) printDirectives(extensions map[string]interface{}) string {
+	if opts.directives == nil || len(extensions) == 0 {
+		return ""
+	}
+	return opts.directives(extensions)
+}
+
+// PrintExtensionsDirective prints extensions, the extensions of an object or a field, as the usage of a directive
+// named directive, with an argument per extension, as PrintExtensions does: @meta(tags: ["billing", "pii"]).
+func PrintExtensionsDirective(extensions map[string]interface{}, directive string) string {
+	keys := sortedKeys(extensions)
+	args := make([]string, len(keys))
+	for i, key := range keys {
+		args[i] = key + ": " + printLiteral(extensions[key])
+	}
+	return "@" + directive + "(" + strings.Join(args, ", ") + ")"
+}
+
+// PrintDefaultValue prints the default value of an argument or an input field as a GraphQL literal, "" when it has
+// none.
+func PrintDefaultValue(value *internal.InputField) string {
+	if defaultValue := printDefaultValue(value.DefaultValue, value.Type); defaultValue != nil {
+		return *defaultValue
+	}
+	return ""
+}
+
+// printLiteral prints a Go value as a GraphQL literal, from its JSON encoding: the maps and the structs are
+// printed as objects, the slices as lists.
+func printLiteral(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return printString(fmt.Sprintf("%v", value))
+	}
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return printString(fmt.Sprintf("%v", value))
+	}
+	return printJSONLiteral(decoded)
+}
+
+func printJSONLiteral(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(value)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + printJSONLiteral(value[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = printJSONLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case nil:
+		return "null"
+	case string:
+		return printString(value)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// printDescription prints desc as a block string on the lines before a definition.
+func printDescription(desc string, indent string) string {
+	if desc == "" {
+		return ""
+	}
+	desc = strings.ReplaceAll(desc, `"""`, `\"""`)
+	if !strings.Contains(desc, "\n") {
+		return indent + `"""` + desc + `"""` + "\n"
+	}
+	lines := strings.Split(desc, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return indent + `"""` + "\n" + strings.Join(lines, "\n") + "\n" + indent + `"""` + "\n"
+}
+
+func printString(s string) string {
+	return printValue(s, &internal.Scalar{Name: "String"})
+}
+
+func printBlock(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
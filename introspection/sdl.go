@@ -0,0 +1,289 @@
+package introspection
+
+import (
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/validation"
+)
+
+// builtinSDL defines the scalars and the directives which every schema has, unless its document redefines them.
+const builtinSDL = `
+scalar String
+scalar Int
+scalar Float
+scalar Boolean
+scalar ID
+directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @specifiedBy(url: String!) on SCALAR
+directive @oneOf on INPUT_OBJECT
+`
+
+// BuildASTSchema builds the schema defined by doc, a type system document, eg. the SDL of a server split in files,
+// so that the operations of its clients can be validated offline. doc is checked by validation.ValidateSDL first,
+// the errors of which are returned as a MultiError. The extensions of doc are merged into the types they extend.
+//
+// Like the schemas of BuildClientSchema, the schema has no resolvers and its custom scalars accept any value. The
+// introspection fields are added to its query type.
+func BuildASTSchema(doc *ast.Document) (*internal.Schema, error) {
+	if errs := validation.ValidateSDL(doc); errs != nil {
+		return nil, errs
+	}
+	builtins, errs := internal.ParseDocument(builtinSDL)
+	if errs != nil {
+		return nil, errs
+	}
+	s := &sdlBuilder{types: make(map[string]*clientType)}
+	for _, definition := range doc.Definition {
+		s.add(definition)
+	}
+	for _, definition := range builtins.Definition {
+		if d, ok := definition.(*ast.DirectiveDefinition); ok && s.directives[d.Name.Name] {
+			continue
+		}
+		if d, ok := definition.(*ast.ScalarDefinition); ok && s.types[d.Name.Name] != nil {
+			continue
+		}
+		s.add(definition)
+	}
+
+	result := s.result
+	for _, name := range s.order {
+		typ := s.types[name]
+		if typ.Kind == OBJECT {
+			for _, iface := range typ.Interfaces {
+				if implemented := s.types[iface.Name]; implemented != nil {
+					implemented.PossibleTypes = append(implemented.PossibleTypes, clientTypeRef{Name: typ.Name})
+				}
+			}
+		}
+	}
+	for _, name := range s.order {
+		result.Types = append(result.Types, *s.types[name])
+	}
+	if !s.schemaDefined {
+		result.QueryType, result.MutationType, result.SubscriptionType =
+			s.conventionalRoot("Query"), s.conventionalRoot("Mutation"), s.conventionalRoot("Subscription")
+	}
+
+	b := &clientBuilder{types: make(map[string]internal.NamedType, len(result.Types))}
+	schema, err := b.build(&result)
+	if err != nil {
+		return nil, err
+	}
+	AddIntrospectionToSchema(schema)
+	return schema, nil
+}
+
+// sdlBuilder collects the definitions and the extensions of a document as the result of an introspection query,
+// which clientBuilder builds.
+type sdlBuilder struct {
+	result clientSchema
+	// types are the types by name, in the order of their definitions or first extensions
+	types map[string]*clientType
+	order []string
+	// directives are the names of the directives defined
+	directives    map[string]bool
+	schemaDefined bool
+}
+
+// typ returns the type name, of kind when it is not defined yet.
+func (s *sdlBuilder) typ(name string, kind TypeKind) *clientType {
+	if typ, ok := s.types[name]; ok {
+		return typ
+	}
+	typ := &clientType{Kind: kind, Name: name}
+	s.types[name] = typ
+	s.order = append(s.order, name)
+	return typ
+}
+
+func (s *sdlBuilder) add(definition ast.Definition) {
+	switch d := definition.(type) {
+	case *ast.SchemaDefinition:
+		s.schemaDefined = true
+		s.operationTypes(d.OperationTypes)
+	case *ast.SchemaExtension:
+		s.operationTypes(d.RootOperation)
+	case *ast.ScalarDefinition:
+		s.typ(d.Name.Name, SCALAR).Desc = sdlDescription(d.Desc)
+	case *ast.ObjectDefinition:
+		typ := s.typ(d.Name.Name, OBJECT)
+		typ.Desc = sdlDescription(d.Desc)
+		s.fields(typ, d.Interfaces, d.Fields)
+	case *ast.ObjectExtension:
+		s.fields(s.typ(d.Name.Name, OBJECT), d.Interfaces, d.Fields)
+	case *ast.InterfaceDefinition:
+		typ := s.typ(d.Name.Name, INTERFACE)
+		typ.Desc = sdlDescription(d.Desc)
+		s.fields(typ, d.Interfaces, d.Fields)
+	case *ast.InterfaceExtension:
+		s.fields(s.typ(d.Name.Name, INTERFACE), d.Interfaces, d.Fields)
+	case *ast.UnionDefinition:
+		typ := s.typ(d.Name.Name, UNION)
+		typ.Desc = sdlDescription(d.Desc)
+		typ.PossibleTypes = append(typ.PossibleTypes, sdlTypeRefs(d.Members)...)
+	case *ast.UnionExtension:
+		typ := s.typ(d.Name.Name, UNION)
+		typ.PossibleTypes = append(typ.PossibleTypes, sdlTypeRefs(d.Members)...)
+	case *ast.EnumDefinition:
+		typ := s.typ(d.Name.Name, ENUM)
+		typ.Desc = sdlDescription(d.Desc)
+		typ.EnumValues = append(typ.EnumValues, sdlEnumValues(d.Values)...)
+	case *ast.EnumExtension:
+		typ := s.typ(d.Name.Name, ENUM)
+		typ.EnumValues = append(typ.EnumValues, sdlEnumValues(d.Values)...)
+	case *ast.InputObjectDefinition:
+		typ := s.typ(d.Name.Name, INPUT_OBJECT)
+		typ.Desc = sdlDescription(d.Desc)
+		typ.IsOneOf = typ.IsOneOf || sdlHasDirective(d.Directives, "oneOf")
+		typ.InputFields = append(typ.InputFields, sdlInputValues(d.InputFields)...)
+	case *ast.InputObjectExtension:
+		typ := s.typ(d.Name.Name, INPUT_OBJECT)
+		typ.IsOneOf = typ.IsOneOf || sdlHasDirective(d.Directives, "oneOf")
+		typ.InputFields = append(typ.InputFields, sdlInputValues(d.InputFields)...)
+	case *ast.DirectiveDefinition:
+		if s.directives == nil {
+			s.directives = make(map[string]bool)
+		}
+		s.directives[d.Name.Name] = true
+		locations := make([]DirectiveLocation, len(d.Locations))
+		for i, location := range d.Locations {
+			locations[i] = DirectiveLocation(location)
+		}
+		s.result.Directives = append(s.result.Directives, clientDirective{
+			Name:      d.Name.Name,
+			Desc:      sdlDescription(d.Desc),
+			Locations: locations,
+			Args:      sdlInputValues(d.Arguments),
+		})
+	}
+}
+
+func (s *sdlBuilder) operationTypes(operationTypes []*ast.OperationTypeDefinition) {
+	for _, operationType := range operationTypes {
+		ref := &clientTypeRef{Name: operationType.Type.Name.Name}
+		switch operationType.Operation {
+		case ast.Query:
+			s.result.QueryType = ref
+		case ast.Mutation:
+			s.result.MutationType = ref
+		case ast.Subscription:
+			s.result.SubscriptionType = ref
+		}
+	}
+}
+
+// conventionalRoot returns the root type named name when the schema is not defined, nil when there is none.
+func (s *sdlBuilder) conventionalRoot(name string) *clientTypeRef {
+	if typ, ok := s.types[name]; ok && typ.Kind == OBJECT {
+		return &clientTypeRef{Name: name}
+	}
+	return nil
+}
+
+func (s *sdlBuilder) fields(typ *clientType, interfaces []*ast.Named, fields []*ast.FieldDefinition) {
+	typ.Interfaces = append(typ.Interfaces, sdlTypeRefs(interfaces)...)
+	for _, field := range fields {
+		reason, deprecated := sdlDeprecation(field.Directives)
+		typ.Fields = append(typ.Fields, clientField{
+			Name:              field.Name.Name,
+			Desc:              sdlDescription(field.Desc),
+			Args:              sdlInputValues(field.Argument),
+			Type:              sdlTypeRef(field.Type),
+			IsDeprecated:      deprecated,
+			DeprecationReason: reason,
+		})
+	}
+}
+
+func sdlEnumValues(values []*ast.EnumValueDefinition) []clientEnumValue {
+	result := make([]clientEnumValue, len(values))
+	for i, value := range values {
+		reason, deprecated := sdlDeprecation(value.Directives)
+		result[i] = clientEnumValue{
+			Name:              value.Value.Value,
+			Desc:              sdlDescription(value.Desc),
+			IsDeprecated:      deprecated,
+			DeprecationReason: reason,
+		}
+	}
+	return result
+}
+
+func sdlInputValues(values []*ast.InputValueDefinition) []clientInputValue {
+	result := make([]clientInputValue, len(values))
+	for i, value := range values {
+		reason, deprecated := sdlDeprecation(value.Directives)
+		result[i] = clientInputValue{
+			Name:              value.Name.Name,
+			Desc:              sdlDescription(value.Desc),
+			Type:              sdlTypeRef(value.Type),
+			IsDeprecated:      deprecated,
+			DeprecationReason: reason,
+		}
+		if value.DefaultValue != nil {
+			defaultValue := ast.Print(value.DefaultValue)
+			result[i].DefaultValue = &defaultValue
+		}
+	}
+	return result
+}
+
+// sdlTypeRef returns the reference to typ, its wrapping types included.
+func sdlTypeRef(typ ast.Type) *clientTypeRef {
+	switch typ := typ.(type) {
+	case *ast.NonNull:
+		return &clientTypeRef{Kind: NON_NULL, OfType: sdlTypeRef(typ.Type)}
+	case *ast.List:
+		return &clientTypeRef{Kind: LIST, OfType: sdlTypeRef(typ.Type)}
+	case *ast.Named:
+		return &clientTypeRef{Name: typ.Name.Name}
+	}
+	return nil
+}
+
+func sdlTypeRefs(named []*ast.Named) []clientTypeRef {
+	refs := make([]clientTypeRef, len(named))
+	for i, n := range named {
+		refs[i] = clientTypeRef{Name: n.Name.Name}
+	}
+	return refs
+}
+
+// sdlDescription returns the text of desc, nil when there is none.
+func sdlDescription(desc *ast.StringValue) *string {
+	if desc == nil {
+		return nil
+	}
+	text := desc.Text()
+	return &text
+}
+
+// sdlDeprecation returns the reason of the @deprecated directive of directives, nil for the default one, and whether
+// there is one.
+func sdlDeprecation(directives []*ast.Directive) (*string, bool) {
+	for _, d := range directives {
+		if d.Name.Name != "deprecated" {
+			continue
+		}
+		for _, arg := range d.Args {
+			if reason, ok := arg.Value.(*ast.StringValue); ok && arg.Name.Name == "reason" {
+				text := reason.Text()
+				return &text, true
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+func sdlHasDirective(directives []*ast.Directive, name string) bool {
+	for _, d := range directives {
+		if d.Name.Name == name {
+			return true
+		}
+	}
+	return false
+}
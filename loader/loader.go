@@ -0,0 +1,262 @@
+// Package loader reads the GraphQL documents split in files, eg. the SDL of a schema, and tells the locations of
+// their errors back in the files:
+//
+//	doc, err := loader.LoadSchemaFiles("./schema/*.graphql")
+//	if err != nil {
+//		log.Fatal(err) // schema/user.graphql:3:6: There can be only one type named "User".
+//	}
+//	schema, err := introspection.BuildASTSchema(doc)
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/validation"
+)
+
+// Files are GraphQL files concatenated in a source, the locations of which are told back in the files.
+type Files struct {
+	text  strings.Builder
+	lines int
+	files []file
+	// read are the absolute paths of the files, so that the files are read once
+	read map[string]bool
+}
+
+// file is a file of a source, starting at its line.
+type file struct {
+	name string
+	src  string
+	line int
+}
+
+// Position is a location in a file.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Error is an error of Files, the locations of its GraphQLError being told in the files by Positions.
+type Error struct {
+	*errors.GraphQLError
+	Positions []Position
+}
+
+func (e *Error) Error() string {
+	if len(e.Positions) == 0 {
+		return e.Message
+	}
+	return e.Positions[0].String() + ": " + e.Message
+}
+
+// ErrorList are the errors of Files, in the order of their positions.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// importComment matches the #import "path" comments, the path being relative to the file of the comment.
+var importComment = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*import[ \t]+"([^"]+)"`)
+
+// Read reads the files matching the glob patterns, in their order. The directories are walked for their .graphql
+// and .gql files. The files imported by a #import "path" comment are read before the file of the comment, every
+// file is read once.
+func Read(patterns ...string) (*Files, error) {
+	f := &Files{read: make(map[string]bool)}
+	for _, pattern := range patterns {
+		names, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no file matches %s", pattern)
+		}
+		for _, name := range names {
+			if err := f.readPath(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// readPath reads the file name, or the GraphQL files of the directory name.
+func (f *Files) readPath(name string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return f.readFile(name)
+	}
+	return filepath.WalkDir(name, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ext := filepath.Ext(path); ext != ".graphql" && ext != ".gql" {
+			return nil
+		}
+		return f.readFile(path)
+	})
+}
+
+// readFile reads the file name after its imports, unless it is read already.
+func (f *Files) readFile(name string) error {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	if f.read[abs] {
+		return nil
+	}
+	f.read[abs] = true
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	for _, match := range importComment.FindAllStringSubmatch(string(src), -1) {
+		imported := match[1]
+		if !filepath.IsAbs(imported) {
+			imported = filepath.Join(filepath.Dir(name), imported)
+		}
+		if err := f.readFile(imported); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	f.Add(name, string(src))
+	return nil
+}
+
+// Add appends src, the content of the file name, on lines of its own.
+func (f *Files) Add(name, src string) {
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(src)
+	f.files = append(f.files, file{name: name, src: src, line: f.lines + 1})
+	f.text.WriteString(src)
+	f.text.WriteString("\n")
+	f.lines += strings.Count(src, "\n") + 1
+}
+
+// Names returns the names of the files, in their order.
+func (f *Files) Names() []string {
+	names := make([]string, len(f.files))
+	for i, file := range f.files {
+		names[i] = file.name
+	}
+	return names
+}
+
+// Source returns the concatenation of the files, which the locations of the document of Parse are in.
+func (f *Files) Source() string {
+	return f.text.String()
+}
+
+// Parse parses every file on its own, so that the syntax errors of all of them are returned, then the concatenation
+// of the files. The blank files are skipped.
+func (f *Files) Parse() (*ast.Document, error) {
+	var errs ErrorList
+	for _, file := range f.files {
+		if strings.TrimSpace(file.src) == "" {
+			continue
+		}
+		if _, syntaxErrs := internal.ParseDocument(file.src); syntaxErrs != nil {
+			for _, err := range syntaxErrs {
+				positions := make([]Position, len(err.Locations))
+				for i, loc := range err.Locations {
+					positions[i] = Position{File: file.name, Line: loc.Line, Column: loc.Column}
+				}
+				errs = append(errs, &Error{GraphQLError: err, Positions: positions})
+			}
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	if strings.TrimSpace(f.Source()) == "" {
+		return &ast.Document{}, nil
+	}
+	doc, syntaxErrs := internal.ParseDocument(f.Source())
+	if syntaxErrs != nil {
+		return nil, f.Errors(syntaxErrs)
+	}
+	return doc, nil
+}
+
+// Position returns the position of loc, a location in the concatenation of the files.
+func (f *Files) Position(loc errors.Location) Position {
+	for i := len(f.files) - 1; i >= 0; i-- {
+		if file := f.files[i]; loc.Line >= file.line {
+			return Position{File: file.name, Line: loc.Line - file.line + 1, Column: loc.Column}
+		}
+	}
+	return Position{Line: loc.Line, Column: loc.Column}
+}
+
+// Errors returns errs, errors in the concatenation of the files, at their positions in the files and in their order.
+func (f *Files) Errors(errs errors.MultiError) ErrorList {
+	list := make(ErrorList, len(errs))
+	for i, err := range errs {
+		positions := make([]Position, len(err.Locations))
+		for j, loc := range err.Locations {
+			positions[j] = f.Position(loc)
+		}
+		list[i] = &Error{GraphQLError: err, Positions: positions}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return len(list[j].Locations) == 0 ||
+			len(list[i].Locations) != 0 && list[i].Locations[0].Before(list[j].Locations[0])
+	})
+	return list
+}
+
+// duplicateRules are the rules reporting the definitions of a schema which are duplicated, eg. in two files.
+var duplicateRules = []validation.SDLRule{
+	validation.LoneSchemaDefinition,
+	validation.UniqueOperationTypes,
+	validation.UniqueTypeNames,
+	validation.UniqueDirectiveNames,
+	validation.UniqueFieldDefinitionNames,
+	validation.UniqueEnumValueNames,
+	validation.UniqueArgumentDefinitionNames,
+}
+
+// LoadSchemaFiles reads the SDL of a schema split in the files matching the glob patterns, see Read, and merges
+// their definitions in a document, see Files.Schema.
+func LoadSchemaFiles(patterns ...string) (*ast.Document, error) {
+	files, err := Read(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return files.Schema()
+}
+
+// Schema parses the SDL of a schema split in the files. The syntax errors and the definitions which are duplicated
+// are returned as an ErrorList, at their positions in the files. The document is not validated otherwise, the errors
+// of validation.ValidateSDL or of introspection.BuildASTSchema being told in the files by Errors.
+func (f *Files) Schema() (*ast.Document, error) {
+	doc, err := f.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if errs := validation.ValidateSDLWithRules(doc, duplicateRules); errs != nil {
+		return nil, f.Errors(errs)
+	}
+	return doc, nil
+}
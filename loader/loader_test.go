@@ -0,0 +1,82 @@
+package loader_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	}
+	return dir
+}
+
+func typeNames(doc *ast.Document) []string {
+	var names []string
+	for _, definition := range doc.Definition {
+		if d, ok := definition.(*ast.ObjectDefinition); ok {
+			names = append(names, d.Name.Name)
+		}
+	}
+	return names
+}
+
+func TestLoadSchemaFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.graphql":     "# import \"types/user.graphql\"\ntype Query {\n  user: User\n}\n",
+		"types/user.graphql": "#import \"post.graphql\"\ntype User {\n  posts: [Post]\n}\n",
+		"types/post.graphql": "type Post {\n  author: User\n}\n",
+		"types/notes.txt":    "type Note",
+	})
+
+	doc, err := loader.LoadSchemaFiles(filepath.Join(dir, "schema.graphql"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Post", "User", "Query"}, typeNames(doc))
+
+	// the imported files are read once, the other files of the directories are not read
+	doc, err = loader.LoadSchemaFiles(filepath.Join(dir, "types"), filepath.Join(dir, "*.graphql"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Post", "User", "Query"}, typeNames(doc))
+
+	_, err = loader.LoadSchemaFiles(filepath.Join(dir, "*.gql"))
+	assert.EqualError(t, err, "no file matches "+filepath.Join(dir, "*.gql"))
+
+	dir = writeFiles(t, map[string]string{"a.graphql": "#import \"missing.graphql\"\n"})
+	_, err = loader.LoadSchemaFiles(filepath.Join(dir, "a.graphql"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadSchemaFiles_Errors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  user: User\n}\n\ntype User {\n  id: ID!\n}\n",
+		"b.graphql": "\nenum Role { ADMIN ADMIN }\n\ntype User {\n  name: String\n}\n",
+	})
+	a, b := filepath.Join(dir, "a.graphql"), filepath.Join(dir, "b.graphql")
+	_, err := loader.LoadSchemaFiles(filepath.Join(dir, "*.graphql"))
+	assert.EqualError(t, err, a+`:5:6: There can be only one type named "User".`+"\n"+
+		b+`:2:13: Enum value "Role.ADMIN" can only be defined once.`)
+	errs, ok := err.(loader.ErrorList)
+	if assert.True(t, ok) {
+		assert.Equal(t, []loader.Position{{File: a, Line: 5, Column: 6}, {File: b, Line: 4, Column: 6}},
+			errs[0].Positions)
+	}
+
+	dir = writeFiles(t, map[string]string{
+		"a.graphql": "type Query {\n  user: User\n",
+		"b.graphql": "type User {\n  id: ID!\n}\n",
+		"c.graphql": "type {",
+	})
+	_, err = loader.LoadSchemaFiles(filepath.Join(dir, "*.graphql"))
+	assert.EqualError(t, err, filepath.Join(dir, "a.graphql")+`:3:1: Syntax Error: Expected Ident, found "".`+"\n"+
+		filepath.Join(dir, "c.graphql")+`:1:6: Syntax Error: Expected Ident, found "{".`)
+}
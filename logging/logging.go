@@ -0,0 +1,194 @@
+// Package logging logs the operations run by an executor through a log/slog logger: their start and finish, their
+// validation failures, the errors of their resolvers and their slow fields. The logs have the id and the metadata of
+// the requests, see execution.WithRequestID and execution.WithMetadata.
+//
+//	hooks := logging.New(slog.Default())
+//	hooks.SlowField = 100 * time.Millisecond
+//	hooks.Redact = logging.RedactVariables("password", "input.card.number")
+//	s.Use(hooks.FieldMiddleware)
+//	executor := &execution.Executor{Interceptors: []*execution.Interceptor{hooks.Interceptor()}}
+package logging
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// Redacted is the value logged in place of the redacted variables.
+const Redacted = execution.Redacted
+
+// Hooks log the operations through Logger, with its interceptor and its field middleware.
+// It is safe for concurrent use once configured.
+type Hooks struct {
+	Logger *slog.Logger
+	// SlowField is the duration of the resolver calls beyond which they are logged as slow, zero logs none.
+	SlowField time.Duration
+	// Redact returns the value logged for the variable name, eg. Redacted for the secrets, see RedactVariables. The
+	// values of all the variables are Redacted by default.
+	Redact func(name string, value interface{}) interface{}
+	// Clock measures the durations, it is clock.Real by default.
+	Clock clock.Clock
+
+	// started holds the start of the operations being executed
+	started sync.Map
+}
+
+// New returns the hooks logging through logger.
+func New(logger *slog.Logger) *Hooks {
+	return &Hooks{Logger: logger, Clock: clock.Real}
+}
+
+// RedactVariables returns the Redact function logging the variables but the values at paths. A path is the name of
+// a variable followed by the names of the fields of its input objects, separated by dots, eg. "input.card.number"
+// redacts the number of the card of the input variable. The paths go through the lists, they apply to all their
+// items. RedactVariables() logs all the variables as they are.
+func RedactVariables(paths ...string) func(name string, value interface{}) interface{} {
+	root := &redactPath{}
+	for _, path := range paths {
+		node := root
+		for _, name := range strings.Split(path, ".") {
+			if node.children == nil {
+				node.children = make(map[string]*redactPath)
+			}
+			child := node.children[name]
+			if child == nil {
+				child = &redactPath{}
+				node.children[name] = child
+			}
+			node = child
+		}
+		node.redacted = true
+	}
+	return func(name string, value interface{}) interface{} {
+		if node := root.children[name]; node != nil {
+			return node.redact(value)
+		}
+		return value
+	}
+}
+
+// redactPath is a node of the paths of RedactVariables, the value at the node is redacted when redacted is set.
+type redactPath struct {
+	redacted bool
+	children map[string]*redactPath
+}
+
+// redact returns the value logged for value at p, value is left as is.
+func (p *redactPath) redact(value interface{}) interface{} {
+	if p.redacted {
+		return Redacted
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		logged := make(map[string]interface{}, len(value))
+		for name, field := range value {
+			if child := p.children[name]; child != nil {
+				logged[name] = child.redact(field)
+			} else {
+				logged[name] = field
+			}
+		}
+		return logged
+	case []interface{}:
+		logged := make([]interface{}, len(value))
+		for i, item := range value {
+			logged[i] = p.redact(item)
+		}
+		return logged
+	}
+	return value
+}
+
+// Interceptor returns the interceptor logging the start and the finish of the operations, at the debug and info
+// levels, and their validation failures at the warn level.
+func (h *Hooks) Interceptor() *execution.Interceptor {
+	return &execution.Interceptor{
+		AfterParse: func(ctx context.Context, op *execution.Operation) error {
+			h.started.Store(op, h.Clock.Now())
+			h.logger(ctx).DebugContext(ctx, "graphql operation started", slog.String("operation", op.OperationName),
+				slog.Any("variables", h.variables(op.Variables)))
+			return nil
+		},
+		AfterExecution: func(ctx context.Context, op *execution.Operation, response *execution.Response) {
+			attrs := []any{slog.String("operation", op.OperationName), slog.String("type", string(op.Type))}
+			if start, ok := h.started.LoadAndDelete(op); ok {
+				attrs = append(attrs, slog.Duration("duration", h.Clock.Since(start.(time.Time))))
+			}
+			var validationErr *errors.ValidationError
+			if stderrors.As(response.Errors, &validationErr) {
+				h.logger(ctx).WarnContext(ctx, "graphql validation failed", append(attrs,
+					slog.Any("variables", h.variables(op.Variables)), slog.Any("errors", messages(response.Errors)))...)
+			}
+			h.logger(ctx).InfoContext(ctx, "graphql operation finished", append(attrs, slog.Int("errors", len(response.Errors)))...)
+		},
+	}
+}
+
+// FieldMiddleware logs the errors of the resolvers at the error level and the slow resolver calls at the warn
+// level, see schemabuilder.Schema.Use.
+func (h *Hooks) FieldMiddleware(info schemabuilder.FieldInfo, next internal.FieldResolve) internal.FieldResolve {
+	return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		start := h.Clock.Now()
+		value, err := next(ctx, source, args)
+		if err != nil {
+			h.logger(ctx).ErrorContext(ctx, "graphql resolver failed", slog.String("type", info.Object),
+				slog.String("field", info.Field.Name), slog.String("error", err.Error()))
+		}
+		if elapsed := h.Clock.Since(start); h.SlowField > 0 && elapsed >= h.SlowField {
+			h.logger(ctx).WarnContext(ctx, "graphql slow field", slog.String("type", info.Object),
+				slog.String("field", info.Field.Name), slog.Duration("duration", elapsed))
+		}
+		return value, err
+	}
+}
+
+// logger returns the logger of the logs of ctx, which have the id and the metadata of its request if any, see
+// execution.RequestID and execution.Metadata.
+func (h *Hooks) logger(ctx context.Context) *slog.Logger {
+	logger := h.Logger
+	if id := execution.RequestID(ctx); id != "" {
+		logger = logger.With(slog.String("request_id", id))
+	}
+	if metadata := execution.Metadata(ctx); len(metadata) > 0 {
+		attrs := make([]any, 0, len(metadata))
+		for key, value := range metadata {
+			attrs = append(attrs, slog.Any(key, value))
+		}
+		logger = logger.With(slog.Group("metadata", attrs...))
+	}
+	return logger
+}
+
+// variables returns the variables as they are logged.
+func (h *Hooks) variables(variables map[string]interface{}) map[string]interface{} {
+	if variables == nil {
+		return nil
+	}
+	logged := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		if h.Redact != nil {
+			logged[name] = h.Redact(name, value)
+		} else {
+			logged[name] = Redacted
+		}
+	}
+	return logged
+}
+
+func messages(errs errors.MultiError) []string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return messages
+}
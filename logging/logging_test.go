@@ -0,0 +1,124 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/logging"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	var buf bytes.Buffer
+	hooks := logging.New(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})))
+	mock := clock.NewMock(time.Unix(0, 0))
+	hooks.Clock = mock
+	hooks.SlowField = time.Second
+	hooks.Redact = logging.RedactVariables("password")
+
+	build := schemabuilder.NewSchema()
+	build.Use(hooks.FieldMiddleware)
+	build.Query().FieldFunc("login", func(args struct {
+		Name     string `graphql:"name"`
+		Password string `graphql:"password"`
+	}) string {
+		mock.Add(2 * time.Second)
+		return args.Name
+	})
+	build.Query().FieldFunc("fail", func() (string, error) { return "", fmt.Errorf("failed") })
+	schema := build.MustBuild()
+	executor := &execution.Executor{Interceptors: []*execution.Interceptor{hooks.Interceptor()}}
+
+	ctx := context.Background()
+	logs := func(query string, variables map[string]interface{}) []map[string]interface{} {
+		buf.Reset()
+		executor.Do(schema, execution.Params{Query: query, OperationName: "Op", Variables: variables, Context: ctx})
+		var records []map[string]interface{}
+		decoder := json.NewDecoder(&buf)
+		for decoder.More() {
+			var record map[string]interface{}
+			if assert.NoError(t, decoder.Decode(&record)) {
+				records = append(records, record)
+			}
+		}
+		return records
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "DEBUG", "msg": "graphql operation started", "operation": "Op",
+			"variables": map[string]interface{}{"name": "Luke", "password": "[REDACTED]"}},
+		{"level": "WARN", "msg": "graphql slow field", "type": "Query", "field": "login", "duration": 2e9},
+		{"level": "INFO", "msg": "graphql operation finished", "operation": "Op", "type": "QUERY", "duration": 2e9,
+			"errors": 0.0},
+	}, logs(`query Op($name: String!, $password: String!) { login(name: $name, password: $password) }`,
+		map[string]interface{}{"name": "Luke", "password": "secret"}))
+
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "DEBUG", "msg": "graphql operation started", "operation": "Op", "variables": nil},
+		{"level": "ERROR", "msg": "graphql resolver failed", "type": "Query", "field": "fail", "error": "failed"},
+		{"level": "INFO", "msg": "graphql operation finished", "operation": "Op", "type": "QUERY", "duration": 0.0,
+			"errors": 1.0},
+	}, logs(`query Op { fail }`, nil))
+
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "DEBUG", "msg": "graphql operation started", "operation": "Op", "variables": nil},
+		{"level": "WARN", "msg": "graphql validation failed", "operation": "Op", "type": "", "duration": 0.0,
+			"variables": nil, "errors": []interface{}{`graphql: Cannot query field "unknown" on type "Query". (1:12)`}},
+		{"level": "INFO", "msg": "graphql operation finished", "operation": "Op", "type": "", "duration": 0.0,
+			"errors": 1.0},
+	}, logs(`query Op { unknown }`, nil))
+
+	ctx = execution.WithMetadata(execution.WithRequestID(context.Background(), "42"), "tenant", "acme")
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "DEBUG", "msg": "graphql operation started", "request_id": "42",
+			"metadata": map[string]interface{}{"tenant": "acme"}, "operation": "Op", "variables": nil},
+		{"level": "ERROR", "msg": "graphql resolver failed", "request_id": "42",
+			"metadata": map[string]interface{}{"tenant": "acme"}, "type": "Query", "field": "fail", "error": "failed"},
+		{"level": "INFO", "msg": "graphql operation finished", "request_id": "42",
+			"metadata": map[string]interface{}{"tenant": "acme"}, "operation": "Op", "type": "QUERY", "duration": 0.0,
+			"errors": 1.0},
+	}, logs(`query Op { fail }`, nil))
+
+	hooks.Redact = nil
+	records := logs(`query Op($name: String!, $password: String!) { login(name: $name, password: $password) }`,
+		map[string]interface{}{"name": "Luke", "password": "secret"})
+	if assert.NotEmpty(t, records) {
+		assert.Equal(t, map[string]interface{}{"name": "[REDACTED]", "password": "[REDACTED]"}, records[0]["variables"],
+			"all the variables are redacted by default")
+	}
+}
+
+func TestRedactVariables(t *testing.T) {
+	redact := logging.RedactVariables("token", "input.card.number", "input.users.password")
+	assert.Equal(t, logging.Redacted, redact("token", map[string]interface{}{"value": "secret"}))
+	assert.Equal(t, "Luke", redact("name", "Luke"))
+	input := map[string]interface{}{
+		"card":  map[string]interface{}{"number": "4242", "expiry": "12/30"},
+		"users": []interface{}{map[string]interface{}{"name": "Luke", "password": "secret"}, nil},
+		"note":  "kept",
+	}
+	assert.Equal(t, map[string]interface{}{
+		"card":  map[string]interface{}{"number": logging.Redacted, "expiry": "12/30"},
+		"users": []interface{}{map[string]interface{}{"name": "Luke", "password": logging.Redacted}, nil},
+		"note":  "kept",
+	}, redact("input", input))
+	assert.Equal(t, "4242", input["card"].(map[string]interface{})["number"], "the variables are left as they are")
+
+	assert.Equal(t, "secret", logging.RedactVariables()("password", "secret"))
+}
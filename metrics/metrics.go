@@ -0,0 +1,206 @@
+// Package metrics exposes the metrics of the operations run by an executor to Prometheus: the counts, latencies and
+// errors of the requests, the durations of the resolvers and the number of active subscriptions. The errors and the
+// resolvers are labelled with the team owning their field, see schemabuilder.Owner.
+//
+//	collector := metrics.New("graphql")
+//	prometheus.MustRegister(collector)
+//	s.Use(collector.FieldMiddleware)
+//	executor := &execution.Executor{Interceptors: []*execution.Interceptor{collector.Interceptor()}}
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// Collector is a prometheus.Collector of the metrics of the operations, fed by its interceptor, its field
+// middleware and TrackSubscription. It is safe for concurrent use.
+type Collector struct {
+	// Clock measures the durations, it is clock.Real by default.
+	Clock clock.Clock
+
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	errors        *prometheus.CounterVec
+	resolvers     *prometheus.HistogramVec
+	subscriptions prometheus.Gauge
+
+	// started holds the start of the operations being executed
+	started sync.Map
+}
+
+// New returns a collector whose metrics are prefixed with namespace, eg. graphql_requests_total.
+func New(namespace string) *Collector {
+	return &Collector{
+		Clock: clock.Real,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of the operations, by operation type.",
+		}, []string{"operation"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the operations from their parsing to their response, by operation type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of the errors of the responses, by error code and owner of the failed field.",
+		}, []string{"code", "owner"}),
+		resolvers: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "resolver_duration_seconds",
+			Help:      "Duration of the resolver calls, by object type, field and owner of the field.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type", "field", "owner"}),
+		subscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "active_subscriptions",
+			Help:      "Number of the subscriptions sending responses.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.resolvers.Describe(ch)
+	c.subscriptions.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.resolvers.Collect(ch)
+	c.subscriptions.Collect(ch)
+}
+
+// Interceptor returns the interceptor counting the operations of an executor along with their errors, and
+// measuring their latency. The operations rejected before being validated, eg. which can not be parsed, are of the
+// "unknown" type.
+func (c *Collector) Interceptor() *execution.Interceptor {
+	return &execution.Interceptor{
+		AfterParse: func(ctx context.Context, op *execution.Operation) error {
+			c.started.Store(op, c.Clock.Now())
+			return nil
+		},
+		AfterExecution: func(ctx context.Context, op *execution.Operation, response *execution.Response) {
+			operation := strings.ToLower(string(op.Type))
+			if operation == "" {
+				operation = "unknown"
+			}
+			c.requests.WithLabelValues(operation).Inc()
+			if start, ok := c.started.LoadAndDelete(op); ok {
+				c.duration.WithLabelValues(operation).Observe(c.Clock.Since(start.(time.Time)).Seconds())
+			}
+			for _, err := range response.Errors {
+				owner, _ := err.Extensions[internal.OwnerExtension].(string)
+				c.errors.WithLabelValues(errors.Code(err), owner).Inc()
+			}
+		},
+	}
+}
+
+// FieldMiddleware measures the duration of the resolver calls, see schemabuilder.Schema.Use. The resolvers
+// returning a thunk are measured until they return it.
+func (c *Collector) FieldMiddleware(info schemabuilder.FieldInfo, next internal.FieldResolve) internal.FieldResolve {
+	observer := c.resolvers.WithLabelValues(info.Object, info.Field.Name, info.Field.Owner())
+	return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		start := c.Clock.Now()
+		defer func() {
+			observer.Observe(c.Clock.Since(start).Seconds())
+		}()
+		return next(ctx, source, args)
+	}
+}
+
+// TrackSubscription counts the subscription sending responses as active until the channel is closed or ctx, the
+// context of the subscription, is done. It returns the channel relaying the responses, which is closed then:
+//
+//	responses, err := executor.Subscribe(ctx, schema, doc, operationName, variables)
+//	if err == nil {
+//		responses = collector.TrackSubscription(ctx, responses)
+//	}
+func (c *Collector) TrackSubscription(ctx context.Context, responses <-chan *execution.Response) <-chan *execution.Response {
+	c.subscriptions.Inc()
+	relayed := make(chan *execution.Response)
+	go func() {
+		defer close(relayed)
+		defer c.subscriptions.Dec()
+		for {
+			select {
+			case response, ok := <-responses:
+				if !ok {
+					return
+				}
+				select {
+				case relayed <- response:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return relayed
+}
+
+// DocumentCacheCollector is a prometheus.Collector of the counters of a document cache, see
+// execution.DocumentCache:
+//
+//	prometheus.MustRegister(metrics.NewDocumentCacheCollector("graphql", cache))
+type DocumentCacheCollector struct {
+	cache     *execution.DocumentCache
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	size      *prometheus.Desc
+}
+
+// NewDocumentCacheCollector returns a collector of the counters of cache, whose metrics are prefixed with
+// namespace, eg. graphql_document_cache_hits_total.
+func NewDocumentCacheCollector(namespace string, cache *execution.DocumentCache) *DocumentCacheCollector {
+	name := func(name string) string {
+		return prometheus.BuildFQName(namespace, "document_cache", name)
+	}
+	return &DocumentCacheCollector{
+		cache:     cache,
+		hits:      prometheus.NewDesc(name("hits_total"), "Number of the queries whose document was cached.", nil, nil),
+		misses:    prometheus.NewDesc(name("misses_total"), "Number of the queries parsed by the cache.", nil, nil),
+		evictions: prometheus.NewDesc(name("evictions_total"), "Number of the documents evicted from the cache.", nil, nil),
+		size:      prometheus.NewDesc(name("size"), "Number of the cached documents.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *DocumentCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.size
+}
+
+// Collect implements prometheus.Collector.
+func (c *DocumentCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(stats.Len))
+}
@@ -0,0 +1,127 @@
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/metrics"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	collector := metrics.New("graphql")
+	mock := clock.NewMock(time.Unix(0, 0))
+	collector.Clock = mock
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	build := schemabuilder.NewSchema()
+	build.Use(collector.FieldMiddleware)
+	build.Query().FieldFunc("hero", func() string {
+		mock.Add(2 * time.Second)
+		return "Luke"
+	})
+	build.Query().FieldFunc("fail", func() (*string, error) { return nil, fmt.Errorf("failed") },
+		schemabuilder.Owner("billing"))
+	schema := build.MustBuild()
+	executor := &execution.Executor{Interceptors: []*execution.Interceptor{collector.Interceptor()}}
+
+	executor.Do(schema, execution.Params{Query: `{ hero }`})
+	executor.Do(schema, execution.Params{Query: `{ hero fail }`})
+	executor.Do(schema, execution.Params{Query: `{ unknown }`})
+	executor.Do(schema, execution.Params{Query: `{ hero `})
+
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP graphql_requests_total Number of the operations, by operation type.
+# TYPE graphql_requests_total counter
+graphql_requests_total{operation="query"} 2
+graphql_requests_total{operation="unknown"} 2
+# HELP graphql_errors_total Number of the errors of the responses, by error code and owner of the failed field.
+# TYPE graphql_errors_total counter
+graphql_errors_total{code="",owner="billing"} 1
+graphql_errors_total{code="GRAPHQL_PARSE_FAILED",owner=""} 1
+graphql_errors_total{code="GRAPHQL_VALIDATION_FAILED",owner=""} 1
+`), "graphql_requests_total", "graphql_errors_total"))
+
+	families, err := registry.Gather()
+	if !assert.NoError(t, err) {
+		return
+	}
+	sums := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if histogram := metric.GetHistogram(); histogram != nil {
+				var labels []string
+				for _, label := range metric.GetLabel() {
+					labels = append(labels, label.GetValue())
+				}
+				sums[family.GetName()+"{"+strings.Join(labels, ",")+"}"] = histogram.GetSampleSum()
+			}
+		}
+	}
+	// the operation which can not be parsed has no latency
+	assert.Equal(t, map[string]float64{
+		"graphql_request_duration_seconds{query}":               4,
+		"graphql_request_duration_seconds{unknown}":             0,
+		"graphql_resolver_duration_seconds{fail,billing,Query}": 0,
+		"graphql_resolver_duration_seconds{hero,,Query}":        4,
+	}, sums)
+}
+
+func TestCollector_TrackSubscription(t *testing.T) {
+	collector := metrics.New("graphql")
+	responses := make(chan *execution.Response)
+	tracked := collector.TrackSubscription(context.Background(), responses)
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector))
+
+	responses <- &execution.Response{Data: "hero"}
+	assert.Equal(t, "hero", (<-tracked).Data)
+	close(responses)
+	_, ok := <-tracked
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, testutil.ToFloat64(collector))
+
+	// the subscription ends with its context, though its responses are not closed
+	ctx, cancel := context.WithCancel(context.Background())
+	tracked = collector.TrackSubscription(ctx, make(chan *execution.Response))
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector))
+	cancel()
+	_, ok = <-tracked
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, testutil.ToFloat64(collector))
+}
+
+func TestDocumentCacheCollector(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() string { return "Luke" })
+	schema := build.MustBuild()
+	cache := execution.NewDocumentCache(1)
+	executor := &execution.Executor{DocumentCache: cache}
+
+	executor.Do(schema, execution.Params{Query: `{ hero }`})
+	executor.Do(schema, execution.Params{Query: `{ hero }`})
+	executor.Do(schema, execution.Params{Query: `{ a: hero }`})
+
+	assert.NoError(t, testutil.CollectAndCompare(metrics.NewDocumentCacheCollector("graphql", cache), strings.NewReader(`
+# HELP graphql_document_cache_evictions_total Number of the documents evicted from the cache.
+# TYPE graphql_document_cache_evictions_total counter
+graphql_document_cache_evictions_total 1
+# HELP graphql_document_cache_hits_total Number of the queries whose document was cached.
+# TYPE graphql_document_cache_hits_total counter
+graphql_document_cache_hits_total 1
+# HELP graphql_document_cache_misses_total Number of the queries parsed by the cache.
+# TYPE graphql_document_cache_misses_total counter
+graphql_document_cache_misses_total 2
+# HELP graphql_document_cache_size Number of the cached documents.
+# TYPE graphql_document_cache_size gauge
+graphql_document_cache_size 1
+`)))
+}
@@ -6,7 +6,6 @@ import (
 	"net/http/httputil"
 	"os"
 	"strings"
-	"time"
 )
 
 func Recovery() graphql.HandlerFunc {
@@ -43,7 +42,7 @@ func Recovery() graphql.HandlerFunc {
 
 func Logger() graphql.HandlerFunc {
 	return func(ctx *graphql.Context) {
-		startTime := time.Now()
+		startTime := ctx.Clock.Now()
 		logger := ctx.Logger
 		ctx.Set("logger", logger)
 		defer func() {
@@ -54,7 +53,7 @@ func Logger() graphql.HandlerFunc {
 			if operationName == "" {
 				operationName = "query"
 			}
-			logger.Printf("status %d | latencyTime %d | ip %s | method %s | operationName %s", statusCode, time.Now().Sub(startTime), clientIP,
+			logger.Printf("status %d | latencyTime %d | ip %s | method %s | operationName %s", statusCode, ctx.Clock.Since(startTime), clientIP,
 				reqMethod, operationName)
 		}()
 		ctx.Next()
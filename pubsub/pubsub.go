@@ -0,0 +1,116 @@
+// Package pubsub publishes typed events to in-memory subscribers by topic, so subscriptions work
+// without an external broker.
+//
+// Topics are made of segments separated by dots, eg. "chat.general". A subscription pattern matches
+// the topics whose segments it names, with "*" matching any one segment and a last ">" matching one or more
+// trailing segments: "chat.*" matches "chat.general", "orders.>" matches "orders.eu.created".
+//
+// Subscribe returns a schemabuilder stream, so a subscription field can return it as is:
+//
+//	messages := pubsub.New[*Message]()
+//	s.Subscription().FieldFunc("messages", func(args struct{ Room string }) (*schemabuilder.Stream[*Message], error) {
+//		return messages.Subscribe("chat." + args.Room)
+//	})
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// PubSub delivers the events of type T published on topics to the subscribers of matching patterns.
+// It is safe for concurrent use.
+type PubSub[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber[T]]struct{}
+}
+
+type subscriber[T any] struct {
+	pattern []string
+	events  chan T
+	done    chan struct{}
+	once    sync.Once
+}
+
+func New[T any]() *PubSub[T] {
+	return &PubSub[T]{subscribers: make(map[*subscriber[T]]struct{})}
+}
+
+// Publish sends payload to the subscribers of topic and returns how many received it. It waits for the
+// subscribers to take the event, unless ctx is done first. The subscribers are the ones of topic when Publish is
+// called, a slow subscriber does not hold up the subscriptions made meanwhile.
+func (p *PubSub[T]) Publish(ctx context.Context, topic string, payload T) (int, error) {
+	segments := strings.Split(topic, ".")
+	var subscribers []*subscriber[T]
+	p.mu.RLock()
+	for s := range p.subscribers {
+		if match(s.pattern, segments) {
+			subscribers = append(subscribers, s)
+		}
+	}
+	p.mu.RUnlock()
+	delivered := 0
+	for _, s := range subscribers {
+		select {
+		case s.events <- payload:
+			delivered++
+		case <-s.done:
+		case <-ctx.Done():
+			return delivered, ctx.Err()
+		}
+	}
+	return delivered, nil
+}
+
+// Subscribe subscribes to the topics matching pattern. The subscription lasts until the stream is closed,
+// which the executor does when the subscription of the client ends.
+func (p *PubSub[T]) Subscribe(pattern string) (*schemabuilder.Stream[T], error) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s := &subscriber[T]{pattern: segments, events: make(chan T), done: make(chan struct{})}
+	p.mu.Lock()
+	p.subscribers[s] = struct{}{}
+	p.mu.Unlock()
+	return schemabuilder.NewStream(s.events, func() { p.unsubscribe(s) }), nil
+}
+
+func (p *PubSub[T]) unsubscribe(s *subscriber[T]) {
+	s.once.Do(func() {
+		// unblocks the publishers sending to s
+		close(s.done)
+		p.mu.Lock()
+		delete(p.subscribers, s)
+		p.mu.Unlock()
+	})
+}
+
+func parsePattern(pattern string) ([]string, error) {
+	segments := strings.Split(pattern, ".")
+	for i, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("pubsub: empty segment in pattern %q", pattern)
+		}
+		if segment == ">" && i != len(segments)-1 {
+			return nil, fmt.Errorf("pubsub: > must be the last segment of pattern %q", pattern)
+		}
+	}
+	return segments, nil
+}
+
+func match(pattern, topic []string) bool {
+	for i, segment := range pattern {
+		if segment == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) || (segment != "*" && segment != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
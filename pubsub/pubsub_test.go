@@ -0,0 +1,130 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/pubsub"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPubSub(t *testing.T) {
+	ps := pubsub.New[string]()
+	ctx := context.Background()
+
+	_, err := ps.Subscribe("orders.>.created")
+	assert.EqualError(t, err, `pubsub: > must be the last segment of pattern "orders.>.created"`)
+	_, err = ps.Subscribe("orders..created")
+	assert.EqualError(t, err, `pubsub: empty segment in pattern "orders..created"`)
+
+	for _, test := range []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{pattern: "chat.general", matches: []string{"chat.general"}, misses: []string{"chat.random", "chat.general.pinned", "chat"}},
+		{pattern: "chat.*", matches: []string{"chat.general", "chat.random"}, misses: []string{"chat", "chat.general.pinned"}},
+		{pattern: "orders.>", matches: []string{"orders.eu", "orders.eu.created"}, misses: []string{"orders", "chat.eu"}},
+	} {
+		stream, err := ps.Subscribe(test.pattern)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		for _, topic := range test.matches {
+			go ps.Publish(ctx, topic, topic)
+			event, err := stream.Next(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, topic, event, test.pattern)
+		}
+		for _, topic := range test.misses {
+			delivered, err := ps.Publish(ctx, topic, topic)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, delivered, "%s published to %s", topic, test.pattern)
+		}
+		stream.Close()
+	}
+
+	t.Run("closed subscriptions do not block publishers", func(t *testing.T) {
+		stream, _ := ps.Subscribe("news")
+		stream.Close()
+		delivered, err := ps.Publish(ctx, "news", "hello")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+	})
+
+	t.Run("publishing waits for the subscribers until ctx is done", func(t *testing.T) {
+		stream, _ := ps.Subscribe("news")
+		defer stream.Close()
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := ps.Publish(ctx, "news", "hello")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("slow subscribers do not block the subscriptions", func(t *testing.T) {
+		slow, _ := ps.Subscribe("news")
+		defer slow.Close()
+		published := make(chan struct{})
+		go func() {
+			defer close(published)
+			ps.Publish(ctx, "news", "hello")
+		}()
+		// the publisher is waiting for slow, the other subscriptions are made and closed meanwhile
+		time.Sleep(10 * time.Millisecond)
+		stream, err := ps.Subscribe("weather")
+		assert.NoError(t, err)
+		stream.Close()
+		event, err := slow.Next(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", event)
+		<-published
+	})
+}
+
+type message struct {
+	Text string `graphql:"text"`
+}
+
+func TestPubSub_Subscription(t *testing.T) {
+	messages := pubsub.New[*message]()
+	build := schemabuilder.NewSchema()
+	build.Object("Message", message{})
+	build.Query().FieldFunc("ping", func() string { return "pong" })
+	build.Subscription().FieldFunc("messages", func(args struct {
+		Room string `graphql:"room"`
+	}) (*schemabuilder.Stream[*message], error) {
+		return messages.Subscribe("chat." + args.Room)
+	})
+	schema := build.MustBuild()
+
+	doc, parseErrs := internal.Parse(`subscription { messages(room: "general") { text } }`)
+	if !assert.Nil(t, parseErrs) {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	responses, err := execution.Subscribe(ctx, schema, doc, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	go func() {
+		messages.Publish(ctx, "chat.random", &message{Text: "elsewhere"})
+		messages.Publish(ctx, "chat.general", &message{Text: "hello"})
+	}()
+	response := <-responses
+	assert.Nil(t, response.Errors)
+	assert.Equal(t, map[string]interface{}{"messages": map[string]interface{}{"text": "hello"}}, response.Data)
+
+	cancel()
+	for range responses {
+	}
+	// the subscription is gone once the responses are closed
+	delivered, err := messages.Publish(context.Background(), "chat.general", &message{Text: "bye"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+}
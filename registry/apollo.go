@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultApolloEndpoint is the endpoint of the Platform API of Apollo GraphOS.
+const DefaultApolloEndpoint = "https://api.apollographql.com/api/graphql"
+
+// Apollo is the registry of a variant of a graph of Apollo GraphOS, which is not federated, authenticated with an
+// API key of the graph.
+type Apollo struct {
+	APIKey string
+	// GraphRef is the graph and the variant of the schema, eg. "my-graph@current".
+	GraphRef string
+	// Endpoint is the Platform API of GraphOS, it is DefaultApolloEndpoint by default.
+	Endpoint string
+	// Client sends the requests, it is http.DefaultClient by default.
+	Client *http.Client
+}
+
+// NewApollo returns the registry of the variant of graphRef, eg. "my-graph@current", in Apollo GraphOS.
+func NewApollo(apiKey, graphRef string) *Apollo {
+	return &Apollo{APIKey: apiKey, GraphRef: graphRef, Endpoint: DefaultApolloEndpoint, Client: http.DefaultClient}
+}
+
+func (a *Apollo) post(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	header := http.Header{"X-Api-Key": {a.APIKey}, "Apollographql-Client-Name": {"graphql-go"}}
+	return post(ctx, a.Client, a.Endpoint, header, query, variables, data)
+}
+
+const apolloSchemaQuery = `query schema($ref: ID!) {
+  variant(ref: $ref) {
+    __typename
+    ... on GraphVariant {
+      latestPublication {
+        schema {
+          document
+        }
+      }
+    }
+    ... on InvalidRefFormat {
+      message
+    }
+  }
+}`
+
+func (a *Apollo) Schema(ctx context.Context) (string, error) {
+	var data struct {
+		Variant *struct {
+			Typename          string `json:"__typename"`
+			Message           string `json:"message"`
+			LatestPublication *struct {
+				Schema struct {
+					Document string `json:"document"`
+				} `json:"schema"`
+			} `json:"latestPublication"`
+		} `json:"variant"`
+	}
+	if err := a.post(ctx, apolloSchemaQuery, map[string]interface{}{"ref": a.GraphRef}, &data); err != nil {
+		return "", err
+	}
+	switch {
+	case data.Variant == nil:
+		return "", nil
+	case data.Variant.Typename != "GraphVariant":
+		return "", fmt.Errorf("invalid graph ref %s: %s", a.GraphRef, data.Variant.Message)
+	case data.Variant.LatestPublication == nil:
+		return "", nil
+	}
+	return data.Variant.LatestPublication.Schema.Document, nil
+}
+
+const apolloPublishMutation = `mutation publish($graphId: ID!, $variant: String!, $schemaDocument: String!, $gitContext: GitContextInput) {
+  graph(id: $graphId) {
+    uploadSchema(schemaDocument: $schemaDocument, tag: $variant, gitContext: $gitContext) {
+      code
+      message
+      success
+    }
+  }
+}`
+
+func (a *Apollo) Publish(ctx context.Context, sdl string, metadata Metadata) error {
+	graphID, variant, found := strings.Cut(a.GraphRef, "@")
+	if !found {
+		variant = "current"
+	}
+	var data struct {
+		Graph *struct {
+			UploadSchema *struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+				Success bool   `json:"success"`
+			} `json:"uploadSchema"`
+		} `json:"graph"`
+	}
+	variables := map[string]interface{}{
+		"graphId":        graphID,
+		"variant":        variant,
+		"schemaDocument": sdl,
+		"gitContext":     map[string]interface{}{"committer": metadata.Author, "commit": metadata.Commit},
+	}
+	if err := a.post(ctx, apolloPublishMutation, variables, &data); err != nil {
+		return err
+	}
+	switch {
+	case data.Graph == nil || data.Graph.UploadSchema == nil:
+		return fmt.Errorf("unknown graph %s", graphID)
+	case !data.Graph.UploadSchema.Success:
+		return fmt.Errorf("schema rejected by Apollo (%s): %s", data.Graph.UploadSchema.Code,
+			data.Graph.UploadSchema.Message)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultHiveEndpoint is the endpoint of the GraphQL API of GraphQL Hive.
+const DefaultHiveEndpoint = "https://app.graphql-hive.com/graphql"
+
+// Hive is the registry of a target of GraphQL Hive, or of a self-hosted Hive, authenticated with a registry access
+// token of the target.
+type Hive struct {
+	Token string
+	// Endpoint is the GraphQL API of Hive, it is DefaultHiveEndpoint by default.
+	Endpoint string
+	// Client sends the requests, it is http.DefaultClient by default.
+	Client *http.Client
+}
+
+// NewHive returns the registry of the target of token in GraphQL Hive.
+func NewHive(token string) *Hive {
+	return &Hive{Token: token, Endpoint: DefaultHiveEndpoint, Client: http.DefaultClient}
+}
+
+func (h *Hive) post(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	return post(ctx, h.Client, h.Endpoint, http.Header{"Authorization": {"Bearer " + h.Token}}, query, variables,
+		data)
+}
+
+const hiveSchemaQuery = `query { latestValidVersion { sdl } }`
+
+func (h *Hive) Schema(ctx context.Context) (string, error) {
+	var data struct {
+		LatestValidVersion *struct {
+			SDL string `json:"sdl"`
+		} `json:"latestValidVersion"`
+	}
+	if err := h.post(ctx, hiveSchemaQuery, nil, &data); err != nil {
+		return "", err
+	}
+	if data.LatestValidVersion == nil {
+		return "", nil
+	}
+	return data.LatestValidVersion.SDL, nil
+}
+
+const hivePublishMutation = `mutation schemaPublish($input: SchemaPublishInput!) {
+  schemaPublish(input: $input) {
+    __typename
+    ... on SchemaPublishError {
+      errors {
+        nodes {
+          message
+        }
+      }
+    }
+    ... on SchemaPublishMissingServiceError {
+      message
+    }
+    ... on SchemaPublishMissingUrlError {
+      message
+    }
+  }
+}`
+
+func (h *Hive) Publish(ctx context.Context, sdl string, metadata Metadata) error {
+	var data struct {
+		SchemaPublish struct {
+			Typename string `json:"__typename"`
+			Message  string `json:"message"`
+			Errors   struct {
+				Nodes []graphQLError `json:"nodes"`
+			} `json:"errors"`
+		} `json:"schemaPublish"`
+	}
+	input := map[string]interface{}{"sdl": sdl, "author": metadata.Author, "commit": metadata.Commit}
+	if err := h.post(ctx, hivePublishMutation, map[string]interface{}{"input": input}, &data); err != nil {
+		return err
+	}
+	result := data.SchemaPublish
+	if strings.HasSuffix(result.Typename, "Success") {
+		return nil
+	}
+	messages := []string{result.Message}
+	for _, err := range result.Errors.Nodes {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Errorf("schema rejected by Hive (%s): %s", result.Typename,
+		strings.TrimSpace(strings.Join(messages, " ")))
+}
@@ -0,0 +1,144 @@
+// Package registry publishes the versions of a schema to a schema registry, GraphQL Hive or Apollo GraphOS, and
+// checks the proposed versions against the published one before they are deployed:
+//
+//	r := registry.NewHive(os.Getenv("HIVE_TOKEN"))
+//	changes, err := registry.Publish(ctx, r, sdl, registry.Metadata{Commit: commit})
+//	var breaking *registry.BreakingChangesError
+//	if errors.As(err, &breaking) {
+//		log.Fatal(breaking.Changes)
+//	}
+//
+// The changes are computed by schemadiff, from the latest version published, so that the checks are the same
+// whatever the registry.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemadiff"
+	"github.com/shyptr/graphql/validation"
+)
+
+// Registry stores the versions of a schema.
+type Registry interface {
+	// Schema returns the SDL of the latest version published, "" when there is none.
+	Schema(ctx context.Context) (string, error)
+	// Publish publishes sdl as the latest version of the schema.
+	Publish(ctx context.Context, sdl string, metadata Metadata) error
+}
+
+// Metadata tells where a version of a schema comes from.
+type Metadata struct {
+	// Author is the author of the version, eg. the author of its commit.
+	Author string
+	// Commit is the commit of the version, eg. its hash.
+	Commit string
+}
+
+// BreakingChangesError is the error of Publish when a version breaks the operations of the clients.
+type BreakingChangesError struct {
+	Changes []*schemadiff.Change
+}
+
+func (e *BreakingChangesError) Error() string {
+	messages := make([]string, len(e.Changes))
+	for i, change := range e.Changes {
+		messages[i] = change.Message
+	}
+	return "breaking changes: " + strings.Join(messages, " ")
+}
+
+// Check returns the changes of sdl from the latest version published to r, all of them being safe when nothing was
+// published yet. sdl is checked by validation.ValidateSDL first, the syntax and validation errors of which are
+// returned as a MultiError.
+func Check(ctx context.Context, r Registry, sdl string) ([]*schemadiff.Change, error) {
+	doc, errs := internal.ParseDocument(sdl)
+	if errs != nil {
+		return nil, errs
+	}
+	if errs := validation.ValidateSDL(doc); errs != nil {
+		return nil, errs
+	}
+	published, err := r.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	publishedDoc := &ast.Document{}
+	if strings.TrimSpace(published) != "" {
+		if publishedDoc, errs = internal.ParseDocument(published); errs != nil {
+			return nil, fmt.Errorf("published schema: %w", errs)
+		}
+	}
+	return schemadiff.Diff(publishedDoc, doc), nil
+}
+
+// Publish checks sdl, see Check, and publishes it to r unless it has breaking changes, which are returned as
+// a *BreakingChangesError. The versions with breaking changes may be published by Registry.Publish, once the
+// clients are updated.
+func Publish(ctx context.Context, r Registry, sdl string, metadata Metadata) ([]*schemadiff.Change, error) {
+	changes, err := Check(ctx, r, sdl)
+	if err != nil {
+		return nil, err
+	}
+	if breaking := schemadiff.BreakingChanges(changes); breaking != nil {
+		return changes, &BreakingChangesError{Changes: breaking}
+	}
+	if err := r.Publish(ctx, sdl, metadata); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// graphQLError is an error of the GraphQL API of a registry.
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// post sends a GraphQL operation to the API of a registry and decodes its data into data.
+func post(ctx context.Context, client *http.Client, endpoint string, header http.Header, query string,
+	variables map[string]interface{}, data interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("registry request rejected with %s: %s", resp.Status, message)
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, err := range result.Errors {
+			messages[i] = err.Message
+		}
+		return fmt.Errorf("registry error: %s", strings.Join(messages, " "))
+	}
+	return json.Unmarshal(result.Data, data)
+}
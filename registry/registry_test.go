@@ -0,0 +1,117 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shyptr/graphql/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a GraphQL request received by a registry.
+type request struct {
+	Header    http.Header
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// registryServer returns a server answering the requests with the responses, in their order.
+func registryServer(t *testing.T, responses ...string) (*httptest.Server, *[]request) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Header: r.Header}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		if !assert.LessOrEqual(t, len(requests), len(responses)) {
+			return
+		}
+		w.Write([]byte(responses[len(requests)-1]))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHive(t *testing.T) {
+	server, requests := registryServer(t,
+		`{"data": {"latestValidVersion": {"sdl": "type Query { a: Int b: Int }"}}}`,
+		`{"data": {"latestValidVersion": {"sdl": "type Query { a: Int b: Int }"}}}`,
+		`{"data": {"schemaPublish": {"__typename": "SchemaPublishSuccess"}}}`,
+		`{"data": {"latestValidVersion": null}}`,
+		`{"data": {"schemaPublish": {"__typename": "SchemaPublishError", "errors": {"nodes": [{"message": "Invalid"}]}}}}`,
+	)
+	hive := registry.NewHive("token")
+	hive.Endpoint = server.URL
+	ctx := context.Background()
+
+	changes, err := registry.Publish(ctx, hive, "type Query { a: Int }", registry.Metadata{})
+	var breaking *registry.BreakingChangesError
+	if assert.True(t, errors.As(err, &breaking)) {
+		assert.EqualError(t, err, "breaking changes: Field Query.b was removed.")
+		assert.Len(t, breaking.Changes, 1)
+	}
+	assert.Len(t, changes, 1)
+
+	changes, err = registry.Publish(ctx, hive, "type Query { a: Int b: Int c: Int }",
+		registry.Metadata{Author: "jane", Commit: "abc"})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "Query.c", changes[0].Path)
+	}
+	if assert.Len(t, *requests, 3) {
+		publish := (*requests)[2]
+		assert.Equal(t, "Bearer token", publish.Header.Get("Authorization"))
+		assert.True(t, strings.HasPrefix(publish.Query, "mutation schemaPublish"))
+		assert.Equal(t, map[string]interface{}{"input": map[string]interface{}{
+			"sdl": "type Query { a: Int b: Int c: Int }", "author": "jane", "commit": "abc",
+		}}, publish.Variables)
+	}
+
+	// nothing published yet
+	changes, err = registry.Check(ctx, hive, "type Query { a: Int }")
+	assert.NoError(t, err)
+	assert.Equal(t, "safe: The query root type Query was added.", changes[0].String())
+
+	assert.EqualError(t, hive.Publish(ctx, "type Query { a: Int }", registry.Metadata{}),
+		"schema rejected by Hive (SchemaPublishError): Invalid")
+
+	// the invalid schemas are not checked against the registry
+	_, err = registry.Check(ctx, hive, "type Query { a: Unknown }")
+	assert.EqualError(t, err, `[graphql: Unknown type "Unknown". (1:17)]`)
+	assert.Len(t, *requests, 5)
+}
+
+func TestApollo(t *testing.T) {
+	server, requests := registryServer(t,
+		`{"data": {"variant": {"__typename": "GraphVariant", "latestPublication": {"schema": {"document": "type Query { a: Int }"}}}}}`,
+		`{"data": {"graph": {"uploadSchema": {"code": "UPLOAD_SUCCESS", "success": true}}}}`,
+		`{"data": {"variant": {"__typename": "InvalidRefFormat", "message": "Invalid ref"}}}`,
+		`{"errors": [{"message": "Unauthorized"}]}`,
+	)
+	apollo := registry.NewApollo("key", "graph@prod")
+	apollo.Endpoint = server.URL
+	ctx := context.Background()
+
+	changes, err := registry.Publish(ctx, apollo, "type Query { a: Int! }", registry.Metadata{Commit: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "safe: Field Query.a changed type from Int to Int!.", changes[0].String())
+	if assert.Len(t, *requests, 2) {
+		assert.Equal(t, "key", (*requests)[0].Header.Get("X-Api-Key"))
+		assert.Equal(t, map[string]interface{}{"ref": "graph@prod"}, (*requests)[0].Variables)
+		assert.Equal(t, map[string]interface{}{
+			"graphId":        "graph",
+			"variant":        "prod",
+			"schemaDocument": "type Query { a: Int! }",
+			"gitContext":     map[string]interface{}{"committer": "", "commit": "abc"},
+		}, (*requests)[1].Variables)
+	}
+
+	_, err = apollo.Schema(ctx)
+	assert.EqualError(t, err, "invalid graph ref graph@prod: Invalid ref")
+	_, err = apollo.Schema(ctx)
+	assert.EqualError(t, err, "registry error: Unauthorized")
+}
@@ -0,0 +1,192 @@
+package relay
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// Resolver resolves a paginated field: it returns the items of page in the order of the list, along with the
+// total count of the items.
+type Resolver[T any] func(ctx context.Context, page Page) ([]T, int, error)
+
+// Page is the window of items which a Resolver returns.
+type Page struct {
+	// Offset is the offset of the first item to return, with OffsetCursors.
+	Offset int
+	// After and Before are the keys of the items which the returned ones follow and precede, with KeysetCursors.
+	After, Before *string
+	// Limit is the maximum count of items to return, -1 for no limit. A Limit of 0 asks for the total count only.
+	Limit int
+	// Last asks for the last items before Before rather than for the first ones after After, with KeysetCursors.
+	Last bool
+}
+
+// Cursors paginate a field, encoding the cursors of its edges and decoding the ones of its arguments.
+type Cursors[T any] interface {
+	// Paginate returns the connection of the items selected by args, fetched with resolve.
+	Paginate(ctx context.Context, args Args, resolve Resolver[T]) (Connection[T], error)
+}
+
+// Paginate adds to object the connection field name, which takes the Args and is resolved by resolve with
+// cursors, OffsetCursors when nil. The connection and the edge of T are registered along, named after T, eg.
+// UserConnection and UserEdge for *User. The options are the ones of schemabuilder.Object.FieldFunc.
+//
+//	relay.Paginate(schema, schema.Query(), "users", func(ctx context.Context, page relay.Page) ([]*User, int, error) {
+//		return db.Users(ctx, page.Offset, page.Limit)
+//	}, nil)
+func Paginate[T any](schema *schemabuilder.Schema, object *schemabuilder.Object, name string, resolve Resolver[T],
+	cursors Cursors[T], options ...interface{}) {
+	if cursors == nil {
+		cursors = OffsetCursors[T]()
+	}
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	Register[T](schema, typ.Name())
+	object.FieldFunc(name, func(ctx context.Context, args Args) (Connection[T], error) {
+		if args.First != nil && args.Last != nil {
+			return Connection[T]{}, fmt.Errorf("cannot use both first and last together")
+		}
+		if args.First != nil && *args.First < 0 || args.Last != nil && *args.Last < 0 {
+			return Connection[T]{}, fmt.Errorf("first/last cannot be a negative integer")
+		}
+		return cursors.Paginate(ctx, args, resolve)
+	}, options...)
+}
+
+type offsetCursors[T any] struct{}
+
+// OffsetCursors paginate with the offsets of the items, see Cursor. The resolver is asked for the items from
+// Page.Offset, and for the total count only when the last items are requested without a before cursor.
+func OffsetCursors[T any]() Cursors[T] {
+	return offsetCursors[T]{}
+}
+
+func (offsetCursors[T]) Paginate(ctx context.Context, args Args, resolve Resolver[T]) (Connection[T], error) {
+	// the items are bounded by the cursors from lower to upper, -1 when the upper bound is unknown
+	lower, upper := 0, -1
+	if args.After != nil {
+		after, err := Offset(*args.After)
+		if err != nil {
+			return Connection[T]{}, err
+		}
+		lower = after + 1
+	}
+	if args.Before != nil {
+		before, err := Offset(*args.Before)
+		if err != nil {
+			return Connection[T]{}, err
+		}
+		upper = max(before, lower)
+	}
+	if args.Last != nil && upper < 0 {
+		_, count, err := resolve(ctx, Page{Offset: lower, Limit: 0})
+		if err != nil {
+			return Connection[T]{}, err
+		}
+		upper = max(count, lower)
+	}
+
+	start, end := lower, upper
+	if args.First != nil && (end < 0 || start+*args.First < end) {
+		end = start + *args.First
+	}
+	if args.Last != nil {
+		start = max(start, end-*args.Last)
+	}
+	limit := -1
+	if end >= 0 {
+		limit = end - start
+	}
+	items, count, err := resolve(ctx, Page{Offset: start, Limit: limit})
+	if err != nil {
+		return Connection[T]{}, err
+	}
+	if limit >= 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	if upper < 0 || upper > count {
+		upper = count
+	}
+
+	connection := newConnection(items, count, func(i int, _ T) string { return Cursor(start + i) })
+	connection.PageInfo.HasPreviousPage = args.Last != nil && start > lower
+	connection.PageInfo.HasNextPage = args.First != nil && start+len(items) < upper
+	return connection, nil
+}
+
+const keysetPrefix = "keyset:"
+
+type keysetCursors[T any] struct {
+	key func(T) string
+}
+
+// KeysetCursors paginate with the keys of the items, eg. their ids when the list is sorted by id, which stay
+// valid when items are added or removed. The resolver is asked for the items following Page.After, or for the
+// last ones preceding Page.Before, one more item than requested telling whether there is another page.
+func KeysetCursors[T any](key func(item T) string) Cursors[T] {
+	return keysetCursors[T]{key: key}
+}
+
+func (c keysetCursors[T]) Paginate(ctx context.Context, args Args, resolve Resolver[T]) (Connection[T], error) {
+	page := Page{Limit: -1, Last: args.Last != nil}
+	for _, bound := range []struct {
+		cursor *string
+		key    **string
+	}{{args.After, &page.After}, {args.Before, &page.Before}} {
+		if bound.cursor == nil {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(*bound.cursor)
+		if err != nil || !strings.HasPrefix(string(decoded), keysetPrefix) {
+			return Connection[T]{}, fmt.Errorf("invalid cursor %q", *bound.cursor)
+		}
+		key := strings.TrimPrefix(string(decoded), keysetPrefix)
+		*bound.key = &key
+	}
+	limit := -1
+	if args.First != nil {
+		limit = *args.First
+	} else if args.Last != nil {
+		limit = *args.Last
+	}
+	if limit >= 0 {
+		page.Limit = limit + 1
+	}
+
+	items, count, err := resolve(ctx, page)
+	if err != nil {
+		return Connection[T]{}, err
+	}
+	more := limit >= 0 && len(items) > limit
+	if more && page.Last {
+		items = items[len(items)-limit:]
+	} else if more {
+		items = items[:limit]
+	}
+
+	connection := newConnection(items, count, func(_ int, item T) string {
+		return base64.StdEncoding.EncodeToString([]byte(keysetPrefix + c.key(item)))
+	})
+	connection.PageInfo.HasPreviousPage = page.Last && more
+	connection.PageInfo.HasNextPage = !page.Last && more
+	return connection, nil
+}
+
+func newConnection[T any](items []T, count int, cursor func(i int, item T) string) Connection[T] {
+	connection := Connection[T]{TotalCount: count, Edges: make([]Edge[T], len(items))}
+	for i, item := range items {
+		connection.Edges[i] = Edge[T]{Node: item, Cursor: cursor(i, item)}
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	return connection
+}
@@ -0,0 +1,133 @@
+package relay_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/relay"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+type Post struct {
+	ID    int    `graphql:"id"`
+	Title string `graphql:"title"`
+}
+
+var posts = []*Post{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}
+
+func postsSchema(pages *[]relay.Page) *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Object("Post", Post{})
+	relay.Paginate(build, build.Query(), "posts", func(ctx context.Context, page relay.Page) ([]*Post, int, error) {
+		*pages = append(*pages, page)
+		end := len(posts)
+		if page.Limit >= 0 {
+			end = min(end, page.Offset+page.Limit)
+		}
+		return posts[page.Offset:end], len(posts), nil
+	}, nil)
+	relay.Paginate(build, build.Query(), "postsByID", func(ctx context.Context, page relay.Page) ([]*Post, int, error) {
+		*pages = append(*pages, page)
+		var window []*Post
+		for _, post := range posts {
+			id := strconv.Itoa(post.ID)
+			if page.After != nil && id <= *page.After || page.Before != nil && id >= *page.Before {
+				continue
+			}
+			window = append(window, post)
+		}
+		if page.Limit >= 0 && len(window) > page.Limit {
+			if page.Last {
+				window = window[len(window)-page.Limit:]
+			} else {
+				window = window[:page.Limit]
+			}
+		}
+		return window, len(posts), nil
+	}, relay.KeysetCursors(func(post *Post) string { return strconv.Itoa(post.ID) }))
+	return build.MustBuild()
+}
+
+func query(t *testing.T, schema *internal.Schema, q string) string {
+	result, errs := execution.Do(schema, execution.Params{Query: q})
+	assert.Equal(t, 0, len(errs), "%v", errs)
+	marshal, _ := json.Marshal(result)
+	return string(marshal)
+}
+
+func TestPaginate(t *testing.T) {
+	var pages []relay.Page
+	schema := postsSchema(&pages)
+
+	t.Run("generates the connection types", func(t *testing.T) {
+		var names []string
+		for name := range schema.TypeMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		assert.Equal(t, []string{"Boolean", "Int", "PageInfo", "Post", "PostConnection", "PostEdge", "Query", "String"}, names)
+	})
+
+	t.Run("paginates forward with offset cursors", func(t *testing.T) {
+		pages = nil
+		assert.JSONEq(t, `{"posts": {
+			"totalCount": 5,
+			"edges": [{"cursor": "YXJyYXljb25uZWN0aW9uOjI=", "node": {"id": 3}}],
+			"pageInfo": {"hasNextPage": true, "hasPreviousPage": false}
+		}}`, query(t, schema, `{
+			posts(first: 1, after: "YXJyYXljb25uZWN0aW9uOjE=") {
+				totalCount edges { cursor node { id } } pageInfo { hasNextPage hasPreviousPage }
+			}
+		}`))
+		assert.Equal(t, []relay.Page{{Offset: 2, Limit: 1}}, pages)
+	})
+
+	t.Run("paginates backward with offset cursors", func(t *testing.T) {
+		pages = nil
+		assert.JSONEq(t, `{"posts": {
+			"edges": [{"node": {"id": 4}}, {"node": {"id": 5}}],
+			"pageInfo": {"hasNextPage": false, "hasPreviousPage": true}
+		}}`, query(t, schema, `{
+			posts(last: 2) { edges { node { id } } pageInfo { hasNextPage hasPreviousPage } }
+		}`))
+		// the total count is asked first to find where the last items start
+		assert.Equal(t, []relay.Page{{Offset: 0, Limit: 0}, {Offset: 3, Limit: 2}}, pages)
+	})
+
+	t.Run("paginates with keyset cursors", func(t *testing.T) {
+		pages = nil
+		first := query(t, schema, `{ postsByID(first: 2) { edges { cursor node { id } } pageInfo { hasNextPage endCursor } } }`)
+		var result struct {
+			PostsByID struct {
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+		assert.NoError(t, json.Unmarshal([]byte(first), &result))
+		assert.True(t, result.PostsByID.PageInfo.HasNextPage)
+
+		assert.JSONEq(t, `{"postsByID": {
+			"edges": [{"node": {"id": 3}}, {"node": {"id": 4}}, {"node": {"id": 5}}],
+			"pageInfo": {"hasNextPage": false}
+		}}`, query(t, schema, `{
+			postsByID(first: 3, after: "`+result.PostsByID.PageInfo.EndCursor+`") { edges { node { id } } pageInfo { hasNextPage } }
+		}`))
+		after := "2"
+		assert.Equal(t, []relay.Page{{Limit: 3}, {After: &after, Limit: 4}}, pages)
+	})
+
+	t.Run("rejects the invalid cursors", func(t *testing.T) {
+		executor := &execution.Executor{UnmaskedErrors: true}
+		_, errs := executor.Do(schema, execution.Params{Query: `{ postsByID(after: "YXJyYXljb25uZWN0aW9uOjE=") { totalCount } }`})
+		assert.Equal(t, 1, len(errs))
+		assert.Contains(t, errs[0].Error(), `invalid cursor "YXJyYXljb25uZWN0aW9uOjE="`)
+	})
+}
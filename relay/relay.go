@@ -0,0 +1,156 @@
+// Package relay builds the connections of the Relay cursor connections specification,
+// https://relay.dev/graphql/connections.htm, from a slice or from a paged source of items.
+//
+// The connection types are generic, register them with the schema for each type of node:
+//
+//	relay.Register[*User](schema, "User")
+//	schema.Query().FieldFunc("users", func(ctx context.Context, args relay.Args) (relay.Connection[*User], error) {
+//		return relay.FromSlice(users, args)
+//	})
+//
+// The cursors are the base64 encoded offsets of the items, so the items should be in a stable order.
+//
+// Paginate builds a whole connection field from a resolver returning a page of items and their total count,
+// with the offset cursors or with the keyset ones.
+package relay
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shyptr/graphql/schemabuilder"
+)
+
+// Connection is a page of the items of type T, as an edge per item.
+type Connection[T any] struct {
+	TotalCount int       `graphql:"totalCount"`
+	Edges      []Edge[T] `graphql:"edges"`
+	PageInfo   PageInfo  `graphql:"pageInfo"`
+}
+
+// Edge is an item of a connection along with its cursor.
+type Edge[T any] struct {
+	Node   T      `graphql:"node"`
+	Cursor string `graphql:"cursor"`
+}
+
+// PageInfo tells whether there are items around the page of a connection, and the cursors of its bounds.
+type PageInfo struct {
+	HasNextPage     bool    `graphql:"hasNextPage"`
+	HasPreviousPage bool    `graphql:"hasPreviousPage"`
+	StartCursor     *string `graphql:"startCursor"`
+	EndCursor       *string `graphql:"endCursor"`
+}
+
+// Args are the pagination arguments of a connection field: the first items after a cursor, or the last ones
+// before a cursor.
+type Args struct {
+	First  *int    `graphql:"first"`
+	After  *string `graphql:"after"`
+	Last   *int    `graphql:"last"`
+	Before *string `graphql:"before"`
+}
+
+// Pager is a paged source of items, eg. a table queried with OFFSET and LIMIT.
+type Pager[T any] interface {
+	// Count returns the total count of the items.
+	Count(ctx context.Context) (int, error)
+	// Page returns at most limit items from offset.
+	Page(ctx context.Context, offset, limit int) ([]T, error)
+}
+
+// Register registers the connection and the edge of the nodes of type T with schema, eg. UserConnection and
+// UserEdge for name User, along with PageInfo.
+func Register[T any](schema *schemabuilder.Schema, name string) {
+	schema.Object(name+"Connection", Connection[T]{})
+	schema.Object(name+"Edge", Edge[T]{})
+	schema.Object("PageInfo", PageInfo{})
+}
+
+// FromSlice returns the connection of the page of items selected by args.
+func FromSlice[T any](items []T, args Args) (Connection[T], error) {
+	return FromPager[T](context.Background(), slicePager[T](items), args)
+}
+
+type slicePager[T any] []T
+
+func (s slicePager[T]) Count(context.Context) (int, error) {
+	return len(s), nil
+}
+
+func (s slicePager[T]) Page(_ context.Context, offset, limit int) ([]T, error) {
+	return s[offset : offset+limit], nil
+}
+
+// FromPager returns the connection of the page of the items of pager selected by args, fetching that page only.
+// HasPreviousPage is set when paginating backward with last and HasNextPage when paginating forward with first.
+func FromPager[T any](ctx context.Context, pager Pager[T], args Args) (Connection[T], error) {
+	if args.First != nil && args.Last != nil {
+		return Connection[T]{}, fmt.Errorf("cannot use both first and last together")
+	}
+	if args.First != nil && *args.First < 0 || args.Last != nil && *args.Last < 0 {
+		return Connection[T]{}, fmt.Errorf("first/last cannot be a negative integer")
+	}
+	count, err := pager.Count(ctx)
+	if err != nil {
+		return Connection[T]{}, err
+	}
+
+	// the page is the items from start to end, bounded by the cursors then by first or last
+	lower, upper := 0, count
+	if args.After != nil {
+		after, err := Offset(*args.After)
+		if err != nil {
+			return Connection[T]{}, err
+		}
+		lower = min(max(lower, after+1), upper)
+	}
+	if args.Before != nil {
+		before, err := Offset(*args.Before)
+		if err != nil {
+			return Connection[T]{}, err
+		}
+		upper = max(min(upper, before), lower)
+	}
+	start, end := lower, upper
+	if args.First != nil {
+		end = min(end, start+*args.First)
+	}
+	if args.Last != nil {
+		start = max(start, end-*args.Last)
+	}
+
+	var items []T
+	if end > start {
+		if items, err = pager.Page(ctx, start, end-start); err != nil {
+			return Connection[T]{}, err
+		}
+	}
+	connection := newConnection(items, count, func(i int, _ T) string { return Cursor(start + i) })
+	connection.PageInfo.HasPreviousPage = args.Last != nil && start > lower
+	connection.PageInfo.HasNextPage = args.First != nil && end < upper
+	return connection, nil
+}
+
+const cursorPrefix = "arrayconnection:"
+
+// Cursor returns the cursor of the item at offset.
+func Cursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// Offset returns the offset of the item of cursor.
+func Offset(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
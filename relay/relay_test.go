@@ -0,0 +1,125 @@
+package relay_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/relay"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(i int) *int { return &i }
+
+func strPtr(s string) *string { return &s }
+
+func names(c relay.Connection[string]) []string {
+	var names []string
+	for _, edge := range c.Edges {
+		names = append(names, edge.Node)
+	}
+	return names
+}
+
+func TestFromSlice(t *testing.T) {
+	letters := []string{"A", "B", "C", "D", "E"}
+
+	for _, tt := range []struct {
+		name                 string
+		args                 relay.Args
+		names                []string
+		hasPrevious, hasNext bool
+	}{
+		{name: "returns all the items without arguments", names: letters},
+		{name: "returns the first items", args: relay.Args{First: intPtr(2)}, names: []string{"A", "B"}, hasNext: true},
+		{name: "returns the first items after a cursor", args: relay.Args{First: intPtr(2), After: strPtr(relay.Cursor(1))},
+			names: []string{"C", "D"}, hasNext: true},
+		{name: "returns the last items", args: relay.Args{Last: intPtr(2)}, names: []string{"D", "E"}, hasPrevious: true},
+		{name: "returns the last items before a cursor", args: relay.Args{Last: intPtr(2), Before: strPtr(relay.Cursor(4))},
+			names: []string{"C", "D"}, hasPrevious: true},
+		{name: "returns the items between cursors", args: relay.Args{After: strPtr(relay.Cursor(0)), Before: strPtr(relay.Cursor(4))},
+			names: []string{"B", "C", "D"}},
+		{name: "returns no item after the last one", args: relay.Args{First: intPtr(2), After: strPtr(relay.Cursor(4))}},
+		{name: "has no next page when first covers the rest", args: relay.Args{First: intPtr(10)}, names: letters},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := relay.FromSlice(letters, tt.args)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.names, names(c))
+			assert.Equal(t, 5, c.TotalCount)
+			assert.Equal(t, tt.hasPrevious, c.PageInfo.HasPreviousPage)
+			assert.Equal(t, tt.hasNext, c.PageInfo.HasNextPage)
+		})
+	}
+
+	t.Run("sets the cursors of the page bounds", func(t *testing.T) {
+		c, err := relay.FromSlice(letters, relay.Args{First: intPtr(2), After: strPtr(relay.Cursor(0))})
+		assert.NoError(t, err)
+		assert.Equal(t, relay.Cursor(1), *c.PageInfo.StartCursor)
+		assert.Equal(t, relay.Cursor(2), *c.PageInfo.EndCursor)
+		offset, err := relay.Offset(c.Edges[1].Cursor)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, offset)
+	})
+
+	t.Run("rejects the invalid arguments", func(t *testing.T) {
+		_, err := relay.FromSlice(letters, relay.Args{First: intPtr(1), Last: intPtr(1)})
+		assert.EqualError(t, err, "cannot use both first and last together")
+		_, err = relay.FromSlice(letters, relay.Args{First: intPtr(-1)})
+		assert.EqualError(t, err, "first/last cannot be a negative integer")
+		_, err = relay.FromSlice(letters, relay.Args{After: strPtr("nope")})
+		assert.EqualError(t, err, `invalid cursor "nope"`)
+	})
+}
+
+type pager struct {
+	items []string
+	pages [][2]int
+}
+
+func (p *pager) Count(context.Context) (int, error) {
+	return len(p.items), nil
+}
+
+func (p *pager) Page(_ context.Context, offset, limit int) ([]string, error) {
+	p.pages = append(p.pages, [2]int{offset, limit})
+	return p.items[offset : offset+limit], nil
+}
+
+func TestFromPager(t *testing.T) {
+	p := &pager{items: []string{"A", "B", "C", "D", "E"}}
+	c, err := relay.FromPager[string](context.Background(), p, relay.Args{Last: intPtr(2), Before: strPtr(relay.Cursor(3))})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"B", "C"}, names(c))
+	assert.Equal(t, [][2]int{{1, 2}}, p.pages, "only the page is fetched")
+}
+
+type User struct {
+	Name string `graphql:"name"`
+}
+
+func TestRegister(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("User", User{})
+	relay.Register[*User](build, "User")
+	build.Query().FieldFunc("users", func(args relay.Args) (relay.Connection[*User], error) {
+		return relay.FromSlice([]*User{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}}, args)
+	})
+	result, errs := execution.Do(build.MustBuild(), execution.Params{Query: `{
+		users(first: 1, after: "YXJyYXljb25uZWN0aW9uOjA=") {
+			totalCount
+			edges { cursor node { name } }
+			pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+		}
+	}`})
+	assert.Equal(t, 0, len(errs), "%v", errs)
+	marshal, _ := json.Marshal(result)
+	assert.JSONEq(t, `{"users": {
+		"totalCount": 3,
+		"edges": [{"cursor": "YXJyYXljb25uZWN0aW9uOjE=", "node": {"name": "Bob"}}],
+		"pageInfo": {"hasNextPage": true, "hasPreviousPage": false,
+			"startCursor": "YXJyYXljb25uZWN0aW9uOjE=", "endCursor": "YXJyYXljb25uZWN0aW9uOjE="}
+	}}`, string(marshal))
+}
@@ -0,0 +1,259 @@
+package schemabuilder
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// DecodeArgs decodes args, the arguments of a field as an internal.FieldResolve receives them, into the struct dst
+// points to, so that the resolvers of the dynamic fields do not assert the types of the argument map. The arguments
+// are matched to the fields of the struct by their graphql tag, or by their name when they have none, like the
+// arguments of the FieldFunc resolvers:
+//
+//	var args struct {
+//		First  int            `graphql:"first"`
+//		Filter *ArticleFilter `graphql:"filter"`
+//	}
+//	if err := schemabuilder.DecodeArgs(rawArgs, &args); err != nil {
+//		return nil, err
+//	}
+//
+// The numbers are converted to the numeric type of their field and fail when they do not fit in it, the input
+// objects are decoded into structs or maps, the lists into slices and the strings into the string types or the
+// types implementing encoding.TextUnmarshaler, eg. time.Time. The arguments with no field are ignored, the fields
+// with no argument are left as they are, the Omittable fields are set for the null arguments too. args may be the
+// struct already, eg. the arguments of a FieldFunc. The fields are matched by the decoder of the struct type which
+// builds the arguments of the FieldFunc resolvers, the values are converted without the schema though.
+func DecodeArgs(args interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("args must be decoded into a pointer to a struct, not %T", dst)
+	}
+	if args == nil {
+		return nil
+	}
+	if decoded := reflect.ValueOf(args); decoded.Type() == v.Type().Elem() {
+		v.Elem().Set(decoded)
+		return nil
+	}
+	object, ok := args.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot decode args %T into %s", args, v.Type().Elem())
+	}
+	return argDecoder(v.Type().Elem()).decodeInto(v.Elem(), object, "")
+}
+
+// TypedResolve returns the internal.FieldResolve calling resolve with the arguments decoded into A, a struct, see
+// DecodeArgs. It is meant for the Resolve of a DynamicField:
+//
+//	Resolve: schemabuilder.TypedResolve(func(ctx context.Context, source interface{}, args struct {
+//		First int `graphql:"first"`
+//	}) (interface{}, error) {
+//		return listArticles(ctx, args.First)
+//	}),
+func TypedResolve[A interface{}](resolve func(ctx context.Context, source interface{}, args A) (interface{}, error)) internal.FieldResolve {
+	return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		var decoded A
+		if err := DecodeArgs(args, &decoded); err != nil {
+			return nil, err
+		}
+		return resolve(ctx, source, decoded)
+	}
+}
+
+// argDecoders caches the decoders of the struct types of DecodeArgs.
+var argDecoders sync.Map // map[reflect.Type]*structDecoder
+
+func argDecoder(typ reflect.Type) *structDecoder {
+	if decoder, ok := argDecoders.Load(typ); ok {
+		return decoder.(*structDecoder)
+	}
+	decoder, _ := argDecoders.LoadOrStore(typ, newStructDecoder(typ))
+	return decoder.(*structDecoder)
+}
+
+// decodeInto decodes object, the arguments or an input object at path, into dst, a struct of the type of d. The
+// values are converted to the types of the fields with decodeArg, the fields with no value are left as they are.
+func (d *structDecoder) decodeInto(dst reflect.Value, object map[string]interface{}, path string) error {
+	for _, field := range d.fields {
+		value, ok := object[field.name]
+		if !ok {
+			continue
+		}
+		fieldPath := argPath(path, field.name)
+		if field.omittable {
+			// an Omittable is set even when its value is null
+			setter := dst.Field(field.index).Addr().Interface().(omittableSetter)
+			if value == nil {
+				setter.setOmittable(reflect.Value{})
+				continue
+			}
+			elemType, _ := omittableElem(dst.Field(field.index).Type())
+			elem := reflect.New(elemType).Elem()
+			if err := decodeArg(elem, value, fieldPath); err != nil {
+				return err
+			}
+			if err := setter.setOmittable(elem); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeArg(dst.Field(field.index), value, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func argPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// decodeArg decodes value, an argument or a part of it at path, into dst.
+func decodeArg(dst reflect.Value, value interface{}, path string) error {
+	if value == nil {
+		dst.SetZero()
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(dst.Type()) {
+		dst.Set(v)
+		return nil
+	}
+	if s, ok := value.(string); ok && reflect.PointerTo(dst.Type()).Implements(textUnmarshalerType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value for %q: %w", path, err)
+		}
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := decodeArg(elem.Elem(), value, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Struct:
+		if object, ok := value.(map[string]interface{}); ok {
+			dst.SetZero()
+			return argDecoder(dst.Type()).decodeInto(dst, object, path)
+		}
+	case reflect.Map:
+		if object, ok := value.(map[string]interface{}); ok && dst.Type().Key().Kind() == reflect.String {
+			m := reflect.MakeMapWithSize(dst.Type(), len(object))
+			for key, item := range object {
+				elem := reflect.New(dst.Type().Elem()).Elem()
+				if err := decodeArg(elem, item, argPath(path, key)); err != nil {
+					return err
+				}
+				m.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+			}
+			dst.Set(m)
+			return nil
+		}
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			// a single value is accepted as a list of one item
+			list = []interface{}{value}
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := decodeArg(slice.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := argInteger(value); ok {
+			if !dst.OverflowInt(n) {
+				dst.SetInt(n)
+				return nil
+			}
+			return fmt.Errorf("invalid value for %q: %v does not fit in %s", path, value, dst.Type())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := argInteger(value); ok {
+			if n >= 0 && !dst.OverflowUint(uint64(n)) {
+				dst.SetUint(uint64(n))
+				return nil
+			}
+			return fmt.Errorf("invalid value for %q: %v does not fit in %s", path, value, dst.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, ok := argNumber(value); ok {
+			if dst.OverflowFloat(n) {
+				return fmt.Errorf("invalid value for %q: %v does not fit in %s", path, value, dst.Type())
+			}
+			dst.SetFloat(n)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value for %q: cannot decode %T into %s", path, value, dst.Type())
+}
+
+// argInteger returns the integer of value, a number with no fractional part.
+func argInteger(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+	}
+	f, ok := argNumber(value)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// argNumber returns the number of value, the arguments of the literals are float64 and the ones of the variables
+// may be any number.
+func argNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
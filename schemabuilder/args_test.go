@@ -0,0 +1,98 @@
+package schemabuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeArgs(t *testing.T) {
+	type Range struct {
+		From  time.Time `graphql:"from"`
+		Until *time.Time
+	}
+	type Args struct {
+		First    int               `graphql:"first"`
+		Offset   *uint8            `graphql:"offset"`
+		Ratio    float32           `graphql:"ratio"`
+		Status   string            `graphql:"status"`
+		Draft    bool              `graphql:"draft"`
+		Tags     []string          `graphql:"tags"`
+		Ranges   []*Range          `graphql:"ranges"`
+		Labels   map[string]int    `graphql:"labels"`
+		Raw      interface{}       `graphql:"raw"`
+		Skipped  string            `graphql:"-"`
+		Defaults map[string]string `graphql:"defaults"`
+	}
+	from := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	offset := uint8(3)
+
+	var args Args
+	args.Skipped = "kept"
+	assert.NoError(t, schemabuilder.DecodeArgs(map[string]interface{}{
+		"first":  float64(10),
+		"offset": json.Number("3"),
+		"ratio":  0.5,
+		"status": "PUBLISHED",
+		"draft":  true,
+		// a single value is accepted as a list of one item
+		"tags":     "news",
+		"ranges":   []interface{}{map[string]interface{}{"from": "2024-01-02T03:04:05Z", "Until": nil}, nil},
+		"labels":   map[string]interface{}{"a": 1, "b": int64(2)},
+		"raw":      map[string]interface{}{"any": "value"},
+		"Skipped":  "ignored",
+		"unknown":  "ignored",
+		"defaults": nil,
+	}, &args))
+	assert.Equal(t, Args{
+		First:   10,
+		Offset:  &offset,
+		Ratio:   0.5,
+		Status:  "PUBLISHED",
+		Draft:   true,
+		Tags:    []string{"news"},
+		Ranges:  []*Range{{From: from}, nil},
+		Labels:  map[string]int{"a": 1, "b": 2},
+		Raw:     map[string]interface{}{"any": "value"},
+		Skipped: "kept",
+	}, args)
+
+	t.Run("the arguments decoded already are copied", func(t *testing.T) {
+		var decoded Args
+		assert.NoError(t, schemabuilder.DecodeArgs(args, &decoded))
+		assert.Equal(t, args, decoded)
+	})
+
+	t.Run("the Omittable fields tell null apart from omitted", func(t *testing.T) {
+		var update struct {
+			Name     schemabuilder.Omittable[string]  `graphql:"name"`
+			Nickname schemabuilder.Omittable[*string] `graphql:"nickname"`
+			Age      schemabuilder.Omittable[int]     `graphql:"age"`
+		}
+		assert.NoError(t, schemabuilder.DecodeArgs(map[string]interface{}{"name": "Ada", "nickname": nil}, &update))
+		assert.Equal(t, schemabuilder.OmittableOf("Ada"), update.Name)
+		assert.Equal(t, schemabuilder.OmittableOf[*string](nil), update.Nickname)
+		assert.False(t, update.Age.IsSet())
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, test := range []struct {
+			args map[string]interface{}
+			err  string
+		}{
+			{map[string]interface{}{"first": 1.5}, `invalid value for "first": cannot decode float64 into int`},
+			{map[string]interface{}{"offset": float64(256)}, `invalid value for "offset": 256 does not fit in uint8`},
+			{map[string]interface{}{"offset": float64(-1)}, `invalid value for "offset": -1 does not fit in uint8`},
+			{map[string]interface{}{"status": true}, `invalid value for "status": cannot decode bool into string`},
+			{map[string]interface{}{"ranges": []interface{}{map[string]interface{}{"from": "yesterday"}}},
+				`invalid value for "ranges[0].from": parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+		} {
+			assert.EqualError(t, schemabuilder.DecodeArgs(test.args, &Args{}), test.err)
+		}
+		assert.EqualError(t, schemabuilder.DecodeArgs(map[string]interface{}{}, Args{}),
+			"args must be decoded into a pointer to a struct, not schemabuilder_test.Args")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/shyptr/graphql/internal"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -21,6 +22,9 @@ type schemaBuilder struct {
 	interfaces   map[reflect.Type]*Interface
 	scalars      map[reflect.Type]*Scalar
 	unions       map[reflect.Type]*Union
+	intOverflow  IntOverflow
+	// validated caches whether the arguments structs reach input objects with validators, see validateInput
+	validated sync.Map // map[reflect.Type]bool
 }
 
 var Serialize = func(value interface{}) (interface{}, error) {
@@ -51,6 +55,19 @@ func (sb *schemaBuilder) getType(nodeType reflect.Type) (internal.Type, error) {
 		return typ, nil
 	}
 
+	// Omittable has the nullable type of its value
+	if elem, ok := omittableElem(nodeType); ok {
+		typ, err := sb.getType(elem)
+		if err != nil {
+			return nil, err
+		}
+		if nonNull, ok := typ.(*internal.NonNull); ok {
+			typ = nonNull.Type
+		}
+		sb.types[nodeType] = typ
+		return typ, nil
+	}
+
 	// Support scalars and optional scalars. Scalars have precedence over structs to have eg. time.Time function as a scalar.
 	// Enum
 	if enum := sb.getEnum(nodeType); enum != nil {
@@ -128,12 +145,13 @@ func (sb *schemaBuilder) getEnum(typ reflect.Type) *internal.Enum {
 			values = append(values, mapping)
 		}
 		return &internal.Enum{
-			Name:       enum.Name,
-			Values:     values,
-			ValuesDesc: enum.DescMap,
-			ReverseMap: enum.Map,
-			Map:        enum.ReverseMap,
-			Desc:       enum.Desc,
+			Name:              enum.Name,
+			Values:            values,
+			ValuesDesc:        enum.DescMap,
+			ValuesDeprecation: enum.DeprecationMap,
+			ReverseMap:        enum.Map,
+			Map:               enum.ReverseMap,
+			Desc:              enum.Desc,
 		}
 	}
 	return nil
@@ -143,10 +161,14 @@ func (sb *schemaBuilder) getEnum(typ reflect.Type) *internal.Enum {
 // in variable reflect type.
 func (sb *schemaBuilder) getScalar(typ reflect.Type) *internal.Scalar {
 	if scalar, ok := sb.scalars[typ]; ok {
+		serialize := scalar.Serialize
+		if scalar == Int && sb.intOverflow == IntOverflowAllow {
+			serialize = Serialize
+		}
 		return &internal.Scalar{
 			Name:         scalar.Name,
 			Desc:         scalar.Desc,
-			Serialize:    scalar.Serialize,
+			Serialize:    serialize,
 			ParseValue:   scalar.ParseValue,
 			ParseLiteral: scalar.ParseLiteral,
 		}
@@ -267,9 +289,17 @@ func (sb *schemaBuilder) getDirective(directive *Directive) (*internal.Directive
 		}
 	}
 
-	arguments, err := sb.getArguments(argType)
-	if err != nil {
-		return nil, err
+	arguments := make(map[string]*internal.InputField)
+	if hasArg {
+		var err error
+		if arguments, err = sb.getArguments(argType); err != nil {
+			return nil, err
+		}
+	}
+	for name, f := range directive.Fields {
+		if arg, ok := arguments[name]; ok {
+			arg.DefaultValue = f.DefaultValue
+		}
 	}
 
 	return &internal.Directive{
@@ -282,10 +312,24 @@ func (sb *schemaBuilder) getDirective(directive *Directive) (*internal.Directive
 				in = append(in, reflect.ValueOf(ctx))
 			}
 			if hasArg {
-				arguments, err := sb.cacheTypes[argType](args)
+				values := make(map[string]interface{}, len(arguments))
+				if args, ok := args.(map[string]interface{}); ok {
+					for name, value := range args {
+						values[name] = value
+					}
+				}
+				for name, arg := range arguments {
+					if _, ok := values[name]; !ok && arg.DefaultValue != nil {
+						values[name] = arg.DefaultValue
+					}
+				}
+				arguments, err := sb.cacheTypes[argType](values)
 				if err != nil {
 					return false, nil, err
 				}
+				if err := sb.validateInput(ctx, reflect.ValueOf(arguments), nil); err != nil {
+					return false, nil, err
+				}
 				in = append(in, reflect.ValueOf(arguments))
 			}
 			if hasField {
@@ -331,6 +375,7 @@ func (sb *schemaBuilder) buildStruct(typ reflect.Type) error {
 			Interfaces: map[string]*internal.Interface{},
 			Fields:     map[string]*internal.Field{},
 			IsTypeOf:   reflect.New(typ).Elem().Interface(),
+			Extensions: obj.Extensions,
 		}
 
 		sb.types[reflect.PtrTo(typ)] = object
@@ -357,6 +402,9 @@ func (sb *schemaBuilder) buildStruct(typ reflect.Type) error {
 			}
 			object.Fields[buildField.Name] = buildField
 		}
+		for _, f := range object.Fields {
+			inheritOwner(f, object)
+		}
 		for _, iface := range obj.Interface {
 			ifaceTyp, err := sb.getType(reflect.TypeOf(iface.Type))
 			if err != nil {
@@ -402,11 +450,11 @@ func (sb *schemaBuilder) buildField(field reflect.StructField) (*internal.Field,
 			if value.Kind() == reflect.Ptr {
 				value = value.Elem()
 			}
-			fieldVal := GetField(value, name)
-			if fieldVal == nil {
+			i, ok := fieldIndex(value.Type(), name)
+			if !ok {
 				return nil, fmt.Errorf("can not get field %s", name)
 			}
-			return (*fieldVal).Interface(), nil
+			return value.Field(i).Interface(), nil
 		},
 		Desc: desc,
 	}, nil
@@ -445,14 +493,25 @@ func (sb *schemaBuilder) builInputObject(typ reflect.Type) error {
 		Name:   input.Name,
 		Fields: map[string]*internal.InputField{},
 		Desc:   input.Desc,
+		OneOf:  input.OneOf,
 	}
 	sb.types[reflect.PtrTo(typ)] = inputObject
 	sb.types[typ] = &internal.NonNull{Type: inputObject}
 	arguments, err := sb.getArguments(typ)
 	if err != nil {
-		return nil
+		return fmt.Errorf("input object %s: %w", input.Name, err)
 	}
 	inputObject.Fields = arguments
+	if input.OneOf {
+		for name, field := range arguments {
+			if _, ok := field.Type.(*internal.NonNull); ok {
+				return fmt.Errorf("oneOf input object %s field %s must be nullable", input.Name, name)
+			}
+			if field.DefaultValue != nil {
+				return fmt.Errorf("oneOf input object %s field %s cannot have a default value", input.Name, name)
+			}
+		}
+	}
 	return nil
 }
 
@@ -514,17 +573,29 @@ func (sb *schemaBuilder) getField(fnresolve *fieldResolve, src reflect.Type) (*i
 			if err != nil {
 				return nil, err
 			}
-			for _, execute := range fnresolve.executeChain {
-				if result, err = execute.execute(executeFuncParam{
-					sb:     sb,
-					ctx:    ctx,
-					args:   args,
-					source: result,
-				}); err != nil {
-					return nil, err
+			afterExecute := func(result interface{}) (_ interface{}, err error) {
+				for _, execute := range fnresolve.executeChain {
+					if result, err = execute.execute(executeFuncParam{
+						sb:     sb,
+						ctx:    ctx,
+						args:   args,
+						source: result,
+					}); err != nil {
+						return nil, err
+					}
 				}
-			}
-			return result, nil
+				return result, nil
+			}
+			if thunk, ok := result.(internal.Thunk); ok && len(fnresolve.executeChain) > 0 {
+				return internal.Thunk(func() (interface{}, error) {
+					result, err := thunk()
+					if err != nil {
+						return nil, err
+					}
+					return afterExecute(result)
+				}), nil
+			}
+			return afterExecute(result)
 		},
 		Desc: fnresolve.desc,
 	}
@@ -605,6 +676,7 @@ type funcContext struct {
 	typ             reflect.Type
 
 	returnsFunc    bool
+	returnsStream  bool
 	wrapperFuncTyp reflect.Type
 }
 
@@ -719,7 +791,18 @@ func (funcCtx *funcContext) getReturnType(sb *schemaBuilder) (internal.Type, err
 	if funcCtx.hasRet {
 		var err error
 
-		retType, err = sb.getType(funcCtx.funcType.Out(0))
+		out := funcCtx.funcType.Out(0)
+		// a subscription field returns the stream of its events, the field has the type of the events
+		if out.Kind() == reflect.Chan {
+			if out.ChanDir()&reflect.RecvDir == 0 {
+				return nil, fmt.Errorf("%s should be a receivable channel", out)
+			}
+			out = out.Elem()
+		} else if event := streamEventType(out); event != nil {
+			funcCtx.returnsStream = true
+			out = event
+		}
+		retType, err = sb.getType(out)
 		if err != nil {
 			return nil, err
 		}
@@ -769,6 +852,9 @@ func (funcCtx *funcContext) prepareResolveArgs(sb *schemaBuilder, source interfa
 			if err != nil {
 				return nil, err
 			}
+			if err := sb.validateInput(ctx, reflect.ValueOf(args), nil); err != nil {
+				return nil, err
+			}
 			if validate != nil {
 				err = validate.Struct(args)
 				if err != nil {
@@ -790,9 +876,22 @@ func (funcCtx *funcContext) extractResultAndErr(out []reflect.Value) (interface{
 	var result interface{}
 	if funcCtx.hasRet {
 		result = out[0].Interface()
-		if out[0].Kind() == reflect.Func {
-			call := out[0].Call(nil)
-			result = call[0].Interface()
+		if fn := out[0]; fn.Kind() == reflect.Func && !fn.IsNil() {
+			// the returned func is called by the executor, see internal.Thunk
+			result = internal.Thunk(func() (interface{}, error) {
+				call := fn.Call(nil)
+				if last := call[len(call)-1]; len(call) > 1 && !last.IsNil() {
+					return nil, last.Interface().(error)
+				}
+				return call[0].Interface(), nil
+			})
+		}
+		if stream := out[0]; funcCtx.returnsStream {
+			if (stream.Kind() == reflect.Ptr || stream.Kind() == reflect.Interface) && stream.IsNil() {
+				result = nil
+			} else {
+				result = newSourceStream(stream)
+			}
 		}
 		out = out[1:]
 	} else {
@@ -0,0 +1,256 @@
+package schemabuilder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// DynamicObject is an object whose fields are defined at runtime, eg. from the content models of a CMS,
+// instead of from a go struct. Its values are map[string]interface{}.
+//
+//	article := s.DynamicObject("Article")
+//	article.AddField(schemabuilder.DynamicField{Name: "title", Type: "String!"})
+//	article.AddField(schemabuilder.DynamicField{Name: "author", Type: "Author"})
+//	s.DynamicObject("Query").AddField(schemabuilder.DynamicField{
+//		Name:    "articles",
+//		Type:    "[Article!]!",
+//		Args:    map[string]string{"first": "Int"},
+//		Resolve: listArticles,
+//	})
+//
+// A dynamic object named like a static one, such as Query or Mutation, adds its fields to it.
+type DynamicObject struct {
+	Name   string
+	Desc   string
+	Fields []DynamicField
+	// Extensions are the metadata of the object, see Object.Extensions
+	Extensions map[string]interface{}
+}
+
+// DynamicField is a field of a DynamicObject.
+type DynamicField struct {
+	Name string
+	Desc string
+	// Type references a type of the schema by name with the GraphQL notation, eg. "[String!]!".
+	Type string
+	// Args maps the argument names to their type, which must be input types.
+	// The resolver receives the arguments as a map[string]interface{}, see TypedResolve to receive them as a struct.
+	Args map[string]string
+	// Resolve resolves the field, it defaults to the value under Name of the source map.
+	Resolve    internal.FieldResolve
+	Extensions map[string]interface{}
+	// ErrorPolicy tells what becomes of the field when it fails, see OnError.
+	ErrorPolicy ErrorPolicy
+}
+
+// DynamicObject returns the dynamic object with name, creating it when it does not exist.
+func (s *Schema) DynamicObject(name string, desc ...string) *DynamicObject {
+	if name == "" {
+		panic("dynamic object must have name")
+	}
+	if object, ok := s.dynamicObjects[name]; ok {
+		return object
+	}
+	var d string
+	if len(desc) > 0 {
+		d = desc[0]
+	}
+	if s.dynamicObjects == nil {
+		s.dynamicObjects = make(map[string]*DynamicObject)
+	}
+	s.dynamicObjects[name] = &DynamicObject{Name: name, Desc: d}
+	return s.dynamicObjects[name]
+}
+
+// AddField adds a field, replacing the field of the same name.
+func (o *DynamicObject) AddField(field DynamicField) *DynamicObject {
+	for i := range o.Fields {
+		if o.Fields[i].Name == field.Name {
+			o.Fields[i] = field
+			return o
+		}
+	}
+	o.Fields = append(o.Fields, field)
+	return o
+}
+
+// MapResolve returns a resolver reading key of the source map.
+func MapResolve(key string) internal.FieldResolve {
+	return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		if m, ok := source.(map[string]interface{}); ok {
+			return m[key], nil
+		}
+		return nil, nil
+	}
+}
+
+// buildDynamicObjects adds the dynamic objects to types, which holds the named types built from go types.
+func (s *Schema) buildDynamicObjects(sb *schemaBuilder, types map[string]internal.NamedType) error {
+	if len(s.dynamicObjects) == 0 {
+		return nil
+	}
+	objects := make(map[string]*internal.Object, len(s.dynamicObjects))
+	for name, dynamic := range s.dynamicObjects {
+		switch typ := types[name].(type) {
+		case nil:
+			object := &internal.Object{
+				Name:       name,
+				Desc:       dynamic.Desc,
+				Interfaces: map[string]*internal.Interface{},
+				Fields:     map[string]*internal.Field{},
+				Extensions: dynamic.Extensions,
+			}
+			types[name] = object
+			objects[name] = object
+		case *internal.Object:
+			objects[name] = typ
+		default:
+			return fmt.Errorf("dynamic object %s conflicts with a type which is not an object", name)
+		}
+	}
+
+	lookup := func(name string) (internal.NamedType, error) {
+		if typ, ok := types[name]; ok {
+			return typ, nil
+		}
+		if goType, ok := sb.goType(name); ok {
+			typ, err := sb.getType(goType)
+			if err != nil {
+				return nil, err
+			}
+			if nonNull, ok := typ.(*internal.NonNull); ok {
+				typ = nonNull.Type
+			}
+			if named, ok := typ.(internal.NamedType); ok {
+				types[name] = named
+				return named, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown type %s", name)
+	}
+
+	for name, dynamic := range s.dynamicObjects {
+		object := objects[name]
+		for _, field := range dynamic.Fields {
+			f, err := buildDynamicField(field, lookup)
+			if err != nil {
+				return fmt.Errorf("dynamic object %s field %s parse error:%w", name, field.Name, err)
+			}
+			inheritOwner(f, object)
+			object.Fields[f.Name] = f
+		}
+	}
+	// the lookups may have built go types which were not reachable from the roots
+	for _, t := range sb.types {
+		if named, ok := t.(internal.NamedType); ok {
+			if _, ok := types[named.TypeName()]; !ok {
+				types[named.TypeName()] = named
+			}
+		}
+	}
+	return nil
+}
+
+// goType returns the go type registered for the scalar, enum, input object or object named name.
+func (sb *schemaBuilder) goType(name string) (reflect.Type, bool) {
+	for typ, scalar := range sb.scalars {
+		if scalar.Name == name {
+			return typ, true
+		}
+	}
+	for typ, enum := range sb.enums {
+		if enum.Name == name {
+			return typ, true
+		}
+	}
+	for typ, inputObject := range sb.inputObjects {
+		if inputObject.Name == name {
+			return typ, true
+		}
+	}
+	for typ, object := range sb.objects {
+		if object.Name == name {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+func buildDynamicField(field DynamicField, lookup func(string) (internal.NamedType, error)) (*internal.Field, error) {
+	if field.Name == "" {
+		return nil, fmt.Errorf("field must have name")
+	}
+	typ, err := parseTypeRef(field.Type, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if !isOutputType(typ) {
+		return nil, fmt.Errorf("%s is not an output type", field.Type)
+	}
+	f := &internal.Field{
+		Name:        field.Name,
+		Type:        typ,
+		Args:        make(map[string]*internal.InputField, len(field.Args)),
+		Resolve:     field.Resolve,
+		Desc:        field.Desc,
+		Extensions:  field.Extensions,
+		ErrorPolicy: field.ErrorPolicy,
+	}
+	if f.Resolve == nil {
+		f.Resolve = MapResolve(field.Name)
+	}
+	for name, ref := range field.Args {
+		argTyp, err := parseTypeRef(ref, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		if !internal.IsInputType(argTyp) {
+			return nil, fmt.Errorf("argument %s: %s is not an input type", name, ref)
+		}
+		f.Args[name] = &internal.InputField{Name: name, Type: argTyp}
+	}
+	return f, nil
+}
+
+// parseTypeRef parses a type reference like "[String!]!".
+func parseTypeRef(ref string, lookup func(string) (internal.NamedType, error)) (internal.Type, error) {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case ref == "":
+		return nil, fmt.Errorf("missing type")
+	case strings.HasSuffix(ref, "!"):
+		typ, err := parseTypeRef(ref[:len(ref)-1], lookup)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := typ.(*internal.NonNull); ok {
+			return nil, fmt.Errorf("invalid type %s", ref)
+		}
+		return &internal.NonNull{Type: typ}, nil
+	case strings.HasPrefix(ref, "[") && strings.HasSuffix(ref, "]"):
+		typ, err := parseTypeRef(ref[1:len(ref)-1], lookup)
+		if err != nil {
+			return nil, err
+		}
+		return &internal.List{Type: typ}, nil
+	case strings.ContainsAny(ref, "[]! "):
+		return nil, fmt.Errorf("invalid type %s", ref)
+	}
+	return lookup(ref)
+}
+
+func isOutputType(typ internal.Type) bool {
+	switch typ := typ.(type) {
+	case *internal.NonNull:
+		return isOutputType(typ.Type)
+	case *internal.List:
+		return isOutputType(typ.Type)
+	case *internal.InputObject:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type contentModel struct {
+	name   string
+	fields map[string]string
+}
+
+func buildContentSchema(models []contentModel, entries map[string][]map[string]interface{}) func() (*schemabuilder.Schema, error) {
+	return func() (*schemabuilder.Schema, error) {
+		builder := schemabuilder.NewSchema()
+		builder.Query().FieldFunc("version", func() string { return "1" })
+		query := builder.DynamicObject("Query")
+		for _, model := range models {
+			object := builder.DynamicObject(model.name)
+			for name, typ := range model.fields {
+				object.AddField(schemabuilder.DynamicField{Name: name, Type: typ})
+			}
+			model := model
+			query.AddField(schemabuilder.DynamicField{
+				Name: "all" + model.name,
+				Type: "[" + model.name + "!]!",
+				Args: map[string]string{"first": "Int"},
+				Resolve: schemabuilder.TypedResolve(func(ctx context.Context, source interface{}, args struct {
+					First *int `graphql:"first"`
+				}) (interface{}, error) {
+					list := entries[model.name]
+					if args.First != nil && *args.First < len(list) {
+						list = list[:*args.First]
+					}
+					return list, nil
+				}),
+			})
+		}
+		return builder, nil
+	}
+}
+
+func TestSchema_DynamicObject(t *testing.T) {
+	entries := map[string][]map[string]interface{}{
+		"Article": {
+			{"title": "Hello", "tags": []interface{}{"a", "b"}, "author": map[string]interface{}{"name": "Luke"}},
+			{"title": "World", "author": nil},
+		},
+	}
+	models := []contentModel{
+		{name: "Author", fields: map[string]string{"name": "String!"}},
+		{name: "Article", fields: map[string]string{"title": "String!", "tags": "[String!]", "author": "Author"}},
+	}
+	holder := schemabuilder.NewSchemaHolder(nil)
+	assert.NoError(t, holder.Rebuild(buildContentSchema(models, entries)))
+	old := holder.Schema()
+
+	result, err := execution.Do(old, execution.Params{Query: `{ version allArticle(first: 1) { title tags author { name } } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{
+		"version": "1",
+		"allArticle": []interface{}{
+			map[string]interface{}{"title": "Hello", "tags": []interface{}{"a", "b"}, "author": map[string]interface{}{"name": "Luke"}},
+		},
+	}, result)
+
+	t.Run("rebuild swaps the schema", func(t *testing.T) {
+		models := append(models, contentModel{name: "Page", fields: map[string]string{"slug": "String!"}})
+		entries["Page"] = []map[string]interface{}{{"slug": "home"}}
+		assert.NoError(t, holder.Rebuild(buildContentSchema(models, entries)))
+		assert.True(t, old != holder.Schema())
+
+		result, err := execution.Do(holder.Schema(), execution.Params{Query: `{ allPage { slug } }`})
+		assert.Equal(t, errors.MultiError(nil), err)
+		assert.Equal(t, map[string]interface{}{"allPage": []interface{}{map[string]interface{}{"slug": "home"}}}, result)
+
+		_, err = execution.Do(old, execution.Params{Query: `{ allPage { slug } }`})
+		assert.NotEmpty(t, err, "the old schema is unchanged")
+	})
+
+	t.Run("a failed rebuild keeps the schema", func(t *testing.T) {
+		current := holder.Schema()
+		models := []contentModel{{name: "Broken", fields: map[string]string{"x": "Unknown"}}}
+		assert.EqualError(t, holder.Rebuild(buildContentSchema(models, entries)),
+			"dynamic object Broken field x parse error:unknown type Unknown")
+		assert.True(t, current == holder.Schema())
+	})
+}
@@ -0,0 +1,63 @@
+package schemabuilder
+
+import "github.com/shyptr/graphql/internal"
+
+// EnumType is a Go type bound to an enum of the schema, see BindEnum.
+type EnumType[T comparable] struct {
+	enum *Enum
+}
+
+// BindEnum registers the Go type T as the enum name, values maps the names of the enum values to the values of T.
+// The fields returning a T are serialized to the names of their values, and the arguments and input fields of
+// type T are parsed from them.
+//
+// For example:
+//
+//	type Status int
+//	const (
+//		Active Status = iota
+//		Banned
+//	)
+//
+//	statuses := schemabuilder.BindEnum(s, "Status", map[string]Status{"ACTIVE": Active, "BANNED": Banned})
+//	statuses.Describe(Banned, "the user cannot sign in")
+func BindEnum[T comparable](s *Schema, name string, values map[string]T, desc ...string) *EnumType[T] {
+	var zero T
+	enum := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		enum[key] = value
+	}
+	s.Enum(name, zero, enum, desc...)
+	return &EnumType[T]{enum: s.enums[name]}
+}
+
+// Describe sets the description of value.
+func (e *EnumType[T]) Describe(value T, desc string) *EnumType[T] {
+	if name, ok := e.Name(value); ok {
+		e.enum.DescMap[name] = desc
+	}
+	return e
+}
+
+// Deprecate marks value deprecated, an empty reason is the default one.
+func (e *EnumType[T]) Deprecate(value T, reason string) *EnumType[T] {
+	if name, ok := e.Name(value); ok {
+		if reason == "" {
+			reason = internal.DefaultDeprecationReason
+		}
+		e.enum.DeprecationMap[name] = reason
+	}
+	return e
+}
+
+// Name returns the name of the enum value of value.
+func (e *EnumType[T]) Name(value T) (string, bool) {
+	name, ok := e.enum.ReverseMap[value]
+	return name, ok
+}
+
+// Parse returns the value of T of the enum value name.
+func (e *EnumType[T]) Parse(name string) (T, bool) {
+	value, ok := e.enum.Map[name].(T)
+	return value, ok
+}
@@ -0,0 +1,64 @@
+package schemabuilder_test
+
+import (
+	"testing"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+type Status int
+
+const (
+	Active Status = iota
+	Banned
+)
+
+func TestBindEnum(t *testing.T) {
+	builder := schemabuilder.NewSchema()
+	statuses := schemabuilder.BindEnum(builder, "Status", map[string]Status{"ACTIVE": Active, "BANNED": Banned})
+	statuses.Describe(Banned, "the user cannot sign in")
+	builder.Query().FieldFunc("next", func(args struct {
+		Status Status   `graphql:"status"`
+		After  []Status `graphql:"after"`
+	}) Status {
+		if args.Status == Active && len(args.After) == 0 {
+			return Banned
+		}
+		return Active
+	})
+	schema := builder.MustBuild()
+
+	name, ok := statuses.Name(Banned)
+	assert.True(t, ok)
+	assert.Equal(t, "BANNED", name)
+	status, ok := statuses.Parse("ACTIVE")
+	assert.True(t, ok)
+	assert.Equal(t, Active, status)
+	_, ok = statuses.Parse("UNKNOWN")
+	assert.False(t, ok)
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ next(status: ACTIVE) }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"next": "BANNED"}, result)
+
+	result, err = execution.Do(schema, execution.Params{
+		Query:     `query ($after: [Status!]) { next(status: ACTIVE, after: $after) }`,
+		Variables: map[string]interface{}{"after": []interface{}{"BANNED"}},
+	})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"next": "ACTIVE"}, result)
+}
+
+func TestEnum_ConvertsUnderlyingValues(t *testing.T) {
+	builder := schemabuilder.NewSchema()
+	builder.Enum("Status", Status(0), map[string]interface{}{"ACTIVE": 0, "BANNED": 1})
+	builder.Query().FieldFunc("status", func() Status { return Banned })
+	schema := builder.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ status }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"status": "BANNED"}, result)
+}
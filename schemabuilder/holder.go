@@ -0,0 +1,50 @@
+package schemabuilder
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// SchemaHolder holds the current schema of a server whose schema changes at runtime,
+// eg. when the content models backing dynamic objects are edited.
+// Operations take the schema once when they start, so a swap never affects the operations in flight.
+type SchemaHolder struct {
+	schema atomic.Pointer[internal.Schema]
+	// mu serializes the rebuilds, so an older build can not replace a newer one
+	mu sync.Mutex
+}
+
+func NewSchemaHolder(schema *internal.Schema) *SchemaHolder {
+	h := &SchemaHolder{}
+	h.schema.Store(schema)
+	return h
+}
+
+// Schema returns the current schema.
+func (h *SchemaHolder) Schema() *internal.Schema {
+	return h.schema.Load()
+}
+
+// Swap replaces the current schema by schema and returns the previous one.
+func (h *SchemaHolder) Swap(schema *internal.Schema) *internal.Schema {
+	return h.schema.Swap(schema)
+}
+
+// Rebuild builds the schema returned by define and swaps it in.
+// The current schema is kept when define or the build fails.
+func (h *SchemaHolder) Rebuild(define func() (*Schema, error)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, err := define()
+	if err != nil {
+		return err
+	}
+	schema, err := s.Build()
+	if err != nil {
+		return err
+	}
+	h.schema.Store(schema)
+	return nil
+}
@@ -0,0 +1,204 @@
+package schemabuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/shyptr/graphql/ast"
+)
+
+// IntOverflow is how the fields of the spec Int serialize the values out of its 32-bit range, see
+// Schema.SetIntOverflow.
+type IntOverflow int
+
+const (
+	// IntOverflowError fails the fields returning values out of the range of Int, it is the default.
+	IntOverflowError IntOverflow = iota
+	// IntOverflowAllow serializes the values out of the range of Int as they are.
+	IntOverflowAllow
+)
+
+// SetIntOverflow sets how the fields of the spec Int serialize the values out of its 32-bit range, the ones
+// needing 64 bits are better served by the Int64String or BigInt scalars.
+func (s *Schema) SetIntOverflow(policy IntOverflow) {
+	s.intOverflow = policy
+}
+
+// UseScalar registers one of the predefined opt-in scalars, eg. BigInt, in place of the scalar of the same name.
+//
+//	s.UseScalar(schemabuilder.Int64String)
+func (s *Schema) UseScalar(scalar *Scalar) {
+	s.scalars[scalar.Name] = scalar
+}
+
+// serializeInt serializes the values of the fields of the spec Int, which fail out of its 32-bit range.
+func serializeInt(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n > math.MaxInt32 || n < math.MinInt32 {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+		}
+	}
+	return Serialize(value)
+}
+
+// integer returns the integer of value, a number of the arguments or of the variables.
+func integer(value interface{}) (*big.Int, error) {
+	if v, ok := value.(*float64); ok && v != nil {
+		value = *v
+	}
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		i, _ := big.NewFloat(v).Int(nil)
+		return i, nil
+	case json.Number:
+		if i, ok := new(big.Int).SetString(string(v), 10); ok {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, errors.New("not a number")
+		}
+		return integer(f)
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()), nil
+	}
+	return nil, errors.New("not a number")
+}
+
+// parseSigned returns the integer of value when it fits in a signed integer of bits.
+func parseSigned(value interface{}, bits uint) (int64, error) {
+	i, err := integer(value)
+	if err != nil {
+		return 0, err
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min := new(big.Int).Neg(max)
+	if i.Cmp(min) < 0 || i.Cmp(max) >= 0 {
+		return 0, fmt.Errorf("%s overflows int%d", i, bits)
+	}
+	return i.Int64(), nil
+}
+
+// parseUnsigned returns the integer of value when it fits in an unsigned integer of bits.
+func parseUnsigned(value interface{}, bits uint) (uint64, error) {
+	i, err := integer(value)
+	if err != nil {
+		return 0, err
+	}
+	if i.Sign() < 0 || i.BitLen() > int(bits) {
+		return 0, fmt.Errorf("%s overflows uint%d", i, bits)
+	}
+	return i.Uint64(), nil
+}
+
+// Int64String is the opt-in Int64 scalar serialized as a string, which the JavaScript clients read without losing
+// the precision of the values beyond 2^53, eg. database ids. It parses both strings and integers.
+var Int64String = &Scalar{
+	Name: "Int64",
+	Desc: "int64 is the set of all signed 64-bit integers, serialized as strings. Range: -9223372036854775808 through 9223372036854775807.",
+	Type: int64(0),
+	Serialize: func(value interface{}) (interface{}, error) {
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Int64 {
+			return nil, fmt.Errorf("unexpected type %T for Int64", value)
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	},
+	ParseValue: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case nil:
+			return int64(0), nil
+		case string:
+			return strconv.ParseInt(value, 10, 64)
+		}
+		return parseSigned(value, 64)
+	},
+	ParseLiteral: func(value ast.Value) (interface{}, error) {
+		switch value := value.(type) {
+		case *ast.IntValue:
+			return strconv.ParseInt(value.Value, 10, 64)
+		case *ast.StringValue:
+			return strconv.ParseInt(value.Value, 10, 64)
+		}
+		return nil, errors.New("not an integer")
+	},
+}
+
+// BigInt is the opt-in scalar of the big.Int values, serialized as strings. It parses both strings and integers.
+var BigInt = &Scalar{
+	Name: "BigInt",
+	Desc: "BigInt is the set of all the integers, serialized as strings.",
+	Type: big.Int{},
+	Serialize: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case *big.Int:
+			if value == nil {
+				return nil, nil
+			}
+			return value.String(), nil
+		case big.Int:
+			return value.String(), nil
+		}
+		return nil, fmt.Errorf("unexpected type %T for BigInt", value)
+	},
+	ParseValue: func(value interface{}) (interface{}, error) {
+		if value == nil {
+			return big.Int{}, nil
+		}
+		if s, ok := value.(string); ok {
+			value = json.Number(s)
+		}
+		i, err := integer(value)
+		if err != nil {
+			return nil, err
+		}
+		return *i, nil
+	},
+	ParseLiteral: func(value ast.Value) (interface{}, error) {
+		var literal string
+		switch value := value.(type) {
+		case *ast.IntValue:
+			literal = value.Value
+		case *ast.StringValue:
+			literal = value.Value
+		default:
+			return nil, errors.New("not an integer")
+		}
+		i, ok := new(big.Int).SetString(literal, 10)
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", literal)
+		}
+		return *i, nil
+	},
+}
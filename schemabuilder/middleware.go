@@ -0,0 +1,48 @@
+package schemabuilder
+
+import (
+	"github.com/shyptr/graphql/internal"
+)
+
+// FieldInfo is the field a FieldMiddleware is applied to.
+type FieldInfo struct {
+	// Object is the name of the object owning the field
+	Object string
+	Field  *internal.Field
+}
+
+// FieldMiddleware wraps the resolver of a field, it is called once per field when the schema is built
+// and returns the resolver which is called at execution, eg.
+//
+//	func(info FieldInfo, next internal.FieldResolve) internal.FieldResolve {
+//		return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+//			start := time.Now()
+//			defer func() { observe(info.Object, info.Field.Name, time.Since(start)) }()
+//			return next(ctx, source, args)
+//		}
+//	}
+type FieldMiddleware func(info FieldInfo, next internal.FieldResolve) internal.FieldResolve
+
+// Use adds middlewares applied to the fields of every object, for concerns like auth, logging or metrics.
+// The first middleware is the outermost, middlewares run around the FieldFunc options of the field.
+func (s *Schema) Use(middlewares ...FieldMiddleware) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+func (s *Schema) applyMiddlewares(typeMap map[string]internal.NamedType) {
+	if len(s.middlewares) == 0 {
+		return
+	}
+	for _, typ := range typeMap {
+		object, ok := typ.(*internal.Object)
+		if !ok {
+			continue
+		}
+		for _, field := range object.Fields {
+			info := FieldInfo{Object: object.Name, Field: field}
+			for i := len(s.middlewares) - 1; i >= 0; i-- {
+				field.Resolve = s.middlewares[i](info, field.Resolve)
+			}
+		}
+	}
+}
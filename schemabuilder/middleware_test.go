@@ -0,0 +1,43 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSchema_Use(t *testing.T) {
+	var calls []string
+	builder := schemabuilder.NewSchema()
+	builder.Object("User", User{})
+	builder.Query().FieldFunc("me", func() *User { return &User{Name: "luke"} })
+	builder.Query().FieldFunc("secret", func() string { return "42" })
+	builder.Use(func(info schemabuilder.FieldInfo, next internal.FieldResolve) internal.FieldResolve {
+		return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			calls = append(calls, info.Object+"."+info.Field.Name)
+			return next(ctx, source, args)
+		}
+	}, func(info schemabuilder.FieldInfo, next internal.FieldResolve) internal.FieldResolve {
+		if info.Field.Name != "secret" {
+			return next
+		}
+		return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("forbidden")
+		}
+	})
+	schema := builder.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ me { name } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"me": map[string]interface{}{"name": "luke"}}, result)
+	assert.Equal(t, []string{"Query.me", "User.name"}, calls)
+
+	_, err = (&execution.Executor{UnmaskedErrors: true}).Do(schema, execution.Params{Query: `{ secret }`})
+	assert.EqualError(t, err, "[graphql: forbidden (1:3) path: [secret]]")
+	assert.Equal(t, []string{"Query.me", "User.name", "Query.secret"}, calls)
+}
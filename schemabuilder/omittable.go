@@ -0,0 +1,74 @@
+package schemabuilder
+
+import "reflect"
+
+// Omittable is an input field or an argument which tells whether the client omitted it or set it, explicitly null
+// included, eg. for the partial updates of a mutation:
+//
+//	type UpdateUser struct {
+//		Name     Omittable[string]  `graphql:"name"`
+//		Nickname Omittable[*string] `graphql:"nickname"`
+//	}
+//
+// The GraphQL type of an Omittable[T] is the nullable type of T. An explicit null is set with the zero value of
+// T, so that a *T tells null apart from the zero value.
+type Omittable[T interface{}] struct {
+	value T
+	set   bool
+}
+
+// OmittableOf returns the Omittable set to value.
+func OmittableOf[T interface{}](value T) Omittable[T] {
+	return Omittable[T]{value: value, set: true}
+}
+
+// Value returns the value of o, the zero value of T when it is omitted.
+func (o Omittable[T]) Value() T {
+	return o.value
+}
+
+// IsSet reports whether the client provided the value of o, null included.
+func (o Omittable[T]) IsSet() bool {
+	return o.set
+}
+
+// Get returns the value of o and whether the client provided it.
+func (o Omittable[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+func (o Omittable[T]) omittableElem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (o *Omittable[T]) setOmittable(v reflect.Value) error {
+	o.set = true
+	if !v.IsValid() {
+		return nil
+	}
+	return value(reflect.ValueOf(&o.value).Elem(), v)
+}
+
+func (o Omittable[T]) omittableValue() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+type omittable interface {
+	omittableElem() reflect.Type
+	omittableValue() reflect.Value
+}
+
+// omittableSetter sets the Omittable fields of the decoded structs.
+type omittableSetter interface {
+	setOmittable(v reflect.Value) error
+}
+
+var omittableType = reflect.TypeOf((*omittable)(nil)).Elem()
+
+// omittableElem returns T when typ is an Omittable[T].
+func omittableElem(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Struct || !typ.Implements(omittableType) {
+		return nil, false
+	}
+	return reflect.Zero(typ).Interface().(omittable).omittableElem(), true
+}
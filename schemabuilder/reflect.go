@@ -6,6 +6,7 @@ import (
 	"go/ast"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 type structFields struct {
@@ -68,22 +69,42 @@ func getMethod(source interface{}, name string) reflect.Type {
 	return nil
 }
 
-func GetField(typ reflect.Value, name string) *reflect.Value {
+// fieldIndexes caches the indexes of the fields of the struct types by their graphql name, their tag names or
+// their Go names, so that the resolvers of the struct fields do not parse the tags of the struct for every value.
+var fieldIndexes sync.Map // map[reflect.Type]map[string]int
+
+// fieldIndex returns the index of the field of the struct type typ named name.
+func fieldIndex(typ reflect.Type, name string) (int, bool) {
+	if indexes, ok := fieldIndexes.Load(typ); ok {
+		i, ok := indexes.(map[string]int)[name]
+		return i, ok
+	}
+	indexes := make(map[string]int, typ.NumField())
+	add := func(name string, i int) {
+		if _, ok := indexes[name]; !ok {
+			indexes[name] = i
+		}
+	}
 	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		fieldTyp := typ.Type().Field(i)
+		fieldTyp := typ.Field(i)
 		tag := fieldTyp.Tag.Get("graphql")
 		if tag == "" || tag == "-" {
-			if fieldTyp.Name == name {
-				return &field
-			}
-		}
-		split := strings.Split(tag, ";")
-		if split[0] == name {
-			return &field
+			add(fieldTyp.Name, i)
 		}
+		add(strings.Split(tag, ";")[0], i)
 	}
-	return nil
+	fieldIndexes.Store(typ, indexes)
+	i, ok := indexes[name]
+	return i, ok
+}
+
+func GetField(typ reflect.Value, name string) *reflect.Value {
+	i, ok := fieldIndex(typ.Type(), name)
+	if !ok {
+		return nil
+	}
+	field := typ.Field(i)
+	return &field
 }
 
 func Convert(args map[string]interface{}, typ reflect.Type) (interface{}, error) {
@@ -136,7 +157,7 @@ func Convert(args map[string]interface{}, typ reflect.Type) (interface{}, error)
 
 func value(f reflect.Value, v reflect.Value) error {
 	if f.IsValid() {
-		f.Set(reflect.New(f.Type()).Elem())
+		f.SetZero()
 	}
 	for v.Kind() == reflect.Interface {
 		if v.IsNil() {
@@ -58,3 +58,20 @@ func TestConvert(t *testing.T) {
 		F:  [][]*int{{&f}},
 	}, convert)
 }
+
+func TestGetField(t *testing.T) {
+	type Source struct {
+		ID      string `graphql:"id;the id;nonnull"`
+		Name    string
+		Skipped string `graphql:"-"`
+		Alias   string `graphql:"Name"`
+	}
+	value := reflect.ValueOf(Source{ID: "1", Name: "name", Skipped: "skipped", Alias: "alias"})
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "1", schemabuilder.GetField(value, "id").Interface())
+		assert.Equal(t, "name", schemabuilder.GetField(value, "Name").Interface())
+		assert.Equal(t, "skipped", schemabuilder.GetField(value, "Skipped").Interface())
+		assert.Nil(t, schemabuilder.GetField(value, "ID"))
+		assert.Nil(t, schemabuilder.GetField(value, "unknown"))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/shyptr/graphql/internal"
 	"reflect"
+	"sync"
 )
 
 type resolveFunc func(interface{}) (interface{}, error)
@@ -19,6 +20,9 @@ func (sb *schemaBuilder) getArguments(typ reflect.Type) (map[string]*internal.In
 		if skip {
 			continue
 		}
+		if err := sb.registerInput(field.Type); err != nil {
+			return nil, err
+		}
 		fieldTyp, err := sb.getType(field.Type)
 		if err != nil {
 			return nil, err
@@ -42,11 +46,49 @@ func (sb *schemaBuilder) getArguments(typ reflect.Type) (map[string]*internal.In
 			Desc:         desc,
 			DefaultValue: defaultValue,
 		}
+		// a deprecated argument is tagged `deprecated:"reason"`
+		if reason, ok := field.Tag.Lookup("deprecated"); ok {
+			if reason == "" {
+				reason = internal.DefaultDeprecationReason
+			}
+			args[name].DeprecationReason = reason
+		}
 	}
 	sb.cacheTypes[typ] = sb.converToStruct(typ)
 	return args, nil
 }
 
+// registerInput registers the struct type of an argument or of an input field, which is not registered, as an
+// input object named after the Go type, its fields are mapped by their graphql tags.
+func (sb *schemaBuilder) registerInput(typ reflect.Type) error {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if elem, ok := omittableElem(typ); ok {
+		return sb.registerInput(elem)
+	}
+	if typ.Kind() != reflect.Struct || sb.scalars[typ] != nil || sb.inputObjects[typ] != nil {
+		return nil
+	}
+	if sb.objects[typ] != nil || sb.unions[typ] != nil {
+		return fmt.Errorf("%s is an output type, it cannot be an input", typ)
+	}
+	if typ.Name() == "" {
+		return fmt.Errorf("anonymous struct %s cannot be an input, it must be registered as an InputObject", typ)
+	}
+	for other, input := range sb.inputObjects {
+		if input.Name == typ.Name() {
+			return fmt.Errorf("input %s of %s conflicts with the input object of %s", typ.Name(), typ, other)
+		}
+	}
+	sb.inputObjects[typ] = &InputObject{
+		Name:   typ.Name(),
+		Type:   reflect.Zero(typ).Interface(),
+		Fields: map[string]*inputFieldResolve{},
+	}
+	return nil
+}
+
 func (sb *schemaBuilder) getArgResolve(src reflect.Type, typ internal.Type) error {
 	for src.Kind() == reflect.Ptr {
 		src = src.Elem()
@@ -54,12 +96,28 @@ func (sb *schemaBuilder) getArgResolve(src reflect.Type, typ internal.Type) erro
 	if _, ok := sb.cacheTypes[src]; ok {
 		return nil
 	}
+	if elem, ok := omittableElem(src); ok {
+		if err := sb.getArgResolve(elem, typ); err != nil {
+			return err
+		}
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		sb.cacheTypes[src] = func(value interface{}) (interface{}, error) {
+			return sb.cacheTypes[elem](value)
+		}
+		return nil
+	}
 	switch typ := typ.(type) {
 	case *internal.Scalar:
 		sb.cacheTypes[src] = func(value interface{}) (interface{}, error) {
 			if value == nil {
 				return nil, nil
 			}
+			// the literals parsed by the ParseLiteral of the scalar already are of its type
+			if reflect.TypeOf(value) == src {
+				return value, nil
+			}
 			return typ.ParseValue(value)
 		}
 		return nil
@@ -88,40 +146,36 @@ func (sb *schemaBuilder) getArgResolve(src reflect.Type, typ internal.Type) erro
 	case *internal.NonNull:
 		return sb.getArgResolve(src, typ.Type)
 	case *internal.List:
-		if err := sb.getArgResolve(src.Elem(), typ.Type); err != nil {
+		elem := src.Elem()
+		if err := sb.getArgResolve(elem, typ.Type); err != nil {
 			return err
 		}
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
 		sb.cacheTypes[src] = func(value interface{}) (interface{}, error) {
 			if value == nil {
 				return nil, nil
 			}
+			resolve := sb.cacheTypes[elem]
 			v := reflect.ValueOf(value)
-			vtyp := v.Type()
 			if v.Kind() != reflect.Slice {
-				if resolve, ok := sb.cacheTypes[vtyp]; ok {
-					if value, err := resolve(value); err == nil {
-						return []interface{}{value}, nil
-					} else {
-						return nil, err
-					}
+				// a single value is accepted as a list of one item
+				item, err := resolve(value)
+				if err != nil {
+					return nil, err
 				}
-				return nil, fmt.Errorf("unexpected type %s", src.String())
-			} else {
-				var res []interface{}
-				for i := 0; i < v.Len(); i++ {
-					val := v.Index(i)
-					if resolve, ok := sb.cacheTypes[val.Type()]; ok {
-						if value, err := resolve(val.Interface()); err == nil {
-							res = append(res, value)
-						} else {
-							return nil, err
-						}
-					} else {
-						return nil, fmt.Errorf("unexpected type %s", src.String())
-					}
+				return []interface{}{item}, nil
+			}
+			res := make([]interface{}, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				item, err := resolve(v.Index(i).Interface())
+				if err != nil {
+					return nil, err
 				}
-				return nil, nil
+				res[i] = item
 			}
+			return res, nil
 		}
 		return nil
 	default:
@@ -129,37 +183,125 @@ func (sb *schemaBuilder) getArgResolve(src reflect.Type, typ internal.Type) erro
 	}
 }
 
+// converToStruct returns the resolveFunc building the values of the struct typ, the arguments of a field or an input
+// object, from their map. Its decoder is computed on the first call, once the schema is built.
 func (sb *schemaBuilder) converToStruct(typ reflect.Type) resolveFunc {
+	var once sync.Once
+	var decoder *structDecoder
 	return func(value interface{}) (interface{}, error) {
-		args := value.(map[string]interface{})
+		once.Do(func() {
+			decoder = sb.schemaDecoder(typ)
+		})
+		return decoder.decode(value.(map[string]interface{}))
+	}
+}
 
-		if input, ok := sb.inputObjects[typ]; ok {
-			for name, f := range input.Fields {
-				if _, ok := args[name]; !ok {
-					args[name] = f.DefaultValue
-				}
+// structDecoder builds the values of a struct type from their map, without parsing the tags of the struct for
+// every value. The decoders of the schema parse the values of the fields with their resolve, see decode, the ones
+// of DecodeArgs convert them to the types of the fields, see decodeInto.
+type structDecoder struct {
+	typ    reflect.Type
+	fields []decodedField
+	// oneOf is the name of the @oneOf input object decoded, its fields are checked when a variable is null
+	oneOf string
+}
+
+type decodedField struct {
+	name  string
+	index int
+	// resolve parses the value of the field, nil when the field has no resolve
+	resolve resolveFunc
+	// defaultValue is the value of the field of an input object when it is missing
+	defaultValue interface{}
+	hasDefault   bool
+	// omittable is set for the Omittable fields, which are set even when their value is null
+	omittable bool
+}
+
+// newStructDecoder returns the decoder of the struct typ, whose fields have no resolve.
+func newStructDecoder(typ reflect.Type) *structDecoder {
+	decoder := &structDecoder{typ: typ}
+	for i := 0; i < typ.NumField(); i++ {
+		skip, _, name, _ := parseFieldTag(typ.Field(i))
+		if skip {
+			continue
+		}
+		field := decodedField{name: name, index: i}
+		_, field.omittable = omittableElem(fieldElem(typ.Field(i).Type))
+		decoder.fields = append(decoder.fields, field)
+	}
+	return decoder
+}
+
+// schemaDecoder returns the decoder of the struct typ whose fields are parsed with the resolves of the schema,
+// along with the default values and the @oneOf check of its input object.
+func (sb *schemaBuilder) schemaDecoder(typ reflect.Type) *structDecoder {
+	decoder := newStructDecoder(typ)
+	input := sb.inputObjects[typ]
+	if input != nil && input.OneOf {
+		decoder.oneOf = input.Name
+	}
+	for i := range decoder.fields {
+		field := &decoder.fields[i]
+		field.resolve = sb.cacheTypes[fieldElem(typ.Field(field.index).Type)]
+		if input != nil {
+			if f, ok := input.Fields[field.name]; ok {
+				field.defaultValue, field.hasDefault = f.DefaultValue, true
 			}
 		}
+	}
+	return decoder
+}
 
-		conver := make(map[string]interface{})
-		for i := 0; i < typ.NumField(); i++ {
-			field := typ.Field(i)
-			skip, _, name, _ := parseFieldTag(typ.Field(i))
-			if skip {
-				continue
+// fieldElem returns typ without its pointers.
+func fieldElem(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// decode returns the struct of args, args is left as is.
+func (d *structDecoder) decode(args map[string]interface{}) (interface{}, error) {
+	if d.oneOf != "" {
+		provided := 0
+		for _, v := range args {
+			if v != nil {
+				provided++
 			}
-			ftyp := field.Type
-			for ftyp.Kind() == reflect.Ptr {
-				ftyp = ftyp.Elem()
+		}
+		if len(args) != 1 || provided != 1 {
+			return nil, fmt.Errorf("oneOf input object %s must specify exactly one non-null field", d.oneOf)
+		}
+	}
+	tv := reflect.New(d.typ).Elem()
+	for _, field := range d.fields {
+		v, ok := args[field.name]
+		if !ok {
+			if !field.hasDefault {
+				continue
 			}
-			if v, ok := args[name]; ok {
-				vv, err := sb.cacheTypes[ftyp](v)
-				if err != nil {
-					return nil, err
-				}
-				conver[name] = vv
+			v = field.defaultValue
+		}
+		if field.resolve == nil {
+			return nil, fmt.Errorf("%s have null resolve for input field %s", d.typ, field.name)
+		}
+		resolved, err := field.resolve(v)
+		if err != nil {
+			return nil, err
+		}
+		if field.omittable {
+			if err := tv.Field(field.index).Addr().Interface().(omittableSetter).setOmittable(reflect.ValueOf(resolved)); err != nil {
+				return nil, err
 			}
+			continue
+		}
+		if resolved == nil {
+			continue
+		}
+		if err := value(tv.Field(field.index), reflect.ValueOf(resolved)); err != nil {
+			return nil, err
 		}
-		return Convert(conver, typ)
 	}
+	return tv.Interface(), nil
 }
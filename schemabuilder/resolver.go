@@ -0,0 +1,79 @@
+package schemabuilder
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+)
+
+// Resolver exposes every exported method of resolver as a field of the object, so the resolvers of a type
+// can be kept in a struct of their own instead of being registered one FieldFunc at a time:
+//
+//	type userResolver struct{ db *DB }
+//
+//	func (r *userResolver) Posts(ctx context.Context, u *User, args struct{ First int }) ([]*Post, error)
+//
+//	s.Object("User", User{}).Resolver(&userResolver{db})
+//
+// A method takes the same arguments as a FieldFunc and names its field with a lower case first word,
+// Posts becomes posts and URLPath becomes urlPath. The options are applied to every field.
+func (s *Object) Resolver(resolver interface{}, options ...interface{}) {
+	value := reflect.ValueOf(resolver)
+	typ := value.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if !method.IsExported() {
+			continue
+		}
+		s.FieldFunc(methodFieldName(method.Name), value.Method(i).Interface(), options...)
+	}
+}
+
+// Resolvers discovers the resolver of each object from the methods of root: a method without arguments
+// returning a single value is the resolver of the object it is named after, eg.
+//
+//	func (r *Resolver) Query() *queryResolver { return &queryResolver{r} }
+//	func (r *Resolver) User() *userResolver { return &userResolver{r} }
+//
+// Query, Mutation and Subscription are registered when they are missing, the other objects must already be.
+func (s *Schema) Resolvers(root interface{}) {
+	value := reflect.ValueOf(root)
+	typ := value.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+			continue
+		}
+		var object *Object
+		switch method.Name {
+		case "Query":
+			object = s.Query()
+		case "Mutation":
+			object = s.Mutation()
+		case "Subscription":
+			object = s.Subscription()
+		default:
+			var ok bool
+			if object, ok = s.objects[method.Name]; !ok {
+				panic(fmt.Sprintf("resolver %s.%s is not named after an object", typ, method.Name))
+			}
+		}
+		object.Resolver(value.Method(i).Call(nil)[0].Interface())
+	}
+}
+
+func methodFieldName(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// keep the last upper case letter of an acronym followed by a word: URLPath is urlPath
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,60 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type User struct {
+	Name string `graphql:"name"`
+}
+
+type Post struct {
+	Title string `graphql:"title"`
+}
+
+type rootResolver struct {
+	posts map[string][]*Post
+}
+
+func (r *rootResolver) Query() *queryResolver { return &queryResolver{r} }
+func (r *rootResolver) User() *userResolver   { return &userResolver{r} }
+
+type queryResolver struct{ *rootResolver }
+
+func (r *queryResolver) Me(ctx context.Context) *User { return &User{Name: "luke"} }
+
+type userResolver struct{ *rootResolver }
+
+func (r *userResolver) Posts(u *User, args struct {
+	First int `graphql:"first"`
+}) []*Post {
+	return r.posts[u.Name][:args.First]
+}
+
+func (r *userResolver) URLPath(u *User) string { return fmt.Sprintf("/users/%s", u.Name) }
+
+func TestSchema_Resolvers(t *testing.T) {
+	builder := schemabuilder.NewSchema()
+	builder.Object("User", User{})
+	builder.Object("Post", Post{})
+	builder.Resolvers(&rootResolver{posts: map[string][]*Post{"luke": {{Title: "a"}, {Title: "b"}}}})
+	schema := builder.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ me { name urlPath posts(first: 1) { title } } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	assert.Equal(t, map[string]interface{}{"me": map[string]interface{}{
+		"name":    "luke",
+		"urlPath": "/users/luke",
+		"posts":   []interface{}{map[string]interface{}{"title": "a"}},
+	}}, result)
+
+	t.Run("panics for a resolver without object", func(t *testing.T) {
+		assert.Panics(t, func() { schemabuilder.NewSchema().Resolvers(&rootResolver{}) })
+	})
+}
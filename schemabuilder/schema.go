@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/internal"
+	"maps"
 	"reflect"
 	"strconv"
 )
@@ -28,6 +28,11 @@ type Schema struct {
 	unions       map[string]*Union
 	scalars      map[string]*Scalar
 	directives   map[string]*Directive
+	middlewares  []FieldMiddleware
+	// dynamicObjects are the objects defined at runtime, see DynamicObject
+	dynamicObjects map[string]*DynamicObject
+	// intOverflow is how the Int fields serialize the values out of its range, see SetIntOverflow
+	intOverflow IntOverflow
 }
 
 // NewSchema creates a new schema.
@@ -38,7 +43,7 @@ func NewSchema() *Schema {
 		inputObjects: map[string]*InputObject{},
 		interfaces:   map[string]*Interface{},
 		unions:       map[string]*Union{},
-		scalars:      scalars,
+		scalars:      maps.Clone(scalars),
 		directives: map[string]*Directive{
 			"include": IncludeDirective,
 			"skip":    SkipDirective,
@@ -57,6 +62,15 @@ type DescField struct {
 	Desc  string
 }
 
+// only use in enum definition
+// marks an enum value deprecated, an empty reason is the default one
+var DeprecatedFieldTyp = reflect.TypeOf(DeprecatedField{})
+
+type DeprecatedField struct {
+	Field  interface{}
+	Reason string
+}
+
 // Enum registers an enumType in the schema. The val should be any arbitrary value
 // of the enumType to be used for reflection, and the enumMap should be
 // the corresponding map of the enums.
@@ -73,7 +87,7 @@ type DescField struct {
 //   s.Enum("number",enumType(1), map[string]interface{}{
 //     "one":   DescField{one,"the first one"},
 //     "two":   two,
-//     "three": three,
+//     "three": DeprecatedField{three,"use two"},
 //   },"")
 func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...string) {
 	if name == "" {
@@ -93,26 +107,43 @@ func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...st
 	rMap := make(map[interface{}]string)
 	eMap := make(map[string]interface{})
 	dMap := make(map[string]string)
+	deprecations := make(map[string]string)
 	for em := enumMap.MapRange(); em.Next(); {
 		desc := ""
+		key := em.Key().String()
 		val := em.Value()
 		for val.Kind() == reflect.Interface {
 			val = val.Elem()
 		}
 		valInterface := val.Interface()
 		if val.Kind() != typ.Kind() {
-			if val.Type() == DescFieldTyp {
+			switch val.Type() {
+			case DescFieldTyp:
 				value := reflect.ValueOf(valInterface)
 				desc = value.FieldByName("Desc").String()
 				valInterface = value.FieldByName("Field").Interface()
 				if reflect.TypeOf(valInterface).Kind() != typ.Kind() {
 					panic("enum descField's field types are not equal")
 				}
-			} else {
+			case DeprecatedFieldTyp:
+				value := reflect.ValueOf(valInterface)
+				reason := value.FieldByName("Reason").String()
+				if reason == "" {
+					reason = internal.DefaultDeprecationReason
+				}
+				deprecations[key] = reason
+				valInterface = value.FieldByName("Field").Interface()
+				if reflect.TypeOf(valInterface).Kind() != typ.Kind() {
+					panic("enum deprecatedField's field types are not equal")
+				}
+			default:
 				panic("enum types are not equal")
 			}
 		}
-		key := em.Key().String()
+		// the values of the underlying type of the enum type, eg. untyped constants, are converted to it
+		if value := reflect.ValueOf(valInterface); value.Type() != typ {
+			valInterface = value.Convert(typ).Interface()
+		}
 		eMap[key] = valInterface
 		rMap[valInterface] = key
 		dMap[key] = desc
@@ -122,12 +153,13 @@ func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...st
 		d = desc[0]
 	}
 	s.enums[name] = &Enum{
-		Name:       name,
-		Desc:       d,
-		Type:       val,
-		Map:        eMap,
-		ReverseMap: rMap,
-		DescMap:    dMap,
+		Name:           name,
+		Desc:           d,
+		Type:           val,
+		Map:            eMap,
+		ReverseMap:     rMap,
+		DescMap:        dMap,
+		DeprecationMap: deprecations,
 	}
 }
 
@@ -165,6 +197,8 @@ func (s *Schema) Object(name string, typ interface{}, desc ...string) *Object {
 
 // InputObject registers a struct as inout object which can be passed as an argument to a Query or Mutation
 // We'll read through the fields of the struct and create argument parsers to fill the data from graphQL JSON input
+// The structs reached by the arguments and the input fields which are not registered are input objects named after
+// their Go type, their fields mapped by their graphql tags like the ones of a registered struct.
 func (s *Schema) InputObject(name string, typ interface{}, desc ...string) *InputObject {
 	if inputObject, ok := s.inputObjects[name]; ok {
 		if reflect.TypeOf(inputObject.Type) != reflect.TypeOf(typ) {
@@ -296,10 +330,6 @@ func (s *Schema) Scalar(name string, tp interface{}, options ...interface{}) *Sc
 		Type:       tp,
 		Serialize:  Serialize,
 		ParseValue: parseValue,
-		ParseLiteral: func(value ast.Value) error {
-			_, err := parseValue(value.GetValue())
-			return err
-		},
 	}
 	s.scalars[name] = scalar
 	return scalar
@@ -368,8 +398,12 @@ func (s *Schema) Interface(name string, typ interface{}, typeResolve interface{}
 //
 // use as :
 // s.Directive("dir",[]string{"Field"},struct{ a scalar `graphql:"a,nonnull,is a"` },"testdir")
-
-func (s *Schema) Directive(name string, locs []string, fn interface{}, desc ...string) {
+//
+// Directives used on fields wrap the resolver of the field, fn receives the decoded arguments of the directive
+// and a func() (interface{}, error) calling the wrapped resolver, what fn returns is the value of the field.
+// fn may also return a bool first, true lets the field resolve: its value is then the one of the wrapped resolver,
+// which is called once whether fn called it or not.
+func (s *Schema) Directive(name string, locs []string, fn interface{}, desc ...string) *Directive {
 	// Ensure directive is named
 	if name == "" {
 		panic("Directive must be named.")
@@ -384,13 +418,15 @@ func (s *Schema) Directive(name string, locs []string, fn interface{}, desc ...s
 	}
 
 	s.directives[name] = &Directive{
-		Name: name,
-		Fn:   fn,
-		Locs: locs,
+		Name:   name,
+		Fn:     fn,
+		Locs:   locs,
+		Fields: map[string]*inputFieldResolve{},
 	}
 	if len(desc) > 0 {
 		s.directives[name].Desc = desc[0]
 	}
+	return s.directives[name]
 }
 
 func (s *Schema) GetInterface(name string) *Interface {
@@ -428,12 +464,13 @@ func (s *Schema) Subscription() *Object {
 // Query, Mutation and Subscription Objects and ensure that those functions are returning other Objects that we can resolve in our GraphQL graph.
 func (s *Schema) Build() (*internal.Schema, error) {
 	sb := &schemaBuilder{
-		types:      make(map[reflect.Type]internal.Type),
-		cacheTypes: make(map[reflect.Type]resolveFunc),
-		enums:      make(map[reflect.Type]*Enum, len(s.enums)),
-		interfaces: make(map[reflect.Type]*Interface, len(s.interfaces)),
-		scalars:    make(map[reflect.Type]*Scalar, len(s.scalars)),
-		unions:     make(map[reflect.Type]*Union, len(s.unions)),
+		types:       make(map[reflect.Type]internal.Type),
+		cacheTypes:  make(map[reflect.Type]resolveFunc),
+		enums:       make(map[reflect.Type]*Enum, len(s.enums)),
+		interfaces:  make(map[reflect.Type]*Interface, len(s.interfaces)),
+		scalars:     make(map[reflect.Type]*Scalar, len(s.scalars)),
+		unions:      make(map[reflect.Type]*Union, len(s.unions)),
+		intOverflow: s.intOverflow,
 		objects: map[reflect.Type]*Object{
 			paginationInfoType.Elem(): {
 				Name: paginationInfoType.Name(),
@@ -554,6 +591,10 @@ func (s *Schema) Build() (*internal.Schema, error) {
 			typeMap[named.TypeName()] = named
 		}
 	}
+	if err := s.buildDynamicObjects(sb, typeMap); err != nil {
+		return nil, err
+	}
+	s.applyMiddlewares(typeMap)
 	return &internal.Schema{
 		TypeMap:      typeMap,
 		Query:        queryTyp,
@@ -0,0 +1,105 @@
+package schemabuilder
+
+import (
+	"context"
+	"io"
+	"reflect"
+)
+
+// SourceStream is the stream of events a subscription field resolves to, so any event backend can feed
+// subscriptions. The field has the GraphQL type of T.
+//
+// Next blocks until the next event of the stream, or until ctx is done and then returns the error of ctx.
+// It returns io.EOF once the stream is completed; any other error ends the subscription with a last
+// response reporting it. Close is called once the subscription ends, whether the stream completed or the
+// subscriber went away, to release the resources of the stream, eg. its subscription to a broker.
+//
+// Resolvers may also return a receive channel, which is a stream completing when the channel is closed.
+type SourceStream[T interface{}] interface {
+	Next(ctx context.Context) (T, error)
+	Close()
+}
+
+// Stream is a SourceStream over a channel, with a cleanup called when the subscription ends.
+type Stream[T interface{}] struct {
+	// Events are the events of the stream, the stream completes once it is closed.
+	Events <-chan T
+	// Err, when set, is called once Events is closed: a non-nil error ends the stream with that error.
+	Err func() error
+	// Cleanup, when set, is called by Close.
+	Cleanup func()
+}
+
+// NewStream returns a Stream of events, cleanup may be nil.
+func NewStream[T interface{}](events <-chan T, cleanup func()) *Stream[T] {
+	return &Stream[T]{Events: events, Cleanup: cleanup}
+}
+
+func (s *Stream[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	select {
+	case event, ok := <-s.Events:
+		if ok {
+			return event, nil
+		}
+		if s.Err != nil {
+			if err := s.Err(); err != nil {
+				return zero, err
+			}
+		}
+		return zero, io.EOF
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (s *Stream[T]) Close() {
+	if s.Cleanup != nil {
+		s.Cleanup()
+	}
+}
+
+// streamEventType returns T when typ implements SourceStream[T], nil otherwise.
+func streamEventType(typ reflect.Type) reflect.Type {
+	next, ok := typ.MethodByName("Next")
+	if !ok {
+		return nil
+	}
+	closeMethod, ok := typ.MethodByName("Close")
+	if !ok {
+		return nil
+	}
+	// the methods of a concrete type take the receiver first
+	receiver := 1
+	if typ.Kind() == reflect.Interface {
+		receiver = 0
+	}
+	nextTyp, closeTyp := next.Type, closeMethod.Type
+	if nextTyp.NumIn() != receiver+1 || nextTyp.In(receiver) != contextType ||
+		nextTyp.NumOut() != 2 || nextTyp.Out(1) != errType ||
+		closeTyp.NumIn() != receiver || closeTyp.NumOut() != 0 {
+		return nil
+	}
+	return nextTyp.Out(0)
+}
+
+// sourceStream adapts a SourceStream[T] to the internal.SourceStream run by the executor.
+type sourceStream struct {
+	next, close reflect.Value
+}
+
+func newSourceStream(stream reflect.Value) sourceStream {
+	return sourceStream{next: stream.MethodByName("Next"), close: stream.MethodByName("Close")}
+}
+
+func (s sourceStream) Next(ctx context.Context) (interface{}, error) {
+	out := s.next.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err := out[1]; !err.IsNil() {
+		return nil, err.Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
+
+func (s sourceStream) Close() {
+	s.close.Call(nil)
+}
@@ -21,6 +21,9 @@ type Object struct {
 	Type         interface{}
 	FieldResolve map[string]*fieldResolve
 	Interface    []*Interface
+	// Extensions are the metadata of the object, exposed to the tooling reading the schema, see Extension. The
+	// owner of the object, see Owner, is the owner of the fields which have none of their own.
+	Extensions map[string]interface{}
 }
 
 // InputObject represents the input objects passed in queries,mutations and subscriptions
@@ -29,8 +32,19 @@ type InputObject struct {
 	Desc   string
 	Type   interface{}
 	Fields map[string]*inputFieldResolve
+	// OneOf makes the input object a @oneOf input object: exactly one of its fields is provided and non-null,
+	// eg. to look up a user by id or by email. Its fields must be nullable and have no default value.
+	//
+	//	s.InputObject("UserBy", UserBy{}).OneOf = true
+	OneOf      bool
+	validators []InputValidator
 }
 
+// InputValidator checks an input object as a whole, after every field has been coerced,
+// so it can enforce rules across fields, eg. "startDate must be before endDate".
+// The value is the decoded go value of the registered input object type.
+type InputValidator func(ctx context.Context, value interface{}) error
+
 type FieldFuncOption interface {
 	execute(interface{}) (interface{}, error)
 }
@@ -81,6 +95,119 @@ var NonNullField afterBuildFunc = func(param buildParam) error {
 	return nil
 }
 
+// Timeout bounds the resolver calls of a field, it overrides the FieldTimeout of the executor.
+func Timeout(d time.Duration) afterBuildFunc {
+	return func(param buildParam) error {
+		param.f.Timeout = d
+		return nil
+	}
+}
+
+// ErrorPolicy tells what becomes of a field which fails.
+type ErrorPolicy = internal.ErrorPolicy
+
+const (
+	// NullOnError makes the field null, or its failed items when it is a list of nullable items, even when the
+	// field is non-null.
+	NullOnError = internal.NullOnError
+	// PropagateError makes the field null when it is nullable, else its parent object, as the specification tells.
+	// It is the default policy of the executors.
+	PropagateError = internal.PropagateError
+	// OmitOnError leaves the failed items out of the list of the field.
+	OmitOnError = internal.OmitOnError
+)
+
+// OnError sets the error policy of a field, it overrides the ErrorPolicy of the executor:
+//
+//	s.Query().FieldFunc("widgets", fn, schemabuilder.OnError(schemabuilder.OmitOnError))
+func OnError(policy ErrorPolicy) afterBuildFunc {
+	return func(param buildParam) error {
+		param.f.ErrorPolicy = policy
+		return nil
+	}
+}
+
+// Owner tags a field with the team owning it, the owner is added to the extensions of the field errors and labels
+// its metrics. It is the extension internal.OwnerExtension of the field, which objects have too:
+//
+//	s.Query().FieldFunc("invoice", fn, schemabuilder.Owner("billing"))
+//	s.Object("Invoice", Invoice{}).Extensions = map[string]interface{}{internal.OwnerExtension: "billing"}
+func Owner(team string) afterBuildFunc {
+	return Extension(internal.OwnerExtension, team)
+}
+
+// inheritOwner tags field with the owner of object when it has no owner of its own.
+func inheritOwner(field *internal.Field, object *internal.Object) {
+	owner, ok := object.Extensions[internal.OwnerExtension]
+	if !ok {
+		return
+	}
+	if _, ok := field.Extensions[internal.OwnerExtension]; ok {
+		return
+	}
+	// the extensions may be shared by several fields
+	extensions := make(map[string]interface{}, len(field.Extensions)+1)
+	for key, value := range field.Extensions {
+		extensions[key] = value
+	}
+	extensions[internal.OwnerExtension] = owner
+	field.Extensions = extensions
+}
+
+// Extension attaches value under key to the metadata of a field, which the tooling reading the schema gets, eg.
+// through introspection.ExtensionsField. The executor only reads the owner of the field, see Owner.
+//
+//	s.Query().FieldFunc("invoice", fn, schemabuilder.Extension("tags", []string{"billing", "pii"}))
+func Extension(key string, value interface{}) afterBuildFunc {
+	return func(param buildParam) error {
+		if param.f.Extensions == nil {
+			param.f.Extensions = make(map[string]interface{})
+		}
+		param.f.Extensions[key] = value
+		return nil
+	}
+}
+
+// Deprecated marks a field deprecated, reason tells the clients what to use instead.
+//
+//	s.Object("User", User{}).FieldFunc("fullName", fn, schemabuilder.Deprecated("Use name instead."))
+func Deprecated(reason string) afterBuildFunc {
+	return func(param buildParam) error {
+		if reason == "" {
+			reason = internal.DefaultDeprecationReason
+		}
+		param.f.DeprecationReason = reason
+		return nil
+	}
+}
+
+// Filter drops the events of a subscription field for which filter returns false, before the selection set
+// of the subscription is executed for them. args are the arguments of the field, decoded like those of its resolver.
+//
+//	s.Subscription().FieldFunc("messages", fn, schemabuilder.Filter(func(ctx context.Context, event, args interface{}) bool {
+//		return event.(*Message).Room == args.(MessagesArgs).Room
+//	}))
+func Filter(filter func(ctx context.Context, event, args interface{}) bool) afterBuildFunc {
+	return func(param buildParam) error {
+		decode := func(args interface{}) (interface{}, error) { return args, nil }
+		if param.functx.hasArg {
+			argResolve, ok := param.sb.cacheTypes[param.functx.argTyp]
+			if !ok {
+				return fmt.Errorf("%s have null resolve for input arg", param.functx.argTyp)
+			}
+			decode = argResolve
+		}
+		param.f.Filter = func(ctx context.Context, event, args interface{}) bool {
+			args, err := decode(args)
+			if err != nil {
+				return false
+			}
+			return filter(ctx, event, args)
+		}
+		return nil
+	}
+}
+
 // Enum is a representation of an enum that includes both the mapping and reverse mapping.
 type Enum struct {
 	Name       string
@@ -89,6 +216,8 @@ type Enum struct {
 	Map        map[string]interface{}
 	ReverseMap map[interface{}]string
 	DescMap    map[string]string
+	// DeprecationMap are the deprecation reasons of the deprecated values
+	DeprecationMap map[string]string
 }
 
 // Interface is a representation of graphql interface
@@ -112,12 +241,13 @@ type Union struct {
 
 // Scalar is a representation of graphql scalar
 type Scalar struct {
-	Name         string
-	Desc         string
-	Type         interface{}
-	Serialize    func(interface{}) (interface{}, error)
-	ParseValue   func(interface{}) (interface{}, error)
-	ParseLiteral func(value ast.Value) error
+	Name       string
+	Desc       string
+	Type       interface{}
+	Serialize  func(interface{}) (interface{}, error)
+	ParseValue func(interface{}) (interface{}, error)
+	// ParseLiteral parses the inline literals of the scalar into values of Type, see LiteralFunc
+	ParseLiteral func(value ast.Value) (interface{}, error)
 }
 
 type Directive struct {
@@ -134,18 +264,20 @@ type Directive struct {
 //
 // For example, for an object of type User, a fullName field might take just an
 // instance of the object:
-//    user.FieldDefault("fullName", func(u *User) string {
-//       return u.FirstName + " " + u.LastName
-//    })
+//
+//	user.FieldDefault("fullName", func(u *User) string {
+//	   return u.FirstName + " " + u.LastName
+//	})
 //
 // An addUser Mutation field might take both a context and arguments:
-//    Mutation.FieldFunc("addUser", func(ctx context.context, args struct{
-//        FirstName string
-//        LastName  string
-//    }) (int, error) {
-//        userID, err := db.AddUser(ctx, args.FirstName, args.LastName)
-//        return userID, err
-//    })
+//
+//	Mutation.FieldFunc("addUser", func(ctx context.context, args struct{
+//	    FirstName string
+//	    LastName  string
+//	}) (int, error) {
+//	    userID, err := db.AddUser(ctx, args.FirstName, args.LastName)
+//	    return userID, err
+//	})
 func (s *Object) FieldFunc(name string, fn interface{}, options ...interface{}) {
 	if s.FieldResolve == nil {
 		s.FieldResolve = make(map[string]*fieldResolve)
@@ -189,6 +321,12 @@ func (io *InputObject) FieldDefault(name string, defaultValue interface{}) {
 	io.Fields[name] = resolve
 }
 
+// Validate registers a validator which is invoked every time a value of the input object is decoded.
+// Nested input objects are validated before the input objects containing them.
+func (io *InputObject) Validate(fn InputValidator) {
+	io.validators = append(io.validators, fn)
+}
+
 // FieldDefault is used to expose the fields of an input object
 func (io *Directive) FieldDefault(name string, defaultValue interface{}) {
 	if _, ok := io.Fields[name]; ok {
@@ -244,8 +382,15 @@ func (s *Interface) InterfaceList(list ...*Interface) {
 	}
 }
 
-// use to valid type, if not set, will use parseValue
-func (s *Scalar) LiteralFunc(fn func(value ast.Value) error) {
+// LiteralFunc sets the parser of the inline literals of the scalar, which interprets them distinctly from the
+// values of the variables, eg. the object literals of a JSON scalar. fn returns a value of the Type of the scalar,
+// which the resolvers receive as is. When it is not set, the literals are converted to JSON and parsed with
+// ParseValue.
+//
+//	jsonScalar.LiteralFunc(func(value ast.Value) (interface{}, error) {
+//		return literalToJSON(value)
+//	})
+func (s *Scalar) LiteralFunc(fn func(value ast.Value) (interface{}, error)) {
 	s.ParseLiteral = fn
 }
 
@@ -291,23 +436,14 @@ var Int = &Scalar{
 	Name:      "Int",
 	Desc:      "int is a signed integer type that is at least 32 bits in size.",
 	Type:      int(0),
-	Serialize: Serialize,
+	Serialize: serializeInt,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return int32(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return int(0), nil
 		}
-		if val > math.MaxInt32 && val < math.MinInt32 {
-			return nil, errors.New("value not int32")
+		val, err := parseSigned(value, 32)
+		if err != nil {
+			return nil, err
 		}
 		return int(val), nil
 	},
@@ -319,21 +455,12 @@ var Int8 = &Scalar{
 	Type:      int8(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return int8(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return int8(0), nil
 		}
-		if val > math.MaxInt8 && val < math.MinInt8 {
-			return nil, errors.New("value not int8")
+		val, err := parseSigned(value, 8)
+		if err != nil {
+			return nil, err
 		}
 		return int8(val), nil
 	},
@@ -345,21 +472,12 @@ var Int16 = &Scalar{
 	Type:      int16(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return int16(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return int16(0), nil
 		}
-		if val > math.MaxInt16 && val < math.MinInt16 {
-			return nil, errors.New("value not int16")
+		val, err := parseSigned(value, 16)
+		if err != nil {
+			return nil, err
 		}
 		return int16(val), nil
 	},
@@ -371,21 +489,12 @@ var Int32 = &Scalar{
 	Type:      int32(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return int32(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return int32(0), nil
 		}
-		if val > math.MaxInt32 && val < math.MinInt32 {
-			return nil, errors.New("value not int32")
+		val, err := parseSigned(value, 32)
+		if err != nil {
+			return nil, err
 		}
 		return int32(val), nil
 	},
@@ -397,21 +506,12 @@ var Int64 = &Scalar{
 	Type:      int64(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return int64(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return int64(0), nil
 		}
-		if val > math.MaxInt64 && val < math.MinInt64 {
-			return nil, errors.New("value not int8")
+		val, err := parseSigned(value, 64)
+		if err != nil {
+			return nil, err
 		}
 		return int64(val), nil
 	},
@@ -423,21 +523,12 @@ var Uint = &Scalar{
 	Type:      uint(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return uint(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return uint(0), nil
 		}
-		if val > math.MaxUint32 && val < 0 {
-			return nil, errors.New("value not uint32")
+		val, err := parseUnsigned(value, 32)
+		if err != nil {
+			return nil, err
 		}
 		return uint(val), nil
 	},
@@ -449,21 +540,12 @@ var Uint8 = &Scalar{
 	Type:      uint8(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return uint8(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return uint8(0), nil
 		}
-		if val > math.MaxUint8 && val < 0 {
-			return nil, errors.New("value not uint8")
+		val, err := parseUnsigned(value, 8)
+		if err != nil {
+			return nil, err
 		}
 		return uint8(val), nil
 	},
@@ -475,21 +557,12 @@ var Uint16 = &Scalar{
 	Type:      uint16(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return uint16(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return uint16(0), nil
 		}
-		if val > math.MaxUint16 && val < 0 {
-			return nil, errors.New("value not uint16")
+		val, err := parseUnsigned(value, 16)
+		if err != nil {
+			return nil, err
 		}
 		return uint16(val), nil
 	},
@@ -501,23 +574,14 @@ var Uint32 = &Scalar{
 	Type:      uint32(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return uint32(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return uint32(0), nil
 		}
-		if val > math.MaxUint32 && val < 0 {
-			return nil, errors.New("value not uint32")
+		val, err := parseUnsigned(value, 32)
+		if err != nil {
+			return nil, err
 		}
-		return uint(val), nil
+		return uint32(val), nil
 	},
 }
 
@@ -527,21 +591,12 @@ var Uint64 = &Scalar{
 	Type:      uint64(0),
 	Serialize: Serialize,
 	ParseValue: func(value interface{}) (interface{}, error) {
-		var val float64
-		switch value := value.(type) {
-		case float64:
-			val = value
-		case *float64:
-			val = *value
-		default:
-			if value == nil {
-				return uint64(0), nil
-			} else {
-				return nil, errors.New("not a number")
-			}
+		if value == nil {
+			return uint64(0), nil
 		}
-		if val > math.MaxUint64 && val < 0 {
-			return nil, errors.New("value not uint64")
+		val, err := parseUnsigned(value, 64)
+		if err != nil {
+			return nil, err
 		}
 		return uint64(val), nil
 	},
@@ -618,11 +673,24 @@ var String = &Scalar{
 	},
 }
 
-// ID is the graphql ID scalar
+// ID is the graphql ID scalar, its Value is a string: like graphql-js, the ID inputs are strings or integers, and
+// the IDs are serialized as strings.
 type Id struct {
 	Value interface{}
 }
 
+// String returns the string of the ID.
+func (id Id) String() string {
+	switch value := id.Value.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
 var ID = &Scalar{
 	Name: "ID",
 	Desc: "ID",
@@ -630,24 +698,52 @@ var ID = &Scalar{
 	Serialize: func(id interface{}) (interface{}, error) {
 		switch id := id.(type) {
 		case Id:
-			return id.Value, nil
+			return serializeID(id.Value)
 		case *Id:
-			return id.Value, nil
+			if id == nil {
+				return nil, nil
+			}
+			return serializeID(id.Value)
 		default:
 			return nil, fmt.Errorf("unexpected type %v for Id", id)
 		}
 	},
 	ParseValue: func(value interface{}) (interface{}, error) {
-		switch val := value.(type) {
-		case string:
+		if val, ok := value.(string); ok {
 			return Id{Value: val}, nil
-		case float64:
-			return Id{Value: int(val)}, nil
+		}
+		i, err := integer(value)
+		if err != nil {
+			return nil, errors.New("not a ID")
+		}
+		return Id{Value: i.String()}, nil
+	},
+	ParseLiteral: func(value ast.Value) (interface{}, error) {
+		switch value := value.(type) {
+		case *ast.StringValue:
+			return Id{Value: value.Value}, nil
+		case *ast.IntValue:
+			return Id{Value: value.Value}, nil
 		}
 		return nil, errors.New("not a ID")
 	},
 }
 
+// serializeID serializes the value of an ID, a string or an integer, as a string.
+func serializeID(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+	i, err := integer(value)
+	if err != nil {
+		return nil, fmt.Errorf("ID cannot represent value: %v", value)
+	}
+	return i.String(), nil
+}
+
 type Map struct {
 	Value string
 }
@@ -1,7 +1,11 @@
 package schemabuilder
 
 import (
+	"context"
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -14,3 +18,122 @@ func NewValidate() *validator.Validate {
 	})
 	return validate
 }
+
+// InputValidationError is returned when an InputValidator rejects an input object.
+// Path is the position of the input object inside the arguments, eg. ["filter", "ranges", 1].
+type InputValidationError struct {
+	Path []interface{}
+	Err  error
+}
+
+func (e *InputValidationError) Error() string {
+	var path strings.Builder
+	for _, p := range e.Path {
+		switch p := p.(type) {
+		case int:
+			fmt.Fprintf(&path, "[%d]", p)
+		default:
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			fmt.Fprint(&path, p)
+		}
+	}
+	return fmt.Sprintf("invalid value for %q: %s", path.String(), e.Err)
+}
+
+func (e *InputValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateInput walks through the decoded arguments and runs the validators of every input object it meets,
+// children first.
+func (sb *schemaBuilder) validateInput(ctx context.Context, value reflect.Value, path []interface{}) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	// the arguments which reach no validator are not walked through
+	if len(path) == 0 && (!value.IsValid() || !sb.hasValidators(value.Type())) {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := sb.validateInput(ctx, value.Index(i), append(path[:len(path):len(path)], i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if o, ok := value.Interface().(omittable); ok {
+			return sb.validateInput(ctx, o.omittableValue(), path)
+		}
+		input, ok := sb.inputObjects[value.Type()]
+		// only the arguments struct itself and registered input objects are walked into
+		if !ok && len(path) > 0 {
+			return nil
+		}
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			skip, _, name, _ := parseFieldTag(typ.Field(i))
+			if skip {
+				continue
+			}
+			if err := sb.validateInput(ctx, value.Field(i), append(path[:len(path):len(path)], name)); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return nil
+		}
+		for _, fn := range input.validators {
+			if err := fn(ctx, value.Interface()); err != nil {
+				return &InputValidationError{Path: path, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// hasValidators reports whether the values of typ, an arguments struct, may reach an input object which has
+// validators.
+func (sb *schemaBuilder) hasValidators(typ reflect.Type) bool {
+	if validated, ok := sb.validated.Load(typ); ok {
+		return validated.(bool)
+	}
+	validated := sb.reachesValidators(typ, make(map[reflect.Type]bool))
+	sb.validated.Store(typ, validated)
+	return validated
+}
+
+func (sb *schemaBuilder) reachesValidators(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if elem, ok := omittableElem(typ); ok {
+		return sb.reachesValidators(elem, seen)
+	}
+	if typ.Kind() == reflect.Interface {
+		// the dynamic value is not known
+		return true
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return false
+	}
+	input, ok := sb.inputObjects[typ]
+	if !ok && len(seen) > 0 {
+		return false
+	}
+	seen[typ] = true
+	if ok && len(input.validators) > 0 {
+		return true
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if skip, _, _, _ := parseFieldTag(typ.Field(i)); !skip && sb.reachesValidators(typ.Field(i).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package schemadiff
+
+import "github.com/shyptr/graphql/ast"
+
+// index is a version of a schema, with its types and directives by name.
+type index struct {
+	roots map[ast.OperationType]string
+	// types are the types by name, their extensions merged, in the order of their definitions or first extensions
+	types     map[string]*typeIndex
+	typeNames []string
+	// directives are the directive definitions by name, the first one of a name wins
+	directives     map[string]*ast.DirectiveDefinition
+	directiveNames []string
+}
+
+// typeIndex is a type, along with the parts of its extensions.
+type typeIndex struct {
+	// kind is the keyword of the definition, eg. type or input
+	kind        string
+	name        string
+	interfaces  []string
+	fields      []*ast.FieldDefinition
+	inputFields []*ast.InputValueDefinition
+	values      []string
+	members     []string
+}
+
+var kindNames = map[string]string{
+	"scalar":    "a scalar",
+	"type":      "an object type",
+	"interface": "an interface",
+	"union":     "a union",
+	"enum":      "an enum",
+	"input":     "an input object type",
+}
+
+func newIndex(doc *ast.Document) *index {
+	x := &index{
+		roots:      make(map[ast.OperationType]string),
+		types:      make(map[string]*typeIndex),
+		directives: make(map[string]*ast.DirectiveDefinition),
+	}
+	schemaDefined := false
+	for _, definition := range doc.Definition {
+		switch d := definition.(type) {
+		case *ast.SchemaDefinition:
+			schemaDefined = true
+			x.operationTypes(d.OperationTypes)
+		case *ast.SchemaExtension:
+			x.operationTypes(d.RootOperation)
+		case *ast.ScalarDefinition:
+			x.typ("scalar", d.Name)
+		case *ast.ScalarExtension:
+			x.typ("scalar", d.Name)
+		case *ast.ObjectDefinition:
+			x.typ("type", d.Name).addFields(d.Interfaces, d.Fields)
+		case *ast.ObjectExtension:
+			x.typ("type", d.Name).addFields(d.Interfaces, d.Fields)
+		case *ast.InterfaceDefinition:
+			x.typ("interface", d.Name).addFields(d.Interfaces, d.Fields)
+		case *ast.InterfaceExtension:
+			x.typ("interface", d.Name).addFields(d.Interfaces, d.Fields)
+		case *ast.UnionDefinition:
+			t := x.typ("union", d.Name)
+			t.members = append(t.members, names(d.Members)...)
+		case *ast.UnionExtension:
+			t := x.typ("union", d.Name)
+			t.members = append(t.members, names(d.Members)...)
+		case *ast.EnumDefinition:
+			x.typ("enum", d.Name).addValues(d.Values)
+		case *ast.EnumExtension:
+			x.typ("enum", d.Name).addValues(d.Values)
+		case *ast.InputObjectDefinition:
+			t := x.typ("input", d.Name)
+			t.inputFields = append(t.inputFields, d.InputFields...)
+		case *ast.InputObjectExtension:
+			t := x.typ("input", d.Name)
+			t.inputFields = append(t.inputFields, d.InputFields...)
+		case *ast.DirectiveDefinition:
+			if x.directives[d.Name.Name] == nil {
+				x.directives[d.Name.Name] = d
+				x.directiveNames = append(x.directiveNames, d.Name.Name)
+			}
+		}
+	}
+	// without a schema definition, the root types are the ones of the conventional names
+	if !schemaDefined {
+		for operation, name := range map[ast.OperationType]string{ast.Query: "Query", ast.Mutation: "Mutation",
+			ast.Subscription: "Subscription"} {
+			if t := x.types[name]; t != nil && t.kind == "type" && x.roots[operation] == "" {
+				x.roots[operation] = name
+			}
+		}
+	}
+	return x
+}
+
+func (x *index) operationTypes(operationTypes []*ast.OperationTypeDefinition) {
+	for _, operationType := range operationTypes {
+		x.roots[operationType.Operation] = operationType.Type.Name.Name
+	}
+}
+
+// typ returns the type name, of kind when it is not defined yet.
+func (x *index) typ(kind string, name *ast.Name) *typeIndex {
+	if t, ok := x.types[name.Name]; ok {
+		return t
+	}
+	t := &typeIndex{kind: kind, name: name.Name}
+	x.types[name.Name] = t
+	x.typeNames = append(x.typeNames, name.Name)
+	return t
+}
+
+func (t *typeIndex) addFields(interfaces []*ast.Named, fields []*ast.FieldDefinition) {
+	t.interfaces = append(t.interfaces, names(interfaces)...)
+	t.fields = append(t.fields, fields...)
+}
+
+func (t *typeIndex) addValues(values []*ast.EnumValueDefinition) {
+	for _, value := range values {
+		t.values = append(t.values, value.Value.Value)
+	}
+}
+
+func names(named []*ast.Named) []string {
+	result := make([]string, len(named))
+	for i, n := range named {
+		result[i] = n.Name.Name
+	}
+	return result
+}
@@ -0,0 +1,404 @@
+// Package schemadiff compares two versions of the SDL of a schema and tells which of their changes break the
+// operations of the clients, eg. before a schema is deployed:
+//
+//	changes, err := schemadiff.DiffSDL(published, proposed)
+//	if breaking := schemadiff.BreakingChanges(changes); len(breaking) > 0 {
+//		log.Fatal(breaking)
+//	}
+//
+// The criticalities of the changes are the ones of findBreakingChanges and findDangerousChanges of graphql-js.
+package schemadiff
+
+import (
+	"fmt"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Criticality tells whether a change breaks the operations of the clients.
+type Criticality int
+
+const (
+	// Safe is the criticality of the changes which the clients do not notice, eg. an added field.
+	Safe Criticality = iota
+	// Dangerous is the criticality of the changes which may change the results of the operations, eg. an enum
+	// value added, which the clients may not handle.
+	Dangerous
+	// Breaking is the criticality of the changes which fail some operations, eg. a removed field.
+	Breaking
+)
+
+var criticalityNames = []string{Safe: "safe", Dangerous: "dangerous", Breaking: "breaking"}
+
+func (c Criticality) String() string {
+	if c < 0 || int(c) >= len(criticalityNames) {
+		return fmt.Sprintf("Criticality(%d)", int(c))
+	}
+	return criticalityNames[c]
+}
+
+func (c Criticality) MarshalText() ([]byte, error) {
+	if c < 0 || int(c) >= len(criticalityNames) {
+		return nil, fmt.Errorf("invalid criticality %d", int(c))
+	}
+	return []byte(criticalityNames[c]), nil
+}
+
+func (c *Criticality) UnmarshalText(text []byte) error {
+	for criticality, name := range criticalityNames {
+		if string(text) == name {
+			*c = Criticality(criticality)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown criticality %q", text)
+}
+
+// Change is a difference between two versions of a schema.
+type Change struct {
+	Criticality Criticality `json:"criticality"`
+	// Path is the schema coordinate of what changed, eg. User, User.name, User.posts(first:) or @auth.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (c *Change) String() string {
+	return c.Criticality.String() + ": " + c.Message
+}
+
+// BreakingChanges returns the changes which are Breaking.
+func BreakingChanges(changes []*Change) []*Change {
+	var breaking []*Change
+	for _, change := range changes {
+		if change.Criticality == Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// DiffSDL parses the SDLs of two versions of a schema and returns their changes, see Diff. The syntax errors are
+// returned as a MultiError.
+func DiffSDL(oldSDL, newSDL string) ([]*Change, error) {
+	oldDoc, errs := internal.ParseDocument(oldSDL)
+	if errs != nil {
+		return nil, errs
+	}
+	newDoc, errs := internal.ParseDocument(newSDL)
+	if errs != nil {
+		return nil, errs
+	}
+	return Diff(oldDoc, newDoc), nil
+}
+
+// Diff returns the changes from oldDoc to newDoc, type system documents defining two versions of a schema: the
+// changes of the root types, then of the types and of the directives, in the order of the definitions of oldDoc
+// then of the ones added by newDoc. The extensions of the documents are merged
+// into the types they extend, the documents are expected to be valid, see validation.ValidateSDL.
+func Diff(oldDoc, newDoc *ast.Document) []*Change {
+	d := &differ{}
+	d.diff(newIndex(oldDoc), newIndex(newDoc))
+	return d.changes
+}
+
+type differ struct {
+	changes []*Change
+}
+
+func (d *differ) add(criticality Criticality, path string, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{Criticality: criticality, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) diff(oldSchema, newSchema *index) {
+	for _, operation := range []ast.OperationType{ast.Query, ast.Mutation, ast.Subscription} {
+		oldRoot, newRoot := oldSchema.roots[operation], newSchema.roots[operation]
+		switch {
+		case oldRoot == newRoot:
+		case newRoot == "":
+			d.add(Breaking, oldRoot, "The %s root type %s was removed.", operationName(operation), oldRoot)
+		case oldRoot == "":
+			d.add(Safe, newRoot, "The %s root type %s was added.", operationName(operation), newRoot)
+		default:
+			d.add(Breaking, newRoot, "The %s root type changed from %s to %s.", operationName(operation), oldRoot,
+				newRoot)
+		}
+	}
+
+	for _, name := range oldSchema.typeNames {
+		oldType, newType := oldSchema.types[name], newSchema.types[name]
+		switch {
+		case newType == nil:
+			d.add(Breaking, name, "Type %s was removed.", name)
+		case oldType.kind != newType.kind:
+			d.add(Breaking, name, "%s changed from %s to %s.", name, kindNames[oldType.kind], kindNames[newType.kind])
+		default:
+			d.diffType(oldType, newType)
+		}
+	}
+	for _, name := range newSchema.typeNames {
+		if oldSchema.types[name] == nil {
+			d.add(Safe, name, "Type %s was added.", name)
+		}
+	}
+
+	for _, name := range oldSchema.directiveNames {
+		oldDirective, newDirective := oldSchema.directives[name], newSchema.directives[name]
+		if newDirective == nil {
+			d.add(Breaking, "@"+name, "Directive @%s was removed.", name)
+			continue
+		}
+		d.diffDirective(oldDirective, newDirective)
+	}
+	for _, name := range newSchema.directiveNames {
+		if oldSchema.directives[name] == nil {
+			d.add(Safe, "@"+name, "Directive @%s was added.", name)
+		}
+	}
+}
+
+func (d *differ) diffType(oldType, newType *typeIndex) {
+	name := oldType.name
+	for _, iface := range oldType.interfaces {
+		if !contains(newType.interfaces, iface) {
+			d.add(Breaking, name, "%s no longer implements the interface %s.", name, iface)
+		}
+	}
+	for _, iface := range newType.interfaces {
+		if !contains(oldType.interfaces, iface) {
+			d.add(Dangerous, name, "%s implements the interface %s.", name, iface)
+		}
+	}
+
+	for _, oldField := range oldType.fields {
+		path := name + "." + oldField.Name.Name
+		newField := findField(newType.fields, oldField.Name.Name)
+		if newField == nil {
+			d.add(Breaking, path, "Field %s was removed.", path)
+			continue
+		}
+		criticality := Breaking
+		if safeOutputChange(oldField.Type, newField.Type) {
+			criticality = Safe
+		}
+		d.typeChange(criticality, path, "Field", oldField.Type, newField.Type)
+		if !deprecated(oldField.Directives) && deprecated(newField.Directives) {
+			d.add(Safe, path, "Field %s was deprecated.", path)
+		}
+		d.diffArguments(path, oldField.Argument, newField.Argument)
+	}
+	for _, newField := range newType.fields {
+		if findField(oldType.fields, newField.Name.Name) == nil {
+			d.add(Safe, name+"."+newField.Name.Name, "Field %s.%s was added.", name, newField.Name.Name)
+		}
+	}
+
+	for _, oldField := range oldType.inputFields {
+		path := name + "." + oldField.Name.Name
+		newField := findInputValue(newType.inputFields, oldField.Name.Name)
+		if newField == nil {
+			d.add(Breaking, path, "Input field %s was removed.", path)
+			continue
+		}
+		d.diffInputValue("Input field", path, oldField, newField)
+	}
+	for _, newField := range newType.inputFields {
+		if findInputValue(oldType.inputFields, newField.Name.Name) == nil {
+			path := name + "." + newField.Name.Name
+			if required(newField) {
+				d.add(Breaking, path, "Required input field %s was added.", path)
+			} else {
+				d.add(Dangerous, path, "Input field %s was added.", path)
+			}
+		}
+	}
+
+	for _, value := range oldType.values {
+		if !contains(newType.values, value) {
+			d.add(Breaking, name+"."+value, "Enum value %s.%s was removed.", name, value)
+		}
+	}
+	for _, value := range newType.values {
+		if !contains(oldType.values, value) {
+			d.add(Dangerous, name+"."+value, "Enum value %s.%s was added.", name, value)
+		}
+	}
+
+	for _, member := range oldType.members {
+		if !contains(newType.members, member) {
+			d.add(Breaking, name, "%s was removed from the union %s.", member, name)
+		}
+	}
+	for _, member := range newType.members {
+		if !contains(oldType.members, member) {
+			d.add(Dangerous, name, "%s was added to the union %s.", member, name)
+		}
+	}
+}
+
+// diffArguments compares the arguments of the field or directive of path.
+func (d *differ) diffArguments(path string, oldArgs, newArgs []*ast.InputValueDefinition) {
+	for _, oldArg := range oldArgs {
+		argPath := path + "(" + oldArg.Name.Name + ":)"
+		newArg := findInputValue(newArgs, oldArg.Name.Name)
+		if newArg == nil {
+			d.add(Breaking, argPath, "Argument %s was removed.", argPath)
+			continue
+		}
+		d.diffInputValue("Argument", argPath, oldArg, newArg)
+	}
+	for _, newArg := range newArgs {
+		if findInputValue(oldArgs, newArg.Name.Name) == nil {
+			argPath := path + "(" + newArg.Name.Name + ":)"
+			if required(newArg) {
+				d.add(Breaking, argPath, "Required argument %s was added.", argPath)
+			} else {
+				d.add(Dangerous, argPath, "Argument %s was added.", argPath)
+			}
+		}
+	}
+}
+
+// diffInputValue compares an argument or an input field, of what.
+func (d *differ) diffInputValue(what, path string, oldValue, newValue *ast.InputValueDefinition) {
+	criticality := Breaking
+	if safeInputChange(oldValue.Type, newValue.Type) {
+		criticality = Safe
+	}
+	d.typeChange(criticality, path, what, oldValue.Type, newValue.Type)
+	oldDefault, newDefault := printValue(oldValue.DefaultValue), printValue(newValue.DefaultValue)
+	switch {
+	case oldDefault == newDefault:
+	case newDefault == "":
+		d.add(Dangerous, path, "The default value %s of %s was removed.", oldDefault, path)
+	case oldDefault == "":
+		d.add(Safe, path, "%s %s has the default value %s.", what, path, newDefault)
+	default:
+		d.add(Dangerous, path, "The default value of %s changed from %s to %s.", path, oldDefault, newDefault)
+	}
+}
+
+func (d *differ) typeChange(criticality Criticality, path, what string, oldType, newType ast.Type) {
+	if oldPrinted, newPrinted := ast.Print(oldType), ast.Print(newType); oldPrinted != newPrinted {
+		d.add(criticality, path, "%s %s changed type from %s to %s.", what, path, oldPrinted, newPrinted)
+	}
+}
+
+func (d *differ) diffDirective(oldDirective, newDirective *ast.DirectiveDefinition) {
+	path := "@" + oldDirective.Name.Name
+	if oldDirective.Repeatable && !newDirective.Repeatable {
+		d.add(Breaking, path, "Directive %s is no longer repeatable.", path)
+	}
+	for _, location := range oldDirective.Locations {
+		if !contains(newDirective.Locations, location) {
+			d.add(Breaking, path, "The location %s of the directive %s was removed.", location, path)
+		}
+	}
+	for _, location := range newDirective.Locations {
+		if !contains(oldDirective.Locations, location) {
+			d.add(Safe, path, "The location %s of the directive %s was added.", location, path)
+		}
+	}
+	d.diffArguments(path, oldDirective.Arguments, newDirective.Arguments)
+}
+
+// safeOutputChange tells whether the type of a field changed from oldType to newType without breaking the
+// operations, which is when the new type is the old one or one of its non-null versions.
+func safeOutputChange(oldType, newType ast.Type) bool {
+	switch oldType := oldType.(type) {
+	case *ast.List:
+		switch newType := newType.(type) {
+		case *ast.List:
+			return safeOutputChange(oldType.Type, newType.Type)
+		case *ast.NonNull:
+			return safeOutputChange(oldType, newType.Type)
+		}
+	case *ast.NonNull:
+		if newType, ok := newType.(*ast.NonNull); ok {
+			return safeOutputChange(oldType.Type, newType.Type)
+		}
+	case *ast.Named:
+		switch newType := newType.(type) {
+		case *ast.Named:
+			return oldType.Name.Name == newType.Name.Name
+		case *ast.NonNull:
+			return safeOutputChange(oldType, newType.Type)
+		}
+	}
+	return false
+}
+
+// safeInputChange tells whether the type of an argument or an input field changed from oldType to newType without
+// breaking the operations, which is when the new type accepts every value of the old one, eg. a nullable version
+// of it.
+func safeInputChange(oldType, newType ast.Type) bool {
+	switch oldType := oldType.(type) {
+	case *ast.List:
+		if newType, ok := newType.(*ast.List); ok {
+			return safeInputChange(oldType.Type, newType.Type)
+		}
+	case *ast.NonNull:
+		if newType, ok := newType.(*ast.NonNull); ok {
+			return safeInputChange(oldType.Type, newType.Type)
+		}
+		return safeInputChange(oldType.Type, newType)
+	case *ast.Named:
+		if newType, ok := newType.(*ast.Named); ok {
+			return oldType.Name.Name == newType.Name.Name
+		}
+	}
+	return false
+}
+
+func required(value *ast.InputValueDefinition) bool {
+	_, nonNull := value.Type.(*ast.NonNull)
+	return nonNull && value.DefaultValue == nil
+}
+
+func deprecated(directives []*ast.Directive) bool {
+	for _, directive := range directives {
+		if directive.Name.Name == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+func printValue(value ast.Value) string {
+	if value == nil {
+		return ""
+	}
+	return ast.Print(value)
+}
+
+func operationName(operation ast.OperationType) string {
+	return map[ast.OperationType]string{ast.Query: "query", ast.Mutation: "mutation",
+		ast.Subscription: "subscription"}[operation]
+}
+
+func findField(fields []*ast.FieldDefinition, name string) *ast.FieldDefinition {
+	for _, field := range fields {
+		if field.Name.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func findInputValue(values []*ast.InputValueDefinition, name string) *ast.InputValueDefinition {
+	for _, value := range values {
+		if value.Name.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package schemadiff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shyptr/graphql/schemadiff"
+	"github.com/stretchr/testify/assert"
+)
+
+func diff(t *testing.T, oldSDL, newSDL string) []string {
+	changes, err := schemadiff.DiffSDL(oldSDL, newSDL)
+	assert.NoError(t, err)
+	var printed []string
+	for _, change := range changes {
+		printed = append(printed, change.String())
+	}
+	return printed
+}
+
+func TestDiff(t *testing.T) {
+	oldSDL := `
+type Query {
+  user(id: ID!): User
+  users(first: Int = 10): [User]
+  node(id: ID!): Node
+}
+interface Node { id: ID! }
+type User implements Node {
+  id: ID!
+  name: String
+  email: String!
+  role: Role
+}
+enum Role { ADMIN EDITOR }
+union Result = User
+input UserFilter { name: String, role: Role! }
+scalar Time
+directive @auth(role: Role) on FIELD_DEFINITION | OBJECT
+`
+	newSDL := `
+type Query {
+  user(id: ID): User!
+  users(first: Int = 20, after: String, filter: UserFilter!): [User]
+  node(id: ID!): Node
+}
+interface Node { id: ID! }
+type User {
+  id: ID!
+  name: String! @deprecated
+  email: String
+  role: Role
+  posts: [Post]
+}
+type Post { title: String }
+enum Role { ADMIN VIEWER }
+union Result = Post
+input UserFilter { name: String!, role: Role, age: Int }
+extend input UserFilter { team: ID! }
+type Time { value: String }
+directive @auth(role: Role, scope: String!) on FIELD_DEFINITION
+`
+	assert.Equal(t, []string{
+		"safe: Field Query.user changed type from User to User!.",
+		"safe: Argument Query.user(id:) changed type from ID! to ID.",
+		"dangerous: The default value of Query.users(first:) changed from 10 to 20.",
+		"dangerous: Argument Query.users(after:) was added.",
+		"breaking: Required argument Query.users(filter:) was added.",
+		"breaking: User no longer implements the interface Node.",
+		"safe: Field User.name changed type from String to String!.",
+		"safe: Field User.name was deprecated.",
+		"breaking: Field User.email changed type from String! to String.",
+		"safe: Field User.posts was added.",
+		"breaking: Enum value Role.EDITOR was removed.",
+		"dangerous: Enum value Role.VIEWER was added.",
+		"breaking: User was removed from the union Result.",
+		"dangerous: Post was added to the union Result.",
+		"breaking: Input field UserFilter.name changed type from String to String!.",
+		"safe: Input field UserFilter.role changed type from Role! to Role.",
+		"dangerous: Input field UserFilter.age was added.",
+		"breaking: Required input field UserFilter.team was added.",
+		"breaking: Time changed from a scalar to an object type.",
+		"safe: Type Post was added.",
+		"breaking: The location OBJECT of the directive @auth was removed.",
+		"breaking: Required argument @auth(scope:) was added.",
+	}, diff(t, oldSDL, newSDL))
+
+	assert.Empty(t, diff(t, oldSDL, oldSDL))
+}
+
+func TestDiff_Roots(t *testing.T) {
+	assert.Equal(t, []string{
+		"breaking: The mutation root type Mutation was removed.",
+		"safe: The subscription root type Subscription was added.",
+		"breaking: Type Mutation was removed.",
+		"safe: Type Subscription was added.",
+		"breaking: Directive @cache was removed.",
+	}, diff(t, "type Query { a: Int } type Mutation { b: Int } directive @cache on FIELD",
+		"type Query { a: Int } type Subscription { c: Int }"))
+
+	assert.Equal(t, []string{
+		"breaking: The query root type changed from Query to Root.",
+		"safe: Type Root was added.",
+		"safe: Directive @cache was added.",
+	}, diff(t, "type Query { a: Int }",
+		"schema { query: Root } type Query { a: Int } type Root { a: Int } directive @cache on FIELD"))
+}
+
+func TestBreakingChanges(t *testing.T) {
+	changes, err := schemadiff.DiffSDL("type Query { a: Int b: Int }", "type Query { a: Int! c: Int }")
+	assert.NoError(t, err)
+	breaking := schemadiff.BreakingChanges(changes)
+	b, err := json.Marshal(breaking)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"criticality": "breaking", "path": "Query.b", "message": "Field Query.b was removed."}]`,
+		string(b))
+
+	_, err = schemadiff.DiffSDL("type Query {", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package schemadoc
+
+import (
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// example returns an example operation of the root field: its required arguments have example values and the leaf
+// fields of its type are selected, the __typename of the unions.
+func example(operation string, field *internal.Field) string {
+	var b strings.Builder
+	b.WriteString(operation + " {\n  " + field.Name)
+	var args []string
+	for _, name := range sortedKeys(field.Args) {
+		if arg := field.Args[name]; required(arg) {
+			args = append(args, name+": "+exampleValue(arg.Type, 0))
+		}
+	}
+	if len(args) > 0 {
+		b.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	if selection := exampleSelection(namedType(field.Type)); selection != nil {
+		b.WriteString(" {\n    " + strings.Join(selection, "\n    ") + "\n  }")
+	}
+	b.WriteString("\n}")
+	return b.String()
+}
+
+// exampleSelection returns the leaf fields of typ without required arguments, nil when typ is a leaf type.
+func exampleSelection(typ internal.Type) []string {
+	var fields map[string]*internal.Field
+	switch typ := typ.(type) {
+	case *internal.Object:
+		fields = typ.Fields
+	case *internal.Interface:
+		fields = typ.Fields
+	case *internal.Union:
+		return []string{"__typename"}
+	default:
+		return nil
+	}
+	var selection []string
+	for _, name := range sortedKeys(fields) {
+		field := fields[name]
+		if strings.HasPrefix(name, "__") || field.DeprecationReason != "" {
+			continue
+		}
+		if leaf(field.Type) && !hasRequiredArgs(field) {
+			selection = append(selection, name)
+		}
+	}
+	if len(selection) == 0 {
+		return []string{"__typename"}
+	}
+	return selection
+}
+
+// exampleValue returns an example literal of typ, the input objects of which are nested depth times.
+func exampleValue(typ internal.Type, depth int) string {
+	switch typ := typ.(type) {
+	case *internal.NonNull:
+		return exampleValue(typ.Type, depth)
+	case *internal.List:
+		return "[" + exampleValue(typ.Type, depth) + "]"
+	case *internal.Enum:
+		if len(typ.Values) > 0 {
+			return typ.Values[0]
+		}
+	case *internal.InputObject:
+		if depth > 3 {
+			return "{}"
+		}
+		var fields []string
+		for _, name := range sortedKeys(typ.Fields) {
+			// a oneOf input object has exactly one field
+			if field := typ.Fields[name]; required(field) || typ.OneOf && len(fields) == 0 {
+				fields = append(fields, name+": "+exampleValue(field.Type, depth+1))
+			}
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case *internal.Scalar:
+		switch typ.Name {
+		case "Int":
+			return "10"
+		case "Float":
+			return "1.5"
+		case "Boolean":
+			return "true"
+		case "ID":
+			return `"1"`
+		}
+		return `"` + strings.ToLower(typ.Name) + `"`
+	}
+	return "null"
+}
+
+func leaf(typ internal.Type) bool {
+	switch namedType(typ).(type) {
+	case *internal.Scalar, *internal.Enum:
+		return true
+	}
+	return false
+}
+
+func required(value *internal.InputField) bool {
+	_, nonNull := value.Type.(*internal.NonNull)
+	return nonNull && value.DefaultValue == nil
+}
+
+func hasRequiredArgs(field *internal.Field) bool {
+	for _, arg := range field.Args {
+		if required(arg) {
+			return true
+		}
+	}
+	return false
+}
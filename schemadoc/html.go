@@ -0,0 +1,127 @@
+package schemadoc
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// HTML returns the documentation of schema as a standalone HTML page, the types of which are linked to their
+// documentation.
+func HTML(schema *internal.Schema, opts Options) string {
+	var b strings.Builder
+	// the template only fails on the writes of b, which do not fail
+	_ = htmlTemplate.Execute(&b, newPage(schema, opts))
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"kind":   func(kind string) string { return strings.ToUpper(kind[:1]) + kind[1:] },
+	"anchor": anchor,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 0 auto; padding: 1rem; line-height: 1.5; }
+section { border-top: 1px solid #ddd; }
+code, pre { font-family: ui-monospace, monospace; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; }
+.kind { color: #666; font-style: italic; }
+.deprecated { color: #b35900; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- define "type"}}{{if .Anchor}}<a href="#{{.Anchor}}"><code>{{.Type}}</code></a>{{else}}<code>{{.Type}}</code>{{end}}{{end}}
+{{- define "types"}}{{range $i, $ref := .}}{{if $i}}, {{end}}{{template "type" $ref}}{{end}}{{end}}
+{{- define "deprecation"}}{{if .}} <span class="deprecated">Deprecated: {{.}}</span>{{end}}{{end}}
+{{- define "directives"}}{{range .}} <code>{{.}}</code>{{end}}{{end}}
+{{- define "arguments"}}
+<ul>
+{{- range .}}
+<li><code>{{.Name}}</code>: {{template "type" .Type}}{{if .DefaultValue}} = <code>{{.DefaultValue}}</code>{{end}}{{if .Description}} — {{.Description}}{{end}}{{template "deprecation" .Deprecation}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- range .Operations}}
+<h2>{{.Title}}</h2>
+{{- $type := .Type}}
+{{- range .Fields}}
+<section id="{{$type}}.{{.Name}}">
+<h3>{{$type}}.{{.Name}}</h3>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Deprecation}}
+<p class="deprecated">Deprecated: {{.Deprecation}}</p>
+{{- end}}
+<p>Returns {{template "type" .Type}}.</p>
+{{- if .Arguments}}
+<p>Arguments:</p>
+{{- template "arguments" .Arguments}}
+{{- end}}
+<pre><code class="language-graphql">{{.Example}}</code></pre>
+</section>
+{{- end}}
+{{- end}}
+{{- if .Types}}
+<h2>Types</h2>
+{{- end}}
+{{- range .Types}}
+<section id="{{anchor .Name}}">
+<h3>{{.Name}}</h3>
+<p><span class="kind">{{kind .Kind}}</span>{{template "directives" .Directives}}</p>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Interfaces}}
+<p>Implements {{template "types" .Interfaces}}.</p>
+{{- end}}
+{{- if .PossibleTypes}}
+<p>Possible types: {{template "types" .PossibleTypes}}.</p>
+{{- end}}
+{{- if .Fields}}
+<p>Fields:</p>
+<ul>
+{{- range .Fields}}
+<li><code>{{.Name}}</code>: {{template "type" .Type}}{{template "directives" .Directives}}{{if .Description}} — {{.Description}}{{end}}{{template "deprecation" .Deprecation}}
+{{- if .Arguments}}{{template "arguments" .Arguments}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .InputFields}}
+<p>Fields:</p>
+{{- template "arguments" .InputFields}}
+{{- end}}
+{{- if .Values}}
+<p>Values:</p>
+<ul>
+{{- range .Values}}
+<li><code>{{.Name}}</code>{{if .Description}} — {{.Description}}{{end}}{{template "deprecation" .Deprecation}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+{{- if .Directives}}
+<h2>Directives</h2>
+{{- end}}
+{{- range .Directives}}
+<section id="@{{.Name}}">
+<h3>@{{.Name}}</h3>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<p>Locations: {{range $i, $location := .Locations}}{{if $i}}, {{end}}{{$location}}{{end}}.</p>
+{{- if .Arguments}}
+<p>Arguments:</p>
+{{- template "arguments" .Arguments}}
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
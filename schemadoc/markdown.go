@@ -0,0 +1,155 @@
+package schemadoc
+
+import (
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+)
+
+// Markdown returns the documentation of schema as a Markdown page, the types of which are linked to the headings of
+// their documentation.
+func Markdown(schema *internal.Schema, opts Options) string {
+	p := newPage(schema, opts)
+	m := &markdown{}
+	m.line("# " + p.Title)
+
+	for _, operation := range p.Operations {
+		m.line("## " + operation.Title)
+		for _, field := range operation.Fields {
+			m.line("### " + operation.Type + "." + field.Name)
+			m.paragraph(field.Description)
+			m.deprecation(field.Deprecation)
+			m.paragraph("Returns " + m.typeRef(field.Type) + ".")
+			m.arguments("Arguments", field.Arguments)
+			m.line("```graphql\n" + field.Example + "\n```")
+		}
+	}
+
+	if len(p.Types) > 0 {
+		m.line("## Types")
+	}
+	for _, typ := range p.Types {
+		m.line("### " + typ.Name)
+		m.paragraph("*" + strings.ToUpper(typ.Kind[:1]) + typ.Kind[1:] + "*" + m.directives(typ.Directives))
+		m.paragraph(typ.Description)
+		if len(typ.Interfaces) > 0 {
+			m.paragraph("Implements " + m.typeRefs(typ.Interfaces) + ".")
+		}
+		if len(typ.PossibleTypes) > 0 {
+			m.paragraph("Possible types: " + m.typeRefs(typ.PossibleTypes) + ".")
+		}
+		if len(typ.Fields) > 0 {
+			var items []string
+			for _, field := range typ.Fields {
+				item := "- `" + field.Name + "`: " + m.typeRef(field.Type) + m.directives(field.Directives) +
+					m.description(field.Description) + m.deprecated(field.Deprecation)
+				for _, arg := range field.Arguments {
+					item += "\n  " + m.argument(arg)
+				}
+				items = append(items, item)
+			}
+			m.line("Fields:\n\n" + strings.Join(items, "\n"))
+		}
+		m.arguments("Fields", typ.InputFields)
+		if len(typ.Values) > 0 {
+			var items []string
+			for _, value := range typ.Values {
+				items = append(items, "- `"+value.Name+"`"+m.description(value.Description)+
+					m.deprecated(value.Deprecation))
+			}
+			m.line("Values:\n\n" + strings.Join(items, "\n"))
+		}
+	}
+
+	if len(p.Directives) > 0 {
+		m.line("## Directives")
+	}
+	for _, d := range p.Directives {
+		m.line("### @" + d.Name)
+		m.paragraph(d.Description)
+		m.paragraph("Locations: " + strings.Join(d.Locations, ", ") + ".")
+		m.arguments("Arguments", d.Arguments)
+	}
+	return m.String()
+}
+
+type markdown struct {
+	strings.Builder
+}
+
+// line writes a block of the page, separated from the previous one by a blank line.
+func (m *markdown) line(s string) {
+	if m.Len() > 0 {
+		m.WriteString("\n")
+	}
+	m.WriteString(s + "\n")
+}
+
+func (m *markdown) paragraph(s string) {
+	if s != "" {
+		m.line(s)
+	}
+}
+
+func (m *markdown) deprecation(reason string) {
+	if reason != "" {
+		m.line("**Deprecated:** " + reason)
+	}
+}
+
+func (m *markdown) arguments(title string, args []*argumentDoc) {
+	if len(args) == 0 {
+		return
+	}
+	items := make([]string, len(args))
+	for i, arg := range args {
+		items[i] = m.argument(arg)
+	}
+	m.line(title + ":\n\n" + strings.Join(items, "\n"))
+}
+
+func (m *markdown) argument(arg *argumentDoc) string {
+	item := "- `" + arg.Name + "`: " + m.typeRef(arg.Type)
+	if arg.DefaultValue != "" {
+		item += " = `" + arg.DefaultValue + "`"
+	}
+	return item + m.description(arg.Description) + m.deprecated(arg.Deprecation)
+}
+
+func (m *markdown) description(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	// the description is kept in the item of its list
+	return " — " + strings.ReplaceAll(desc, "\n", " ")
+}
+
+func (m *markdown) deprecated(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " **Deprecated:** " + reason
+}
+
+func (m *markdown) directives(usages []string) string {
+	var s string
+	for _, usage := range usages {
+		s += " `" + usage + "`"
+	}
+	return s
+}
+
+func (m *markdown) typeRef(ref typeRef) string {
+	if ref.Anchor == "" {
+		return "`" + ref.Type + "`"
+	}
+	return "[`" + ref.Type + "`](#" + ref.Anchor + ")"
+}
+
+func (m *markdown) typeRefs(refs []typeRef) string {
+	links := make([]string, len(refs))
+	for i, ref := range refs {
+		links[i] = m.typeRef(ref)
+	}
+	return strings.Join(links, ", ")
+}
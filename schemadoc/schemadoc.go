@@ -0,0 +1,293 @@
+// Package schemadoc generates the reference documentation of a schema, as a Markdown or an HTML page, so that the
+// documentation of an API is published from the code of its server:
+//
+//	schema := build.MustBuild()
+//	page := schemadoc.HTML(schema, schemadoc.Options{Title: "Billing API"})
+//
+// The page documents the operations, with an example of each, then the types with their fields, arguments,
+// deprecations and the directives applied to them, then the directives of the schema.
+package schemadoc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+)
+
+// Options configure the documentation of a schema.
+type Options struct {
+	// Title is the title of the page, "API reference" by default.
+	Title string
+	// ExtensionsDirective documents the Extensions of the objects and of the fields as the usages of a directive
+	// named so, see introspection.PrintExtensions. The extensions are not documented when it is empty.
+	ExtensionsDirective string
+	// Examples are the example operations of the root fields by schema coordinate, eg. "Query.user", which
+	// replace the generated ones.
+	Examples map[string]string
+}
+
+// page is the documentation of a schema, which the formats render.
+type page struct {
+	Title      string
+	Operations []*operationDoc
+	Types      []*typeDoc
+	Directives []*directiveDoc
+}
+
+// operationDoc documents the fields of a root type.
+type operationDoc struct {
+	// Operation is the keyword of the operations, eg. query
+	Operation string
+	Title     string
+	Type      string
+	Fields    []*fieldDoc
+}
+
+type typeDoc struct {
+	Name string
+	// Kind is the kind of the type in words, eg. object type
+	Kind        string
+	Description string
+	// Directives are the usages of the directives applied to the type, eg. @oneOf
+	Directives []string
+	Interfaces []typeRef
+	// PossibleTypes are the members of a union or the implementations of an interface
+	PossibleTypes []typeRef
+	Fields        []*fieldDoc
+	InputFields   []*argumentDoc
+	Values        []*valueDoc
+}
+
+type fieldDoc struct {
+	Name        string
+	Type        typeRef
+	Description string
+	Deprecation string
+	Directives  []string
+	Arguments   []*argumentDoc
+	// Example is an example operation of a root field
+	Example string
+}
+
+type argumentDoc struct {
+	Name         string
+	Type         typeRef
+	Description  string
+	DefaultValue string
+	Deprecation  string
+}
+
+type valueDoc struct {
+	Name        string
+	Description string
+	Deprecation string
+}
+
+type directiveDoc struct {
+	Name        string
+	Description string
+	Locations   []string
+	Arguments   []*argumentDoc
+}
+
+// typeRef is the type of a field or of an argument, eg. [User!]!, with the name of the type it wraps, which is
+// empty when the type is not documented, eg. String.
+type typeRef struct {
+	Type   string
+	Anchor string
+}
+
+var specifiedScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+var specifiedDirectives = map[string]bool{"include": true, "skip": true, "deprecated": true, "specifiedBy": true,
+	"oneOf": true}
+
+// newPage returns the documentation of schema. The built-in scalars and directives and the introspection types
+// are not documented.
+func newPage(schema *internal.Schema, opts Options) *page {
+	p := &page{Title: opts.Title}
+	if p.Title == "" {
+		p.Title = "API reference"
+	}
+	documented := func(name string) bool {
+		return !strings.HasPrefix(name, "__") && !specifiedScalars[name]
+	}
+	ref := func(typ internal.Type) typeRef {
+		name := namedType(typ).String()
+		if !documented(name) {
+			return typeRef{Type: typ.String()}
+		}
+		return typeRef{Type: typ.String(), Anchor: anchor(name)}
+	}
+	b := &builder{opts: opts, ref: ref}
+
+	for _, root := range []struct {
+		operation, title string
+		typ              internal.Type
+	}{
+		{"query", "Queries", schema.Query},
+		{"mutation", "Mutations", schema.Mutation},
+		{"subscription", "Subscriptions", schema.Subscription},
+	} {
+		object, ok := root.typ.(*internal.Object)
+		if !ok {
+			continue
+		}
+		fields := b.fields(object.Fields)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields {
+			field.Example = opts.Examples[object.Name+"."+field.Name]
+			if field.Example == "" {
+				field.Example = example(root.operation, object.Fields[field.Name])
+			}
+		}
+		p.Operations = append(p.Operations, &operationDoc{Operation: root.operation, Title: root.title,
+			Type: object.Name, Fields: fields})
+	}
+
+	for _, name := range sortedKeys(schema.TypeMap) {
+		if documented(name) {
+			p.Types = append(p.Types, b.typ(schema.TypeMap[name]))
+		}
+	}
+
+	for _, name := range sortedKeys(schema.Directives) {
+		d := schema.Directives[name]
+		if specifiedDirectives[name] {
+			continue
+		}
+		p.Directives = append(p.Directives, &directiveDoc{
+			Name:        d.Name,
+			Description: d.Desc,
+			Locations:   d.Locs,
+			Arguments:   b.arguments(d.Args),
+		})
+	}
+	return p
+}
+
+type builder struct {
+	opts Options
+	ref  func(typ internal.Type) typeRef
+}
+
+func (b *builder) typ(typ internal.NamedType) *typeDoc {
+	doc := &typeDoc{Name: typ.String(), Description: typ.Description()}
+	switch typ := typ.(type) {
+	case *internal.Scalar:
+		doc.Kind = "scalar"
+	case *internal.Object:
+		doc.Kind = "object type"
+		doc.Interfaces = refs(typ.Interfaces, b.ref)
+		doc.Fields = b.fields(typ.Fields)
+		doc.Directives = b.extensions(typ.Extensions)
+	case *internal.Interface:
+		doc.Kind = "interface"
+		doc.Interfaces = refs(typ.Interfaces, b.ref)
+		doc.PossibleTypes = refs(typ.PossibleTypes, b.ref)
+		doc.Fields = b.fields(typ.Fields)
+	case *internal.Union:
+		doc.Kind = "union"
+		doc.PossibleTypes = refs(typ.Types, b.ref)
+	case *internal.Enum:
+		doc.Kind = "enum"
+		values := append([]string(nil), typ.Values...)
+		sort.Strings(values)
+		for _, value := range values {
+			doc.Values = append(doc.Values, &valueDoc{
+				Name:        value,
+				Description: typ.ValuesDesc[value],
+				Deprecation: typ.ValuesDeprecation[value],
+			})
+		}
+	case *internal.InputObject:
+		doc.Kind = "input object type"
+		doc.InputFields = b.arguments(typ.Fields)
+		if typ.OneOf {
+			doc.Directives = []string{"@oneOf"}
+		}
+	}
+	return doc
+}
+
+// fields documents fields, the meta-fields of the query type excepted.
+func (b *builder) fields(fields map[string]*internal.Field) []*fieldDoc {
+	var docs []*fieldDoc
+	for _, name := range sortedKeys(fields) {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		field := fields[name]
+		docs = append(docs, &fieldDoc{
+			Name:        name,
+			Type:        b.ref(field.Type),
+			Description: field.Desc,
+			Deprecation: field.DeprecationReason,
+			Directives:  b.extensions(field.Extensions),
+			Arguments:   b.arguments(field.Args),
+		})
+	}
+	return docs
+}
+
+func (b *builder) arguments(args map[string]*internal.InputField) []*argumentDoc {
+	var docs []*argumentDoc
+	for _, name := range sortedKeys(args) {
+		arg := args[name]
+		docs = append(docs, &argumentDoc{
+			Name:         name,
+			Type:         b.ref(arg.Type),
+			Description:  arg.Desc,
+			DefaultValue: introspection.PrintDefaultValue(arg),
+			Deprecation:  arg.DeprecationReason,
+		})
+	}
+	return docs
+}
+
+// extensions returns the usage of Options.ExtensionsDirective for extensions, none without extensions.
+func (b *builder) extensions(extensions map[string]interface{}) []string {
+	if b.opts.ExtensionsDirective == "" || len(extensions) == 0 {
+		return nil
+	}
+	return []string{introspection.PrintExtensionsDirective(extensions, b.opts.ExtensionsDirective)}
+}
+
+func refs[T internal.Type](types map[string]T, ref func(typ internal.Type) typeRef) []typeRef {
+	result := make([]typeRef, 0, len(types))
+	for _, name := range sortedKeys(types) {
+		result = append(result, ref(types[name]))
+	}
+	return result
+}
+
+// anchor returns the anchor of the documentation of the type name, the one of the heading of the type in Markdown.
+func anchor(name string) string {
+	return strings.ToLower(name)
+}
+
+func namedType(typ internal.Type) internal.Type {
+	for {
+		switch t := typ.(type) {
+		case *internal.NonNull:
+			typ = t.Type
+		case *internal.List:
+			typ = t.Type
+		default:
+			return typ
+		}
+	}
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
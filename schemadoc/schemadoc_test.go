@@ -0,0 +1,143 @@
+package schemadoc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemadoc"
+	"github.com/stretchr/testify/assert"
+)
+
+const sdl = `
+"The root."
+type Query {
+  "Returns a user."
+  user(id: ID!, "Whether to include <deleted> users." deleted: Boolean = false): User
+  search(filter: SearchFilter!): [Result!]! @deprecated(reason: "Use users.")
+}
+
+type Mutation {
+  createUser(input: CreateUserInput!): User!
+}
+
+"A node."
+interface Node {
+  id: ID!
+}
+
+"A user."
+type User implements Node {
+  id: ID!
+  name: String @deprecated
+  role: Role!
+  friends(first: Int = 10): [User!]!
+}
+
+union Result = User
+
+enum Role {
+  "An administrator."
+  ADMIN
+  EDITOR @deprecated(reason: "Use ADMIN.")
+}
+
+input SearchFilter @oneOf {
+  name: String
+  role: Role
+}
+
+input CreateUserInput {
+  name: String!
+  role: Role = EDITOR
+}
+
+"When the field is computed."
+directive @cost(weight: Int!) on FIELD_DEFINITION | OBJECT
+`
+
+func buildSchema(t *testing.T) *internal.Schema {
+	doc, errs := internal.ParseDocument(sdl)
+	if !assert.Nil(t, errs) {
+		t.FailNow()
+	}
+	schema, err := introspection.BuildASTSchema(doc)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return schema
+}
+
+func TestMarkdown(t *testing.T) {
+	schema := buildSchema(t)
+	schema.TypeMap["User"].(*internal.Object).Fields["id"].Extensions = map[string]interface{}{"tags": []string{"pii"}}
+	doc := schemadoc.Markdown(schema, schemadoc.Options{
+		Title:               "Users API",
+		ExtensionsDirective: "meta",
+		Examples:            map[string]string{"Mutation.createUser": `mutation { createUser(input: {name: "Ada"}) { id } }`},
+	})
+
+	assert.True(t, strings.HasPrefix(doc, "# Users API\n\n## Queries\n\n### Query.search\n"), doc)
+	assert.Contains(t, doc, "### Query.user\n\nReturns a user.\n\nReturns [`User`](#user).\n\nArguments:\n\n"+
+		"- `deleted`: `Boolean` = `false` — Whether to include <deleted> users.\n- `id`: `ID!`\n\n"+
+		"```graphql\nquery {\n  user(id: \"1\") {\n    id\n    role\n  }\n}\n```\n")
+	assert.Contains(t, doc, "### Query.search\n\n**Deprecated:** Use users.\n\nReturns [`[Result!]!`](#result).\n\n"+
+		"Arguments:\n\n- `filter`: [`SearchFilter!`](#searchfilter)\n\n"+
+		"```graphql\nquery {\n  search(filter: {name: \"string\"}) {\n    __typename\n  }\n}\n```\n")
+	assert.Contains(t, doc, "```graphql\nmutation { createUser(input: {name: \"Ada\"}) { id } }\n```\n")
+	assert.Contains(t, doc, "### User\n\n*Object type*\n\nA user.\n\nImplements [`Node`](#node).\n\nFields:\n\n"+
+		"- `friends`: [`[User!]!`](#user)\n  - `first`: `Int` = `10`\n"+
+		"- `id`: `ID!` `@meta(tags: [\"pii\"])`\n"+
+		"- `name`: `String` **Deprecated:** No longer supported\n"+
+		"- `role`: [`Role!`](#role)\n")
+	assert.Contains(t, doc, "### Role\n\n*Enum*\n\nValues:\n\n- `ADMIN` — An administrator.\n"+
+		"- `EDITOR` **Deprecated:** Use ADMIN.\n")
+	assert.Contains(t, doc, "### SearchFilter\n\n*Input object type* `@oneOf`\n")
+	assert.Contains(t, doc, "### CreateUserInput\n\n*Input object type*\n\nFields:\n\n- `name`: `String!`\n"+
+		"- `role`: [`Role`](#role) = `EDITOR`\n")
+	assert.Contains(t, doc, "### Node\n\n*Interface*\n\nA node.\n\nPossible types: [`User`](#user).\n")
+	assert.True(t, strings.HasSuffix(doc, "## Directives\n\n### @cost\n\nWhen the field is computed.\n\n"+
+		"Locations: FIELD_DEFINITION, OBJECT.\n\nArguments:\n\n- `weight`: `Int!`\n"), doc)
+	// the built-in scalars and directives and the introspection types are not documented
+	assert.NotContains(t, doc, "### String")
+	assert.NotContains(t, doc, "@deprecated")
+	assert.NotContains(t, doc, "__Schema")
+}
+
+func TestHTML(t *testing.T) {
+	doc := schemadoc.HTML(buildSchema(t), schemadoc.Options{Title: "Users <API>"})
+
+	assert.Contains(t, doc, "<title>Users &lt;API&gt;</title>")
+	assert.Contains(t, doc, `<section id="Query.user">
+<h3>Query.user</h3>
+<p>Returns a user.</p>
+<p>Returns <a href="#user"><code>User</code></a>.</p>
+<p>Arguments:</p>
+<ul>
+<li><code>deleted</code>: <code>Boolean</code> = <code>false</code> — Whether to include &lt;deleted&gt; users.</li>
+<li><code>id</code>: <code>ID!</code></li>
+</ul>
+<pre><code class="language-graphql">query {
+  user(id: &#34;1&#34;) {
+    id
+    role
+  }
+}</code></pre>
+</section>`)
+	assert.Contains(t, doc, `<p class="deprecated">Deprecated: Use users.</p>`)
+	assert.Contains(t, doc, `<section id="user">
+<h3>User</h3>
+<p><span class="kind">Object type</span></p>
+<p>A user.</p>
+<p>Implements <a href="#node"><code>Node</code></a>.</p>
+<p>Fields:</p>
+<ul>
+<li><code>friends</code>: <a href="#user"><code>[User!]!</code></a>
+<ul>
+<li><code>first</code>: <code>Int</code> = <code>10</code></li>
+</ul></li>`)
+	assert.Contains(t, doc, `<li><code>EDITOR</code> <span class="deprecated">Deprecated: Use ADMIN.</span></li>`)
+	assert.Contains(t, doc, `<section id="@cost">`)
+	assert.True(t, strings.HasSuffix(doc, "</section>\n</body>\n</html>\n"))
+}
@@ -0,0 +1,216 @@
+package schemalint
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+)
+
+// TypeDescriptions reports the types which have no description. The extensions of the types are not reported.
+var TypeDescriptions = &Rule{Name: "TypeDescriptions", Severity: Warning, Check: func(c *Context) {
+	for _, d := range c.definitions() {
+		if !d.extension && d.desc == nil {
+			c.Report(d.name.Loc, "The type %s has no description.", d.name.Name)
+		}
+	}
+}}
+
+// FieldDescriptions reports the fields of the object, interface and input object types which have no description.
+var FieldDescriptions = &Rule{Name: "FieldDescriptions", Severity: Warning, Check: func(c *Context) {
+	for _, d := range c.definitions() {
+		for _, field := range d.fields {
+			if field.Desc == nil {
+				c.Report(field.Name.Loc, "The field %s.%s has no description.", d.name.Name, field.Name.Name)
+			}
+		}
+		for _, field := range d.inputFields {
+			if field.Desc == nil {
+				c.Report(field.Name.Loc, "The field %s.%s has no description.", d.name.Name, field.Name.Name)
+			}
+		}
+	}
+}}
+
+var pascalCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// TypeNamesPascalCase reports the types the names of which are not PascalCase, eg. user_profile.
+var TypeNamesPascalCase = &Rule{Name: "TypeNamesPascalCase", Severity: Error, Check: func(c *Context) {
+	for _, d := range c.definitions() {
+		if !d.extension && !pascalCase.MatchString(d.name.Name) {
+			c.Report(d.name.Loc, "The type name %s is not PascalCase.", d.name.Name)
+		}
+	}
+}}
+
+var camelCase = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// FieldNamesCamelCase reports the fields, the input fields and the arguments the names of which are not camelCase,
+// eg. created_at.
+var FieldNamesCamelCase = &Rule{Name: "FieldNamesCamelCase", Severity: Error, Check: func(c *Context) {
+	for _, d := range c.definitions() {
+		for _, field := range d.fields {
+			if !camelCase.MatchString(field.Name.Name) {
+				c.Report(field.Name.Loc, "The field name %s.%s is not camelCase.", d.name.Name, field.Name.Name)
+			}
+			for _, arg := range field.Argument {
+				if !camelCase.MatchString(arg.Name.Name) {
+					c.Report(arg.Name.Loc, "The argument name %s.%s(%s:) is not camelCase.", d.name.Name,
+						field.Name.Name, arg.Name.Name)
+				}
+			}
+		}
+		for _, field := range d.inputFields {
+			if !camelCase.MatchString(field.Name.Name) {
+				c.Report(field.Name.Loc, "The field name %s.%s is not camelCase.", d.name.Name, field.Name.Name)
+			}
+		}
+	}
+}}
+
+var screamingSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+
+// EnumValuesScreamingSnakeCase reports the enum values which are not SCREAMING_SNAKE_CASE, eg. Admin.
+var EnumValuesScreamingSnakeCase = &Rule{Name: "EnumValuesScreamingSnakeCase", Severity: Error,
+	Check: func(c *Context) {
+		for _, d := range c.definitions() {
+			for _, value := range d.values {
+				if !screamingSnakeCase.MatchString(value.Value.Value) {
+					c.Report(value.Value.Loc, "The enum value %s.%s is not SCREAMING_SNAKE_CASE.", d.name.Name,
+						value.Value.Value)
+				}
+			}
+		}
+	}}
+
+// MutationPayloads reports the mutations which do not return a payload type, an object type the name of which ends
+// with Payload, eg. createUser returning CreateUserPayload, so that the mutations may return more fields later.
+var MutationPayloads = &Rule{Name: "MutationPayloads", Severity: Warning, Check: func(c *Context) {
+	mutation := c.mutationType()
+	objects := make(map[string]bool)
+	for _, d := range c.definitions() {
+		if d.kind == "type" {
+			objects[d.name.Name] = true
+		}
+	}
+	for _, d := range c.definitions() {
+		if d.kind != "type" || d.name.Name != mutation {
+			continue
+		}
+		for _, field := range d.fields {
+			typ := field.Type
+			if nonNull, ok := typ.(*ast.NonNull); ok {
+				typ = nonNull.Type
+			}
+			if named, ok := typ.(*ast.Named); ok && objects[named.Name.Name] &&
+				strings.HasSuffix(named.Name.Name, "Payload") {
+				continue
+			}
+			c.Report(field.Name.Loc, "The mutation %s.%s returns %s instead of a payload type, eg. %sPayload.",
+				d.name.Name, field.Name.Name, ast.Print(field.Type),
+				strings.ToUpper(field.Name.Name[:1])+field.Name.Name[1:])
+		}
+	}
+}}
+
+// DeprecationReasons reports the @deprecated directives which do not tell the reason of the deprecation, eg. what
+// to use instead.
+var DeprecationReasons = &Rule{Name: "DeprecationReasons", Severity: Warning, Check: func(c *Context) {
+	check := func(directives []*ast.Directive, coordinate string) {
+		for _, directive := range directives {
+			if directive.Name.Name != "deprecated" {
+				continue
+			}
+			reason := ""
+			for _, arg := range directive.Args {
+				if value, ok := arg.Value.(*ast.StringValue); ok && arg.Name.Name == "reason" {
+					reason = strings.TrimSpace(value.Text())
+				}
+			}
+			if reason == "" {
+				c.Report(directive.Loc, "The deprecation of %s has no reason.", coordinate)
+			}
+		}
+	}
+	for _, d := range c.definitions() {
+		for _, field := range d.fields {
+			check(field.Directives, d.name.Name+"."+field.Name.Name)
+			for _, arg := range field.Argument {
+				check(arg.Directives, d.name.Name+"."+field.Name.Name+"("+arg.Name.Name+":)")
+			}
+		}
+		for _, field := range d.inputFields {
+			check(field.Directives, d.name.Name+"."+field.Name.Name)
+		}
+		for _, value := range d.values {
+			check(value.Directives, d.name.Name+"."+value.Value.Value)
+		}
+	}
+}}
+
+// definition is a type definition or extension, with the parts of it the rules check.
+type definition struct {
+	// kind is the keyword of the definition, eg. type or input
+	kind        string
+	name        *ast.Name
+	desc        *ast.StringValue
+	extension   bool
+	fields      []*ast.FieldDefinition
+	inputFields []*ast.InputValueDefinition
+	values      []*ast.EnumValueDefinition
+}
+
+// definitions returns the type definitions and extensions of the document, in the order of the source.
+func (c *Context) definitions() []*definition {
+	var definitions []*definition
+	for _, d := range c.Document.Definition {
+		switch d := d.(type) {
+		case *ast.ScalarDefinition:
+			definitions = append(definitions, &definition{kind: "scalar", name: d.Name, desc: d.Desc})
+		case *ast.ObjectDefinition:
+			definitions = append(definitions, &definition{kind: "type", name: d.Name, desc: d.Desc, fields: d.Fields})
+		case *ast.ObjectExtension:
+			definitions = append(definitions, &definition{kind: "type", name: d.Name, extension: true,
+				fields: d.Fields})
+		case *ast.InterfaceDefinition:
+			definitions = append(definitions, &definition{kind: "interface", name: d.Name, desc: d.Desc,
+				fields: d.Fields})
+		case *ast.InterfaceExtension:
+			definitions = append(definitions, &definition{kind: "interface", name: d.Name, extension: true,
+				fields: d.Fields})
+		case *ast.UnionDefinition:
+			definitions = append(definitions, &definition{kind: "union", name: d.Name, desc: d.Desc})
+		case *ast.EnumDefinition:
+			definitions = append(definitions, &definition{kind: "enum", name: d.Name, desc: d.Desc, values: d.Values})
+		case *ast.EnumExtension:
+			definitions = append(definitions, &definition{kind: "enum", name: d.Name, extension: true,
+				values: d.Values})
+		case *ast.InputObjectDefinition:
+			definitions = append(definitions, &definition{kind: "input", name: d.Name, desc: d.Desc,
+				inputFields: d.InputFields})
+		case *ast.InputObjectExtension:
+			definitions = append(definitions, &definition{kind: "input", name: d.Name, extension: true,
+				inputFields: d.InputFields})
+		}
+	}
+	return definitions
+}
+
+// mutationType returns the name of the mutation type, Mutation unless the schema definition tells another one.
+func (c *Context) mutationType() string {
+	for _, d := range c.Document.Definition {
+		var operationTypes []*ast.OperationTypeDefinition
+		switch d := d.(type) {
+		case *ast.SchemaDefinition:
+			operationTypes = d.OperationTypes
+		case *ast.SchemaExtension:
+			operationTypes = d.RootOperation
+		}
+		for _, operationType := range operationTypes {
+			if operationType.Operation == ast.Mutation {
+				return operationType.Type.Name.Name
+			}
+		}
+	}
+	return "Mutation"
+}
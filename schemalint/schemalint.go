@@ -0,0 +1,149 @@
+// Package schemalint checks the style of the SDL of a schema, eg. that its fields are described or that its enum
+// values are SCREAMING_SNAKE_CASE, which validation.ValidateSDL does not tell:
+//
+//	problems, err := schemalint.Lint(doc, schemalint.Config{Rules: map[string]schemalint.Severity{
+//		"TypeDescriptions": schemalint.Off,
+//		"FieldDescriptions": schemalint.Error,
+//	}})
+//
+// The severity of every rule may be configured, the problems are reported with theirs and encode to JSON.
+package schemalint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+)
+
+// Severity tells whether the problems of a rule fail a lint, are warnings only or are not reported.
+type Severity int
+
+const (
+	Off Severity = iota
+	Warning
+	Error
+)
+
+var severityNames = []string{Off: "off", Warning: "warning", Error: "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(severityNames) {
+		return nil, fmt.Errorf("invalid severity %d", int(s))
+	}
+	return []byte(severityNames[s]), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	for severity, name := range severityNames {
+		if string(text) == name {
+			*s = Severity(severity)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q, expected off, warning or error", text)
+}
+
+// Rule checks the style of a type system document, reporting its problems to the context.
+type Rule struct {
+	Name string
+	// Severity is the severity of the problems of the rule, unless the Config of the lint tells another one.
+	Severity Severity
+	Check    func(c *Context)
+}
+
+// Config configures the rules of a lint, eg. read from a JSON file: {"rules": {"TypeDescriptions": "off"}}.
+type Config struct {
+	// Rules are the severities of the rules by name, which override the ones of the rules.
+	Rules map[string]Severity `json:"rules"`
+}
+
+// Problem is a problem of a document reported by a rule, with the severity of the rule.
+type Problem struct {
+	Rule      string            `json:"rule"`
+	Severity  Severity          `json:"severity"`
+	Message   string            `json:"message"`
+	Locations []errors.Location `json:"locations,omitempty"`
+}
+
+func (p *Problem) String() string {
+	if len(p.Locations) == 0 {
+		return fmt.Sprintf("%s: %s (%s)", p.Severity, p.Message, p.Rule)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", p.Locations[0].Line, p.Locations[0].Column, p.Severity, p.Message,
+		p.Rule)
+}
+
+// DefaultRules are the rules of Lint.
+var DefaultRules = []*Rule{
+	TypeDescriptions,
+	FieldDescriptions,
+	TypeNamesPascalCase,
+	FieldNamesCamelCase,
+	EnumValuesScreamingSnakeCase,
+	MutationPayloads,
+	DeprecationReasons,
+}
+
+// Lint checks doc, a type system document, with the DefaultRules configured by config, see LintWithRules.
+func Lint(doc *ast.Document, config Config) ([]*Problem, error) {
+	return LintWithRules(doc, DefaultRules, config)
+}
+
+// LintWithRules checks doc with rules, the severities of which are configured by config, and returns the problems
+// in the order of their locations. The rules which are Off are not run. An error is returned when config names
+// a rule which is not one of rules.
+func LintWithRules(doc *ast.Document, rules []*Rule, config Config) ([]*Problem, error) {
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name] = true
+	}
+	for name := range config.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	c := &Context{Document: doc}
+	for _, rule := range rules {
+		c.rule, c.severity = rule, rule.Severity
+		if severity, ok := config.Rules[rule.Name]; ok {
+			c.severity = severity
+		}
+		if c.severity != Off {
+			rule.Check(c)
+		}
+	}
+	sort.SliceStable(c.problems, func(i, j int) bool {
+		a, b := c.problems[i].Locations, c.problems[j].Locations
+		return len(b) == 0 || len(a) != 0 && a[0].Before(b[0])
+	})
+	return c.problems, nil
+}
+
+// Context is the type system document being linted, along with the rule which is run.
+type Context struct {
+	Document *ast.Document
+
+	rule     *Rule
+	severity Severity
+	problems []*Problem
+}
+
+// Report adds a problem of the rule which is run at loc.
+func (c *Context) Report(loc errors.Location, format string, args ...interface{}) {
+	c.problems = append(c.problems, &Problem{
+		Rule:      c.rule.Name,
+		Severity:  c.severity,
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []errors.Location{loc},
+	})
+}
@@ -0,0 +1,113 @@
+package schemalint_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemalint"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, src string) *ast.Document {
+	doc, errs := internal.ParseDocument(src)
+	assert.Nil(t, errs)
+	return doc
+}
+
+func lint(t *testing.T, src string, config schemalint.Config) []string {
+	problems, err := schemalint.Lint(parse(t, src), config)
+	assert.NoError(t, err)
+	var printed []string
+	for _, problem := range problems {
+		printed = append(printed, problem.String())
+	}
+	return printed
+}
+
+func TestLint(t *testing.T) {
+	src := `
+"The root."
+type Query {
+  "A user."
+  user(user_id: ID!): User
+}
+
+type User {
+  "The name."
+  name: String @deprecated
+  created_at: String @deprecated(reason: "Use createdAt.")
+  role: role
+}
+
+"A role."
+enum role { ADMIN editor }
+
+"""Mutations."""
+type Mutation {
+  "Creates a user."
+  createUser: User!
+  "Deletes a user."
+  deleteUser: DeleteUserPayload!
+}
+
+"The result of deleteUser."
+type DeleteUserPayload { "Whether it was deleted." deleted: Boolean }
+`
+	assert.Equal(t, []string{
+		`5:8: error: The argument name Query.user(user_id:) is not camelCase. (FieldNamesCamelCase)`,
+		`8:6: warning: The type User has no description. (TypeDescriptions)`,
+		`10:16: warning: The deprecation of User.name has no reason. (DeprecationReasons)`,
+		`11:3: warning: The field User.created_at has no description. (FieldDescriptions)`,
+		`11:3: error: The field name User.created_at is not camelCase. (FieldNamesCamelCase)`,
+		`12:3: warning: The field User.role has no description. (FieldDescriptions)`,
+		`16:6: error: The type name role is not PascalCase. (TypeNamesPascalCase)`,
+		`16:19: error: The enum value role.editor is not SCREAMING_SNAKE_CASE. (EnumValuesScreamingSnakeCase)`,
+		`21:3: warning: The mutation Mutation.createUser returns User! instead of a payload type, eg. CreateUserPayload. (MutationPayloads)`,
+	}, lint(t, src, schemalint.Config{}))
+
+	var config schemalint.Config
+	assert.NoError(t, json.Unmarshal([]byte(`{"rules": {"TypeDescriptions": "off", "FieldDescriptions": "off",
+		"DeprecationReasons": "error", "FieldNamesCamelCase": "off", "TypeNamesPascalCase": "warning"}}`), &config))
+	assert.Equal(t, []string{
+		`10:16: error: The deprecation of User.name has no reason. (DeprecationReasons)`,
+		`16:6: warning: The type name role is not PascalCase. (TypeNamesPascalCase)`,
+		`16:19: error: The enum value role.editor is not SCREAMING_SNAKE_CASE. (EnumValuesScreamingSnakeCase)`,
+		`21:3: warning: The mutation Mutation.createUser returns User! instead of a payload type, eg. CreateUserPayload. (MutationPayloads)`,
+	}, lint(t, src, config))
+
+	// the mutation type of the schema definition
+	assert.Equal(t, []string{
+		`1:62: warning: The mutation Mutations.delete returns [ID] instead of a payload type, eg. DeletePayload. (MutationPayloads)`,
+	}, lint(t, `schema { query: Query mutation: Mutations } type Mutations { delete: [ID] } type Query { a: Int }`,
+		schemalint.Config{Rules: map[string]schemalint.Severity{"TypeDescriptions": schemalint.Off,
+			"FieldDescriptions": schemalint.Off}}))
+}
+
+func TestLint_Config(t *testing.T) {
+	_, err := schemalint.Lint(parse(t, "type Query { a: Int }"), schemalint.Config{
+		Rules: map[string]schemalint.Severity{"FieldDescription": schemalint.Error},
+	})
+	assert.EqualError(t, err, `unknown rule "FieldDescription"`)
+
+	var config schemalint.Config
+	assert.EqualError(t, json.Unmarshal([]byte(`{"rules": {"TypeDescriptions": "warn"}}`), &config),
+		`unknown severity "warn", expected off, warning or error`)
+
+	// a custom rule
+	noInterfaces := &schemalint.Rule{Name: "NoInterfaces", Severity: schemalint.Error, Check: func(c *schemalint.Context) {
+		for _, definition := range c.Document.Definition {
+			if d, ok := definition.(*ast.InterfaceDefinition); ok {
+				c.Report(d.Name.Loc, "The interface %s is not allowed.", d.Name.Name)
+			}
+		}
+	}}
+	problems, err := schemalint.LintWithRules(parse(t, "interface Node { id: ID! }"), []*schemalint.Rule{noInterfaces},
+		schemalint.Config{})
+	assert.NoError(t, err)
+	b, err := json.Marshal(problems)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"rule": "NoInterfaces", "severity": "error", "message": "The interface Node is not allowed.",
+		"locations": [{"line": 1, "column": 11}]}]`, string(b))
+}
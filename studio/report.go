@@ -0,0 +1,190 @@
+package studio
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"time"
+)
+
+// The messages of the usage reports are encoded by hand with the field numbers of reports.proto of Apollo, only
+// the fields of the statistics are sent.
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+type encoder []byte
+
+func (e *encoder) tag(field int, wireType int) {
+	*e = binary.AppendUvarint(*e, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	*e = binary.AppendUvarint(*e, v)
+}
+
+func (e *encoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	*e = binary.AppendUvarint(*e, uint64(len(s)))
+	*e = append(*e, s...)
+}
+
+func (e *encoder) message(field int, encode func(e *encoder)) {
+	var m encoder
+	encode(&m)
+	e.tag(field, wireBytes)
+	*e = binary.AppendUvarint(*e, uint64(len(m)))
+	*e = append(*e, m...)
+}
+
+// sints encodes a packed repeated sint64 field.
+func (e *encoder) sints(field int, values []int64) {
+	if len(values) == 0 {
+		return
+	}
+	var packed []byte
+	for _, v := range values {
+		packed = binary.AppendUvarint(packed, uint64(v<<1)^uint64(v>>63))
+	}
+	e.tag(field, wireBytes)
+	*e = binary.AppendUvarint(*e, uint64(len(packed)))
+	*e = append(*e, packed...)
+}
+
+// histogramBuckets is the number of buckets of the duration histograms, the bucket n counts the durations up to
+// 1.1^n microseconds.
+const histogramBuckets = 384
+
+type histogram [histogramBuckets]int64
+
+func (h *histogram) observe(d time.Duration) {
+	bucket := int(math.Ceil(math.Log(float64(d)/1000) / math.Log(1.1)))
+	switch {
+	case bucket <= 0 || d <= 0:
+		bucket = 0
+	case bucket >= histogramBuckets:
+		bucket = histogramBuckets - 1
+	}
+	h[bucket]++
+}
+
+// counts returns the counts of the buckets, the runs of empty buckets being replaced by their negated length and
+// the trailing ones left out.
+func (h *histogram) counts() []int64 {
+	var counts []int64
+	zeros := int64(0)
+	for _, count := range h {
+		if count == 0 {
+			zeros++
+			continue
+		}
+		switch zeros {
+		case 0:
+		case 1:
+			counts = append(counts, 0)
+		default:
+			counts = append(counts, -zeros)
+		}
+		counts = append(counts, count)
+		zeros = 0
+	}
+	return counts
+}
+
+// header is the ReportHeader of the reports.
+type header struct {
+	graphRef       string
+	hostname       string
+	agentVersion   string
+	serviceVersion string
+	runtimeVersion string
+}
+
+// client identifies the StatsContext of the statistics.
+type client struct {
+	name    string
+	version string
+}
+
+// stats are the QueryLatencyStats of the operations of a client.
+type stats struct {
+	latency         histogram
+	requests        uint64
+	requestsInError uint64
+}
+
+// report is a Report, the statistics of the operations by stats report key and client.
+type report struct {
+	header     header
+	operations map[string]map[client]*stats
+	count      uint64
+	end        time.Time
+}
+
+func (r *report) encode() []byte {
+	var e encoder
+	e.message(1, func(e *encoder) {
+		e.string(5, r.header.hostname)
+		e.string(6, r.header.agentVersion)
+		e.string(7, r.header.serviceVersion)
+		e.string(8, r.header.runtimeVersion)
+		e.string(12, r.header.graphRef)
+	})
+	e.message(2, func(e *encoder) {
+		e.uint(1, uint64(r.end.Unix()))
+		e.uint(2, uint64(r.end.Nanosecond()))
+	})
+	keys := make([]string, 0, len(r.operations))
+	for key := range r.operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		clients := r.operations[key]
+		// traces_per_query is a map, whose entries are messages of a key and a value
+		e.message(5, func(e *encoder) {
+			e.string(1, key)
+			e.message(2, func(e *encoder) {
+				for _, c := range sortedClients(clients) {
+					s := clients[c]
+					e.message(2, func(e *encoder) {
+						e.message(1, func(e *encoder) {
+							e.string(2, c.name)
+							e.string(3, c.version)
+						})
+						e.message(2, func(e *encoder) {
+							e.uint(2, s.requests)
+							e.uint(8, s.requestsInError)
+							e.sints(13, s.latency.counts())
+						})
+					})
+				}
+			})
+		})
+	}
+	e.uint(6, r.count)
+	return e
+}
+
+func sortedClients(clients map[client]*stats) []client {
+	sorted := make([]client, 0, len(clients))
+	for c := range clients {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].name != sorted[j].name {
+			return sorted[i].name < sorted[j].name
+		}
+		return sorted[i].version < sorted[j].version
+	})
+	return sorted
+}
@@ -0,0 +1,237 @@
+// Package studio reports the usage of a graph to Apollo Studio, or to an endpoint accepting its usage reports: the
+// operations run by an executor are aggregated by operation and client, and their statistics are sent in the
+// background.
+//
+//	reporter := studio.NewReporter(os.Getenv("APOLLO_KEY"), "my-graph@current")
+//	go reporter.Run(ctx)
+//	executor := &execution.Executor{Interceptors: []*execution.Interceptor{reporter.Interceptor()}}
+//
+// The transports tell the client of the operations with WithClient, eg. from the apollographql-client-name and
+// apollographql-client-version headers.
+package studio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+)
+
+// DefaultEndpoint is the endpoint of the usage reports of Apollo Studio.
+const DefaultEndpoint = "https://usage-reporting.api.apollographql.com/api/ingress/traces"
+
+// The stats report keys of the operations which are not executed.
+const (
+	parseFailureKey      = "## GraphQLParseFailure\n"
+	validationFailureKey = "## GraphQLValidationFailure\n"
+)
+
+// Reporter aggregates the statistics of the operations and sends them to Endpoint every Interval, once Run is
+// called. Its fields are not to be modified once Run is called.
+type Reporter struct {
+	APIKey   string
+	GraphRef string
+	// Endpoint receives the reports, it is DefaultEndpoint by default.
+	Endpoint string
+	// Interval is the period of the reports, 10 seconds by default.
+	Interval time.Duration
+	// ServiceVersion is the version of the server reported along with the statistics.
+	ServiceVersion string
+	// Signature returns the signature of an operation, which identifies it in the reports along with its name.
+	// By default it is the query without its comments, its insignificant whitespace and its inline values, the
+	// strings being replaced by "" and the numbers by 0.
+	Signature func(query, operationName string) string
+	// Client sends the reports, it is http.DefaultClient by default.
+	Client *http.Client
+	// ErrorLog is called with the errors of the reports which can not be sent, they are dropped.
+	ErrorLog func(err error)
+	// Clock measures the durations and triggers the reports, it is clock.Real by default.
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	pending *report
+	// started holds the start of the operations being executed
+	started sync.Map
+}
+
+// NewReporter returns a reporter of the usage of the graph of graphRef, eg. "my-graph@current", authenticated with
+// apiKey.
+func NewReporter(apiKey, graphRef string) *Reporter {
+	return &Reporter{
+		APIKey:   apiKey,
+		GraphRef: graphRef,
+		Endpoint: DefaultEndpoint,
+		Interval: 10 * time.Second,
+		Client:   http.DefaultClient,
+		Clock:    clock.Real,
+	}
+}
+
+type clientKey struct{}
+
+// WithClient returns a context telling the name and the version of the client sending the operations run with it.
+func WithClient(ctx context.Context, name, version string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client{name: name, version: version})
+}
+
+// Interceptor returns the interceptor aggregating the statistics of the operations of an executor.
+func (r *Reporter) Interceptor() *execution.Interceptor {
+	return &execution.Interceptor{
+		AfterParse: func(ctx context.Context, op *execution.Operation) error {
+			r.started.Store(op, r.Clock.Now())
+			return nil
+		},
+		AfterExecution: func(ctx context.Context, op *execution.Operation, response *execution.Response) {
+			var duration time.Duration
+			if start, ok := r.started.LoadAndDelete(op); ok {
+				duration = r.Clock.Since(start.(time.Time))
+			}
+			var syntaxErr *errors.SyntaxError
+			var validationErr *errors.ValidationError
+			var key string
+			switch {
+			case stderrors.As(response.Errors, &syntaxErr):
+				key = parseFailureKey
+			case stderrors.As(response.Errors, &validationErr):
+				key = validationFailureKey
+			default:
+				key = r.statsReportKey(op.Query, operationName(op))
+			}
+			c, _ := ctx.Value(clientKey{}).(client)
+			r.record(key, c, duration, len(response.Errors) > 0)
+		},
+	}
+}
+
+// operationName returns the name of the operation executed by op, which is not told by the request when the
+// document has a single operation.
+func operationName(op *execution.Operation) string {
+	if op.OperationName != "" || op.Document == nil || len(op.Document.Operations) != 1 {
+		return op.OperationName
+	}
+	if name := op.Document.Operations[0].Name; name != nil {
+		return name.Name
+	}
+	return op.OperationName
+}
+
+// statsReportKey returns the key of the statistics of an operation, its name and its signature.
+func (r *Reporter) statsReportKey(query, operationName string) string {
+	name := operationName
+	if name == "" {
+		name = "-"
+	}
+	signature := r.Signature
+	if signature == nil {
+		signature = defaultSignature
+	}
+	return "# " + name + "\n" + signature(query, operationName)
+}
+
+func defaultSignature(query, operationName string) string {
+	return internal.NormalizeQuery(query)
+}
+
+func (r *Reporter) record(key string, c client, duration time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = &report{operations: make(map[string]map[client]*stats)}
+	}
+	clients := r.pending.operations[key]
+	if clients == nil {
+		clients = make(map[client]*stats)
+		r.pending.operations[key] = clients
+	}
+	s := clients[c]
+	if s == nil {
+		s = &stats{}
+		clients[c] = s
+	}
+	s.requests++
+	if failed {
+		s.requestsInError++
+	}
+	s.latency.observe(duration)
+	r.pending.count++
+}
+
+// Run sends the reports every Interval until ctx is done, the pending statistics are sent then.
+func (r *Reporter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			// the context of the last report outlives ctx
+			flushCtx, cancel := context.WithTimeout(context.Background(), r.Interval)
+			r.report(flushCtx)
+			cancel()
+			return
+		case <-r.Clock.After(r.Interval):
+			r.report(ctx)
+		}
+	}
+}
+
+func (r *Reporter) report(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil && r.ErrorLog != nil {
+		r.ErrorLog(err)
+	}
+}
+
+// Flush sends the pending statistics at once, they are dropped when they can not be sent.
+func (r *Reporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if pending == nil {
+		return nil
+	}
+	hostname, _ := os.Hostname()
+	pending.header = header{
+		graphRef:       r.GraphRef,
+		hostname:       hostname,
+		agentVersion:   "graphql-go",
+		serviceVersion: r.ServiceVersion,
+		runtimeVersion: runtime.Version(),
+	}
+	pending.end = r.Clock.Now()
+
+	var body bytes.Buffer
+	w := gzip.NewWriter(&body)
+	if _, err := w.Write(pending.encode()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-Api-Key", r.APIKey)
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("usage report rejected with %s: %s", resp.Status, message)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package studio_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/clock"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/studio"
+	"github.com/stretchr/testify/assert"
+)
+
+// fields are the fields of a protobuf message by number, the values are uint64 or []byte.
+type fields map[uint64][]interface{}
+
+func decode(t *testing.T, b []byte) fields {
+	f := make(fields)
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		b = b[n:]
+		switch tag & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			b = b[n:]
+			f[tag>>3] = append(f[tag>>3], v)
+		case 2:
+			length, n := binary.Uvarint(b)
+			f[tag>>3] = append(f[tag>>3], b[n:n+int(length)])
+			b = b[n+int(length):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return f
+}
+
+func (f fields) message(t *testing.T, field uint64) fields {
+	if !assert.Len(t, f[field], 1, "field %d", field) {
+		t.FailNow()
+	}
+	return decode(t, f[field][0].([]byte))
+}
+
+func (f fields) string(t *testing.T, field uint64) string {
+	if len(f[field]) == 0 {
+		return ""
+	}
+	return string(f[field][0].([]byte))
+}
+
+func sints(b []byte) []int64 {
+	var values []int64
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		values = append(values, int64(v>>1)^-int64(v&1))
+	}
+	return values
+}
+
+func TestReporter(t *testing.T) {
+	reports := make(chan fields, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, "application/protobuf", r.Header.Get("Content-Type"))
+		gz, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		body, _ := io.ReadAll(gz)
+		reports <- decode(t, body)
+	}))
+	defer server.Close()
+
+	reporter := studio.NewReporter("key", "graph@current")
+	reporter.Endpoint = server.URL
+	mock := clock.NewMock(time.Unix(100, 0))
+	reporter.Clock = mock
+
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hero", func() string {
+		mock.Add(time.Millisecond)
+		return "Luke"
+	})
+	schema := build.MustBuild()
+	executor := &execution.Executor{Interceptors: []*execution.Interceptor{reporter.Interceptor()}}
+	ctx := studio.WithClient(context.Background(), "web", "1.0")
+	executor.Do(schema, execution.Params{Query: "query Hero {\n  hero\n}", Context: ctx})
+	executor.Do(schema, execution.Params{Query: "query Hero {\n  # the hero\n  hero\n}", Context: ctx})
+	executor.Do(schema, execution.Params{Query: "{ hero "})
+
+	assert.NoError(t, reporter.Flush(context.Background()))
+	report := <-reports
+	assert.Equal(t, "graph@current", report.message(t, 1).string(t, 12))
+	assert.Equal(t, []interface{}{uint64(100)}, report.message(t, 2)[1])
+	assert.Equal(t, []interface{}{uint64(3)}, report[6])
+
+	perQuery := make(map[string]fields)
+	for _, entry := range report[5] {
+		entry := decode(t, entry.([]byte))
+		perQuery[entry.string(t, 1)] = entry.message(t, 2)
+	}
+	if !assert.Len(t, perQuery, 2) {
+		return
+	}
+	hero := perQuery["# Hero\nquery Hero{hero}"].message(t, 2)
+	assert.Equal(t, "web", hero.message(t, 1).string(t, 2))
+	assert.Equal(t, "1.0", hero.message(t, 1).string(t, 3))
+	latency := hero.message(t, 2)
+	assert.Equal(t, []interface{}{uint64(2)}, latency[2])
+	assert.Nil(t, latency[8])
+	// 1ms is in the bucket 73, 1.1^73 being the first power above 1000 microseconds
+	assert.Equal(t, []int64{-73, 2}, sints(latency[13][0].([]byte)))
+
+	failure := perQuery["## GraphQLParseFailure\n"].message(t, 2)
+	assert.Equal(t, []interface{}{uint64(1)}, failure.message(t, 2)[8])
+
+	// nothing is sent without statistics
+	assert.NoError(t, reporter.Flush(context.Background()))
+	assert.Len(t, reports, 0)
+}
@@ -23,6 +23,9 @@ const (
 	STRING    = scanner.String
 	RAWSTRING = scanner.RawString
 	AMP       = '&'
+
+	// text/scanner has no notion of `"""`, so block strings get a token of their own
+	BLOCK_STRING = scanner.Comment - 1
 )
 
 // NAME -> keyword relationship
@@ -0,0 +1,534 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/utils"
+)
+
+// the subprotocol of https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const transportWSProtocol = "graphql-transport-ws"
+
+// the subprotocol of https://github.com/apollographql/subscriptions-transport-ws/blob/master/PROTOCOL.md,
+// which is deprecated but still spoken by many deployed clients
+const legacyWSProtocol = "graphql-ws"
+
+// close codes of the graphql-transport-ws protocol
+const (
+	closeBadRequest          = 4400
+	closeUnauthorized        = 4401
+	closeForbidden           = 4403
+	closeSubprotocolNotValid = 4406
+	closeInitTimeout         = 4408
+	closeSubscriberExists    = 4409
+	closeTooManyInitRequests = 4429
+)
+
+const defaultWebsocketInitTimeout = 3 * time.Second
+
+// WebsocketHandler serves GraphQL over websocket with the graphql-transport-ws protocol, or the legacy
+// subscriptions-transport-ws protocol of Apollo when the client asks for the graphql-ws subprotocol.
+// Subscriptions are run with Executor.Subscribe, every event being sent as a next message,
+// queries and mutations are run with Executor.Run and get a single next message.
+type WebsocketHandler struct {
+	Schema *internal.Schema
+	// SchemaHolder, when set, provides the schema of each operation instead of Schema
+	SchemaHolder *schemabuilder.SchemaHolder
+	Executor     *execution.Executor
+	Upgrader     websocket.Upgrader
+	// InitTimeout is how long a client has to send connection_init after connecting, 3 seconds by default.
+	InitTimeout time.Duration
+	// KeepAlive is the interval of the ka messages sent to the clients of the legacy protocol,
+	// zero sends none.
+	KeepAlive time.Duration
+	// InitFunc, when set, is called with the payload of connection_init before the connection is acknowledged,
+	// eg. to authenticate the token it carries. The context it returns is the parent context of the operations
+	// of the connection, so it can hold the user. An error rejects the connection, which is closed with
+	// 4403 Forbidden or the code of a *CloseError.
+	InitFunc func(ctx context.Context, payload map[string]interface{}) (context.Context, error)
+	// Multiplexer, when set, shares the executions of the identical subscriptions of the clients,
+	// see execution.SubscriptionMultiplexer for the subscriptions it suits.
+	Multiplexer *execution.SubscriptionMultiplexer
+}
+
+// CloseError is an error of WebsocketHandler.InitFunc closing the connection with Code and Reason.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed with %d: %s", e.Code, e.Reason)
+}
+
+// WebsocketHTTPHandler returns a WebsocketHandler serving schema. The upgrader accepts every origin,
+// set its CheckOrigin to restrict them.
+func WebsocketHTTPHandler(schema *internal.Schema) *WebsocketHandler {
+	return &WebsocketHandler{
+		Schema:   schema,
+		Executor: &execution.Executor{},
+		Upgrader: websocket.Upgrader{
+			Subprotocols: []string{transportWSProtocol, legacyWSProtocol},
+			CheckOrigin:  func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *WebsocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has replied with the error
+		return
+	}
+	transport := &websocketTransport{conn: conn}
+	defer conn.Close()
+	switch conn.Subprotocol() {
+	case transportWSProtocol:
+		h.serve(r.Context(), transport, false)
+	case legacyWSProtocol:
+		h.serve(r.Context(), transport, true)
+	default:
+		transport.Close(closeSubprotocolNotValid, "Subprotocol not acceptable")
+	}
+}
+
+func (h *WebsocketHandler) schema() *internal.Schema {
+	if h.SchemaHolder != nil {
+		return h.SchemaHolder.Schema()
+	}
+	return h.Schema
+}
+
+// wsTransport is the connection of a websocket session, its writes may be called concurrently.
+type wsTransport interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	// Close closes the connection with a close frame of code and reason.
+	Close(code int, reason string) error
+}
+
+type websocketTransport struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (t *websocketTransport) ReadJSON(v interface{}) error {
+	return t.conn.ReadJSON(v)
+}
+
+func (t *websocketTransport) WriteJSON(v interface{}) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn.WriteJSON(v)
+}
+
+func (t *websocketTransport) Close(code int, reason string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := t.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+		return err
+	}
+	return t.conn.Close()
+}
+
+type transportMessage struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type outgoingMessage struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id,omitempty"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// wsSession is one connection.
+type wsSession struct {
+	handler   *WebsocketHandler
+	transport wsTransport
+	ctx       context.Context
+	// legacy is set for the subscriptions-transport-ws protocol
+	legacy bool
+	// operationCtx is the parent context of the operations, returned by the InitFunc of the handler
+	operationCtx context.Context
+
+	mu           sync.Mutex
+	initReceived bool
+	acknowledged bool
+	closed       bool
+	operations   map[string]*wsOperation
+}
+
+type wsOperation struct {
+	cancel context.CancelFunc
+}
+
+func (h *WebsocketHandler) serve(ctx context.Context, transport wsTransport, legacy bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s := &wsSession{handler: h, transport: transport, ctx: ctx, legacy: legacy, operationCtx: ctx, operations: make(map[string]*wsOperation)}
+
+	initTimeout := h.InitTimeout
+	if initTimeout <= 0 {
+		initTimeout = defaultWebsocketInitTimeout
+	}
+	timer := time.AfterFunc(initTimeout, func() {
+		s.mu.Lock()
+		acknowledged := s.acknowledged
+		s.mu.Unlock()
+		if !acknowledged {
+			s.close(closeInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer timer.Stop()
+
+	for {
+		var msg transportMessage
+		if err := transport.ReadJSON(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if stderrors.As(err, &syntaxErr) || stderrors.As(err, &typeErr) {
+				if legacy {
+					if s.send(outgoingMessage{Type: "connection_error", Payload: errors.New("invalid message received")}) {
+						continue
+					}
+					return
+				}
+				s.close(closeBadRequest, "Invalid message received")
+			} else {
+				// the connection is gone
+				s.close(0, "")
+			}
+			return
+		}
+		handle := s.handle
+		if legacy {
+			handle = s.handleLegacy
+		}
+		if !handle(msg) {
+			return
+		}
+	}
+}
+
+// handle handles a message of the client, it returns false once the connection is closed.
+func (s *wsSession) handle(msg transportMessage) bool {
+	switch msg.Type {
+	case "connection_init":
+		s.mu.Lock()
+		if s.initReceived {
+			s.mu.Unlock()
+			s.close(closeTooManyInitRequests, "Too many initialisation requests")
+			return false
+		}
+		s.initReceived = true
+		s.mu.Unlock()
+		if err := s.init(msg.Payload); err != nil {
+			s.reject(err)
+			return false
+		}
+		return s.send(outgoingMessage{Type: "connection_ack"})
+	case "ping":
+		return s.send(outgoingMessage{Type: "pong"})
+	case "pong":
+		return true
+	case "subscribe":
+		s.mu.Lock()
+		if !s.acknowledged {
+			s.mu.Unlock()
+			s.close(closeUnauthorized, "Unauthorized")
+			return false
+		}
+		if msg.ID == "" {
+			s.mu.Unlock()
+			s.close(closeBadRequest, "Invalid message received")
+			return false
+		}
+		if _, ok := s.operations[msg.ID]; ok {
+			s.mu.Unlock()
+			s.close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+			return false
+		}
+		var params execution.Params
+		if err := json.Unmarshal(msg.Payload, &params); err != nil {
+			s.mu.Unlock()
+			s.close(closeBadRequest, "Invalid message received")
+			return false
+		}
+		s.start(msg.ID, params)
+		s.mu.Unlock()
+		return true
+	case "complete":
+		s.stop(msg.ID)
+		return true
+	default:
+		s.close(closeBadRequest, "Invalid message received")
+		return false
+	}
+}
+
+// handleLegacy handles a message of a subscriptions-transport-ws client, it returns false once the connection
+// is closed. Unlike graphql-transport-ws, the protocol reports the invalid messages without closing the connection.
+func (s *wsSession) handleLegacy(msg transportMessage) bool {
+	switch msg.Type {
+	case "connection_init":
+		if err := s.init(msg.Payload); err != nil {
+			s.reject(err)
+			return false
+		}
+		if !s.send(outgoingMessage{Type: "connection_ack"}) {
+			return false
+		}
+		if s.handler.KeepAlive > 0 {
+			go s.keepAlive(s.handler.KeepAlive)
+			return s.send(outgoingMessage{Type: "ka"})
+		}
+		return true
+	case "start":
+		s.mu.Lock()
+		if !s.acknowledged {
+			s.mu.Unlock()
+			return s.send(outgoingMessage{Type: "error", ID: msg.ID, Payload: errors.New("connection not initialised")})
+		}
+		var params execution.Params
+		if err := json.Unmarshal(msg.Payload, &params); err != nil {
+			s.mu.Unlock()
+			return s.send(outgoingMessage{Type: "error", ID: msg.ID, Payload: errors.New("invalid payload: %s", err)})
+		}
+		// a start reusing the id of a running operation replaces it
+		if operation, ok := s.operations[msg.ID]; ok {
+			operation.cancel()
+		}
+		s.start(msg.ID, params)
+		s.mu.Unlock()
+		return true
+	case "stop":
+		s.stop(msg.ID)
+		return true
+	case "connection_terminate":
+		s.close(websocket.CloseNormalClosure, "")
+		return false
+	default:
+		return s.send(outgoingMessage{Type: "error", ID: msg.ID, Payload: errors.New("invalid message type %q", msg.Type)})
+	}
+}
+
+// init runs the InitFunc of the handler on the payload of connection_init, then acknowledges the connection
+// unless it is rejected.
+func (s *wsSession) init(payload json.RawMessage) error {
+	ctx := s.ctx
+	if s.handler.InitFunc != nil {
+		var values map[string]interface{}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &values); err != nil {
+				return &CloseError{Code: closeBadRequest, Reason: "Invalid connection_init payload"}
+			}
+		}
+		initCtx, err := s.handler.InitFunc(ctx, values)
+		if err != nil {
+			return err
+		}
+		if initCtx != nil {
+			ctx = initCtx
+		}
+	}
+	s.mu.Lock()
+	s.operationCtx, s.acknowledged = ctx, true
+	s.mu.Unlock()
+	return nil
+}
+
+// reject closes the connection refused by err. The message of err is not sent, it may tell too much.
+func (s *wsSession) reject(err error) {
+	code, reason := closeForbidden, "Forbidden"
+	var closeErr *CloseError
+	if stderrors.As(err, &closeErr) {
+		code, reason = closeErr.Code, closeErr.Reason
+	}
+	if s.legacy {
+		s.send(outgoingMessage{Type: "connection_error", Payload: errors.New("%s", reason)})
+	}
+	s.close(code, reason)
+}
+
+func (s *wsSession) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !s.send(outgoingMessage{Type: "ka"}) {
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// start runs an operation in the background, s.mu must be held.
+func (s *wsSession) start(id string, params execution.Params) {
+	ctx, cancel := context.WithCancel(s.operationCtx)
+	operation := &wsOperation{cancel: cancel}
+	s.operations[id] = operation
+	params.Context = ctx
+	go s.execute(id, operation, params)
+}
+
+// stop stops the operation of the client with id.
+func (s *wsSession) stop(id string) {
+	s.mu.Lock()
+	operation, ok := s.operations[id]
+	delete(s.operations, id)
+	s.mu.Unlock()
+	if ok {
+		operation.cancel()
+	}
+}
+
+// execute runs an operation and sends its results, followed by complete unless the client completed it.
+func (s *wsSession) execute(id string, operation *wsOperation, params execution.Params) {
+	ctx := params.Context
+	defer s.finish(id, operation)
+
+	executor := s.handler.Executor
+	if executor == nil {
+		executor = &execution.Executor{}
+	}
+	schema := s.handler.schema()
+
+	doc, parseErrs := internal.Parse(params.Query)
+	if parseErrs != nil {
+		s.sendErrors(ctx, id, parseErrs)
+		return
+	}
+	if isSubscription(doc, params.OperationName) {
+		subscribe := func(ctx context.Context) (<-chan *execution.Response, error) {
+			return executor.Subscribe(ctx, schema, doc, params.OperationName, params.Variables)
+		}
+		var responses <-chan *execution.Response
+		var err error
+		if s.handler.Multiplexer != nil {
+			// the subscriptions of different schemas can not be shared
+			key := fmt.Sprintf("%p:%s", schema, execution.MultiplexKey(params.Query, params.OperationName, params.Variables))
+			responses, err = s.handler.Multiplexer.Subscribe(ctx, key, subscribe)
+		} else {
+			responses, err = subscribe(ctx)
+		}
+		if err != nil {
+			s.sendErrors(ctx, id, err)
+			return
+		}
+		for response := range responses {
+			if !s.next(id, &Response{Data: response.Data, Errors: response.Errors}) {
+				return
+			}
+		}
+	} else {
+		op, response := executor.Run(schema, params)
+		if op.SelectionSet == nil {
+			// rejected before execution
+			s.sendErrors(ctx, id, response.Errors)
+			return
+		}
+		if !s.next(id, &Response{Data: response.Data, Errors: response.Errors}) {
+			return
+		}
+	}
+	if ctx.Err() == nil {
+		s.send(outgoingMessage{Type: "complete", ID: id})
+	}
+}
+
+// next sends a result of the operation with id.
+func (s *wsSession) next(id string, response *Response) bool {
+	if s.legacy {
+		return s.send(outgoingMessage{Type: "data", ID: id, Payload: response})
+	}
+	return s.send(outgoingMessage{Type: "next", ID: id, Payload: response})
+}
+
+// sendErrors reports the errors preventing the execution of the operation with id.
+func (s *wsSession) sendErrors(ctx context.Context, id string, err error) {
+	var errs errors.MultiError
+	switch err := err.(type) {
+	case errors.MultiError:
+		errs = err
+	case *errors.GraphQLError:
+		errs = errors.MultiError{err}
+	default:
+		errs = errors.MultiError{{Message: err.Error()}}
+	}
+	if s.legacy {
+		// subscriptions-transport-ws sends them as the result of the operation, like graphql-js does
+		if s.next(id, &Response{Errors: errs}) && ctx.Err() == nil {
+			s.send(outgoingMessage{Type: "complete", ID: id})
+		}
+		return
+	}
+	s.send(outgoingMessage{Type: "error", ID: id, Payload: errs})
+}
+
+// finish forgets a terminated operation, unless another operation has taken its id since.
+func (s *wsSession) finish(id string, operation *wsOperation) {
+	s.mu.Lock()
+	if s.operations[id] == operation {
+		delete(s.operations, id)
+	}
+	s.mu.Unlock()
+	operation.cancel()
+}
+
+func (s *wsSession) send(msg outgoingMessage) bool {
+	if err := s.transport.WriteJSON(msg); err != nil {
+		s.close(0, "")
+		return false
+	}
+	return true
+}
+
+// close closes the connection once with code and stops its operations.
+// A zero code only stops the operations, for connections which are already gone.
+func (s *wsSession) close(code int, reason string) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	operations := s.operations
+	s.operations = map[string]*wsOperation{}
+	s.mu.Unlock()
+	for _, operation := range operations {
+		operation.cancel()
+	}
+	if code != 0 {
+		s.transport.Close(code, reason)
+	}
+}
+
+// isSubscription tells whether the operation to execute is a subscription, the other errors are left
+// to the execution.
+func isSubscription(doc *internal.Document, operationName string) bool {
+	var op *ast.OperationDefinition
+	if operationName != "" {
+		op = utils.GetOperation(doc.Operations, operationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	return op != nil && op.Operation == ast.Subscription
+}
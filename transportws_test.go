@@ -0,0 +1,284 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// fakeTransport is an in-memory wsTransport, the client writes to in and reads from out.
+type fakeTransport struct {
+	in     chan string
+	out    chan map[string]interface{}
+	closed chan closeFrame
+	done   chan struct{}
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		in:     make(chan string, 10),
+		out:    make(chan map[string]interface{}, 10),
+		closed: make(chan closeFrame, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+func (t *fakeTransport) ReadJSON(v interface{}) error {
+	select {
+	case message, ok := <-t.in:
+		if !ok {
+			return io.EOF
+		}
+		return json.Unmarshal([]byte(message), v)
+	case <-t.done:
+		return io.EOF
+	}
+}
+
+func (t *fakeTransport) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var message map[string]interface{}
+	if err := json.Unmarshal(b, &message); err != nil {
+		return err
+	}
+	t.out <- message
+	return nil
+}
+
+func (t *fakeTransport) Close(code int, reason string) error {
+	t.closed <- closeFrame{code: code, reason: reason}
+	close(t.done)
+	return nil
+}
+
+func (t *fakeTransport) receive(tt *testing.T) map[string]interface{} {
+	select {
+	case message := <-t.out:
+		return message
+	case <-time.After(time.Second):
+		tt.Fatal("no message received")
+		return nil
+	}
+}
+
+func (t *fakeTransport) closeFrame(tt *testing.T) closeFrame {
+	select {
+	case frame := <-t.closed:
+		return frame
+	case <-time.After(time.Second):
+		tt.Fatal("connection not closed")
+		return closeFrame{}
+	}
+}
+
+type wsUserKey struct{}
+
+type wsHero struct {
+	Name string `graphql:"name"`
+}
+
+func TestWebsocketHandler(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", wsHero{})
+	build.Query().FieldFunc("hero", func() *wsHero { return &wsHero{Name: "Luke"} })
+	build.Query().FieldFunc("me", func(ctx context.Context) string {
+		user, _ := ctx.Value(wsUserKey{}).(string)
+		return user
+	})
+	build.Subscription().FieldFunc("heroes", func(ctx context.Context, args struct {
+		Names []string `graphql:"names"`
+	}) <-chan *wsHero {
+		heroes := make(chan *wsHero)
+		go func() {
+			defer close(heroes)
+			if len(args.Names) == 0 {
+				<-ctx.Done()
+			}
+			for _, name := range args.Names {
+				select {
+				case heroes <- &wsHero{Name: name}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return heroes
+	})
+	handler := WebsocketHTTPHandler(build.MustBuild())
+
+	connect := func(t *testing.T) *fakeTransport {
+		transport := newFakeTransport()
+		go handler.serve(context.Background(), transport, false)
+		transport.in <- `{"type":"connection_init"}`
+		assert.Equal(t, map[string]interface{}{"type": "connection_ack"}, transport.receive(t))
+		return transport
+	}
+
+	t.Run("ping", func(t *testing.T) {
+		transport := connect(t)
+		defer close(transport.in)
+		transport.in <- `{"type":"ping"}`
+		assert.Equal(t, map[string]interface{}{"type": "pong"}, transport.receive(t))
+	})
+
+	t.Run("subscription", func(t *testing.T) {
+		transport := connect(t)
+		defer close(transport.in)
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"subscription ($names: [String!]!) { heroes(names: $names) { name } }","variables":{"names":["Luke","Leia"]}}}`
+		for _, name := range []string{"Luke", "Leia"} {
+			assert.Equal(t, map[string]interface{}{
+				"type":    "next",
+				"id":      "1",
+				"payload": map[string]interface{}{"data": map[string]interface{}{"heroes": map[string]interface{}{"name": name}}},
+			}, transport.receive(t))
+		}
+		assert.Equal(t, map[string]interface{}{"type": "complete", "id": "1"}, transport.receive(t))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		transport := connect(t)
+		defer close(transport.in)
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"{ hero { name } }"}}`
+		assert.Equal(t, map[string]interface{}{
+			"type":    "next",
+			"id":      "1",
+			"payload": map[string]interface{}{"data": map[string]interface{}{"hero": map[string]interface{}{"name": "Luke"}}},
+		}, transport.receive(t))
+		assert.Equal(t, map[string]interface{}{"type": "complete", "id": "1"}, transport.receive(t))
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		transport := connect(t)
+		defer close(transport.in)
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"subscription { villains { name } }"}}`
+		message := transport.receive(t)
+		assert.Equal(t, "error", message["type"])
+		assert.Equal(t, "1", message["id"])
+		assert.Len(t, message["payload"], 1)
+	})
+
+	t.Run("subscribe before init", func(t *testing.T) {
+		transport := newFakeTransport()
+		go handler.serve(context.Background(), transport, false)
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"{ hero { name } }"}}`
+		assert.Equal(t, closeFrame{code: closeUnauthorized, reason: "Unauthorized"}, transport.closeFrame(t))
+	})
+
+	t.Run("too many init requests", func(t *testing.T) {
+		transport := connect(t)
+		transport.in <- `{"type":"connection_init"}`
+		assert.Equal(t, closeTooManyInitRequests, transport.closeFrame(t).code)
+	})
+
+	t.Run("duplicate subscriber", func(t *testing.T) {
+		transport := connect(t)
+		// heroes without names never ends
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"subscription { heroes(names: []) { name } }"}}`
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"subscription { heroes(names: []) { name } }"}}`
+		assert.Equal(t, closeFrame{code: closeSubscriberExists, reason: "Subscriber for 1 already exists"}, transport.closeFrame(t))
+	})
+
+	t.Run("init timeout", func(t *testing.T) {
+		handler := *handler
+		handler.InitTimeout = 10 * time.Millisecond
+		transport := newFakeTransport()
+		go handler.serve(context.Background(), transport, false)
+		assert.Equal(t, closeFrame{code: closeInitTimeout, reason: "Connection initialisation timeout"}, transport.closeFrame(t))
+	})
+
+	t.Run("legacy protocol", func(t *testing.T) {
+		handler := *handler
+		handler.KeepAlive = time.Hour
+		transport := newFakeTransport()
+		go handler.serve(context.Background(), transport, true)
+		transport.in <- `{"type":"connection_init"}`
+		assert.Equal(t, map[string]interface{}{"type": "connection_ack"}, transport.receive(t))
+		assert.Equal(t, map[string]interface{}{"type": "ka"}, transport.receive(t))
+
+		transport.in <- `{"type":"start","id":"1","payload":{"query":"subscription { heroes(names: [\"Luke\"]) { name } }"}}`
+		assert.Equal(t, map[string]interface{}{
+			"type":    "data",
+			"id":      "1",
+			"payload": map[string]interface{}{"data": map[string]interface{}{"heroes": map[string]interface{}{"name": "Luke"}}},
+		}, transport.receive(t))
+		assert.Equal(t, map[string]interface{}{"type": "complete", "id": "1"}, transport.receive(t))
+
+		transport.in <- `{"type":"start","id":"2","payload":{"query":"subscription { villains { name } }"}}`
+		message := transport.receive(t)
+		assert.Equal(t, "data", message["type"])
+		assert.Len(t, message["payload"].(map[string]interface{})["errors"], 1)
+		assert.Equal(t, map[string]interface{}{"type": "complete", "id": "2"}, transport.receive(t))
+
+		transport.in <- `{"type":"unknown","id":"3"}`
+		assert.Equal(t, map[string]interface{}{
+			"type":    "error",
+			"id":      "3",
+			"payload": map[string]interface{}{"message": `invalid message type "unknown"`},
+		}, transport.receive(t))
+
+		transport.in <- `{"type":"start","id":"4","payload":{"query":"subscription { heroes(names: []) { name } }"}}`
+		transport.in <- `{"type":"stop","id":"4"}`
+		transport.in <- `{"type":"connection_terminate"}`
+		assert.Equal(t, closeFrame{code: 1000}, transport.closeFrame(t))
+		assert.Len(t, transport.out, 0)
+	})
+
+	t.Run("init hook", func(t *testing.T) {
+		handler := *handler
+		handler.InitFunc = func(ctx context.Context, payload map[string]interface{}) (context.Context, error) {
+			switch payload["token"] {
+			case "secret":
+				return context.WithValue(ctx, wsUserKey{}, "luke"), nil
+			case nil:
+				return nil, &CloseError{Code: closeUnauthorized, Reason: "Missing token"}
+			default:
+				return nil, fmt.Errorf("invalid token %v", payload["token"])
+			}
+		}
+
+		transport := newFakeTransport()
+		defer close(transport.in)
+		go handler.serve(context.Background(), transport, false)
+		transport.in <- `{"type":"connection_init","payload":{"token":"secret"}}`
+		assert.Equal(t, map[string]interface{}{"type": "connection_ack"}, transport.receive(t))
+		transport.in <- `{"type":"subscribe","id":"1","payload":{"query":"{ me }"}}`
+		assert.Equal(t, map[string]interface{}{
+			"type":    "next",
+			"id":      "1",
+			"payload": map[string]interface{}{"data": map[string]interface{}{"me": "luke"}},
+		}, transport.receive(t))
+
+		transport = newFakeTransport()
+		go handler.serve(context.Background(), transport, false)
+		transport.in <- `{"type":"connection_init","payload":{"token":"guess"}}`
+		assert.Equal(t, closeFrame{code: closeForbidden, reason: "Forbidden"}, transport.closeFrame(t))
+
+		transport = newFakeTransport()
+		go handler.serve(context.Background(), transport, false)
+		transport.in <- `{"type":"connection_init"}`
+		assert.Equal(t, closeFrame{code: closeUnauthorized, reason: "Missing token"}, transport.closeFrame(t))
+
+		transport = newFakeTransport()
+		go handler.serve(context.Background(), transport, true)
+		transport.in <- `{"type":"connection_init","payload":{"token":"guess"}}`
+		assert.Equal(t, map[string]interface{}{
+			"type":    "connection_error",
+			"payload": map[string]interface{}{"message": "Forbidden"},
+		}, transport.receive(t))
+		assert.Equal(t, closeFrame{code: closeForbidden, reason: "Forbidden"}, transport.closeFrame(t))
+	})
+}
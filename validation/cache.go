@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Cache keeps the validation results of the most recently used documents, so the queries sent again and again,
+// eg. persisted queries, are validated once per schema. The results are keyed by the schema, which is immutable
+// once built, and the sha256 hash of the query text: a rebuilt schema, eg. swapped in a SchemaHolder, validates
+// the documents again. It is safe for concurrent use.
+type Cache struct {
+	rules []Rule
+
+	mu  sync.Mutex
+	lru *internal.LRU[cacheKey, errors.MultiError]
+}
+
+type cacheKey struct {
+	schema *internal.Schema
+	hash   [sha256.Size]byte
+}
+
+// NewCache returns a Cache of the results of size documents at most, validated with the SpecifiedRules.
+func NewCache(size int) *Cache {
+	return NewCacheWithRules(size, SpecifiedRules)
+}
+
+// NewCacheWithRules returns a Cache of the results of size documents at most, validated with rules. It is how an
+// executor runs rules other than the specified ones:
+//
+//	rules := append([]validation.Rule{validation.MaxAliases(15)}, validation.SpecifiedRules...)
+//	executor := &execution.Executor{ValidationCache: validation.NewCacheWithRules(1024, rules)}
+func NewCacheWithRules(size int, rules []Rule) *Cache {
+	return &Cache{rules: rules, lru: internal.NewLRU[cacheKey, errors.MultiError](size)}
+}
+
+// Validate returns the violations of doc, the document parsed from query, validating it unless its result is cached.
+func (c *Cache) Validate(schema *internal.Schema, query string, doc *internal.Document) errors.MultiError {
+	key := cacheKey{schema: schema, hash: sha256.Sum256([]byte(query))}
+	c.mu.Lock()
+	if errs, ok := c.lru.Get(key); ok {
+		c.mu.Unlock()
+		return copyErrors(errs)
+	}
+	c.mu.Unlock()
+
+	// concurrent misses of a query validate it each, the results are the same
+	errs := ValidateWithRules(schema, doc, c.rules)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lru.Contains(key) {
+		c.lru.Add(key, errs)
+	}
+	return copyErrors(errs)
+}
+
+// ValidateDocument returns the violations of doc with the rules of the cache, without caching them, eg. for a document
+// whose query is not known.
+func (c *Cache) ValidateDocument(schema *internal.Schema, doc *internal.Document) errors.MultiError {
+	return ValidateWithRules(schema, doc, c.rules)
+}
+
+// Len returns the number of cached results.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// copyErrors copies the cached errors, the callers may modify theirs, eg. to add extensions.
+func copyErrors(errs errors.MultiError) errors.MultiError {
+	if errs == nil {
+		return nil
+	}
+	copied := make(errors.MultiError, len(errs))
+	for i, err := range errs {
+		e := *err
+		if err.Extensions != nil {
+			e.Extensions = make(map[string]interface{}, len(err.Extensions))
+			for key, value := range err.Extensions {
+				e.Extensions[key] = value
+			}
+		}
+		copied[i] = &e
+	}
+	return copied
+}
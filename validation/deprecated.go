@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// NoDeprecated reports the deprecated fields, arguments and enum values used by a document. It is not one of the
+// SpecifiedRules, deprecated elements are still valid: it lints the queries of the clients to drive migrations.
+//
+//	errs := validation.ValidateWithRules(schema, doc, []validation.Rule{validation.NoDeprecated})
+func NoDeprecated(c *Context) {
+	checkArgs := func(args []*ast.Argument, definitions map[string]*internal.InputField, report func(arg *ast.Argument, reason string)) {
+		for _, arg := range args {
+			definition := definitions[arg.Name.Name]
+			if definition == nil {
+				continue
+			}
+			if definition.DeprecationReason != "" {
+				report(arg, definition.DeprecationReason)
+			}
+			c.checkDeprecatedValue(arg.Value, definition.Type)
+		}
+	}
+	for _, op := range c.Document.Operations {
+		for _, v := range op.Vars {
+			if typ := c.TypeFromAST(v.Type); v.DefaultValue != nil && typ != nil {
+				c.checkDeprecatedValue(v.DefaultValue, typ)
+			}
+		}
+	}
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return
+		}
+		definition := FieldDefinition(parent, field.Name.Name)
+		if definition == nil {
+			return
+		}
+		if definition.DeprecationReason != "" {
+			c.Report("NoDeprecated", fieldLoc(field), "The field %s.%s is deprecated. %s", parent.TypeName(),
+				definition.Name, definition.DeprecationReason)
+		}
+		checkArgs(field.Arguments, definition.Args, func(arg *ast.Argument, reason string) {
+			c.Report("NoDeprecated", arg.Loc, "Field \"%s.%s\" argument %q is deprecated. %s", parent.TypeName(),
+				definition.Name, arg.Name.Name, reason)
+		})
+	})
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		for _, d := range directives {
+			if definition := c.Schema.Directives[d.Name.Name]; definition != nil {
+				checkArgs(d.Args, definition.Args, func(arg *ast.Argument, reason string) {
+					c.Report("NoDeprecated", arg.Loc, "Directive \"@%s\" argument %q is deprecated. %s", d.Name.Name,
+						arg.Name.Name, reason)
+				})
+			}
+		}
+	})
+}
+
+// checkDeprecatedValue reports the deprecated enum values of the literal value of type typ.
+func (c *Context) checkDeprecatedValue(value ast.Value, typ internal.Type) {
+	switch nullable := nullableType(typ).(type) {
+	case *internal.List:
+		if list, ok := value.(*ast.ListValue); ok {
+			for _, item := range list.Values {
+				c.checkDeprecatedValue(item, nullable.Type)
+			}
+			return
+		}
+		c.checkDeprecatedValue(value, nullable.Type)
+	case *internal.InputObject:
+		if object, ok := value.(*ast.ObjectValue); ok {
+			for _, field := range object.Fields {
+				if definition := nullable.Fields[field.Name.Name.Name]; definition != nil {
+					c.checkDeprecatedValue(field.Value, definition.Type)
+				}
+			}
+		}
+	case *internal.Enum:
+		if enum, ok := value.(*ast.EnumValue); ok {
+			if reason := nullable.ValuesDeprecation[enum.Value]; reason != "" {
+				c.Report("NoDeprecated", enum.Loc, "The enum value \"%s.%s\" is deprecated. %s", nullable.Name,
+					enum.Value, reason)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// MaxAliases limits the number of aliased fields written in a document, so a request can not select an expensive
+// field a thousand times under different names. It is not one of the SpecifiedRules, a max of zero disables it.
+func MaxAliases(max int) Rule {
+	return func(c *Context) {
+		if max <= 0 {
+			return
+		}
+		var locs []errors.Location
+		c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+			if field, ok := selection.(*ast.Field); ok && field.Alias != nil && field.Alias.Name != field.Name.Name {
+				locs = append(locs, field.Alias.Loc)
+			}
+		})
+		if len(locs) > max {
+			c.Report("MaxAliases", nthLocation(locs, max), "Aliases limit of %d exceeded, found %d.", max, len(locs))
+		}
+	}
+}
+
+// MaxRootFields limits the number of fields selected at the root of an operation, the fields of the fragments
+// spread at the root included, against the batching of many queries in one. A max of zero disables it.
+func MaxRootFields(max int) Rule {
+	return func(c *Context) {
+		if max <= 0 {
+			return
+		}
+		for _, op := range c.Document.Operations {
+			names := make(map[string]struct{})
+			c.collectResponseNames(op.SelectionSet, names, make(map[string]bool))
+			if len(names) <= max {
+				continue
+			}
+			if op.Name != nil {
+				c.Report("MaxRootFields", op.Loc, "Root fields limit of %d exceeded by operation %q, found %d.", max,
+					op.Name.Name, len(names))
+			} else {
+				c.Report("MaxRootFields", op.Loc, "Root fields limit of %d exceeded, found %d.", max, len(names))
+			}
+		}
+	}
+}
+
+// MaxDirectives limits the number of directives written in a document, each of them being resolved for every
+// value of its field. A max of zero disables it.
+func MaxDirectives(max int) Rule {
+	return func(c *Context) {
+		if max <= 0 {
+			return
+		}
+		var locs []errors.Location
+		c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+			for _, d := range directives {
+				locs = append(locs, d.Name.Loc)
+			}
+		})
+		if len(locs) > max {
+			c.Report("MaxDirectives", nthLocation(locs, max), "Directives limit of %d exceeded, found %d.", max, len(locs))
+		}
+	}
+}
+
+// MaxDepth limits the depth of the operations, the fields of their root selection set being at depth 1. The fragments
+// do not add a level, their fields count at the depth where they are spread, like execution.CheckDepth which checks
+// the operations once their variables are known. A max of zero disables it.
+func MaxDepth(max int) Rule {
+	return func(c *Context) {
+		if max <= 0 {
+			return
+		}
+		for _, op := range c.Document.Operations {
+			if depth := c.depth(op.SelectionSet, make(map[string]bool)); depth > max {
+				c.reportOperation("MaxDepth", op, "Depth limit of %d exceeded", max, depth)
+			}
+		}
+	}
+}
+
+// MaxComplexity limits the complexity of the operations, the number of their fields, every field counting for one
+// plus the complexity of its selection set, multiplied by its first, last or limit argument when it is an integer
+// literal, the number of items of a page. A max of zero disables it.
+func MaxComplexity(max int) Rule {
+	return func(c *Context) {
+		if max <= 0 {
+			return
+		}
+		for _, op := range c.Document.Operations {
+			if complexity := c.complexity(op.SelectionSet, make(map[string]bool)); complexity > max {
+				c.reportOperation("MaxComplexity", op, "Complexity limit of %d exceeded", max, complexity)
+			}
+		}
+	}
+}
+
+// reportOperation reports that op exceeds the limit max of rule, by found.
+func (c *Context) reportOperation(rule string, op *ast.OperationDefinition, message string, max, found int) {
+	if op.Name != nil {
+		c.Report(rule, op.Loc, message+" by operation %q, found %d.", max, op.Name.Name, found)
+		return
+	}
+	c.Report(rule, op.Loc, message+", found %d.", max, found)
+}
+
+// depth returns the depth of the fields of selectionSet, following the fragment spreads which are not being
+// followed, so that the cycles reported by NoFragmentCycles end.
+func (c *Context) depth(selectionSet *ast.SelectionSet, following map[string]bool) int {
+	max := 0
+	c.eachSelection(selectionSet, following, func(field *ast.Field) {
+		depth := 1
+		if field.SelectionSet != nil {
+			depth += c.depth(field.SelectionSet, following)
+		}
+		if depth > max {
+			max = depth
+		}
+	}, func(fragment *ast.SelectionSet) {
+		if depth := c.depth(fragment, following); depth > max {
+			max = depth
+		}
+	})
+	return max
+}
+
+// complexity returns the complexity of selectionSet, see MaxComplexity.
+func (c *Context) complexity(selectionSet *ast.SelectionSet, following map[string]bool) int {
+	complexity := 0
+	c.eachSelection(selectionSet, following, func(field *ast.Field) {
+		fieldComplexity := 1
+		if field.SelectionSet != nil {
+			fieldComplexity = addSaturated(fieldComplexity, c.complexity(field.SelectionSet, following))
+		}
+		for _, arg := range field.Arguments {
+			switch arg.Name.Name {
+			case "first", "last", "limit":
+				if value, ok := arg.Value.(*ast.IntValue); ok {
+					if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+						fieldComplexity = mulSaturated(fieldComplexity, n)
+					}
+				}
+			}
+		}
+		complexity = addSaturated(complexity, fieldComplexity)
+	}, func(fragment *ast.SelectionSet) {
+		complexity = addSaturated(complexity, c.complexity(fragment, following))
+	})
+	return complexity
+}
+
+// addSaturated and mulSaturated return math.MaxInt rather than overflowing, so that huge pages can not wrap the
+// complexity around below the limit.
+func addSaturated(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mulSaturated(a, b int) int {
+	if b > 0 && a > math.MaxInt/b {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// eachSelection calls field for the fields of selectionSet and fragment for the selection sets of its inline
+// fragments and of the fragments it spreads which are not being followed.
+func (c *Context) eachSelection(selectionSet *ast.SelectionSet, following map[string]bool, field func(*ast.Field),
+	fragment func(*ast.SelectionSet)) {
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field(selection)
+		case *ast.InlineFragment:
+			fragment(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			name := selection.Name.Name
+			definition := c.fragments[name]
+			if definition == nil || following[name] {
+				continue
+			}
+			following[name] = true
+			fragment(definition.SelectionSet)
+			following[name] = false
+		}
+	}
+}
+
+// nthLocation returns the location at index n of locs in the order of the document, the first one over a limit.
+func nthLocation(locs []errors.Location, n int) errors.Location {
+	sort.Slice(locs, func(i, j int) bool { return locs[i].Before(locs[j]) })
+	return locs[n]
+}
@@ -0,0 +1,379 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// OverlappingFieldsCanBeMerged checks that the fields with the same response name, fragments included, select
+// the same field with the same arguments and have compatible types, so the response is not ambiguous.
+//
+// Like graphql-js, the fields of every selection set and fragment are collected once, and the pairs of fragments,
+// and of fields and fragments, already compared are remembered, so documents spreading the same fragments many
+// times are not compared quadratically over and over.
+func OverlappingFieldsCanBeMerged(c *Context) {
+	o := &overlapChecker{
+		c:                          c,
+		fields:                     make(map[*ast.SelectionSet]*fieldsAndFragments),
+		comparedFragmentPairs:      make(pairSet[[2]string]),
+		comparedFieldsAndFragments: make(pairSet[fieldsAndFragment]),
+	}
+	check := func(parent internal.NamedType, selectionSet *ast.SelectionSet) {
+		for _, conflict := range o.conflictsWithin(parent, selectionSet) {
+			var locs []errors.Location
+			for _, field := range append(conflict.fields1[:len(conflict.fields1):len(conflict.fields1)], conflict.fields2...) {
+				locs = append(locs, fieldLoc(field))
+			}
+			c.ReportLocations("OverlappingFieldsCanBeMerged", locs,
+				"Fields %q conflict because %s. Use different aliases on the fields to fetch both if this was intentional.",
+				conflict.responseName, conflict.message())
+		}
+	}
+	for _, op := range c.Document.Operations {
+		check(c.RootType(op.Operation), op.SelectionSet)
+	}
+	for _, fragment := range c.Document.Fragments {
+		check(c.Schema.TypeMap[fragment.TypeCondition.Name.Name], fragment.SelectionSet)
+	}
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.SelectionSet == nil {
+				return
+			}
+			var typ internal.NamedType
+			if definition := FieldDefinition(parent, selection.Name.Name); definition != nil {
+				typ = NamedType(definition.Type)
+			}
+			check(typ, selection.SelectionSet)
+		case *ast.InlineFragment:
+			condition := parent
+			if selection.TypeCondition != nil {
+				condition = c.Schema.TypeMap[selection.TypeCondition.Name.Name]
+			}
+			check(condition, selection.SelectionSet)
+		}
+	})
+}
+
+// conflict is a pair of fields with the same response name which can not be merged.
+type conflict struct {
+	responseName string
+	// reason is why the fields conflict, empty when they conflict because of their subfields
+	reason       string
+	subconflicts []*conflict
+	// fields1 and fields2 are the fields on both sides of the conflict, their conflicting subfields included
+	fields1, fields2 []*ast.Field
+}
+
+func (c *conflict) message() string {
+	if len(c.subconflicts) == 0 {
+		return c.reason
+	}
+	reasons := make([]string, len(c.subconflicts))
+	for i, sub := range c.subconflicts {
+		reasons[i] = fmt.Sprintf("subfields %q conflict because %s", sub.responseName, sub.message())
+	}
+	return strings.Join(reasons, " and ")
+}
+
+// fieldAndDefinition is a field selected on parent, definition is nil when the field is unknown.
+type fieldAndDefinition struct {
+	parent     internal.NamedType
+	field      *ast.Field
+	definition *internal.Field
+}
+
+// fieldsAndFragments are the fields of a selection set by response name, inline fragments included, along with
+// the names of the fragments it spreads.
+type fieldsAndFragments struct {
+	responseNames []string
+	fields        map[string][]fieldAndDefinition
+	fragmentNames []string
+}
+
+type fieldsAndFragment struct {
+	fields   *fieldsAndFragments
+	fragment string
+}
+
+// pairSet remembers the pairs compared, and whether they were compared as mutually exclusive.
+type pairSet[K comparable] map[K]bool
+
+// has reports whether the pair was compared already: a comparison of fields which are not mutually exclusive
+// covers the mutually exclusive one.
+func (p pairSet[K]) has(key K, mutuallyExclusive bool) bool {
+	result, ok := p[key]
+	if !ok {
+		return false
+	}
+	return mutuallyExclusive || !result
+}
+
+func (p pairSet[K]) add(key K, mutuallyExclusive bool) {
+	p[key] = mutuallyExclusive
+}
+
+func fragmentPair(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+type overlapChecker struct {
+	c *Context
+	// fields caches the fields and fragment names of the selection sets
+	fields                     map[*ast.SelectionSet]*fieldsAndFragments
+	comparedFragmentPairs      pairSet[[2]string]
+	comparedFieldsAndFragments pairSet[fieldsAndFragment]
+}
+
+// conflictsWithin returns the conflicts between the fields of a selection set and the fragments it spreads.
+func (o *overlapChecker) conflictsWithin(parent internal.NamedType, selectionSet *ast.SelectionSet) []*conflict {
+	var conflicts []*conflict
+	fields := o.fieldsAndFragmentNames(parent, selectionSet)
+	for _, name := range fields.responseNames {
+		candidates := fields.fields[name]
+		for i := range candidates {
+			for j := i + 1; j < len(candidates); j++ {
+				if conflict := o.findConflict(false, name, candidates[i], candidates[j]); conflict != nil {
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+	}
+	for i, fragmentName := range fields.fragmentNames {
+		conflicts = o.conflictsBetweenFieldsAndFragment(conflicts, false, fields, fragmentName)
+		for _, other := range fields.fragmentNames[i+1:] {
+			conflicts = o.conflictsBetweenFragments(conflicts, false, fragmentName, other)
+		}
+	}
+	return conflicts
+}
+
+func (o *overlapChecker) conflictsBetweenFieldsAndFragment(conflicts []*conflict, mutuallyExclusive bool,
+	fields *fieldsAndFragments, fragmentName string) []*conflict {
+	key := fieldsAndFragment{fields: fields, fragment: fragmentName}
+	if o.comparedFieldsAndFragments.has(key, mutuallyExclusive) {
+		return conflicts
+	}
+	o.comparedFieldsAndFragments.add(key, mutuallyExclusive)
+	fragment := o.c.Fragment(fragmentName)
+	if fragment == nil {
+		return conflicts
+	}
+	fragmentFields := o.fragmentFields(fragment)
+	if fragmentFields == fields {
+		return conflicts
+	}
+	conflicts = o.conflictsBetween(conflicts, mutuallyExclusive, fields, fragmentFields)
+	for _, name := range fragmentFields.fragmentNames {
+		conflicts = o.conflictsBetweenFieldsAndFragment(conflicts, mutuallyExclusive, fields, name)
+	}
+	return conflicts
+}
+
+func (o *overlapChecker) conflictsBetweenFragments(conflicts []*conflict, mutuallyExclusive bool, name1, name2 string) []*conflict {
+	if name1 == name2 {
+		return conflicts
+	}
+	key := fragmentPair(name1, name2)
+	if o.comparedFragmentPairs.has(key, mutuallyExclusive) {
+		return conflicts
+	}
+	o.comparedFragmentPairs.add(key, mutuallyExclusive)
+	fragment1, fragment2 := o.c.Fragment(name1), o.c.Fragment(name2)
+	if fragment1 == nil || fragment2 == nil {
+		return conflicts
+	}
+	fields1, fields2 := o.fragmentFields(fragment1), o.fragmentFields(fragment2)
+	conflicts = o.conflictsBetween(conflicts, mutuallyExclusive, fields1, fields2)
+	for _, name := range fields2.fragmentNames {
+		conflicts = o.conflictsBetweenFragments(conflicts, mutuallyExclusive, name1, name)
+	}
+	for _, name := range fields1.fragmentNames {
+		conflicts = o.conflictsBetweenFragments(conflicts, mutuallyExclusive, name, name2)
+	}
+	return conflicts
+}
+
+// conflictsBetweenSubSelectionSets returns the conflicts between the subfields of two fields with the same
+// response name.
+func (o *overlapChecker) conflictsBetweenSubSelectionSets(mutuallyExclusive bool, parent1 internal.NamedType,
+	selectionSet1 *ast.SelectionSet, parent2 internal.NamedType, selectionSet2 *ast.SelectionSet) []*conflict {
+	var conflicts []*conflict
+	fields1 := o.fieldsAndFragmentNames(parent1, selectionSet1)
+	fields2 := o.fieldsAndFragmentNames(parent2, selectionSet2)
+	conflicts = o.conflictsBetween(conflicts, mutuallyExclusive, fields1, fields2)
+	for _, name := range fields2.fragmentNames {
+		conflicts = o.conflictsBetweenFieldsAndFragment(conflicts, mutuallyExclusive, fields1, name)
+	}
+	for _, name := range fields1.fragmentNames {
+		conflicts = o.conflictsBetweenFieldsAndFragment(conflicts, mutuallyExclusive, fields2, name)
+	}
+	for _, name1 := range fields1.fragmentNames {
+		for _, name2 := range fields2.fragmentNames {
+			conflicts = o.conflictsBetweenFragments(conflicts, mutuallyExclusive, name1, name2)
+		}
+	}
+	return conflicts
+}
+
+// conflictsBetween adds the conflicts between the fields of two field maps with the same response names.
+func (o *overlapChecker) conflictsBetween(conflicts []*conflict, mutuallyExclusive bool, fields1, fields2 *fieldsAndFragments) []*conflict {
+	for _, name := range fields1.responseNames {
+		others, ok := fields2.fields[name]
+		if !ok {
+			continue
+		}
+		for _, field1 := range fields1.fields[name] {
+			for _, field2 := range others {
+				if conflict := o.findConflict(mutuallyExclusive, name, field1, field2); conflict != nil {
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// findConflict compares two fields with the same response name. The fields of different objects, or nested in
+// such fields, are never both in a response, they only have to have compatible types.
+func (o *overlapChecker) findConflict(parentsMutuallyExclusive bool, responseName string, field1, field2 fieldAndDefinition) *conflict {
+	_, object1 := field1.parent.(*internal.Object)
+	_, object2 := field2.parent.(*internal.Object)
+	mutuallyExclusive := parentsMutuallyExclusive ||
+		(object1 && object2 && field1.parent.TypeName() != field2.parent.TypeName())
+
+	if !mutuallyExclusive {
+		name1, name2 := field1.field.Name.Name, field2.field.Name.Name
+		if name1 != name2 {
+			return &conflict{responseName: responseName, reason: fmt.Sprintf("%q and %q are different fields", name1, name2),
+				fields1: []*ast.Field{field1.field}, fields2: []*ast.Field{field2.field}}
+		}
+		if !sameArguments(field1.field.Arguments, field2.field.Arguments) {
+			return &conflict{responseName: responseName, reason: "they have differing arguments",
+				fields1: []*ast.Field{field1.field}, fields2: []*ast.Field{field2.field}}
+		}
+	}
+
+	var type1, type2 internal.Type
+	if field1.definition != nil {
+		type1 = field1.definition.Type
+	}
+	if field2.definition != nil {
+		type2 = field2.definition.Type
+	}
+	if type1 != nil && type2 != nil && typesConflict(type1, type2) {
+		return &conflict{responseName: responseName,
+			reason:  fmt.Sprintf("they return conflicting types %q and %q", type1.String(), type2.String()),
+			fields1: []*ast.Field{field1.field}, fields2: []*ast.Field{field2.field}}
+	}
+
+	if field1.field.SelectionSet == nil || field2.field.SelectionSet == nil {
+		return nil
+	}
+	subconflicts := o.conflictsBetweenSubSelectionSets(mutuallyExclusive, NamedType(type1), field1.field.SelectionSet,
+		NamedType(type2), field2.field.SelectionSet)
+	if len(subconflicts) == 0 {
+		return nil
+	}
+	result := &conflict{responseName: responseName, subconflicts: subconflicts,
+		fields1: []*ast.Field{field1.field}, fields2: []*ast.Field{field2.field}}
+	for _, sub := range subconflicts {
+		result.fields1 = append(result.fields1, sub.fields1...)
+		result.fields2 = append(result.fields2, sub.fields2...)
+	}
+	return result
+}
+
+func sameArguments(args1, args2 []*ast.Argument) bool {
+	if len(args1) != len(args2) {
+		return false
+	}
+	for _, arg1 := range args1 {
+		found := false
+		for _, arg2 := range args2 {
+			if arg1.Name.Name == arg2.Name.Name {
+				found = printValue(arg1.Value) == printValue(arg2.Value)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// typesConflict reports whether the values of two types can not be merged: lists and non nulls must wrap the
+// same way and leaf types must be the same, the composite types are compared through their subfields.
+func typesConflict(type1, type2 internal.Type) bool {
+	list1, isList1 := type1.(*internal.List)
+	list2, isList2 := type2.(*internal.List)
+	if isList1 || isList2 {
+		return !isList1 || !isList2 || typesConflict(list1.Type, list2.Type)
+	}
+	nonNull1, isNonNull1 := type1.(*internal.NonNull)
+	nonNull2, isNonNull2 := type2.(*internal.NonNull)
+	if isNonNull1 || isNonNull2 {
+		return !isNonNull1 || !isNonNull2 || typesConflict(nonNull1.Type, nonNull2.Type)
+	}
+	if isLeafType(type1) || isLeafType(type2) {
+		return NamedType(type1).TypeName() != NamedType(type2).TypeName()
+	}
+	return false
+}
+
+func (o *overlapChecker) fragmentFields(fragment *ast.FragmentDefinition) *fieldsAndFragments {
+	return o.fieldsAndFragmentNames(o.c.Schema.TypeMap[fragment.TypeCondition.Name.Name], fragment.SelectionSet)
+}
+
+// fieldsAndFragmentNames returns the fields and fragment names of a selection set selected on parent, the
+// first time a selection set is seen its parent is the one its fields are collected with.
+func (o *overlapChecker) fieldsAndFragmentNames(parent internal.NamedType, selectionSet *ast.SelectionSet) *fieldsAndFragments {
+	if fields, ok := o.fields[selectionSet]; ok {
+		return fields
+	}
+	fields := &fieldsAndFragments{fields: make(map[string][]fieldAndDefinition)}
+	seenFragments := make(map[string]bool)
+	var collect func(parent internal.NamedType, selectionSet *ast.SelectionSet)
+	collect = func(parent internal.NamedType, selectionSet *ast.SelectionSet) {
+		if selectionSet == nil {
+			return
+		}
+		for _, selection := range selectionSet.Selections {
+			switch selection := selection.(type) {
+			case *ast.Field:
+				name := responseName(selection)
+				if _, ok := fields.fields[name]; !ok {
+					fields.responseNames = append(fields.responseNames, name)
+				}
+				fields.fields[name] = append(fields.fields[name], fieldAndDefinition{
+					parent:     parent,
+					field:      selection,
+					definition: Fields(parent)[selection.Name.Name],
+				})
+			case *ast.FragmentSpread:
+				if !seenFragments[selection.Name.Name] {
+					seenFragments[selection.Name.Name] = true
+					fields.fragmentNames = append(fields.fragmentNames, selection.Name.Name)
+				}
+			case *ast.InlineFragment:
+				condition := parent
+				if selection.TypeCondition != nil {
+					condition = o.c.Schema.TypeMap[selection.TypeCondition.Name.Name]
+				}
+				collect(condition, selection.SelectionSet)
+			}
+		}
+	}
+	collect(parent, selectionSet)
+	o.fields[selectionSet] = fields
+	return fields
+}
@@ -0,0 +1,469 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// UniqueOperationNames checks that the named operations of a document have different names.
+func UniqueOperationNames(c *Context) {
+	known := make(map[string]*ast.Name)
+	for _, op := range c.Document.Operations {
+		if op.Name == nil {
+			continue
+		}
+		if first, ok := known[op.Name.Name]; ok {
+			c.ReportLocations("UniqueOperationNames", []errors.Location{first.Loc, op.Name.Loc},
+				"There can be only one operation named %q.", op.Name.Name)
+			continue
+		}
+		known[op.Name.Name] = op.Name
+	}
+}
+
+// LoneAnonymousOperation checks that an anonymous operation is the only operation of its document.
+func LoneAnonymousOperation(c *Context) {
+	if len(c.Document.Operations) < 2 {
+		return
+	}
+	for _, op := range c.Document.Operations {
+		if op.Name == nil {
+			c.Report("LoneAnonymousOperation", op.Loc, "This anonymous operation must be the only defined operation.")
+		}
+	}
+}
+
+// SingleFieldSubscriptions checks that subscriptions select exactly one root field, fragments included.
+func SingleFieldSubscriptions(c *Context) {
+	for _, op := range c.Document.Operations {
+		if op.Operation != ast.Subscription {
+			continue
+		}
+		names := make(map[string]struct{})
+		c.collectResponseNames(op.SelectionSet, names, make(map[string]bool))
+		if len(names) == 1 {
+			continue
+		}
+		if op.Name != nil {
+			c.Report("SingleFieldSubscriptions", op.Loc, "Subscription %q must select only one top level field.", op.Name.Name)
+		} else {
+			c.Report("SingleFieldSubscriptions", op.Loc, "Anonymous Subscription must select only one top level field.")
+		}
+	}
+}
+
+// collectResponseNames adds the response names of the fields of selectionSet to names, without descending into
+// the fields.
+func (c *Context) collectResponseNames(selectionSet *ast.SelectionSet, names map[string]struct{}, visited map[string]bool) {
+	if selectionSet == nil {
+		return
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			names[responseName(selection)] = struct{}{}
+		case *ast.InlineFragment:
+			c.collectResponseNames(selection.SelectionSet, names, visited)
+		case *ast.FragmentSpread:
+			name := selection.Name.Name
+			if fragment := c.Fragment(name); fragment != nil && !visited[name] {
+				visited[name] = true
+				c.collectResponseNames(fragment.SelectionSet, names, visited)
+			}
+		}
+	}
+}
+
+// KnownTypeNames checks that the types named by variable definitions and type conditions are defined by the schema.
+func KnownTypeNames(c *Context) {
+	var typeNames []string
+	check := func(loc errors.Location, named *ast.Named) {
+		if named == nil {
+			return
+		}
+		if _, ok := c.Schema.TypeMap[named.Name.Name]; ok {
+			return
+		}
+		if typeNames == nil {
+			typeNames = sortedKeys(c.Schema.TypeMap)
+		}
+		c.Report("KnownTypeNames", loc, "Unknown type %q.%s", named.Name.Name, makeSuggestion("Did you mean", typeNames, named.Name.Name))
+	}
+	// the unknown types of variables are reported at the variables, as their invalid values are
+	for _, op := range c.Document.Operations {
+		for _, v := range op.Vars {
+			check(v.Loc, astNamedType(v.Type))
+		}
+	}
+	for _, fragment := range c.Document.Fragments {
+		for _, v := range fragment.VariableDefinitions {
+			check(v.Loc, astNamedType(v.Type))
+		}
+		check(fragment.TypeCondition.Loc, fragment.TypeCondition)
+	}
+	c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+		if fragment, ok := selection.(*ast.InlineFragment); ok && fragment.TypeCondition != nil {
+			check(fragment.TypeCondition.Loc, fragment.TypeCondition)
+		}
+	})
+}
+
+// FragmentsOnCompositeTypes checks that fragments are conditioned on objects, interfaces or unions.
+func FragmentsOnCompositeTypes(c *Context) {
+	for _, fragment := range c.Document.Fragments {
+		typ, ok := c.Schema.TypeMap[fragment.TypeCondition.Name.Name]
+		if ok && !isCompositeType(typ) {
+			c.Report("FragmentsOnCompositeTypes", fragment.TypeCondition.Loc,
+				"Fragment %q cannot condition on non composite type %q.", fragment.Name.Name, typ.TypeName())
+		}
+	}
+	c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+		fragment, ok := selection.(*ast.InlineFragment)
+		if !ok || fragment.TypeCondition == nil {
+			return
+		}
+		typ, ok := c.Schema.TypeMap[fragment.TypeCondition.Name.Name]
+		if ok && !isCompositeType(typ) {
+			c.Report("FragmentsOnCompositeTypes", fragment.TypeCondition.Loc,
+				"Fragment cannot condition on non composite type %q.", typ.TypeName())
+		}
+	})
+}
+
+// ScalarLeafs checks that the fields of leaf types have no selection set and the other fields have one.
+func ScalarLeafs(c *Context) {
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return
+		}
+		definition := FieldDefinition(parent, field.Name.Name)
+		if definition == nil {
+			return
+		}
+		if isLeafType(NamedType(definition.Type)) {
+			if field.SelectionSet != nil {
+				c.Report("ScalarLeafs", fieldLoc(field), "Field %q must not have a selection since type %q has no subfields.",
+					field.Name.Name, definition.Type.String())
+			}
+		} else if field.SelectionSet == nil {
+			c.Report("ScalarLeafs", fieldLoc(field), "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?",
+				field.Name.Name, definition.Type.String(), field.Name.Name)
+		}
+	})
+}
+
+// FieldsOnCorrectType checks that the selected fields are defined by the type they are selected on.
+func FieldsOnCorrectType(c *Context) {
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		field, ok := selection.(*ast.Field)
+		if !ok || !isCompositeType(parent) || FieldDefinition(parent, field.Name.Name) != nil {
+			return
+		}
+		suggestion := makeSuggestion("Did you mean", sortedKeys(Fields(parent)), field.Name.Name)
+		c.Report("FieldsOnCorrectType", fieldLoc(field), "Cannot query field %q on type %q.%s", field.Name.Name, parent.TypeName(), suggestion)
+	})
+}
+
+// UniqueFragmentNames checks that the fragment definitions of a document have different names.
+func UniqueFragmentNames(c *Context) {
+	known := make(map[string]*ast.Name)
+	for _, fragment := range c.Document.Fragments {
+		if first, ok := known[fragment.Name.Name]; ok {
+			c.ReportLocations("UniqueFragmentNames", []errors.Location{first.Loc, fragment.Name.Loc},
+				"There can be only one fragment named %q.", fragment.Name.Name)
+			continue
+		}
+		known[fragment.Name.Name] = fragment.Name
+	}
+}
+
+// KnownFragmentNames checks that the spread fragments are defined by the document.
+func KnownFragmentNames(c *Context) {
+	c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+		if spread, ok := selection.(*ast.FragmentSpread); ok && c.Fragment(spread.Name.Name) == nil {
+			c.Report("KnownFragmentNames", spread.Name.Loc, "Unknown fragment %q.", spread.Name.Name)
+		}
+	})
+}
+
+// NoUnusedFragments checks that every fragment definition is used by an operation.
+func NoUnusedFragments(c *Context) {
+	used := make(map[string]bool)
+	for _, op := range c.Document.Operations {
+		for _, fragment := range c.RecursiveFragments(op.SelectionSet) {
+			used[fragment.Name.Name] = true
+		}
+	}
+	for _, fragment := range c.Document.Fragments {
+		if !used[fragment.Name.Name] {
+			c.Report("NoUnusedFragments", fragment.Loc, "Fragment %q is never used.", fragment.Name.Name)
+		}
+	}
+}
+
+// PossibleFragmentSpreads checks that fragments are spread where their type condition can apply.
+func PossibleFragmentSpreads(c *Context) {
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		if !isCompositeType(parent) {
+			return
+		}
+		switch selection := selection.(type) {
+		case *ast.InlineFragment:
+			if selection.TypeCondition == nil {
+				return
+			}
+			typ := c.Schema.TypeMap[selection.TypeCondition.Name.Name]
+			if isCompositeType(typ) && !typesOverlap(parent, typ) {
+				c.Report("PossibleFragmentSpreads", selection.Loc,
+					"Fragment cannot be spread here as objects of type %q can never be of type %q.", parent.TypeName(), typ.TypeName())
+			}
+		case *ast.FragmentSpread:
+			fragment := c.Fragment(selection.Name.Name)
+			if fragment == nil {
+				return
+			}
+			typ := c.Schema.TypeMap[fragment.TypeCondition.Name.Name]
+			if isCompositeType(typ) && !typesOverlap(parent, typ) {
+				c.Report("PossibleFragmentSpreads", selection.Loc,
+					"Fragment %q cannot be spread here as objects of type %q can never be of type %q.",
+					selection.Name.Name, parent.TypeName(), typ.TypeName())
+			}
+		}
+	})
+}
+
+// possibleTypes returns the names of the objects a composite type can be.
+func possibleTypes(typ internal.NamedType) map[string]bool {
+	types := make(map[string]bool)
+	switch typ := typ.(type) {
+	case *internal.Object:
+		types[typ.Name] = true
+	case *internal.Interface:
+		for name := range typ.PossibleTypes {
+			types[name] = true
+		}
+	case *internal.Union:
+		for name := range typ.Types {
+			types[name] = true
+		}
+	}
+	return types
+}
+
+func typesOverlap(a, b internal.NamedType) bool {
+	if a.TypeName() == b.TypeName() {
+		return true
+	}
+	possibleA, possibleB := possibleTypes(a), possibleTypes(b)
+	if len(possibleA) == 0 || len(possibleB) == 0 {
+		// the implementations of an interface are unknown until an object implementing it is built
+		return true
+	}
+	for name := range possibleA {
+		if possibleB[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// NoFragmentCycles checks that fragments do not spread themselves, directly or through other fragments.
+func NoFragmentCycles(c *Context) {
+	visited := make(map[string]bool)
+	// path is the chain of spreads being followed, pathIndex the position in it of the fragments it goes through
+	var path []*ast.FragmentSpread
+	pathIndex := make(map[string]int)
+	var detect func(fragment *ast.FragmentDefinition)
+	detect = func(fragment *ast.FragmentDefinition) {
+		name := fragment.Name.Name
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		spreads := FragmentSpreads(fragment.SelectionSet)
+		if len(spreads) == 0 {
+			return
+		}
+		pathIndex[name] = len(path)
+		for _, spread := range spreads {
+			index, cycle := pathIndex[spread.Name.Name]
+			path = append(path, spread)
+			if !cycle {
+				if next := c.Fragment(spread.Name.Name); next != nil {
+					detect(next)
+				}
+			} else {
+				spreads := path[index:]
+				locs := make([]errors.Location, len(spreads))
+				via := make([]string, 0, len(spreads)-1)
+				for i, s := range spreads {
+					locs[i] = s.Loc
+					if i < len(spreads)-1 {
+						via = append(via, strconv.Quote(s.Name.Name))
+					}
+				}
+				message := fmt.Sprintf("Cannot spread fragment %q within itself", spread.Name.Name)
+				if len(via) > 0 {
+					message += " via " + strings.Join(via, ", ")
+				}
+				c.ReportLocations("NoFragmentCycles", locs, "%s.", message)
+			}
+			path = path[:len(path)-1]
+		}
+		delete(pathIndex, name)
+	}
+	for _, fragment := range c.Document.Fragments {
+		detect(fragment)
+	}
+}
+
+// KnownDirectives checks that the directives are defined by the schema and used at one of their locations.
+func KnownDirectives(c *Context) {
+	c.VisitDirectives(func(location string, directives []*ast.Directive) {
+		for _, d := range directives {
+			definition, ok := c.Schema.Directives[d.Name.Name]
+			if !ok {
+				c.Report("KnownDirectives", d.Name.Loc, "Unknown directive %q.", d.Name.Name)
+				continue
+			}
+			allowed := false
+			for _, loc := range definition.Locs {
+				if loc == location {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				c.Report("KnownDirectives", d.Name.Loc, "Directive %q may not be used on %s.", d.Name.Name, location)
+			}
+		}
+	})
+}
+
+// UniqueDirectivesPerLocation checks that a directive is used once at most at each location.
+func UniqueDirectivesPerLocation(c *Context) {
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		known := make(map[string]*ast.Directive)
+		for _, d := range directives {
+			if first, ok := known[d.Name.Name]; ok {
+				c.ReportLocations("UniqueDirectivesPerLocation", []errors.Location{first.Loc, d.Loc},
+					"The directive %q can only be used once at this location.", d.Name.Name)
+				continue
+			}
+			known[d.Name.Name] = d
+		}
+	})
+}
+
+// KnownArgumentNames checks that the arguments of fields and directives are defined by them.
+func KnownArgumentNames(c *Context) {
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return
+		}
+		definition := FieldDefinition(parent, field.Name.Name)
+		if definition == nil {
+			return
+		}
+		for _, arg := range field.Arguments {
+			if _, ok := definition.Args[arg.Name.Name]; !ok {
+				c.Report("KnownArgumentNames", arg.Loc, "Unknown argument %q on field \"%s.%s\".%s", arg.Name.Name,
+					parent.TypeName(), field.Name.Name, makeSuggestion("Did you mean", sortedKeys(definition.Args), arg.Name.Name))
+			}
+		}
+	})
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		for _, d := range directives {
+			definition, ok := c.Schema.Directives[d.Name.Name]
+			if !ok {
+				continue
+			}
+			for _, arg := range d.Args {
+				if _, ok := definition.Args[arg.Name.Name]; !ok {
+					c.Report("KnownArgumentNames", arg.Loc, "Unknown argument %q on directive \"@%s\".%s", arg.Name.Name,
+						d.Name.Name, makeSuggestion("Did you mean", sortedKeys(definition.Args), arg.Name.Name))
+				}
+			}
+		}
+	})
+}
+
+// UniqueArgumentNames checks that an argument is given once at most to a field or a directive.
+func UniqueArgumentNames(c *Context) {
+	check := func(args []*ast.Argument) {
+		known := make(map[string]*ast.Argument)
+		for _, arg := range args {
+			if first, ok := known[arg.Name.Name]; ok {
+				c.ReportLocations("UniqueArgumentNames", []errors.Location{first.Name.Loc, arg.Name.Loc},
+					"There can be only one argument named %q.", arg.Name.Name)
+				continue
+			}
+			known[arg.Name.Name] = arg
+		}
+	}
+	c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+		if field, ok := selection.(*ast.Field); ok {
+			check(field.Arguments)
+		}
+	})
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		for _, d := range directives {
+			check(d.Args)
+		}
+	})
+}
+
+// ProvidedRequiredArguments checks that the non-null arguments without a default value are given.
+func ProvidedRequiredArguments(c *Context) {
+	missing := func(definitions map[string]*internal.InputField, args []*ast.Argument, report func(name string, arg *internal.InputField)) {
+		for _, name := range sortedKeys(definitions) {
+			definition := definitions[name]
+			if _, ok := definition.Type.(*internal.NonNull); !ok || definition.DefaultValue != nil {
+				continue
+			}
+			provided := false
+			for _, arg := range args {
+				if arg.Name.Name == name {
+					provided = true
+					break
+				}
+			}
+			if !provided {
+				report(name, definition)
+			}
+		}
+	}
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return
+		}
+		definition := FieldDefinition(parent, field.Name.Name)
+		if definition == nil {
+			return
+		}
+		missing(definition.Args, field.Arguments, func(name string, arg *internal.InputField) {
+			c.Report("ProvidedRequiredArguments", fieldLoc(field), "Field %q argument %q of type %q is required, but it was not provided.",
+				field.Name.Name, name, arg.Type.String())
+		})
+	})
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		for _, d := range directives {
+			definition, ok := c.Schema.Directives[d.Name.Name]
+			if !ok {
+				continue
+			}
+			missing(definition.Args, d.Args, func(name string, arg *internal.InputField) {
+				c.Report("ProvidedRequiredArguments", d.Loc, "Directive \"@%s\" argument %q of type %q is required, but it was not provided.",
+					d.Name.Name, name, arg.Type.String())
+			})
+		}
+	})
+}
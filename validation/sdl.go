@@ -0,0 +1,633 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+)
+
+// SDLRule checks a type system document, reporting its violations to the context.
+type SDLRule func(c *SDLContext)
+
+// SpecifiedSDLRules are the rules checking that the definitions and extensions of a type system document make
+// a valid schema.
+var SpecifiedSDLRules = []SDLRule{
+	LoneSchemaDefinition,
+	UniqueOperationTypes,
+	UniqueTypeNames,
+	UniqueEnumValueNames,
+	UniqueFieldDefinitionNames,
+	UniqueArgumentDefinitionNames,
+	UniqueDirectiveNames,
+	KnownTypeNamesInSDL,
+	KnownDirectiveLocations,
+	KnownDirectivesInSDL,
+	UniqueDirectivesPerLocationInSDL,
+	PossibleTypeExtensions,
+	ValidInterfaceImplementations,
+}
+
+// ValidateSDL checks a type system document, eg. parsed with internal.ParseDocument, with the SpecifiedSDLRules
+// and returns all its violations, nil when its definitions make a valid schema. No schema is built: the
+// documents of the schemas which are built elsewhere, eg. by a gateway, can be checked on their own.
+func ValidateSDL(doc *ast.Document) errors.MultiError {
+	return ValidateSDLWithRules(doc, SpecifiedSDLRules)
+}
+
+// ValidateSDLWithRules checks a type system document with rules and returns the violations, in the order of the
+// rules.
+func ValidateSDLWithRules(doc *ast.Document, rules []SDLRule) errors.MultiError {
+	c := newSDLContext(doc)
+	for _, rule := range rules {
+		rule(c)
+	}
+	return c.errs
+}
+
+// SDLContext is the type system document being validated, along with what the rules share about it.
+type SDLContext struct {
+	Document *ast.Document
+
+	errs errors.MultiError
+	// types are the type definitions and extensions of the document in the order of the source
+	types []*sdlType
+	// definitions are the type definitions by name, the first one of a name wins
+	definitions map[string]*sdlType
+	// byName are the type definitions and extensions by name
+	byName map[string][]*sdlType
+	// directives are the directive definitions by name, the built-in ones included
+	directives map[string]*ast.DirectiveDefinition
+}
+
+// sdlType is a type definition or extension, with the parts of it the rules check.
+type sdlType struct {
+	// kind is the keyword of the definition, eg. type or input
+	kind        string
+	name        *ast.Name
+	extension   bool
+	directives  []*ast.Directive
+	interfaces  []*ast.Named
+	fields      []*ast.FieldDefinition
+	members     []*ast.Named
+	values      []*ast.EnumValueDefinition
+	inputFields []*ast.InputValueDefinition
+}
+
+// location is where the directives of the type are used.
+func (t *sdlType) location() string {
+	return map[string]string{
+		"scalar":    "SCALAR",
+		"type":      "OBJECT",
+		"interface": "INTERFACE",
+		"union":     "UNION",
+		"enum":      "ENUM",
+		"input":     "INPUT_OBJECT",
+	}[t.kind]
+}
+
+var builtinScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+var builtinDirectives = map[string]*ast.DirectiveDefinition{
+	"skip":        {Name: &ast.Name{Name: "skip"}, Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}},
+	"include":     {Name: &ast.Name{Name: "include"}, Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}},
+	"deprecated":  {Name: &ast.Name{Name: "deprecated"}, Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"}},
+	"specifiedBy": {Name: &ast.Name{Name: "specifiedBy"}, Locations: []string{"SCALAR"}},
+	"oneOf":       {Name: &ast.Name{Name: "oneOf"}, Locations: []string{"INPUT_OBJECT"}},
+}
+
+var directiveLocations = []string{
+	"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT",
+	"VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INTERFACE",
+	"UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION",
+}
+
+func newSDLContext(doc *ast.Document) *SDLContext {
+	c := &SDLContext{
+		Document:    doc,
+		definitions: make(map[string]*sdlType),
+		byName:      make(map[string][]*sdlType),
+		directives:  make(map[string]*ast.DirectiveDefinition),
+	}
+	for name, definition := range builtinDirectives {
+		c.directives[name] = definition
+	}
+	for _, definition := range doc.Definition {
+		var t *sdlType
+		switch d := definition.(type) {
+		case *ast.ScalarDefinition:
+			t = &sdlType{kind: "scalar", name: d.Name, directives: d.Directives}
+		case *ast.ScalarExtension:
+			t = &sdlType{kind: "scalar", name: d.Name, extension: true, directives: d.Directives}
+		case *ast.ObjectDefinition:
+			t = &sdlType{kind: "type", name: d.Name, directives: d.Directives, interfaces: d.Interfaces, fields: d.Fields}
+		case *ast.ObjectExtension:
+			t = &sdlType{kind: "type", name: d.Name, extension: true, directives: d.Directives, interfaces: d.Interfaces, fields: d.Fields}
+		case *ast.InterfaceDefinition:
+			t = &sdlType{kind: "interface", name: d.Name, directives: d.Directives, interfaces: d.Interfaces, fields: d.Fields}
+		case *ast.InterfaceExtension:
+			t = &sdlType{kind: "interface", name: d.Name, extension: true, directives: d.Directives, interfaces: d.Interfaces, fields: d.Fields}
+		case *ast.UnionDefinition:
+			t = &sdlType{kind: "union", name: d.Name, directives: d.Directives, members: d.Members}
+		case *ast.UnionExtension:
+			t = &sdlType{kind: "union", name: d.Name, extension: true, directives: d.Directives, members: d.Members}
+		case *ast.EnumDefinition:
+			t = &sdlType{kind: "enum", name: d.Name, directives: d.Directives, values: d.Values}
+		case *ast.EnumExtension:
+			t = &sdlType{kind: "enum", name: d.Name, extension: true, directives: d.Directives, values: d.Values}
+		case *ast.InputObjectDefinition:
+			t = &sdlType{kind: "input", name: d.Name, directives: d.Directives, inputFields: d.InputFields}
+		case *ast.InputObjectExtension:
+			t = &sdlType{kind: "input", name: d.Name, extension: true, directives: d.Directives, inputFields: d.InputFields}
+		case *ast.DirectiveDefinition:
+			// the first definition of a name wins, over the built-in one too
+			if existing := c.directives[d.Name.Name]; existing == nil || existing == builtinDirectives[d.Name.Name] {
+				c.directives[d.Name.Name] = d
+			}
+			continue
+		default:
+			continue
+		}
+		c.types = append(c.types, t)
+		c.byName[t.name.Name] = append(c.byName[t.name.Name], t)
+		if _, ok := c.definitions[t.name.Name]; !ok && !t.extension {
+			c.definitions[t.name.Name] = t
+		}
+	}
+	return c
+}
+
+// Report adds a violation of rule at loc.
+func (c *SDLContext) Report(rule string, loc errors.Location, format string, args ...interface{}) {
+	c.ReportLocations(rule, []errors.Location{loc}, format, args...)
+}
+
+// ReportLocations adds a violation of rule involving several parts of the document.
+func (c *SDLContext) ReportLocations(rule string, locs []errors.Location, format string, args ...interface{}) {
+	c.errs = append(c.errs, &errors.GraphQLError{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: locs,
+		Rule:      rule,
+	})
+}
+
+// typeNames returns the names of the types defined by the document and of the built-in scalars.
+func (c *SDLContext) typeNames() []string {
+	names := make(map[string]bool, len(c.definitions)+len(builtinScalars))
+	for name := range c.definitions {
+		names[name] = true
+	}
+	for name := range builtinScalars {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
+
+// fieldsOf returns the fields of a type, those of its extensions included.
+func (c *SDLContext) fieldsOf(name string) []*ast.FieldDefinition {
+	var fields []*ast.FieldDefinition
+	for _, t := range c.byName[name] {
+		fields = append(fields, t.fields...)
+	}
+	return fields
+}
+
+func (c *SDLContext) interfacesOf(name string) []*ast.Named {
+	var interfaces []*ast.Named
+	for _, t := range c.byName[name] {
+		interfaces = append(interfaces, t.interfaces...)
+	}
+	return interfaces
+}
+
+// visitSDLDirectives calls visit for the directives used in the document, with their location.
+func (c *SDLContext) visitSDLDirectives(visit func(location string, directives []*ast.Directive)) {
+	for _, definition := range c.Document.Definition {
+		switch d := definition.(type) {
+		case *ast.SchemaDefinition:
+			visit("SCHEMA", d.Directives)
+		case *ast.SchemaExtension:
+			visit("SCHEMA", d.Directives)
+		case *ast.DirectiveDefinition:
+			for _, arg := range d.Arguments {
+				visit("ARGUMENT_DEFINITION", arg.Directives)
+			}
+		}
+	}
+	for _, t := range c.types {
+		visit(t.location(), t.directives)
+		for _, field := range t.fields {
+			visit("FIELD_DEFINITION", field.Directives)
+			for _, arg := range field.Argument {
+				visit("ARGUMENT_DEFINITION", arg.Directives)
+			}
+		}
+		for _, value := range t.values {
+			visit("ENUM_VALUE", value.Directives)
+		}
+		for _, field := range t.inputFields {
+			visit("INPUT_FIELD_DEFINITION", field.Directives)
+		}
+	}
+}
+
+// LoneSchemaDefinition checks that the schema is defined once at most.
+func LoneSchemaDefinition(c *SDLContext) {
+	defined := false
+	for _, definition := range c.Document.Definition {
+		if schema, ok := definition.(*ast.SchemaDefinition); ok {
+			if defined {
+				c.Report("LoneSchemaDefinition", schema.Loc, "Must provide only one schema definition.")
+			}
+			defined = true
+		}
+	}
+}
+
+// UniqueOperationTypes checks that the root type of an operation type is defined once, extensions of the
+// schema included.
+func UniqueOperationTypes(c *SDLContext) {
+	defined := make(map[ast.OperationType]*ast.OperationTypeDefinition)
+	for _, definition := range c.Document.Definition {
+		var operationTypes []*ast.OperationTypeDefinition
+		switch d := definition.(type) {
+		case *ast.SchemaDefinition:
+			operationTypes = d.OperationTypes
+		case *ast.SchemaExtension:
+			operationTypes = d.RootOperation
+		}
+		for _, operationType := range operationTypes {
+			if first, ok := defined[operationType.Operation]; ok {
+				c.ReportLocations("UniqueOperationTypes", []errors.Location{first.Loc, operationType.Loc},
+					"There can be only one %s type in schema.", strings.ToLower(string(operationType.Operation)))
+				continue
+			}
+			defined[operationType.Operation] = operationType
+		}
+	}
+}
+
+// UniqueTypeNames checks that the names of the type definitions are unique.
+func UniqueTypeNames(c *SDLContext) {
+	for _, t := range c.types {
+		if first := c.definitions[t.name.Name]; !t.extension && first != t {
+			c.ReportLocations("UniqueTypeNames", []errors.Location{first.name.Loc, t.name.Loc},
+				"There can be only one type named %q.", t.name.Name)
+		}
+	}
+}
+
+// UniqueEnumValueNames checks that the values of an enum, extensions included, are unique.
+func UniqueEnumValueNames(c *SDLContext) {
+	for _, name := range sortedKeys(c.byName) {
+		defined := make(map[string]*ast.EnumValueDefinition)
+		for _, t := range c.byName[name] {
+			for _, value := range t.values {
+				if first, ok := defined[value.Value.Value]; ok {
+					c.ReportLocations("UniqueEnumValueNames", []errors.Location{first.Loc, value.Loc},
+						"Enum value \"%s.%s\" can only be defined once.", name, value.Value.Value)
+					continue
+				}
+				defined[value.Value.Value] = value
+			}
+		}
+	}
+}
+
+// UniqueFieldDefinitionNames checks that the fields of a type or input object, extensions included, are unique.
+func UniqueFieldDefinitionNames(c *SDLContext) {
+	for _, name := range sortedKeys(c.byName) {
+		defined := make(map[string]*ast.Name)
+		check := func(field *ast.Name) {
+			if first, ok := defined[field.Name]; ok {
+				c.ReportLocations("UniqueFieldDefinitionNames", []errors.Location{first.Loc, field.Loc},
+					"Field \"%s.%s\" can only be defined once.", name, field.Name)
+				return
+			}
+			defined[field.Name] = field
+		}
+		for _, t := range c.byName[name] {
+			for _, field := range t.fields {
+				check(field.Name)
+			}
+			for _, field := range t.inputFields {
+				check(field.Name)
+			}
+		}
+	}
+}
+
+// UniqueArgumentDefinitionNames checks that the arguments of a field or directive definition are unique.
+func UniqueArgumentDefinitionNames(c *SDLContext) {
+	check := func(args []*ast.InputValueDefinition, format string, names ...interface{}) {
+		defined := make(map[string]*ast.InputValueDefinition)
+		for _, arg := range args {
+			if first, ok := defined[arg.Name.Name]; ok {
+				c.ReportLocations("UniqueArgumentDefinitionNames", []errors.Location{first.Name.Loc, arg.Name.Loc},
+					format, append(names, arg.Name.Name)...)
+				continue
+			}
+			defined[arg.Name.Name] = arg
+		}
+	}
+	for _, t := range c.types {
+		for _, field := range t.fields {
+			check(field.Argument, "Argument \"%s.%s(%s:)\" can only be defined once.", t.name.Name, field.Name.Name)
+		}
+	}
+	for _, definition := range c.Document.Definition {
+		if d, ok := definition.(*ast.DirectiveDefinition); ok {
+			check(d.Arguments, "Argument \"@%s(%s:)\" can only be defined once.", d.Name.Name)
+		}
+	}
+}
+
+// UniqueDirectiveNames checks that the directives are defined once and do not redefine the built-in ones.
+func UniqueDirectiveNames(c *SDLContext) {
+	defined := make(map[string]*ast.DirectiveDefinition)
+	for _, definition := range c.Document.Definition {
+		d, ok := definition.(*ast.DirectiveDefinition)
+		if !ok {
+			continue
+		}
+		if first, ok := defined[d.Name.Name]; ok {
+			c.ReportLocations("UniqueDirectiveNames", []errors.Location{first.Name.Loc, d.Name.Loc},
+				"There can be only one directive named \"@%s\".", d.Name.Name)
+			continue
+		}
+		defined[d.Name.Name] = d
+		if builtinDirectives[d.Name.Name] != nil {
+			c.Report("UniqueDirectiveNames", d.Name.Loc, "Directive \"@%s\" already exists in the schema. It cannot be redefined.",
+				d.Name.Name)
+		}
+	}
+}
+
+// KnownTypeNamesInSDL checks that the types referenced by the document are defined by it or built in.
+func KnownTypeNamesInSDL(c *SDLContext) {
+	check := func(typ ast.Type) {
+		named := astNamedType(typ)
+		if named == nil || builtinScalars[named.Name.Name] || c.definitions[named.Name.Name] != nil {
+			return
+		}
+		c.Report("KnownTypeNamesInSDL", named.Name.Loc, "Unknown type %q.%s", named.Name.Name,
+			makeSuggestion("Did you mean", c.typeNames(), named.Name.Name))
+	}
+	checkArgs := func(args []*ast.InputValueDefinition) {
+		for _, arg := range args {
+			check(arg.Type)
+		}
+	}
+	for _, definition := range c.Document.Definition {
+		switch d := definition.(type) {
+		case *ast.SchemaDefinition:
+			for _, operationType := range d.OperationTypes {
+				check(operationType.Type)
+			}
+		case *ast.SchemaExtension:
+			for _, operationType := range d.RootOperation {
+				check(operationType.Type)
+			}
+		case *ast.DirectiveDefinition:
+			checkArgs(d.Arguments)
+		}
+	}
+	for _, t := range c.types {
+		for _, named := range t.interfaces {
+			check(named)
+		}
+		for _, named := range t.members {
+			check(named)
+		}
+		for _, field := range t.fields {
+			checkArgs(field.Argument)
+			check(field.Type)
+		}
+		checkArgs(t.inputFields)
+	}
+}
+
+// KnownDirectiveLocations checks the locations of the directive definitions.
+func KnownDirectiveLocations(c *SDLContext) {
+	known := make(map[string]bool, len(directiveLocations))
+	for _, location := range directiveLocations {
+		known[location] = true
+	}
+	for _, definition := range c.Document.Definition {
+		d, ok := definition.(*ast.DirectiveDefinition)
+		if !ok {
+			continue
+		}
+		for _, location := range d.Locations {
+			if !known[location] {
+				c.Report("KnownDirectiveLocations", d.Loc, "Unknown directive location %q on \"@%s\".%s", location, d.Name.Name,
+					makeSuggestion("Did you mean", directiveLocations, location))
+			}
+		}
+	}
+}
+
+// KnownDirectivesInSDL checks that the directives used by the document are defined, by it or built in, and are
+// used where they are allowed.
+func KnownDirectivesInSDL(c *SDLContext) {
+	c.visitSDLDirectives(func(location string, directives []*ast.Directive) {
+		for _, d := range directives {
+			definition, ok := c.directives[d.Name.Name]
+			if !ok {
+				c.Report("KnownDirectivesInSDL", d.Name.Loc, "Unknown directive %q.", d.Name.Name)
+				continue
+			}
+			allowed := false
+			for _, l := range definition.Locations {
+				allowed = allowed || l == location
+			}
+			if !allowed {
+				c.Report("KnownDirectivesInSDL", d.Name.Loc, "Directive %q may not be used on %s.", d.Name.Name, location)
+			}
+		}
+	})
+}
+
+// UniqueDirectivesPerLocationInSDL checks that the directives which are not repeatable are used once at
+// a location.
+func UniqueDirectivesPerLocationInSDL(c *SDLContext) {
+	c.visitSDLDirectives(func(_ string, directives []*ast.Directive) {
+		seen := make(map[string]*ast.Directive)
+		for _, d := range directives {
+			if definition := c.directives[d.Name.Name]; definition != nil && definition.Repeatable {
+				continue
+			}
+			if first, ok := seen[d.Name.Name]; ok {
+				c.ReportLocations("UniqueDirectivesPerLocationInSDL", []errors.Location{first.Name.Loc, d.Name.Loc},
+					"The directive %q can only be used once at this location.", d.Name.Name)
+				continue
+			}
+			seen[d.Name.Name] = d
+		}
+	})
+}
+
+// PossibleTypeExtensions checks that the extended types are defined, with the kind of their extensions.
+func PossibleTypeExtensions(c *SDLContext) {
+	kinds := map[string]string{
+		"scalar":    "scalar",
+		"type":      "object",
+		"interface": "interface",
+		"union":     "union",
+		"enum":      "enum",
+		"input":     "input object",
+	}
+	for _, t := range c.types {
+		if !t.extension {
+			continue
+		}
+		definition := c.definitions[t.name.Name]
+		if definition == nil {
+			c.Report("PossibleTypeExtensions", t.name.Loc, "Cannot extend type %q because it is not defined.%s", t.name.Name,
+				makeSuggestion("Did you mean", sortedKeys(c.definitions), t.name.Name))
+			continue
+		}
+		if definition.kind != t.kind {
+			c.ReportLocations("PossibleTypeExtensions", []errors.Location{definition.name.Loc, t.name.Loc},
+				"Cannot extend non-%s type %q.", kinds[t.kind], t.name.Name)
+		}
+	}
+}
+
+// ValidInterfaceImplementations checks that the objects and interfaces implement the fields of their interfaces,
+// with compatible types and the same arguments, and implement the interfaces of their interfaces too.
+func ValidInterfaceImplementations(c *SDLContext) {
+	for _, name := range sortedKeys(c.definitions) {
+		t := c.definitions[name]
+		if t.kind != "type" && t.kind != "interface" {
+			continue
+		}
+		implemented := make(map[string]bool)
+		for _, named := range c.interfacesOf(name) {
+			implemented[named.Name.Name] = true
+		}
+		for _, named := range c.interfacesOf(name) {
+			iface := c.definitions[named.Name.Name]
+			switch {
+			case iface == nil && !builtinScalars[named.Name.Name]:
+				// reported by KnownTypeNamesInSDL
+			case named.Name.Name == name:
+				c.Report("ValidInterfaceImplementations", named.Name.Loc,
+					"Type %s cannot implement itself because it would create a circular reference.", name)
+			case iface == nil || iface.kind != "interface":
+				c.Report("ValidInterfaceImplementations", named.Name.Loc,
+					"Type %s must only implement Interface types, it cannot implement %s.", name, named.Name.Name)
+			default:
+				for _, transitive := range c.interfacesOf(iface.name.Name) {
+					if !implemented[transitive.Name.Name] && transitive.Name.Name != name {
+						c.Report("ValidInterfaceImplementations", named.Name.Loc,
+							"Type %s must implement %s because it is implemented by %s.", name, transitive.Name.Name, iface.name.Name)
+					}
+				}
+				c.checkImplementation(t, iface)
+			}
+		}
+	}
+}
+
+func (c *SDLContext) checkImplementation(t, iface *sdlType) {
+	const rule = "ValidInterfaceImplementations"
+	name, ifaceName := t.name.Name, iface.name.Name
+	fields := make(map[string]*ast.FieldDefinition)
+	for _, field := range c.fieldsOf(name) {
+		if _, ok := fields[field.Name.Name]; !ok {
+			fields[field.Name.Name] = field
+		}
+	}
+	for _, ifaceField := range c.fieldsOf(ifaceName) {
+		fieldName := ifaceField.Name.Name
+		field := fields[fieldName]
+		if field == nil {
+			c.ReportLocations(rule, []errors.Location{ifaceField.Name.Loc, t.name.Loc},
+				"Interface field %s.%s expected but %s does not provide it.", ifaceName, fieldName, name)
+			continue
+		}
+		if !c.isSubType(field.Type, ifaceField.Type) {
+			c.ReportLocations(rule, []errors.Location{ifaceField.Type.Location(), field.Type.Location()},
+				"Interface field %s.%s expects type %s but %s.%s is type %s.", ifaceName, fieldName, ifaceField.Type.String(),
+				name, fieldName, field.Type.String())
+		}
+		args := make(map[string]*ast.InputValueDefinition)
+		for _, arg := range field.Argument {
+			args[arg.Name.Name] = arg
+		}
+		for _, ifaceArg := range ifaceField.Argument {
+			arg := args[ifaceArg.Name.Name]
+			if arg == nil {
+				c.ReportLocations(rule, []errors.Location{ifaceArg.Name.Loc, field.Name.Loc},
+					"Interface field argument %s.%s(%s:) expected but %s.%s does not provide it.", ifaceName, fieldName,
+					ifaceArg.Name.Name, name, fieldName)
+				continue
+			}
+			if arg.Type.String() != ifaceArg.Type.String() {
+				c.ReportLocations(rule, []errors.Location{ifaceArg.Type.Location(), arg.Type.Location()},
+					"Interface field argument %s.%s(%s:) expects type %s but %s.%s(%s:) is type %s.", ifaceName, fieldName,
+					ifaceArg.Name.Name, ifaceArg.Type.String(), name, fieldName, arg.Name.Name, arg.Type.String())
+			}
+		}
+		for _, arg := range field.Argument {
+			_, nonNull := arg.Type.(*ast.NonNull)
+			if !nonNull || arg.DefaultValue != nil {
+				continue
+			}
+			required := true
+			for _, ifaceArg := range ifaceField.Argument {
+				required = required && ifaceArg.Name.Name != arg.Name.Name
+			}
+			if required {
+				c.ReportLocations(rule, []errors.Location{arg.Name.Loc, ifaceField.Name.Loc},
+					"Object field %s.%s includes required argument %s that is missing from the Interface field %s.%s.",
+					name, fieldName, arg.Name.Name, ifaceName, fieldName)
+			}
+		}
+	}
+}
+
+// isSubType reports whether the values of type sub are values of type super, eg. a field implementing an
+// interface field may return a non null or an implementation of the interface field type.
+func (c *SDLContext) isSubType(sub, super ast.Type) bool {
+	if superNonNull, ok := super.(*ast.NonNull); ok {
+		subNonNull, ok := sub.(*ast.NonNull)
+		return ok && c.isSubType(subNonNull.Type, superNonNull.Type)
+	}
+	if subNonNull, ok := sub.(*ast.NonNull); ok {
+		return c.isSubType(subNonNull.Type, super)
+	}
+	if superList, ok := super.(*ast.List); ok {
+		subList, ok := sub.(*ast.List)
+		return ok && c.isSubType(subList.Type, superList.Type)
+	}
+	subNamed, ok := sub.(*ast.Named)
+	if !ok {
+		return false
+	}
+	superNamed := super.(*ast.Named)
+	if subNamed.Name.Name == superNamed.Name.Name {
+		return true
+	}
+	superType := c.definitions[superNamed.Name.Name]
+	if superType == nil {
+		return false
+	}
+	var possible []*ast.Named
+	switch superType.kind {
+	case "union":
+		for _, t := range c.byName[superType.name.Name] {
+			possible = append(possible, t.members...)
+		}
+	case "interface":
+		possible = c.interfacesOf(subNamed.Name.Name)
+	}
+	for _, named := range possible {
+		if named.Name.Name == subNamed.Name.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,4 +1,4 @@
-package execution
+package validation
 
 import (
 	"fmt"
@@ -23,7 +23,11 @@ func makeSuggestion(prefix string, options []string, input string) string {
 		return ""
 	}
 	sort.Slice(selected, func(i, j int) bool {
-		return distances[selected[i]] < distances[selected[j]]
+		if distances[selected[i]] != distances[selected[j]] {
+			return distances[selected[i]] < distances[selected[j]]
+		}
+		// the options usually come from maps, ties are sorted by name to keep the messages stable
+		return selected[i] < selected[j]
 	})
 
 	parts := make([]string, len(selected))
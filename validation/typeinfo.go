@@ -0,0 +1,276 @@
+package validation
+
+import (
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+)
+
+// TypeInfo tracks the schema types of the nodes of a document while it is walked, so the tools walking documents,
+// eg. linters or complexity analyzers, do not have to resolve them again. It is advanced by Walk, or by calling
+// Enter and Leave around the visit of every node. The types are nil when they are unknown to the schema.
+type TypeInfo struct {
+	c               *Context
+	typeStack       []internal.Type
+	parentTypeStack []internal.NamedType
+	inputTypeStack  []internal.Type
+	fieldDefStack   []*internal.Field
+	directive       *internal.Directive
+	argument        *internal.InputField
+}
+
+// NewTypeInfo returns a TypeInfo of the documents run against schema.
+func NewTypeInfo(schema *internal.Schema) *TypeInfo {
+	return &TypeInfo{c: &Context{Schema: schema}}
+}
+
+// Type returns the output type of the current operation, fragment or field.
+func (t *TypeInfo) Type() internal.Type {
+	return top(t.typeStack)
+}
+
+// ParentType returns the composite type the current selections are selected on.
+func (t *TypeInfo) ParentType() internal.NamedType {
+	return top(t.parentTypeStack)
+}
+
+// InputType returns the type expected of the current variable definition, argument or value.
+func (t *TypeInfo) InputType() internal.Type {
+	return top(t.inputTypeStack)
+}
+
+// FieldDef returns the definition of the current field.
+func (t *TypeInfo) FieldDef() *internal.Field {
+	return top(t.fieldDefStack)
+}
+
+// Directive returns the definition of the current directive.
+func (t *TypeInfo) Directive() *internal.Directive {
+	return t.directive
+}
+
+// Argument returns the definition of the current argument.
+func (t *TypeInfo) Argument() *internal.InputField {
+	return t.argument
+}
+
+func top[T interface{}](stack []T) T {
+	var zero T
+	if len(stack) == 0 {
+		return zero
+	}
+	return stack[len(stack)-1]
+}
+
+func pop[T interface{}](stack []T) []T {
+	if len(stack) == 0 {
+		return stack
+	}
+	return stack[:len(stack)-1]
+}
+
+// Enter advances the TypeInfo into node.
+func (t *TypeInfo) Enter(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.SelectionSet:
+		var parent internal.NamedType
+		if named := NamedType(t.Type()); isCompositeType(named) {
+			parent = named
+		}
+		t.parentTypeStack = append(t.parentTypeStack, parent)
+	case *ast.Field:
+		var typ internal.Type
+		definition := FieldDefinition(t.ParentType(), node.Name.Name)
+		if definition != nil {
+			typ = definition.Type
+		}
+		t.fieldDefStack = append(t.fieldDefStack, definition)
+		t.typeStack = append(t.typeStack, typ)
+	case *ast.Directive:
+		t.directive = t.c.Schema.Directives[node.Name.Name]
+	case *ast.OperationDefinition:
+		var typ internal.Type
+		if root := t.c.RootType(node.Operation); root != nil {
+			typ = root
+		}
+		t.typeStack = append(t.typeStack, typ)
+	case *ast.InlineFragment:
+		var typ internal.Type = NamedType(t.Type())
+		if node.TypeCondition != nil {
+			typ = t.c.TypeFromAST(node.TypeCondition)
+		}
+		t.typeStack = append(t.typeStack, typ)
+	case *ast.FragmentDefinition:
+		t.typeStack = append(t.typeStack, t.c.TypeFromAST(node.TypeCondition))
+	case *ast.VariableDefinition:
+		var typ internal.Type
+		if input := t.c.TypeFromAST(node.Type); input != nil && internal.IsInputType(input) {
+			typ = input
+		}
+		t.inputTypeStack = append(t.inputTypeStack, typ)
+	case *ast.Argument:
+		var args map[string]*internal.InputField
+		if t.directive != nil {
+			args = t.directive.Args
+		} else if definition := t.FieldDef(); definition != nil {
+			args = definition.Args
+		}
+		var typ internal.Type
+		t.argument = args[node.Name.Name]
+		if t.argument != nil {
+			typ = t.argument.Type
+		}
+		t.inputTypeStack = append(t.inputTypeStack, typ)
+	case *ast.ListValue:
+		// the items of a list are expected of the item type, a single value is coerced to a list of one item
+		typ := nullableType(t.InputType())
+		if list, ok := typ.(*internal.List); ok {
+			typ = list.Type
+		}
+		t.inputTypeStack = append(t.inputTypeStack, typ)
+	case *ast.ObjectField:
+		var typ internal.Type
+		if object, ok := NamedType(t.InputType()).(*internal.InputObject); ok {
+			if field := object.Fields[node.Name.Name.Name]; field != nil {
+				typ = field.Type
+			}
+		}
+		t.inputTypeStack = append(t.inputTypeStack, typ)
+	}
+}
+
+// Leave moves the TypeInfo back out of node.
+func (t *TypeInfo) Leave(node ast.Node) {
+	switch node.(type) {
+	case *ast.SelectionSet:
+		t.parentTypeStack = pop(t.parentTypeStack)
+	case *ast.Field:
+		t.fieldDefStack = pop(t.fieldDefStack)
+		t.typeStack = pop(t.typeStack)
+	case *ast.Directive:
+		t.directive = nil
+	case *ast.OperationDefinition, *ast.InlineFragment, *ast.FragmentDefinition:
+		t.typeStack = pop(t.typeStack)
+	case *ast.VariableDefinition, *ast.ListValue, *ast.ObjectField:
+		t.inputTypeStack = pop(t.inputTypeStack)
+	case *ast.Argument:
+		t.argument = nil
+		t.inputTypeStack = pop(t.inputTypeStack)
+	}
+}
+
+// Visitor is called by Walk on the nodes of a document: Enter when the walk enters a node, after its TypeInfo
+// is advanced, and Leave when the walk leaves it, before. Either of them may be nil.
+type Visitor struct {
+	Enter func(node ast.Node)
+	Leave func(node ast.Node)
+}
+
+// Walk walks the operations and then the fragment definitions of doc depth first, in the order of the source,
+// advancing info along. Fragment spreads are not followed.
+func Walk(doc *internal.Document, info *TypeInfo, visitor Visitor) {
+	w := walker{info: info, visitor: visitor}
+	for _, op := range doc.Operations {
+		w.enter(op)
+		w.variableDefinitions(op.Vars)
+		w.directives(op.Directives)
+		w.selectionSet(op.SelectionSet)
+		w.leave(op)
+	}
+	for _, fragment := range doc.Fragments {
+		w.enter(fragment)
+		w.variableDefinitions(fragment.VariableDefinitions)
+		w.directives(fragment.Directives)
+		w.selectionSet(fragment.SelectionSet)
+		w.leave(fragment)
+	}
+}
+
+type walker struct {
+	info    *TypeInfo
+	visitor Visitor
+}
+
+func (w *walker) enter(node ast.Node) {
+	if w.info != nil {
+		w.info.Enter(node)
+	}
+	if w.visitor.Enter != nil {
+		w.visitor.Enter(node)
+	}
+}
+
+func (w *walker) leave(node ast.Node) {
+	if w.visitor.Leave != nil {
+		w.visitor.Leave(node)
+	}
+	if w.info != nil {
+		w.info.Leave(node)
+	}
+}
+
+func (w *walker) variableDefinitions(definitions []*ast.VariableDefinition) {
+	for _, v := range definitions {
+		w.enter(v)
+		if v.DefaultValue != nil {
+			w.value(v.DefaultValue)
+		}
+		w.directives(v.Directives)
+		w.leave(v)
+	}
+}
+
+func (w *walker) directives(directives []*ast.Directive) {
+	for _, d := range directives {
+		w.enter(d)
+		w.arguments(d.Args)
+		w.leave(d)
+	}
+}
+
+func (w *walker) arguments(args []*ast.Argument) {
+	for _, arg := range args {
+		w.enter(arg)
+		w.value(arg.Value)
+		w.leave(arg)
+	}
+}
+
+func (w *walker) value(value ast.Value) {
+	w.enter(value)
+	switch value := value.(type) {
+	case *ast.ListValue:
+		for _, item := range value.Values {
+			w.value(item)
+		}
+	case *ast.ObjectValue:
+		for _, field := range value.Fields {
+			w.enter(field)
+			w.value(field.Value)
+			w.leave(field)
+		}
+	}
+	w.leave(value)
+}
+
+func (w *walker) selectionSet(selectionSet *ast.SelectionSet) {
+	if selectionSet == nil {
+		return
+	}
+	w.enter(selectionSet)
+	for _, selection := range selectionSet.Selections {
+		w.enter(selection)
+		switch selection := selection.(type) {
+		case *ast.Field:
+			w.arguments(selection.Arguments)
+			w.directives(selection.Directives)
+			w.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			w.directives(selection.Directives)
+		case *ast.InlineFragment:
+			w.directives(selection.Directives)
+			w.selectionSet(selection.SelectionSet)
+		}
+		w.leave(selection)
+	}
+	w.leave(selectionSet)
+}
@@ -0,0 +1,357 @@
+// Package validation checks executable documents against a schema with the validation rules of the
+// specification, see https://spec.graphql.org/October2021/#sec-Validation.
+//
+// Every violation is reported as a GraphQLError with its locations and the name of its rule, so a client
+// gets all the problems of a document at once, before anything is executed.
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// Rule checks a document, reporting its violations to the context.
+type Rule func(c *Context)
+
+// SpecifiedRules are the rules of the specification for executable documents. The parser drops the
+// definitions which are not executable, so the ExecutableDefinitions rule has nothing to check.
+var SpecifiedRules = []Rule{
+	UniqueOperationNames,
+	LoneAnonymousOperation,
+	SingleFieldSubscriptions,
+	KnownTypeNames,
+	FragmentsOnCompositeTypes,
+	VariablesAreInputTypes,
+	ScalarLeafs,
+	FieldsOnCorrectType,
+	UniqueFragmentNames,
+	KnownFragmentNames,
+	NoUnusedFragments,
+	PossibleFragmentSpreads,
+	NoFragmentCycles,
+	UniqueVariableNames,
+	NoUndefinedVariables,
+	NoUnusedVariables,
+	KnownDirectives,
+	UniqueDirectivesPerLocation,
+	KnownArgumentNames,
+	UniqueArgumentNames,
+	ValuesOfCorrectType,
+	ProvidedRequiredArguments,
+	VariablesInAllowedPosition,
+	OverlappingFieldsCanBeMerged,
+}
+
+// Validate checks doc with the SpecifiedRules and returns the violations, nil when doc is valid.
+func Validate(schema *internal.Schema, doc *internal.Document) errors.MultiError {
+	return ValidateWithRules(schema, doc, SpecifiedRules)
+}
+
+// ValidateWithRules checks doc with rules and returns the violations, in the order of the rules.
+func ValidateWithRules(schema *internal.Schema, doc *internal.Document, rules []Rule) errors.MultiError {
+	c := newContext(schema, doc)
+	for _, rule := range rules {
+		rule(c)
+	}
+	return c.errs
+}
+
+// Context is the document being validated, along with what the rules share about it.
+type Context struct {
+	Schema   *internal.Schema
+	Document *internal.Document
+
+	errs errors.MultiError
+	// fragments are the fragment definitions by name, the first one of a name wins
+	fragments map[string]*ast.FragmentDefinition
+	// variableUsages caches the variables used directly by the fragments
+	variableUsages map[*ast.FragmentDefinition][]variableUsage
+}
+
+func newContext(schema *internal.Schema, doc *internal.Document) *Context {
+	c := &Context{
+		Schema:         schema,
+		Document:       doc,
+		fragments:      make(map[string]*ast.FragmentDefinition),
+		variableUsages: make(map[*ast.FragmentDefinition][]variableUsage),
+	}
+	for _, fragment := range doc.Fragments {
+		if _, ok := c.fragments[fragment.Name.Name]; !ok {
+			c.fragments[fragment.Name.Name] = fragment
+		}
+	}
+	return c
+}
+
+// Report adds a violation of rule at loc.
+func (c *Context) Report(rule string, loc errors.Location, format string, args ...interface{}) {
+	c.ReportLocations(rule, []errors.Location{loc}, format, args...)
+}
+
+// ReportLocations adds a violation of rule involving several parts of the document, eg. two definitions
+// with the same name.
+func (c *Context) ReportLocations(rule string, locs []errors.Location, format string, args ...interface{}) {
+	c.errs = append(c.errs, &errors.GraphQLError{
+		Message:    fmt.Sprintf(format, args...),
+		Locations:  locs,
+		Rule:       rule,
+		Extensions: map[string]interface{}{"code": errors.CodeValidationFailed},
+	})
+}
+
+// Fragment returns the fragment definition named name, nil when there is none.
+func (c *Context) Fragment(name string) *ast.FragmentDefinition {
+	return c.fragments[name]
+}
+
+// RootType returns the root type of the operations of type op, nil when the schema has none.
+func (c *Context) RootType(op ast.OperationType) internal.NamedType {
+	var root internal.Type
+	switch op {
+	case ast.Query:
+		root = c.Schema.Query
+	case ast.Mutation:
+		root = c.Schema.Mutation
+	case ast.Subscription:
+		root = c.Schema.Subscription
+	}
+	if object, ok := root.(*internal.Object); ok && object != nil {
+		return object
+	}
+	return nil
+}
+
+// TypeFromAST returns the schema type of a type reference, nil when its named type is unknown.
+func (c *Context) TypeFromAST(typ ast.Type) internal.Type {
+	switch typ := typ.(type) {
+	case *ast.Named:
+		if named, ok := c.Schema.TypeMap[typ.Name.Name]; ok {
+			return named
+		}
+	case *ast.List:
+		if inner := c.TypeFromAST(typ.Type); inner != nil {
+			return &internal.List{Type: inner}
+		}
+	case *ast.NonNull:
+		if inner := c.TypeFromAST(typ.Type); inner != nil {
+			return &internal.NonNull{Type: inner}
+		}
+	}
+	return nil
+}
+
+// VisitSelections calls visit for every selection of the operations and fragment definitions, nested ones
+// included, with the type it is selected on, nil when that type is unknown. Fragment spreads are not followed,
+// the fragment definitions are visited on their own.
+func (c *Context) VisitSelections(visit func(parent internal.NamedType, selection ast.Selection)) {
+	for _, op := range c.Document.Operations {
+		c.walk(c.RootType(op.Operation), op.SelectionSet, visit)
+	}
+	for _, fragment := range c.Document.Fragments {
+		c.walk(c.Schema.TypeMap[fragment.TypeCondition.Name.Name], fragment.SelectionSet, visit)
+	}
+}
+
+func (c *Context) walk(parent internal.NamedType, selectionSet *ast.SelectionSet,
+	visit func(parent internal.NamedType, selection ast.Selection)) {
+	if selectionSet == nil {
+		return
+	}
+	for _, selection := range selectionSet.Selections {
+		visit(parent, selection)
+		switch selection := selection.(type) {
+		case *ast.Field:
+			var typ internal.NamedType
+			if definition := FieldDefinition(parent, selection.Name.Name); definition != nil {
+				typ = NamedType(definition.Type)
+			}
+			c.walk(typ, selection.SelectionSet, visit)
+		case *ast.InlineFragment:
+			condition := parent
+			if selection.TypeCondition != nil {
+				condition = c.Schema.TypeMap[selection.TypeCondition.Name.Name]
+			}
+			c.walk(condition, selection.SelectionSet, visit)
+		}
+	}
+}
+
+// VisitDirectives calls visit for the directives of every part of the document, along with the name of
+// the location they are used at, eg. "FIELD".
+func (c *Context) VisitDirectives(visit func(location string, directives []*ast.Directive)) {
+	for _, op := range c.Document.Operations {
+		visit(string(op.Operation), op.Directives)
+		for _, v := range op.Vars {
+			visit("VARIABLE_DEFINITION", v.Directives)
+		}
+	}
+	for _, fragment := range c.Document.Fragments {
+		visit("FRAGMENT_DEFINITION", fragment.Directives)
+		for _, v := range fragment.VariableDefinitions {
+			visit("VARIABLE_DEFINITION", v.Directives)
+		}
+	}
+	c.VisitSelections(func(_ internal.NamedType, selection ast.Selection) {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			visit("FIELD", selection.Directives)
+		case *ast.FragmentSpread:
+			visit("FRAGMENT_SPREAD", selection.Directives)
+		case *ast.InlineFragment:
+			visit("INLINE_FRAGMENT", selection.Directives)
+		}
+	})
+}
+
+// FragmentSpreads returns the fragments spread in selectionSet, directly or in its nested selection sets,
+// without following them.
+func FragmentSpreads(selectionSet *ast.SelectionSet) []*ast.FragmentSpread {
+	var spreads []*ast.FragmentSpread
+	var walk func(selectionSet *ast.SelectionSet)
+	walk = func(selectionSet *ast.SelectionSet) {
+		if selectionSet == nil {
+			return
+		}
+		for _, selection := range selectionSet.Selections {
+			switch selection := selection.(type) {
+			case *ast.Field:
+				walk(selection.SelectionSet)
+			case *ast.InlineFragment:
+				walk(selection.SelectionSet)
+			case *ast.FragmentSpread:
+				spreads = append(spreads, selection)
+			}
+		}
+	}
+	walk(selectionSet)
+	return spreads
+}
+
+// RecursiveFragments returns the fragment definitions used by selectionSet, following the spreads of the
+// fragments, each one once.
+func (c *Context) RecursiveFragments(selectionSet *ast.SelectionSet) []*ast.FragmentDefinition {
+	var fragments []*ast.FragmentDefinition
+	visited := make(map[string]bool)
+	var collect func(selectionSet *ast.SelectionSet)
+	collect = func(selectionSet *ast.SelectionSet) {
+		for _, spread := range FragmentSpreads(selectionSet) {
+			name := spread.Name.Name
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			if fragment := c.fragments[name]; fragment != nil {
+				fragments = append(fragments, fragment)
+				collect(fragment.SelectionSet)
+			}
+		}
+	}
+	collect(selectionSet)
+	return fragments
+}
+
+// typenameField is the meta field every composite type has.
+var typenameField = &internal.Field{Name: "__typename", Type: &internal.NonNull{Type: &internal.Scalar{Name: "String"}}}
+
+// FieldDefinition returns the definition of the field name of parent, nil when there is none.
+func FieldDefinition(parent internal.NamedType, name string) *internal.Field {
+	if name == "__typename" && isCompositeType(parent) {
+		return typenameField
+	}
+	return Fields(parent)[name]
+}
+
+// Fields returns the fields of an object or interface type, nil for the other types.
+func Fields(typ internal.Type) map[string]*internal.Field {
+	switch typ := typ.(type) {
+	case *internal.Object:
+		return typ.Fields
+	case *internal.Interface:
+		return typ.Fields
+	}
+	return nil
+}
+
+// NamedType returns the named type wrapped by lists and non nulls, nil for a nil type.
+func NamedType(typ internal.Type) internal.NamedType {
+	for {
+		switch t := typ.(type) {
+		case *internal.List:
+			typ = t.Type
+		case *internal.NonNull:
+			typ = t.Type
+		case internal.NamedType:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func nullableType(typ internal.Type) internal.Type {
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		return nonNull.Type
+	}
+	return typ
+}
+
+func isCompositeType(typ internal.Type) bool {
+	switch typ.(type) {
+	case *internal.Object, *internal.Interface, *internal.Union:
+		return true
+	}
+	return false
+}
+
+func isLeafType(typ internal.Type) bool {
+	switch typ.(type) {
+	case *internal.Scalar, *internal.Enum:
+		return true
+	}
+	return false
+}
+
+// astNamedType returns the named type of a type reference.
+func astNamedType(typ ast.Type) *ast.Named {
+	for {
+		switch t := typ.(type) {
+		case *ast.List:
+			typ = t.Type
+		case *ast.NonNull:
+			typ = t.Type
+		case *ast.Named:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// responseName returns the key of a field in the response.
+func responseName(field *ast.Field) string {
+	if field.Alias != nil {
+		return field.Alias.Name
+	}
+	return field.Name.Name
+}
+
+// fieldLoc returns the location of the first token of a field, its alias or its name.
+func fieldLoc(field *ast.Field) errors.Location {
+	if field.Alias != nil {
+		return field.Alias.Loc
+	}
+	return field.Name.Loc
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,618 @@
+package validation_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+type Episode int
+
+type Hero struct {
+	Name    string  `graphql:"name"`
+	Friends []*Hero `graphql:"friends"`
+}
+
+type ReviewInput struct {
+	Stars   int     `graphql:"stars"`
+	Comment *string `graphql:"comment"`
+}
+
+func buildSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Enum("Episode", Episode(0), map[string]interface{}{
+		"NEWHOPE": Episode(4),
+		"EMPIRE":  Episode(5),
+		"CLONES":  schemabuilder.DeprecatedField{Field: Episode(2), Reason: "Use NEWHOPE."},
+	})
+	hero := build.Object("Hero", Hero{})
+	hero.FieldFunc("nickname", func(h Hero) string { return h.Name }, schemabuilder.Deprecated("Use name."))
+	build.InputObject("ReviewInput", ReviewInput{})
+	build.Query().FieldFunc("hero", func(args struct {
+		Episode *Episode `graphql:"episode"`
+	}) *Hero {
+		return nil
+	})
+	build.Query().FieldFunc("search", func(args struct {
+		Text  string  `graphql:"text"`
+		Limit *int    `graphql:"limit"`
+		Name  *string `graphql:"name" deprecated:"Use text."`
+	}) []*Hero {
+		return nil
+	})
+	build.Mutation().FieldFunc("review", func(args struct {
+		Review ReviewInput `graphql:"review"`
+	}) bool {
+		return true
+	})
+	build.Subscription().FieldFunc("heroes", func() <-chan *Hero { return nil })
+	return build.MustBuild()
+}
+
+func TestValidate(t *testing.T) {
+	schema := buildSchema()
+	for _, test := range []struct {
+		name  string
+		query string
+		// rule is the rule of the first error
+		rule   string
+		errors []string
+	}{
+		{
+			name:  "valid document",
+			query: `query Q($text: String!, $limit: Int = 1) { search(text: $text, limit: $limit) { ...names } hero(episode: EMPIRE) { __typename } } fragment names on Hero { name friends { name } }`,
+		},
+		{
+			name:   "UniqueOperationNames",
+			query:  `query A { hero { name } } query A { hero { name } }`,
+			rule:   "UniqueOperationNames",
+			errors: []string{`graphql: There can be only one operation named "A". (1:7) (1:33)`},
+		},
+		{
+			name:   "LoneAnonymousOperation",
+			query:  `{ hero { name } } query A { hero { name } }`,
+			rule:   "LoneAnonymousOperation",
+			errors: []string{`graphql: This anonymous operation must be the only defined operation. (1:1)`},
+		},
+		{
+			name:   "SingleFieldSubscriptions",
+			query:  `subscription S { heroes { name } ...more } fragment more on Subscription { other: heroes { name } }`,
+			rule:   "SingleFieldSubscriptions",
+			errors: []string{`graphql: Subscription "S" must select only one top level field. (1:1)`},
+		},
+		{
+			name:  "KnownTypeNames",
+			query: `query ($e: Episod) { hero(episode: $e) { ... on Heroes { name } } }`,
+			rule:  "KnownTypeNames",
+			errors: []string{
+				`graphql: Unknown type "Episod". Did you mean "Episode"? (1:8)`,
+				`graphql: Unknown type "Heroes". Did you mean "Hero"? (1:49)`,
+			},
+		},
+		{
+			name:   "FragmentsOnCompositeTypes",
+			query:  `{ hero { ...on Episode { name } } }`,
+			rule:   "FragmentsOnCompositeTypes",
+			errors: []string{`graphql: Fragment cannot condition on non composite type "Episode". (1:16)`},
+		},
+		{
+			name:  "VariablesAreInputTypes",
+			query: `query ($hero: Hero) { hero { name } }`,
+			rule:  "VariablesAreInputTypes",
+			errors: []string{
+				`graphql: Variable "$hero" cannot be non-input type "Hero". (1:8)`,
+				`graphql: Variable "$hero" is never used. (1:8)`,
+			},
+		},
+		{
+			name:  "ScalarLeafs",
+			query: `{ hero { name { first } friends } }`,
+			rule:  "ScalarLeafs",
+			errors: []string{
+				`graphql: Field "name" must not have a selection since type "String!" has no subfields. (1:10)`,
+				`graphql: Field "friends" of type "[Hero]" must have a selection of subfields. Did you mean "friends { ... }"? (1:25)`,
+			},
+		},
+		{
+			name:   "FieldsOnCorrectType",
+			query:  `{ hero { nam } }`,
+			rule:   "FieldsOnCorrectType",
+			errors: []string{`graphql: Cannot query field "nam" on type "Hero". Did you mean "name"? (1:10)`},
+		},
+		{
+			name:   "UniqueFragmentNames",
+			query:  `{ hero { ...f } } fragment f on Hero { name } fragment f on Hero { name }`,
+			rule:   "UniqueFragmentNames",
+			errors: []string{`graphql: There can be only one fragment named "f". (1:28) (1:56)`},
+		},
+		{
+			name:   "KnownFragmentNames",
+			query:  `{ hero { ...unknown } }`,
+			rule:   "KnownFragmentNames",
+			errors: []string{`graphql: Unknown fragment "unknown". (1:13)`},
+		},
+		{
+			name:   "NoUnusedFragments",
+			query:  `{ hero { name } } fragment unused on Hero { name }`,
+			rule:   "NoUnusedFragments",
+			errors: []string{`graphql: Fragment "unused" is never used. (1:19)`},
+		},
+		{
+			name:   "PossibleFragmentSpreads",
+			query:  `{ hero { ... on Query { __typename } } }`,
+			rule:   "PossibleFragmentSpreads",
+			errors: []string{`graphql: Fragment cannot be spread here as objects of type "Hero" can never be of type "Query". (1:10)`},
+		},
+		{
+			name:   "NoFragmentCycles",
+			query:  `{ hero { ...a } } fragment a on Hero { ...b } fragment b on Hero { friends { ...a } }`,
+			rule:   "NoFragmentCycles",
+			errors: []string{`graphql: Cannot spread fragment "a" within itself via "b". (1:40) (1:78)`},
+		},
+		{
+			name:   "UniqueVariableNames",
+			query:  `query ($a: Int, $a: Int) { search(text: "", limit: $a) { name } }`,
+			rule:   "UniqueVariableNames",
+			errors: []string{`graphql: There can be only one variable named "$a". (1:8) (1:17)`},
+		},
+		{
+			name:   "NoUndefinedVariables",
+			query:  `query Q { search(text: $text) { name } }`,
+			rule:   "NoUndefinedVariables",
+			errors: []string{`graphql: Variable "$text" is not defined by operation "Q". (1:24) (1:1)`},
+		},
+		{
+			name:   "NoUnusedVariables",
+			query:  `query ($unused: Int) { hero { name } }`,
+			rule:   "NoUnusedVariables",
+			errors: []string{`graphql: Variable "$unused" is never used. (1:8)`},
+		},
+		{
+			name:  "KnownDirectives",
+			query: `query @skip(if: true) { hero @unknown { name } }`,
+			rule:  "KnownDirectives",
+			errors: []string{
+				`graphql: Directive "skip" may not be used on QUERY. (1:7)`,
+				`graphql: Unknown directive "unknown". (1:30)`,
+			},
+		},
+		{
+			name:   "UniqueDirectivesPerLocation",
+			query:  `{ hero { name @include(if: true) @include(if: false) } }`,
+			rule:   "UniqueDirectivesPerLocation",
+			errors: []string{`graphql: The directive "include" can only be used once at this location. (1:15) (1:34)`},
+		},
+		{
+			name:   "KnownArgumentNames",
+			query:  `{ search(text: "", limt: 1) { name } }`,
+			rule:   "KnownArgumentNames",
+			errors: []string{`graphql: Unknown argument "limt" on field "Query.search". Did you mean "limit"? (1:20)`},
+		},
+		{
+			name:   "UniqueArgumentNames",
+			query:  `{ search(text: "a", text: "b") { name } }`,
+			rule:   "UniqueArgumentNames",
+			errors: []string{`graphql: There can be only one argument named "text". (1:10) (1:21)`},
+		},
+		{
+			name:  "ValuesOfCorrectType",
+			query: `mutation { review(review: {stars: "5", comments: ""}) }`,
+			rule:  "ValuesOfCorrectType",
+			errors: []string{
+				`graphql: Expected value of type "Int!", found "5". (1:35)`,
+				`graphql: Field "comments" is not defined by type "ReviewInput". Did you mean "comment"? (1:40)`,
+			},
+		},
+		{
+			name:   "UniqueInputFieldNames",
+			query:  `mutation { review(review: {stars: 5, stars: 4}) }`,
+			rule:   "UniqueInputFieldNames",
+			errors: []string{`graphql: There can be only one input field named "stars". (1:28) (1:38)`},
+		},
+		{
+			name:  "ValuesOfCorrectType enums and required fields",
+			query: `mutation M { review(review: {comment: null}) } query Q { hero(episode: JEDI) { name } search(text: 1) { name } }`,
+			rule:  "ValuesOfCorrectType",
+			errors: []string{
+				`graphql: Field "ReviewInput.stars" of required type "Int!" was not provided. (1:29)`,
+				`graphql: Value "JEDI" does not exist in "Episode" enum. (1:72)`,
+				`graphql: Expected value of type "String!", found 1. (1:100)`,
+			},
+		},
+		{
+			name:   "ProvidedRequiredArguments",
+			query:  `{ search { name } }`,
+			rule:   "ProvidedRequiredArguments",
+			errors: []string{`graphql: Field "search" argument "text" of type "String!" is required, but it was not provided. (1:3)`},
+		},
+		{
+			name:   "VariablesInAllowedPosition",
+			query:  `query ($text: String, $default: String = "luke") { a: search(text: $text) { name } b: search(text: $default) { name } }`,
+			rule:   "VariablesInAllowedPosition",
+			errors: []string{`graphql: Variable "$text" of type "String" used in position expecting type "String!". (1:8) (1:68)`},
+		},
+		{
+			name:   "OverlappingFieldsCanBeMerged",
+			query:  `{ hero { name: __typename name } }`,
+			rule:   "OverlappingFieldsCanBeMerged",
+			errors: []string{`graphql: Fields "name" conflict because "__typename" and "name" are different fields. Use different aliases on the fields to fetch both if this was intentional. (1:10) (1:27)`},
+		},
+		{
+			name:   "OverlappingFieldsCanBeMerged arguments",
+			query:  `{ heroes: search(text: "a") { name } ...more } fragment more on Query { heroes: search(text: "b") { name } }`,
+			rule:   "OverlappingFieldsCanBeMerged",
+			errors: []string{`graphql: Fields "heroes" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intentional. (1:3) (1:73)`},
+		},
+		{
+			name:  "OverlappingFieldsCanBeMerged subfields",
+			query: `{ hero { friends { name } } ...a ...b } fragment a on Query { hero { friends { name: __typename } } } fragment b on Query { hero { name } }`,
+			rule:  "OverlappingFieldsCanBeMerged",
+			errors: []string{
+				`graphql: Fields "hero" conflict because subfields "friends" conflict because subfields "name" conflict because "name" and "__typename" are different fields. Use different aliases on the fields to fetch both if this was intentional. (1:3) (1:10) (1:20) (1:63) (1:70) (1:80)`,
+			},
+		},
+		{
+			name:  "OverlappingFieldsCanBeMerged same fields",
+			query: `query Q($text: String!) { search(text: $text) { name } ...f ...f hero { ... on Hero { name } name } } fragment f on Query { search(text: $text) { name } }`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.Nil(t, err) {
+				return
+			}
+			errs := validation.Validate(schema, doc)
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.errors, messages)
+			if len(errs) > 0 {
+				assert.Equal(t, test.rule, errs[0].Rule)
+			}
+		})
+	}
+}
+
+func TestNoDeprecated(t *testing.T) {
+	doc, err := internal.Parse(`query ($e: Episode = CLONES) { hero(episode: $e) { name nickname } search(text: "", name: "luke") { name } }`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	schema := buildSchema()
+	assert.Nil(t, validation.Validate(schema, doc), "deprecated elements are valid")
+
+	var messages []string
+	for _, err := range validation.ValidateWithRules(schema, doc, []validation.Rule{validation.NoDeprecated}) {
+		assert.Equal(t, "NoDeprecated", err.Rule)
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		`graphql: The enum value "Episode.CLONES" is deprecated. Use NEWHOPE. (1:22)`,
+		`graphql: The field Hero.nickname is deprecated. Use name. (1:57)`,
+		`graphql: Field "Query.search" argument "name" is deprecated. Use text. (1:85)`,
+	}, messages)
+}
+
+type Dog struct {
+	Name  string `graphql:"name"`
+	Barks bool   `graphql:"barks"`
+	Owner *Hero  `graphql:"owner"`
+}
+
+type Cat struct {
+	Name  string `graphql:"name"`
+	Meows bool   `graphql:"meows"`
+	Owner *Hero  `graphql:"owner"`
+}
+
+type Pet struct {
+	Dog *Dog `graphql:"dog"`
+	Cat *Cat `graphql:"cat"`
+}
+
+func TestOverlappingFieldsCanBeMerged(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Hero", Hero{})
+	build.Object("Dog", Dog{})
+	build.Object("Cat", Cat{})
+	build.Union("Pet", Pet{}, "")
+	build.Query().FieldFunc("pet", func() Pet { return Pet{} })
+	schema := build.MustBuild()
+
+	for _, test := range []struct {
+		name   string
+		query  string
+		errors []string
+	}{
+		{
+			name:  "different fields of mutually exclusive parents",
+			query: `{ pet { ... on Dog { noise: barks } ... on Cat { noise: meows } } }`,
+		},
+		{
+			name:   "conflicting types of mutually exclusive parents",
+			query:  `{ pet { ... on Dog { x: name } ... on Cat { x: meows } } }`,
+			errors: []string{`graphql: Fields "x" conflict because they return conflicting types "String!" and "Boolean!". Use different aliases on the fields to fetch both if this was intentional. (1:22) (1:45)`},
+		},
+		{
+			name:   "conflicting types of the subfields of mutually exclusive parents",
+			query:  `{ pet { ... on Dog { owner { x: name } } ...cat } } fragment cat on Cat { owner { x: friends { name } } }`,
+			errors: []string{`graphql: Fields "owner" conflict because subfields "x" conflict because they return conflicting types "String!" and "[Hero]". Use different aliases on the fields to fetch both if this was intentional. (1:22) (1:30) (1:75) (1:83)`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.Nil(t, err) {
+				return
+			}
+			var messages []string
+			for _, err := range validation.ValidateWithRules(schema, doc, []validation.Rule{validation.OverlappingFieldsCanBeMerged}) {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.errors, messages)
+		})
+	}
+
+	t.Run("nested fragments", func(t *testing.T) {
+		// every fragment spreads all the fragments before it, comparing the fragments spread again and again
+		// instead of once per pair would take exponential time
+		var query strings.Builder
+		query.WriteString(`{ pet { ...f39 } }`)
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&query, ` fragment f%d on Dog { name owner { name }`, i)
+			for j := 0; j < i; j++ {
+				fmt.Fprintf(&query, ` ...f%d`, j)
+			}
+			query.WriteString(` }`)
+		}
+		doc, err := internal.Parse(query.String())
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Empty(t, validation.ValidateWithRules(schema, doc, []validation.Rule{validation.OverlappingFieldsCanBeMerged}))
+	})
+}
+
+func TestLimits(t *testing.T) {
+	schema := buildSchema()
+	rules := []validation.Rule{validation.MaxAliases(2), validation.MaxRootFields(2), validation.MaxDirectives(1)}
+	for _, test := range []struct {
+		name   string
+		query  string
+		errors []string
+	}{
+		{
+			name:  "within the limits",
+			query: `query Q { a: hero { name } b: hero @skip(if: false) { name } }`,
+		},
+		{
+			name:  "aliases and root fields of fragments",
+			query: `query Q { a: hero { name } ...more } fragment more on Query { b: hero { name } c: hero { name } }`,
+			errors: []string{
+				`graphql: Aliases limit of 2 exceeded, found 3. (1:80)`,
+				`graphql: Root fields limit of 2 exceeded by operation "Q", found 3. (1:1)`,
+			},
+		},
+		{
+			name:   "directives",
+			query:  `{ hero @include(if: true) { name @skip(if: false) } }`,
+			errors: []string{`graphql: Directives limit of 1 exceeded, found 2. (1:34)`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.Nil(t, err) {
+				return
+			}
+			var messages []string
+			for _, err := range validation.ValidateWithRules(schema, doc, rules) {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.errors, messages)
+		})
+	}
+
+	for _, test := range []struct {
+		name   string
+		rule   validation.Rule
+		query  string
+		errors []string
+	}{
+		{
+			name:  "depth within the limit",
+			rule:  validation.MaxDepth(3),
+			query: `{ hero { friends { name } } }`,
+		},
+		{
+			name:   "depth of fragments",
+			rule:   validation.MaxDepth(3),
+			query:  `query Q { hero { ...f } } fragment f on Hero { friends { ... on Hero { friends { name } } } }`,
+			errors: []string{`graphql: Depth limit of 3 exceeded by operation "Q", found 4. (1:1)`},
+		},
+		{
+			name:  "cyclic fragments",
+			rule:  validation.MaxDepth(3),
+			query: `{ hero { ...f } } fragment f on Hero { friends { ...f } }`,
+		},
+		{
+			name:   "complexity",
+			rule:   validation.MaxComplexity(20),
+			query:  `{ hero { name } search(text: "", limit: 10) { name friends { name } } }`,
+			errors: []string{`graphql: Complexity limit of 20 exceeded, found 42. (1:1)`},
+		},
+		{
+			name:   "saturated complexity",
+			rule:   validation.MaxComplexity(20),
+			query:  `{ search(text: "", limit: 4000000000) { friends: search(text: "", limit: 4000000000) { friends: search(text: "", limit: 4000000000) { name } } } }`,
+			errors: []string{fmt.Sprintf(`graphql: Complexity limit of 20 exceeded, found %d. (1:1)`, math.MaxInt)},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.Nil(t, err) {
+				return
+			}
+			var messages []string
+			for _, err := range validation.ValidateWithRules(schema, doc, []validation.Rule{test.rule}) {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.errors, messages)
+		})
+	}
+
+	cache := validation.NewCacheWithRules(1, append([]validation.Rule{validation.MaxAliases(1)}, validation.SpecifiedRules...))
+	query := `{ a: hero { name } b: hero { name } }`
+	doc, _ := internal.Parse(query)
+	errs := cache.Validate(schema, query, doc)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "MaxAliases", errs[0].Rule)
+	}
+}
+
+func TestTypeInfo(t *testing.T) {
+	doc, err := internal.Parse(`query ($n: Int = 1) { search(text: "luke", limit: $n) { ... on Hero { friends @include(if: true) { name } } } } ` +
+		`mutation { review(review: {stars: 5}) }`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	info := validation.NewTypeInfo(buildSchema())
+	var visited []string
+	validation.Walk(doc, info, validation.Visitor{Enter: func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.Field:
+			visited = append(visited, fmt.Sprintf("field %s.%s: %s", info.ParentType().TypeName(), info.FieldDef().Name, info.Type()))
+		case *ast.Argument:
+			visited = append(visited, fmt.Sprintf("argument %s: %s", info.Argument().Name, info.InputType()))
+		case *ast.ObjectField:
+			visited = append(visited, fmt.Sprintf("input field %s: %s", node.Name.Name.Name, info.InputType()))
+		case *ast.VariableDefinition:
+			visited = append(visited, fmt.Sprintf("variable %s: %s", node.Var.Name.Name, info.InputType()))
+		case *ast.Directive:
+			visited = append(visited, fmt.Sprintf("directive %s", info.Directive().Name))
+		}
+	}})
+	assert.Equal(t, []string{
+		"variable n: Int",
+		"field Query.search: [Hero]",
+		"argument text: String!",
+		"argument limit: Int",
+		"field Hero.friends: [Hero]",
+		"directive include",
+		"argument if: Boolean!",
+		"field Hero.name: String!",
+		"field Mutation.review: Boolean!",
+		"argument review: ReviewInput!",
+		"input field stars: Int!",
+	}, visited)
+	assert.Nil(t, info.Type(), "the walk leaves the nodes it enters")
+}
+
+func TestValidateSDL(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		sdl    string
+		errors []string
+	}{
+		{
+			name: "valid document",
+			sdl: `schema { query: Query } type Query { node(id: ID!): Node @deprecated } interface Node { id: ID! } ` +
+				`type User implements Node { id: ID! friends: [User!] } extend type User { name: String } directive @auth(role: String) on FIELD_DEFINITION`,
+		},
+		{
+			name: "duplicate names",
+			sdl:  `type Query { a: Int a: Int } type Query { b: Int } enum E { A A } directive @d(x: Int, x: Int) on FIELD`,
+			errors: []string{
+				`graphql: There can be only one type named "Query". (1:6) (1:35)`,
+				`graphql: Enum value "E.A" can only be defined once. (1:61) (1:63)`,
+				`graphql: Field "Query.a" can only be defined once. (1:14) (1:21)`,
+				`graphql: Argument "@d(x:)" can only be defined once. (1:80) (1:88)`,
+			},
+		},
+		{
+			name: "unknown types, directives and locations",
+			sdl:  `type Query { user: Usr @auth } directive @auth on FIELD_DEFINITON extend type Query @include(if: true)`,
+			errors: []string{
+				`graphql: Unknown type "Usr". (1:20)`,
+				`graphql: Unknown directive location "FIELD_DEFINITON" on "@auth". Did you mean "FIELD_DEFINITION", ` +
+					`"FRAGMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "VARIABLE_DEFINITION", or "ARGUMENT_DEFINITION"? (1:32)`,
+				`graphql: Directive "auth" may not be used on FIELD_DEFINITION. (1:24)`,
+				`graphql: Directive "include" may not be used on OBJECT. (1:85)`,
+			},
+		},
+		{
+			name: "invalid extensions",
+			sdl:  `type Query { a: Int } extend type Querry { b: Int } extend input Query { c: Int }`,
+			errors: []string{
+				`graphql: Cannot extend type "Querry" because it is not defined. Did you mean "Query"? (1:35)`,
+				`graphql: Cannot extend non-input object type "Query". (1:6) (1:66)`,
+			},
+		},
+		{
+			name: "interface implementations",
+			sdl: `interface Node { id: ID! friends(first: Int): [Node] } interface Named implements Node { id: ID! friends(first: Int): [Node] name: String } ` +
+				`type User implements Named { id: String friends: [User] } type Query implements Int { a: Int }`,
+			errors: []string{
+				`graphql: Type Query must only implement Interface types, it cannot implement Int. (1:221)`,
+				`graphql: Type User must implement Node because it is implemented by Named. (1:162)`,
+				`graphql: Interface field Named.id expects type ID! but User.id is type String. (1:94) (1:174)`,
+				`graphql: Interface field Named.friends expects type [Node] but User.friends is type [User]. (1:119) (1:190)`,
+				`graphql: Interface field argument Named.friends(first:) expected but User.friends does not provide it. (1:106) (1:181)`,
+				`graphql: Interface field Named.name expected but User does not provide it. (1:126) (1:146)`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.ParseDocument(test.sdl)
+			if !assert.Nil(t, err) {
+				return
+			}
+			var messages []string
+			for _, err := range validation.ValidateSDL(doc) {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.errors, messages)
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	schema := buildSchema()
+	cache := validation.NewCache(2)
+	validate := func(schema *internal.Schema, query string) []string {
+		doc, err := internal.Parse(query)
+		if !assert.Nil(t, err) {
+			return nil
+		}
+		var messages []string
+		for _, err := range cache.Validate(schema, query, doc) {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+
+	invalid := `{ hero { nam } }`
+	expected := []string{`graphql: Cannot query field "nam" on type "Hero". Did you mean "name"? (1:10)`}
+	assert.Equal(t, expected, validate(schema, invalid))
+	assert.Equal(t, expected, validate(schema, invalid))
+	assert.Equal(t, 1, cache.Len())
+
+	doc, _ := internal.Parse(invalid)
+	cache.Validate(schema, invalid, doc)[0].WithCode(errors.CodeBadUserInput)
+	assert.Equal(t, map[string]interface{}{"code": errors.CodeValidationFailed}, cache.Validate(schema, invalid, doc)[0].Extensions,
+		"the cached errors are copied")
+
+	assert.Nil(t, validate(schema, `{ hero { name } }`))
+	assert.Equal(t, 2, cache.Len())
+
+	// a rebuilt schema has its own results
+	assert.Nil(t, validate(buildSchema(), `{ hero { name } }`))
+	assert.Equal(t, 2, cache.Len(), "the least recently used result is evicted")
+}
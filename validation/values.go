@@ -0,0 +1,246 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// ValuesOfCorrectType checks that the literal values of arguments and default values of variables can be coerced
+// to the types of their inputs. The values of variables are coerced when the operation is executed.
+func ValuesOfCorrectType(c *Context) {
+	checkArgs := func(args []*ast.Argument, definitions map[string]*internal.InputField) {
+		for _, arg := range args {
+			if definition := definitions[arg.Name.Name]; definition != nil {
+				c.checkValue(arg.Value, definition.Type)
+			}
+		}
+	}
+	checkDefaults := func(definitions []*ast.VariableDefinition) {
+		for _, v := range definitions {
+			if typ := c.TypeFromAST(v.Type); v.DefaultValue != nil && typ != nil && internal.IsInputType(typ) {
+				c.checkValue(v.DefaultValue, typ)
+			}
+		}
+	}
+	for _, op := range c.Document.Operations {
+		checkDefaults(op.Vars)
+	}
+	for _, fragment := range c.Document.Fragments {
+		checkDefaults(fragment.VariableDefinitions)
+	}
+	c.VisitSelections(func(parent internal.NamedType, selection ast.Selection) {
+		if field, ok := selection.(*ast.Field); ok {
+			if definition := FieldDefinition(parent, field.Name.Name); definition != nil {
+				checkArgs(field.Arguments, definition.Args)
+			}
+		}
+	})
+	c.VisitDirectives(func(_ string, directives []*ast.Directive) {
+		for _, d := range directives {
+			if definition := c.Schema.Directives[d.Name.Name]; definition != nil {
+				checkArgs(d.Args, definition.Args)
+			}
+		}
+	})
+}
+
+// checkValue reports the parts of the literal value which can not be coerced to typ.
+func (c *Context) checkValue(value ast.Value, typ internal.Type) {
+	if _, ok := value.(*ast.Variable); ok {
+		return
+	}
+	if _, null := value.(*ast.NullValue); null {
+		if _, ok := typ.(*internal.NonNull); ok {
+			c.Report("ValuesOfCorrectType", value.Location(), "Expected value of type %q, found null.", typ.String())
+		}
+		return
+	}
+
+	switch nullable := nullableType(typ).(type) {
+	case *internal.List:
+		if list, ok := value.(*ast.ListValue); ok {
+			for _, item := range list.Values {
+				c.checkValue(item, nullable.Type)
+			}
+			return
+		}
+		// a single value is coerced to a list of one item
+		c.checkValue(value, nullable.Type)
+	case *internal.InputObject:
+		object, ok := value.(*ast.ObjectValue)
+		if !ok {
+			c.reportValue(value, typ)
+			return
+		}
+		provided := make(map[string]*ast.ObjectField)
+		for _, field := range object.Fields {
+			name := field.Name.Name.Name
+			if first, ok := provided[name]; ok {
+				c.ReportLocations("UniqueInputFieldNames", []errors.Location{first.Name.Loc, field.Name.Loc},
+					"There can be only one input field named %q.", name)
+				continue
+			}
+			provided[name] = field
+			definition, ok := nullable.Fields[name]
+			if !ok {
+				c.Report("ValuesOfCorrectType", field.Loc, "Field %q is not defined by type %q.%s", name, nullable.Name,
+					makeSuggestion("Did you mean", sortedKeys(nullable.Fields), name))
+				continue
+			}
+			c.checkValue(field.Value, definition.Type)
+		}
+		if nullable.OneOf {
+			c.checkOneOf(object, nullable)
+		}
+		for _, name := range sortedKeys(nullable.Fields) {
+			definition := nullable.Fields[name]
+			if _, ok := definition.Type.(*internal.NonNull); ok && definition.DefaultValue == nil && provided[name] == nil {
+				c.Report("ValuesOfCorrectType", object.Loc, "Field \"%s.%s\" of required type %q was not provided.",
+					nullable.Name, name, definition.Type.String())
+			}
+		}
+	case *internal.Enum:
+		enum, ok := value.(*ast.EnumValue)
+		if !ok {
+			c.reportValue(value, typ)
+			return
+		}
+		for _, option := range nullable.Values {
+			if option == enum.Value {
+				return
+			}
+		}
+		c.Report("ValuesOfCorrectType", enum.Loc, "Value %q does not exist in %q enum.%s", enum.Value, nullable.Name,
+			makeSuggestion("Did you mean the enum value", nullable.Values, enum.Value))
+	case *internal.Scalar:
+		if !validScalarLiteral(nullable, value) {
+			c.reportValue(value, typ)
+		}
+	}
+}
+
+// checkOneOf reports the literals of the @oneOf input object typ which do not have exactly one non-null field. The
+// variables used as their fields are checked to be non-null by VariablesInAllowedPosition.
+func (c *Context) checkOneOf(object *ast.ObjectValue, typ *internal.InputObject) {
+	if len(object.Fields) != 1 {
+		c.Report("ValuesOfCorrectType", object.Loc, "OneOf Input Object %q must specify exactly one key.", typ.Name)
+		return
+	}
+	if field := object.Fields[0]; isNull(field.Value) {
+		c.Report("ValuesOfCorrectType", field.Loc, "Field \"%s.%s\" must be non-null.", typ.Name, field.Name.Name.Name)
+	}
+}
+
+func isNull(value ast.Value) bool {
+	_, null := value.(*ast.NullValue)
+	return null
+}
+
+func (c *Context) reportValue(value ast.Value, typ internal.Type) {
+	c.Report("ValuesOfCorrectType", value.Location(), "Expected value of type %q, found %s.", typ.String(), printValue(value))
+}
+
+// validScalarLiteral reports whether value is a literal of the scalar typ. The values of the custom scalars
+// are checked with their ParseLiteral, or their ParseValue when they have none, as the arguments of the resolvers are.
+func validScalarLiteral(typ *internal.Scalar, value ast.Value) bool {
+	switch typ.Name {
+	case "Int":
+		v, ok := value.(*ast.IntValue)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(v.Value, 10, 32)
+		return err == nil
+	case "Float":
+		switch value.(type) {
+		case *ast.IntValue, *ast.FloatValue:
+			return true
+		}
+		return false
+	case "String":
+		_, ok := value.(*ast.StringValue)
+		return ok
+	case "Boolean":
+		_, ok := value.(*ast.BooleanValue)
+		return ok
+	case "ID":
+		switch value.(type) {
+		case *ast.StringValue, *ast.IntValue:
+			return true
+		}
+		return false
+	}
+	if containsVariable(value) {
+		return true
+	}
+	if typ.ParseLiteral != nil {
+		_, err := typ.ParseLiteral(value)
+		return err == nil
+	}
+	if typ.ParseValue == nil {
+		return true
+	}
+	v, err := internal.ValueToJson(value, nil)
+	if err != nil {
+		return false
+	}
+	_, parseErr := typ.ParseValue(v)
+	return parseErr == nil
+}
+
+func containsVariable(value ast.Value) bool {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return true
+	case *ast.ListValue:
+		for _, v := range value.Values {
+			if containsVariable(v) {
+				return true
+			}
+		}
+	case *ast.ObjectValue:
+		for _, field := range value.Fields {
+			if containsVariable(field.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printValue prints a literal value the way it is written in a document.
+func printValue(value ast.Value) string {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		return value.Value
+	case *ast.FloatValue:
+		return value.Value
+	case *ast.StringValue:
+		return strconv.Quote(value.Value)
+	case *ast.BooleanValue:
+		return strconv.FormatBool(value.Value)
+	case *ast.NullValue:
+		return "null"
+	case *ast.EnumValue:
+		return value.Value
+	case *ast.Variable:
+		return "$" + value.Name.Name
+	case *ast.ListValue:
+		items := make([]string, len(value.Values))
+		for i, v := range value.Values {
+			items[i] = printValue(v)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *ast.ObjectValue:
+		fields := make([]string, len(value.Fields))
+		for i, field := range value.Fields {
+			fields[i] = field.Name.Name.Name + ": " + printValue(field.Value)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return ""
+}
@@ -0,0 +1,280 @@
+package validation
+
+import (
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+)
+
+// variableUsage is a variable used as the value of an input, typ is the type of the input, nil when it is unknown.
+type variableUsage struct {
+	variable   *ast.Variable
+	typ        internal.Type
+	hasDefault bool
+}
+
+// UniqueVariableNames checks that the variables of an operation or a fragment have different names.
+func UniqueVariableNames(c *Context) {
+	check := func(definitions []*ast.VariableDefinition) {
+		known := make(map[string]*ast.VariableDefinition)
+		for _, v := range definitions {
+			name := v.Var.Name.Name
+			if first, ok := known[name]; ok {
+				c.ReportLocations("UniqueVariableNames", []errors.Location{first.Loc, v.Loc}, "There can be only one variable named \"$%s\".", name)
+				continue
+			}
+			known[name] = v
+		}
+	}
+	for _, op := range c.Document.Operations {
+		check(op.Vars)
+	}
+	for _, fragment := range c.Document.Fragments {
+		check(fragment.VariableDefinitions)
+	}
+}
+
+// VariablesAreInputTypes checks that variables are of scalar, enum or input object types.
+func VariablesAreInputTypes(c *Context) {
+	check := func(definitions []*ast.VariableDefinition) {
+		for _, v := range definitions {
+			if typ := c.TypeFromAST(v.Type); typ != nil && !internal.IsInputType(typ) {
+				c.Report("VariablesAreInputTypes", v.Loc, "Variable \"$%s\" cannot be non-input type %q.", v.Var.Name.Name, v.Type.String())
+			}
+		}
+	}
+	for _, op := range c.Document.Operations {
+		check(op.Vars)
+	}
+	for _, fragment := range c.Document.Fragments {
+		check(fragment.VariableDefinitions)
+	}
+}
+
+// NoUndefinedVariables checks that the variables used by an operation, through its fragments too, are defined by it
+// or by the fragments.
+func NoUndefinedVariables(c *Context) {
+	for _, op := range c.Document.Operations {
+		definitions := c.variableDefinitions(op)
+		for _, usage := range c.operationVariableUsages(op) {
+			name := usage.variable.Name.Name
+			if _, ok := definitions[name]; ok {
+				continue
+			}
+			locs := []errors.Location{usage.variable.Loc, op.Loc}
+			if op.Name != nil {
+				c.ReportLocations("NoUndefinedVariables", locs, "Variable \"$%s\" is not defined by operation %q.", name, op.Name.Name)
+			} else {
+				c.ReportLocations("NoUndefinedVariables", locs, "Variable \"$%s\" is not defined.", name)
+			}
+		}
+	}
+}
+
+// NoUnusedVariables checks that the variables defined by an operation or a fragment are used by it.
+func NoUnusedVariables(c *Context) {
+	used := func(usages []variableUsage) map[string]bool {
+		names := make(map[string]bool, len(usages))
+		for _, usage := range usages {
+			names[usage.variable.Name.Name] = true
+		}
+		return names
+	}
+	for _, op := range c.Document.Operations {
+		names := used(c.operationVariableUsages(op))
+		for _, v := range op.Vars {
+			if names[v.Var.Name.Name] {
+				continue
+			}
+			if op.Name != nil {
+				c.Report("NoUnusedVariables", v.Loc, "Variable \"$%s\" is never used in operation %q.", v.Var.Name.Name, op.Name.Name)
+			} else {
+				c.Report("NoUnusedVariables", v.Loc, "Variable \"$%s\" is never used.", v.Var.Name.Name)
+			}
+		}
+	}
+	for _, fragment := range c.Document.Fragments {
+		if len(fragment.VariableDefinitions) == 0 {
+			continue
+		}
+		usages := c.fragmentVariableUsages(fragment)
+		for _, spread := range c.RecursiveFragments(fragment.SelectionSet) {
+			usages = append(usages, c.fragmentVariableUsages(spread)...)
+		}
+		names := used(usages)
+		for _, v := range fragment.VariableDefinitions {
+			if !names[v.Var.Name.Name] {
+				c.Report("NoUnusedVariables", v.Loc, "Variable \"$%s\" is never used in fragment %q.", v.Var.Name.Name, fragment.Name.Name)
+			}
+		}
+	}
+}
+
+// VariablesInAllowedPosition checks that variables are used as the values of inputs of compatible types.
+func VariablesInAllowedPosition(c *Context) {
+	for _, op := range c.Document.Operations {
+		definitions := c.variableDefinitions(op)
+		for _, usage := range c.operationVariableUsages(op) {
+			definition, ok := definitions[usage.variable.Name.Name]
+			if !ok || usage.typ == nil {
+				continue
+			}
+			typ := c.TypeFromAST(definition.Type)
+			if typ == nil || allowedVariableUsage(typ, definition.DefaultValue, usage) {
+				continue
+			}
+			c.ReportLocations("VariablesInAllowedPosition", []errors.Location{definition.Loc, usage.variable.Loc},
+				"Variable \"$%s\" of type %q used in position expecting type %q.", usage.variable.Name.Name, typ.String(), usage.typ.String())
+		}
+	}
+}
+
+// allowedVariableUsage reports whether a variable of type typ can be used where usage is. A nullable variable
+// can be used for a non-null input when one of them has a default value.
+func allowedVariableUsage(typ internal.Type, defaultValue ast.Value, usage variableUsage) bool {
+	if nonNull, ok := usage.typ.(*internal.NonNull); ok {
+		if _, ok := typ.(*internal.NonNull); !ok {
+			_, nullDefault := defaultValue.(*ast.NullValue)
+			if (defaultValue == nil || nullDefault) && !usage.hasDefault {
+				return false
+			}
+			return isSubType(typ, nonNull.Type)
+		}
+	}
+	return isSubType(typ, usage.typ)
+}
+
+// isSubType reports whether the values of typ are values of super.
+func isSubType(typ, super internal.Type) bool {
+	if superNonNull, ok := super.(*internal.NonNull); ok {
+		if nonNull, ok := typ.(*internal.NonNull); ok {
+			return isSubType(nonNull.Type, superNonNull.Type)
+		}
+		return false
+	}
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		return isSubType(nonNull.Type, super)
+	}
+	if superList, ok := super.(*internal.List); ok {
+		if list, ok := typ.(*internal.List); ok {
+			return isSubType(list.Type, superList.Type)
+		}
+		return false
+	}
+	if _, ok := typ.(*internal.List); ok {
+		return false
+	}
+	named, superNamed := NamedType(typ), NamedType(super)
+	return named != nil && superNamed != nil && named.TypeName() == superNamed.TypeName()
+}
+
+// variableDefinitions returns the variables defined by op and by the fragments it uses, by name.
+func (c *Context) variableDefinitions(op *ast.OperationDefinition) map[string]*ast.VariableDefinition {
+	definitions := make(map[string]*ast.VariableDefinition)
+	add := func(vars []*ast.VariableDefinition) {
+		for _, v := range vars {
+			if _, ok := definitions[v.Var.Name.Name]; !ok {
+				definitions[v.Var.Name.Name] = v
+			}
+		}
+	}
+	add(op.Vars)
+	for _, fragment := range c.RecursiveFragments(op.SelectionSet) {
+		add(fragment.VariableDefinitions)
+	}
+	return definitions
+}
+
+// operationVariableUsages returns the variables used by op and by the fragments it uses.
+func (c *Context) operationVariableUsages(op *ast.OperationDefinition) []variableUsage {
+	usages := c.variableUsagesOf(c.RootType(op.Operation), op.SelectionSet, op.Directives)
+	for _, fragment := range c.RecursiveFragments(op.SelectionSet) {
+		usages = append(usages, c.fragmentVariableUsages(fragment)...)
+	}
+	return usages
+}
+
+// fragmentVariableUsages returns the variables used by fragment itself, without following its spreads.
+func (c *Context) fragmentVariableUsages(fragment *ast.FragmentDefinition) []variableUsage {
+	usages, ok := c.variableUsages[fragment]
+	if !ok {
+		usages = c.variableUsagesOf(c.Schema.TypeMap[fragment.TypeCondition.Name.Name], fragment.SelectionSet, fragment.Directives)
+		c.variableUsages[fragment] = usages
+	}
+	return usages
+}
+
+func (c *Context) variableUsagesOf(parent internal.NamedType, selectionSet *ast.SelectionSet, directives []*ast.Directive) []variableUsage {
+	var usages []variableUsage
+	addArgs := func(args []*ast.Argument, definitions map[string]*internal.InputField) {
+		for _, arg := range args {
+			var typ internal.Type
+			hasDefault := false
+			if definition := definitions[arg.Name.Name]; definition != nil {
+				typ, hasDefault = definition.Type, definition.DefaultValue != nil
+			}
+			usages = collectVariables(usages, arg.Value, typ, hasDefault)
+		}
+	}
+	addDirectives := func(directives []*ast.Directive) {
+		for _, d := range directives {
+			var definitions map[string]*internal.InputField
+			if definition := c.Schema.Directives[d.Name.Name]; definition != nil {
+				definitions = definition.Args
+			}
+			addArgs(d.Args, definitions)
+		}
+	}
+	addDirectives(directives)
+	c.walk(parent, selectionSet, func(parent internal.NamedType, selection ast.Selection) {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			var definitions map[string]*internal.InputField
+			if definition := FieldDefinition(parent, selection.Name.Name); definition != nil {
+				definitions = definition.Args
+			}
+			addArgs(selection.Arguments, definitions)
+			addDirectives(selection.Directives)
+		case *ast.FragmentSpread:
+			addDirectives(selection.Directives)
+		case *ast.InlineFragment:
+			addDirectives(selection.Directives)
+		}
+	})
+	return usages
+}
+
+// collectVariables adds the variables used in value, an input of type typ, to usages.
+func collectVariables(usages []variableUsage, value ast.Value, typ internal.Type, hasDefault bool) []variableUsage {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return append(usages, variableUsage{variable: value, typ: typ, hasDefault: hasDefault})
+	case *ast.ListValue:
+		var item internal.Type
+		if list, ok := nullableType(typ).(*internal.List); ok {
+			item = list.Type
+		}
+		for _, v := range value.Values {
+			usages = collectVariables(usages, v, item, false)
+		}
+	case *ast.ObjectValue:
+		var fields map[string]*internal.InputField
+		oneOf := false
+		if object, ok := nullableType(typ).(*internal.InputObject); ok {
+			fields, oneOf = object.Fields, object.OneOf
+		}
+		for _, field := range value.Fields {
+			var fieldTyp internal.Type
+			fieldDefault := false
+			if definition := fields[field.Name.Name.Name]; definition != nil {
+				fieldTyp, fieldDefault = definition.Type, definition.DefaultValue != nil
+				// the field of a @oneOf input object is non-null, its variable can not be null
+				if oneOf {
+					fieldTyp = &internal.NonNull{Type: fieldTyp}
+				}
+			}
+			usages = collectVariables(usages, field.Value, fieldTyp, fieldDefault)
+		}
+	}
+	return usages
+}
@@ -164,13 +164,13 @@ loop:
 				fmt.Println(err)
 				return
 			}
-			query, err := internal.Parse(gql.Query)
-			if err != nil {
-				if er := writeResponse(conn, "error", data.Id, nil, err); er != nil {
-					fmt.Println(err)
+			query, parseErrs := internal.Parse(gql.Query)
+			if parseErrs != nil {
+				if er := writeResponse(conn, "error", data.Id, nil, parseErrs); er != nil {
+					fmt.Println(parseErrs)
 					return
 				}
-				fmt.Println(err)
+				fmt.Println(parseErrs)
 				return
 			}
 			schema := h.Schema.Subscription